@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageGeneric(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	packageName := "te-st_pac.kage"
+	packageVersion := "1.0.3"
+	filename := "fi-le_na.me"
+	content := []byte{1, 2, 3}
+
+	url := fmt.Sprintf("/api/packages/%s/generic/%s/%s/%s", user.Name, packageName, packageVersion, filename)
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, "user4")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		p, err := models.GetPackageByName(user.ID, models.PackageGeneric, packageName)
+		assert.NoError(t, err)
+		pv, err := models.GetPackageVersionByName(p.ID, packageVersion)
+		assert.NoError(t, err)
+		pfs, err := models.GetPackageFilesByVersionID(pv.ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, filename, pfs[0].Name)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", url)
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.Bytes())
+
+		p, err := models.GetPackageByName(user.ID, models.PackageGeneric, packageName)
+		assert.NoError(t, err)
+		pv, err := models.GetPackageVersionByName(p.ID, packageVersion)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, pv.DownloadCount)
+	})
+
+	t.Run("API", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		session := loginUser(t, user.Name)
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequestf(t, "GET", "/api/v1/packages/%s?type=generic&token=%s", user.Name, token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var apiPackages []*api.Package
+		DecodeJSON(t, resp, &apiPackages)
+		assert.Len(t, apiPackages, 2)
+
+		req = NewRequestf(t, "GET", "/api/v1/packages/%s/generic/%s/versions?token=%s", user.Name, packageName, token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		var apiVersions []*api.PackageVersion
+		DecodeJSON(t, resp, &apiVersions)
+		assert.Len(t, apiVersions, 1)
+		assert.Equal(t, packageVersion, apiVersions[0].Version)
+		assert.Len(t, apiVersions[0].Files, 1)
+		assert.EqualValues(t, len(content), apiVersions[0].Files[0].Size)
+
+		req = NewRequestf(t, "PUT", "/api/v1/packages/%s/generic/%s/link/repo1?token=%s", user.Name, packageName, token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequestf(t, "GET", "/api/v1/packages/%s/generic/%s?token=%s", user.Name, packageName, token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		var apiPackage *api.Package
+		DecodeJSON(t, resp, &apiPackage)
+		assert.NotNil(t, apiPackage.Repository)
+		assert.Equal(t, "repo1", apiPackage.Repository.Name)
+
+		req = NewRequestf(t, "DELETE", "/api/v1/packages/%s/generic/%s/link?token=%s", user.Name, packageName, token)
+		MakeRequest(t, req, http.StatusNoContent)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", fmt.Sprintf("/api/packages/%s/generic/%s/%s", user.Name, packageName, packageVersion))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		_, err := models.GetPackageByName(user.ID, models.PackageGeneric, packageName)
+		assert.Equal(t, models.ErrPackageNotExist, err)
+
+		req = NewRequest(t, "GET", url)
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
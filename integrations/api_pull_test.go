@@ -58,6 +58,87 @@ func TestAPIMergePullWIP(t *testing.T) {
 	session.MakeRequest(t, req, http.StatusMethodNotAllowed)
 }
 
+func TestAPIPullScheduleAutoMerge(t *testing.T) {
+	defer prepareTestEnv(t)()
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{Status: models.PullRequestStatusMergeable}, models.Cond("has_merged = ?", false)).(*models.PullRequest)
+
+	// require a status check which never reports, so the pull request can't be merged right away
+	assert.NoError(t, models.UpdateProtectBranch(repo, &models.ProtectedBranch{
+		RepoID:              repo.ID,
+		BranchName:          pr.BaseBranch,
+		EnableStatusCheck:   true,
+		StatusCheckContexts: []string{"ci"},
+	}, models.WhitelistOptions{}))
+
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	mergeURL := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/merge?token=%s", owner.Name, repo.Name, pr.Index, token)
+
+	req := NewRequestWithJSON(t, http.MethodPost, mergeURL, &forms.MergePullRequestForm{
+		Do: string(models.MergeStyleMerge),
+	})
+	session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+	req = NewRequestWithJSON(t, http.MethodPost, mergeURL, &forms.MergePullRequestForm{
+		Do:                     string(models.MergeStyleMerge),
+		MergeWhenChecksSucceed: true,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	models.AssertExistsAndLoadBean(t, &models.PullAutoMerge{PullID: pr.ID, DoerID: owner.ID})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypePRScheduledToAutoMerge})
+	models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID, HasMerged: false})
+
+	req = NewRequest(t, http.MethodDelete, mergeURL)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	models.AssertNotExistsBean(t, &models.PullAutoMerge{PullID: pr.ID})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypePRUnScheduledToAutoMerge})
+}
+
+func TestAPIPullAddToMergeQueue(t *testing.T) {
+	defer prepareTestEnv(t)()
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{Status: models.PullRequestStatusMergeable}, models.Cond("has_merged = ?", false)).(*models.PullRequest)
+
+	// require a status check which never reports, so the merge commit stays in the queue
+	assert.NoError(t, models.UpdateProtectBranch(repo, &models.ProtectedBranch{
+		RepoID:              repo.ID,
+		BranchName:          pr.BaseBranch,
+		EnableStatusCheck:   true,
+		StatusCheckContexts: []string{"ci"},
+		EnableMergeQueue:    true,
+	}, models.WhitelistOptions{}))
+
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	mergeURL := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/merge?token=%s", owner.Name, repo.Name, pr.Index, token)
+
+	forceMerge := true
+	req := NewRequestWithJSON(t, http.MethodPost, mergeURL, &forms.MergePullRequestForm{
+		Do:         string(models.MergeStyleMerge),
+		ForceMerge: &forceMerge,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	models.AssertExistsAndLoadBean(t, &models.MergeQueueEntry{PullID: pr.ID, DoerID: owner.ID})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypePRAddedToMergeQueue})
+	models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID, HasMerged: false})
+
+	req = NewRequest(t, http.MethodDelete, mergeURL)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypePRRemovedFromMergeQueue})
+}
+
 func TestAPICreatePullSuccess(t *testing.T) {
 	defer prepareTestEnv(t)()
 	repo10 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 10}).(*models.Repository)
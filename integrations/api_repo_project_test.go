@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	baseURL := fmt.Sprintf("/api/v1/repos/%s/%s/projects", owner.Name, repo.Name)
+
+	req := NewRequest(t, "GET", baseURL+"?state=all&token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	assert.Len(t, apiProjects, 1)
+	assert.Equal(t, "First project", apiProjects[0].Title)
+
+	req = NewRequestWithJSON(t, "POST", baseURL+"?token="+token, &api.CreateProjectOption{
+		Title:     "API project",
+		BoardType: "unknown",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", baseURL+"?token="+token, &api.CreateProjectOption{
+		Title:     "API project",
+		BoardType: "basic_kanban",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "API project", apiProject.Title)
+	assert.Equal(t, "basic_kanban", apiProject.BoardType)
+	assert.Equal(t, owner.Name, apiProject.Creator.UserName)
+	projectURL := fmt.Sprintf("%s/%d", baseURL, apiProject.ID)
+
+	// the basic kanban boards are created with the project
+	req = NewRequest(t, "GET", projectURL+"/boards?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiBoards []*api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoards)
+	assert.Len(t, apiBoards, 4)
+	assert.EqualValues(t, 0, apiBoards[0].ID)
+
+	req = NewRequestWithJSON(t, "POST", projectURL+"/boards?token="+token, &api.CreateProjectBoardOption{Title: "Review"})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiBoard api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, "Review", apiBoard.Title)
+	boardURL := fmt.Sprintf("%s/boards/%d", projectURL, apiBoard.ID)
+
+	title := "In Review"
+	isDefault := true
+	req = NewRequestWithJSON(t, "PATCH", boardURL+"?token="+token, &api.EditProjectBoardOption{Title: &title, Default: &isDefault})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, "In Review", apiBoard.Title)
+	assert.True(t, apiBoard.Default)
+
+	// move the new board to the front
+	req = NewRequest(t, "GET", projectURL+"/boards?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiBoards)
+	assert.Len(t, apiBoards, 4)
+	order := []int64{apiBoards[3].ID, apiBoards[2].ID, apiBoards[1].ID}
+	req = NewRequestWithJSON(t, "POST", projectURL+"/boards/sort?token="+token, &api.SortProjectBoardsOption{BoardIDs: order[:2]})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", projectURL+"/boards/sort?token="+token, &api.SortProjectBoardsOption{BoardIDs: append(order, apiBoard.ID)})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiBoards)
+	assert.Equal(t, apiBoard.ID, apiBoards[0].ID)
+	assert.Equal(t, order, []int64{apiBoards[1].ID, apiBoards[2].ID, apiBoards[3].ID})
+
+	// issue 1 is on a board of another project
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s/boards/%d/issues?token=%s", projectURL, order[0], token), &api.MoveProjectIssueOption{Issue: 1})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/boards/%d/issues?token=%s", projectURL, order[0], token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 1)
+	assert.EqualValues(t, 1, apiIssues[0].Index)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s/boards/%d/issues?token=%s", projectURL, order[0], token), &api.MoveProjectIssueOption{Issue: 9999})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "DELETE", boardURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	state := "closed"
+	req = NewRequestWithJSON(t, "PATCH", projectURL+"?token="+token, &api.EditProjectOption{State: &state})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, api.StateClosed, apiProject.State)
+	assert.NotNil(t, apiProject.Closed)
+
+	req = NewRequest(t, "DELETE", projectURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", projectURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// projects of other repositories can't be accessed
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/2?token=%s", baseURL, token))
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
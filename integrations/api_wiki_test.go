@@ -0,0 +1,123 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIListWikiPages(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages")
+	resp := MakeRequest(t, req, http.StatusOK)
+
+	var pages []*api.WikiPageMetaData
+	DecodeJSON(t, resp, &pages)
+	assert.Len(t, pages, 3)
+	assert.EqualValues(t, "3", resp.Header().Get("X-Total-Count"))
+
+	titles := make([]string, 0, len(pages))
+	for _, page := range pages {
+		titles = append(titles, page.Title)
+		assert.NotNil(t, page.LastCommit)
+	}
+	assert.EqualValues(t, []string{"Home", "Page With Image", "Page With Spaced Name"}, titles)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages?limit=2&page=2")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &pages)
+	assert.Len(t, pages, 1)
+	assert.EqualValues(t, "Page With Spaced Name", pages[0].Title)
+}
+
+func TestAPIGetWikiPage(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/Page-With-Spaced-Name")
+	resp := MakeRequest(t, req, http.StatusOK)
+
+	var page api.WikiPage
+	DecodeJSON(t, resp, &page)
+	assert.EqualValues(t, "Page With Spaced Name", page.Title)
+	assert.EqualValues(t, "Page-With-Spaced-Name", page.SubURL)
+	assert.EqualValues(t, 1, page.CommitCount)
+	assert.NotEmpty(t, page.ContentBase64)
+	assert.NotEmpty(t, page.ContentHTML)
+	assert.NotNil(t, page.LastCommit)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/Non-Existing")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/Home/revisions")
+	resp = MakeRequest(t, req, http.StatusOK)
+
+	var commits []*api.WikiCommit
+	DecodeJSON(t, resp, &commits)
+	assert.Len(t, commits, 1)
+	assert.EqualValues(t, "Add Home.md", commits[0].Message)
+}
+
+func TestAPIWikiPageLifecycle(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	pagesURL := fmt.Sprintf("/api/v1/repos/user2/repo1/wiki/pages?token=%s", token)
+	pageURL := func(name string) string {
+		return fmt.Sprintf("/api/v1/repos/user2/repo1/wiki/pages/%s?token=%s", name, token)
+	}
+
+	// anonymous users can't write
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/wiki/pages", &api.CreateWikiPageOptions{
+		Title: "New Page",
+	})
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequestWithJSON(t, "POST", pagesURL, &api.CreateWikiPageOptions{
+		Title:         "New Page",
+		ContentBase64: base64.StdEncoding.EncodeToString([]byte("# New Page")),
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var page api.WikiPage
+	DecodeJSON(t, resp, &page)
+	assert.EqualValues(t, "New Page", page.Title)
+	assert.EqualValues(t, "Add 'New Page'", page.LastCommit.Message)
+
+	req = NewRequestWithJSON(t, "POST", pagesURL, &api.CreateWikiPageOptions{Title: "New Page"})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	req = NewRequestWithJSON(t, "POST", pagesURL, &api.CreateWikiPageOptions{Title: "_edit"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "PATCH", pageURL("New-Page"), &api.CreateWikiPageOptions{Title: "Home"})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	req = NewRequestWithJSON(t, "PATCH", pageURL("New-Page"), &api.CreateWikiPageOptions{
+		Title:   "Renamed Page",
+		Message: "rename",
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &page)
+	assert.EqualValues(t, "Renamed Page", page.Title)
+	assert.EqualValues(t, base64.StdEncoding.EncodeToString([]byte("# New Page")), page.ContentBase64)
+	assert.EqualValues(t, "rename", page.LastCommit.Message)
+
+	req = NewRequest(t, "GET", pageURL("New-Page"))
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", pageURL("Renamed-Page"))
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "DELETE", pageURL("Renamed-Page"))
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// user3 is a public organization, anybody can see its projects
+	req := NewRequest(t, "GET", "/user3/-/projects")
+	resp := MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find(".milestone.list").Text(), "organization project")
+	req = NewRequest(t, "GET", "/user3/-/projects/4")
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user3/-/projects/1")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// user4 is in team1, which doesn't have the projects unit
+	session := loginUser(t, "user4")
+	req = NewRequest(t, "GET", "/user3/-/projects/new")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	session = loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", "/user3/-/projects/new", map[string]string{
+		"_csrf":      GetCSRF(t, session, "/user3/-/projects/new"),
+		"title":      "cross repository project",
+		"board_type": "1",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	project := models.AssertExistsAndLoadBean(t, &models.Project{OwnerID: 3, Title: "cross repository project"}).(*models.Project)
+	assert.Equal(t, models.ProjectTypeOrganization, project.Type)
+
+	// issues of repositories outside of the organization can be added too
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 1}).(*models.Issue)
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/projects", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user2/repo1/issues/1"),
+		"issue_ids": "1",
+		"id":        "4",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, 4, issue.ProjectID())
+
+	req = NewRequest(t, "GET", "/user3/-/projects/4")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.True(t, strings.Contains(htmlDoc.doc.Find(".board-card").Text(), "user2/repo1#1"))
+
+	// projects of other repositories can't be assigned
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/projects", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user2/repo1/issues/1"),
+		"issue_ids": "1",
+		"id":        "2",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
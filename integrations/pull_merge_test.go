@@ -148,6 +148,65 @@ func TestPullSquash(t *testing.T) {
 	})
 }
 
+func enablePullFastForwardOnly(t *testing.T, repoID int64) {
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: repoID}).(*models.Repository)
+	unit, err := repo.GetUnit(models.UnitTypePullRequests)
+	assert.NoError(t, err)
+	unit.PullRequestsConfig().AllowFastForwardOnly = true
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{*unit}, nil))
+}
+
+func TestPullFastForwardOnly(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		enablePullFastForwardOnly(t, 1)
+
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+		testPullMerge(t, session, elem[1], elem[2], elem[4], models.MergeStyleFastForwardOnly)
+
+		// the base branch now points to the head commit, no new commit was created
+		headRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user1", Name: "repo1"}).(*models.Repository)
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		headCommitID, err := git.GetFullCommitID(headRepo.RepoPath(), "master")
+		assert.NoError(t, err)
+		baseCommitID, err := git.GetFullCommitID(baseRepo.RepoPath(), "master")
+		assert.NoError(t, err)
+		assert.Equal(t, headCommitID, baseCommitID)
+	})
+}
+
+func TestPullFastForwardOnlyDiverging(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		enablePullFastForwardOnly(t, 1)
+
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+
+		// let the base branch diverge without conflicting with the pull request
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		_, err := createFile(user2, baseRepo, "diverged.txt")
+		assert.NoError(t, err)
+
+		token := getTokenForLoggedInUser(t, session)
+		req := NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%s/merge?token=%s", elem[4], token), map[string]string{
+			"Do": string(models.MergeStyleFastForwardOnly),
+		})
+		session.MakeRequest(t, req, http.StatusConflict)
+	})
+}
+
 func TestPullCleanUpAfterMerge(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		session := loginUser(t, "user1")
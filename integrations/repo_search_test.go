@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +38,8 @@ func TestSearchRepo(t *testing.T) {
 	executeIndexer(t, repo, code_indexer.UpdateRepoIndexer)
 
 	testSearch(t, "/user2/repo1/search?q=Description&page=1", []string{"README.md"})
+	testSearch(t, "/user2/repo1/search?q=Description+ext:md&page=1", []string{"README.md"})
+	testSearch(t, "/user2/repo1/search?q=Description+path:docs/&page=1", []string{})
 
 	setting.Indexer.IncludePatterns = setting.IndexerGlobFromString("**.txt")
 	setting.Indexer.ExcludePatterns = setting.IndexerGlobFromString("**/y/**")
@@ -51,6 +54,35 @@ func TestSearchRepo(t *testing.T) {
 	testSearch(t, "/user2/glob/search?q=file3&page=1", []string{"x/b.txt"})
 	testSearch(t, "/user2/glob/search?q=file4&page=1", []string{})
 	testSearch(t, "/user2/glob/search?q=file5&page=1", []string{})
+	testSearch(t, "/user2/glob/search?q=file3+path:x/&page=1", []string{"x/b.txt"})
+	testSearch(t, "/user2/glob/search?q=loren+path:x/&page=1", []string{})
+}
+
+func TestAPISearchCode(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo, err := models.GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.NoError(t, err)
+
+	executeIndexer(t, repo, code_indexer.UpdateRepoIndexer)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/search/code?q=Description+repo:user2/repo1")
+	resp := MakeRequest(t, req, http.StatusOK)
+
+	var results api.CodeSearchResults
+	DecodeJSON(t, resp, &results)
+	assert.True(t, results.OK)
+	if assert.Len(t, results.Data, 1) {
+		assert.Equal(t, "README.md", results.Data[0].Path)
+		assert.Equal(t, "user2/repo1", results.Data[0].RepoFullName)
+		assert.NotEmpty(t, results.Data[0].Lines)
+	}
+	assert.Equal(t, "1", resp.Header().Get("X-Total-Count"))
+
+	req = NewRequest(t, "GET", "/api/v1/repos/search/code?q=Description+repo:user2/repo1+ext:go")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &results)
+	assert.Empty(t, results.Data)
 }
 
 func testSearch(t *testing.T, url string, expected []string) {
@@ -33,6 +33,7 @@ type ProtectedBranch struct {
 	MergeWhitelistTeamIDs         []int64  `xorm:"JSON TEXT"`
 	EnableStatusCheck             bool     `xorm:"NOT NULL DEFAULT false"`
 	StatusCheckContexts           []string `xorm:"JSON TEXT"`
+	EnableMergeQueue              bool     `xorm:"NOT NULL DEFAULT false"`
 	EnableApprovalsWhitelist      bool     `xorm:"NOT NULL DEFAULT false"`
 	ApprovalsWhitelistUserIDs     []int64  `xorm:"JSON TEXT"`
 	ApprovalsWhitelistTeamIDs     []int64  `xorm:"JSON TEXT"`
@@ -628,6 +628,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return "access token is empty"
 }
 
+// ErrAccessTokenScopeInvalid represents an unknown access token scope
+type ErrAccessTokenScopeInvalid struct {
+	Scope string
+}
+
+// IsErrAccessTokenScopeInvalid checks if an error is a ErrAccessTokenScopeInvalid.
+func IsErrAccessTokenScopeInvalid(err error) bool {
+	_, ok := err.(ErrAccessTokenScopeInvalid)
+	return ok
+}
+
+func (err ErrAccessTokenScopeInvalid) Error() string {
+	return fmt.Sprintf("access token scope is invalid [scope: %s]", err.Scope)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -1308,6 +1323,23 @@ func (err ErrIssueNotExist) Error() string {
 	return fmt.Sprintf("issue does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
 }
 
+// ErrForeignReferenceNotExist represents a "ForeignReferenceNotExist" kind of error.
+type ErrForeignReferenceNotExist struct {
+	RepoID       int64
+	ForeignIndex int64
+	Type         string
+}
+
+// IsErrForeignReferenceNotExist checks if an error is a ErrForeignReferenceNotExist.
+func IsErrForeignReferenceNotExist(err error) bool {
+	_, ok := err.(ErrForeignReferenceNotExist)
+	return ok
+}
+
+func (err ErrForeignReferenceNotExist) Error() string {
+	return fmt.Sprintf("foreign reference does not exist [repo_id: %d, foreign_index: %d, type: %s]", err.RepoID, err.ForeignIndex, err.Type)
+}
+
 // ErrIssueIsClosed represents a "IssueIsClosed" kind of error.
 type ErrIssueIsClosed struct {
 	ID     int64
@@ -1446,6 +1478,66 @@ func (err ErrPullRequestNotExist) Error() string {
 		err.ID, err.IssueID, err.HeadRepoID, err.BaseRepoID, err.HeadBranch, err.BaseBranch)
 }
 
+// ErrPullAutoMergeNotExist represents a "PullAutoMergeNotExist" kind of error.
+type ErrPullAutoMergeNotExist struct {
+	PullID int64
+}
+
+// IsErrPullAutoMergeNotExist checks if an error is a ErrPullAutoMergeNotExist.
+func IsErrPullAutoMergeNotExist(err error) bool {
+	_, ok := err.(ErrPullAutoMergeNotExist)
+	return ok
+}
+
+func (err ErrPullAutoMergeNotExist) Error() string {
+	return fmt.Sprintf("pull request is not scheduled to auto merge [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAutoMergeAlreadyScheduled represents a "PullAutoMergeAlreadyScheduled" kind of error.
+type ErrPullAutoMergeAlreadyScheduled struct {
+	PullID int64
+}
+
+// IsErrPullAutoMergeAlreadyScheduled checks if an error is a ErrPullAutoMergeAlreadyScheduled.
+func IsErrPullAutoMergeAlreadyScheduled(err error) bool {
+	_, ok := err.(ErrPullAutoMergeAlreadyScheduled)
+	return ok
+}
+
+func (err ErrPullAutoMergeAlreadyScheduled) Error() string {
+	return fmt.Sprintf("pull request is already scheduled to auto merge [pull_id: %d]", err.PullID)
+}
+
+// ErrMergeQueueEntryNotExist represents a "MergeQueueEntryNotExist" kind of error.
+type ErrMergeQueueEntryNotExist struct {
+	PullID int64
+}
+
+// IsErrMergeQueueEntryNotExist checks if an error is a ErrMergeQueueEntryNotExist.
+func IsErrMergeQueueEntryNotExist(err error) bool {
+	_, ok := err.(ErrMergeQueueEntryNotExist)
+	return ok
+}
+
+func (err ErrMergeQueueEntryNotExist) Error() string {
+	return fmt.Sprintf("pull request is not in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAlreadyInMergeQueue represents a "PullAlreadyInMergeQueue" kind of error.
+type ErrPullAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+// IsErrPullAlreadyInMergeQueue checks if an error is a ErrPullAlreadyInMergeQueue.
+func IsErrPullAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrPullAlreadyInMergeQueue)
+	return ok
+}
+
+func (err ErrPullAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
 // ErrPullRequestAlreadyExists represents a "PullRequestAlreadyExists"-error
 type ErrPullRequestAlreadyExists struct {
 	ID         int64
@@ -1539,6 +1631,23 @@ func (err ErrMergeUnrelatedHistories) Error() string {
 	return fmt.Sprintf("Merge UnrelatedHistories Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
 }
 
+// ErrMergeDivergingFastForwardOnly represents an error if a fast-forward-only merge fails because the branches diverge
+type ErrMergeDivergingFastForwardOnly struct {
+	StdOut string
+	StdErr string
+	Err    error
+}
+
+// IsErrMergeDivergingFastForwardOnly checks if an error is a ErrMergeDivergingFastForwardOnly.
+func IsErrMergeDivergingFastForwardOnly(err error) bool {
+	_, ok := err.(ErrMergeDivergingFastForwardOnly)
+	return ok
+}
+
+func (err ErrMergeDivergingFastForwardOnly) Error() string {
+	return fmt.Sprintf("Merge DivergingFastForwardOnly Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
+}
+
 // ErrRebaseConflicts represents an error if rebase fails with a conflict
 type ErrRebaseConflicts struct {
 	Style     MergeStyle
@@ -1625,6 +1734,21 @@ func (err ErrStopwatchNotExist) Error() string {
 	return fmt.Sprintf("stopwatch does not exist [id: %d]", err.ID)
 }
 
+// ErrSavedSearchNotExist represents a "SavedSearchNotExist" kind of error.
+type ErrSavedSearchNotExist struct {
+	ID int64
+}
+
+// IsErrSavedSearchNotExist checks if an error is a ErrSavedSearchNotExist.
+func IsErrSavedSearchNotExist(err error) bool {
+	_, ok := err.(ErrSavedSearchNotExist)
+	return ok
+}
+
+func (err ErrSavedSearchNotExist) Error() string {
+	return fmt.Sprintf("saved search does not exist [id: %d]", err.ID)
+}
+
 // ___________                     __              .______________.__
 // \__    ___/___________    ____ |  | __ ____   __| _/\__    ___/|__| _____   ____
 // |    |  \_  __ \__  \ _/ ___\|  |/ // __ \ / __ |   |    |   |  |/     \_/ __ \
@@ -1964,25 +2088,22 @@ func (err ErrExternalLoginUserNotExist) Error() string {
 	return fmt.Sprintf("external login user link does not exists [userID: %d, loginSourceID: %d]", err.UserID, err.LoginSourceID)
 }
 
-// ____ ________________________________              .__          __                 __  .__
-// |    |   \_____  \_   _____/\______   \ ____   ____ |__| _______/  |_____________ _/  |_|__| ____   ____
-// |    |   //  ____/|    __)   |       _// __ \ / ___\|  |/  ___/\   __\_  __ \__  \\   __\  |/  _ \ /    \
-// |    |  //       \|     \    |    |   \  ___// /_/  >  |\___ \  |  |  |  | \// __ \|  | |  (  <_> )   |  \
-// |______/ \_______ \___  /    |____|_  /\___  >___  /|__/____  > |__|  |__|  (____  /__| |__|\____/|___|  /
-// \/   \/            \/     \/_____/         \/                   \/                    \/
-
-// ErrU2FRegistrationNotExist represents a "ErrU2FRegistrationNotExist" kind of error.
-type ErrU2FRegistrationNotExist struct {
-	ID int64
+// ErrWebAuthnCredentialNotExist represents a "ErrWebAuthnCredentialNotExist" kind of error.
+type ErrWebAuthnCredentialNotExist struct {
+	ID           int64
+	CredentialID string
 }
 
-func (err ErrU2FRegistrationNotExist) Error() string {
-	return fmt.Sprintf("U2F registration does not exist [id: %d]", err.ID)
+func (err ErrWebAuthnCredentialNotExist) Error() string {
+	if err.CredentialID == "" {
+		return fmt.Sprintf("WebAuthn credential does not exist [id: %d]", err.ID)
+	}
+	return fmt.Sprintf("WebAuthn credential does not exist [credential_id: %s]", err.CredentialID)
 }
 
-// IsErrU2FRegistrationNotExist checks if an error is a ErrU2FRegistrationNotExist.
-func IsErrU2FRegistrationNotExist(err error) bool {
-	_, ok := err.(ErrU2FRegistrationNotExist)
+// IsErrWebAuthnCredentialNotExist checks if an error is a ErrWebAuthnCredentialNotExist.
+func IsErrWebAuthnCredentialNotExist(err error) bool {
+	_, ok := err.(ErrWebAuthnCredentialNotExist)
 	return ok
 }
 
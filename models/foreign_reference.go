@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// Types of the items a foreign reference can point to
+const (
+	ForeignTypeIssue       = "issue"
+	ForeignTypePullRequest = "pull_request"
+)
+
+// ForeignReference maps an issue or pull request migrated from another service to its index on that service,
+// so it can be found again when the repository is synced from there
+type ForeignReference struct {
+	ID           int64  `xorm:"pk autoincr"`
+	RepoID       int64  `xorm:"UNIQUE(repo_foreign_type) INDEX(repo_local)"`
+	LocalIndex   int64  `xorm:"INDEX(repo_local)"`
+	ForeignIndex int64  `xorm:"UNIQUE(repo_foreign_type)"`
+	Type         string `xorm:"VARCHAR(16) UNIQUE(repo_foreign_type)"`
+}
+
+// GetIssueByForeignIndex returns the issue or pull request of a repository by its index on the service it has been migrated from
+func GetIssueByForeignIndex(repoID int64, isPull bool, foreignIndex int64) (*Issue, error) {
+	ref := &ForeignReference{
+		RepoID:       repoID,
+		ForeignIndex: foreignIndex,
+		Type:         ForeignTypeIssue,
+	}
+	if isPull {
+		ref.Type = ForeignTypePullRequest
+	}
+
+	has, err := x.Get(ref)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrForeignReferenceNotExist{RepoID: repoID, ForeignIndex: foreignIndex, Type: ref.Type}
+	}
+	return GetIssueByIndex(repoID, ref.LocalIndex)
+}
@@ -70,6 +70,9 @@ type Issue struct {
 
 	// For view issue page.
 	ShowTag CommentTag `xorm:"-"`
+
+	// ForeignIndex is the index of a migrated issue on the service it has been migrated from
+	ForeignIndex int64 `xorm:"-"`
 }
 
 var (
@@ -211,6 +214,11 @@ func (issue *Issue) LoadDiscussComments() error {
 	return issue.loadCommentsByType(x, CommentTypeComment)
 }
 
+// LoadSearchableComments loads the comments and the published review comments of the issue
+func (issue *Issue) LoadSearchableComments() error {
+	return IssueList{issue}.LoadSearchableComments()
+}
+
 func (issue *Issue) loadCommentsByType(e Engine, tp CommentType) (err error) {
 	if issue.Comments != nil {
 		return nil
@@ -637,6 +645,15 @@ func (issue *Issue) doChangeStatus(e *xorm.Session, doer *User, isMergePull bool
 		return nil, err
 	}
 
+	if issue.IsClosed && issue.IsPull {
+		if err := deletePullAutoMergeByIssueID(e, issue.ID); err != nil {
+			return nil, err
+		}
+		if err := deleteMergeQueueEntryByIssueID(e, issue.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update issue count of labels
 	if err := issue.getLabels(e); err != nil {
 		return nil, err
@@ -1116,6 +1133,8 @@ type IssuesOptions struct {
 	LabelIDs           []int64
 	IncludedLabelNames []string
 	ExcludedLabelNames []string
+	// RequiredLabelNames filters the issues having all of these labels
+	RequiredLabelNames []string
 	SortType           string
 	IssueIDs           []int64
 	UpdatedAfterUnix   int64
@@ -1248,6 +1267,19 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 	if len(opts.ExcludedLabelNames) > 0 {
 		sess.And(builder.NotIn("issue.id", BuildLabelNamesIssueIDsCondition(opts.ExcludedLabelNames)))
 	}
+
+	if len(opts.RequiredLabelNames) > 0 {
+		sess.And(requiredLabelNamesCond(opts.RequiredLabelNames))
+	}
+}
+
+// requiredLabelNamesCond returns the condition of issues having all of the labels
+func requiredLabelNamesCond(labelNames []string) builder.Cond {
+	cond := builder.NewCond()
+	for _, labelName := range labelNames {
+		cond = cond.And(builder.In("issue.id", BuildLabelNamesIssueIDsCondition([]string{labelName})))
+	}
+	return cond
 }
 
 func applyReposCondition(sess *xorm.Session, repoIDs []int64) *xorm.Session {
@@ -1449,6 +1481,10 @@ type IssueStatsOptions struct {
 	ReviewRequestedID int64
 	IsPull            util.OptionalBool
 	IssueIDs          []int64
+	// MilestoneIDs filters the issues of any of these milestones, in addition to MilestoneID
+	MilestoneIDs []int64
+	// RequiredLabelNames filters the issues having all of these labels
+	RequiredLabelNames []string
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
@@ -1510,10 +1546,18 @@ func getIssueStatsChunk(opts *IssueStatsOptions, issueIDs []int64) (*IssueStats,
 			}
 		}
 
+		if len(opts.RequiredLabelNames) > 0 {
+			sess.And(requiredLabelNamesCond(opts.RequiredLabelNames))
+		}
+
 		if opts.MilestoneID > 0 {
 			sess.And("issue.milestone_id = ?", opts.MilestoneID)
 		}
 
+		if len(opts.MilestoneIDs) > 0 {
+			sess.In("issue.milestone_id", opts.MilestoneIDs)
+		}
+
 		if opts.AssigneeID > 0 {
 			applyAssigneeCondition(sess, opts.AssigneeID)
 		}
@@ -1564,6 +1608,12 @@ type UserIssueStatsOptions struct {
 	IssueIDs    []int64
 	IsArchived  util.OptionalBool
 	LabelIDs    []int64
+	// Filters of an issue search query, in addition to the filter mode
+	PosterID           int64
+	AssigneeID         int64
+	MentionedID        int64
+	MilestoneIDs       []int64
+	RequiredLabelNames []string
 }
 
 // GetUserIssueStats returns issue statistic information for dashboard by given conditions.
@@ -1579,6 +1629,23 @@ func GetUserIssueStats(opts UserIssueStatsOptions) (*IssueStats, error) {
 	if len(opts.IssueIDs) > 0 {
 		cond = cond.And(builder.In("issue.id", opts.IssueIDs))
 	}
+	if opts.PosterID > 0 {
+		cond = cond.And(builder.Eq{"issue.poster_id": opts.PosterID})
+	}
+	if opts.AssigneeID > 0 {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("issue_assignees").
+			Where(builder.Eq{"assignee_id": opts.AssigneeID})))
+	}
+	if opts.MentionedID > 0 {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("issue_user").
+			Where(builder.Eq{"uid": opts.MentionedID, "is_mentioned": true})))
+	}
+	if len(opts.MilestoneIDs) > 0 {
+		cond = cond.And(builder.In("issue.milestone_id", opts.MilestoneIDs))
+	}
+	if len(opts.RequiredLabelNames) > 0 {
+		cond = cond.And(requiredLabelNamesCond(opts.RequiredLabelNames))
+	}
 
 	sess := func(cond builder.Cond) *xorm.Session {
 		s := x.Where(cond)
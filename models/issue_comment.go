@@ -101,6 +101,14 @@ const (
 	CommentTypeProjectBoard
 	// Dismiss Review
 	CommentTypeDismissReview
+	// 33 Pull request scheduled to be merged when all checks succeed
+	CommentTypePRScheduledToAutoMerge
+	// 34 Scheduled auto merge of a pull request canceled or aborted
+	CommentTypePRUnScheduledToAutoMerge
+	// 35 Pull request added to the merge queue of its base branch
+	CommentTypePRAddedToMergeQueue
+	// 36 Pull request removed or ejected from the merge queue
+	CommentTypePRRemovedFromMergeQueue
 )
 
 // CommentTag defines comment tag type
@@ -516,6 +516,21 @@ func (issues IssueList) LoadDiscussComments() error {
 	return issues.loadComments(x, builder.Eq{"comment.type": CommentTypeComment})
 }
 
+// searchableCommentsCond returns the condition of the comments and published review comments
+func searchableCommentsCond() builder.Cond {
+	return builder.In("comment.type", CommentTypeComment, CommentTypeCode, CommentTypeReview).
+		And(builder.Or(
+			builder.IsNull{"comment.review_id"},
+			builder.Eq{"comment.review_id": 0},
+			builder.NotIn("comment.review_id", builder.Select("id").From("review").Where(builder.Eq{"type": ReviewTypePending})),
+		))
+}
+
+// LoadSearchableComments loads the comments and the published review comments of the issues
+func (issues IssueList) LoadSearchableComments() error {
+	return issues.loadComments(x, searchableCommentsCond())
+}
+
 // GetApprovalCounts returns a map of issue ID to slice of approval counts
 // FIXME: only returns official counts due to double counting of non-official approvals
 func (issues IssueList) GetApprovalCounts() (map[int64][]*ReviewCount, error) {
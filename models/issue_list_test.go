@@ -69,3 +69,23 @@ func TestIssueList_LoadAttributes(t *testing.T) {
 		}
 	}
 }
+
+func TestIssueList_LoadSearchableComments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issueList := IssueList{
+		AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue),
+		AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue),
+	}
+
+	assert.NoError(t, issueList.LoadSearchableComments())
+	commentIDs := func(issue *Issue) []int64 {
+		ids := make([]int64, 0, len(issue.Comments))
+		for _, comment := range issue.Comments {
+			ids = append(ids, comment.ID)
+		}
+		return ids
+	}
+	// the label comment is no text, and comment 4 belongs to a pending review
+	assert.ElementsMatch(t, []int64{2, 3}, commentIDs(issueList[0]))
+	assert.ElementsMatch(t, []int64{5, 6}, commentIDs(issueList[1]))
+}
@@ -127,6 +127,17 @@ func GetMilestoneByRepoIDANDName(repoID int64, name string) (*Milestone, error)
 	return &mile, nil
 }
 
+// GetMilestoneIDsByRepoIDsAndName returns the ids of the milestones with the given name
+// in the repositories, or in all repositories if none is given.
+func GetMilestoneIDsByRepoIDsAndName(repoIDs []int64, name string) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	sess := x.Table("milestone").Where("name=?", name)
+	if len(repoIDs) > 0 {
+		sess.In("repo_id", repoIDs)
+	}
+	return ids, sess.Cols("id").Find(&ids)
+}
+
 // GetMilestoneByID returns the milestone via id .
 func GetMilestoneByID(id int64) (*Milestone, error) {
 	var m Milestone
@@ -192,33 +203,6 @@ func updateMilestoneCompleteness(e Engine, milestoneID int64) error {
 	return err
 }
 
-// ChangeMilestoneStatusByRepoIDAndID changes a milestone open/closed status if the milestone ID is in the repo.
-func ChangeMilestoneStatusByRepoIDAndID(repoID, milestoneID int64, isClosed bool) error {
-	sess := x.NewSession()
-	defer sess.Close()
-	if err := sess.Begin(); err != nil {
-		return err
-	}
-
-	m := &Milestone{
-		ID:     milestoneID,
-		RepoID: repoID,
-	}
-
-	has, err := sess.ID(milestoneID).Where("repo_id = ?", repoID).Get(m)
-	if err != nil {
-		return err
-	} else if !has {
-		return ErrMilestoneNotExist{ID: milestoneID, RepoID: repoID}
-	}
-
-	if err := changeMilestoneStatus(sess, m, isClosed); err != nil {
-		return err
-	}
-
-	return sess.Commit()
-}
-
 // ChangeMilestoneStatus changes the milestone open/closed status.
 func ChangeMilestoneStatus(m *Milestone, isClosed bool) (err error) {
 	sess := x.NewSession()
@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// SavedSearch represents a named issue search query a user saved on the dashboard.
+type SavedSearch struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"INDEX NOT NULL"`
+	Name        string             `xorm:"NOT NULL"`
+	IsPull      bool               `xorm:"NOT NULL DEFAULT false"`
+	Query       string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// CreateSavedSearch saves a search of a user
+func CreateSavedSearch(search *SavedSearch) error {
+	_, err := x.Insert(search)
+	return err
+}
+
+// GetSavedSearchesByUserID returns the searches of the issues or pull requests a user saved
+func GetSavedSearchesByUserID(userID int64, isPull bool) ([]*SavedSearch, error) {
+	searches := make([]*SavedSearch, 0, 5)
+	return searches, x.
+		Where("user_id = ? AND is_pull = ?", userID, isPull).
+		Asc("name").
+		Find(&searches)
+}
+
+// DeleteSavedSearch deletes a search the user saved
+func DeleteSavedSearch(userID, id int64) error {
+	deleted, err := x.Delete(&SavedSearch{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrSavedSearchNotExist{ID: id}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSearches(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, CreateSavedSearch(&SavedSearch{UserID: 2, Name: "open bugs", Query: "is:open label:bug"}))
+	assert.NoError(t, CreateSavedSearch(&SavedSearch{UserID: 2, Name: "mine", IsPull: true, Query: "author:@me"}))
+	assert.NoError(t, CreateSavedSearch(&SavedSearch{UserID: 3, Name: "crashes", Query: "crash"}))
+
+	searches, err := GetSavedSearchesByUserID(2, false)
+	assert.NoError(t, err)
+	if assert.Len(t, searches, 1) {
+		assert.Equal(t, "open bugs", searches[0].Name)
+		assert.Equal(t, "is:open label:bug", searches[0].Query)
+	}
+
+	searches, err = GetSavedSearchesByUserID(2, true)
+	assert.NoError(t, err)
+	if assert.Len(t, searches, 1) {
+		assert.Equal(t, "mine", searches[0].Name)
+
+		err = DeleteSavedSearch(3, searches[0].ID)
+		assert.True(t, IsErrSavedSearchNotExist(err))
+		assert.NoError(t, DeleteSavedSearch(2, searches[0].ID))
+		AssertNotExistsBean(t, &SavedSearch{ID: searches[0].ID})
+	}
+}
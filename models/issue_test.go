@@ -167,6 +167,19 @@ func TestIssues(t *testing.T) {
 			},
 			[]int64{}, // issues with **both** label 1 and 2, none of these issues matches, TODO: add more tests
 		},
+		{
+			IssuesOptions{
+				RequiredLabelNames: []string{"label1", "orglabel4"},
+				SortType:           "oldest",
+			},
+			[]int64{2},
+		},
+		{
+			IssuesOptions{
+				RequiredLabelNames: []string{"label1", "label2"},
+			},
+			[]int64{},
+		},
 	} {
 		issues, err := Issues(&test.Opts)
 		assert.NoError(t, err)
@@ -224,6 +237,20 @@ func TestGetUserIssueStats(t *testing.T) {
 				ClosedCount:           0,
 			},
 		},
+		{
+			UserIssueStatsOptions{
+				UserID:             1,
+				FilterMode:         FilterModeCreate,
+				RequiredLabelNames: []string{"label1"},
+			},
+			IssueStats{
+				YourRepositoriesCount: 0,
+				AssignCount:           1,
+				CreateCount:           1,
+				OpenCount:             1,
+				ClosedCount:           0,
+			},
+		},
 		{
 			UserIssueStatsOptions{
 				UserID:      2,
@@ -56,6 +56,20 @@ func insertIssue(sess *xorm.Session, issue *Issue) error {
 	if _, err := sess.NoAutoTime().Insert(issue); err != nil {
 		return err
 	}
+	if issue.ForeignIndex > 0 {
+		ref := &ForeignReference{
+			RepoID:       issue.RepoID,
+			LocalIndex:   issue.Index,
+			ForeignIndex: issue.ForeignIndex,
+			Type:         ForeignTypeIssue,
+		}
+		if issue.IsPull {
+			ref.Type = ForeignTypePullRequest
+		}
+		if _, err := sess.Insert(ref); err != nil {
+			return err
+		}
+	}
 	issueLabels := make([]IssueLabel, 0, len(issue.Labels))
 	labelIDs := make([]int64, 0, len(issue.Labels))
 	for _, label := range issue.Labels {
@@ -192,6 +206,100 @@ func InsertPullRequests(prs ...*PullRequest) error {
 	return sess.Commit()
 }
 
+// UpdateMigratedIssue updates an issue or a pull request synced from the service it has been migrated from.
+// Its labels are replaced by issue.Labels and the counters of its repository, labels and milestones are
+// recalculated, no comments are created for the changes as they have been made on the other service.
+func UpdateMigratedIssue(issue *Issue) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	old, err := getIssueByID(sess, issue.ID)
+	if err != nil {
+		return err
+	}
+	labelIDs := make([]int64, 0, len(issue.Labels))
+	if err := sess.Table("issue_label").Where("issue_id = ?", issue.ID).Cols("label_id").Find(&labelIDs); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(issue.ID).NoAutoTime().
+		Cols("name", "content", "is_closed", "closed_unix", "is_locked", "milestone_id", "updated_unix").
+		Update(issue); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&IssueLabel{IssueID: issue.ID}); err != nil {
+		return err
+	}
+	issueLabels := make([]IssueLabel, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		issueLabels = append(issueLabels, IssueLabel{
+			IssueID: issue.ID,
+			LabelID: label.ID,
+		})
+		labelIDs = append(labelIDs, label.ID)
+	}
+	if len(issueLabels) > 0 {
+		if _, err := sess.Insert(issueLabels); err != nil {
+			return err
+		}
+	}
+	for _, labelID := range labelIDs {
+		if err := updateLabelCols(sess, &Label{ID: labelID}, "num_issues", "num_closed_issue"); err != nil {
+			return err
+		}
+	}
+
+	for _, milestoneID := range []int64{old.MilestoneID, issue.MilestoneID} {
+		if milestoneID <= 0 {
+			continue
+		}
+		if err := updateMilestoneTotalNum(sess, milestoneID); err != nil {
+			return err
+		}
+		if err := updateMilestoneClosedNum(sess, milestoneID); err != nil {
+			return err
+		}
+	}
+
+	if old.IsClosed != issue.IsClosed {
+		col := "num_closed_issues"
+		if old.IsPull {
+			col = "num_closed_pulls"
+		}
+		if _, err := sess.Exec("UPDATE `repository` SET "+col+"=(SELECT COUNT(*) FROM `issue` WHERE repo_id=? AND is_closed=? AND is_pull=?) WHERE id=?",
+			old.RepoID, true, old.IsPull, old.RepoID); err != nil {
+			return err
+		}
+	}
+
+	if issue.PullRequest != nil {
+		if _, err := sess.ID(issue.PullRequest.ID).NoAutoTime().
+			Cols("has_merged", "merged_unix", "merged_commit_id", "merger_id", "merge_base").
+			Update(issue.PullRequest); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// UpdateMigratedComment updates the content of a comment synced from the service it has been migrated from
+func UpdateMigratedComment(c *Comment) error {
+	_, err := x.ID(c.ID).NoAutoTime().Cols("content", "updated_unix").Update(c)
+	return err
+}
+
+// GetMaxIssueIndex returns the highest index of the issues and pull requests of a repository
+func GetMaxIssueIndex(repoID int64) (int64, error) {
+	var maxIndex int64
+	_, err := x.Table("issue").Where("repo_id = ?", repoID).Select("coalesce(MAX(`index`),0)").Get(&maxIndex)
+	return maxIndex, err
+}
+
 // InsertReleases migrates release
 func InsertReleases(rels ...*Release) error {
 	sess := x.NewSession()
@@ -304,6 +304,36 @@ var migrations = []Migration{
 	NewMigration("Delete orphaned IssueLabels", deleteOrphanedIssueLabels),
 	// v178 -> v179
 	NewMigration("Add LFS columns to Mirror", addLFSMirrorColumns),
+	// v179 -> v180
+	NewMigration("Add push mirror table", addPushMirrorTable),
+	// v180 -> v181
+	NewMigration("Add package tables", addPackageTables),
+	// v181 -> v182
+	NewMigration("Add scopes, expiry and repository allow-list to access tokens", addScopesToAccessTokens),
+	// v182 -> v183
+	NewMigration("Convert U2F registrations to WebAuthn credentials", convertU2FToWebAuthn),
+	// v183 -> v184
+	NewMigration("Add owner id to project", addOwnerIDToProject),
+	// v184 -> v185
+	NewMigration("Add pull auto merge table", addPullAutoMergeTable),
+	// v185 -> v186
+	NewMigration("Add merge queue", addMergeQueue),
+	// v186 -> v187
+	NewMigration("Add message column to task", addTaskMessageColumn),
+	// v187 -> v188
+	NewMigration("Add foreign reference table", addForeignReferenceTable),
+	// v188 -> v189
+	NewMigration("Add branches to the code indexer", addCodeIndexerBranches),
+	// v189 -> v190
+	NewMigration("Add saved search table", addSavedSearchTable),
+	// v190 -> v191
+	NewMigration("Add webhook delivery retries", addWebhookRetries),
+	// v191 -> v192
+	NewMigration("Add webhook signature algorithm and authorization header", addWebhookSignatureAlgorithmAndAuthorization),
+	// v192 -> v193
+	NewMigration("Add webhook client certificates and CA certificates", addWebhookClientTLS),
+	// v193 -> v194
+	NewMigration("Add last synced time to mirrors", addMirrorLastSyncedUnix),
 }
 
 // GetCurrentDBVersion returns the current db version
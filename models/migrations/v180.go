@@ -0,0 +1,64 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPackageTables(x *xorm.Engine) error {
+	type Package struct {
+		ID        int64  `xorm:"pk autoincr"`
+		OwnerID   int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		RepoID    int64  `xorm:"INDEX"`
+		Type      int    `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Name      string `xorm:"NOT NULL"`
+		LowerName string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type PackageVersion struct {
+		ID            int64  `xorm:"pk autoincr"`
+		PackageID     int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CreatorID     int64  `xorm:"NOT NULL DEFAULT 0"`
+		Version       string `xorm:"NOT NULL"`
+		LowerVersion  string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		MetadataJSON  string `xorm:"metadata_json TEXT"`
+		DownloadCount int64  `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type PackageFile struct {
+		ID        int64  `xorm:"pk autoincr"`
+		VersionID int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		BlobID    int64  `xorm:"INDEX NOT NULL"`
+		Name      string `xorm:"NOT NULL"`
+		LowerName string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	type PackageBlob struct {
+		ID         int64  `xorm:"pk autoincr"`
+		Size       int64  `xorm:"NOT NULL DEFAULT 0"`
+		HashMD5    string `xorm:"hash_md5 char(32) UNIQUE(md5) INDEX NOT NULL"`
+		HashSHA1   string `xorm:"hash_sha1 char(40) UNIQUE(sha1) INDEX NOT NULL"`
+		HashSHA256 string `xorm:"hash_sha256 char(64) UNIQUE(sha256) INDEX NOT NULL"`
+		HashSHA512 string `xorm:"hash_sha512 char(128) UNIQUE(sha512) INDEX NOT NULL"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created NOT NULL"`
+	}
+
+	if err := x.Sync2(new(Package), new(PackageVersion), new(PackageFile), new(PackageBlob)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addScopesToAccessTokens(x *xorm.Engine) error {
+	type AccessToken struct {
+		Scope       string             `xorm:"NOT NULL DEFAULT ''"`
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX"`
+		RepoIDs     []int64            `xorm:"JSON TEXT"`
+	}
+
+	if err := x.Sync2(new(AccessToken)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// Existing tokens keep the unrestricted access they had before
+	_, err := x.Exec("UPDATE `access_token` SET `scope` = ? WHERE `scope` = ''", "all")
+	return err
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// WebAuthnCredential here is a snapshot of models.WebAuthnCredential for this version of the database
+type WebAuthnCredential struct {
+	ID              int64 `xorm:"pk autoincr"`
+	Name            string
+	LowerName       string `xorm:"unique(s)"`
+	UserID          int64  `xorm:"INDEX unique(s)"`
+	CredentialID    string `xorm:"INDEX VARCHAR(410)"`
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32             `xorm:"BIGINT"`
+	Passwordless    bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the database table name to be the correct one, as the
+// autogenerated table name for this struct is "web_authn_credential".
+func (cred WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
+
+// u2fPublicKeyToCOSE converts the uncompressed P-256 public key of a U2F registration to the
+// COSE_Key of an ES256 WebAuthn credential. It is written out here, so that this migration
+// doesn't change with the WebAuthn implementation.
+func u2fPublicKeyToCOSE(point []byte) ([]byte, error) {
+	if x, _ := elliptic.Unmarshal(elliptic.P256(), point); x == nil {
+		return nil, errors.New("invalid P-256 point")
+	}
+
+	// The canonical CBOR map {1: 2 (EC2), 3: -7 (ES256), -1: 1 (P-256), -2: x, -3: y}
+	var buf bytes.Buffer
+	buf.Write([]byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01})
+	buf.Write([]byte{0x21, 0x58, 0x20})
+	buf.Write(point[1:33])
+	buf.Write([]byte{0x22, 0x58, 0x20})
+	buf.Write(point[33:65])
+	return buf.Bytes(), nil
+}
+
+func convertU2FToWebAuthn(x *xorm.Engine) error {
+	type U2FRegistration struct {
+		ID          int64 `xorm:"pk autoincr"`
+		Name        string
+		UserID      int64 `xorm:"INDEX"`
+		Raw         []byte
+		Counter     uint32             `xorm:"BIGINT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(WebAuthnCredential)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	exist, err := x.IsTableExist("u2f_registration")
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return nil
+	}
+
+	regs := make([]*U2FRegistration, 0, 50)
+	if err := x.Table("u2f_registration").Asc("id").Find(&regs); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(regs))
+	for _, reg := range regs {
+		// The raw registration data starts with the reserved byte 0x05, followed by
+		// the uncompressed public key and the length prefixed key handle.
+		raw := reg.Raw
+		if len(raw) < 1+65+1 || raw[0] != 0x05 || len(raw) < 1+65+1+int(raw[66]) {
+			log.Warn("Skipping malformed U2F registration %d of user %d", reg.ID, reg.UserID)
+			continue
+		}
+		publicKey, err := u2fPublicKeyToCOSE(raw[1:66])
+		if err != nil {
+			log.Warn("Skipping U2F registration %d of user %d: %v", reg.ID, reg.UserID, err)
+			continue
+		}
+		keyHandle := raw[67 : 67+int(raw[66])]
+
+		// Names were not unique per user before
+		name := reg.Name
+		for i := 2; names[fmt.Sprintf("%d/%s", reg.UserID, strings.ToLower(name))]; i++ {
+			name = fmt.Sprintf("%s (%d)", reg.Name, i)
+		}
+		names[fmt.Sprintf("%d/%s", reg.UserID, strings.ToLower(name))] = true
+
+		cred := &WebAuthnCredential{
+			Name:            name,
+			LowerName:       strings.ToLower(name),
+			UserID:          reg.UserID,
+			CredentialID:    base64.RawURLEncoding.EncodeToString(keyHandle),
+			PublicKey:       publicKey,
+			AttestationType: "fido-u2f",
+			AAGUID:          make([]byte, 16),
+			SignCount:       reg.Counter,
+			CreatedUnix:     reg.CreatedUnix,
+			UpdatedUnix:     reg.UpdatedUnix,
+		}
+		if _, err := sess.NoAutoTime().Insert(cred); err != nil {
+			return err
+		}
+	}
+
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+
+	return x.DropTables("u2f_registration")
+}
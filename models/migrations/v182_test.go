@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"code.gitea.io/gitea/modules/auth/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_u2fPublicKeyToCOSE(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		point := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+
+		cose, err := u2fPublicKeyToCOSE(point)
+		assert.NoError(t, err)
+		expected, err := webauthn.EncodeEC2PublicKey(point)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, cose)
+	}
+
+	_, err := u2fPublicKeyToCOSE(make([]byte, 65))
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type MergeQueueEntry struct {
+		ID             int64              `xorm:"pk autoincr"`
+		RepoID         int64              `xorm:"INDEX NOT NULL"`
+		BaseBranch     string             `xorm:"NOT NULL"`
+		PullID         int64              `xorm:"UNIQUE"`
+		DoerID         int64              `xorm:"NOT NULL"`
+		MergeStyle     string             `xorm:"varchar(30)"`
+		Message        string             `xorm:"LONGTEXT"`
+		Status         int                `xorm:"NOT NULL DEFAULT 0"`
+		MergeCommitSHA string             `xorm:"VARCHAR(40)"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return x.Sync2(new(MergeQueueEntry))
+}
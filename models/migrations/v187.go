@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+func addForeignReferenceTable(x *xorm.Engine) error {
+	type ForeignReference struct {
+		ID           int64  `xorm:"pk autoincr"`
+		RepoID       int64  `xorm:"UNIQUE(repo_foreign_type) INDEX(repo_local)"`
+		LocalIndex   int64  `xorm:"INDEX(repo_local)"`
+		ForeignIndex int64  `xorm:"UNIQUE(repo_foreign_type)"`
+		Type         string `xorm:"VARCHAR(16) UNIQUE(repo_foreign_type)"`
+	}
+
+	type Issue struct {
+		ID     int64
+		RepoID int64
+		Index  int64
+		IsPull bool
+	}
+
+	if err := x.Sync2(new(ForeignReference)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// The issues and pull requests migrated so far kept their index on the service they have been migrated from,
+	// which is anything but plain git (1)
+	migratedRepos := builder.Select("id").From("repository").Where(builder.Gt{"original_service_type": 1})
+
+	var lastID int64
+	batchSize := setting.Database.IterateBufferSize
+	sess := x.NewSession()
+	defer sess.Close()
+	for {
+		issues := make([]*Issue, 0, batchSize)
+		if err := sess.Where(builder.Gt{"id": lastID}.And(builder.In("repo_id", migratedRepos))).
+			OrderBy("id").
+			Limit(batchSize).
+			Find(&issues); err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			break
+		}
+		lastID = issues[len(issues)-1].ID
+
+		refs := make([]*ForeignReference, 0, len(issues))
+		for _, issue := range issues {
+			ref := &ForeignReference{
+				RepoID:       issue.RepoID,
+				LocalIndex:   issue.Index,
+				ForeignIndex: issue.Index,
+				Type:         "issue",
+			}
+			if issue.IsPull {
+				ref.Type = "pull_request"
+			}
+			refs = append(refs, ref)
+		}
+		if _, err := sess.Insert(refs); err != nil {
+			return fmt.Errorf("insert foreign references: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addCodeIndexerBranches(x *xorm.Engine) error {
+	type Repository struct {
+		CodeIndexerBranches []string `xorm:"TEXT JSON"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	type RepoIndexerStatus struct {
+		Branch string `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+	}
+
+	if err := x.Sync2(new(RepoIndexerStatus)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
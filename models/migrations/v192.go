@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addWebhookClientTLS(x *xorm.Engine) error {
+	type Webhook struct {
+		ClientCertificate  string `xorm:"TEXT"`
+		ClientKeyEncrypted string `xorm:"TEXT"`
+		CACertificates     string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(Webhook)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMirrorLastSyncedUnix(x *xorm.Engine) error {
+	type Mirror struct {
+		LastSyncedUnix timeutil.TimeStamp
+	}
+
+	if err := x.Sync2(new(Mirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// the issues and pull requests of mirrors have been synced from their last update on so far
+	if _, err := x.Exec("UPDATE mirror SET last_synced_unix = updated_unix"); err != nil {
+		return fmt.Errorf("update last_synced_unix: %v", err)
+	}
+	return nil
+}
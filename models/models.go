@@ -82,12 +82,19 @@ func init() {
 		new(Action),
 		new(Issue),
 		new(PullRequest),
+		new(PullAutoMerge),
+		new(MergeQueueEntry),
 		new(Comment),
 		new(Attachment),
 		new(Label),
 		new(IssueLabel),
 		new(Milestone),
 		new(Mirror),
+		new(PushMirror),
+		new(Package),
+		new(PackageVersion),
+		new(PackageFile),
+		new(PackageBlob),
 		new(Release),
 		new(LoginSource),
 		new(Webhook),
@@ -119,7 +126,7 @@ func init() {
 		new(LFSLock),
 		new(Reaction),
 		new(IssueAssignees),
-		new(U2FRegistration),
+		new(WebAuthnCredential),
 		new(TeamUnit),
 		new(Review),
 		new(OAuth2Application),
@@ -134,6 +141,8 @@ func init() {
 		new(ProjectIssue),
 		new(Session),
 		new(RepoTransfer),
+		new(ForeignReference),
+		new(SavedSearch),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
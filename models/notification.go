@@ -72,6 +72,7 @@ type FindNotificationOptions struct {
 	ListOptions
 	UserID            int64
 	RepoID            int64
+	RepoIDs           []int64
 	IssueID           int64
 	Status            []NotificationStatus
 	UpdatedAfterUnix  int64
@@ -87,6 +88,9 @@ func (opts *FindNotificationOptions) ToCond() builder.Cond {
 	if opts.RepoID != 0 {
 		cond = cond.And(builder.Eq{"notification.repo_id": opts.RepoID})
 	}
+	if len(opts.RepoIDs) > 0 {
+		cond = cond.And(builder.In("notification.repo_id", opts.RepoIDs))
+	}
 	if opts.IssueID != 0 {
 		cond = cond.And(builder.Eq{"notification.issue_id": opts.IssueID})
 	}
@@ -41,6 +41,15 @@ func TestNotificationsForUser(t *testing.T) {
 	}
 }
 
+func TestGetNotifications_RepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	notfs, err := GetNotifications(FindNotificationOptions{UserID: 2, RepoIDs: []int64{2}})
+	assert.NoError(t, err)
+	if assert.Len(t, notfs, 1) {
+		assert.EqualValues(t, 2, notfs[0].RepoID)
+	}
+}
+
 func TestNotification_GetRepo(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	notf := AssertExistsAndLoadBean(t, &Notification{RepoID: 1}).(*Notification)
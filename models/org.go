@@ -300,6 +300,14 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err := deletePackagesByOwnerID(e, u.ID); err != nil {
+		return fmt.Errorf("deletePackagesByOwnerID: %v", err)
+	}
+
+	if err := deleteProjectsByOwnerID(e, u.ID); err != nil {
+		return fmt.Errorf("deleteProjectsByOwnerID: %v", err)
+	}
+
 	if _, err = e.ID(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
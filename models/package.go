@@ -0,0 +1,279 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+var (
+	// ErrPackageNotExist indicates a package not exist error
+	ErrPackageNotExist = errors.New("Package does not exist")
+	// ErrPackageAlreadyExist indicates a package already exists error
+	ErrPackageAlreadyExist = errors.New("Package already exists")
+)
+
+// PackageType specifies the different package types
+type PackageType int
+
+// Note: new type must append to the end of list to maintain compatibility.
+const (
+	PackageGeneric   PackageType = iota // 0
+	PackageNpm                          // 1
+	PackageMaven                        // 2
+	PackagePyPI                         // 3
+	PackageContainer                    // 4
+)
+
+// PackageTypes contains all package types in display order
+var PackageTypes = []PackageType{
+	PackageGeneric,
+	PackageNpm,
+	PackageMaven,
+	PackagePyPI,
+	PackageContainer,
+}
+
+// Name gets the name of the package type
+func (pt PackageType) Name() string {
+	switch pt {
+	case PackageGeneric:
+		return "generic"
+	case PackageNpm:
+		return "npm"
+	case PackageMaven:
+		return "maven"
+	case PackagePyPI:
+		return "pypi"
+	case PackageContainer:
+		return "container"
+	}
+	panic(fmt.Sprintf("unknown package type: %d", int(pt)))
+}
+
+// String implements fmt.Stringer
+func (pt PackageType) String() string {
+	return pt.Name()
+}
+
+// PackageTypeFromName returns the package type with the given name
+func PackageTypeFromName(name string) (PackageType, bool) {
+	for _, pt := range PackageTypes {
+		if pt.Name() == strings.ToLower(name) {
+			return pt, true
+		}
+	}
+	return 0, false
+}
+
+// Package represents a package owned by a user or an organization
+type Package struct {
+	ID        int64       `xorm:"pk autoincr"`
+	OwnerID   int64       `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Owner     *User       `xorm:"-"`
+	RepoID    int64       `xorm:"INDEX"`
+	Repo      *Repository `xorm:"-"`
+	Type      PackageType `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name      string      `xorm:"NOT NULL"`
+	LowerName string      `xorm:"UNIQUE(s) INDEX NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LoadAttributes loads the owner and the linked repository of the package
+func (p *Package) LoadAttributes() error {
+	return p.loadAttributes(x)
+}
+
+func (p *Package) loadAttributes(e Engine) (err error) {
+	if p.Owner == nil {
+		if p.Owner, err = getUserByID(e, p.OwnerID); err != nil {
+			return err
+		}
+	}
+	if p.Repo == nil && p.RepoID > 0 {
+		if p.Repo, err = getRepositoryByID(e, p.RepoID); err != nil && !IsErrRepoNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPackageByNameContext gets a package by name in the given context
+func GetPackageByNameContext(ctx DBContext, ownerID int64, packageType PackageType, name string) (*Package, error) {
+	return getPackageByName(ctx.e, ownerID, packageType, name)
+}
+
+func getPackageByName(e Engine, ownerID int64, packageType PackageType, name string) (*Package, error) {
+	p := &Package{}
+	has, err := e.
+		Where("owner_id = ? AND type = ? AND lower_name = ?", ownerID, packageType, strings.ToLower(name)).
+		Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageNotExist
+	}
+	return p, nil
+}
+
+// GetPackageByName gets a package by name
+func GetPackageByName(ownerID int64, packageType PackageType, name string) (*Package, error) {
+	return getPackageByName(x, ownerID, packageType, name)
+}
+
+// GetPackageByID gets a package by id
+func GetPackageByID(packageID int64) (*Package, error) {
+	p := &Package{}
+	has, err := x.ID(packageID).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageNotExist
+	}
+	return p, nil
+}
+
+// TryInsertPackage inserts a package. If a package with the same name exists, that package is returned.
+func TryInsertPackage(ctx DBContext, p *Package) (*Package, error) {
+	e := ctx.e
+	existing, err := getPackageByName(e, p.OwnerID, p.Type, p.Name)
+	if err == nil {
+		return existing, ErrPackageAlreadyExist
+	} else if err != ErrPackageNotExist {
+		return nil, err
+	}
+
+	p.LowerName = strings.ToLower(p.Name)
+	if _, err := e.Insert(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPackageRepositoryLink sets the linked repository of a package, repoID 0 removes the link
+func SetPackageRepositoryLink(packageID, repoID int64) error {
+	_, err := x.ID(packageID).Cols("repo_id").Update(&Package{RepoID: repoID})
+	return err
+}
+
+// unlinkRepositoryFromAllPackages unlinks every package from the repository
+func unlinkRepositoryFromAllPackages(e Engine, repoID int64) error {
+	_, err := e.Where("repo_id = ?", repoID).Cols("repo_id").Update(&Package{})
+	return err
+}
+
+// deletePackageByIDIfUnused deletes a package if it has no versions left
+func deletePackageByIDIfUnused(e Engine, packageID int64) error {
+	count, err := e.Where("package_id = ?", packageID).Count(&PackageVersion{})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = e.ID(packageID).Delete(&Package{})
+	return err
+}
+
+// PackageSearchOptions are options for SearchPackages
+type PackageSearchOptions struct {
+	ListOptions
+	OwnerID int64
+	RepoID  int64
+	Type    *PackageType
+	Keyword string
+}
+
+func (opts *PackageSearchOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.Type != nil {
+		cond = cond.And(builder.Eq{"type": *opts.Type})
+	}
+	if opts.Keyword != "" {
+		cond = cond.And(builder.Like{"lower_name", strings.ToLower(opts.Keyword)})
+	}
+	return cond
+}
+
+// SearchPackages searches packages by the given options
+func SearchPackages(opts *PackageSearchOptions) ([]*Package, int64, error) {
+	sess := x.Where(opts.toConds()).OrderBy("lower_name ASC")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+
+	packages := make([]*Package, 0, 10)
+	count, err := sess.FindAndCount(&packages)
+	return packages, count, err
+}
+
+// PackageAccessMode returns the access mode a user has on the packages of an owner.
+// Packages inherit the visibility of their owner: everyone who can see the owner
+// can read its packages, while only the owner (or the owners of an organization
+// and members of teams with write access) may publish and delete packages.
+func PackageAccessMode(doer, owner *User) (AccessMode, error) {
+	if doer != nil && doer.IsAdmin {
+		return AccessModeOwner, nil
+	}
+
+	if owner.IsOrganization() {
+		if doer != nil {
+			isOwner, err := owner.IsOwnedBy(doer.ID)
+			if err != nil {
+				return AccessModeNone, err
+			} else if isOwner {
+				return AccessModeOwner, nil
+			}
+
+			teams, err := owner.GetUserTeams(doer.ID)
+			if err != nil {
+				return AccessModeNone, err
+			}
+			mode := AccessModeNone
+			for _, t := range teams {
+				if t.Authorize > mode {
+					mode = t.Authorize
+				}
+			}
+			if len(teams) > 0 && mode < AccessModeRead {
+				mode = AccessModeRead
+			}
+			if mode > AccessModeNone {
+				return mode, nil
+			}
+		}
+		if HasOrgVisible(owner, doer) {
+			return AccessModeRead, nil
+		}
+		return AccessModeNone, nil
+	}
+
+	if doer != nil && doer.ID == owner.ID {
+		return AccessModeOwner, nil
+	}
+	switch owner.Visibility {
+	case structs.VisibleTypePublic:
+		return AccessModeRead, nil
+	case structs.VisibleTypeLimited:
+		if doer != nil && !doer.IsRestricted {
+			return AccessModeRead, nil
+		}
+	}
+	return AccessModeNone, nil
+}
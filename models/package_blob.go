@@ -0,0 +1,122 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ErrPackageBlobNotExist indicates a package blob not exist error
+var ErrPackageBlobNotExist = errors.New("Package blob does not exist")
+
+// PackageBlob represents the content of package files.
+// Blobs are deduplicated by their SHA256 hash and stored in storage.Packages.
+type PackageBlob struct {
+	ID         int64  `xorm:"pk autoincr"`
+	Size       int64  `xorm:"NOT NULL DEFAULT 0"`
+	HashMD5    string `xorm:"hash_md5 char(32) UNIQUE(md5) INDEX NOT NULL"`
+	HashSHA1   string `xorm:"hash_sha1 char(40) UNIQUE(sha1) INDEX NOT NULL"`
+	HashSHA256 string `xorm:"hash_sha256 char(64) UNIQUE(sha256) INDEX NOT NULL"`
+	HashSHA512 string `xorm:"hash_sha512 char(128) UNIQUE(sha512) INDEX NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created NOT NULL"`
+}
+
+// GetPackageBlobByID gets a blob by id
+func GetPackageBlobByID(blobID int64) (*PackageBlob, error) {
+	pb := &PackageBlob{}
+	has, err := x.ID(blobID).Get(pb)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageBlobNotExist
+	}
+	return pb, nil
+}
+
+// GetPackageBlobByHash gets a blob by its SHA256 hash
+func GetPackageBlobByHash(hashSHA256 string) (*PackageBlob, error) {
+	pb := &PackageBlob{}
+	has, err := x.Where("hash_sha256 = ?", hashSHA256).Get(pb)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageBlobNotExist
+	}
+	return pb, nil
+}
+
+// GetOrInsertPackageBlob inserts a blob. If the blob exists already, the existing blob is returned.
+func GetOrInsertPackageBlob(ctx DBContext, pb *PackageBlob) (*PackageBlob, bool, error) {
+	e := ctx.e
+	existing := &PackageBlob{}
+	has, err := e.Where("hash_sha256 = ?", pb.HashSHA256).Get(existing)
+	if err != nil {
+		return nil, false, err
+	} else if has {
+		return existing, true, nil
+	}
+	if _, err := e.Insert(pb); err != nil {
+		return nil, false, err
+	}
+	return pb, false, nil
+}
+
+// FindUnreferencedPackageBlobs gets all blobs created before olderThan which are not referenced by a package file
+func FindUnreferencedPackageBlobs(olderThan timeutil.TimeStamp) ([]*PackageBlob, error) {
+	blobs := make([]*PackageBlob, 0, 10)
+	return blobs, x.
+		Where(builder.NotIn("id", builder.Select("blob_id").From("package_file"))).
+		And("created_unix < ?", olderThan).
+		Find(&blobs)
+}
+
+// GetPackageBlobOfPackage gets a blob by its SHA256 hash if it is referenced by a file of the package
+func GetPackageBlobOfPackage(packageID int64, hashSHA256 string) (*PackageBlob, error) {
+	pb := &PackageBlob{}
+	has, err := x.
+		Join("INNER", "package_file", "package_file.blob_id = package_blob.id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Where("package_version.package_id = ? AND package_blob.hash_sha256 = ?", packageID, hashSHA256).
+		Get(pb)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageBlobNotExist
+	}
+	return pb, nil
+}
+
+// GetPackageBlobOfOwner gets a blob by its SHA256 hash if it is referenced by a package of the owner
+func GetPackageBlobOfOwner(ownerID int64, hashSHA256 string) (*PackageBlob, error) {
+	pb := &PackageBlob{}
+	has, err := x.
+		Join("INNER", "package_file", "package_file.blob_id = package_blob.id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where("package.owner_id = ? AND package_blob.hash_sha256 = ?", ownerID, hashSHA256).
+		Get(pb)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageBlobNotExist
+	}
+	return pb, nil
+}
+
+// IsPackageBlobReferenced returns true if a package file references the blob
+func IsPackageBlobReferenced(blobID int64) (bool, error) {
+	return x.Where("blob_id = ?", blobID).Exist(&PackageFile{})
+}
+
+// DeletePackageBlobByID deletes a blob record, the content must be removed by the caller
+func DeletePackageBlobByID(blobID int64) error {
+	_, err := x.ID(blobID).Delete(&PackageBlob{})
+	return err
+}
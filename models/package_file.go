@@ -0,0 +1,82 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+var (
+	// ErrPackageFileNotExist indicates a package file not exist error
+	ErrPackageFileNotExist = errors.New("Package file does not exist")
+	// ErrPackageFileAlreadyExist indicates a package file already exists error
+	ErrPackageFileAlreadyExist = errors.New("Package file already exists")
+)
+
+// PackageFile represents a file of a package version, the content is stored as a PackageBlob
+type PackageFile struct {
+	ID        int64        `xorm:"pk autoincr"`
+	VersionID int64        `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	BlobID    int64        `xorm:"INDEX NOT NULL"`
+	Blob      *PackageBlob `xorm:"-"`
+	Name      string       `xorm:"NOT NULL"`
+	LowerName string       `xorm:"UNIQUE(s) INDEX NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LoadBlob loads the blob of the file
+func (pf *PackageFile) LoadBlob() (err error) {
+	if pf.Blob == nil {
+		pf.Blob, err = GetPackageBlobByID(pf.BlobID)
+	}
+	return err
+}
+
+// GetPackageFileByName gets a file of a package version by name
+func GetPackageFileByName(versionID int64, name string) (*PackageFile, error) {
+	pf := &PackageFile{}
+	has, err := x.
+		Where("version_id = ? AND lower_name = ?", versionID, strings.ToLower(name)).
+		Get(pf)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageFileNotExist
+	}
+	return pf, nil
+}
+
+// GetPackageFilesByVersionID gets all files of a package version
+func GetPackageFilesByVersionID(versionID int64) ([]*PackageFile, error) {
+	files := make([]*PackageFile, 0, 10)
+	return files, x.Where("version_id = ?", versionID).OrderBy("lower_name ASC").Find(&files)
+}
+
+// InsertPackageFile inserts a file of a package version
+func InsertPackageFile(ctx DBContext, pf *PackageFile) error {
+	e := ctx.e
+	has, err := e.
+		Where("version_id = ? AND lower_name = ?", pf.VersionID, strings.ToLower(pf.Name)).
+		Exist(&PackageFile{})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrPackageFileAlreadyExist
+	}
+
+	pf.LowerName = strings.ToLower(pf.Name)
+	_, err = e.Insert(pf)
+	return err
+}
+
+// DeletePackageFile deletes a single file of a package version
+func DeletePackageFile(pf *PackageFile) error {
+	_, err := x.ID(pf.ID).Delete(&PackageFile{})
+	return err
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageAccessMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	org3 := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	limitedOrg := AssertExistsAndLoadBean(t, &User{ID: 22}).(*User)
+	privateOrg := AssertExistsAndLoadBean(t, &User{ID: 23}).(*User)
+
+	test := func(doer, owner *User, expected AccessMode) {
+		mode, err := PackageAccessMode(doer, owner)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+
+	test(admin, user2, AccessModeOwner)
+	test(user2, user2, AccessModeOwner)
+	test(user5, user2, AccessModeRead)
+	test(nil, user2, AccessModeRead)
+
+	test(user2, org3, AccessModeOwner)
+	test(user4, org3, AccessModeWrite)
+	test(user5, org3, AccessModeRead)
+	test(nil, org3, AccessModeRead)
+
+	test(user5, limitedOrg, AccessModeRead)
+	test(nil, limitedOrg, AccessModeNone)
+	test(user5, privateOrg, AccessModeNone)
+	test(admin, privateOrg, AccessModeOwner)
+}
+
+func TestSearchPackages(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	packages, count, err := SearchPackages(&PackageSearchOptions{OwnerID: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, packages, 1) {
+		assert.Equal(t, "test-package", packages[0].Name)
+	}
+
+	npm := PackageNpm
+	packages, count, err = SearchPackages(&PackageSearchOptions{Type: &npm})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, packages, 1) {
+		assert.EqualValues(t, 3, packages[0].OwnerID)
+	}
+
+	_, count, err = SearchPackages(&PackageSearchOptions{RepoID: 1, Keyword: "TEST"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestDeletePackageVersion(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pv := AssertExistsAndLoadBean(t, &PackageVersion{ID: 1}).(*PackageVersion)
+	assert.NoError(t, DeletePackageVersion(pv))
+
+	AssertNotExistsBean(t, &PackageVersion{ID: 1})
+	AssertNotExistsBean(t, &PackageFile{VersionID: 1})
+	AssertNotExistsBean(t, &Package{ID: 1})
+
+	// the blob is still used by another file, the empty blob is not used at all
+	blobs, err := FindUnreferencedPackageBlobs(timeutil.TimeStampNow())
+	assert.NoError(t, err)
+	if assert.Len(t, blobs, 1) {
+		assert.EqualValues(t, 2, blobs[0].ID)
+	}
+}
+
+func TestDeletePackageVersionContext(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pv := AssertExistsAndLoadBean(t, &PackageVersion{ID: 1}).(*PackageVersion)
+	assert.NoError(t, WithTx(func(ctx DBContext) error {
+		return DeletePackageVersionContext(ctx, pv)
+	}))
+
+	AssertNotExistsBean(t, &PackageVersion{ID: 1})
+	AssertNotExistsBean(t, &PackageFile{VersionID: 1})
+	AssertExistsAndLoadBean(t, &Package{ID: 1})
+}
+
+func TestTryInsertPackageVersion(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	ctx := DefaultDBContext()
+	pv, err := TryInsertPackageVersion(ctx, &PackageVersion{PackageID: 1, Version: "1.0.0"})
+	assert.Equal(t, ErrPackageVersionAlreadyExist, err)
+	assert.EqualValues(t, 1, pv.ID)
+
+	pv, err = TryInsertPackageVersion(ctx, &PackageVersion{PackageID: 1, Version: "2.0.0-RC"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0-rc", pv.LowerVersion)
+
+	versions, err := GetPackageVersionsByPackageID(1)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+}
+
+func TestGetPackageBlobOfOwner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	const hashSHA256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	pb, err := GetPackageBlobOfOwner(3, hashSHA256)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, pb.ID)
+
+	_, err = GetPackageBlobOfOwner(4, hashSHA256)
+	assert.Equal(t, ErrPackageBlobNotExist, err)
+
+	referenced, err := IsPackageBlobReferenced(1)
+	assert.NoError(t, err)
+	assert.True(t, referenced)
+	referenced, err = IsPackageBlobReferenced(2)
+	assert.NoError(t, err)
+	assert.False(t, referenced)
+}
+
+func TestGetPackageBlobOfPackage(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	const hashSHA256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	pb, err := GetPackageBlobOfPackage(1, hashSHA256)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, pb.ID)
+	assert.EqualValues(t, 4, pb.Size)
+
+	_, err = GetPackageBlobOfPackage(1, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	assert.Equal(t, ErrPackageBlobNotExist, err)
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+var (
+	// ErrPackageVersionNotExist indicates a package version not exist error
+	ErrPackageVersionNotExist = errors.New("Package version does not exist")
+	// ErrPackageVersionAlreadyExist indicates a package version already exists error
+	ErrPackageVersionAlreadyExist = errors.New("Package version already exists")
+)
+
+// PackageVersion represents a single version of a package
+type PackageVersion struct {
+	ID            int64  `xorm:"pk autoincr"`
+	PackageID     int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatorID     int64  `xorm:"NOT NULL DEFAULT 0"`
+	Creator       *User  `xorm:"-"`
+	Version       string `xorm:"NOT NULL"`
+	LowerVersion  string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	MetadataJSON  string `xorm:"metadata_json TEXT"`
+	DownloadCount int64  `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LoadCreator loads the creator of the version
+func (pv *PackageVersion) LoadCreator() (err error) {
+	if pv.Creator == nil {
+		pv.Creator, err = getUserByID(x, pv.CreatorID)
+		if IsErrUserNotExist(err) {
+			pv.Creator = NewGhostUser()
+			err = nil
+		}
+	}
+	return err
+}
+
+// GetPackageVersionByNameContext gets a version of a package by its version string in the given context
+func GetPackageVersionByNameContext(ctx DBContext, packageID int64, version string) (*PackageVersion, error) {
+	return getPackageVersionByName(ctx.e, packageID, version)
+}
+
+func getPackageVersionByName(e Engine, packageID int64, version string) (*PackageVersion, error) {
+	pv := &PackageVersion{}
+	has, err := e.
+		Where("package_id = ? AND lower_version = ?", packageID, strings.ToLower(version)).
+		Get(pv)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageVersionNotExist
+	}
+	return pv, nil
+}
+
+// GetPackageVersionByName gets a version of a package by its version string
+func GetPackageVersionByName(packageID int64, version string) (*PackageVersion, error) {
+	return getPackageVersionByName(x, packageID, version)
+}
+
+// GetPackageVersionByID gets a package version by id
+func GetPackageVersionByID(versionID int64) (*PackageVersion, error) {
+	pv := &PackageVersion{}
+	has, err := x.ID(versionID).Get(pv)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPackageVersionNotExist
+	}
+	return pv, nil
+}
+
+// GetPackageVersionsByPackageID returns all versions of a package, newest first
+func GetPackageVersionsByPackageID(packageID int64) ([]*PackageVersion, error) {
+	versions := make([]*PackageVersion, 0, 10)
+	return versions, x.
+		Where("package_id = ?", packageID).
+		OrderBy("created_unix DESC").
+		Find(&versions)
+}
+
+// CountPackageVersions returns the number of versions of the packages
+func CountPackageVersions(packageIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(packageIDs))
+	if len(packageIDs) == 0 {
+		return counts, nil
+	}
+
+	type versionCount struct {
+		PackageID int64
+		Count     int64
+	}
+	rows := make([]*versionCount, 0, len(packageIDs))
+	if err := x.Table("package_version").
+		Select("package_id, count(*) AS count").
+		Where(builder.In("package_id", packageIDs)).
+		GroupBy("package_id").
+		Find(&rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.PackageID] = row.Count
+	}
+	return counts, nil
+}
+
+// TryInsertPackageVersion inserts a version. If a version with the same name exists, that version is returned.
+func TryInsertPackageVersion(ctx DBContext, pv *PackageVersion) (*PackageVersion, error) {
+	e := ctx.e
+	existing, err := getPackageVersionByName(e, pv.PackageID, pv.Version)
+	if err == nil {
+		return existing, ErrPackageVersionAlreadyExist
+	} else if err != ErrPackageVersionNotExist {
+		return nil, err
+	}
+
+	pv.LowerVersion = strings.ToLower(pv.Version)
+	if _, err := e.Insert(pv); err != nil {
+		return nil, err
+	}
+	return pv, nil
+}
+
+// UpdatePackageVersion updates the metadata of a package version
+func UpdatePackageVersion(pv *PackageVersion) error {
+	_, err := x.ID(pv.ID).Cols("metadata_json").Update(pv)
+	return err
+}
+
+// IncrementPackageVersionDownloadCount increments the download counter of a version
+func IncrementPackageVersionDownloadCount(versionID int64) error {
+	_, err := x.Exec("UPDATE `package_version` SET download_count = download_count + 1 WHERE id = ?", versionID)
+	return err
+}
+
+// DeletePackageVersion deletes a package version with its files.
+// The package itself is removed if this was the last version.
+// Blobs are not removed, they are cleaned up once they are unreferenced.
+func DeletePackageVersion(pv *PackageVersion) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := deletePackageVersion(sess, pv); err != nil {
+		return err
+	}
+	if err := deletePackageByIDIfUnused(sess, pv.PackageID); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// DeletePackageVersionContext deletes a package version with its files in the given context.
+// Unlike DeletePackageVersion it keeps the package, even if it has no versions left.
+func DeletePackageVersionContext(ctx DBContext, pv *PackageVersion) error {
+	return deletePackageVersion(ctx.e, pv)
+}
+
+func deletePackageVersion(e Engine, pv *PackageVersion) error {
+	if _, err := e.Delete(&PackageFile{VersionID: pv.ID}); err != nil {
+		return err
+	}
+	_, err := e.ID(pv.ID).Delete(&PackageVersion{})
+	return err
+}
+
+// DeletePackage deletes a package with all its versions and files
+func DeletePackage(p *Package) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := deletePackage(sess, p.ID); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+func deletePackage(e Engine, packageID int64) error {
+	if _, err := e.
+		Where(builder.In("version_id", builder.Select("id").From("package_version").Where(builder.Eq{"package_id": packageID}))).
+		Delete(&PackageFile{}); err != nil {
+		return err
+	}
+	if _, err := e.Delete(&PackageVersion{PackageID: packageID}); err != nil {
+		return err
+	}
+	_, err := e.ID(packageID).Delete(&Package{})
+	return err
+}
+
+// deletePackagesByOwnerID deletes all packages of an owner
+func deletePackagesByOwnerID(e Engine, ownerID int64) error {
+	packageIDs := make([]int64, 0, 10)
+	if err := e.Table("package").Where("owner_id = ?", ownerID).Cols("id").Find(&packageIDs); err != nil {
+		return err
+	}
+	for _, id := range packageIDs {
+		if err := deletePackage(e, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
@@ -43,18 +45,141 @@ type Project struct {
 	Title       string `xorm:"INDEX NOT NULL"`
 	Description string `xorm:"TEXT"`
 	RepoID      int64  `xorm:"INDEX"`
+	OwnerID     int64  `xorm:"INDEX"`
 	CreatorID   int64  `xorm:"NOT NULL"`
 	IsClosed    bool   `xorm:"INDEX"`
 	BoardType   ProjectBoardType
 	Type        ProjectType
 
-	RenderedContent string `xorm:"-"`
+	RenderedContent string      `xorm:"-"`
+	Creator         *User       `xorm:"-"`
+	Owner           *User       `xorm:"-"`
+	Repo            *Repository `xorm:"-"`
 
 	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
 	ClosedDateUnix timeutil.TimeStamp
 }
 
+// LoadCreator loads the user who created the project
+func (p *Project) LoadCreator() (err error) {
+	if p.Creator != nil {
+		return nil
+	}
+	p.Creator, err = getUserByID(x, p.CreatorID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			p.Creator = NewGhostUser()
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// LoadOwner loads the user or organization owning an individual or organization project
+func (p *Project) LoadOwner() (err error) {
+	if p.Owner != nil || p.OwnerID == 0 {
+		return nil
+	}
+	p.Owner, err = getUserByID(x, p.OwnerID)
+	return err
+}
+
+// LoadRepo loads the repository of a repository project
+func (p *Project) LoadRepo() (err error) {
+	if p.Repo != nil || p.RepoID == 0 {
+		return nil
+	}
+	p.Repo, err = getRepositoryByID(x, p.RepoID)
+	return err
+}
+
+// IsOwnerProject returns true if the project belongs to a user or an organization instead of a repository
+func (p *Project) IsOwnerProject() bool {
+	return p.Type == ProjectTypeIndividual || p.Type == ProjectTypeOrganization
+}
+
+// Link returns the full link to the project
+func (p *Project) Link() string {
+	if p.IsOwnerProject() {
+		if err := p.LoadOwner(); err != nil {
+			log.Error("LoadOwner: %v", err)
+			return ""
+		}
+		return fmt.Sprintf("%s/-/projects/%d", p.Owner.HomeLink(), p.ID)
+	}
+	if err := p.LoadRepo(); err != nil {
+		log.Error("LoadRepo: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("%s/projects/%d", p.Repo.Link(), p.ID)
+}
+
+// HTMLURL returns the absolute URL to the project
+func (p *Project) HTMLURL() string {
+	if p.IsOwnerProject() {
+		if err := p.LoadOwner(); err != nil {
+			log.Error("LoadOwner: %v", err)
+			return ""
+		}
+		return fmt.Sprintf("%s/-/projects/%d", p.Owner.HTMLURL(), p.ID)
+	}
+	if err := p.LoadRepo(); err != nil {
+		log.Error("LoadRepo: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("%s/projects/%d", p.Repo.HTMLURL(), p.ID)
+}
+
+// AccessModeOfOwnerProjects returns the access the doer has to the projects of a user or an organization.
+// Organization members get the highest access of their teams having the projects unit enabled.
+func AccessModeOfOwnerProjects(owner, doer *User) (AccessMode, error) {
+	return accessModeOfOwnerProjects(x, owner, doer)
+}
+
+func accessModeOfOwnerProjects(e Engine, owner, doer *User) (AccessMode, error) {
+	if doer != nil && (doer.IsAdmin || doer.ID == owner.ID) {
+		return AccessModeOwner, nil
+	}
+
+	if !owner.IsOrganization() {
+		// only the user can see the projects of a private user, and only signed in users those of a limited one
+		switch owner.Visibility {
+		case structs.VisibleTypePrivate:
+			return AccessModeNone, nil
+		case structs.VisibleTypeLimited:
+			if doer == nil {
+				return AccessModeNone, nil
+			}
+		}
+		return AccessModeRead, nil
+	}
+
+	if !hasOrgVisible(e, owner, doer) {
+		return AccessModeNone, nil
+	}
+	if doer == nil {
+		return AccessModeRead, nil
+	}
+
+	teams, err := owner.getUserTeams(e, doer.ID)
+	if err != nil {
+		return AccessModeNone, err
+	}
+	mode := AccessModeNone
+	for _, team := range teams {
+		if team.Authorize > mode && (team.IsOwnerTeam() || team.unitEnabled(e, UnitTypeProjects)) {
+			mode = team.Authorize
+		}
+	}
+
+	if mode == AccessModeNone && owner.Visibility != structs.VisibleTypePrivate {
+		mode = AccessModeRead
+	}
+	return mode, nil
+}
+
 // GetProjectsConfig retrieves the types of configurations projects could have
 func GetProjectsConfig() []ProjectsConfig {
 	return []ProjectsConfig{
@@ -67,7 +192,7 @@ func GetProjectsConfig() []ProjectsConfig {
 // IsProjectTypeValid checks if a project type is valid
 func IsProjectTypeValid(p ProjectType) bool {
 	switch p {
-	case ProjectTypeRepository:
+	case ProjectTypeIndividual, ProjectTypeRepository, ProjectTypeOrganization:
 		return true
 	default:
 		return false
@@ -77,21 +202,25 @@ func IsProjectTypeValid(p ProjectType) bool {
 // ProjectSearchOptions are options for GetProjects
 type ProjectSearchOptions struct {
 	RepoID   int64
+	OwnerID  int64
 	Page     int
+	PageSize int
 	IsClosed util.OptionalBool
 	SortType string
 	Type     ProjectType
 }
 
-// GetProjects returns a list of all projects that have been created in the repository
+// GetProjects returns a list of all projects that have been created in the repository,
+// or by the user or organization if OwnerID is set
 func GetProjects(opts ProjectSearchOptions) ([]*Project, int64, error) {
 	return getProjects(x, opts)
 }
 
-func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error) {
-	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
-
+func (opts ProjectSearchOptions) toConds() builder.Cond {
 	var cond builder.Cond = builder.Eq{"repo_id": opts.RepoID}
+	if opts.OwnerID > 0 {
+		cond = builder.Eq{"owner_id": opts.OwnerID}
+	}
 	switch opts.IsClosed {
 	case util.OptionalBoolTrue:
 		cond = cond.And(builder.Eq{"is_closed": true})
@@ -102,7 +231,18 @@ func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error)
 	if opts.Type > 0 {
 		cond = cond.And(builder.Eq{"type": opts.Type})
 	}
+	return cond
+}
 
+// CountProjects counts the projects matching the options
+func CountProjects(opts ProjectSearchOptions) (int64, error) {
+	return x.Where(opts.toConds()).Count(new(Project))
+}
+
+func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error) {
+	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
+
+	cond := opts.toConds()
 	count, err := e.Where(cond).Count(new(Project))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Count: %v", err)
@@ -111,7 +251,11 @@ func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error)
 	e = e.Where(cond)
 
 	if opts.Page > 0 {
-		e = e.Limit(setting.UI.IssuePagingNum, (opts.Page-1)*setting.UI.IssuePagingNum)
+		pageSize := opts.PageSize
+		if pageSize <= 0 {
+			pageSize = setting.UI.IssuePagingNum
+		}
+		e = e.Limit(pageSize, (opts.Page-1)*pageSize)
 	}
 
 	switch opts.SortType {
@@ -149,8 +293,10 @@ func NewProject(p *Project) error {
 		return err
 	}
 
-	if _, err := sess.Exec("UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
-		return err
+	if p.Type == ProjectTypeRepository {
+		if _, err := sess.Exec("UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
+			return err
+		}
 	}
 
 	if err := createBoardsForProjectsType(sess, p); err != nil {
@@ -178,6 +324,34 @@ func getProjectByID(e Engine, id int64) (*Project, error) {
 	return p, nil
 }
 
+// GetProjectByRepoID returns the project with the given id in a repository
+func GetProjectByRepoID(repoID, id int64) (*Project, error) {
+	p := new(Project)
+
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectNotExist{ID: id, RepoID: repoID}
+	}
+
+	return p, nil
+}
+
+// GetProjectByOwnerID returns the project with the given id owned by a user or an organization
+func GetProjectByOwnerID(ownerID, id int64) (*Project, error) {
+	p := new(Project)
+
+	has, err := x.ID(id).Where("owner_id = ?", ownerID).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has || !p.IsOwnerProject() {
+		return nil, ErrProjectNotExist{ID: id}
+	}
+
+	return p, nil
+}
+
 // UpdateProject updates project properties
 func UpdateProject(p *Project) error {
 	return updateProject(x, p)
@@ -259,7 +433,7 @@ func changeProjectStatus(e Engine, p *Project, isClosed bool) error {
 	if err != nil {
 		return err
 	}
-	if count < 1 {
+	if count < 1 || p.Type != ProjectTypeRepository {
 		return nil
 	}
 
@@ -302,5 +476,22 @@ func deleteProjectByID(e Engine, id int64) error {
 		return err
 	}
 
+	if p.Type != ProjectTypeRepository {
+		return nil
+	}
 	return updateRepositoryProjectCount(e, p.RepoID)
 }
+
+// deleteProjectsByOwnerID deletes all projects of a user or an organization
+func deleteProjectsByOwnerID(e Engine, ownerID int64) error {
+	projectIDs := make([]int64, 0, 10)
+	if err := e.Table("project").Where("owner_id = ?", ownerID).Cols("id").Find(&projectIDs); err != nil {
+		return err
+	}
+	for _, id := range projectIDs {
+		if err := deleteProjectByID(e, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
@@ -19,9 +21,9 @@ func TestIsProjectTypeValid(t *testing.T) {
 		typ   ProjectType
 		valid bool
 	}{
-		{ProjectTypeIndividual, false},
+		{ProjectTypeIndividual, true},
 		{ProjectTypeRepository, true},
-		{ProjectTypeOrganization, false},
+		{ProjectTypeOrganization, true},
 		{UnknownType, false},
 	}
 
@@ -46,6 +48,62 @@ func TestGetProjects(t *testing.T) {
 	assert.Len(t, projects, 1)
 }
 
+func TestGetProjectsByOwner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	projects, _, err := GetProjects(ProjectSearchOptions{OwnerID: 3})
+	assert.NoError(t, err)
+	assert.Len(t, projects, 1)
+	assert.Equal(t, "organization project", projects[0].Title)
+	assert.Equal(t, setting.AppSubURL+"/user3/-/projects/4", projects[0].Link())
+
+	_, err = GetProjectByOwnerID(3, 4)
+	assert.NoError(t, err)
+	_, err = GetProjectByOwnerID(2, 4)
+	assert.True(t, IsErrProjectNotExist(err))
+}
+
+func TestAccessModeOfOwnerProjects(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	test := func(owner, doer *User, expected AccessMode) {
+		mode, err := AccessModeOfOwnerProjects(owner, doer)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+
+	// owner team
+	test(org, user2, AccessModeOwner)
+	// team1 doesn't have the projects unit, the organization is public though
+	test(org, user4, AccessModeRead)
+	test(org, nil, AccessModeRead)
+
+	_, err := x.Insert(&TeamUnit{OrgID: org.ID, TeamID: 2, Type: UnitTypeProjects})
+	assert.NoError(t, err)
+	test(org, user4, AccessModeWrite)
+
+	org.Visibility = structs.VisibleTypePrivate
+	test(org, nil, AccessModeNone)
+	test(org, user4, AccessModeWrite)
+
+	test(user2, user2, AccessModeOwner)
+	test(user2, user4, AccessModeRead)
+	test(user2, nil, AccessModeRead)
+
+	user2.Visibility = structs.VisibleTypeLimited
+	test(user2, user4, AccessModeRead)
+	test(user2, nil, AccessModeNone)
+
+	user2.Visibility = structs.VisibleTypePrivate
+	test(user2, user2, AccessModeOwner)
+	test(user2, user4, AccessModeNone)
+	test(user2, nil, AccessModeNone)
+}
+
 func TestProject(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -80,3 +138,39 @@ func TestProject(t *testing.T) {
 
 	assert.True(t, projectFromDB.IsClosed)
 }
+
+func TestGetProjectByRepoID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	project, err := GetProjectByRepoID(1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, project.RepoID)
+
+	assert.NoError(t, project.LoadCreator())
+	assert.EqualValues(t, 2, project.Creator.ID)
+
+	_, err = GetProjectByRepoID(3, 1)
+	assert.True(t, IsErrProjectNotExist(err))
+}
+
+func TestGetProjectsPageSize(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, NewProject(&Project{
+			Type:      ProjectTypeRepository,
+			Title:     "Paged project",
+			RepoID:    1,
+			CreatorID: 2,
+		}))
+	}
+
+	projects, count, err := GetProjects(ProjectSearchOptions{RepoID: 1, Page: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Len(t, projects, 2)
+
+	projects, _, err = GetProjects(ProjectSearchOptions{RepoID: 1, Page: 2, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, projects, 1)
+}
@@ -351,6 +351,8 @@ const (
 	MergeStyleSquash MergeStyle = "squash"
 	// MergeStyleManuallyMerged pr has been merged manually, just mark it as merged directly
 	MergeStyleManuallyMerged MergeStyle = "manually-merged"
+	// MergeStyleFastForwardOnly fast-forward the base branch to the head without creating any commit (--ff-only)
+	MergeStyleFastForwardOnly MergeStyle = "fast-forward-only"
 )
 
 // SetMerged sets a pull request to merged and closes the corresponding issue
@@ -0,0 +1,139 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// PullAutoMerge represents a pull request scheduled to be merged once all its checks succeed
+type PullAutoMerge struct {
+	ID          int64              `xorm:"pk autoincr"`
+	PullID      int64              `xorm:"UNIQUE"`
+	DoerID      int64              `xorm:"NOT NULL"`
+	Doer        *User              `xorm:"-"`
+	MergeStyle  MergeStyle         `xorm:"varchar(30)"`
+	Message     string             `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// LoadDoer loads the user who scheduled the auto merge
+func (m *PullAutoMerge) LoadDoer() (err error) {
+	if m.Doer != nil {
+		return nil
+	}
+	m.Doer, err = getUserByID(x, m.DoerID)
+	if IsErrUserNotExist(err) {
+		m.Doer = NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// GetPullAutoMergeByPullID returns the scheduled auto merge of a pull request
+func GetPullAutoMergeByPullID(pullID int64) (*PullAutoMerge, error) {
+	return getPullAutoMergeByPullID(x, pullID)
+}
+
+func getPullAutoMergeByPullID(e Engine, pullID int64) (*PullAutoMerge, error) {
+	m := new(PullAutoMerge)
+	has, err := e.Where("pull_id = ?", pullID).Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPullAutoMergeNotExist{PullID: pullID}
+	}
+	return m, nil
+}
+
+// GetPullAutoMergesByBaseRepoID returns the scheduled auto merges of the open pull requests
+// targeting the given repository
+func GetPullAutoMergesByBaseRepoID(repoID int64) ([]*PullAutoMerge, error) {
+	merges := make([]*PullAutoMerge, 0, 5)
+	return merges, x.
+		Join("INNER", "pull_request", "pull_request.id = pull_auto_merge.pull_id").
+		Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Where("pull_request.base_repo_id = ? AND pull_request.has_merged = ? AND issue.is_closed = ?", repoID, false, false).
+		Find(&merges)
+}
+
+// ScheduleAutoMerge schedules a pull request to be merged with the given style and message
+// once all its checks succeed, and records it on the timeline
+func ScheduleAutoMerge(doer *User, pr *PullRequest, style MergeStyle, message string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := getPullAutoMergeByPullID(sess, pr.ID); err == nil {
+		return ErrPullAutoMergeAlreadyScheduled{PullID: pr.ID}
+	} else if !IsErrPullAutoMergeNotExist(err) {
+		return err
+	}
+
+	if _, err := sess.Insert(&PullAutoMerge{
+		PullID:     pr.ID,
+		DoerID:     doer.ID,
+		MergeStyle: style,
+		Message:    message,
+	}); err != nil {
+		return err
+	}
+
+	if err := createAutoMergeComment(sess, CommentTypePRScheduledToAutoMerge, doer, pr, ""); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// UnscheduleAutoMerge removes the scheduled auto merge of a pull request and records on the timeline
+// that it was canceled, reason explains why it was aborted if it wasn't canceled by doer
+func UnscheduleAutoMerge(doer *User, pr *PullRequest, reason string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if cnt, err := sess.Where("pull_id = ?", pr.ID).Delete(new(PullAutoMerge)); err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrPullAutoMergeNotExist{PullID: pr.ID}
+	}
+
+	if err := createAutoMergeComment(sess, CommentTypePRUnScheduledToAutoMerge, doer, pr, reason); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+func createAutoMergeComment(e *xorm.Session, typ CommentType, doer *User, pr *PullRequest, content string) error {
+	if err := pr.loadIssue(e); err != nil {
+		return err
+	}
+	if err := pr.Issue.loadRepo(e); err != nil {
+		return err
+	}
+	_, err := createComment(e, &CreateCommentOptions{
+		Type:    typ,
+		Doer:    doer,
+		Repo:    pr.Issue.Repo,
+		Issue:   pr.Issue,
+		Content: content,
+	})
+	return err
+}
+
+// deletePullAutoMergeByIssueID removes the scheduled auto merge of the pull request of an issue without
+// recording it, this is used once the pull request is closed or merged
+func deletePullAutoMergeByIssueID(e Engine, issueID int64) error {
+	_, err := e.Where("pull_id IN (SELECT id FROM pull_request WHERE issue_id = ?)", issueID).Delete(new(PullAutoMerge))
+	return err
+}
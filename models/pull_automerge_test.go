@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	_, err := GetPullAutoMergeByPullID(pr.ID)
+	assert.True(t, IsErrPullAutoMergeNotExist(err))
+
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, MergeStyleSquash, "squash message"))
+	err = ScheduleAutoMerge(doer, pr, MergeStyleMerge, "")
+	assert.True(t, IsErrPullAutoMergeAlreadyScheduled(err))
+
+	scheduled, err := GetPullAutoMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, doer.ID, scheduled.DoerID)
+	assert.EqualValues(t, MergeStyleSquash, scheduled.MergeStyle)
+	assert.EqualValues(t, "squash message", scheduled.Message)
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, PosterID: doer.ID, Type: CommentTypePRScheduledToAutoMerge})
+
+	merges, err := GetPullAutoMergesByBaseRepoID(pr.BaseRepoID)
+	assert.NoError(t, err)
+	if assert.Len(t, merges, 1) {
+		assert.EqualValues(t, pr.ID, merges[0].PullID)
+	}
+
+	assert.NoError(t, UnscheduleAutoMerge(doer, pr, "The merge failed"))
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, PosterID: doer.ID, Type: CommentTypePRUnScheduledToAutoMerge, Content: "The merge failed"})
+
+	err = UnscheduleAutoMerge(doer, pr, "")
+	assert.True(t, IsErrPullAutoMergeNotExist(err))
+}
+
+func TestAutoMergeRemovedOnClose(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, MergeStyleMerge, ""))
+
+	assert.NoError(t, pr.LoadIssue())
+	_, err := pr.Issue.ChangeStatus(doer, true)
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntryStatus represents the state of a pull request in the merge queue
+type MergeQueueEntryStatus int
+
+const (
+	// MergeQueueEntryStatusWaiting the pull request waits for its merge commit to be built
+	MergeQueueEntryStatusWaiting MergeQueueEntryStatus = iota
+	// MergeQueueEntryStatusTesting the merge commit was built and waits for the required status checks
+	MergeQueueEntryStatusTesting
+)
+
+// MergeQueueEntry represents a pull request queued to be merged into a protected branch
+type MergeQueueEntry struct {
+	ID             int64                 `xorm:"pk autoincr"`
+	RepoID         int64                 `xorm:"INDEX NOT NULL"`
+	BaseBranch     string                `xorm:"NOT NULL"`
+	PullID         int64                 `xorm:"UNIQUE"`
+	DoerID         int64                 `xorm:"NOT NULL"`
+	Doer           *User                 `xorm:"-"`
+	MergeStyle     MergeStyle            `xorm:"varchar(30)"`
+	Message        string                `xorm:"LONGTEXT"`
+	Status         MergeQueueEntryStatus `xorm:"NOT NULL DEFAULT 0"`
+	MergeCommitSHA string                `xorm:"VARCHAR(40)"`
+	CreatedUnix    timeutil.TimeStamp    `xorm:"created"`
+}
+
+// LoadDoer loads the user who added the pull request to the merge queue
+func (e *MergeQueueEntry) LoadDoer() (err error) {
+	if e.Doer != nil {
+		return nil
+	}
+	e.Doer, err = getUserByID(x, e.DoerID)
+	if IsErrUserNotExist(err) {
+		e.Doer = NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of a pull request
+func GetMergeQueueEntryByPullID(pullID int64) (*MergeQueueEntry, error) {
+	return getMergeQueueEntryByPullID(x, pullID)
+}
+
+func getMergeQueueEntryByPullID(e Engine, pullID int64) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := e.Where("pull_id = ?", pullID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMergeQueueEntryNotExist{PullID: pullID}
+	}
+	return entry, nil
+}
+
+// GetMergeQueue returns the entries queued to be merged into a branch, in merge order
+func GetMergeQueue(repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 5)
+	return entries, x.
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		OrderBy("id ASC").
+		Find(&entries)
+}
+
+// GetMergeQueueHead returns the next entry to be merged into a branch, nil if the queue is empty
+func GetMergeQueueHead(repoID int64, branch string) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := x.
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		OrderBy("id ASC").
+		Get(entry)
+	if err != nil || !has {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetMergeQueueEntryByMergeCommit returns the entry whose merge commit is being tested, nil if there is none
+func GetMergeQueueEntryByMergeCommit(repoID int64, sha string) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := x.
+		Where("repo_id = ? AND merge_commit_sha = ?", repoID, sha).
+		Get(entry)
+	if err != nil || !has {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetMergeQueueEntries returns all the entries of all the merge queues
+func GetMergeQueueEntries() ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, x.OrderBy("id ASC").Find(&entries)
+}
+
+// UpdateMergeQueueEntryCols updates specific fields of a merge queue entry
+func UpdateMergeQueueEntryCols(entry *MergeQueueEntry, cols ...string) error {
+	_, err := x.ID(entry.ID).Cols(cols...).Update(entry)
+	return err
+}
+
+// AddToMergeQueue adds a pull request to the merge queue of its base branch and records it on the timeline,
+// a scheduled auto merge of the pull request is replaced by the queue entry
+func AddToMergeQueue(doer *User, pr *PullRequest, style MergeStyle, message string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := getMergeQueueEntryByPullID(sess, pr.ID); err == nil {
+		return ErrPullAlreadyInMergeQueue{PullID: pr.ID}
+	} else if !IsErrMergeQueueEntryNotExist(err) {
+		return err
+	}
+
+	if _, err := sess.Insert(&MergeQueueEntry{
+		RepoID:     pr.BaseRepoID,
+		BaseBranch: pr.BaseBranch,
+		PullID:     pr.ID,
+		DoerID:     doer.ID,
+		MergeStyle: style,
+		Message:    message,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("pull_id = ?", pr.ID).Delete(new(PullAutoMerge)); err != nil {
+		return err
+	}
+
+	if err := createAutoMergeComment(sess, CommentTypePRAddedToMergeQueue, doer, pr, ""); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue and records on the timeline
+// that it was removed, reason explains why it was ejected if it wasn't removed by doer
+func RemoveFromMergeQueue(doer *User, pr *PullRequest, reason string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if cnt, err := sess.Where("pull_id = ?", pr.ID).Delete(new(MergeQueueEntry)); err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrMergeQueueEntryNotExist{PullID: pr.ID}
+	}
+
+	if err := createAutoMergeComment(sess, CommentTypePRRemovedFromMergeQueue, doer, pr, reason); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// deleteMergeQueueEntryByIssueID removes the pull request of an issue from the merge queue without
+// recording it, this is used once the pull request is closed or merged
+func deleteMergeQueueEntryByIssueID(e Engine, issueID int64) error {
+	_, err := e.Where("pull_id IN (SELECT id FROM pull_request WHERE issue_id = ?)", issueID).Delete(new(MergeQueueEntry))
+	return err
+}
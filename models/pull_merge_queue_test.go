@@ -0,0 +1,73 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	head, err := GetMergeQueueHead(pr.BaseRepoID, pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.Nil(t, head)
+
+	assert.NoError(t, ScheduleAutoMerge(doer, pr, MergeStyleMerge, ""))
+	assert.NoError(t, AddToMergeQueue(doer, pr, MergeStyleSquash, "squash message"))
+	err = AddToMergeQueue(doer, pr, MergeStyleMerge, "")
+	assert.True(t, IsErrPullAlreadyInMergeQueue(err))
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, PosterID: doer.ID, Type: CommentTypePRAddedToMergeQueue})
+
+	head, err = GetMergeQueueHead(pr.BaseRepoID, pr.BaseBranch)
+	assert.NoError(t, err)
+	if assert.NotNil(t, head) {
+		assert.EqualValues(t, pr.ID, head.PullID)
+		assert.EqualValues(t, MergeStyleSquash, head.MergeStyle)
+		assert.EqualValues(t, "squash message", head.Message)
+		assert.EqualValues(t, MergeQueueEntryStatusWaiting, head.Status)
+
+		head.Status = MergeQueueEntryStatusTesting
+		head.MergeCommitSHA = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+		assert.NoError(t, UpdateMergeQueueEntryCols(head, "status", "merge_commit_sha"))
+	}
+
+	entry, err := GetMergeQueueEntryByMergeCommit(pr.BaseRepoID, "65f1bf27bc3bf70f64657658635e66094edbcb4d")
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.EqualValues(t, pr.ID, entry.PullID)
+		assert.EqualValues(t, MergeQueueEntryStatusTesting, entry.Status)
+	}
+
+	entries, err := GetMergeQueue(pr.BaseRepoID, pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, RemoveFromMergeQueue(doer, pr, "Required status checks failed"))
+	AssertNotExistsBean(t, &MergeQueueEntry{PullID: pr.ID})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, PosterID: doer.ID, Type: CommentTypePRRemovedFromMergeQueue, Content: "Required status checks failed"})
+
+	err = RemoveFromMergeQueue(doer, pr, "")
+	assert.True(t, IsErrMergeQueueEntryNotExist(err))
+}
+
+func TestMergeQueueEntryRemovedOnClose(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, AddToMergeQueue(doer, pr, MergeStyleMerge, ""))
+
+	assert.NoError(t, pr.LoadIssue())
+	_, err := pr.Issue.ChangeStatus(doer, true)
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &MergeQueueEntry{PullID: pr.ID})
+}
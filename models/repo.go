@@ -236,6 +236,7 @@ type Repository struct {
 	TemplateRepo                    *Repository        `xorm:"-"`
 	Size                            int64              `xorm:"NOT NULL DEFAULT 0"`
 	CodeIndexerStatus               *RepoIndexerStatus `xorm:"-"`
+	CodeIndexerBranches             []string           `xorm:"TEXT JSON"`
 	StatsIndexerStatus              *RepoIndexerStatus `xorm:"-"`
 	IsFsckEnabled                   bool               `xorm:"NOT NULL DEFAULT true"`
 	CloseIssuesViaCommitInAnyBranch bool               `xorm:"NOT NULL DEFAULT false"`
@@ -1020,7 +1021,7 @@ func GetRepoInitFile(tp, name string) ([]byte, error) {
 }
 
 var (
-	reservedRepoNames    = []string{".", ".."}
+	reservedRepoNames    = []string{".", "..", "-"}
 	reservedRepoPatterns = []string{"*.git", "*.wiki"}
 )
 
@@ -1187,6 +1188,11 @@ func RepoPath(userName, repoName string) string {
 	return filepath.Join(UserPath(userName), strings.ToLower(repoName)+".git")
 }
 
+// RepoExportPath returns the directory the exports of a repository are kept in
+func RepoExportPath(repoID int64) string {
+	return filepath.Join(setting.Migrations.DumpPath, "exports", strconv.FormatInt(repoID, 10))
+}
+
 // IncrementRepoForkNum increment repository fork number
 func IncrementRepoForkNum(ctx DBContext, repoID int64) error {
 	_, err := ctx.e.Exec("UPDATE `repository` SET num_forks=num_forks+1 WHERE id=?", repoID)
@@ -1449,12 +1455,21 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	if _, err := sess.Exec("DELETE FROM `pull_auto_merge` WHERE pull_id IN (SELECT id FROM `pull_request` WHERE base_repo_id = ?)", repoID); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&MergeQueueEntry{RepoID: repoID}); err != nil {
+		return err
+	}
+
 	if err := deleteBeans(sess,
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
 		&Watch{RepoID: repoID},
 		&Star{RepoID: repoID},
 		&Mirror{RepoID: repoID},
+		&PushMirror{RepoID: repoID},
 		&Milestone{RepoID: repoID},
 		&Release{RepoID: repoID},
 		&Collaboration{RepoID: repoID},
@@ -1469,10 +1484,15 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&LanguageStat{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&Task{RepoID: repoID},
+		&ForeignReference{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err := unlinkRepositoryFromAllPackages(sess, repoID); err != nil {
+		return err
+	}
+
 	// Delete Labels and related objects
 	if err := deleteLabelsByRepoID(sess, repoID); err != nil {
 		return err
@@ -1519,6 +1539,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 	// FIXME: Remove repository files should be executed after transaction succeed.
 	repoPath := repo.RepoPath()
 	removeAllWithNotice(sess, "Delete repository files", repoPath)
+	removeAllWithNotice(sess, "Delete repository exports", RepoExportPath(repoID))
 
 	err = repo.deleteWiki(sess)
 	if err != nil {
@@ -1659,6 +1680,10 @@ func GetUserRepositories(opts *SearchRepoOptions) ([]*Repository, int64, error)
 		cond = cond.And(builder.In("lower_name", opts.LowerNames))
 	}
 
+	if len(opts.RepoIDs) > 0 {
+		cond = cond.And(builder.In("id", opts.RepoIDs))
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 
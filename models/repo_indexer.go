@@ -21,12 +21,13 @@ const (
 )
 
 // RepoIndexerStatus status of a repo's entry in the repo indexer
-// For now, implicitly refers to default branch
+// An empty Branch refers to the default branch
 type RepoIndexerStatus struct {
 	ID          int64           `xorm:"pk autoincr"`
 	RepoID      int64           `xorm:"INDEX(s)"`
 	CommitSha   string          `xorm:"VARCHAR(40)"`
 	IndexerType RepoIndexerType `xorm:"INDEX(s) NOT NULL DEFAULT 0"`
+	Branch      string          `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
 }
 
 // GetUnindexedRepos returns repos which do not have an indexer status
@@ -37,7 +38,7 @@ func GetUnindexedRepos(indexerType RepoIndexerType, maxRepoID int64, page, pageS
 	}).And(builder.Eq{
 		"repository.is_empty": false,
 	})
-	sess := x.Table("repository").Join("LEFT OUTER", "repo_indexer_status", "repository.id = repo_indexer_status.repo_id AND repo_indexer_status.indexer_type = ?", indexerType)
+	sess := x.Table("repository").Join("LEFT OUTER", "repo_indexer_status", "repository.id = repo_indexer_status.repo_id AND repo_indexer_status.indexer_type = ? AND repo_indexer_status.branch = ?", indexerType, "")
 	if maxRepoID > 0 {
 		cond = builder.And(cond, builder.Lte{
 			"repository.id": maxRepoID,
@@ -69,7 +70,7 @@ func (repo *Repository) getIndexerStatus(e Engine, indexerType RepoIndexerType)
 		}
 	}
 	status := &RepoIndexerStatus{RepoID: repo.ID}
-	if has, err := e.Where("`indexer_type` = ?", indexerType).Get(status); err != nil {
+	if has, err := e.Where("`indexer_type` = ? AND `branch` = ?", indexerType, "").Get(status); err != nil {
 		return nil, err
 	} else if !has {
 		status.IndexerType = indexerType
@@ -117,3 +118,45 @@ func (repo *Repository) updateIndexerStatus(e Engine, indexerType RepoIndexerTyp
 func (repo *Repository) UpdateIndexerStatus(indexerType RepoIndexerType, sha string) error {
 	return repo.updateIndexerStatus(x, indexerType, sha)
 }
+
+// GetIndexerBranchStatuses returns the indexer statuses of the non-default branches of the repo
+func (repo *Repository) GetIndexerBranchStatuses(indexerType RepoIndexerType) ([]*RepoIndexerStatus, error) {
+	statuses := make([]*RepoIndexerStatus, 0, 5)
+	return statuses, x.
+		Where("`repo_id` = ? AND `indexer_type` = ? AND `branch` <> ?", repo.ID, indexerType, "").
+		Find(&statuses)
+}
+
+// UpdateIndexerBranchStatus updates the indexer status of a non-default branch
+func (repo *Repository) UpdateIndexerBranchStatus(indexerType RepoIndexerType, branch, sha string) error {
+	status := new(RepoIndexerStatus)
+	has, err := x.Where("`repo_id` = ? AND `indexer_type` = ? AND `branch` = ?", repo.ID, indexerType, branch).Get(status)
+	if err != nil {
+		return fmt.Errorf("UpdateIndexerBranchStatus: Unable to get repoIndexerStatus for repo: %s Branch: %s Error: %v", repo.FullName(), branch, err)
+	}
+
+	if !has {
+		_, err = x.Insert(&RepoIndexerStatus{
+			RepoID:      repo.ID,
+			CommitSha:   sha,
+			IndexerType: indexerType,
+			Branch:      branch,
+		})
+		if err != nil {
+			return fmt.Errorf("UpdateIndexerBranchStatus: Unable to insert repoIndexerStatus for repo: %s Branch: %s Sha: %s Error: %v", repo.FullName(), branch, sha, err)
+		}
+		return nil
+	}
+	status.CommitSha = sha
+	if _, err = x.ID(status.ID).Cols("commit_sha").Update(status); err != nil {
+		return fmt.Errorf("UpdateIndexerBranchStatus: Unable to update repoIndexerStatus for repo: %s Branch: %s Sha: %s Error: %v", repo.FullName(), branch, sha, err)
+	}
+	return nil
+}
+
+// DeleteIndexerBranchStatus deletes the indexer status of a non-default branch
+func (repo *Repository) DeleteIndexerBranchStatus(indexerType RepoIndexerType, branch string) error {
+	_, err := x.Where("`repo_id` = ? AND `indexer_type` = ? AND `branch` = ?", repo.ID, indexerType, branch).
+		Delete(new(RepoIndexerStatus))
+	return err
+}
@@ -179,6 +179,8 @@ type SearchRepoOptions struct {
 	HasMilestones util.OptionalBool
 	// LowerNames represents valid lower names to restrict to
 	LowerNames []string
+	// RepoIDs represents valid repository IDs to restrict to, e.g. the repositories of an access token
+	RepoIDs []int64
 }
 
 // SearchOrderBy is used to sort the result
@@ -352,6 +354,10 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 		cond = cond.And(builder.Eq{"num_milestones": 0}.Or(builder.IsNull{"num_milestones"}))
 	}
 
+	if len(opts.RepoIDs) > 0 {
+		cond = cond.And(builder.In("id", opts.RepoIDs))
+	}
+
 	return cond
 }
 
@@ -524,3 +530,99 @@ func FindUserAccessibleRepoIDs(user *User) ([]int64, error) {
 	}
 	return repoIDs, nil
 }
+
+// accessibleRepositoryUnitCondition returns a condition for checking if a unit of a repository is readable by a user.
+// Unlike accessibleRepositoryCondition it takes the units of the teams into account.
+func accessibleRepositoryUnitCondition(user *User, unitType UnitType) builder.Cond {
+	cond := builder.NewCond()
+
+	if user == nil || !user.IsRestricted || user.ID <= 0 {
+		orgVisibilityLimit := []structs.VisibleType{structs.VisibleTypePrivate}
+		if user == nil || user.ID <= 0 {
+			orgVisibilityLimit = append(orgVisibilityLimit, structs.VisibleTypeLimited)
+		}
+		// 1. All non-private repositories that aren't in a private organisation or limited organisation if we're
+		// not logged in
+		cond = cond.Or(builder.And(
+			builder.Eq{"`repository`.is_private": false},
+			builder.NotIn("`repository`.owner_id", builder.Select("id").From("`user`").Where(
+				builder.And(
+					builder.Eq{"type": UserTypeOrganization},
+					builder.In("visibility", orgVisibilityLimit)),
+			))))
+	}
+
+	if user != nil {
+		cond = cond.Or(
+			// 2. Repositories that we directly own
+			builder.Eq{"`repository`.owner_id": user.ID},
+			// 3. Repositories that we are a collaborator of, which grants all units
+			builder.In("`repository`.id", builder.Select("repo_id").
+				From("collaboration").
+				Where(builder.Eq{"user_id": user.ID})),
+			// 4. Repositories of a team we are in which is an owner team or has the unit
+			builder.In("`repository`.id", builder.Select("`team_repo`.repo_id").
+				From("team_repo").
+				Join("INNER", "team_user", "`team_user`.team_id = `team_repo`.team_id").
+				Join("INNER", "team", "`team`.id = `team_repo`.team_id").
+				Where(builder.And(
+					builder.Eq{"`team_user`.uid": user.ID},
+					builder.Or(
+						builder.Gte{"`team`.authorize": AccessModeOwner},
+						builder.In("`team`.id", builder.Select("team_id").
+							From("team_unit").
+							Where(builder.Eq{"`team_unit`.type": unitType})))))))
+
+		if !user.IsRestricted {
+			// 5. All public repos in private organizations that we are an org_user of
+			cond = cond.Or(builder.And(builder.Eq{"`repository`.is_private": false},
+				builder.In("`repository`.owner_id",
+					builder.Select("`org_user`.org_id").
+						From("org_user").
+						Where(builder.Eq{"`org_user`.uid": user.ID}))))
+		}
+	}
+
+	// the unit has to be enabled in any case
+	return builder.And(cond, builder.In("`repository`.id", builder.Select("repo_id").
+		From("repo_unit").
+		Where(builder.Eq{"type": unitType})))
+}
+
+// FindUserAccessibleRepoIDsByOwnerAndNames finds the ids of the repositories whose unit of the given type is readable
+// by a user, all of them for site admins. If ownerID isn't zero only the repositories of that owner are returned, and
+// if names are given, as "name" or "owner/name", only the repositories having one of them.
+func FindUserAccessibleRepoIDsByOwnerAndNames(user *User, unitType UnitType, ownerID int64, names []string) ([]int64, error) {
+	cond := builder.NewCond()
+	if user == nil || !user.IsAdmin {
+		cond = accessibleRepositoryUnitCondition(user, unitType)
+	}
+	if ownerID > 0 {
+		cond = cond.And(builder.Eq{"`repository`.owner_id": ownerID})
+	}
+	if len(names) > 0 {
+		namesCond := builder.NewCond()
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if idx := strings.IndexByte(name, '/'); idx >= 0 {
+				namesCond = namesCond.Or(builder.And(
+					builder.Eq{"`repository`.lower_name": name[idx+1:]},
+					builder.In("`repository`.owner_id", builder.Select("id").From("`user`").Where(builder.Eq{"lower_name": name[:idx]})),
+				))
+			} else {
+				namesCond = namesCond.Or(builder.Eq{"`repository`.lower_name": name})
+			}
+		}
+		cond = cond.And(namesCond)
+	}
+
+	repoIDs := make([]int64, 0, 10)
+	if err := x.
+		Table("repository").
+		Cols("id").
+		Where(cond).
+		Find(&repoIDs); err != nil {
+		return nil, fmt.Errorf("FindUserAccessibleRepoIDsByOwnerAndNames: %v", err)
+	}
+	return repoIDs, nil
+}
@@ -259,6 +259,16 @@ func TestSearchRepository(t *testing.T) {
 			opts:  &SearchRepoOptions{ListOptions: ListOptions{Page: 1, PageSize: 10}, Template: util.OptionalBoolTrue},
 			count: 2,
 		},
+		{
+			name:  "RepoIDs",
+			opts:  &SearchRepoOptions{ListOptions: ListOptions{Page: 1, PageSize: 10}, Private: true, RepoIDs: []int64{1, 2, 3}},
+			count: 3,
+		},
+		{
+			name:  "PublicRepoIDs",
+			opts:  &SearchRepoOptions{ListOptions: ListOptions{Page: 1, PageSize: 10}, RepoIDs: []int64{1, 2, 3}},
+			count: 1,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -355,3 +365,34 @@ func TestSearchRepositoryByTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestFindUserAccessibleRepoIDsByOwnerAndNames(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repos := make([]*Repository, 0, 50)
+	assert.NoError(t, x.Find(&repos))
+
+	// the repositories must be those whose code is readable according to the permissions
+	for _, user := range []*User{nil, AssertExistsAndLoadBean(t, &User{ID: 2}).(*User),
+		AssertExistsAndLoadBean(t, &User{ID: 4}).(*User), AssertExistsAndLoadBean(t, &User{ID: 5}).(*User),
+		AssertExistsAndLoadBean(t, &User{ID: 15}).(*User), AssertExistsAndLoadBean(t, &User{ID: 18}).(*User),
+		AssertExistsAndLoadBean(t, &User{ID: 20}).(*User), AssertExistsAndLoadBean(t, &User{ID: 29}).(*User)} {
+		repoIDs, err := FindUserAccessibleRepoIDsByOwnerAndNames(user, UnitTypeCode, 0, nil)
+		assert.NoError(t, err)
+
+		for _, repo := range repos {
+			// the fixture of repo20 leaves is_private NULL, so it is never found as public
+			if repo.ID == 31 {
+				continue
+			}
+			repo.Units = nil
+			perm, err := GetUserRepoPermission(repo, user)
+			assert.NoError(t, err)
+			assert.Equal(t, perm.CanRead(UnitTypeCode), util.IsInt64InSlice(repo.ID, repoIDs), "repo: %d, user: %v", repo.ID, user)
+		}
+	}
+
+	repoIDs, err := FindUserAccessibleRepoIDsByOwnerAndNames(AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), UnitTypeCode, 3, []string{"repo3", "user2/repo1"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{3}, repoIDs)
+}
@@ -24,6 +24,8 @@ type Mirror struct {
 
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX"`
 	NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+	// LastSyncedUnix is when the last successful sync of the issues and pull requests of a migrated mirror started
+	LastSyncedUnix timeutil.TimeStamp
 
 	LFS         bool   `xorm:"lfs_enabled NOT NULL DEFAULT false"`
 	LFSEndpoint string `xorm:"lfs_endpoint TEXT"`
@@ -36,6 +38,7 @@ func (m *Mirror) BeforeInsert() {
 	if m != nil {
 		m.UpdatedUnix = timeutil.TimeStampNow()
 		m.NextUpdateUnix = timeutil.TimeStampNow()
+		m.LastSyncedUnix = timeutil.TimeStampNow()
 	}
 }
 
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushMirrorsIterate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+
+	assert.NoError(t, InsertPushMirror(&PushMirror{
+		RemoteName:     "test-1",
+		LastUpdateUnix: now,
+		Interval:       1,
+	}))
+
+	long, _ := time.ParseDuration("24h")
+	assert.NoError(t, InsertPushMirror(&PushMirror{
+		RemoteName:     "test-2",
+		LastUpdateUnix: now,
+		Interval:       long,
+	}))
+
+	assert.NoError(t, InsertPushMirror(&PushMirror{
+		RemoteName:     "test-3",
+		LastUpdateUnix: now,
+		Interval:       0,
+	}))
+
+	time.Sleep(1 * time.Millisecond)
+
+	assert.NoError(t, PushMirrorsIterate(func(idx int, bean interface{}) error {
+		m, ok := bean.(*PushMirror)
+		assert.True(t, ok)
+		assert.Equal(t, "test-1", m.RemoteName)
+		return nil
+	}))
+}
+
+func TestPushMirrorsSyncedOnCommit(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, InsertPushMirror(&PushMirror{RepoID: 1, RemoteName: "on-commit", SyncOnCommit: true}))
+	assert.NoError(t, InsertPushMirror(&PushMirror{RepoID: 1, RemoteName: "interval-only", SyncOnCommit: false}))
+	assert.NoError(t, InsertPushMirror(&PushMirror{RepoID: 2, RemoteName: "other-repo", SyncOnCommit: true}))
+
+	mirrors, err := GetPushMirrorsSyncedOnCommit(1)
+	assert.NoError(t, err)
+	if assert.Len(t, mirrors, 1) {
+		assert.Equal(t, "on-commit", mirrors[0].RemoteName)
+	}
+
+	mirrors, err = GetPushMirrorsByRepoID(1)
+	assert.NoError(t, err)
+	assert.Len(t, mirrors, 2)
+
+	assert.NoError(t, DeletePushMirrorsByRepoID(1))
+	_, err = GetPushMirrorByID(mirrors[0].ID)
+	assert.Equal(t, ErrPushMirrorNotExist, err)
+}
@@ -100,6 +100,7 @@ type PullRequestsConfig struct {
 	AllowRebase               bool
 	AllowRebaseMerge          bool
 	AllowSquash               bool
+	AllowFastForwardOnly      bool
 	AllowManualMerge          bool
 	AutodetectManualMerge     bool
 	DefaultMergeStyle         MergeStyle
@@ -123,6 +124,7 @@ func (cfg *PullRequestsConfig) IsMergeStyleAllowed(mergeStyle MergeStyle) bool {
 		mergeStyle == MergeStyleRebase && cfg.AllowRebase ||
 		mergeStyle == MergeStyleRebaseMerge && cfg.AllowRebaseMerge ||
 		mergeStyle == MergeStyleSquash && cfg.AllowSquash ||
+		mergeStyle == MergeStyleFastForwardOnly && cfg.AllowFastForwardOnly ||
 		mergeStyle == MergeStyleManuallyMerged && cfg.AllowManualMerge
 }
 
@@ -150,6 +152,9 @@ func (cfg *PullRequestsConfig) AllowedMergeStyleCount() int {
 	if cfg.AllowSquash {
 		count++
 	}
+	if cfg.AllowFastForwardOnly {
+		count++
+	}
 	return count
 }
 
@@ -68,6 +68,20 @@ func TestUser_GetStarredRepos(t *testing.T) {
 	}
 }
 
+func TestGetStarredRepos_RepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	starred, err := GetStarredRepos(2, true, []int64{1, 4}, ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, starred, 1) {
+		assert.EqualValues(t, 4, starred[0].ID)
+	}
+
+	watched, err := GetWatchedRepos(1, true, []int64{2}, ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, watched, 0)
+}
+
 func TestUser_GetStarredRepos2(t *testing.T) {
 	// user who has no starred repos
 	assert.NoError(t, PrepareTestDatabase())
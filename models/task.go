@@ -29,10 +29,18 @@ type Task struct {
 	StartTime      timeutil.TimeStamp
 	EndTime        timeutil.TimeStamp
 	PayloadContent string             `xorm:"TEXT"`
+	Message        string             `xorm:"TEXT"` // if task is running, saved the last progress message
 	Errors         string             `xorm:"TEXT"` // if task failed, saved the error reason
 	Created        timeutil.TimeStamp `xorm:"created"`
 }
 
+// TranslatableMessage represents a message stored as a locale key and its arguments
+// so it can be translated into the language of whoever reads it
+type TranslatableMessage struct {
+	Format string
+	Args   []interface{} `json:",omitempty"`
+}
+
 // LoadRepo loads repository of the task
 func (task *Task) LoadRepo() error {
 	return task.loadRepo(x)
@@ -101,9 +109,36 @@ func (task *Task) UpdateCols(cols ...string) error {
 	return err
 }
 
-// MigrateConfig returns task config when migrate repository
+// SetMessage saves the translatable progress message of the task
+func (task *Task) SetMessage(format string, args ...interface{}) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	bs, err := json.Marshal(&TranslatableMessage{
+		Format: format,
+		Args:   args,
+	})
+	if err != nil {
+		return err
+	}
+	task.Message = string(bs)
+	return task.UpdateCols("message")
+}
+
+// TranslatableMessage returns the progress message of the task, nil if there is none
+func (task *Task) TranslatableMessage() (*TranslatableMessage, error) {
+	if len(task.Message) == 0 {
+		return nil, nil
+	}
+	var message TranslatableMessage
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(task.Message), &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// MigrateConfig returns task config when migrate or restore repository
 func (task *Task) MigrateConfig() (*migration.MigrateOptions, error) {
-	if task.Type == structs.TaskTypeMigrateRepo {
+	if task.Type == structs.TaskTypeMigrateRepo || task.Type == structs.TaskTypeRestoreRepo {
 		var opts migration.MigrateOptions
 		json := jsoniter.ConfigCompatibleWithStandardLibrary
 		err := json.Unmarshal([]byte(task.PayloadContent), &opts)
@@ -112,7 +147,7 @@ func (task *Task) MigrateConfig() (*migration.MigrateOptions, error) {
 		}
 		return &opts, nil
 	}
-	return nil, fmt.Errorf("Task type is %s, not Migrate Repo", task.Type.Name())
+	return nil, fmt.Errorf("Task type is %s, not Migrate or Restore Repo", task.Type.Name())
 }
 
 // ErrTaskDoesNotExist represents a "TaskDoesNotExist" kind of error.
@@ -133,33 +168,31 @@ func (err ErrTaskDoesNotExist) Error() string {
 		err.ID, err.RepoID, err.Type)
 }
 
-// GetMigratingTask returns the migrating task by repo's id
+// GetMigratingTask returns the migrating or restoring task by repo's id
 func GetMigratingTask(repoID int64) (*Task, error) {
 	task := Task{
 		RepoID: repoID,
-		Type:   structs.TaskTypeMigrateRepo,
 	}
-	has, err := x.Get(&task)
+	has, err := x.In("type", structs.TaskTypeMigrateRepo, structs.TaskTypeRestoreRepo).Get(&task)
 	if err != nil {
 		return nil, err
 	} else if !has {
-		return nil, ErrTaskDoesNotExist{0, repoID, task.Type}
+		return nil, ErrTaskDoesNotExist{0, repoID, structs.TaskTypeMigrateRepo}
 	}
 	return &task, nil
 }
 
-// GetMigratingTaskByID returns the migrating task by repo's id
+// GetMigratingTaskByID returns the migrating or restoring task by its id
 func GetMigratingTaskByID(id, doerID int64) (*Task, *migration.MigrateOptions, error) {
 	task := Task{
 		ID:     id,
 		DoerID: doerID,
-		Type:   structs.TaskTypeMigrateRepo,
 	}
-	has, err := x.Get(&task)
+	has, err := x.In("type", structs.TaskTypeMigrateRepo, structs.TaskTypeRestoreRepo).Get(&task)
 	if err != nil {
 		return nil, nil, err
 	} else if !has {
-		return nil, nil, ErrTaskDoesNotExist{id, 0, task.Type}
+		return nil, nil, ErrTaskDoesNotExist{id, 0, structs.TaskTypeMigrateRepo}
 	}
 
 	var opts migration.MigrateOptions
@@ -170,6 +203,26 @@ func GetMigratingTaskByID(id, doerID int64) (*Task, *migration.MigrateOptions, e
 	return &task, &opts, nil
 }
 
+// GetLatestExportTask returns the most recent export task of a repository
+func GetLatestExportTask(repoID int64) (*Task, error) {
+	task := Task{
+		RepoID: repoID,
+		Type:   structs.TaskTypeExportRepo,
+	}
+	has, err := x.Desc("id").Get(&task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{0, repoID, structs.TaskTypeExportRepo}
+	}
+	return &task, nil
+}
+
+// IsRunning returns whether the task is queued or running
+func (task *Task) IsRunning() bool {
+	return task.Status == structs.TaskStatusQueue || task.Status == structs.TaskStatusRunning
+}
+
 // FindTaskOptions find all tasks
 type FindTaskOptions struct {
 	Status int
@@ -7,6 +7,7 @@ package models
 
 import (
 	"crypto/subtle"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/base"
@@ -26,6 +27,11 @@ type AccessToken struct {
 	TokenSalt      string
 	TokenLastEight string `xorm:"token_last_eight"`
 
+	Scope AccessTokenScope `xorm:"NOT NULL DEFAULT ''"`
+	// RepoIDs limits the token to these repositories, it is not limited if empty
+	RepoIDs []int64 `xorm:"JSON TEXT"`
+
+	ExpiresUnix       timeutil.TimeStamp `xorm:"INDEX"`
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
 	HasRecentActivity bool               `xorm:"-"`
@@ -38,7 +44,81 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
+// IsExpired returns true if the token has an expiry date which has passed
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresUnix != 0 && t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// HasScope returns true if the token grants the scope
+func (t *AccessToken) HasScope(scope AccessTokenScope) bool {
+	return t.Scope.HasScope(scope)
+}
+
+// CanAccessRepo returns true if the token is not restricted to other repositories
+func (t *AccessToken) CanAccessRepo(repoID int64) bool {
+	if len(t.RepoIDs) == 0 {
+		return true
+	}
+	for _, id := range t.RepoIDs {
+		if id == repoID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRepositories returns the repositories the token is limited to, deleted repositories are skipped
+func (t *AccessToken) GetRepositories() ([]*Repository, error) {
+	if len(t.RepoIDs) == 0 {
+		return nil, nil
+	}
+	repoMap, err := GetRepositoriesMapByIDs(t.RepoIDs)
+	if err != nil {
+		return nil, err
+	}
+	repos := make([]*Repository, 0, len(repoMap))
+	for _, id := range t.RepoIDs {
+		if repo, ok := repoMap[id]; ok {
+			if err := repo.GetOwner(); err != nil {
+				return nil, err
+			}
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+// GetAccessTokenRepoIDsByNames resolves the full names of repositories a token should be limited to.
+// The user must have access to all of them, otherwise ErrRepoNotExist is returned.
+func GetAccessTokenRepoIDsByNames(u *User, fullNames []string) ([]int64, error) {
+	ids := make([]int64, 0, len(fullNames))
+	for _, fullName := range fullNames {
+		fullName = strings.TrimSpace(fullName)
+		if fullName == "" {
+			continue
+		}
+		parts := strings.SplitN(fullName, "/", 2)
+		if len(parts) != 2 {
+			return nil, ErrRepoNotExist{Name: fullName}
+		}
+		repo, err := GetRepositoryByOwnerAndName(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		perm, err := GetUserRepoPermission(repo, u)
+		if err != nil {
+			return nil, err
+		}
+		if !perm.HasAccess() {
+			return nil, ErrRepoNotExist{OwnerName: parts[0], Name: parts[1]}
+		}
+		ids = append(ids, repo.ID)
+	}
+	return ids, nil
+}
+
 // NewAccessToken creates new access token.
+// A token without scope is granted all scopes.
 func NewAccessToken(t *AccessToken) error {
 	salt, err := generate.GetRandomString(10)
 	if err != nil {
@@ -48,11 +128,15 @@ func NewAccessToken(t *AccessToken) error {
 	t.Token = base.EncodeSha1(gouuid.New().String())
 	t.TokenHash = hashToken(t.Token, t.TokenSalt)
 	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	if t.Scope == "" {
+		t.Scope = AccessTokenScopeAll
+	}
 	_, err = x.Insert(t)
 	return err
 }
 
-// GetAccessTokenBySHA returns access token by given token value
+// GetAccessTokenBySHA returns access token by given token value.
+// Expired tokens are reported as not existing.
 func GetAccessTokenBySHA(token string) (*AccessToken, error) {
 	if token == "" {
 		return nil, ErrAccessTokenEmpty{}
@@ -71,6 +155,9 @@ func GetAccessTokenBySHA(token string) (*AccessToken, error) {
 	for _, t := range tokens {
 		tempHash := hashToken(token, t.TokenSalt)
 		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(tempHash)) == 1 {
+			if t.IsExpired() {
+				return nil, ErrAccessTokenNotExist{token}
+			}
 			return &t, nil
 		}
 	}
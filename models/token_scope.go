@@ -0,0 +1,116 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+)
+
+// AccessTokenScope is a comma separated list of the scopes granted to an access token
+type AccessTokenScope string
+
+// The scopes an access token can be limited to
+const (
+	AccessTokenScopeAll          AccessTokenScope = "all"
+	AccessTokenScopeRepoRead     AccessTokenScope = "repo:read"
+	AccessTokenScopeRepoWrite    AccessTokenScope = "repo:write"
+	AccessTokenScopeIssue        AccessTokenScope = "issue"
+	AccessTokenScopeAdminOrg     AccessTokenScope = "admin:org"
+	AccessTokenScopeUser         AccessTokenScope = "user"
+	AccessTokenScopePackage      AccessTokenScope = "package"
+	AccessTokenScopeNotification AccessTokenScope = "notification"
+)
+
+// AccessTokenScopes contains all scopes in display order
+var AccessTokenScopes = []AccessTokenScope{
+	AccessTokenScopeAll,
+	AccessTokenScopeRepoRead,
+	AccessTokenScopeRepoWrite,
+	AccessTokenScopeIssue,
+	AccessTokenScopeAdminOrg,
+	AccessTokenScopeUser,
+	AccessTokenScopePackage,
+	AccessTokenScopeNotification,
+}
+
+// impliedAccessTokenScopes lists the scopes which are granted by another scope as well
+var impliedAccessTokenScopes = map[AccessTokenScope][]AccessTokenScope{
+	AccessTokenScopeRepoRead: {AccessTokenScopeRepoWrite},
+}
+
+// ParseAccessTokenScope validates the given scopes and joins them in display order.
+// No scopes at all results in an unrestricted token.
+func ParseAccessTokenScope(scopes []string) (AccessTokenScope, error) {
+	set := make(map[AccessTokenScope]bool, len(scopes))
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if !isValidAccessTokenScope(AccessTokenScope(s)) {
+			return "", ErrAccessTokenScopeInvalid{s}
+		}
+		set[AccessTokenScope(s)] = true
+	}
+
+	if len(set) == 0 || set[AccessTokenScopeAll] {
+		return AccessTokenScopeAll, nil
+	}
+
+	parts := make([]string, 0, len(set))
+	for _, s := range AccessTokenScopes {
+		if set[s] {
+			parts = append(parts, string(s))
+		}
+	}
+	return AccessTokenScope(strings.Join(parts, ",")), nil
+}
+
+func isValidAccessTokenScope(scope AccessTokenScope) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns the single scopes
+func (s AccessTokenScope) Scopes() []AccessTokenScope {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(string(s), ",")
+	scopes := make([]AccessTokenScope, 0, len(parts))
+	for _, p := range parts {
+		scopes = append(scopes, AccessTokenScope(p))
+	}
+	return scopes
+}
+
+// HasScope returns true if the scope is granted, either directly or by a broader scope
+func (s AccessTokenScope) HasScope(scope AccessTokenScope) bool {
+	for _, granted := range s.Scopes() {
+		if granted == AccessTokenScopeAll || granted == scope {
+			return true
+		}
+		for _, implied := range impliedAccessTokenScopes[scope] {
+			if granted == implied {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasAnyScope returns true if at least one of the scopes is granted
+func (s AccessTokenScope) HasAnyScope(scopes ...AccessTokenScope) bool {
+	for _, scope := range scopes {
+		if s.HasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccessTokenScope(t *testing.T) {
+	tests := []struct {
+		in       []string
+		expected AccessTokenScope
+	}{
+		{nil, AccessTokenScopeAll},
+		{[]string{""}, AccessTokenScopeAll},
+		{[]string{"all", "repo:read"}, AccessTokenScopeAll},
+		{[]string{"Repo:Read"}, AccessTokenScopeRepoRead},
+		{[]string{"package", "repo:write", "package"}, "repo:write,package"},
+	}
+	for _, test := range tests {
+		scope, err := ParseAccessTokenScope(test.in)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, scope)
+	}
+
+	_, err := ParseAccessTokenScope([]string{"repo", "admin"})
+	assert.True(t, IsErrAccessTokenScopeInvalid(err))
+}
+
+func TestAccessTokenScope_HasScope(t *testing.T) {
+	assert.True(t, AccessTokenScopeAll.HasScope(AccessTokenScopeAdminOrg))
+
+	scope := AccessTokenScope("repo:write,issue")
+	assert.True(t, scope.HasScope(AccessTokenScopeRepoRead))
+	assert.True(t, scope.HasScope(AccessTokenScopeRepoWrite))
+	assert.True(t, scope.HasScope(AccessTokenScopeIssue))
+	assert.False(t, scope.HasScope(AccessTokenScopeUser))
+	assert.True(t, scope.HasAnyScope(AccessTokenScopeUser, AccessTokenScopeIssue))
+
+	assert.False(t, AccessTokenScopeRepoRead.HasScope(AccessTokenScopeRepoWrite))
+	assert.False(t, AccessTokenScope("").HasScope(AccessTokenScopeRepoRead))
+}
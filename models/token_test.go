@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/timeutil"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -80,6 +82,46 @@ func TestGetAccessTokenBySHA(t *testing.T) {
 	assert.True(t, IsErrAccessTokenEmpty(err))
 }
 
+func TestGetAccessTokenBySHA_Expired(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	token := &AccessToken{
+		UID:         3,
+		Name:        "Token Expired",
+		ExpiresUnix: timeutil.TimeStampNow().Add(-60),
+	}
+	assert.NoError(t, NewAccessToken(token))
+	assert.True(t, token.IsExpired())
+
+	_, err := GetAccessTokenBySHA(token.Token)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+}
+
+func TestAccessTokenRepositories(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	ids, err := GetAccessTokenRepoIDsByNames(user2, []string{"user2/repo1", " user2/repo2 "})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids)
+
+	_, err = GetAccessTokenRepoIDsByNames(user2, []string{"user2/repo_not_exist"})
+	assert.True(t, IsErrRepoNotExist(err))
+
+	token := &AccessToken{UID: 2, Name: "Token Repos", RepoIDs: ids}
+	assert.NoError(t, NewAccessToken(token))
+	assert.Equal(t, AccessTokenScopeAll, token.Scope)
+	assert.True(t, token.CanAccessRepo(1))
+	assert.False(t, token.CanAccessRepo(3))
+
+	loaded, err := GetAccessTokenBySHA(token.Token)
+	assert.NoError(t, err)
+	repos, err := loaded.GetRepositories()
+	assert.NoError(t, err)
+	if assert.Len(t, repos, 2) {
+		assert.Equal(t, "user2/repo1", repos[0].FullName())
+	}
+}
+
 func TestListAccessTokens(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	tokens, err := ListAccessTokens(ListAccessTokensOptions{UserID: 1})
@@ -1165,6 +1165,8 @@ func deleteUser(e Engine, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&WebAuthnCredential{UserID: u.ID},
+		&SavedSearch{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1196,6 +1198,14 @@ func deleteUser(e Engine, u *User) error {
 		}
 	}
 
+	if err = deletePackagesByOwnerID(e, u.ID); err != nil {
+		return fmt.Errorf("deletePackagesByOwnerID: %v", err)
+	}
+
+	if err = deleteProjectsByOwnerID(e, u.ID); err != nil {
+		return fmt.Errorf("deleteProjectsByOwnerID: %v", err)
+	}
+
 	// ***** START: PublicKey *****
 	if _, err = e.Delete(&PublicKey{OwnerID: u.ID}); err != nil {
 		return fmt.Errorf("deletePublicKeys: %v", err)
@@ -1637,13 +1647,16 @@ func SearchUsers(opts *SearchUserOptions) (users []*User, _ int64, _ error) {
 	return users, count, sess.Find(&users)
 }
 
-// GetStarredRepos returns the repos starred by a particular user
-func GetStarredRepos(userID int64, private bool, listOptions ListOptions) ([]*Repository, error) {
+// GetStarredRepos returns the repos starred by a particular user, limited to repoIDs if not empty
+func GetStarredRepos(userID int64, private bool, repoIDs []int64, listOptions ListOptions) ([]*Repository, error) {
 	sess := x.Where("star.uid=?", userID).
 		Join("LEFT", "star", "`repository`.id=`star`.repo_id")
 	if !private {
 		sess = sess.And("is_private=?", false)
 	}
+	if len(repoIDs) > 0 {
+		sess = sess.And(builder.In("`repository`.id", repoIDs))
+	}
 
 	if listOptions.Page != 0 {
 		sess = listOptions.setSessionPagination(sess)
@@ -1656,14 +1669,17 @@ func GetStarredRepos(userID int64, private bool, listOptions ListOptions) ([]*Re
 	return repos, sess.Find(&repos)
 }
 
-// GetWatchedRepos returns the repos watched by a particular user
-func GetWatchedRepos(userID int64, private bool, listOptions ListOptions) ([]*Repository, error) {
+// GetWatchedRepos returns the repos watched by a particular user, limited to repoIDs if not empty
+func GetWatchedRepos(userID int64, private bool, repoIDs []int64, listOptions ListOptions) ([]*Repository, error) {
 	sess := x.Where("watch.user_id=?", userID).
 		And("`watch`.mode<>?", RepoWatchModeDont).
 		Join("LEFT", "watch", "`repository`.id=`watch`.repo_id")
 	if !private {
 		sess = sess.And("is_private=?", false)
 	}
+	if len(repoIDs) > 0 {
+		sess = sess.And(builder.In("`repository`.id", repoIDs))
+	}
 
 	if listOptions.Page != 0 {
 		sess = listOptions.setSessionPagination(sess)
@@ -0,0 +1,160 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// WebAuthnCredential represents a WebAuthn credential (security key or platform authenticator) of a user
+type WebAuthnCredential struct {
+	ID              int64 `xorm:"pk autoincr"`
+	Name            string
+	LowerName       string `xorm:"unique(s)"`
+	UserID          int64  `xorm:"INDEX unique(s)"`
+	CredentialID    string `xorm:"INDEX VARCHAR(410)"`
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32             `xorm:"BIGINT"`
+	Passwordless    bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName returns a better table name for WebAuthnCredential
+func (cred WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
+
+// BeforeInsert will be invoked by XORM before updating a record
+func (cred *WebAuthnCredential) BeforeInsert() {
+	cred.LowerName = strings.ToLower(cred.Name)
+}
+
+// IsLegacyU2F returns true if the credential was registered with the U2F API
+func (cred *WebAuthnCredential) IsLegacyU2F() bool {
+	return cred.AttestationType == "fido-u2f"
+}
+
+// HasUsed returns true if the credential has been used to sign in
+func (cred *WebAuthnCredential) HasUsed() bool {
+	return cred.UpdatedUnix > cred.CreatedUnix
+}
+
+// ToCredential converts the credential to the representation used by the WebAuthn ceremonies
+func (cred *WebAuthnCredential) ToCredential() *webauthn.Credential {
+	id, _ := base64.RawURLEncoding.DecodeString(cred.CredentialID)
+	return &webauthn.Credential{
+		ID:              id,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.AAGUID,
+		SignCount:       cred.SignCount,
+	}
+}
+
+func (cred *WebAuthnCredential) updateSignCount(e Engine) error {
+	_, err := e.ID(cred.ID).Cols("sign_count").Update(cred)
+	return err
+}
+
+// UpdateSignCount will update the database value of the sign count
+func (cred *WebAuthnCredential) UpdateSignCount() error {
+	return cred.updateSignCount(x)
+}
+
+// WebAuthnCredentialList is a list of *WebAuthnCredential
+type WebAuthnCredentialList []*WebAuthnCredential
+
+// ToCredentialDescriptors returns the descriptors of all credentials
+func (list WebAuthnCredentialList) ToCredentialDescriptors() []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(list))
+	for _, cred := range list {
+		descriptors = append(descriptors, cred.ToCredential().Descriptor())
+	}
+	return descriptors
+}
+
+// HasLegacyU2F returns true if one of the credentials was registered with the U2F API
+func (list WebAuthnCredentialList) HasLegacyU2F() bool {
+	for _, cred := range list {
+		if cred.IsLegacyU2F() {
+			return true
+		}
+	}
+	return false
+}
+
+func getWebAuthnCredentialsByUID(e Engine, uid int64) (WebAuthnCredentialList, error) {
+	creds := make(WebAuthnCredentialList, 0)
+	return creds, e.Where("user_id = ?", uid).Asc("id").Find(&creds)
+}
+
+// GetWebAuthnCredentialsByUID returns all WebAuthn credentials of the given user
+func GetWebAuthnCredentialsByUID(uid int64) (WebAuthnCredentialList, error) {
+	return getWebAuthnCredentialsByUID(x, uid)
+}
+
+// HasWebAuthnRegistrationsByUID returns true if the user has at least one WebAuthn credential
+func HasWebAuthnRegistrationsByUID(uid int64) (bool, error) {
+	return x.Where("user_id = ?", uid).Exist(&WebAuthnCredential{})
+}
+
+// ExistsWebAuthnCredentialsForUIDAndName returns true if the user has a credential with the given name
+func ExistsWebAuthnCredentialsForUIDAndName(uid int64, name string) (bool, error) {
+	return x.Where("user_id = ? AND lower_name = ?", uid, strings.ToLower(name)).Exist(&WebAuthnCredential{})
+}
+
+// GetWebAuthnCredentialByID returns WebAuthn credential by id
+func GetWebAuthnCredentialByID(id int64) (*WebAuthnCredential, error) {
+	cred := new(WebAuthnCredential)
+	if found, err := x.ID(id).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{ID: id}
+	}
+	return cred, nil
+}
+
+// GetWebAuthnCredentialByCredID returns WebAuthn credential by the id the authenticator assigned to it
+func GetWebAuthnCredentialByCredID(credID []byte) (*WebAuthnCredential, error) {
+	encoded := base64.RawURLEncoding.EncodeToString(credID)
+	cred := new(WebAuthnCredential)
+	if found, err := x.Where("credential_id = ?", encoded).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{CredentialID: encoded}
+	}
+	return cred, nil
+}
+
+// CreateCredential will create a new WebAuthnCredential from the given credential
+func CreateCredential(userID int64, name string, cred *webauthn.Credential, passwordless bool) (*WebAuthnCredential, error) {
+	c := &WebAuthnCredential{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    base64.RawURLEncoding.EncodeToString(cred.ID),
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.AAGUID,
+		SignCount:       cred.SignCount,
+		Passwordless:    passwordless,
+	}
+	if _, err := x.InsertOne(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteCredential will delete the WebAuthnCredential of the user
+func DeleteCredential(id, userID int64) (bool, error) {
+	had, err := x.ID(id).Where("user_id = ?", userID).Delete(&WebAuthnCredential{})
+	return had > 0, err
+}
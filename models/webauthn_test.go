@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/auth/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWebAuthnCredentialByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "WebAuthn credential", res.Name)
+
+	_, err = GetWebAuthnCredentialByID(342432)
+	assert.Error(t, err)
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+}
+
+func TestGetWebAuthnCredentialByCredID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialByCredID([]byte("Test"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, res.ID)
+	assert.Equal(t, []byte("Test"), res.ToCredential().ID)
+
+	_, err = GetWebAuthnCredentialByCredID([]byte("unknown"))
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+}
+
+func TestGetWebAuthnCredentialsByUID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialsByUID(24)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "WebAuthn credential", res[0].Name)
+
+	has, err := HasWebAuthnRegistrationsByUID(24)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasWebAuthnRegistrationsByUID(1)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	exists, err := ExistsWebAuthnCredentialsForUIDAndName(24, "webauthn Credential")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestWebAuthnCredential_TableName(t *testing.T) {
+	assert.Equal(t, "webauthn_credential", WebAuthnCredential{}.TableName())
+}
+
+func TestWebAuthnCredential_UpdateSignCount(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	cred := AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1}).(*WebAuthnCredential)
+	cred.SignCount = 0xffffffff
+	assert.NoError(t, cred.UpdateSignCount())
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: 1, SignCount: 0xffffffff})
+}
+
+func TestCreateCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := CreateCredential(1, "WebAuthn Created Credential", &webauthn.Credential{ID: []byte("Test2"), AttestationType: "none"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "WebAuthn Created Credential", res.Name)
+	assert.Equal(t, "VGVzdDI", res.CredentialID)
+	assert.True(t, res.Passwordless)
+
+	AssertExistsIf(t, true, &WebAuthnCredential{Name: "WebAuthn Created Credential", LowerName: "webauthn created credential", UserID: 1})
+}
+
+func TestDeleteCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	deleted, err := DeleteCredential(1, 1)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1})
+
+	deleted, err = DeleteCredential(1, 24)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	AssertNotExistsBean(t, &WebAuthnCredential{ID: 1})
+}
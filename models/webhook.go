@@ -12,12 +12,14 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/secret"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	gouuid "github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
+	"xorm.io/builder"
 )
 
 // HookContentType is the content type of a web hook
@@ -77,6 +79,18 @@ func IsValidHookContentType(name string) bool {
 	return ok
 }
 
+// HMAC algorithms deliveries of a web hook can be signed with
+const (
+	HookSignatureSHA1   = "sha1"
+	HookSignatureSHA256 = "sha256"
+	HookSignatureSHA512 = "sha512"
+)
+
+// IsValidHookSignatureAlgorithm returns true if given name is a valid HMAC algorithm for deliveries.
+func IsValidHookSignatureAlgorithm(name string) bool {
+	return name == HookSignatureSHA1 || name == HookSignatureSHA256 || name == HookSignatureSHA512
+}
+
 // HookEvents is a set of web hook events
 type HookEvents struct {
 	Create               bool `json:"create"`
@@ -97,6 +111,11 @@ type HookEvents struct {
 	PullRequestSync      bool `json:"pull_request_sync"`
 	Repository           bool `json:"repository"`
 	Release              bool `json:"release"`
+	Wiki                 bool `json:"wiki"`
+	Status               bool `json:"status"`
+	Milestone            bool `json:"milestone"`
+	ProjectCard          bool `json:"project_card"`
+	BranchProtection     bool `json:"branch_protection"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -119,6 +138,9 @@ const (
 	HookStatusFail
 )
 
+// MaxHookRetries is the maximum number of times a failed delivery of a webhook can be retried
+const MaxHookRetries = 10
+
 // Webhook represents a web hook object.
 type Webhook struct {
 	ID              int64 `xorm:"pk autoincr"`
@@ -137,6 +159,20 @@ type Webhook struct {
 	Type            HookTaskType `xorm:"VARCHAR(16) 'type'"`
 	Meta            string       `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus      HookStatus   // Last delivery status
+	// MaxRetries is the number of times a failed delivery is retried
+	MaxRetries int `xorm:"NOT NULL DEFAULT 0"`
+	// DisableAfterRetries deactivates the webhook once a delivery has failed after all retries
+	DisableAfterRetries bool `xorm:"NOT NULL DEFAULT false"`
+	// SignatureAlgorithm is the HMAC algorithm of the X-Gitea-Signature header, sha256 if empty
+	SignatureAlgorithm string `xorm:"VARCHAR(16) NOT NULL DEFAULT ''"`
+	// HeaderAuthorizationEncrypted is the encrypted value of the Authorization header sent with deliveries
+	HeaderAuthorizationEncrypted string `xorm:"TEXT"`
+	// ClientCertificate is the PEM encoded certificate presented to receivers requiring mutual TLS
+	ClientCertificate string `xorm:"TEXT"`
+	// ClientKeyEncrypted is the encrypted PEM encoded private key of the client certificate
+	ClientKeyEncrypted string `xorm:"TEXT"`
+	// CACertificates are the PEM encoded certificates trusted instead of the system roots, if any
+	CACertificates string `xorm:"TEXT"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -152,6 +188,55 @@ func (w *Webhook) AfterLoad() {
 	}
 }
 
+// GetSignatureAlgorithm returns the HMAC algorithm of the X-Gitea-Signature header.
+func (w *Webhook) GetSignatureAlgorithm() string {
+	if len(w.SignatureAlgorithm) == 0 {
+		return HookSignatureSHA256
+	}
+	return w.SignatureAlgorithm
+}
+
+// HeaderAuthorization returns the decrypted value of the Authorization header sent with deliveries.
+func (w *Webhook) HeaderAuthorization() (string, error) {
+	return decryptWebhookSecret(w.HeaderAuthorizationEncrypted)
+}
+
+// SetHeaderAuthorization encrypts and sets the value of the Authorization header sent with deliveries.
+func (w *Webhook) SetHeaderAuthorization(cleartext string) (err error) {
+	w.HeaderAuthorizationEncrypted, err = encryptWebhookSecret(cleartext)
+	return err
+}
+
+// ClientKey returns the decrypted private key of the client certificate.
+func (w *Webhook) ClientKey() (string, error) {
+	return decryptWebhookSecret(w.ClientKeyEncrypted)
+}
+
+// SetClientKey encrypts and sets the private key of the client certificate.
+func (w *Webhook) SetClientKey(cleartext string) (err error) {
+	w.ClientKeyEncrypted, err = encryptWebhookSecret(cleartext)
+	return err
+}
+
+// HasClientTLS returns true if deliveries use a client certificate or custom CA certificates.
+func (w *Webhook) HasClientTLS() bool {
+	return len(w.ClientCertificate) > 0 || len(w.CACertificates) > 0
+}
+
+func decryptWebhookSecret(ciphertext string) (string, error) {
+	if len(ciphertext) == 0 {
+		return "", nil
+	}
+	return secret.DecryptSecret(setting.SecretKey, ciphertext)
+}
+
+func encryptWebhookSecret(cleartext string) (string, error) {
+	if len(cleartext) == 0 {
+		return "", nil
+	}
+	return secret.EncryptSecret(setting.SecretKey, cleartext)
+}
+
 // History returns history of webhook by given conditions.
 func (w *Webhook) History(page int) ([]*HookTask, error) {
 	return HookTasks(w.ID, page)
@@ -285,6 +370,36 @@ func (w *Webhook) HasRepositoryEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Repository)
 }
 
+// HasWikiEvent returns if hook enabled wiki event.
+func (w *Webhook) HasWikiEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Wiki)
+}
+
+// HasStatusEvent returns if hook enabled commit status event.
+func (w *Webhook) HasStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Status)
+}
+
+// HasMilestoneEvent returns if hook enabled milestone event.
+func (w *Webhook) HasMilestoneEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Milestone)
+}
+
+// HasProjectCardEvent returns if hook enabled project card event.
+func (w *Webhook) HasProjectCardEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.ProjectCard)
+}
+
+// HasBranchProtectionEvent returns if hook enabled branch protection event.
+func (w *Webhook) HasBranchProtectionEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.BranchProtection)
+}
+
 // EventCheckers returns event checkers
 func (w *Webhook) EventCheckers() []struct {
 	Has  func() bool
@@ -314,6 +429,11 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasPullRequestSyncEvent, HookEventPullRequestSync},
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
+		{w.HasWikiEvent, HookEventWiki},
+		{w.HasStatusEvent, HookEventStatus},
+		{w.HasMilestoneEvent, HookEventMilestone},
+		{w.HasProjectCardEvent, HookEventProjectCard},
+		{w.HasBranchProtectionEvent, HookEventBranchProtection},
 	}
 }
 
@@ -474,6 +594,12 @@ func UpdateWebhookLastStatus(w *Webhook) error {
 	return err
 }
 
+// UpdateWebhookActive updates whether the webhook is active.
+func UpdateWebhookActive(w *Webhook) error {
+	_, err := x.ID(w.ID).Cols("is_active").Update(w)
+	return err
+}
+
 // deleteWebhook uses argument bean as query condition,
 // ID must be specified and do not assign unnecessary fields.
 func deleteWebhook(bean *Webhook) (err error) {
@@ -572,6 +698,7 @@ const (
 	MSTEAMS  HookTaskType = "msteams"
 	FEISHU   HookTaskType = "feishu"
 	MATRIX   HookTaskType = "matrix"
+	CUSTOM   HookTaskType = "custom"
 )
 
 // HookEventType is the type of an hook event
@@ -599,6 +726,11 @@ const (
 	HookEventPullRequestSync           HookEventType = "pull_request_sync"
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
+	HookEventWiki                      HookEventType = "wiki"
+	HookEventStatus                    HookEventType = "status"
+	HookEventMilestone                 HookEventType = "milestone"
+	HookEventProjectCard               HookEventType = "project_card"
+	HookEventBranchProtection          HookEventType = "branch_protection"
 )
 
 // Event returns the HookEventType as an event string
@@ -629,6 +761,16 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventWiki:
+		return "wiki"
+	case HookEventStatus:
+		return "status"
+	case HookEventMilestone:
+		return "milestone"
+	case HookEventProjectCard:
+		return "project_card"
+	case HookEventBranchProtection:
+		return "branch_protection"
 	}
 	return ""
 }
@@ -664,6 +806,13 @@ type HookTask struct {
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
 
+	// Attempts is the number of times delivery has been attempted
+	Attempts int `xorm:"NOT NULL DEFAULT 0"`
+	// NextAttemptUnix is when a failed delivery is retried
+	NextAttemptUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// IsDeadLetter is set if delivery failed after all retries
+	IsDeadLetter bool `xorm:"INDEX NOT NULL DEFAULT false"`
+
 	// History info.
 	IsSucceed       bool
 	RequestContent  string        `xorm:"TEXT"`
@@ -747,24 +896,45 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
-// FindUndeliveredHookTasks represents find the undelivered hook tasks
+// FindUndeliveredHookTasks represents find the undelivered hook tasks which are due, i.e. new tasks
+// and failed ones to be retried now
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
-	if err := x.Where("is_delivered=?", false).Find(&tasks); err != nil {
+	if err := x.Where("is_delivered=? AND next_attempt_unix<=?", false, timeutil.TimeStampNow()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
-// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository
+// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository which are due
 func FindRepoUndeliveredHookTasks(repoID int64) ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 5)
-	if err := x.Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+	if err := x.Where("repo_id=? AND is_delivered=? AND next_attempt_unix<=?", repoID, false, timeutil.TimeStampNow()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
+// FindDeadLetterHookTasks returns the hook tasks of a webhook whose delivery failed after all retries
+func FindDeadLetterHookTasks(hookID int64, listOptions ListOptions) ([]*HookTask, int64, error) {
+	sess := listOptions.setSessionPagination(x.Where("hook_id=? AND is_dead_letter=?", hookID, true))
+	tasks := make([]*HookTask, 0, listOptions.PageSize)
+	count, err := sess.Desc("id").FindAndCount(&tasks)
+	return tasks, count, err
+}
+
+// RedeliverHookTasks queues the dead-lettered hook tasks of a webhook for delivery again,
+// either the given ones or all if none are given. It returns the number of queued tasks.
+func RedeliverHookTasks(hookID int64, taskIDs []int64) (int64, error) {
+	cond := builder.Eq{"hook_id": hookID, "is_dead_letter": true}
+	if len(taskIDs) > 0 {
+		cond["id"] = taskIDs
+	}
+	return x.Where(cond).
+		Cols("is_delivered", "is_dead_letter", "attempts", "next_attempt_unix").
+		Update(&HookTask{})
+}
+
 // CleanupHookTaskTable deletes rows from hook_task as needed.
 func CleanupHookTaskTable(ctx context.Context, cleanupType HookTaskCleanupType, olderThan time.Duration, numberToKeep int) error {
 	log.Trace("Doing: CleanupHookTaskTable")
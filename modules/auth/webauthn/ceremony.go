@@ -0,0 +1,236 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxCredentialIDLength is the maximum length of a credential id which is accepted
+const MaxCredentialIDLength = 300
+
+// Authenticator data flags
+const (
+	flagUserPresent            byte = 0x01
+	flagUserVerified           byte = 0x04
+	flagAttestedCredentialData byte = 0x40
+)
+
+// ErrInvalidResponse is returned if the response of the authenticator can't be verified
+type ErrInvalidResponse struct {
+	Reason string
+}
+
+func (err ErrInvalidResponse) Error() string {
+	return "webauthn: " + err.Reason
+}
+
+// IsErrInvalidResponse checks if an error is a ErrInvalidResponse.
+func IsErrInvalidResponse(err error) bool {
+	_, ok := err.(ErrInvalidResponse)
+	return ok
+}
+
+func invalid(format string, args ...interface{}) error {
+	return ErrInvalidResponse{Reason: fmt.Sprintf(format, args...)}
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// verifyClientData checks the type, the challenge and the origin of the client data
+func verifyClientData(raw []byte, ceremony string, session *SessionData) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return invalid("malformed client data")
+	}
+	if cd.Type != ceremony {
+		return invalid("unexpected ceremony %q", cd.Type)
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(cd.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(challenge, session.Challenge) != 1 {
+		return invalid("challenge mismatch")
+	}
+	if cd.Origin != RelyingPartyOrigin() {
+		return invalid("origin %q does not match %q", cd.Origin, RelyingPartyOrigin())
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, invalid("authenticator data is too short")
+	}
+	ad := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+	if ad.Flags&flagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return nil, invalid("attested credential data is too short")
+		}
+		ad.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, invalid("credential id is truncated")
+		}
+		ad.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, invalid("malformed credential public key: %v", err)
+		}
+		ad.PublicKey = rest[:len(rest)-len(after)]
+	}
+	return ad, nil
+}
+
+func (ad *authenticatorData) verify(session *SessionData, rpID string) error {
+	hash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(ad.RPIDHash, hash[:]) {
+		return invalid("relying party id mismatch")
+	}
+	if ad.Flags&flagUserPresent == 0 {
+		return invalid("user not present")
+	}
+	if session.UserVerification == UserVerificationRequired && ad.Flags&flagUserVerified == 0 {
+		return invalid("user not verified")
+	}
+	return nil
+}
+
+// FinishRegistration verifies the response of navigator.credentials.create() and returns the new credential.
+// The attestation statement is not verified, the relying party asks for "none" attestation.
+func FinishRegistration(session *SessionData, resp *CredentialCreationResponse) (*Credential, error) {
+	if session == nil {
+		return nil, errors.New("webauthn: no registration session")
+	}
+	if resp.Type != "public-key" {
+		return nil, invalid("unexpected credential type %q", resp.Type)
+	}
+	if err := verifyClientData(resp.Response.ClientDataJSON, "webauthn.create", session); err != nil {
+		return nil, err
+	}
+
+	v, _, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, invalid("malformed attestation object: %v", err)
+	}
+	attestation, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, invalid("malformed attestation object")
+	}
+	format, _ := attestation["fmt"].(string)
+	rawAuthData, _ := attestation["authData"].([]byte)
+
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := ad.verify(session, RelyingPartyID()); err != nil {
+		return nil, err
+	}
+	if ad.CredentialID == nil {
+		return nil, invalid("missing attested credential data")
+	}
+	if len(ad.CredentialID) > MaxCredentialIDLength {
+		return nil, invalid("credential id is too long")
+	}
+	if !bytes.Equal(ad.CredentialID, resp.RawID) {
+		return nil, invalid("credential id mismatch")
+	}
+	if _, err := ParsePublicKey(ad.PublicKey); err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	return &Credential{
+		ID:              append([]byte(nil), ad.CredentialID...),
+		PublicKey:       append([]byte(nil), ad.PublicKey...),
+		AttestationType: format,
+		AAGUID:          append([]byte(nil), ad.AAGUID...),
+		SignCount:       ad.SignCount,
+	}, nil
+}
+
+// FinishLogin verifies the response of navigator.credentials.get() for the given credential.
+// The sign count of the credential is updated if the assertion is valid.
+func FinishLogin(session *SessionData, resp *CredentialAssertionResponse, cred *Credential) error {
+	if session == nil {
+		return errors.New("webauthn: no login session")
+	}
+	if resp.Type != "public-key" {
+		return invalid("unexpected credential type %q", resp.Type)
+	}
+	if !bytes.Equal(resp.RawID, cred.ID) {
+		return invalid("credential id mismatch")
+	}
+	if len(session.AllowedIDs) > 0 {
+		allowed := false
+		for _, id := range session.AllowedIDs {
+			if bytes.Equal(id, cred.ID) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return invalid("credential is not allowed")
+		}
+	}
+	if err := verifyClientData(resp.Response.ClientDataJSON, "webauthn.get", session); err != nil {
+		return err
+	}
+
+	ad, err := parseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return err
+	}
+	rpID := RelyingPartyID()
+	if resp.ClientExtensionResults.AppID && session.AppID != "" {
+		rpID = session.AppID
+	}
+	if err := ad.verify(session, rpID); err != nil {
+		return err
+	}
+
+	key, err := ParsePublicKey(cred.PublicKey)
+	if err != nil {
+		return err
+	}
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), resp.Response.AuthenticatorData...), clientDataHash[:]...)
+	if err := key.Verify(signed, resp.Response.Signature); err != nil {
+		return invalid("%v", err)
+	}
+
+	// A counter which doesn't increase hints at a cloned authenticator
+	if (ad.SignCount != 0 || cred.SignCount != 0) && ad.SignCount <= cred.SignCount {
+		return invalid("sign count %d is not greater than %d", ad.SignCount, cred.SignCount)
+	}
+	cred.SignCount = ad.SignCount
+	return nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package webauthn implements the relying party side of the WebAuthn
+// registration and authentication ceremonies.
+package webauthn
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	challengeLength = 32
+	timeout         = 60000
+)
+
+// User verification requirements
+const (
+	UserVerificationRequired    = "required"
+	UserVerificationPreferred   = "preferred"
+	UserVerificationDiscouraged = "discouraged"
+)
+
+// URLEncodedBase64 is a byte slice which is encoded as unpadded base64url in JSON
+type URLEncodedBase64 []byte
+
+// MarshalJSON implements json.Marshaler
+func (b URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// RelyingPartyEntity describes the relying party
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity describes the user account of a credential
+type UserEntity struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+// CredentialParameter describes an accepted credential type
+type CredentialParameter struct {
+	Type      string `json:"type"`
+	Algorithm int64  `json:"alg"`
+}
+
+// CredentialDescriptor identifies a registered credential
+type CredentialDescriptor struct {
+	Type string           `json:"type"`
+	ID   URLEncodedBase64 `json:"id"`
+}
+
+// AuthenticatorSelection describes the requirements on the authenticator
+type AuthenticatorSelection struct {
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	ResidentKey        string `json:"residentKey,omitempty"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// PublicKeyCredentialCreationOptions are the options passed to navigator.credentials.create()
+type PublicKeyCredentialCreationOptions struct {
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	RelyingParty           RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Parameters             []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// PublicKeyCredentialRequestOptions are the options passed to navigator.credentials.get()
+type PublicKeyCredentialRequestOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int                    `json:"timeout"`
+	RelyingPartyID   string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+	Extensions       map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// CredentialCreation wraps the creation options like the browser API expects them
+type CredentialCreation struct {
+	PublicKey PublicKeyCredentialCreationOptions `json:"publicKey"`
+}
+
+// CredentialAssertion wraps the request options like the browser API expects them
+type CredentialAssertion struct {
+	PublicKey PublicKeyCredentialRequestOptions `json:"publicKey"`
+}
+
+// SessionData is stored in the session between the start and the end of a ceremony
+type SessionData struct {
+	Challenge        []byte
+	UserID           int64
+	UserVerification string
+	AllowedIDs       [][]byte
+	AppID            string
+}
+
+// CredentialCreationResponse is the serialized result of navigator.credentials.create()
+type CredentialCreationResponse struct {
+	ID       string           `json:"id"`
+	RawID    URLEncodedBase64 `json:"rawId"`
+	Type     string           `json:"type"`
+	Response struct {
+		ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+		AttestationObject URLEncodedBase64 `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// CredentialAssertionResponse is the serialized result of navigator.credentials.get()
+type CredentialAssertionResponse struct {
+	ID       string           `json:"id"`
+	RawID    URLEncodedBase64 `json:"rawId"`
+	Type     string           `json:"type"`
+	Response struct {
+		ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+		AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+		Signature         URLEncodedBase64 `json:"signature"`
+		UserHandle        URLEncodedBase64 `json:"userHandle"`
+	} `json:"response"`
+	ClientExtensionResults struct {
+		AppID bool `json:"appid"`
+	} `json:"clientExtensionResults"`
+}
+
+// Credential is a verified credential
+type Credential struct {
+	ID              []byte
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32
+}
+
+// Descriptor returns the descriptor of the credential
+func (c *Credential) Descriptor() CredentialDescriptor {
+	return CredentialDescriptor{Type: "public-key", ID: c.ID}
+}
+
+// RelyingPartyID returns the relying party id which is the host name of the instance
+func RelyingPartyID() string {
+	u, err := url.Parse(setting.AppURL)
+	if err != nil {
+		return setting.Domain
+	}
+	return u.Hostname()
+}
+
+// RelyingPartyOrigin returns the origin the ceremonies have to be performed on
+func RelyingPartyOrigin() string {
+	u, err := url.Parse(setting.AppURL)
+	if err != nil {
+		return strings.TrimSuffix(setting.AppURL, "/")
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func newChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// BeginRegistration creates the options for the registration of a new credential.
+// Passwordless credentials are stored on the authenticator (resident keys) and
+// require user verification, so they can be used without entering a password.
+func BeginRegistration(user UserEntity, exclude []CredentialDescriptor, passwordless bool) (*CredentialCreation, *SessionData, error) {
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selection := AuthenticatorSelection{
+		UserVerification: UserVerificationDiscouraged,
+	}
+	if passwordless {
+		selection = AuthenticatorSelection{
+			RequireResidentKey: true,
+			ResidentKey:        "required",
+			UserVerification:   UserVerificationRequired,
+		}
+	}
+
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Algorithm: alg})
+	}
+	if exclude == nil {
+		exclude = []CredentialDescriptor{}
+	}
+
+	creation := &CredentialCreation{
+		PublicKey: PublicKeyCredentialCreationOptions{
+			Challenge: challenge,
+			RelyingParty: RelyingPartyEntity{
+				ID:   RelyingPartyID(),
+				Name: setting.AppName,
+			},
+			User:                   user,
+			Parameters:             params,
+			Timeout:                timeout,
+			ExcludeCredentials:     exclude,
+			AuthenticatorSelection: selection,
+			Attestation:            "none",
+		},
+	}
+	session := &SessionData{
+		Challenge:        challenge,
+		UserVerification: selection.UserVerification,
+	}
+	return creation, session, nil
+}
+
+// BeginLogin creates the options for an assertion of one of the allowed credentials.
+// Without allowed credentials the authenticator picks a discoverable credential and
+// has to verify the user. If legacyAppID is set, credentials registered with the
+// U2F API can be used as well.
+func BeginLogin(userID int64, allowed []CredentialDescriptor, legacyAppID string) (*CredentialAssertion, *SessionData, error) {
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := &SessionData{
+		Challenge:        challenge,
+		UserID:           userID,
+		UserVerification: UserVerificationDiscouraged,
+		AppID:            legacyAppID,
+	}
+	if len(allowed) == 0 {
+		allowed = []CredentialDescriptor{}
+		session.UserVerification = UserVerificationRequired
+	}
+	for _, d := range allowed {
+		session.AllowedIDs = append(session.AllowedIDs, d.ID)
+	}
+
+	assertion := &CredentialAssertion{
+		PublicKey: PublicKeyCredentialRequestOptions{
+			Challenge:        challenge,
+			Timeout:          timeout,
+			RelyingPartyID:   RelyingPartyID(),
+			AllowCredentials: allowed,
+			UserVerification: session.UserVerification,
+		},
+	}
+	if legacyAppID != "" {
+		assertion.PublicKey.Extensions = map[string]interface{}{"appid": legacyAppID}
+	}
+	return assertion, session, nil
+}
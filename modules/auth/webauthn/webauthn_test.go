@@ -0,0 +1,220 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testAuthenticator is a software authenticator with a single P-256 credential
+type testAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &testAuthenticator{key: key, id: []byte("test-credential-id")}
+}
+
+func (a *testAuthenticator) clientData(ceremony string, challenge []byte, origin string) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	return data
+}
+
+func (a *testAuthenticator) authData(rpID string, flags byte, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	var buf bytes.Buffer
+	buf.Write(hash[:])
+	if attested {
+		flags |= flagAttestedCredentialData
+	}
+	buf.WriteByte(flags)
+	_ = binary.Write(&buf, binary.BigEndian, a.signCount)
+	if attested {
+		buf.Write(make([]byte, 16))
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(a.id)))
+		buf.Write(a.id)
+		point := elliptic.Marshal(elliptic.P256(), a.key.X, a.key.Y)
+		key, _ := EncodeEC2PublicKey(point)
+		buf.Write(key)
+	}
+	return buf.Bytes()
+}
+
+func (a *testAuthenticator) create(t *testing.T, creation *CredentialCreation) *CredentialCreationResponse {
+	var attestation bytes.Buffer
+	attestation.Write([]byte{0xa3})
+	assert.NoError(t, encodeCBOR(&attestation, "fmt"))
+	assert.NoError(t, encodeCBOR(&attestation, "none"))
+	assert.NoError(t, encodeCBOR(&attestation, "attStmt"))
+	attestation.Write([]byte{0xa0})
+	assert.NoError(t, encodeCBOR(&attestation, "authData"))
+	assert.NoError(t, encodeCBOR(&attestation, a.authData(creation.PublicKey.RelyingParty.ID, flagUserPresent|flagUserVerified, true)))
+
+	resp := &CredentialCreationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.id),
+		RawID: a.id,
+		Type:  "public-key",
+	}
+	resp.Response.ClientDataJSON = a.clientData("webauthn.create", creation.PublicKey.Challenge, RelyingPartyOrigin())
+	resp.Response.AttestationObject = attestation.Bytes()
+	return resp
+}
+
+func (a *testAuthenticator) get(t *testing.T, challenge []byte, rpID string, flags byte) *CredentialAssertionResponse {
+	a.signCount++
+	authData := a.authData(rpID, flags, false)
+	clientData := a.clientData("webauthn.get", challenge, RelyingPartyOrigin())
+	clientDataHash := sha256.Sum256(clientData)
+	hash := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, hash[:])
+	assert.NoError(t, err)
+
+	resp := &CredentialAssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.id),
+		RawID: a.id,
+		Type:  "public-key",
+	}
+	resp.Response.ClientDataJSON = clientData
+	resp.Response.AuthenticatorData = authData
+	resp.Response.Signature = sig
+	return resp
+}
+
+func setTestAppURL(t *testing.T) {
+	oldAppURL := setting.AppURL
+	setting.AppURL = "https://gitea.example.com:3000/"
+	t.Cleanup(func() {
+		setting.AppURL = oldAppURL
+	})
+}
+
+func TestRelyingParty(t *testing.T) {
+	setTestAppURL(t)
+	assert.Equal(t, "gitea.example.com", RelyingPartyID())
+	assert.Equal(t, "https://gitea.example.com:3000", RelyingPartyOrigin())
+}
+
+func TestURLEncodedBase64(t *testing.T) {
+	data, err := json.Marshal(URLEncodedBase64{0xfb, 0xff})
+	assert.NoError(t, err)
+	assert.Equal(t, `"-_8"`, string(data))
+
+	var b URLEncodedBase64
+	assert.NoError(t, json.Unmarshal([]byte(`"-_8="`), &b))
+	assert.Equal(t, URLEncodedBase64{0xfb, 0xff}, b)
+}
+
+func TestCBOR(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, encodeCBOR(&buf, map[int64]interface{}{
+		-2:  []byte{1, 2},
+		1:   2,
+		3:   -7,
+		500: "text",
+	}))
+	v, rest, err := decodeCBOR(append(buf.Bytes(), 0xf5))
+	assert.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		int64(-2):  []byte{1, 2},
+		int64(1):   int64(2),
+		int64(3):   int64(-7),
+		int64(500): "text",
+	}, v)
+	assert.Equal(t, []byte{0xf5}, rest)
+
+	v, _, err = decodeCBOR(rest)
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	_, _, err = decodeCBOR([]byte{0x5a, 0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err)
+	_, _, err = decodeCBOR([]byte{0x9f})
+	assert.Error(t, err)
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	setTestAppURL(t)
+	authenticator := newTestAuthenticator(t)
+
+	creation, session, err := BeginRegistration(UserEntity{ID: []byte("1"), Name: "user1"}, nil, true)
+	assert.NoError(t, err)
+	assert.True(t, creation.PublicKey.AuthenticatorSelection.RequireResidentKey)
+	assert.Equal(t, UserVerificationRequired, session.UserVerification)
+
+	cred, err := FinishRegistration(session, authenticator.create(t, creation))
+	assert.NoError(t, err)
+	assert.Equal(t, authenticator.id, cred.ID)
+	assert.Equal(t, "none", cred.AttestationType)
+
+	// replayed registration with another challenge
+	_, otherSession, err := BeginRegistration(UserEntity{ID: []byte("1"), Name: "user1"}, nil, false)
+	assert.NoError(t, err)
+	_, err = FinishRegistration(otherSession, authenticator.create(t, creation))
+	assert.True(t, IsErrInvalidResponse(err))
+
+	assertion, session, err := BeginLogin(1, []CredentialDescriptor{cred.Descriptor()}, "")
+	assert.NoError(t, err)
+	assert.Len(t, assertion.PublicKey.AllowCredentials, 1)
+	assert.NoError(t, FinishLogin(session, authenticator.get(t, assertion.PublicKey.Challenge, RelyingPartyID(), flagUserPresent), cred))
+	assert.EqualValues(t, 1, cred.SignCount)
+
+	// a sign count which doesn't increase is rejected
+	authenticator.signCount = 0
+	err = FinishLogin(session, authenticator.get(t, assertion.PublicKey.Challenge, RelyingPartyID(), flagUserPresent), cred)
+	assert.True(t, IsErrInvalidResponse(err))
+
+	// passwordless login requires user verification
+	authenticator.signCount = 10
+	assertion, session, err = BeginLogin(0, nil, "")
+	assert.NoError(t, err)
+	assert.Empty(t, assertion.PublicKey.AllowCredentials)
+	err = FinishLogin(session, authenticator.get(t, assertion.PublicKey.Challenge, RelyingPartyID(), flagUserPresent), cred)
+	assert.True(t, IsErrInvalidResponse(err))
+	assert.NoError(t, FinishLogin(session, authenticator.get(t, assertion.PublicKey.Challenge, RelyingPartyID(), flagUserPresent|flagUserVerified), cred))
+
+	// wrong relying party
+	err = FinishLogin(session, authenticator.get(t, assertion.PublicKey.Challenge, "evil.example.com", flagUserPresent|flagUserVerified), cred)
+	assert.True(t, IsErrInvalidResponse(err))
+}
+
+func TestLoginWithLegacyAppID(t *testing.T) {
+	setTestAppURL(t)
+	authenticator := newTestAuthenticator(t)
+	publicKey, err := EncodeEC2PublicKey(elliptic.Marshal(elliptic.P256(), authenticator.key.X, authenticator.key.Y))
+	assert.NoError(t, err)
+	cred := &Credential{ID: authenticator.id, PublicKey: publicKey, AttestationType: "fido-u2f"}
+
+	appID := "https://gitea.example.com:3000"
+	assertion, session, err := BeginLogin(1, []CredentialDescriptor{cred.Descriptor()}, appID)
+	assert.NoError(t, err)
+	assert.Equal(t, appID, assertion.PublicKey.Extensions["appid"])
+
+	resp := authenticator.get(t, assertion.PublicKey.Challenge, appID, flagUserPresent)
+	err = FinishLogin(session, resp, cred)
+	assert.True(t, IsErrInvalidResponse(err))
+
+	resp.ClientExtensionResults.AppID = true
+	assert.NoError(t, FinishLogin(session, resp, cred))
+}
@@ -184,6 +184,21 @@ func (ctx *APIContext) SetLinkHeader(total, pageSize int) {
 	}
 }
 
+// AccessToken returns the personal access token used to authenticate the request, nil if none was used
+func (ctx *APIContext) AccessToken() *models.AccessToken {
+	token, _ := ctx.Data["ApiToken"].(*models.AccessToken)
+	return token
+}
+
+// AccessTokenRepoIDs returns the repositories the access token of the request is restricted to,
+// nil if the request isn't restricted to some repositories.
+func (ctx *APIContext) AccessTokenRepoIDs() []int64 {
+	if token := ctx.AccessToken(); token != nil {
+		return token.RepoIDs
+	}
+	return nil
+}
+
 // RequireCSRF requires a validated a CSRF token
 func (ctx *APIContext) RequireCSRF() {
 	headerToken := ctx.Req.Header.Get(ctx.csrf.GetHeaderName())
@@ -64,8 +64,9 @@ type Context struct {
 	IsSigned    bool
 	IsBasicAuth bool
 
-	Repo *Repository
-	Org  *Organization
+	Repo    *Repository
+	Org     *Organization
+	Package *Package
 }
 
 // GetData returns the data
@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Package contains the owner and the access mode of the current user on its packages
+type Package struct {
+	Owner      *models.User
+	AccessMode models.AccessMode
+}
+
+// PackageAssignmentAPI returns a middleware to handle context-package assignment for api routes
+func PackageAssignmentAPI() func(ctx *APIContext) {
+	return func(ctx *APIContext) {
+		if !setting.Packages.Enabled {
+			ctx.NotFound()
+			return
+		}
+
+		owner, err := models.GetUserByName(ctx.Params("username"))
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+
+		accessMode, err := models.PackageAccessMode(ctx.User, owner)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "PackageAccessMode", err)
+			return
+		}
+
+		ctx.Package = &Package{
+			Owner:      owner,
+			AccessMode: accessMode,
+		}
+	}
+}
@@ -116,6 +116,7 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 		MergeWhitelistTeams:           mergeWhitelistTeams,
 		EnableStatusCheck:             bp.EnableStatusCheck,
 		StatusCheckContexts:           bp.StatusCheckContexts,
+		EnableMergeQueue:              bp.EnableMergeQueue,
 		RequiredApprovals:             bp.RequiredApprovals,
 		EnableApprovalsWhitelist:      bp.EnableApprovalsWhitelist,
 		ApprovalsWhitelistUsernames:   approvalsWhitelistUsernames,
@@ -222,8 +223,9 @@ func ToGPGKeyEmail(email *models.EmailAddress) *api.GPGKeyEmail {
 // ToHook convert models.Webhook to api.Hook
 func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 	config := map[string]string{
-		"url":          w.URL,
-		"content_type": w.ContentType.Name(),
+		"url":                 w.URL,
+		"content_type":        w.ContentType.Name(),
+		"signature_algorithm": w.GetSignatureAlgorithm(),
 	}
 	if w.Type == models.SLACK {
 		s := webhook.GetSlackHook(w)
@@ -231,20 +233,47 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 		config["username"] = s.Username
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
+	} else if w.Type == models.CUSTOM {
+		c := webhook.GetCustomHook(w)
+		config["content_type"] = c.ContentType
+		config["http_method"] = w.HTTPMethod
+		config["template"] = c.Template
+		config["headers"] = c.Headers
 	}
 
 	return &api.Hook{
-		ID:      w.ID,
-		Type:    string(w.Type),
-		URL:     fmt.Sprintf("%s/settings/hooks/%d", repoLink, w.ID),
-		Active:  w.IsActive,
-		Config:  config,
-		Events:  w.EventsArray(),
-		Updated: w.UpdatedUnix.AsTime(),
-		Created: w.CreatedUnix.AsTime(),
+		ID:                  w.ID,
+		Type:                string(w.Type),
+		URL:                 fmt.Sprintf("%s/settings/hooks/%d", repoLink, w.ID),
+		Active:              w.IsActive,
+		Config:              config,
+		Events:              w.EventsArray(),
+		MaxRetries:          w.MaxRetries,
+		DisableAfterRetries: w.DisableAfterRetries,
+		ClientCertificate:   w.ClientCertificate,
+		CACertificates:      w.CACertificates,
+		Updated:             w.UpdatedUnix.AsTime(),
+		Created:             w.CreatedUnix.AsTime(),
 	}
 }
 
+// ToHookDelivery convert models.HookTask to api.HookDelivery
+func ToHookDelivery(t *models.HookTask) *api.HookDelivery {
+	delivery := &api.HookDelivery{
+		ID:        t.ID,
+		UUID:      t.UUID,
+		Event:     string(t.EventType),
+		URL:       t.URL,
+		Attempts:  t.Attempts,
+		IsSucceed: t.IsSucceed,
+		Delivered: time.Unix(0, t.Delivered),
+	}
+	if t.ResponseInfo != nil {
+		delivery.ResponseStatus = t.ResponseInfo.Status
+	}
+	return delivery
+}
+
 // ToGitHook convert git.Hook to api.GitHook
 func ToGitHook(h *git.Hook) *api.GitHook {
 	return &api.GitHook{
@@ -345,6 +374,36 @@ func ToOAuth2Application(app *models.OAuth2Application) *api.OAuth2Application {
 	}
 }
 
+// ToAccessToken convert a models.AccessToken to api.AccessToken, the secret token is only set if it is known
+func ToAccessToken(t *models.AccessToken, repos []*models.Repository) *api.AccessToken {
+	scopes := t.Scope.Scopes()
+	apiScopes := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		apiScopes = append(apiScopes, string(s))
+	}
+
+	repoNames := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		repoNames = append(repoNames, repo.FullName())
+	}
+
+	var expiresAt *time.Time
+	if t.ExpiresUnix != 0 {
+		expires := t.ExpiresUnix.AsTime()
+		expiresAt = &expires
+	}
+
+	return &api.AccessToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+		Scopes:         apiScopes,
+		Repositories:   repoNames,
+		ExpiresAt:      expiresAt,
+	}
+}
+
 // ToLFSLock convert a LFSLock to api.LFSLock
 func ToLFSLock(l *models.LFSLock) *api.LFSLock {
 	return &api.LFSLock{
@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToPushMirror convert from models.PushMirror to api.PushMirror.
+// remoteAddress is expected to already be stripped of credentials.
+func ToPushMirror(pm *models.PushMirror, remoteAddress string) *api.PushMirror {
+	var lastUpdate *time.Time
+	if pm.LastUpdateUnix != 0 {
+		t := pm.LastUpdateUnix.AsTime()
+		lastUpdate = &t
+	}
+	return &api.PushMirror{
+		ID:            pm.ID,
+		RepoName:      pm.Repo.Name,
+		RemoteName:    pm.RemoteName,
+		RemoteAddress: remoteAddress,
+		Created:       pm.CreatedUnix.AsTime(),
+		LastUpdate:    lastUpdate,
+		LastError:     pm.LastError,
+		Interval:      pm.Interval.String(),
+		SyncOnCommit:  pm.SyncOnCommit,
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToPackage convert a models.Package to api.Package
+// The attributes of the package must be loaded and the doer is used to decide
+// which information of the owner and the linked repository is shown.
+func ToPackage(p *models.Package, doer *models.User) (*api.Package, error) {
+	var repo *api.Repository
+	if p.Repo != nil {
+		mode, err := models.AccessLevel(doer, p.Repo)
+		if err != nil {
+			return nil, err
+		}
+		if mode >= models.AccessModeRead {
+			repo = ToRepo(p.Repo, mode)
+		}
+	}
+
+	return &api.Package{
+		ID:         p.ID,
+		Owner:      ToUser(p.Owner, doer),
+		Repository: repo,
+		Type:       p.Type.Name(),
+		Name:       p.Name,
+		CreatedAt:  p.CreatedUnix.AsTime(),
+	}, nil
+}
+
+// ToPackageVersion convert a models.PackageVersion to api.PackageVersion
+// The creator of the version and the blobs of the files must be loaded.
+func ToPackageVersion(pv *models.PackageVersion, files []*models.PackageFile, doer *models.User) *api.PackageVersion {
+	apiFiles := make([]*api.PackageFile, 0, len(files))
+	for _, pf := range files {
+		apiFiles = append(apiFiles, ToPackageFile(pf))
+	}
+
+	return &api.PackageVersion{
+		ID:            pv.ID,
+		Creator:       ToUser(pv.Creator, doer),
+		Version:       pv.Version,
+		DownloadCount: pv.DownloadCount,
+		CreatedAt:     pv.CreatedUnix.AsTime(),
+		Files:         apiFiles,
+	}
+}
+
+// ToPackageFile convert a models.PackageFile to api.PackageFile
+func ToPackageFile(pf *models.PackageFile) *api.PackageFile {
+	return &api.PackageFile{
+		ID:         pf.ID,
+		Size:       pf.Blob.Size,
+		Name:       pf.Name,
+		HashMD5:    pf.Blob.HashMD5,
+		HashSHA1:   pf.Blob.HashSHA1,
+		HashSHA256: pf.Blob.HashSHA256,
+		HashSHA512: pf.Blob.HashSHA512,
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+var projectBoardTypeNames = map[models.ProjectBoardType]string{
+	models.ProjectBoardTypeNone:        "none",
+	models.ProjectBoardTypeBasicKanban: "basic_kanban",
+	models.ProjectBoardTypeBugTriage:   "bug_triage",
+}
+
+// ToProjectBoardType converts the API name of a board type, it returns false for unknown names
+func ToProjectBoardType(name string) (models.ProjectBoardType, bool) {
+	if name == "" {
+		return models.ProjectBoardTypeNone, true
+	}
+	for typ, n := range projectBoardTypeNames {
+		if n == name {
+			return typ, true
+		}
+	}
+	return models.ProjectBoardTypeNone, false
+}
+
+// ToAPIProject converts Project to API format
+func ToAPIProject(p *models.Project) *api.Project {
+	apiProject := &api.Project{
+		ID:           p.ID,
+		Title:        p.Title,
+		Description:  p.Description,
+		BoardType:    projectBoardTypeNames[p.BoardType],
+		State:        api.StateOpen,
+		OpenIssues:   p.NumOpenIssues(),
+		ClosedIssues: p.NumClosedIssues(),
+		HTMLURL:      p.HTMLURL(),
+		Created:      p.CreatedUnix.AsTime(),
+		Updated:      p.UpdatedUnix.AsTimePtr(),
+	}
+	if p.IsClosed {
+		apiProject.State = api.StateClosed
+		apiProject.Closed = p.ClosedDateUnix.AsTimePtr()
+	}
+	if p.Creator != nil {
+		apiProject.Creator = ToUser(p.Creator, nil)
+	}
+	return apiProject
+}
+
+// ToAPIProjectBoard converts ProjectBoard to API format
+func ToAPIProjectBoard(b *models.ProjectBoard) *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:      b.ID,
+		Title:   b.Title,
+		Default: b.Default,
+		Sorting: b.Sorting,
+		Created: b.CreatedUnix.AsTime(),
+		Updated: b.UpdatedUnix.AsTime(),
+	}
+}
@@ -33,6 +33,12 @@ func ToGitServiceType(value string) structs.GitServiceType {
 		return structs.GitlabService
 	case "gogs":
 		return structs.GogsService
+	case "gitbucket":
+		return structs.GitBucketService
+	case "onedev":
+		return structs.OneDevService
+	case "bitbucketserver":
+		return structs.BitbucketServerService
 	default:
 		return structs.PlainGitService
 	}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"container/list"
+
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToWikiCommit converts a git commit of a wiki repository to a WikiCommit
+func ToWikiCommit(commit *git.Commit) *api.WikiCommit {
+	return &api.WikiCommit{
+		ID:        commit.ID.String(),
+		Author:    ToCommitUser(commit.Author),
+		Committer: ToCommitUser(commit.Committer),
+		Message:   commit.Summary(),
+	}
+}
+
+// ToWikiCommitList converts a list of git commits of a wiki repository to a list of WikiCommits
+func ToWikiCommitList(commits *list.List) []*api.WikiCommit {
+	result := make([]*api.WikiCommit, 0, commits.Len())
+	for e := commits.Front(); e != nil; e = e.Next() {
+		result = append(result, ToWikiCommit(e.Value.(*git.Commit)))
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package doctor
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"xorm.io/builder"
+)
+
+func checkPushMirrors(logger log.Logger, autofix bool) error {
+	numMirrors := 0
+	numBroken := 0
+	numFailing := 0
+	err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.PushMirror),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			m := bean.(*models.PushMirror)
+			numMirrors++
+
+			if m.Repo == nil {
+				numBroken++
+				if autofix {
+					if err := models.DeletePushMirrorByID(m.ID); err != nil {
+						logger.Critical("Unable to delete push mirror %d without repository. ERROR: %v", m.ID, err)
+						return fmt.Errorf("Unable to delete push mirror %d without repository. ERROR: %v", m.ID, err)
+					}
+					logger.Info("Deleted push mirror %d without existing repository %d", m.ID, m.RepoID)
+				} else {
+					logger.Warn("Push mirror %d references non-existent repository %d", m.ID, m.RepoID)
+				}
+				return nil
+			}
+
+			if _, err := git.NewCommand("config", "--get", "remote."+m.RemoteName+".url").RunInDir(m.Repo.RepoPath()); err != nil {
+				numBroken++
+				if autofix {
+					if err := models.DeletePushMirrorByID(m.ID); err != nil {
+						logger.Critical("Unable to delete push mirror %d with missing remote %s in %s. ERROR: %v", m.ID, m.RemoteName, m.Repo.FullName(), err)
+						return fmt.Errorf("Unable to delete push mirror %d with missing remote %s in %s. ERROR: %v", m.ID, m.RemoteName, m.Repo.FullName(), err)
+					}
+					logger.Info("Deleted push mirror %d as its remote %s is missing in %s", m.ID, m.RemoteName, m.Repo.FullName())
+				} else {
+					logger.Warn("Push mirror %d: remote %s is missing in %s", m.ID, m.RemoteName, m.Repo.FullName())
+				}
+				return nil
+			}
+
+			if len(m.LastError) > 0 {
+				numFailing++
+				logger.Warn("Push mirror %d of %s failed on last sync: %s", m.ID, m.Repo.FullName(), strings.TrimSpace(m.LastError))
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if numBroken > 0 && !autofix {
+		logger.Critical("%d broken push mirrors of %d push mirrors total", numBroken, numMirrors)
+		return fmt.Errorf("%d broken push mirrors of %d push mirrors total", numBroken, numMirrors)
+	}
+	logger.Info("%d push mirrors checked: %d broken, %d failing on last sync", numMirrors, numBroken, numFailing)
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check push mirrors for missing remotes",
+		Name:      "check-push-mirrors",
+		IsDefault: false,
+		Run:       checkPushMirrors,
+		Priority:  7,
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/analyze"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/formatters/html"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
@@ -66,17 +67,7 @@ func Code(fileName, code string) string {
 	htmlbuf := bytes.Buffer{}
 	htmlw := bufio.NewWriter(&htmlbuf)
 
-	if val, ok := highlightMapping[filepath.Ext(fileName)]; ok {
-		//change file name to one with mapped extension so we look that up instead
-		fileName = "mapped." + val
-	}
-
-	lexer := lexers.Match(fileName)
-	if lexer == nil {
-		lexer = lexers.Fallback
-	}
-
-	iterator, err := lexer.Tokenise(nil, string(code))
+	iterator, err := matchLexer(fileName).Tokenise(nil, string(code))
 	if err != nil {
 		log.Error("Can't tokenize code: %v", err)
 		return code
@@ -94,6 +85,69 @@ func Code(fileName, code string) string {
 	return strings.TrimSuffix(htmlbuf.String(), "\n")
 }
 
+// Token is a piece of highlighted code
+type Token struct {
+	// Class is the chroma syntax highlighting class of the token, it may be empty
+	Class string
+	Value string
+}
+
+// Tokens splits a line of code into the tokens it is highlighted with by Code
+func Tokens(fileName, code string) []Token {
+	NewContext()
+
+	if len(code) == 0 || len(code) > sizeLimit {
+		return []Token{{Value: code}}
+	}
+
+	iterator, err := matchLexer(fileName).Tokenise(nil, code)
+	if err != nil {
+		log.Error("Can't tokenize code: %v", err)
+		return []Token{{Value: code}}
+	}
+
+	tokens := make([]Token, 0, 8)
+	length := 0
+	for _, token := range iterator.Tokens() {
+		value := token.Value
+		// Chroma will add newlines for certain lexers, strip them like Code does
+		if length+len(value) > len(code) {
+			value = value[:len(code)-length]
+		}
+		length += len(value)
+		if len(value) == 0 {
+			continue
+		}
+		tokens = append(tokens, Token{Class: tokenClass(token.Type), Value: value})
+	}
+	return tokens
+}
+
+// matchLexer returns the lexer for a file name, taking the custom mapping into account
+func matchLexer(fileName string) chroma.Lexer {
+	if val, ok := highlightMapping[filepath.Ext(fileName)]; ok {
+		//change file name to one with mapped extension so we look that up instead
+		fileName = "mapped." + val
+	}
+
+	lexer := lexers.Match(fileName)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return lexer
+}
+
+// tokenClass returns the class chroma's HTML formatter uses for a token type
+func tokenClass(tokenType chroma.TokenType) string {
+	for tokenType != 0 {
+		if class, ok := chroma.StandardTypes[tokenType]; ok {
+			return class
+		}
+		tokenType = tokenType.Parent()
+	}
+	return chroma.StandardTypes[tokenType]
+}
+
 // File returns map with line lumbers and HTML version of code with chroma syntax highlighting classes
 func File(numLines int, fileName string, code []byte) map[int]string {
 	NewContext()
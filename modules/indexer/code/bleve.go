@@ -90,11 +90,16 @@ func openBleveIndexer(path string, latestVersion int) (bleve.Index, error) {
 
 // RepoIndexerData data stored in the repo indexer
 type RepoIndexerData struct {
-	RepoID    int64
-	CommitID  string
-	Content   string
-	Language  string
-	UpdatedAt time.Time
+	RepoID int64
+	// Branch is empty for the entries of the default branch
+	Branch          string
+	IsDefaultBranch bool
+	CommitID        string
+	Content         string
+	Filename        string
+	Extension       string
+	Language        string
+	UpdatedAt       time.Time
 }
 
 // Type returns the document type, for bleve's mapping.Classifier interface.
@@ -105,7 +110,7 @@ func (d *RepoIndexerData) Type() string {
 const (
 	repoIndexerAnalyzer      = "repoIndexerAnalyzer"
 	repoIndexerDocType       = "repoIndexerDocType"
-	repoIndexerLatestVersion = 5
+	repoIndexerLatestVersion = 7
 )
 
 // createBleveIndexer create a bleve repo indexer if one does not already exist
@@ -124,6 +129,13 @@ func createBleveIndexer(path string, latestVersion int) (bleve.Index, error) {
 	termFieldMapping.Analyzer = analyzer_keyword.Name
 	docMapping.AddFieldMappingsAt("Language", termFieldMapping)
 	docMapping.AddFieldMappingsAt("CommitID", termFieldMapping)
+	docMapping.AddFieldMappingsAt("Filename", termFieldMapping)
+	docMapping.AddFieldMappingsAt("Extension", termFieldMapping)
+	docMapping.AddFieldMappingsAt("Branch", termFieldMapping)
+
+	boolFieldMapping := bleve.NewBooleanFieldMapping()
+	boolFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("IsDefaultBranch", boolFieldMapping)
 
 	timeFieldMapping := bleve.NewDateTimeFieldMapping()
 	timeFieldMapping.IncludeInAll = false
@@ -176,7 +188,7 @@ func NewBleveIndexer(indexDir string) (*BleveIndexer, bool, error) {
 	return indexer, created, err
 }
 
-func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, commitSha string, update fileUpdate, repo *models.Repository, batch rupture.FlushingBatch) error {
+func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, branch, commitSha string, update fileUpdate, repo *models.Repository, batch rupture.FlushingBatch) error {
 	// Ignore vendored files in code search
 	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
 		return nil
@@ -196,7 +208,7 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 	}
 
 	if size > setting.Indexer.MaxIndexerFileSize {
-		return b.addDelete(update.Filename, repo, batch)
+		return b.addDelete(branch, update.Filename, repo, batch)
 	}
 
 	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
@@ -216,18 +228,22 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 		return nil
 	}
 
-	id := filenameIndexerID(repo.ID, update.Filename)
+	id := filenameIndexerID(repo.ID, branch, update.Filename)
 	return batch.Index(id, &RepoIndexerData{
-		RepoID:    repo.ID,
-		CommitID:  commitSha,
-		Content:   string(charset.ToUTF8DropErrors(fileContents)),
-		Language:  analyze.GetCodeLanguage(update.Filename, fileContents),
-		UpdatedAt: time.Now().UTC(),
+		RepoID:          repo.ID,
+		Branch:          branch,
+		IsDefaultBranch: len(branch) == 0,
+		CommitID:        commitSha,
+		Content:         string(charset.ToUTF8DropErrors(fileContents)),
+		Filename:        update.Filename,
+		Extension:       fileExtension(update.Filename),
+		Language:        analyze.GetCodeLanguage(update.Filename, fileContents),
+		UpdatedAt:       time.Now().UTC(),
 	})
 }
 
-func (b *BleveIndexer) addDelete(filename string, repo *models.Repository, batch rupture.FlushingBatch) error {
-	id := filenameIndexerID(repo.ID, filename)
+func (b *BleveIndexer) addDelete(branch, filename string, repo *models.Repository, batch rupture.FlushingBatch) error {
+	id := filenameIndexerID(repo.ID, branch, filename)
 	return batch.Delete(id)
 }
 
@@ -263,7 +279,7 @@ func (b *BleveIndexer) Close() {
 }
 
 // Index indexes the data
-func (b *BleveIndexer) Index(repo *models.Repository, sha string, changes *repoChanges) error {
+func (b *BleveIndexer) Index(repo *models.Repository, branch, sha string, changes *repoChanges) error {
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
 	if len(changes.Updates) > 0 {
 
@@ -271,14 +287,14 @@ func (b *BleveIndexer) Index(repo *models.Repository, sha string, changes *repoC
 		defer cancel()
 
 		for _, update := range changes.Updates {
-			if err := b.addUpdate(batchWriter, batchReader, sha, update, repo, batch); err != nil {
+			if err := b.addUpdate(batchWriter, batchReader, branch, sha, update, repo, batch); err != nil {
 				return err
 			}
 		}
 		cancel()
 	}
 	for _, filename := range changes.RemovedFilenames {
-		if err := b.addDelete(filename, repo, batch); err != nil {
+		if err := b.addDelete(branch, filename, repo, batch); err != nil {
 			return err
 		}
 	}
@@ -287,7 +303,30 @@ func (b *BleveIndexer) Index(repo *models.Repository, sha string, changes *repoC
 
 // Delete deletes indexes by ids
 func (b *BleveIndexer) Delete(repoID int64) error {
-	query := numericEqualityQuery(repoID, "RepoID")
+	return b.deleteByQuery(numericEqualityQuery(repoID, "RepoID"))
+}
+
+// DeleteBranch deletes the indexes of a branch of a repository
+func (b *BleveIndexer) DeleteBranch(repoID int64, branch string) error {
+	return b.deleteByQuery(bleve.NewConjunctionQuery(
+		numericEqualityQuery(repoID, "RepoID"),
+		branchQuery(branch),
+	))
+}
+
+// branchQuery matches the entries of a branch, an empty branch refers to the default branch
+func branchQuery(branch string) query.Query {
+	if len(branch) == 0 {
+		q := bleve.NewBoolFieldQuery(true)
+		q.SetField("IsDefaultBranch")
+		return q
+	}
+	q := bleve.NewTermQuery(branch)
+	q.SetField("Branch")
+	return q
+}
+
+func (b *BleveIndexer) deleteByQuery(query query.Query) error {
 	searchRequest := bleve.NewSearchRequestOptions(query, 2147483647, 0, false)
 	result, err := b.indexer.Search(searchRequest)
 	if err != nil {
@@ -304,36 +343,66 @@ func (b *BleveIndexer) Delete(repoID int64) error {
 
 // Search searches for files in the specified repo.
 // Returns the matching file-paths
-func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+func (b *BleveIndexer) Search(opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
 	var (
 		indexerQuery query.Query
 		keywordQuery query.Query
+		language     = opts.Language
 	)
 
-	if isMatch {
-		prefixQuery := bleve.NewPrefixQuery(keyword)
+	if opts.IsMatch {
+		prefixQuery := bleve.NewPrefixQuery(opts.Keyword)
 		prefixQuery.FieldVal = "Content"
 		keywordQuery = prefixQuery
 	} else {
-		phraseQuery := bleve.NewMatchPhraseQuery(keyword)
+		phraseQuery := bleve.NewMatchPhraseQuery(opts.Keyword)
 		phraseQuery.FieldVal = "Content"
 		phraseQuery.Analyzer = repoIndexerAnalyzer
 		keywordQuery = phraseQuery
 	}
 
-	if len(repoIDs) > 0 {
-		var repoQueries = make([]query.Query, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
+	conjuncts := []query.Query{keywordQuery}
+	if len(opts.RepoIDs) > 0 {
+		var repoQueries = make([]query.Query, 0, len(opts.RepoIDs))
+		for _, repoID := range opts.RepoIDs {
 			repoQueries = append(repoQueries, numericEqualityQuery(repoID, "RepoID"))
 		}
-
-		indexerQuery = bleve.NewConjunctionQuery(
-			bleve.NewDisjunctionQuery(repoQueries...),
-			keywordQuery,
-		)
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(repoQueries...))
+	}
+	if len(opts.Branch) > 0 && len(opts.BranchRepoIDs) > 0 {
+		var branchRepoQueries = make([]query.Query, 0, len(opts.BranchRepoIDs))
+		for _, repoID := range opts.BranchRepoIDs {
+			branchRepoQueries = append(branchRepoQueries, numericEqualityQuery(repoID, "RepoID"))
+		}
+		defaultBranchQuery := bleve.NewBooleanQuery()
+		defaultBranchQuery.AddMust(branchQuery(""))
+		defaultBranchQuery.AddMustNot(branchRepoQueries...)
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(
+			defaultBranchQuery,
+			bleve.NewConjunctionQuery(branchQuery(opts.Branch), bleve.NewDisjunctionQuery(branchRepoQueries...)),
+		))
 	} else {
-		indexerQuery = keywordQuery
+		conjuncts = append(conjuncts, branchQuery(""))
+	}
+	if len(opts.Paths) > 0 {
+		var pathQueries = make([]query.Query, 0, len(opts.Paths))
+		for _, path := range opts.Paths {
+			pathQuery := bleve.NewPrefixQuery(path)
+			pathQuery.FieldVal = "Filename"
+			pathQueries = append(pathQueries, pathQuery)
+		}
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(pathQueries...))
+	}
+	if len(opts.Extensions) > 0 {
+		var extensionQueries = make([]query.Query, 0, len(opts.Extensions))
+		for _, extension := range opts.Extensions {
+			extensionQuery := bleve.NewTermQuery(extension)
+			extensionQuery.FieldVal = "Extension"
+			extensionQueries = append(extensionQueries, extensionQuery)
+		}
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(extensionQueries...))
 	}
+	indexerQuery = bleve.NewConjunctionQuery(conjuncts...)
 
 	// Save for reuse without language filter
 	facetQuery := indexerQuery
@@ -348,8 +417,8 @@ func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, p
 		)
 	}
 
-	from := (page - 1) * pageSize
-	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, pageSize, from, false)
+	from := (opts.Page - 1) * opts.PageSize
+	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, opts.PageSize, from, false)
 	searchRequest.Fields = []string{"Content", "RepoID", "Language", "CommitID", "UpdatedAt"}
 	searchRequest.IncludeLocations = true
 
@@ -383,11 +452,13 @@ func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, p
 		if t, err := time.Parse(time.RFC3339, hit.Fields["UpdatedAt"].(string)); err == nil {
 			updatedUnix = timeutil.TimeStamp(t.Unix())
 		}
+		_, branch, filename := parseIndexerID(hit.ID)
 		searchResults[i] = &SearchResult{
 			RepoID:      int64(hit.Fields["RepoID"].(float64)),
+			Branch:      branch,
 			StartIndex:  startIndex,
 			EndIndex:    endIndex,
-			Filename:    filenameOfIndexerID(hit.ID),
+			Filename:    filename,
 			Content:     hit.Fields["Content"].(string),
 			CommitID:    hit.Fields["CommitID"].(string),
 			UpdatedUnix: updatedUnix,
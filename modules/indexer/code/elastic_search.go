@@ -29,7 +29,7 @@ import (
 )
 
 const (
-	esRepoIndexerLatestVersion = 1
+	esRepoIndexerLatestVersion = 3
 	// multi-match-types, currently only 2 types are used
 	// Reference: https://www.elastic.co/guide/en/elasticsearch/reference/7.0/query-dsl-multi-match-query.html#multi-match-types
 	esMultiMatchTypeBestFields   = "best_fields"
@@ -104,6 +104,22 @@ const (
 					"type": "keyword",
 					"index": true
 				},
+				"filename": {
+					"type": "keyword",
+					"index": true
+				},
+				"extension": {
+					"type": "keyword",
+					"index": true
+				},
+				"branch": {
+					"type": "keyword",
+					"index": true
+				},
+				"is_default_branch": {
+					"type": "boolean",
+					"index": true
+				},
 				"language": {
 					"type": "keyword",
 					"index": true
@@ -175,7 +191,7 @@ func (b *ElasticSearchIndexer) init() (bool, error) {
 	return exists, nil
 }
 
-func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, sha string, update fileUpdate, repo *models.Repository) ([]elastic.BulkableRequest, error) {
+func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, branch, sha string, update fileUpdate, repo *models.Repository) ([]elastic.BulkableRequest, error) {
 	// Ignore vendored files in code search
 	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
 		return nil, nil
@@ -195,7 +211,7 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 	}
 
 	if size > setting.Indexer.MaxIndexerFileSize {
-		return []elastic.BulkableRequest{b.addDelete(update.Filename, repo)}, nil
+		return []elastic.BulkableRequest{b.addDelete(branch, update.Filename, repo)}, nil
 	}
 
 	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
@@ -215,31 +231,35 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 		return nil, nil
 	}
 
-	id := filenameIndexerID(repo.ID, update.Filename)
+	id := filenameIndexerID(repo.ID, branch, update.Filename)
 
 	return []elastic.BulkableRequest{
 		elastic.NewBulkIndexRequest().
 			Index(b.indexerAliasName).
 			Id(id).
 			Doc(map[string]interface{}{
-				"repo_id":    repo.ID,
-				"content":    string(charset.ToUTF8DropErrors(fileContents)),
-				"commit_id":  sha,
-				"language":   analyze.GetCodeLanguage(update.Filename, fileContents),
-				"updated_at": timeutil.TimeStampNow(),
+				"repo_id":           repo.ID,
+				"branch":            branch,
+				"is_default_branch": len(branch) == 0,
+				"content":           string(charset.ToUTF8DropErrors(fileContents)),
+				"commit_id":         sha,
+				"filename":          update.Filename,
+				"extension":         fileExtension(update.Filename),
+				"language":          analyze.GetCodeLanguage(update.Filename, fileContents),
+				"updated_at":        timeutil.TimeStampNow(),
 			}),
 	}, nil
 }
 
-func (b *ElasticSearchIndexer) addDelete(filename string, repo *models.Repository) elastic.BulkableRequest {
-	id := filenameIndexerID(repo.ID, filename)
+func (b *ElasticSearchIndexer) addDelete(branch, filename string, repo *models.Repository) elastic.BulkableRequest {
+	id := filenameIndexerID(repo.ID, branch, filename)
 	return elastic.NewBulkDeleteRequest().
 		Index(b.indexerAliasName).
 		Id(id)
 }
 
 // Index will save the index data
-func (b *ElasticSearchIndexer) Index(repo *models.Repository, sha string, changes *repoChanges) error {
+func (b *ElasticSearchIndexer) Index(repo *models.Repository, branch, sha string, changes *repoChanges) error {
 	reqs := make([]elastic.BulkableRequest, 0)
 	if len(changes.Updates) > 0 {
 
@@ -247,7 +267,7 @@ func (b *ElasticSearchIndexer) Index(repo *models.Repository, sha string, change
 		defer cancel()
 
 		for _, update := range changes.Updates {
-			updateReqs, err := b.addUpdate(batchWriter, batchReader, sha, update, repo)
+			updateReqs, err := b.addUpdate(batchWriter, batchReader, branch, sha, update, repo)
 			if err != nil {
 				return err
 			}
@@ -259,7 +279,7 @@ func (b *ElasticSearchIndexer) Index(repo *models.Repository, sha string, change
 	}
 
 	for _, filename := range changes.RemovedFilenames {
-		reqs = append(reqs, b.addDelete(filename, repo))
+		reqs = append(reqs, b.addDelete(branch, filename, repo))
 	}
 
 	if len(reqs) > 0 {
@@ -280,6 +300,25 @@ func (b *ElasticSearchIndexer) Delete(repoID int64) error {
 	return err
 }
 
+// DeleteBranch deletes the indexes of a branch of a repository
+func (b *ElasticSearchIndexer) DeleteBranch(repoID int64, branch string) error {
+	_, err := b.client.DeleteByQuery(b.indexerAliasName).
+		Query(elastic.NewBoolQuery().Must(
+			elastic.NewTermsQuery("repo_id", repoID),
+			esBranchQuery(branch),
+		)).
+		Do(context.Background())
+	return err
+}
+
+// esBranchQuery matches the entries of a branch, an empty branch refers to the default branch
+func esBranchQuery(branch string) elastic.Query {
+	if len(branch) == 0 {
+		return elastic.NewTermQuery("is_default_branch", true)
+	}
+	return elastic.NewTermQuery("branch", branch)
+}
+
 // indexPos find words positions for start and the following end on content. It will
 // return the beginning position of the frist start and the ending position of the
 // first end following the start string.
@@ -316,7 +355,7 @@ func convertResult(searchResult *elastic.SearchResult, kw string, pageSize int)
 			panic(fmt.Sprintf("2===%#v", hit.Highlight))
 		}
 
-		repoID, fileName := parseIndexerID(hit.Id)
+		repoID, branch, fileName := parseIndexerID(hit.Id)
 		var res = make(map[string]interface{})
 		json := jsoniter.ConfigCompatibleWithStandardLibrary
 		if err := json.Unmarshal(hit.Source, &res); err != nil {
@@ -327,6 +366,7 @@ func convertResult(searchResult *elastic.SearchResult, kw string, pageSize int)
 
 		hits = append(hits, &SearchResult{
 			RepoID:      repoID,
+			Branch:      branch,
 			Filename:    fileName,
 			CommitID:    res["commit_id"].(string),
 			Content:     res["content"].(string),
@@ -359,23 +399,57 @@ func extractAggs(searchResult *elastic.SearchResult) []*SearchResultLanguages {
 }
 
 // Search searches for codes and language stats by given conditions.
-func (b *ElasticSearchIndexer) Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+func (b *ElasticSearchIndexer) Search(opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	var (
+		language = opts.Language
+		keyword  = opts.Keyword
+		page     = opts.Page
+		pageSize = opts.PageSize
+	)
+
 	searchType := esMultiMatchTypeBestFields
-	if isMatch {
+	if opts.IsMatch {
 		searchType = esMultiMatchTypePhrasePrefix
 	}
 
 	kwQuery := elastic.NewMultiMatchQuery(keyword, "content").Type(searchType)
 	query := elastic.NewBoolQuery()
 	query = query.Must(kwQuery)
-	if len(repoIDs) > 0 {
-		var repoStrs = make([]interface{}, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
+	if len(opts.RepoIDs) > 0 {
+		var repoStrs = make([]interface{}, 0, len(opts.RepoIDs))
+		for _, repoID := range opts.RepoIDs {
 			repoStrs = append(repoStrs, repoID)
 		}
 		repoQuery := elastic.NewTermsQuery("repo_id", repoStrs...)
 		query = query.Must(repoQuery)
 	}
+	if len(opts.Branch) > 0 && len(opts.BranchRepoIDs) > 0 {
+		var branchRepoStrs = make([]interface{}, 0, len(opts.BranchRepoIDs))
+		for _, repoID := range opts.BranchRepoIDs {
+			branchRepoStrs = append(branchRepoStrs, repoID)
+		}
+		branchRepoQuery := elastic.NewTermsQuery("repo_id", branchRepoStrs...)
+		query = query.Must(elastic.NewBoolQuery().Should(
+			elastic.NewBoolQuery().Must(esBranchQuery("")).MustNot(branchRepoQuery),
+			elastic.NewBoolQuery().Must(esBranchQuery(opts.Branch), branchRepoQuery),
+		))
+	} else {
+		query = query.Must(esBranchQuery(""))
+	}
+	if len(opts.Paths) > 0 {
+		var pathQueries = make([]elastic.Query, 0, len(opts.Paths))
+		for _, path := range opts.Paths {
+			pathQueries = append(pathQueries, elastic.NewPrefixQuery("filename", path))
+		}
+		query = query.Must(elastic.NewBoolQuery().Should(pathQueries...))
+	}
+	if len(opts.Extensions) > 0 {
+		var extensions = make([]interface{}, 0, len(opts.Extensions))
+		for _, extension := range opts.Extensions {
+			extensions = append(extensions, extension)
+		}
+		query = query.Must(elastic.NewTermsQuery("extension", extensions...))
+	}
 
 	var (
 		start       int
@@ -35,17 +35,36 @@ func getDefaultBranchSha(repo *models.Repository) (string, error) {
 	return strings.TrimSpace(stdout), nil
 }
 
-// getRepoChanges returns changes to repo since last indexer update
-func getRepoChanges(repo *models.Repository, revision string) (*repoChanges, error) {
-	status, err := repo.GetIndexerStatus(models.RepoIndexerTypeCode)
+// getIndexedBranchShas returns the head commits of the non-default branches which are indexed
+func getIndexedBranchShas(repo *models.Repository) (map[string]string, error) {
+	shas := make(map[string]string)
+	if len(repo.CodeIndexerBranches) == 0 {
+		return shas, nil
+	}
+	stdout, err := git.NewCommand("for-each-ref", "--format=%(objectname) %(refname)", git.BranchPrefix).RunInDir(repo.RepoPath())
 	if err != nil {
 		return nil, err
 	}
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], git.BranchPrefix) {
+			continue
+		}
+		branch := strings.TrimPrefix(fields[1], git.BranchPrefix)
+		if IsIndexedBranch(repo, branch) {
+			shas[branch] = fields[0]
+		}
+	}
+	return shas, nil
+}
 
-	if len(status.CommitSha) == 0 {
+// getRepoChanges returns changes to a branch of the repo since the indexed commit, the branch
+// is empty for the default branch
+func getRepoChanges(repo *models.Repository, branch, indexedSha, revision string) (*repoChanges, error) {
+	if len(indexedSha) == 0 {
 		return genesisChanges(repo, revision)
 	}
-	return nonGenesisChanges(repo, revision)
+	return nonGenesisChanges(repo, branch, indexedSha, revision)
 }
 
 func isIndexable(entry *git.TreeEntry) bool {
@@ -101,15 +120,14 @@ func genesisChanges(repo *models.Repository, revision string) (*repoChanges, err
 }
 
 // nonGenesisChanges get changes since the previous indexer update
-func nonGenesisChanges(repo *models.Repository, revision string) (*repoChanges, error) {
-	diffCmd := git.NewCommand("diff", "--name-status",
-		repo.CodeIndexerStatus.CommitSha, revision)
+func nonGenesisChanges(repo *models.Repository, branch, indexedSha, revision string) (*repoChanges, error) {
+	diffCmd := git.NewCommand("diff", "--name-status", indexedSha, revision)
 	stdout, err := diffCmd.RunInDir(repo.RepoPath())
 	if err != nil {
 		// previous commit sha may have been removed by a force push, so
 		// try rebuilding from scratch
 		log.Warn("git diff: %v", err)
-		if err = indexer.Delete(repo.ID); err != nil {
+		if err = indexer.DeleteBranch(repo.ID, branch); err != nil {
 			return nil, err
 		}
 		return genesisChanges(repo, revision)
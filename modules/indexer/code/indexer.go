@@ -6,6 +6,7 @@ package code
 
 import (
 	"context"
+	"encoding/hex"
 	"os"
 	"strconv"
 	"strings"
@@ -17,11 +18,15 @@ import (
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
 )
 
 // SearchResult result of performing a search in a repo
 type SearchResult struct {
-	RepoID      int64
+	RepoID int64
+	// Branch is empty for results on the default branch
+	Branch      string
 	StartIndex  int
 	EndIndex    int
 	Filename    string
@@ -39,37 +44,51 @@ type SearchResultLanguages struct {
 	Count    int
 }
 
-// Indexer defines an interface to index and search code contents
+// Indexer defines an interface to index and search code contents.
+// The entries of a repository are indexed per branch, an empty branch refers to the default branch.
 type Indexer interface {
-	Index(repo *models.Repository, sha string, changes *repoChanges) error
+	Index(repo *models.Repository, branch, sha string, changes *repoChanges) error
 	Delete(repoID int64) error
-	Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error)
+	DeleteBranch(repoID int64, branch string) error
+	Search(opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error)
 	Close()
 }
 
-func filenameIndexerID(repoID int64, filename string) string {
-	return indexerID(repoID) + "_" + filename
+// filenameIndexerID returns the id of a file of a branch in the indexer. The branch is hex
+// encoded, so that the repository part of the id never contains the '_' separator.
+func filenameIndexerID(repoID int64, branch, filename string) string {
+	return branchIndexerID(repoID, branch) + "_" + filename
+}
+
+// branchIndexerID returns the common part of the ids of the files of a branch in the indexer
+func branchIndexerID(repoID int64, branch string) string {
+	if len(branch) == 0 {
+		return indexerID(repoID)
+	}
+	return indexerID(repoID) + "-" + hex.EncodeToString([]byte(branch))
 }
 
 func indexerID(id int64) string {
 	return strconv.FormatInt(id, 36)
 }
 
-func parseIndexerID(indexerID string) (int64, string) {
+// parseIndexerID returns the repository id, branch and filename of an id in the indexer
+func parseIndexerID(indexerID string) (int64, string, string) {
 	index := strings.IndexByte(indexerID, '_')
 	if index == -1 {
 		log.Error("Unexpected ID in repo indexer: %s", indexerID)
 	}
-	repoID, _ := strconv.ParseInt(indexerID[:index], 36, 64)
-	return repoID, indexerID[index+1:]
-}
-
-func filenameOfIndexerID(indexerID string) string {
-	index := strings.IndexByte(indexerID, '_')
-	if index == -1 {
-		log.Error("Unexpected ID in repo indexer: %s", indexerID)
+	repoPart := indexerID[:index]
+	var branch string
+	if dash := strings.IndexByte(repoPart, '-'); dash != -1 {
+		decoded, err := hex.DecodeString(repoPart[dash+1:])
+		if err != nil {
+			log.Error("Unexpected ID in repo indexer: %s", indexerID)
+		}
+		repoPart, branch = repoPart[:dash], string(decoded)
 	}
-	return indexerID[index+1:]
+	repoID, _ := strconv.ParseInt(repoPart, 36, 64)
+	return repoID, branch, indexerID[index+1:]
 }
 
 // IndexerData represents data stored in the code indexer
@@ -92,18 +111,89 @@ func index(indexer Indexer, repoID int64) error {
 	if err != nil {
 		return err
 	}
-	changes, err := getRepoChanges(repo, sha)
+	status, err := repo.GetIndexerStatus(models.RepoIndexerTypeCode)
+	if err != nil {
+		return err
+	}
+	changes, err := getRepoChanges(repo, "", status.CommitSha, sha)
 	if err != nil {
 		return err
 	} else if changes == nil {
 		return nil
 	}
 
-	if err := indexer.Index(repo, sha, changes); err != nil {
+	if err := indexer.Index(repo, "", sha, changes); err != nil {
 		return err
 	}
 
-	return repo.UpdateIndexerStatus(models.RepoIndexerTypeCode, sha)
+	if err := repo.UpdateIndexerStatus(models.RepoIndexerTypeCode, sha); err != nil {
+		return err
+	}
+
+	return indexBranches(indexer, repo)
+}
+
+// indexBranches brings the entries of the non-default branches of the repository in the indexer
+// in step with the branches matching its code indexer branch patterns
+func indexBranches(indexer Indexer, repo *models.Repository) error {
+	shas, err := getIndexedBranchShas(repo)
+	if err != nil {
+		return err
+	}
+	statuses, err := repo.GetIndexerBranchStatuses(models.RepoIndexerTypeCode)
+	if err != nil {
+		return err
+	}
+
+	indexedShas := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		if _, ok := shas[status.Branch]; ok {
+			indexedShas[status.Branch] = status.CommitSha
+			continue
+		}
+		// the branch has been deleted or isn't indexed anymore
+		if err := indexer.DeleteBranch(repo.ID, status.Branch); err != nil {
+			return err
+		}
+		if err := repo.DeleteIndexerBranchStatus(models.RepoIndexerTypeCode, status.Branch); err != nil {
+			return err
+		}
+	}
+
+	for branch, sha := range shas {
+		if indexedShas[branch] == sha {
+			continue
+		}
+		changes, err := getRepoChanges(repo, branch, indexedShas[branch], sha)
+		if err != nil {
+			return err
+		}
+		if err := indexer.Index(repo, branch, sha, changes); err != nil {
+			return err
+		}
+		if err := repo.UpdateIndexerBranchStatus(models.RepoIndexerTypeCode, branch, sha); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsIndexedBranch returns whether a non-default branch of the repository matches one of its code indexer branch patterns
+func IsIndexedBranch(repo *models.Repository, branch string) bool {
+	if branch == repo.DefaultBranch {
+		return false
+	}
+	for _, pattern := range repo.CodeIndexerBranches {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			log.Warn("Invalid code indexer branch pattern %q of repository %s: %v", pattern, repo.FullName(), err)
+			continue
+		}
+		if g.Match(branch) {
+			return true
+		}
+	}
+	return false
 }
 
 // Init initialize the repo indexer
@@ -125,7 +215,7 @@ func Init() {
 
 	// Create the Queue
 	switch setting.Indexer.RepoType {
-	case "bleve", "elasticsearch":
+	case "bleve", "elasticsearch", "trigram":
 		handler := func(data ...queue.Data) {
 			idx, err := indexer.get()
 			if idx == nil || err != nil {
@@ -190,6 +280,26 @@ func Init() {
 				close(waitChannel)
 				log.Fatal("PID: %d Unable to initialize the bleve Repository Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.RepoPath, err)
 			}
+		case "trigram":
+			log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoPath)
+			defer func() {
+				if err := recover(); err != nil {
+					log.Error("PANIC whilst initializing repository indexer: %v\nStacktrace: %s", err, log.Stack(2))
+					log.Error("The indexer files are likely corrupted and may need to be deleted")
+					log.Error("You can completely remove the \"%s\" directory to make Gitea recreate the indexes", setting.Indexer.RepoPath)
+				}
+			}()
+
+			rIndexer, populate, err = NewTrigramIndexer(setting.Indexer.RepoPath)
+			if err != nil {
+				if rIndexer != nil {
+					rIndexer.Close()
+				}
+				cancel()
+				indexer.Close()
+				close(waitChannel)
+				log.Fatal("PID: %d Unable to initialize the trigram Repository Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.RepoPath, err)
+			}
 		case "elasticsearch":
 			log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoConnStr)
 			defer func() {
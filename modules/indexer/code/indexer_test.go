@@ -64,7 +64,12 @@ func testIndexer(name string, t *testing.T, indexer Indexer) {
 
 		for _, kw := range keywords {
 			t.Run(kw.Keyword, func(t *testing.T) {
-				total, res, langs, err := indexer.Search(kw.RepoIDs, "", kw.Keyword, 1, 10, false)
+				total, res, langs, err := indexer.Search(&SearchOptions{
+					RepoIDs:  kw.RepoIDs,
+					Keyword:  kw.Keyword,
+					Page:     1,
+					PageSize: 10,
+				})
 				assert.NoError(t, err)
 				assert.EqualValues(t, len(kw.IDs), total)
 				assert.EqualValues(t, kw.Langs, len(langs))
@@ -78,6 +83,105 @@ func testIndexer(name string, t *testing.T, indexer Indexer) {
 			})
 		}
 
+		for _, filter := range []struct {
+			Paths      []string
+			Extensions []string
+			Total      int64
+		}{
+			{Paths: []string{"README"}, Total: 1},
+			{Paths: []string{"docs/"}, Total: 0},
+			{Extensions: []string{"md"}, Total: 1},
+			{Extensions: []string{"go"}, Total: 0},
+			{Paths: []string{"docs/", "README"}, Extensions: []string{"go", "md"}, Total: 1},
+		} {
+			total, _, _, err := indexer.Search(&SearchOptions{
+				Keyword:    "Description",
+				Paths:      filter.Paths,
+				Extensions: filter.Extensions,
+				Page:       1,
+				PageSize:   10,
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, filter.Total, total, "paths %v, extensions %v", filter.Paths, filter.Extensions)
+		}
+
+		repo, err := models.GetRepositoryByID(repoID)
+		assert.NoError(t, err)
+		repo.CodeIndexerBranches = []string{"branch*"}
+		assert.NoError(t, models.UpdateRepositoryCols(repo, "code_indexer_branches"))
+		assert.NoError(t, index(indexer, repoID))
+
+		for _, branch := range []struct {
+			Branch        string
+			BranchRepoIDs []int64
+			Total         int64
+		}{
+			{Total: 0},
+			{Branch: "branch2", BranchRepoIDs: []int64{repoID}, Total: 1},
+			{Branch: "branch2", Total: 0},
+			{Branch: "develop", BranchRepoIDs: []int64{repoID}, Total: 0},
+		} {
+			total, res, _, err := indexer.Search(&SearchOptions{
+				Keyword:       "change",
+				Branch:        branch.Branch,
+				BranchRepoIDs: branch.BranchRepoIDs,
+				Page:          1,
+				PageSize:      10,
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, branch.Total, total, "branch %q", branch.Branch)
+			for _, hit := range res {
+				assert.EqualValues(t, branch.Branch, hit.Branch)
+				assert.EqualValues(t, "README.md", hit.Filename)
+			}
+		}
+
+		// the entries of branches which aren't indexed anymore are removed
+		repo.CodeIndexerBranches = nil
+		assert.NoError(t, models.UpdateRepositoryCols(repo, "code_indexer_branches"))
+		assert.NoError(t, index(indexer, repoID))
+		total, _, _, err := indexer.Search(&SearchOptions{
+			Keyword:       "change",
+			Branch:        "branch2",
+			BranchRepoIDs: []int64{repoID},
+			Page:          1,
+			PageSize:      10,
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, total)
+		statuses, err := repo.GetIndexerBranchStatuses(models.RepoIndexerTypeCode)
+		assert.NoError(t, err)
+		assert.Empty(t, statuses)
+
 		assert.NoError(t, indexer.Delete(repoID))
 	})
 }
+
+func TestIndexerID(t *testing.T) {
+	for _, tc := range []struct {
+		RepoID   int64
+		Branch   string
+		Filename string
+	}{
+		{RepoID: 1, Filename: "README.md"},
+		{RepoID: 42, Branch: "release/1.x", Filename: "docs/a_b.md"},
+		{RepoID: 36, Branch: "feature_x-y", Filename: "_"},
+	} {
+		repoID, branch, filename := parseIndexerID(filenameIndexerID(tc.RepoID, tc.Branch, tc.Filename))
+		assert.EqualValues(t, tc.RepoID, repoID)
+		assert.EqualValues(t, tc.Branch, branch)
+		assert.EqualValues(t, tc.Filename, filename)
+	}
+}
+
+func TestIsIndexedBranch(t *testing.T) {
+	repo := &models.Repository{
+		DefaultBranch:       "master",
+		CodeIndexerBranches: []string{"release/*", "develop", "master"},
+	}
+	assert.True(t, IsIndexedBranch(repo, "release/1.14"))
+	assert.True(t, IsIndexedBranch(repo, "develop"))
+	assert.False(t, IsIndexedBranch(repo, "master"))
+	assert.False(t, IsIndexedBranch(repo, "feature"))
+	assert.False(t, IsIndexedBranch(&models.Repository{DefaultBranch: "master"}, "develop"))
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-enry/go-enry/v2"
+)
+
+// SearchOptions represents the options of a code search
+type SearchOptions struct {
+	RepoIDs  []int64
+	Keyword  string
+	Language string
+	IsMatch  bool
+	// Paths restricts the search to files whose paths start with one of them
+	Paths []string
+	// Extensions restricts the search to files with one of these extensions, lower cased and without the dot
+	Extensions []string
+	// Branch is searched instead of the default branch in the repositories of BranchRepoIDs
+	Branch        string
+	BranchRepoIDs []int64
+	Page          int
+	PageSize      int
+}
+
+// matchesBranch returns whether the entries of a branch of a repository are searched, an empty
+// branch refers to the default branch
+func (opts *SearchOptions) matchesBranch(repoID int64, branch string) bool {
+	if len(opts.Branch) > 0 && util.IsInt64InSlice(repoID, opts.BranchRepoIDs) {
+		return branch == opts.Branch
+	}
+	return len(branch) == 0
+}
+
+// matchesFile returns whether a file passes the path and extension filters of the options
+func (opts *SearchOptions) matchesFile(filename string) bool {
+	if len(opts.Paths) > 0 {
+		found := false
+		for _, prefix := range opts.Paths {
+			if strings.HasPrefix(filename, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(opts.Extensions) > 0 {
+		return util.IsStringInSlice(fileExtension(filename), opts.Extensions)
+	}
+	return true
+}
+
+// SearchQuery represents a code search query with its qualifiers split off
+type SearchQuery struct {
+	Keyword    string
+	Repos      []string
+	Language   string
+	Paths      []string
+	Extensions []string
+	Branch     string
+}
+
+var searchQualifierPattern = regexp.MustCompile(`(?:^|\s)(repo|lang|language|path|ext|extension|branch):("[^"]*"|\S*)`)
+
+// ParseSearchQuery splits the qualifiers `repo:`, `lang:`, `path:`, `ext:` and `branch:` off a code search query.
+// `repo:`, `path:` and `ext:` may be given several times, a file has to match one of each.
+func ParseSearchQuery(query string) *SearchQuery {
+	result := &SearchQuery{}
+	keyword := searchQualifierPattern.ReplaceAllStringFunc(query, func(match string) string {
+		submatches := searchQualifierPattern.FindStringSubmatch(match)
+		value := strings.Trim(submatches[2], `"`)
+		if len(value) == 0 {
+			return " "
+		}
+		switch submatches[1] {
+		case "repo":
+			result.Repos = append(result.Repos, strings.ToLower(strings.Trim(value, "/")))
+		case "lang", "language":
+			if lang, ok := enry.GetLanguageByAlias(value); ok {
+				result.Language = lang
+			} else {
+				result.Language = value
+			}
+		case "path":
+			cleaned := strings.TrimPrefix(path.Clean("/"+value), "/")
+			if len(cleaned) > 0 && strings.HasSuffix(value, "/") {
+				cleaned += "/"
+			}
+			result.Paths = append(result.Paths, cleaned)
+		case "ext", "extension":
+			result.Extensions = append(result.Extensions, strings.ToLower(strings.TrimPrefix(value, ".")))
+		case "branch":
+			result.Branch = value
+		}
+		return " "
+	})
+	result.Keyword = strings.TrimSpace(keyword)
+	return result
+}
+
+// fileExtension returns the lower cased extension of a file without the dot
+func fileExtension(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	for _, c := range []struct {
+		Query    string
+		Expected *SearchQuery
+	}{
+		{
+			Query:    "func main",
+			Expected: &SearchQuery{Keyword: "func main"},
+		},
+		{
+			Query: `repo:user2/Repo1 lang:golang path:/modules/indexer/ ext:.GO branch:develop func  main repo:glob`,
+			Expected: &SearchQuery{
+				Keyword:    "func  main",
+				Repos:      []string{"user2/repo1", "glob"},
+				Language:   "Go",
+				Paths:      []string{"modules/indexer/"},
+				Extensions: []string{"go"},
+				Branch:     "develop",
+			},
+		},
+		{
+			Query: `path:"docs" lang:unknown /Open\w+/`,
+			Expected: &SearchQuery{
+				Keyword:  `/Open\w+/`,
+				Language: "unknown",
+				Paths:    []string{"docs"},
+			},
+		},
+		{
+			Query:    "repo: keyword",
+			Expected: &SearchQuery{Keyword: "keyword"},
+		},
+	} {
+		assert.Equal(t, c.Expected, ParseSearchQuery(c.Query), c.Query)
+	}
+}
@@ -5,7 +5,7 @@
 package code
 
 import (
-	"bytes"
+	"html"
 	"strings"
 
 	"code.gitea.io/gitea/modules/highlight"
@@ -15,16 +15,28 @@ import (
 
 // Result a search result to display
 type Result struct {
-	RepoID         int64
+	RepoID int64
+	// Branch is empty for results on the default branch
+	Branch         string
 	Filename       string
 	CommitID       string
 	UpdatedUnix    timeutil.TimeStamp
 	Language       string
 	Color          string
 	LineNumbers    []int
+	Lines          []*ResultLine
 	FormattedLines string
 }
 
+// ResultLine a line of the snippet of a search result
+type ResultLine struct {
+	Num     int
+	Content string
+	// HighlightStart and HighlightEnd are the offsets of the match within the line, both are zero if it isn't on the line
+	HighlightStart int
+	HighlightEnd   int
+}
+
 func indices(content string, selectionStartIndex, selectionEndIndex int) (int, int) {
 	startIndex := selectionStartIndex
 	numLinesBefore := 0
@@ -51,67 +63,106 @@ func indices(content string, selectionStartIndex, selectionEndIndex int) (int, i
 	return startIndex, endIndex
 }
 
-func writeStrings(buf *bytes.Buffer, strs ...string) error {
-	for _, s := range strs {
-		_, err := buf.WriteString(s)
-		if err != nil {
-			return err
+// markRange wraps the text between the start and end offsets of the unescaped text of the
+// highlighted html code into active spans, closing and reopening them around tags
+func markRange(code string, start, end int) string {
+	if start < 0 || start >= end {
+		return code
+	}
+
+	var buf strings.Builder
+	offset := 0
+	open := false
+	for i := 0; i < len(code); {
+		if code[i] == '<' {
+			tagEnd := strings.IndexByte(code[i:], '>')
+			if tagEnd < 0 {
+				tagEnd = len(code) - i - 1
+			}
+			if open {
+				buf.WriteString("</span>")
+				open = false
+			}
+			buf.WriteString(code[i : i+tagEnd+1])
+			i += tagEnd + 1
+			continue
+		}
+
+		length, unescaped := 1, 1
+		if code[i] == '&' {
+			if entityEnd := strings.IndexByte(code[i:], ';'); entityEnd > 0 {
+				length = entityEnd + 1
+				unescaped = len(html.UnescapeString(code[i : i+length]))
+			}
 		}
+
+		inRange := offset >= start && offset < end
+		if inRange && !open {
+			buf.WriteString(`<span class="active">`)
+			open = true
+		} else if !inRange && open {
+			buf.WriteString("</span>")
+			open = false
+		}
+		buf.WriteString(code[i : i+length])
+		offset += unescaped
+		i += length
+	}
+	if open {
+		buf.WriteString("</span>")
 	}
-	return nil
+	return buf.String()
 }
 
 func searchResult(result *SearchResult, startIndex, endIndex int) (*Result, error) {
 	startLineNum := 1 + strings.Count(result.Content[:startIndex], "\n")
 
-	var formattedLinesBuffer bytes.Buffer
-
 	contentLines := strings.SplitAfter(result.Content[startIndex:endIndex], "\n")
 	lineNumbers := make([]int, len(contentLines))
+	lines := make([]*ResultLine, len(contentLines))
 	index := startIndex
 	for i, line := range contentLines {
-		var err error
+		lines[i] = &ResultLine{
+			Num:     startLineNum + i,
+			Content: strings.TrimSuffix(line, "\n"),
+		}
 		if index < result.EndIndex &&
 			result.StartIndex < index+len(line) &&
 			result.StartIndex < result.EndIndex {
-			openActiveIndex := util.Max(result.StartIndex-index, 0)
-			closeActiveIndex := util.Min(result.EndIndex-index, len(line))
-			err = writeStrings(&formattedLinesBuffer,
-				line[:openActiveIndex],
-				line[openActiveIndex:closeActiveIndex],
-				line[closeActiveIndex:],
-			)
-		} else {
-			err = writeStrings(&formattedLinesBuffer,
-				line,
-			)
-		}
-		if err != nil {
-			return nil, err
+			lines[i].HighlightStart = util.Max(result.StartIndex-index, 0)
+			lines[i].HighlightEnd = util.Min(result.EndIndex-index, len(lines[i].Content))
 		}
 
 		lineNumbers[i] = startLineNum + i
 		index += len(line)
 	}
+
+	formattedLines := highlight.Code(result.Filename, result.Content[startIndex:endIndex])
+	if result.StartIndex < result.EndIndex {
+		formattedLines = markRange(formattedLines, result.StartIndex-startIndex, result.EndIndex-startIndex)
+	}
+
 	return &Result{
 		RepoID:         result.RepoID,
+		Branch:         result.Branch,
 		Filename:       result.Filename,
 		CommitID:       result.CommitID,
 		UpdatedUnix:    result.UpdatedUnix,
 		Language:       result.Language,
 		Color:          result.Color,
 		LineNumbers:    lineNumbers,
-		FormattedLines: highlight.Code(result.Filename, formattedLinesBuffer.String()),
+		Lines:          lines,
+		FormattedLines: formattedLines,
 	}, nil
 }
 
-// PerformSearch perform a search on a repository
-func PerformSearch(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int, []*Result, []*SearchResultLanguages, error) {
-	if len(keyword) == 0 {
+// PerformSearch perform a search on repositories
+func PerformSearch(opts *SearchOptions) (int, []*Result, []*SearchResultLanguages, error) {
+	if len(opts.Keyword) == 0 {
 		return 0, nil, nil, nil
 	}
 
-	total, results, resultLanguages, err := indexer.Search(repoIDs, language, keyword, page, pageSize, isMatch)
+	total, results, resultLanguages, err := indexer.Search(opts)
 	if err != nil {
 		return 0, nil, nil, err
 	}
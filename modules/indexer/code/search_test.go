@@ -0,0 +1,19 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkRange(t *testing.T) {
+	code := `<span class="kd">func</span> <span class="nf">a</span><span class="p">(</span><span class="s">&#34;b&#34;</span>`
+	assert.Equal(t,
+		`<span class="kd">func</span> <span class="nf"><span class="active">a</span></span><span class="p"><span class="active">(</span></span><span class="s"><span class="active">&#34;</span>b&#34;</span>`,
+		markRange(code, 5, 8))
+	assert.Equal(t, code, markRange(code, -1, -1))
+}
@@ -0,0 +1,652 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-enry/go-enry/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldb_util "github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	trigramIndexerLatestVersion = 1
+
+	trigramVersionKey     = "version"
+	trigramDocumentPrefix = "d/"
+	trigramPostingPrefix  = "t/"
+
+	// trigramMaxMatches limits the number of matches per file taken into account for ranking
+	trigramMaxMatches = 100
+	// trigramMaxScannedDocuments limits the number of files queries without trigrams, e.g. for keywords
+	// shorter than three characters, are verified against, as they can't be narrowed down by the index
+	trigramMaxScannedDocuments = 10000
+)
+
+// Ranking bonuses of a match, a file matching its name or a symbol definition outranks plain occurrences
+const (
+	trigramScoreMatch      = 1
+	trigramScoreWholeWord  = 2
+	trigramScoreDefinition = 8
+	trigramScoreFilename   = 10
+)
+
+// definitionPattern matches the beginning of a line up to a name it declares, e.g. "func (r *Repo) " or "export class "
+var definitionPattern = regexp.MustCompile(`^\s*(?:(?:export|default|public|private|protected|internal|static|final|abstract|async|pub(?:\([a-z]+\))?|unsafe|extern|inline|virtual|override|local)\s+)*` +
+	`(?:func|def|class|type|struct|interface|fn|function|const|var|let|enum|trait|impl|module|package|macro|namespace|union|record|object|sub|proc)` +
+	`(?:\s*\([^)]*\))?\s+[\w\s,*&<>\[\].:]*$`)
+
+var (
+	_ Indexer = &TrigramIndexer{}
+)
+
+// ErrQueryTooBroad is returned for a query which can't be narrowed down by the trigram index, e.g. a
+// keyword shorter than three characters, on more files than it may be verified against
+type ErrQueryTooBroad struct {
+	Limit int
+}
+
+// IsErrQueryTooBroad checks if an error is a ErrQueryTooBroad.
+func IsErrQueryTooBroad(err error) bool {
+	_, ok := err.(ErrQueryTooBroad)
+	return ok
+}
+
+func (err ErrQueryTooBroad) Error() string {
+	return fmt.Sprintf("query without trigrams searches more than %d files", err.Limit)
+}
+
+// trigramDocument represents a file stored in the trigram indexer
+type trigramDocument struct {
+	RepoID      int64
+	CommitID    string
+	Content     string
+	Language    string
+	UpdatedUnix timeutil.TimeStamp
+}
+
+// TrigramIndexer represents a code indexer keeping a trigram index of the files on local disk.
+// Queries are narrowed down to the files containing all trigrams of the literal parts of the
+// keyword and then verified against the file contents, which allows exact substring and regular
+// expression queries.
+type TrigramIndexer struct {
+	indexDir string
+	db       *leveldb.DB
+}
+
+// NewTrigramIndexer creates a new trigram local indexer
+func NewTrigramIndexer(indexDir string) (*TrigramIndexer, bool, error) {
+	indexer := &TrigramIndexer{
+		indexDir: indexDir,
+	}
+	created, err := indexer.init()
+	return indexer, created, err
+}
+
+// init opens the index, recreating it if it has been written by a different version
+func (t *TrigramIndexer) init() (bool, error) {
+	_, err := os.Stat(t.indexDir)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	created := os.IsNotExist(err)
+
+	if t.db, err = leveldb.OpenFile(t.indexDir, nil); err != nil {
+		return false, err
+	}
+	if created {
+		return true, t.db.Put([]byte(trigramVersionKey), []byte(strconv.Itoa(trigramIndexerLatestVersion)), nil)
+	}
+
+	version, err := t.db.Get([]byte(trigramVersionKey), nil)
+	if err != nil && err != leveldb.ErrNotFound {
+		return false, err
+	}
+	if string(version) == strconv.Itoa(trigramIndexerLatestVersion) {
+		return false, nil
+	}
+
+	// the indexer is using a previous version, so we should delete it and
+	// re-populate
+	if err = t.db.Close(); err != nil {
+		return false, err
+	}
+	t.db = nil
+	if err = util.RemoveAll(t.indexDir); err != nil {
+		return false, err
+	}
+	if t.db, err = leveldb.OpenFile(t.indexDir, nil); err != nil {
+		return false, err
+	}
+	return true, t.db.Put([]byte(trigramVersionKey), []byte(strconv.Itoa(trigramIndexerLatestVersion)), nil)
+}
+
+// Close close the indexer
+func (t *TrigramIndexer) Close() {
+	log.Debug("Closing repo indexer")
+	if t.db != nil {
+		if err := t.db.Close(); err != nil {
+			log.Error("Error whilst closing the repository indexer: %v", err)
+		}
+	}
+	log.Info("PID: %d Repository Indexer closed", os.Getpid())
+}
+
+// trigrams returns the distinct trigrams of the lower cased content
+func trigrams(content string) map[string]struct{} {
+	content = strings.ToLower(content)
+	result := make(map[string]struct{})
+	for i := 0; i+3 <= len(content); i++ {
+		result[content[i:i+3]] = struct{}{}
+	}
+	return result
+}
+
+func trigramDocumentKey(id string) []byte {
+	return []byte(trigramDocumentPrefix + id)
+}
+
+func trigramPostingKey(trigram, id string) []byte {
+	return []byte(trigramPostingPrefix + trigram + id)
+}
+
+func (t *TrigramIndexer) getDocument(id string) (*trigramDocument, error) {
+	data, err := t.db.Get(trigramDocumentKey(id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var doc trigramDocument
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// deleteDocument adds the removal of a stored document and its postings to the batch
+func (t *TrigramIndexer) deleteDocument(id string, batch *leveldb.Batch) error {
+	doc, err := t.getDocument(id)
+	if err != nil || doc == nil {
+		return err
+	}
+	for trigram := range trigrams(doc.Content) {
+		batch.Delete(trigramPostingKey(trigram, id))
+	}
+	batch.Delete(trigramDocumentKey(id))
+	return nil
+}
+
+func (t *TrigramIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, branch, commitSha string, update fileUpdate, repo *models.Repository) error {
+	// Ignore vendored files in code search
+	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
+		return nil
+	}
+
+	size := update.Size
+
+	if !update.Sized {
+		stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
+			RunInDir(repo.RepoPath())
+		if err != nil {
+			return err
+		}
+		if size, err = strconv.ParseInt(strings.TrimSpace(stdout), 10, 64); err != nil {
+			return fmt.Errorf("Misformatted git cat-file output: %v", err)
+		}
+	}
+
+	if size > setting.Indexer.MaxIndexerFileSize {
+		return t.addDelete(branch, update.Filename, repo)
+	}
+
+	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
+		return err
+	}
+
+	_, _, size, err := git.ReadBatchLine(batchReader)
+	if err != nil {
+		return err
+	}
+
+	fileContents, err := ioutil.ReadAll(io.LimitReader(batchReader, size))
+	if err != nil {
+		return err
+	} else if !base.IsTextFile(fileContents) {
+		// FIXME: UTF-16 files will probably fail here
+		return nil
+	}
+
+	doc := &trigramDocument{
+		RepoID:      repo.ID,
+		CommitID:    commitSha,
+		Content:     string(charset.ToUTF8DropErrors(fileContents)),
+		Language:    analyze.GetCodeLanguage(update.Filename, fileContents),
+		UpdatedUnix: timeutil.TimeStampNow(),
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	id := filenameIndexerID(repo.ID, branch, update.Filename)
+	batch := new(leveldb.Batch)
+	if err := t.deleteDocument(id, batch); err != nil {
+		return err
+	}
+	for trigram := range trigrams(doc.Content) {
+		batch.Put(trigramPostingKey(trigram, id), nil)
+	}
+	batch.Put(trigramDocumentKey(id), data)
+	return t.db.Write(batch, nil)
+}
+
+func (t *TrigramIndexer) addDelete(branch, filename string, repo *models.Repository) error {
+	batch := new(leveldb.Batch)
+	if err := t.deleteDocument(filenameIndexerID(repo.ID, branch, filename), batch); err != nil {
+		return err
+	}
+	return t.db.Write(batch, nil)
+}
+
+// Index indexes the data
+func (t *TrigramIndexer) Index(repo *models.Repository, branch, sha string, changes *repoChanges) error {
+	if len(changes.Updates) > 0 {
+		batchWriter, batchReader, cancel := git.CatFileBatch(repo.RepoPath())
+		defer cancel()
+
+		for _, update := range changes.Updates {
+			if err := t.addUpdate(batchWriter, batchReader, branch, sha, update, repo); err != nil {
+				return err
+			}
+		}
+		cancel()
+	}
+	for _, filename := range changes.RemovedFilenames {
+		if err := t.addDelete(branch, filename, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes indexes by ids
+func (t *TrigramIndexer) Delete(repoID int64) error {
+	return t.deletePrefixes(repoIndexerIDPrefixes(repoID)...)
+}
+
+// DeleteBranch deletes the indexes of a branch of a repository
+func (t *TrigramIndexer) DeleteBranch(repoID int64, branch string) error {
+	return t.deletePrefixes(branchIndexerID(repoID, branch) + "_")
+}
+
+// deletePrefixes deletes the documents whose ids start with one of the prefixes
+func (t *TrigramIndexer) deletePrefixes(prefixes ...string) error {
+	batch := new(leveldb.Batch)
+	for _, prefix := range prefixes {
+		iter := t.db.NewIterator(leveldb_util.BytesPrefix(trigramDocumentKey(prefix)), nil)
+		for iter.Next() {
+			if err := t.deleteDocument(string(iter.Key()[len(trigramDocumentPrefix):]), batch); err != nil {
+				iter.Release()
+				return err
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return t.db.Write(batch, nil)
+}
+
+// repoIndexerIDPrefixes returns the prefixes of the ids of the documents of all branches of a repository
+func repoIndexerIDPrefixes(repoID int64) []string {
+	return []string{indexerID(repoID) + "_", indexerID(repoID) + "-"}
+}
+
+// trigramQuery is a parsed code search keyword
+type trigramQuery struct {
+	// matchers all have to match a file
+	matchers []*regexp.Regexp
+	// literals are substrings every matching file contains, lower cased
+	literals []string
+}
+
+// parseTrigramQuery parses the keyword of a search. A keyword enclosed in slashes is a regular
+// expression, an exact match searches for the keyword as a case-insensitive substring and
+// otherwise each of the words of the keyword has to be found in a file.
+func parseTrigramQuery(keyword string, isMatch bool) (*trigramQuery, error) {
+	query := &trigramQuery{}
+	if len(keyword) > 2 && strings.HasPrefix(keyword, "/") && strings.HasSuffix(keyword, "/") {
+		expr := keyword[1 : len(keyword)-1]
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := syntax.Parse(expr, syntax.Perl)
+		if err != nil {
+			return nil, err
+		}
+		query.matchers = []*regexp.Regexp{re}
+		query.literals = requiredLiterals(parsed.Simplify())
+		return query, nil
+	}
+
+	var terms []string
+	if isMatch {
+		terms = []string{keyword}
+	} else {
+		terms = strings.Fields(keyword)
+	}
+	for _, term := range terms {
+		if len(term) == 0 {
+			continue
+		}
+		query.matchers = append(query.matchers, regexp.MustCompile("(?i)"+regexp.QuoteMeta(term)))
+		query.literals = append(query.literals, strings.ToLower(term))
+	}
+	return query, nil
+}
+
+// requiredLiterals returns lower cased substrings every match of the regular expression contains
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{strings.ToLower(string(re.Rune))}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var (
+			literals []string
+			run      strings.Builder
+		)
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run.WriteString(strings.ToLower(string(sub.Rune)))
+				continue
+			}
+			if run.Len() > 0 {
+				literals = append(literals, run.String())
+				run.Reset()
+			}
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		if run.Len() > 0 {
+			literals = append(literals, run.String())
+		}
+		return literals
+	}
+	return nil
+}
+
+// repoPrefixes returns the prefixes of the ids of the documents in the given repositories, or the
+// empty prefix of all documents if none are given
+func repoPrefixes(repoIDs []int64) []string {
+	if len(repoIDs) == 0 {
+		return []string{""}
+	}
+	prefixes := make([]string, 0, 2*len(repoIDs))
+	for _, repoID := range repoIDs {
+		prefixes = append(prefixes, repoIndexerIDPrefixes(repoID)...)
+	}
+	return prefixes
+}
+
+// candidates returns the ids of the documents in the given repositories containing all trigrams of the
+// query, or nil if the query has no trigrams to narrow down the documents
+func (t *TrigramIndexer) candidates(query *trigramQuery, repoIDs []int64) (map[string]struct{}, error) {
+	required := make(map[string]struct{})
+	for _, literal := range query.literals {
+		for trigram := range trigrams(literal) {
+			required[trigram] = struct{}{}
+		}
+	}
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	var result map[string]struct{}
+	for trigram := range required {
+		ids := make(map[string]struct{})
+		for _, repoPrefix := range repoPrefixes(repoIDs) {
+			prefix := trigramPostingKey(trigram, repoPrefix)
+			iter := t.db.NewIterator(leveldb_util.BytesPrefix(prefix), nil)
+			for iter.Next() {
+				id := string(iter.Key()[len(trigramPostingPrefix)+len(trigram):])
+				if result == nil {
+					ids[id] = struct{}{}
+				} else if _, ok := result[id]; ok {
+					ids[id] = struct{}{}
+				}
+			}
+			iter.Release()
+			if err := iter.Error(); err != nil {
+				return nil, err
+			}
+		}
+		result = ids
+		if len(result) == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
+// allDocuments returns the ids of all documents in the given repositories, or in all repositories if none
+// are given, it fails with ErrQueryTooBroad if there are more than limit
+func (t *TrigramIndexer) allDocuments(repoIDs []int64, limit int) (map[string]struct{}, error) {
+	result := make(map[string]struct{})
+	for _, prefix := range repoPrefixes(repoIDs) {
+		iter := t.db.NewIterator(leveldb_util.BytesPrefix(trigramDocumentKey(prefix)), nil)
+		for iter.Next() {
+			if len(result) == limit {
+				iter.Release()
+				return nil, ErrQueryTooBroad{Limit: limit}
+			}
+			result[string(iter.Key()[len(trigramDocumentPrefix):])] = struct{}{}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// trigramHit is a document matching a query
+type trigramHit struct {
+	id         string
+	doc        *trigramDocument
+	score      int
+	startIndex int
+	endIndex   int
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+// scoreMatch ranks a single match, whole words and names of declarations rank higher
+func scoreMatch(content string, start, end int) int {
+	score := trigramScoreMatch
+	if (start == 0 || !isWordByte(content[start-1])) && (end == len(content) || !isWordByte(content[end])) {
+		score += trigramScoreWholeWord
+	}
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	if definitionPattern.MatchString(content[lineStart:start]) {
+		score += trigramScoreDefinition
+	}
+	return score
+}
+
+// match verifies a document against the query and ranks it, returns nil if the document doesn't match
+func (query *trigramQuery) match(id string, doc *trigramDocument) *trigramHit {
+	hit := &trigramHit{
+		id:         id,
+		doc:        doc,
+		startIndex: -1,
+		endIndex:   -1,
+	}
+	_, _, filename := parseIndexerID(id)
+	filename = path.Base(filename)
+
+	bestScore := 0
+	for _, matcher := range query.matchers {
+		matches := matcher.FindAllStringIndex(doc.Content, trigramMaxMatches)
+		if len(matches) == 0 {
+			return nil
+		}
+		for _, match := range matches {
+			score := scoreMatch(doc.Content, match[0], match[1])
+			hit.score += score
+			if score > bestScore {
+				bestScore = score
+				hit.startIndex, hit.endIndex = match[0], match[1]
+			}
+		}
+		if matcher.MatchString(filename) {
+			hit.score += trigramScoreFilename
+		}
+	}
+	return hit
+}
+
+// Search searches for files in the specified repo.
+// Returns the matching file-paths
+func (t *TrigramIndexer) Search(opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	var (
+		repoIDs  = opts.RepoIDs
+		language = opts.Language
+		page     = opts.Page
+		pageSize = opts.PageSize
+	)
+
+	query, err := parseTrigramQuery(opts.Keyword, opts.IsMatch)
+	if err != nil {
+		return 0, nil, nil, err
+	} else if len(query.matchers) == 0 {
+		return 0, nil, nil, nil
+	}
+
+	ids, err := t.candidates(query, repoIDs)
+	if err != nil {
+		return 0, nil, nil, err
+	} else if ids == nil {
+		if ids, err = t.allDocuments(repoIDs, trigramMaxScannedDocuments); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+
+	hits := make([]*trigramHit, 0, len(ids))
+	languageCounts := make(map[string]int)
+	for id := range ids {
+		repoID, branch, filename := parseIndexerID(id)
+		if !opts.matchesBranch(repoID, branch) || !opts.matchesFile(filename) {
+			continue
+		}
+		doc, err := t.getDocument(id)
+		if err != nil {
+			return 0, nil, nil, err
+		} else if doc == nil {
+			continue
+		}
+		hit := query.match(id, doc)
+		if hit == nil {
+			continue
+		}
+		if len(doc.Language) > 0 {
+			languageCounts[doc.Language]++
+		}
+		if len(language) > 0 && doc.Language != language {
+			continue
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].id < hits[j].id
+	})
+
+	total := int64(len(hits))
+	from := (page - 1) * pageSize
+	if from < 0 {
+		from = 0
+	}
+	if from > len(hits) {
+		from = len(hits)
+	}
+	to := from + pageSize
+	if pageSize <= 0 || to > len(hits) {
+		to = len(hits)
+	}
+
+	searchResults := make([]*SearchResult, 0, to-from)
+	for _, hit := range hits[from:to] {
+		_, branch, filename := parseIndexerID(hit.id)
+		searchResults = append(searchResults, &SearchResult{
+			RepoID:      hit.doc.RepoID,
+			Branch:      branch,
+			StartIndex:  hit.startIndex,
+			EndIndex:    hit.endIndex,
+			Filename:    filename,
+			Content:     hit.doc.Content,
+			CommitID:    hit.doc.CommitID,
+			UpdatedUnix: hit.doc.UpdatedUnix,
+			Language:    hit.doc.Language,
+			Color:       enry.GetColor(hit.doc.Language),
+		})
+	}
+
+	searchResultLanguages := make([]*SearchResultLanguages, 0, len(languageCounts))
+	for lang, count := range languageCounts {
+		searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+			Language: lang,
+			Color:    enry.GetColor(lang),
+			Count:    count,
+		})
+	}
+	sort.Slice(searchResultLanguages, func(i, j int) bool {
+		if searchResultLanguages[i].Count != searchResultLanguages[j].Count {
+			return searchResultLanguages[i].Count > searchResultLanguages[j].Count
+		}
+		return searchResultLanguages[i].Language < searchResultLanguages[j].Language
+	})
+	if len(searchResultLanguages) > 10 {
+		searchResultLanguages = searchResultLanguages[:10]
+	}
+
+	return total, searchResults, searchResultLanguages, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigramIndexAndSearch(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	dir, err := ioutil.TempDir("", "trigram.index")
+	assert.NoError(t, err)
+	if err != nil {
+		assert.Fail(t, "Unable to create temporary directory")
+		return
+	}
+	defer util.RemoveAll(dir)
+
+	idx, _, err := NewTrigramIndexer(dir)
+	if err != nil {
+		assert.Fail(t, "Unable to create trigram indexer Error: %v", err)
+		if idx != nil {
+			idx.Close()
+		}
+		return
+	}
+	defer func() {
+		idx.Close()
+	}()
+
+	testIndexer("trigram", t, idx)
+
+	assert.NoError(t, index(idx, 1))
+	for _, kw := range []struct {
+		Keyword string
+		IsMatch bool
+		Total   int64
+	}{
+		{Keyword: "/Desc\\w+ion for/", Total: 1},
+		{Keyword: "/repo[0-9]{2}/", Total: 0},
+		{Keyword: "for repo", IsMatch: true, Total: 1},
+		{Keyword: "DESCRIPTION FOR", IsMatch: true, Total: 1},
+		{Keyword: "repo1 Description", IsMatch: true, Total: 0},
+		{Keyword: "repo1 Description", Total: 1},
+		{Keyword: "repo1 missing", Total: 0},
+	} {
+		total, res, _, err := idx.Search(&SearchOptions{Keyword: kw.Keyword, IsMatch: kw.IsMatch, Page: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.EqualValues(t, kw.Total, total, kw.Keyword)
+		for _, hit := range res {
+			assert.True(t, hit.StartIndex >= 0 && hit.StartIndex < hit.EndIndex, kw.Keyword)
+		}
+	}
+
+	_, _, _, err = idx.Search(&SearchOptions{Keyword: "/repo(/", Page: 1, PageSize: 10})
+	assert.Error(t, err)
+
+	// keywords without trigrams are verified against a limited number of files
+	total, _, _, err := idx.Search(&SearchOptions{Keyword: "re", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	ids, err := idx.allDocuments([]int64{1}, trigramMaxScannedDocuments)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+	_, err = idx.allDocuments(nil, 0)
+	assert.True(t, IsErrQueryTooBroad(err))
+
+	// the index is only searched in the given repositories
+	ids, err = idx.allDocuments([]int64{2}, trigramMaxScannedDocuments)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	query, err := parseTrigramQuery("Description", false)
+	assert.NoError(t, err)
+	ids, err = idx.candidates(query, []int64{1})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+	ids, err = idx.candidates(query, []int64{2, 36})
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	// a reopened index is kept
+	idx.Close()
+	idx, created, err := NewTrigramIndexer(dir)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	total, _, _, err = idx.Search(&SearchOptions{RepoIDs: []int64{1}, Keyword: "Description", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	assert.NoError(t, idx.Delete(1))
+	total, _, _, err = idx.Search(&SearchOptions{Keyword: "Description", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+}
+
+func TestTrigramRanking(t *testing.T) {
+	content := "// Open opens the repository\nfunc (r *Repo) Open() error {\n\treturn r.Opener()\n}\n"
+	query, err := parseTrigramQuery("Open", false)
+	assert.NoError(t, err)
+
+	hit := query.match(filenameIndexerID(1, "", "repo.go"), &trigramDocument{Content: content})
+	assert.NotNil(t, hit)
+	assert.Equal(t, strings.Index(content, "Open()"), hit.startIndex)
+
+	assert.Equal(t, trigramScoreMatch, scoreMatch(content, strings.Index(content, "Opener"), strings.Index(content, "Opener")+4))
+	assert.Equal(t, trigramScoreMatch+trigramScoreWholeWord, scoreMatch(content, 3, 7))
+
+	other, err := parseTrigramQuery("/func \\(r \\*Repo\\) (Open|Close)/", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"func (r *repo) "}, other.literals)
+}
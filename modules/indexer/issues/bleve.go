@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/util"
@@ -25,7 +26,7 @@ import (
 const (
 	issueIndexerAnalyzer      = "issueIndexer"
 	issueIndexerDocType       = "issueIndexerDocType"
-	issueIndexerLatestVersion = 1
+	issueIndexerLatestVersion = 2
 )
 
 // indexerID a bleve-compatible unique identifier for an integer id
@@ -33,6 +34,11 @@ func indexerID(id int64) string {
 	return strconv.FormatInt(id, 36)
 }
 
+// commentIndexerID a bleve-compatible unique identifier for a comment of an issue
+func commentIndexerID(issueID, commentID int64) string {
+	return indexerID(issueID) + "_" + indexerID(commentID)
+}
+
 // idOfIndexerID the integer id associated with an indexer id
 func idOfIndexerID(indexerID string) (int64, error) {
 	id, err := strconv.ParseInt(indexerID, 36, 64)
@@ -42,6 +48,16 @@ func idOfIndexerID(indexerID string) (int64, error) {
 	return id, nil
 }
 
+// idsOfIndexerID the issue id and, for a comment, the comment id associated with an indexer id
+func idsOfIndexerID(indexerID string) (issueID, commentID int64, err error) {
+	parts := strings.SplitN(indexerID, "_", 2)
+	if issueID, err = idOfIndexerID(parts[0]); err != nil || len(parts) == 1 {
+		return issueID, 0, err
+	}
+	commentID, err = idOfIndexerID(parts[1])
+	return issueID, commentID, err
+}
+
 // numericEqualityQuery a numeric equality query for the given value and field
 func numericEqualityQuery(value int64, field string) *query.NumericRangeQuery {
 	f := float64(value)
@@ -118,13 +134,17 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 	numericFieldMapping := bleve.NewNumericFieldMapping()
 	numericFieldMapping.IncludeInAll = false
 	docMapping.AddFieldMappingsAt("RepoID", numericFieldMapping)
+	docMapping.AddFieldMappingsAt("IssueID", numericFieldMapping)
+
+	boolFieldMapping := bleve.NewBooleanFieldMapping()
+	boolFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("IsComment", boolFieldMapping)
 
 	textFieldMapping := bleve.NewTextFieldMapping()
 	textFieldMapping.Store = false
 	textFieldMapping.IncludeInAll = false
 	docMapping.AddFieldMappingsAt("Title", textFieldMapping)
 	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
-	docMapping.AddFieldMappingsAt("Comments", textFieldMapping)
 
 	if err := addUnicodeNormalizeTokenFilter(mapping); err != nil {
 		return nil, err
@@ -195,35 +215,115 @@ func (b *BleveIndexer) Close() {
 	}
 }
 
+// bleveIssueDocument is an issue or a comment of it, as stored in the indexer
+type bleveIssueDocument struct {
+	RepoID    int64
+	IssueID   int64
+	IsComment bool
+	Title     string
+	Content   string
+}
+
+// commentIndexerIDs returns the indexer ids of the comments indexed for the issues
+func (b *BleveIndexer) commentIndexerIDs(issueIDs ...int64) ([]string, error) {
+	issueQueries := make([]query.Query, 0, len(issueIDs))
+	for _, issueID := range issueIDs {
+		issueQueries = append(issueQueries, numericEqualityQuery(issueID, "IssueID"))
+	}
+	isCommentQuery := bleve.NewBoolFieldQuery(true)
+	isCommentQuery.SetField("IsComment")
+	indexerQuery := bleve.NewConjunctionQuery(bleve.NewDisjunctionQuery(issueQueries...), isCommentQuery)
+
+	var ids []string
+	const pageSize = 1000
+	for start := 0; ; start += pageSize {
+		result, err := b.indexer.Search(bleve.NewSearchRequestOptions(indexerQuery, pageSize, start, false))
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.ID)
+		}
+		if len(result.Hits) < pageSize {
+			return ids, nil
+		}
+	}
+}
+
 // Index will save the index data
 func (b *BleveIndexer) Index(issues []*IndexerData) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	issueIDs := make([]int64, 0, len(issues))
+	for _, issue := range issues {
+		issueIDs = append(issueIDs, issue.ID)
+	}
+	indexedCommentIDs, err := b.commentIndexerIDs(issueIDs...)
+	if err != nil {
+		return err
+	}
+
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
+	commentIDs := make(map[string]bool)
 	for _, issue := range issues {
-		if err := batch.Index(indexerID(issue.ID), struct {
-			RepoID   int64
-			Title    string
-			Content  string
-			Comments []string
-		}{
-			RepoID:   issue.RepoID,
-			Title:    issue.Title,
-			Content:  issue.Content,
-			Comments: issue.Comments,
+		if err := batch.Index(indexerID(issue.ID), &bleveIssueDocument{
+			RepoID:  issue.RepoID,
+			IssueID: issue.ID,
+			Title:   issue.Title,
+			Content: issue.Content,
 		}); err != nil {
 			return err
 		}
+
+		for _, comment := range issue.Comments {
+			id := commentIndexerID(issue.ID, comment.ID)
+			commentIDs[id] = true
+			if err := batch.Index(id, &bleveIssueDocument{
+				RepoID:    issue.RepoID,
+				IssueID:   issue.ID,
+				IsComment: true,
+				Content:   comment.Content,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// delete the comments which are gone since the issues were indexed
+	for _, id := range indexedCommentIDs {
+		if commentIDs[id] {
+			continue
+		}
+		if err := batch.Delete(id); err != nil {
+			return err
+		}
 	}
 	return batch.Flush()
 }
 
 // Delete deletes indexes by ids
 func (b *BleveIndexer) Delete(ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	commentIDs, err := b.commentIndexerIDs(ids...)
+	if err != nil {
+		return err
+	}
+
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
 	for _, id := range ids {
 		if err := batch.Delete(indexerID(id)); err != nil {
 			return err
 		}
 	}
+	for _, id := range commentIDs {
+		if err := batch.Delete(id); err != nil {
+			return err
+		}
+	}
 	return batch.Flush()
 }
 
@@ -244,27 +344,39 @@ func (b *BleveIndexer) Search(keyword string, repoIDs []int64, limit, start int)
 		bleve.NewDisjunctionQuery(
 			newMatchPhraseQuery(keyword, "Title", issueIndexerAnalyzer),
 			newMatchPhraseQuery(keyword, "Content", issueIndexerAnalyzer),
-			newMatchPhraseQuery(keyword, "Comments", issueIndexerAnalyzer),
 		))
-	search := bleve.NewSearchRequestOptions(indexerQuery, limit, start, false)
-	search.SortBy([]string{"-_score"})
 
-	result, err := b.indexer.Search(search)
-	if err != nil {
-		return nil, err
+	// an issue may match by several comments, so the documents are paged through until limit issues are found
+	collector := newMatchCollector(limit, start)
+	pageSize := limit * 4
+	if pageSize < 50 {
+		pageSize = 50
 	}
+	for from := 0; !collector.full(); from += pageSize {
+		search := bleve.NewSearchRequestOptions(indexerQuery, pageSize, from, false)
+		search.SortBy([]string{"-_score", "_id"})
 
-	var ret = SearchResult{
-		Hits: make([]Match, 0, len(result.Hits)),
-	}
-	for _, hit := range result.Hits {
-		id, err := idOfIndexerID(hit.ID)
+		result, err := b.indexer.Search(search)
 		if err != nil {
 			return nil, err
 		}
-		ret.Hits = append(ret.Hits, Match{
-			ID: id,
-		})
+
+		for _, hit := range result.Hits {
+			id, commentID, err := idsOfIndexerID(hit.ID)
+			if err != nil {
+				return nil, err
+			}
+			collector.add(Match{
+				ID:        id,
+				CommentID: commentID,
+				Score:     hit.Score,
+			})
+		}
+		if len(result.Hits) < pageSize {
+			break
+		}
 	}
-	return &ret, nil
+	return &SearchResult{
+		Hits: collector.hits,
+	}, nil
 }
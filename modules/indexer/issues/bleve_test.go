@@ -34,9 +34,9 @@ func TestBleveIndexAndSearch(t *testing.T) {
 			RepoID:  2,
 			Title:   "Issue search should support Chinese",
 			Content: "As title",
-			Comments: []string{
-				"test1",
-				"test2",
+			Comments: []*CommentData{
+				{ID: 1, Content: "test1"},
+				{ID: 2, Content: "test2"},
 			},
 		},
 		{
@@ -44,9 +44,9 @@ func TestBleveIndexAndSearch(t *testing.T) {
 			RepoID:  2,
 			Title:   "CJK support could be optional",
 			Content: "Chinese Korean and Japanese should be supported but I would like it's not enabled by default",
-			Comments: []string{
-				"LGTM",
-				"Good idea",
+			Comments: []*CommentData{
+				{ID: 3, Content: "LGTM"},
+				{ID: 4, Content: "Good idea"},
 			},
 		},
 	})
@@ -94,4 +94,60 @@ func TestBleveIndexAndSearch(t *testing.T) {
 		}
 		assert.ElementsMatch(t, kw.IDs, ids)
 	}
+
+	res, err := indexer.Search("good idea", []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []Match{{ID: 2, CommentID: 4}}, clearScores(res.Hits))
+
+	// comments are replaced on reindexing
+	err = indexer.Index([]*IndexerData{
+		{
+			ID:       1,
+			RepoID:   2,
+			Title:    "Issue search should support Chinese",
+			Content:  "As title",
+			Comments: []*CommentData{{ID: 1, Content: "test1 again"}},
+		},
+	})
+	assert.NoError(t, err)
+	res, err = indexer.Search("test2", []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Hits)
+	res, err = indexer.Search("again", []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []Match{{ID: 1, CommentID: 1}}, clearScores(res.Hits))
+
+	// an issue is found once, even if it matches by more comments than fit on a page
+	comments := make([]*CommentData, 0, 60)
+	for i := int64(0); i < 60; i++ {
+		comments = append(comments, &CommentData{ID: 100 + i, Content: "needle needle"})
+	}
+	err = indexer.Index([]*IndexerData{
+		{ID: 3, RepoID: 2, Title: "needle", Content: "needle needle", Comments: comments},
+		{ID: 4, RepoID: 2, Title: "haystack", Content: "a needle in a haystack"},
+	})
+	assert.NoError(t, err)
+	res, err = indexer.Search("needle", []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, res.Hits, 2) {
+		assert.EqualValues(t, 3, res.Hits[0].ID)
+		assert.EqualValues(t, 4, res.Hits[1].ID)
+	}
+	res, err = indexer.Search("needle", []int64{2}, 1, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, res.Hits, 1) {
+		assert.EqualValues(t, 4, res.Hits[0].ID)
+	}
+
+	assert.NoError(t, indexer.Delete(1))
+	res, err = indexer.Search("test1", []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Hits)
+}
+
+func clearScores(matches []Match) []Match {
+	for i := range matches {
+		matches[i].Score = 0
+	}
+	return matches
 }
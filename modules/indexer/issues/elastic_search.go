@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
@@ -65,6 +66,8 @@ func NewElasticSearchIndexer(url, indexerName string) (*ElasticSearchIndexer, er
 }
 
 const (
+	esIssueIndexerLatestVersion = 1
+
 	defaultMapping = `{
 		"mappings": {
 			"properties": {
@@ -72,6 +75,10 @@ const (
 					"type": "integer",
 					"index": true
 				},
+				"comment_id": {
+					"type": "integer",
+					"index": true
+				},
 				"repo_id": {
 					"type": "integer",
 					"index": true
@@ -83,20 +90,22 @@ const (
 				"content": {
 					"type": "text",
 					"index": true
-				},
-				"comments": {
-					"type" : "text",
-					"index": true
 				}
 			}
 		}
 	}`
 )
 
+// realIndexerName returns the name of the index of the latest version, so that an index of
+// a previous version is populated again
+func (b *ElasticSearchIndexer) realIndexerName() string {
+	return fmt.Sprintf("%s.v%d", b.indexerName, esIssueIndexerLatestVersion)
+}
+
 // Init will initialize the indexer
 func (b *ElasticSearchIndexer) Init() (bool, error) {
 	ctx := context.Background()
-	exists, err := b.client.IndexExists(b.indexerName).Do(ctx)
+	exists, err := b.client.IndexExists(b.realIndexerName()).Do(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -104,7 +113,7 @@ func (b *ElasticSearchIndexer) Init() (bool, error) {
 	if !exists {
 		var mapping = defaultMapping
 
-		createIndex, err := b.client.CreateIndex(b.indexerName).BodyString(mapping).Do(ctx)
+		createIndex, err := b.client.CreateIndex(b.realIndexerName()).BodyString(mapping).Do(ctx)
 		if err != nil {
 			return false, err
 		}
@@ -121,69 +130,72 @@ func (b *ElasticSearchIndexer) Init() (bool, error) {
 func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 	if len(issues) == 0 {
 		return nil
-	} else if len(issues) == 1 {
-		issue := issues[0]
-		_, err := b.client.Index().
-			Index(b.indexerName).
-			Id(fmt.Sprintf("%d", issue.ID)).
-			BodyJson(map[string]interface{}{
-				"id":       issue.ID,
-				"repo_id":  issue.RepoID,
-				"title":    issue.Title,
-				"content":  issue.Content,
-				"comments": issue.Comments,
-			}).
-			Do(context.Background())
-		return err
 	}
 
-	reqs := make([]elastic.BulkableRequest, 0)
+	reqs := make([]elastic.BulkableRequest, 0, len(issues))
+	issueIDs := make([]interface{}, 0, len(issues))
+	var commentIDs []interface{}
 	for _, issue := range issues {
 		reqs = append(reqs,
 			elastic.NewBulkIndexRequest().
-				Index(b.indexerName).
+				Index(b.realIndexerName()).
 				Id(fmt.Sprintf("%d", issue.ID)).
 				Doc(map[string]interface{}{
-					"id":       issue.ID,
-					"repo_id":  issue.RepoID,
-					"title":    issue.Title,
-					"content":  issue.Content,
-					"comments": issue.Comments,
+					"id":      issue.ID,
+					"repo_id": issue.RepoID,
+					"title":   issue.Title,
+					"content": issue.Content,
 				}),
 		)
+
+		issueIDs = append(issueIDs, issue.ID)
+		for _, comment := range issue.Comments {
+			commentIDs = append(commentIDs, comment.ID)
+			reqs = append(reqs,
+				elastic.NewBulkIndexRequest().
+					Index(b.realIndexerName()).
+					Id(fmt.Sprintf("%d_%d", issue.ID, comment.ID)).
+					Doc(map[string]interface{}{
+						"id":         issue.ID,
+						"comment_id": comment.ID,
+						"repo_id":    issue.RepoID,
+						"content":    comment.Content,
+					}),
+			)
+		}
+	}
+
+	// delete the comments which are gone since the issues were indexed, comment ids are unique across issues
+	staleQuery := elastic.NewBoolQuery().
+		Must(elastic.NewTermsQuery("id", issueIDs...), elastic.NewRangeQuery("comment_id").Gt(0))
+	if len(commentIDs) > 0 {
+		staleQuery = staleQuery.MustNot(elastic.NewTermsQuery("comment_id", commentIDs...))
+	}
+	if _, err := b.client.DeleteByQuery(b.realIndexerName()).
+		Query(staleQuery).
+		Do(context.Background()); err != nil {
+		return err
 	}
 
 	_, err := b.client.Bulk().
-		Index(b.indexerName).
+		Index(b.realIndexerName()).
 		Add(reqs...).
 		Do(context.Background())
 	return err
 }
 
-// Delete deletes indexes by ids
+// Delete deletes indexes by ids, with the comments of the issues
 func (b *ElasticSearchIndexer) Delete(ids ...int64) error {
 	if len(ids) == 0 {
 		return nil
-	} else if len(ids) == 1 {
-		_, err := b.client.Delete().
-			Index(b.indexerName).
-			Id(fmt.Sprintf("%d", ids[0])).
-			Do(context.Background())
-		return err
 	}
 
-	reqs := make([]elastic.BulkableRequest, 0)
+	issueIDs := make([]interface{}, 0, len(ids))
 	for _, id := range ids {
-		reqs = append(reqs,
-			elastic.NewBulkDeleteRequest().
-				Index(b.indexerName).
-				Id(fmt.Sprintf("%d", id)),
-		)
+		issueIDs = append(issueIDs, id)
 	}
-
-	_, err := b.client.Bulk().
-		Index(b.indexerName).
-		Add(reqs...).
+	_, err := b.client.DeleteByQuery(b.realIndexerName()).
+		Query(elastic.NewTermsQuery("id", issueIDs...)).
 		Do(context.Background())
 	return err
 }
@@ -191,7 +203,7 @@ func (b *ElasticSearchIndexer) Delete(ids ...int64) error {
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
 func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	kwQuery := elastic.NewMultiMatchQuery(keyword, "title", "content", "comments")
+	kwQuery := elastic.NewMultiMatchQuery(keyword, "title", "content")
 	query := elastic.NewBoolQuery()
 	query = query.Must(kwQuery)
 	if len(repoIDs) > 0 {
@@ -202,9 +214,13 @@ func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, st
 		repoQuery := elastic.NewTermsQuery("repo_id", repoStrs...)
 		query = query.Must(repoQuery)
 	}
+	// an issue may match by several comments, so the matches are collapsed to the best one of every issue
+	// and the issues are counted instead of the documents
 	searchResult, err := b.client.Search().
-		Index(b.indexerName).
+		Index(b.realIndexerName()).
 		Query(query).
+		Collapse(elastic.NewCollapseBuilder("id")).
+		Aggregation("issues", elastic.NewCardinalityAggregation().Field("id")).
 		Sort("_score", false).
 		From(start).Size(limit).
 		Do(context.Background())
@@ -214,14 +230,25 @@ func (b *ElasticSearchIndexer) Search(keyword string, repoIDs []int64, limit, st
 
 	hits := make([]Match, 0, limit)
 	for _, hit := range searchResult.Hits.Hits {
-		id, _ := strconv.ParseInt(hit.Id, 10, 64)
-		hits = append(hits, Match{
-			ID: id,
-		})
+		// the id of a comment is the issue id and the comment id joined by "_"
+		parts := strings.SplitN(hit.Id, "_", 2)
+		match := Match{}
+		match.ID, _ = strconv.ParseInt(parts[0], 10, 64)
+		if len(parts) == 2 {
+			match.CommentID, _ = strconv.ParseInt(parts[1], 10, 64)
+		}
+		if hit.Score != nil {
+			match.Score = *hit.Score
+		}
+		hits = append(hits, match)
 	}
 
+	var total int64
+	if issues, ok := searchResult.Aggregations.Cardinality("issues"); ok && issues.Value != nil {
+		total = int64(*issues.Value)
+	}
 	return &SearchResult{
-		Total: searchResult.TotalHits(),
+		Total: total,
 		Hits:  hits,
 	}, nil
 }
@@ -21,19 +21,61 @@ import (
 
 // IndexerData data stored in the issue indexer
 type IndexerData struct {
-	ID       int64    `json:"id"`
-	RepoID   int64    `json:"repo_id"`
-	Title    string   `json:"title"`
-	Content  string   `json:"content"`
-	Comments []string `json:"comments"`
-	IsDelete bool     `json:"is_delete"`
-	IDs      []int64  `json:"ids"`
+	ID      int64  `json:"id"`
+	RepoID  int64  `json:"repo_id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// Comments are indexed as documents of their own, replacing the comments indexed before
+	Comments []*CommentData `json:"comments"`
+	IsDelete bool           `json:"is_delete"`
+	IDs      []int64        `json:"ids"`
+}
+
+// CommentData data of an issue comment stored in the issue indexer
+type CommentData struct {
+	ID      int64  `json:"id"`
+	Content string `json:"content"`
 }
 
 // Match represents on search result
 type Match struct {
-	ID    int64   `json:"id"`
-	Score float64 `json:"score"`
+	ID int64 `json:"id"`
+	// CommentID is the id of the matching comment, or 0 if the issue itself matched
+	CommentID int64   `json:"comment_id"`
+	Score     float64 `json:"score"`
+}
+
+// matchCollector collects the matches of distinct issues, skipping the matches of the first start issues,
+// as an issue may match by several comments
+type matchCollector struct {
+	limit, start int
+	seen         map[int64]bool
+	hits         []Match
+}
+
+func newMatchCollector(limit, start int) *matchCollector {
+	return &matchCollector{
+		limit: limit,
+		start: start,
+		seen:  make(map[int64]bool),
+		hits:  make([]Match, 0, limit),
+	}
+}
+
+// add adds the match if it's the first one of its issue
+func (c *matchCollector) add(match Match) {
+	if c.seen[match.ID] {
+		return
+	}
+	c.seen[match.ID] = true
+	if len(c.seen) > c.start && !c.full() {
+		c.hits = append(c.hits, match)
+	}
+}
+
+// full returns whether limit issues have been collected
+func (c *matchCollector) full() bool {
+	return len(c.hits) >= c.limit
 }
 
 // SearchResult represents search results
@@ -278,7 +320,7 @@ func UpdateRepoIndexer(repo *models.Repository) {
 		log.Error("Issues: %v", err)
 		return
 	}
-	if err = models.IssueList(is).LoadDiscussComments(); err != nil {
+	if err = models.IssueList(is).LoadSearchableComments(); err != nil {
 		log.Error("LoadComments: %v", err)
 		return
 	}
@@ -287,12 +329,20 @@ func UpdateRepoIndexer(repo *models.Repository) {
 	}
 }
 
-// UpdateIssueIndexer add/update an issue to the issue indexer
+// UpdateIssueIndexer add/update an issue to the issue indexer, with the comments loaded
+// by models.Issue.LoadSearchableComments
 func UpdateIssueIndexer(issue *models.Issue) {
-	var comments []string
+	var comments []*CommentData
 	for _, comment := range issue.Comments {
-		if comment.Type == models.CommentTypeComment {
-			comments = append(comments, comment.Content)
+		if len(comment.Content) == 0 {
+			continue
+		}
+		switch comment.Type {
+		case models.CommentTypeComment, models.CommentTypeCode, models.CommentTypeReview:
+			comments = append(comments, &CommentData{
+				ID:      comment.ID,
+				Content: comment.Content,
+			})
 		}
 	}
 	indexerData := &IndexerData{
@@ -332,19 +382,30 @@ func DeleteRepoIssueIndexer(repo *models.Repository) {
 // SearchIssuesByKeyword search issue ids by keywords and repo id
 // WARNNING: You have to ensure user have permission to visit repoIDs' issues
 func SearchIssuesByKeyword(repoIDs []int64, keyword string) ([]int64, error) {
+	matches, err := SearchIssueMatchesByKeyword(repoIDs, keyword)
+	if err != nil {
+		return nil, err
+	}
 	var issueIDs []int64
+	for _, match := range matches {
+		issueIDs = append(issueIDs, match.ID)
+	}
+	return issueIDs, nil
+}
+
+// SearchIssueMatchesByKeyword search issues by keywords and repo id, returning which comment
+// of an issue matched
+// WARNNING: You have to ensure user have permission to visit repoIDs' issues
+func SearchIssueMatchesByKeyword(repoIDs []int64, keyword string) ([]Match, error) {
 	indexer := holder.get()
 
 	if indexer == nil {
-		log.Error("SearchIssuesByKeyword(): unable to get indexer!")
+		log.Error("SearchIssueMatchesByKeyword(): unable to get indexer!")
 		return nil, fmt.Errorf("unable to get issue indexer")
 	}
 	res, err := indexer.Search(keyword, repoIDs, 50, 0)
 	if err != nil {
 		return nil, err
 	}
-	for _, r := range res.Hits {
-		issueIDs = append(issueIDs, r.ID)
-	}
-	return issueIDs, nil
+	return res.Hits, nil
 }
@@ -71,6 +71,20 @@ func TestBleveSearchIssues(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
 
+	matches, err := SearchIssueMatchesByKeyword([]int64{1}, "good")
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.EqualValues(t, 1, matches[0].ID)
+		assert.EqualValues(t, 2, matches[0].CommentID)
+	}
+
+	// code comments of published reviews are indexed
+	matches, err = SearchIssueMatchesByKeyword([]int64{1}, "boring")
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.EqualValues(t, 2, matches[0].ID)
+		assert.EqualValues(t, 6, matches[0].CommentID)
+	}
 }
 
 func TestDBSearchIssues(t *testing.T) {
@@ -0,0 +1,85 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SearchQuery represents an issue search query with its qualifiers split off
+type SearchQuery struct {
+	Keyword string
+	// State is "open", "closed" or empty
+	State string
+	// Type is "issue", "pr" or empty
+	Type string
+	// Labels have all to be set on an issue
+	Labels    []string
+	Author    string
+	Assignee  string
+	Mentions  string
+	Milestone string
+	// SortType is the sort type of models.IssuesOptions or empty
+	SortType string
+}
+
+var searchQualifierPattern = regexp.MustCompile(`(?:^|\s)(is|label|author|assignee|mentions|milestone|sort):("[^"]*"|\S*)`)
+
+// issueSortTypes maps the values of the `sort:` qualifier to sort types
+var issueSortTypes = map[string]string{
+	"created":       "newest",
+	"created-desc":  "newest",
+	"created-asc":   "oldest",
+	"updated":       "recentupdate",
+	"updated-desc":  "recentupdate",
+	"updated-asc":   "leastupdate",
+	"comments":      "mostcomment",
+	"comments-desc": "mostcomment",
+	"comments-asc":  "leastcomment",
+	"due":           "nearduedate",
+	"due-asc":       "nearduedate",
+	"due-desc":      "farduedate",
+}
+
+// ParseSearchQuery splits the qualifiers `is:`, `label:`, `author:`, `assignee:`, `mentions:`,
+// `milestone:` and `sort:` off an issue search query. Values containing spaces can be quoted, and
+// `label:` may be given several times.
+func ParseSearchQuery(query string) *SearchQuery {
+	result := &SearchQuery{}
+	keyword := searchQualifierPattern.ReplaceAllStringFunc(query, func(match string) string {
+		submatches := searchQualifierPattern.FindStringSubmatch(match)
+		value := strings.Trim(submatches[2], `"`)
+		if len(value) == 0 {
+			return " "
+		}
+		switch submatches[1] {
+		case "is":
+			switch strings.ToLower(value) {
+			case "open", "closed":
+				result.State = strings.ToLower(value)
+			case "issue":
+				result.Type = "issue"
+			case "pr", "pull":
+				result.Type = "pr"
+			}
+		case "label":
+			result.Labels = append(result.Labels, value)
+		case "author":
+			result.Author = value
+		case "assignee":
+			result.Assignee = value
+		case "mentions":
+			result.Mentions = value
+		case "milestone":
+			result.Milestone = value
+		case "sort":
+			result.SortType = issueSortTypes[strings.ToLower(value)]
+		}
+		return " "
+	})
+	result.Keyword = strings.TrimSpace(keyword)
+	return result
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	for _, c := range []struct {
+		Query    string
+		Expected *SearchQuery
+	}{
+		{
+			Query:    "crash on start",
+			Expected: &SearchQuery{Keyword: "crash on start"},
+		},
+		{
+			Query: `is:open label:bug author:x assignee:@me milestone:"1.2 beta" sort:updated crash  label:"needs review" is:pr`,
+			Expected: &SearchQuery{
+				Keyword:   "crash",
+				State:     "open",
+				Type:      "pr",
+				Labels:    []string{"bug", "needs review"},
+				Author:    "x",
+				Assignee:  "@me",
+				Milestone: "1.2 beta",
+				SortType:  "recentupdate",
+			},
+		},
+		{
+			Query:    "is:Closed is:issue sort:comments-asc mentions:user2",
+			Expected: &SearchQuery{State: "closed", Type: "issue", Mentions: "user2", SortType: "leastcomment"},
+		},
+		{
+			Query:    "label: sort:unknown is:unknown keyword",
+			Expected: &SearchQuery{Keyword: "keyword"},
+		},
+	} {
+		assert.Equal(t, c.Expected, ParseSearchQuery(c.Query), c.Query)
+	}
+}
@@ -9,20 +9,21 @@ import "time"
 
 // Issue is a standard issue information
 type Issue struct {
-	Number      int64
-	PosterID    int64  `yaml:"poster_id"`
-	PosterName  string `yaml:"poster_name"`
-	PosterEmail string `yaml:"poster_email"`
-	Title       string
-	Content     string
-	Ref         string
-	Milestone   string
-	State       string // closed, open
-	IsLocked    bool   `yaml:"is_locked"`
-	Created     time.Time
-	Updated     time.Time
-	Closed      *time.Time
-	Labels      []*Label
-	Reactions   []*Reaction
-	Assignees   []string
+	Number         int64
+	OriginalNumber int64  `yaml:"original_number"` // the number on the source when it differs from Number
+	PosterID       int64  `yaml:"poster_id"`
+	PosterName     string `yaml:"poster_name"`
+	PosterEmail    string `yaml:"poster_email"`
+	Title          string
+	Content        string
+	Ref            string
+	Milestone      string
+	State          string // closed, open
+	IsLocked       bool   `yaml:"is_locked"`
+	Created        time.Time
+	Updated        time.Time
+	Closed         *time.Time
+	Labels         []*Label
+	Reactions      []*Reaction
+	Assignees      []string
 }
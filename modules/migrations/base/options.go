@@ -36,4 +36,6 @@ type MigrateOptions struct {
 	ReleaseAssets   bool
 	MigrateToRepoID int64
 	MirrorInterval  string `json:"mirror_interval"`
+	// Incremental keeps the issues, pull requests, comments and releases of a mirror in sync with the source
+	Incremental bool `json:"incremental"`
 }
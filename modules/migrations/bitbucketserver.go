@@ -0,0 +1,489 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &BitbucketServerDownloader{}
+	_ base.DownloaderFactory = &BitbucketServerDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&BitbucketServerDownloaderFactory{})
+}
+
+// BitbucketServerDownloaderFactory defines a Bitbucket Server downloader factory
+type BitbucketServerDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *BitbucketServerDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, projectKey, repoSlug, err := parseBitbucketServerURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("Create Bitbucket Server downloader. BaseURL: %s ProjectKey: %s RepoSlug: %s", baseURL, projectKey, repoSlug)
+	return NewBitbucketServerDownloader(ctx, baseURL, opts.AuthUsername, opts.AuthPassword, projectKey, repoSlug), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *BitbucketServerDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.BitbucketServerService
+}
+
+// parseBitbucketServerURL extracts the base URL, the project key and the repository slug from
+// the clone URL (/scm/KEY/slug.git) or the web URL (/projects/KEY/repos/slug or /users/name/repos/slug)
+// of a repository, Bitbucket Server might be served from a context path
+func parseBitbucketServerURL(u *url.URL) (baseURL, projectKey, repoSlug string, err error) {
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := range fields {
+		switch {
+		case fields[i] == "scm" && len(fields) > i+2:
+			projectKey = fields[i+1]
+			repoSlug = strings.TrimSuffix(fields[i+2], ".git")
+		case fields[i] == "projects" && len(fields) > i+3 && fields[i+2] == "repos":
+			projectKey = fields[i+1]
+			repoSlug = fields[i+3]
+		case fields[i] == "users" && len(fields) > i+3 && fields[i+2] == "repos":
+			// personal repositories belong to the "~name" project
+			projectKey = "~" + fields[i+1]
+			repoSlug = fields[i+3]
+		default:
+			continue
+		}
+
+		baseURL = u.Scheme + "://" + u.Host
+		if i > 0 {
+			baseURL += "/" + strings.Join(fields[:i], "/")
+		}
+		return baseURL, projectKey, repoSlug, nil
+	}
+	return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+}
+
+type bitbucketServerUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Slug         string `json:"slug"`
+}
+
+type bitbucketServerLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type bitbucketServerRepository struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Project     struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []bitbucketServerLink `json:"clone"`
+		Self  []bitbucketServerLink `json:"self"`
+	} `json:"links"`
+}
+
+// httpCloneURL returns the HTTP(S) clone URL of the repository
+func (r *bitbucketServerRepository) httpCloneURL() string {
+	for _, link := range r.Links.Clone {
+		if link.Name == "http" || link.Name == "https" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+type bitbucketServerRef struct {
+	ID           string                    `json:"id"`
+	DisplayID    string                    `json:"displayId"`
+	LatestCommit string                    `json:"latestCommit"`
+	Repository   bitbucketServerRepository `json:"repository"`
+}
+
+type bitbucketServerPullRequest struct {
+	ID          int64              `json:"id"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	State       string             `json:"state"`
+	CreatedDate int64              `json:"createdDate"`
+	UpdatedDate int64              `json:"updatedDate"`
+	ClosedDate  int64              `json:"closedDate"`
+	FromRef     bitbucketServerRef `json:"fromRef"`
+	ToRef       bitbucketServerRef `json:"toRef"`
+	Author      struct {
+		User bitbucketServerUser `json:"user"`
+	} `json:"author"`
+	Properties struct {
+		MergeCommit *struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+}
+
+type bitbucketServerComment struct {
+	ID          int64                     `json:"id"`
+	Text        string                    `json:"text"`
+	Author      bitbucketServerUser       `json:"author"`
+	CreatedDate int64                     `json:"createdDate"`
+	UpdatedDate int64                     `json:"updatedDate"`
+	Comments    []*bitbucketServerComment `json:"comments"`
+}
+
+type bitbucketServerActivity struct {
+	ID            int64                   `json:"id"`
+	CreatedDate   int64                   `json:"createdDate"`
+	User          bitbucketServerUser     `json:"user"`
+	Action        string                  `json:"action"`
+	CommentAction string                  `json:"commentAction"`
+	Comment       *bitbucketServerComment `json:"comment"`
+	CommentAnchor *struct {
+		Path     string `json:"path"`
+		Line     int    `json:"line"`
+		FileType string `json:"fileType"`
+		FromHash string `json:"fromHash"`
+		ToHash   string `json:"toHash"`
+	} `json:"commentAnchor"`
+}
+
+// bitbucketServerTime converts the milliseconds timestamps of the Bitbucket Server API
+func bitbucketServerTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
+
+// BitbucketServerDownloader implements a Downloader interface to get repository informations
+// from Bitbucket Server via its REST API, Bitbucket Server has no issues, milestones, labels
+// or releases so only pull requests, their comments and their reviews are migrated
+type BitbucketServerDownloader struct {
+	base.NullDownloader
+	ctx        context.Context
+	client     *http.Client
+	baseURL    string
+	userName   string
+	password   string
+	projectKey string
+	repoSlug   string
+	maxPerPage int
+}
+
+// NewBitbucketServerDownloader creates a Bitbucket Server downloader
+func NewBitbucketServerDownloader(ctx context.Context, baseURL, userName, password, projectKey, repoSlug string) *BitbucketServerDownloader {
+	return &BitbucketServerDownloader{
+		ctx:        ctx,
+		client:     &http.Client{},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		userName:   userName,
+		password:   password,
+		projectKey: projectKey,
+		repoSlug:   repoSlug,
+		maxPerPage: 100,
+	}
+}
+
+// SetContext set context
+func (d *BitbucketServerDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// callAPI requests an endpoint of the repository in the REST API and decodes its response into result
+func (d *BitbucketServerDownloader) callAPI(endpoint string, parameter url.Values, result interface{}) error {
+	u := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s%s", d.baseURL, url.PathEscape(d.projectKey), url.PathEscape(d.repoSlug), endpoint)
+	if len(parameter) > 0 {
+		u += "?" + parameter.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if d.userName != "" || d.password != "" {
+		req.SetBasicAuth(d.userName, d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d calling %s", resp.StatusCode, req.URL.Path)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// GetRepoInfo returns a repository information
+func (d *BitbucketServerDownloader) GetRepoInfo() (*base.Repository, error) {
+	var repo bitbucketServerRepository
+	if err := d.callAPI("", nil, &repo); err != nil {
+		return nil, err
+	}
+
+	// empty repositories have no default branch
+	var defaultBranch struct {
+		DisplayID string `json:"displayId"`
+	}
+	if err := d.callAPI("/branches/default", nil, &defaultBranch); err != nil {
+		log.Warn("Unable to get the default branch of %s/%s: %v", d.projectKey, d.repoSlug, err)
+	}
+
+	var originalURL string
+	if len(repo.Links.Self) > 0 {
+		originalURL = repo.Links.Self[0].Href
+	}
+
+	return &base.Repository{
+		Name:          repo.Slug,
+		Owner:         repo.Project.Key,
+		IsPrivate:     !repo.Public,
+		Description:   repo.Description,
+		CloneURL:      repo.httpCloneURL(),
+		OriginalURL:   originalURL,
+		DefaultBranch: defaultBranch.DisplayID,
+	}, nil
+}
+
+// GetTopics return repository topics
+func (d *BitbucketServerDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (d *BitbucketServerDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	if perPage > d.maxPerPage {
+		perPage = d.maxPerPage
+	}
+
+	var result struct {
+		IsLastPage bool                          `json:"isLastPage"`
+		Values     []*bitbucketServerPullRequest `json:"values"`
+	}
+	err := d.callAPI("/pull-requests", url.Values{
+		"state": {"ALL"},
+		"order": {"OLDEST"},
+		"start": {strconv.Itoa((page - 1) * perPage)},
+		"limit": {strconv.Itoa(perPage)},
+	}, &result)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var allPRs = make([]*base.PullRequest, 0, len(result.Values))
+	for _, pr := range result.Values {
+		allPRs = append(allPRs, convertBitbucketServerPullRequest(pr))
+	}
+
+	return allPRs, result.IsLastPage, nil
+}
+
+func convertBitbucketServerPullRequest(pr *bitbucketServerPullRequest) *base.PullRequest {
+	state := "open"
+	var closed *time.Time
+	if pr.State != "OPEN" {
+		state = "closed"
+		closedDate := pr.ClosedDate
+		if closedDate == 0 {
+			// only recent versions report when a pull request was closed
+			closedDate = pr.UpdatedDate
+		}
+		t := bitbucketServerTime(closedDate)
+		closed = &t
+	}
+
+	var merged bool
+	var mergedTime *time.Time
+	var mergeCommitSHA string
+	if pr.State == "MERGED" {
+		merged = true
+		mergedTime = closed
+		if pr.Properties.MergeCommit != nil {
+			mergeCommitSHA = pr.Properties.MergeCommit.ID
+		}
+	}
+
+	return &base.PullRequest{
+		Number:         pr.ID,
+		Title:          pr.Title,
+		PosterID:       pr.Author.User.ID,
+		PosterName:     pr.Author.User.Name,
+		PosterEmail:    pr.Author.User.EmailAddress,
+		Content:        pr.Description,
+		State:          state,
+		Created:        bitbucketServerTime(pr.CreatedDate),
+		Updated:        bitbucketServerTime(pr.UpdatedDate),
+		Closed:         closed,
+		Merged:         merged,
+		MergedTime:     mergedTime,
+		MergeCommitSHA: mergeCommitSHA,
+		Head: base.PullRequestBranch{
+			CloneURL:  pr.FromRef.Repository.httpCloneURL(),
+			Ref:       pr.FromRef.DisplayID,
+			SHA:       pr.FromRef.LatestCommit,
+			RepoName:  pr.FromRef.Repository.Slug,
+			OwnerName: pr.FromRef.Repository.Project.Key,
+		},
+		Base: base.PullRequestBranch{
+			CloneURL:  pr.ToRef.Repository.httpCloneURL(),
+			Ref:       pr.ToRef.DisplayID,
+			SHA:       pr.ToRef.LatestCommit,
+			RepoName:  pr.ToRef.Repository.Slug,
+			OwnerName: pr.ToRef.Repository.Project.Key,
+		},
+	}
+}
+
+// getActivities returns the activities of a pull request, the newest first
+func (d *BitbucketServerDownloader) getActivities(pullRequestNumber int64) ([]*bitbucketServerActivity, error) {
+	var activities = make([]*bitbucketServerActivity, 0, d.maxPerPage)
+	start := 0
+	for {
+		var result struct {
+			IsLastPage    bool                       `json:"isLastPage"`
+			NextPageStart int                        `json:"nextPageStart"`
+			Values        []*bitbucketServerActivity `json:"values"`
+		}
+		err := d.callAPI(fmt.Sprintf("/pull-requests/%d/activities", pullRequestNumber), url.Values{
+			"start": {strconv.Itoa(start)},
+			"limit": {strconv.Itoa(d.maxPerPage)},
+		}, &result)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, result.Values...)
+		if result.IsLastPage {
+			break
+		}
+		start = result.NextPageStart
+	}
+	return activities, nil
+}
+
+// GetComments returns the general comments of a pull request and their replies,
+// Bitbucket Server has no issues
+func (d *BitbucketServerDownloader) GetComments(pullRequestNumber int64) ([]*base.Comment, error) {
+	activities, err := d.getActivities(pullRequestNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var allComments = make([]*base.Comment, 0, len(activities))
+	var appendComment func(c *bitbucketServerComment)
+	appendComment = func(c *bitbucketServerComment) {
+		allComments = append(allComments, &base.Comment{
+			IssueIndex:  pullRequestNumber,
+			PosterID:    c.Author.ID,
+			PosterName:  c.Author.Name,
+			PosterEmail: c.Author.EmailAddress,
+			Content:     c.Text,
+			Created:     bitbucketServerTime(c.CreatedDate),
+			Updated:     bitbucketServerTime(c.UpdatedDate),
+		})
+		for _, reply := range c.Comments {
+			appendComment(reply)
+		}
+	}
+
+	// the activities are listed newest first
+	for i := len(activities) - 1; i >= 0; i-- {
+		activity := activities[i]
+		if activity.Action != "COMMENTED" || activity.CommentAction != "ADDED" || activity.Comment == nil || activity.CommentAnchor != nil {
+			continue
+		}
+		appendComment(activity.Comment)
+	}
+
+	return allComments, nil
+}
+
+// GetReviews returns the approvals, the change requests and the code comments of a pull request
+func (d *BitbucketServerDownloader) GetReviews(pullRequestNumber int64) ([]*base.Review, error) {
+	activities, err := d.getActivities(pullRequestNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var allReviews = make([]*base.Review, 0, len(activities))
+	for i := len(activities) - 1; i >= 0; i-- {
+		activity := activities[i]
+		review := &base.Review{
+			ID:           activity.ID,
+			IssueIndex:   pullRequestNumber,
+			ReviewerID:   activity.User.ID,
+			ReviewerName: activity.User.Name,
+			CreatedAt:    bitbucketServerTime(activity.CreatedDate),
+		}
+
+		switch activity.Action {
+		case "APPROVED":
+			review.State = base.ReviewStateApproved
+		case "REVIEWED":
+			// the reviewer marked the pull request as needing work
+			review.State = base.ReviewStateChangesRequested
+		case "COMMENTED":
+			if activity.CommentAction != "ADDED" || activity.Comment == nil || activity.CommentAnchor == nil {
+				continue
+			}
+			anchor := activity.CommentAnchor
+			line := anchor.Line
+			if anchor.FileType == "FROM" {
+				// the comment is on a line of the original file
+				line = -line
+			}
+
+			review.State = base.ReviewStateCommented
+			review.CommitID = anchor.ToHash
+			var appendComment func(c *bitbucketServerComment, inReplyTo int64)
+			appendComment = func(c *bitbucketServerComment, inReplyTo int64) {
+				review.Comments = append(review.Comments, &base.ReviewComment{
+					ID:        c.ID,
+					InReplyTo: inReplyTo,
+					Content:   c.Text,
+					TreePath:  anchor.Path,
+					Line:      line,
+					CommitID:  anchor.ToHash,
+					PosterID:  c.Author.ID,
+					CreatedAt: bitbucketServerTime(c.CreatedDate),
+					UpdatedAt: bitbucketServerTime(c.UpdatedDate),
+				})
+				for _, reply := range c.Comments {
+					appendComment(reply, c.ID)
+				}
+			}
+			appendComment(activity.Comment, 0)
+		default:
+			continue
+		}
+
+		allReviews = append(allReviews, review)
+	}
+
+	return allReviews, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBitbucketServerURL(t *testing.T) {
+	kases := []struct {
+		URL        string
+		BaseURL    string
+		ProjectKey string
+		RepoSlug   string
+	}{
+		{"https://bitbucket.example.com/scm/test/test_repo.git", "https://bitbucket.example.com", "test", "test_repo"},
+		{"https://bitbucket.example.com/projects/TEST/repos/test_repo/browse", "https://bitbucket.example.com", "TEST", "test_repo"},
+		{"https://bitbucket.example.com/users/alice/repos/test_repo", "https://bitbucket.example.com", "~alice", "test_repo"},
+		{"https://example.com/bitbucket/scm/~alice/test_repo.git", "https://example.com/bitbucket", "~alice", "test_repo"},
+	}
+	for _, kase := range kases {
+		u, err := url.Parse(kase.URL)
+		assert.NoError(t, err)
+		baseURL, projectKey, repoSlug, err := parseBitbucketServerURL(u)
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.BaseURL, baseURL)
+		assert.EqualValues(t, kase.ProjectKey, projectKey)
+		assert.EqualValues(t, kase.RepoSlug, repoSlug)
+	}
+
+	u, err := url.Parse("https://bitbucket.example.com/dashboard")
+	assert.NoError(t, err)
+	_, _, _, err = parseBitbucketServerURL(u)
+	assert.Error(t, err)
+}
+
+func TestBitbucketServerDownloadRepo(t *testing.T) {
+	server := newFixtureServer("bitbucketserver")
+	defer server.Close()
+
+	downloader := NewBitbucketServerDownloader(context.Background(), server.URL, "", "", "TEST", "test_repo")
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.EqualValues(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "TEST",
+		IsPrivate:     true,
+		Description:   "Test repository for testing migration from Bitbucket Server to gitea",
+		CloneURL:      "https://bitbucket.example.com/scm/test/test_repo.git",
+		OriginalURL:   "https://bitbucket.example.com/projects/TEST/repos/test_repo/browse",
+		DefaultBranch: "master",
+	}, repo)
+
+	_, err = downloader.GetMilestones()
+	assert.True(t, base.IsErrNotSupported(err))
+	_, _, err = downloader.GetIssues(1, 50)
+	assert.True(t, base.IsErrNotSupported(err))
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	closed := time.Date(2021, 6, 15, 10, 0, 0, 0, time.UTC)
+	assert.EqualValues(t, []*base.PullRequest{
+		{
+			Number:         1,
+			Title:          "Add the changelog",
+			Content:        "keeps track of the changes",
+			PosterID:       101,
+			PosterName:     "alice",
+			PosterEmail:    "alice@example.com",
+			State:          "closed",
+			Created:        time.Date(2021, 6, 14, 10, 0, 0, 0, time.UTC),
+			Updated:        closed,
+			Closed:         &closed,
+			Merged:         true,
+			MergedTime:     &closed,
+			MergeCommitSHA: "5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f80",
+			Head: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.example.com/scm/test/test_repo.git",
+				Ref:       "changelog",
+				SHA:       "a1b2c3d4e5f60718293a4b5c6d7e8f9001122334",
+				RepoName:  "test_repo",
+				OwnerName: "TEST",
+			},
+			Base: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.example.com/scm/test/test_repo.git",
+				Ref:       "master",
+				SHA:       "f1e2d3c4b5a6978877665544332211ffeeddccbb",
+				RepoName:  "test_repo",
+				OwnerName: "TEST",
+			},
+		},
+		{
+			Number:      2,
+			Title:       "Fix the typo",
+			PosterID:    102,
+			PosterName:  "bob",
+			PosterEmail: "bob@example.com",
+			State:       "open",
+			Created:     time.Date(2021, 6, 16, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 16, 10, 0, 0, 0, time.UTC),
+			Head: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.example.com/scm/~bob/test_repo.git",
+				Ref:       "typo",
+				SHA:       "0a1b2c3d4e5f60718293a4b5c6d7e8f900112233",
+				RepoName:  "test_repo",
+				OwnerName: "~BOB",
+			},
+			Base: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.example.com/scm/test/test_repo.git",
+				Ref:       "master",
+				SHA:       "f1e2d3c4b5a6978877665544332211ffeeddccbb",
+				RepoName:  "test_repo",
+				OwnerName: "TEST",
+			},
+		},
+	}, prs)
+	assert.True(t, prs[1].IsForkPullRequest())
+
+	comments, err := downloader.GetComments(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterID:    102,
+			PosterName:  "bob",
+			PosterEmail: "bob@example.com",
+			Content:     "Nice, thanks!",
+			Created:     time.Date(2021, 6, 14, 11, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 14, 12, 0, 0, 0, time.UTC),
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Review{
+		{
+			ID:           14,
+			IssueIndex:   1,
+			ReviewerID:   102,
+			ReviewerName: "bob",
+			CommitID:     "a1b2c3d4e5f60718293a4b5c6d7e8f9001122334",
+			CreatedAt:    time.Date(2021, 6, 15, 8, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        22,
+					Content:   "Please mention the release date",
+					TreePath:  "CHANGELOG.md",
+					Line:      3,
+					CommitID:  "a1b2c3d4e5f60718293a4b5c6d7e8f9001122334",
+					PosterID:  102,
+					CreatedAt: time.Date(2021, 6, 15, 8, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2021, 6, 15, 8, 0, 0, 0, time.UTC),
+				},
+				{
+					ID:        23,
+					InReplyTo: 22,
+					Content:   "Done",
+					TreePath:  "CHANGELOG.md",
+					Line:      3,
+					CommitID:  "a1b2c3d4e5f60718293a4b5c6d7e8f9001122334",
+					PosterID:  101,
+					CreatedAt: time.Date(2021, 6, 15, 8, 30, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2021, 6, 15, 8, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			ID:           15,
+			IssueIndex:   1,
+			ReviewerID:   102,
+			ReviewerName: "bob",
+			CreatedAt:    time.Date(2021, 6, 15, 9, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateApproved,
+		},
+	}, reviews)
+}
@@ -412,8 +412,11 @@ func (g *RepositoryDumper) CreateComments(comments ...*base.Comment) error {
 // CreatePullRequests creates pull requests
 func (g *RepositoryDumper) CreatePullRequests(prs ...*base.PullRequest) error {
 	for _, pr := range prs {
-		// download patch file
+		// download patch file, not every downloader provides one
 		err := func() error {
+			if pr.PatchURL == "" {
+				return nil
+			}
 			u, err := g.setURLToken(pr.PatchURL)
 			if err != nil {
 				return err
@@ -554,7 +557,7 @@ func DumpRepository(ctx context.Context, baseDir, ownerName string, opts base.Mi
 		return err
 	}
 
-	if err := migrateRepository(downloader, uploader, opts); err != nil {
+	if err := migrateRepository(downloader, uploader, opts, nil); err != nil {
 		if err1 := uploader.Rollback(); err1 != nil {
 			log.Error("rollback failed: %v", err1)
 		}
@@ -569,32 +572,48 @@ func RestoreRepository(ctx context.Context, baseDir string, ownerName, repoName
 	if err != nil {
 		return err
 	}
-	var uploader = NewGiteaLocalUploader(ctx, doer, ownerName, repoName)
-	downloader, err := NewRepositoryRestorer(ctx, baseDir, ownerName, repoName)
+	_, err = RestoreDumpedRepository(ctx, doer, baseDir, ownerName, base.MigrateOptions{
+		RepoName: repoName,
+	}, nil)
+	return err
+}
+
+// RestoreDumpedRepository restores everything the dump in baseDir contains into a repository of ownerName,
+// opts.MigrateToRepoID references the repository when it has been created beforehand
+func RestoreDumpedRepository(ctx context.Context, doer *models.User, baseDir, ownerName string, opts base.MigrateOptions, messenger base.Messenger) (*models.Repository, error) {
+	var uploader = NewGiteaLocalUploader(ctx, doer, ownerName, opts.RepoName)
+	downloader, err := NewRepositoryRestorer(ctx, baseDir, ownerName, opts.RepoName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	opts, err := downloader.getRepoOptions()
+	repoOpts, err := downloader.getRepoOptions()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	tp, _ := strconv.Atoi(opts["service_type"])
-
-	if err = migrateRepository(downloader, uploader, base.MigrateOptions{
-		Wiki:           true,
-		Issues:         true,
-		Milestones:     true,
-		Labels:         true,
-		Releases:       true,
-		Comments:       true,
-		PullRequests:   true,
-		ReleaseAssets:  true,
-		GitServiceType: structs.GitServiceType(tp),
-	}); err != nil {
+	tp, _ := strconv.Atoi(repoOpts["service_type"])
+	uploader.gitServiceType = structs.GitServiceType(tp)
+
+	opts.Wiki = true
+	opts.Issues = true
+	opts.Milestones = true
+	opts.Labels = true
+	opts.Releases = true
+	opts.Comments = true
+	opts.PullRequests = true
+	opts.ReleaseAssets = true
+	opts.GitServiceType = structs.GitServiceType(tp)
+
+	if err = migrateRepository(downloader, uploader, opts, messenger); err != nil {
 		if err1 := uploader.Rollback(); err1 != nil {
 			log.Error("rollback failed: %v", err1)
 		}
-		return err
+		return nil, err
+	}
+	if err = downloader.restoreWiki(uploader.repo); err != nil {
+		return nil, err
+	}
+	if err = updateMigrationPosterIDByGitService(ctx, structs.GitServiceType(tp)); err != nil {
+		return nil, err
 	}
-	return updateMigrationPosterIDByGitService(ctx, structs.GitServiceType(tp))
+	return uploader.repo, nil
 }
@@ -0,0 +1,149 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// RepositoryExportPath returns the path of the latest export archive of a repository
+func RepositoryExportPath(repo *models.Repository) string {
+	return filepath.Join(models.RepoExportPath(repo.ID), "export.zip")
+}
+
+// ExportRepository dumps everything of a repository: its git data, wiki, issues, pull requests, reviews, releases,
+// labels and milestones, and archives the dump to RepositoryExportPath so it can be restored from it
+func ExportRepository(ctx context.Context, repo *models.Repository, messenger base.Messenger) error {
+	if messenger == nil {
+		messenger = base.NilMessenger
+	}
+	if err := repo.GetOwner(); err != nil {
+		return err
+	}
+
+	tmpDir, err := models.CreateTemporaryPath("export")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpDir); err != nil {
+			log.Error("Unable to remove temporary directory: %s: Error: %v", tmpDir, err)
+		}
+	}()
+
+	opts := base.MigrateOptions{
+		CloneAddr:      repo.CloneLink().HTTPS,
+		RepoName:       repo.Name,
+		Description:    repo.Description,
+		Private:        repo.IsPrivate,
+		GitServiceType: structs.GiteaService,
+		Wiki:           repo.HasWiki(),
+		Issues:         true,
+		Milestones:     true,
+		Labels:         true,
+		Releases:       true,
+		Comments:       true,
+		PullRequests:   true,
+		ReleaseAssets:  true,
+	}
+
+	downloader := NewGiteaLocalDownloader(ctx, repo)
+	uploader, err := NewRepositoryDumper(ctx, tmpDir, repo.OwnerName, repo.Name, opts)
+	if err != nil {
+		return err
+	}
+	if err := migrateRepository(downloader, uploader, opts, messenger); err != nil {
+		if err1 := uploader.Rollback(); err1 != nil {
+			log.Error("rollback failed: %v", err1)
+		}
+		return err
+	}
+
+	messenger("repo.settings.export.archiving")
+
+	exportPath := RepositoryExportPath(repo)
+	if err := os.MkdirAll(filepath.Dir(exportPath), os.ModePerm); err != nil {
+		return err
+	}
+	// the previous export stays available until the new one is complete
+	f, err := ioutil.TempFile(filepath.Dir(exportPath), "export-*.zip.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer func() {
+		if err := util.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+			log.Error("Unable to remove %s: %v", tmpPath, err)
+		}
+	}()
+
+	err = writeDumpZip(f, tmpDir)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, exportPath)
+}
+
+// writeDumpZip writes the files of the directory dir to w as a zip archive
+func writeDumpZip(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			hdr.Name += "/"
+			_, err = zw.CreateHeader(hdr)
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		hdr.Method = zip.Deflate
+
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		fr, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer fr.Close()
+		_, err = io.Copy(fw, fr)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiteaLocalDownloader(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	downloader := NewGiteaLocalDownloader(context.Background(), repo)
+
+	info, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "repo1", info.Name)
+	assert.Equal(t, "user2", info.Owner)
+	assert.Equal(t, repo.RepoPath(), info.CloneURL)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assert.Len(t, labels, 2)
+
+	issues, isEnd, err := downloader.GetIssues(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.NotEmpty(t, issues)
+	assert.EqualValues(t, 1, issues[0].Number)
+	assert.Equal(t, "user1", issues[0].PosterName)
+
+	comments, err := downloader.GetComments(1)
+	assert.NoError(t, err)
+	for _, comment := range comments {
+		assert.EqualValues(t, 1, comment.IssueIndex)
+	}
+
+	prs, _, err := downloader.GetPullRequests(1, 50)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, prs)
+	for _, pr := range prs {
+		assert.NotEmpty(t, pr.Head.SHA)
+		assert.False(t, pr.IsForkPullRequest())
+	}
+}
+
+func TestExportRepository(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	dumpPath, err := ioutil.TempDir("", "export-dumps")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dumpPath)
+	oldDumpPath := setting.Migrations.DumpPath
+	setting.Migrations.DumpPath = dumpPath
+	defer func() {
+		setting.Migrations.DumpPath = oldDumpPath
+	}()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	var messages []string
+	assert.NoError(t, ExportRepository(context.Background(), repo, func(key string, args ...interface{}) {
+		messages = append(messages, key)
+	}))
+	assert.Contains(t, messages, "repo.migrate.migrating_pulls")
+	assert.Contains(t, messages, "repo.settings.export.archiving")
+	assert.FileExists(t, RepositoryExportPath(repo))
+
+	dir, err := ioutil.TempDir("", "export-restore")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	baseDir, err := ExtractRepositoryDump(RepositoryExportPath(repo), dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "user2", "repo1"), baseDir)
+	for _, name := range []string{"repo.yml", "label.yml", "milestone.yml", "release.yml", "issue.yml", "pull_request.yml", "git/HEAD", "wiki/HEAD"} {
+		assert.FileExists(t, filepath.Join(baseDir, name))
+	}
+
+	// restored pull requests are pushed to the pull request check queue which isn't running here
+	assert.NoError(t, util.Remove(filepath.Join(baseDir, "pull_request.yml")))
+
+	restored, err := RestoreDumpedRepository(context.Background(), user, baseDir, user.Name, base.MigrateOptions{
+		RepoName: "repo1-restored",
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, restored.HasWiki())
+
+	labels, err := models.GetLabelsByRepoID(restored.ID, "", models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, labels, 2)
+
+	restored = models.AssertExistsAndLoadBean(t, &models.Repository{ID: restored.ID}).(*models.Repository)
+	assert.Equal(t, repo.NumIssues, restored.NumIssues)
+}
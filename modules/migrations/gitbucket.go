@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &GitBucketDownloader{}
+	_ base.DownloaderFactory = &GitBucketDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&GitBucketDownloaderFactory{})
+}
+
+// GitBucketDownloaderFactory defines a GitBucket downloader factory
+type GitBucketDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *GitBucketDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// GitBucket might be served from a sub path and its clone URLs are prefixed with /git
+	fields := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid path: %s", u.Path)
+	}
+	oldOwner := fields[len(fields)-2]
+	oldName := fields[len(fields)-1]
+	subPath := fields[:len(fields)-2]
+	if len(subPath) > 0 && subPath[len(subPath)-1] == "git" {
+		subPath = subPath[:len(subPath)-1]
+	}
+
+	baseURL := u.Scheme + "://" + u.Host
+	if len(subPath) > 0 {
+		baseURL += "/" + strings.Join(subPath, "/")
+	}
+
+	log.Trace("Create GitBucket downloader. BaseURL: %s RepoOwner: %s RepoName: %s", baseURL, oldOwner, oldName)
+	return NewGitBucketDownloader(ctx, baseURL, opts.AuthUsername, opts.AuthPassword, opts.AuthToken, oldOwner, oldName), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *GitBucketDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.GitBucketService
+}
+
+// GitBucketDownloader implements a Downloader interface to get repository informations
+// from GitBucket via its GitHub v3 compatible API
+type GitBucketDownloader struct {
+	*GithubDownloaderV3
+}
+
+// NewGitBucketDownloader creates a GitBucket downloader
+func NewGitBucketDownloader(ctx context.Context, baseURL, userName, password, token, repoOwner, repoName string) *GitBucketDownloader {
+	// the GitHub enterprise client appends the api/v3 path GitBucket serves its API from
+	githubDownloader := NewGithubDownloaderV3(ctx, strings.TrimSuffix(baseURL, "/")+"/", userName, password, token, repoOwner, repoName)
+	githubDownloader.SkipReactions = true
+	githubDownloader.SkipReviews = true
+	return &GitBucketDownloader{
+		githubDownloader,
+	}
+}
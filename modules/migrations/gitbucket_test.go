@@ -0,0 +1,146 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitBucketDownloaderFactory(t *testing.T) {
+	kases := []struct {
+		CloneAddr string
+		BaseURL   string
+	}{
+		{"https://gitbucket.example.com/root/test_repo", "https://gitbucket.example.com/"},
+		{"https://gitbucket.example.com/git/root/test_repo.git", "https://gitbucket.example.com/"},
+		{"https://example.com/gitbucket/git/root/test_repo.git", "https://example.com/gitbucket/"},
+	}
+	for _, kase := range kases {
+		downloader, err := new(GitBucketDownloaderFactory).New(context.Background(), base.MigrateOptions{CloneAddr: kase.CloneAddr})
+		assert.NoError(t, err)
+		g := downloader.(*GitBucketDownloader)
+		assert.EqualValues(t, kase.BaseURL+"api/v3/", g.client.BaseURL.String())
+		assert.EqualValues(t, "root", g.repoOwner)
+		assert.EqualValues(t, "test_repo", g.repoName)
+	}
+}
+
+func TestGitBucketDownloadRepo(t *testing.T) {
+	server := newFixtureServer("gitbucket")
+	defer server.Close()
+
+	downloader := NewGitBucketDownloader(context.Background(), server.URL, "", "", "", "root", "test_repo")
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.EqualValues(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "root",
+		Description:   "Test repository for testing migration from GitBucket to gitea",
+		CloneURL:      "http://gitbucket.example.com/git/root/test_repo.git",
+		OriginalURL:   "http://gitbucket.example.com/root/test_repo",
+		DefaultBranch: "master",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	assert.Len(t, milestones, 1)
+	assertMilestoneEqual(t, "first release", "1.0.0", "2021-09-30 00:00:00 +0000 UTC", "2021-06-02 10:00:00 +0000 UTC", "2021-06-02 10:00:00 +0000 UTC", "", "open", milestones[0])
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assert.Len(t, labels, 2)
+	assertLabelEqual(t, "bug", "fc2929", "", labels[0])
+	assertLabelEqual(t, "enhancement", "84b6eb", "", labels[1])
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Release{
+		{
+			TagName:         "v1.0.0",
+			TargetCommitish: "master",
+			Name:            "First release",
+			Body:            "the first release",
+			PublisherName:   "root",
+			PublisherEmail:  "root@localhost",
+		},
+	}, releases)
+
+	// the pull requests listed along the issues are skipped
+	issues, isEnd, err := downloader.GetIssues(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.EqualValues(t, []*base.Issue{
+		{
+			Number:      1,
+			Title:       "Crash on startup",
+			Content:     "it crashes",
+			PosterName:  "alice",
+			PosterEmail: "alice@example.com",
+			Milestone:   "1.0.0",
+			State:       "open",
+			Created:     time.Date(2021, 6, 3, 8, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 3, 9, 0, 0, 0, time.UTC),
+			Labels: []*base.Label{
+				{
+					Name:  "bug",
+					Color: "fc2929",
+				},
+			},
+		},
+	}, issues)
+
+	comments, err := downloader.GetComments(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterName:  "root",
+			PosterEmail: "root@localhost",
+			Content:     "confirmed",
+			Created:     time.Date(2021, 6, 3, 8, 30, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 3, 8, 30, 0, 0, time.UTC),
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.EqualValues(t, []*base.PullRequest{
+		{
+			Number:      2,
+			Title:       "Fix the crash",
+			PosterName:  "alice",
+			PosterEmail: "alice@example.com",
+			State:       "open",
+			Created:     time.Date(2021, 6, 3, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 3, 10, 0, 0, 0, time.UTC),
+			Labels:      []*base.Label{},
+			Head: base.PullRequestBranch{
+				CloneURL:  "http://gitbucket.example.com/git/root/test_repo.git",
+				Ref:       "fix-crash",
+				SHA:       "3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e",
+				RepoName:  "test_repo",
+				OwnerName: "root",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "master",
+				SHA:       "8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3",
+				RepoName:  "test_repo",
+				OwnerName: "root",
+			},
+		},
+	}, prs)
+
+	// GitBucket has no reviews API
+	reviews, err := downloader.GetReviews(2)
+	assert.NoError(t, err)
+	assert.Empty(t, reviews)
+}
@@ -0,0 +1,525 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var (
+	_ base.Downloader = &GiteaLocalDownloader{}
+)
+
+// GiteaLocalDownloader implements a Downloader interface to get repository informations
+// from a repository of this instance, it's used to export repositories
+type GiteaLocalDownloader struct {
+	ctx  context.Context
+	repo *models.Repository
+}
+
+// NewGiteaLocalDownloader creates a downloader of a local repository
+func NewGiteaLocalDownloader(ctx context.Context, repo *models.Repository) *GiteaLocalDownloader {
+	return &GiteaLocalDownloader{
+		ctx:  ctx,
+		repo: repo,
+	}
+}
+
+// SetContext set context
+func (g *GiteaLocalDownloader) SetContext(ctx context.Context) {
+	g.ctx = ctx
+}
+
+// GetRepoInfo returns a repository information
+func (g *GiteaLocalDownloader) GetRepoInfo() (*base.Repository, error) {
+	if err := g.repo.GetOwner(); err != nil {
+		return nil, err
+	}
+
+	originalURL := g.repo.OriginalURL
+	if originalURL == "" {
+		originalURL = g.repo.HTMLURL()
+	}
+
+	return &base.Repository{
+		Name:          g.repo.Name,
+		Owner:         g.repo.OwnerName,
+		IsPrivate:     g.repo.IsPrivate,
+		Description:   g.repo.Description,
+		CloneURL:      g.repo.RepoPath(),
+		OriginalURL:   originalURL,
+		DefaultBranch: g.repo.DefaultBranch,
+	}, nil
+}
+
+// GetTopics return repository topics
+func (g *GiteaLocalDownloader) GetTopics() ([]string, error) {
+	topics, err := models.FindTopics(&models.FindTopicOptions{
+		RepoID: g.repo.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ret = make([]string, 0, len(topics))
+	for _, topic := range topics {
+		ret = append(ret, topic.Name)
+	}
+	return ret, nil
+}
+
+// GetMilestones returns milestones
+func (g *GiteaLocalDownloader) GetMilestones() ([]*base.Milestone, error) {
+	milestones, err := models.GetMilestones(models.GetMilestonesOption{
+		RepoID: g.repo.ID,
+		State:  api.StateAll,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ret = make([]*base.Milestone, 0, len(milestones))
+	for _, milestone := range milestones {
+		var deadline, closed *time.Time
+		if milestone.DeadlineUnix.Year() != 9999 {
+			deadline = milestone.DeadlineUnix.AsTimePtr()
+		}
+		state := "open"
+		if milestone.IsClosed {
+			state = "closed"
+			closed = milestone.ClosedDateUnix.AsTimePtr()
+		}
+
+		ret = append(ret, &base.Milestone{
+			Title:       milestone.Name,
+			Description: milestone.Content,
+			Deadline:    deadline,
+			Created:     milestone.CreatedUnix.AsTime(),
+			Updated:     milestone.UpdatedUnix.AsTimePtr(),
+			Closed:      closed,
+			State:       state,
+		})
+	}
+	return ret, nil
+}
+
+// GetLabels returns labels
+func (g *GiteaLocalDownloader) GetLabels() ([]*base.Label, error) {
+	labels, err := models.GetLabelsByRepoID(g.repo.ID, "", models.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return convertLocalLabels(labels), nil
+}
+
+func convertLocalLabels(labels []*models.Label) []*base.Label {
+	var ret = make([]*base.Label, 0, len(labels))
+	for _, label := range labels {
+		ret = append(ret, &base.Label{
+			Name:        label.Name,
+			Color:       strings.TrimPrefix(label.Color, "#"),
+			Description: label.Description,
+		})
+	}
+	return ret
+}
+
+// GetReleases returns releases
+func (g *GiteaLocalDownloader) GetReleases() ([]*base.Release, error) {
+	releases, err := models.GetReleasesByRepoID(g.repo.ID, models.FindReleasesOptions{
+		IncludeDrafts: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := models.GetReleaseAttachments(releases...); err != nil {
+		return nil, err
+	}
+
+	var ret = make([]*base.Release, 0, len(releases))
+	for _, release := range releases {
+		if err := release.LoadAttributes(); err != nil {
+			return nil, err
+		}
+		publisherID, publisherName, publisherEmail := localPoster(release.Publisher, release.OriginalAuthorID, release.OriginalAuthor)
+
+		r := &base.Release{
+			TagName:         release.TagName,
+			TargetCommitish: release.Target,
+			Name:            release.Title,
+			Body:            release.Note,
+			Draft:           release.IsDraft,
+			Prerelease:      release.IsPrerelease,
+			PublisherID:     publisherID,
+			PublisherName:   publisherName,
+			PublisherEmail:  publisherEmail,
+			Created:         release.CreatedUnix.AsTime(),
+			Published:       release.CreatedUnix.AsTime(),
+		}
+
+		for _, attachment := range release.Attachments {
+			attachment := attachment
+			// an asset whose file is lost must not prevent the export of everything else
+			if _, err := storage.Attachments.Stat(attachment.RelativePath()); err != nil {
+				log.Warn("Unable to export asset %s of release %s in %s: %v", attachment.Name, release.TagName, g.repo.FullName(), err)
+				continue
+			}
+			size := int(attachment.Size)
+			downloadCount := int(attachment.DownloadCount)
+			r.Assets = append(r.Assets, &base.ReleaseAsset{
+				ID:            attachment.ID,
+				Name:          attachment.Name,
+				Size:          &size,
+				DownloadCount: &downloadCount,
+				Created:       attachment.CreatedUnix.AsTime(),
+				Updated:       attachment.CreatedUnix.AsTime(),
+				DownloadFunc: func() (io.ReadCloser, error) {
+					return storage.Attachments.Open(attachment.RelativePath())
+				},
+			})
+		}
+
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// localPoster returns the id, name and email the original poster of something was known by
+func localPoster(poster *models.User, originalAuthorID int64, originalAuthor string) (int64, string, string) {
+	if originalAuthor != "" {
+		return originalAuthorID, originalAuthor, ""
+	}
+	if poster == nil {
+		poster = models.NewGhostUser()
+	}
+	return poster.ID, poster.Name, poster.GetEmail()
+}
+
+func (g *GiteaLocalDownloader) getReactions(reactions models.ReactionList) ([]*base.Reaction, error) {
+	if _, err := reactions.LoadUsers(g.repo); err != nil {
+		return nil, err
+	}
+
+	var ret = make([]*base.Reaction, 0, len(reactions))
+	for _, reaction := range reactions {
+		userID, userName, _ := localPoster(reaction.User, reaction.OriginalAuthorID, reaction.OriginalAuthor)
+		ret = append(ret, &base.Reaction{
+			UserID:   userID,
+			UserName: userName,
+			Content:  reaction.Type,
+		})
+	}
+	return ret, nil
+}
+
+func (g *GiteaLocalDownloader) getIssues(page, perPage int, isPull bool) ([]*models.Issue, bool, error) {
+	issues, err := models.Issues(&models.IssuesOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: perPage,
+		},
+		RepoIDs:  []int64{g.repo.ID},
+		IsPull:   util.OptionalBoolOf(isPull),
+		SortType: "oldest",
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return issues, len(issues) < perPage, nil
+}
+
+func (g *GiteaLocalDownloader) getIssueAttributes(issue *models.Issue) (labels []*base.Label, milestone string, assignees []string, reactions []*base.Reaction, err error) {
+	labels = convertLocalLabels(issue.Labels)
+	if issue.Milestone != nil {
+		milestone = issue.Milestone.Name
+	}
+	for _, assignee := range issue.Assignees {
+		assignees = append(assignees, assignee.Name)
+	}
+
+	issueReactions, err := models.FindIssueReactions(issue, models.ListOptions{})
+	if err != nil {
+		return
+	}
+	reactions, err = g.getReactions(issueReactions)
+	return
+}
+
+func localIssueState(issue *models.Issue) (string, *time.Time) {
+	if issue.IsClosed {
+		return "closed", issue.ClosedUnix.AsTimePtr()
+	}
+	return "open", nil
+}
+
+// GetIssues returns issues according start and limit
+func (g *GiteaLocalDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	issues, isEnd, err := g.getIssues(page, perPage, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ret = make([]*base.Issue, 0, len(issues))
+	for _, issue := range issues {
+		labels, milestone, assignees, reactions, err := g.getIssueAttributes(issue)
+		if err != nil {
+			return nil, false, err
+		}
+		posterID, posterName, posterEmail := localPoster(issue.Poster, issue.OriginalAuthorID, issue.OriginalAuthor)
+		state, closed := localIssueState(issue)
+
+		ret = append(ret, &base.Issue{
+			Number:      issue.Index,
+			PosterID:    posterID,
+			PosterName:  posterName,
+			PosterEmail: posterEmail,
+			Title:       issue.Title,
+			Content:     issue.Content,
+			Ref:         issue.Ref,
+			Milestone:   milestone,
+			State:       state,
+			IsLocked:    issue.IsLocked,
+			Created:     issue.CreatedUnix.AsTime(),
+			Updated:     issue.UpdatedUnix.AsTime(),
+			Closed:      closed,
+			Labels:      labels,
+			Reactions:   reactions,
+			Assignees:   assignees,
+		})
+	}
+	return ret, isEnd, nil
+}
+
+// GetComments returns comments according issueNumber
+func (g *GiteaLocalDownloader) GetComments(issueNumber int64) ([]*base.Comment, error) {
+	issue, err := models.GetIssueByIndex(g.repo.ID, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		IssueID: issue.ID,
+		Type:    models.CommentTypeComment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := models.CommentList(comments).LoadPosters(); err != nil {
+		return nil, err
+	}
+
+	var ret = make([]*base.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if err := comment.LoadReactions(g.repo); err != nil {
+			return nil, err
+		}
+		reactions, err := g.getReactions(comment.Reactions)
+		if err != nil {
+			return nil, err
+		}
+		posterID, posterName, posterEmail := localPoster(comment.Poster, comment.OriginalAuthorID, comment.OriginalAuthor)
+
+		ret = append(ret, &base.Comment{
+			IssueIndex:  issueNumber,
+			PosterID:    posterID,
+			PosterName:  posterName,
+			PosterEmail: posterEmail,
+			Created:     comment.CreatedUnix.AsTime(),
+			Updated:     comment.UpdatedUnix.AsTime(),
+			Content:     comment.Content,
+			Reactions:   reactions,
+		})
+	}
+	return ret, nil
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (g *GiteaLocalDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	issues, isEnd, err := g.getIssues(page, perPage, true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	gitRepo, err := git.OpenRepository(g.repo.RepoPath())
+	if err != nil {
+		return nil, false, err
+	}
+	defer gitRepo.Close()
+
+	var ret = make([]*base.PullRequest, 0, len(issues))
+	for _, issue := range issues {
+		pr := issue.PullRequest
+		if pr == nil {
+			return nil, false, fmt.Errorf("pull request of issue %d is missing", issue.ID)
+		}
+		pr.Issue = issue
+		if err := pr.LoadHeadRepo(); err != nil {
+			return nil, false, err
+		}
+
+		labels, milestone, assignees, reactions, err := g.getIssueAttributes(issue)
+		if err != nil {
+			return nil, false, err
+		}
+		posterID, posterName, posterEmail := localPoster(issue.Poster, issue.OriginalAuthorID, issue.OriginalAuthor)
+		state, closed := localIssueState(issue)
+
+		headSHA, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			return nil, false, fmt.Errorf("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+		}
+
+		// a deleted head repository leaves its branch in the base repository
+		head := base.PullRequestBranch{
+			CloneURL:  g.repo.RepoPath(),
+			Ref:       pr.HeadBranch,
+			SHA:       headSHA,
+			RepoName:  g.repo.Name,
+			OwnerName: g.repo.OwnerName,
+		}
+		if pr.HeadRepo != nil {
+			head.CloneURL = pr.HeadRepo.RepoPath()
+			head.RepoName = pr.HeadRepo.Name
+			head.OwnerName = pr.HeadRepo.OwnerName
+		}
+
+		var mergedTime *time.Time
+		if pr.HasMerged {
+			mergedTime = pr.MergedUnix.AsTimePtr()
+		}
+
+		ret = append(ret, &base.PullRequest{
+			Number:         issue.Index,
+			Title:          issue.Title,
+			PosterName:     posterName,
+			PosterID:       posterID,
+			PosterEmail:    posterEmail,
+			Content:        issue.Content,
+			Milestone:      milestone,
+			State:          state,
+			Created:        issue.CreatedUnix.AsTime(),
+			Updated:        issue.UpdatedUnix.AsTime(),
+			Closed:         closed,
+			Labels:         labels,
+			Merged:         pr.HasMerged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: pr.MergedCommitID,
+			Head:           head,
+			Base: base.PullRequestBranch{
+				CloneURL:  g.repo.RepoPath(),
+				Ref:       pr.BaseBranch,
+				SHA:       pr.MergeBase,
+				RepoName:  g.repo.Name,
+				OwnerName: g.repo.OwnerName,
+			},
+			Assignees: assignees,
+			IsLocked:  issue.IsLocked,
+			Reactions: reactions,
+		})
+	}
+	return ret, isEnd, nil
+}
+
+func convertLocalReviewState(tp models.ReviewType) string {
+	switch tp {
+	case models.ReviewTypeApprove:
+		return base.ReviewStateApproved
+	case models.ReviewTypeReject:
+		return base.ReviewStateChangesRequested
+	case models.ReviewTypeComment:
+		return base.ReviewStateCommented
+	default:
+		return base.ReviewStatePending
+	}
+}
+
+// GetReviews returns pull requests review
+func (g *GiteaLocalDownloader) GetReviews(pullRequestNumber int64) ([]*base.Review, error) {
+	issue, err := models.GetIssueByIndex(g.repo.ID, pullRequestNumber)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := models.FindReviews(models.FindReviewOptions{
+		IssueID: issue.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ret = make([]*base.Review, 0, len(reviews))
+	for _, review := range reviews {
+		// pending reviews are drafts and review requests have no content
+		if review.Type != models.ReviewTypeApprove && review.Type != models.ReviewTypeReject && review.Type != models.ReviewTypeComment {
+			continue
+		}
+		if err := review.LoadReviewer(); err != nil && !models.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		if err := review.LoadCodeComments(); err != nil {
+			return nil, err
+		}
+		reviewerID, reviewerName, _ := localPoster(review.Reviewer, review.OriginalAuthorID, review.OriginalAuthor)
+
+		r := &base.Review{
+			ID:           review.ID,
+			IssueIndex:   pullRequestNumber,
+			ReviewerID:   reviewerID,
+			ReviewerName: reviewerName,
+			Official:     review.Official,
+			CommitID:     review.CommitID,
+			Content:      review.Content,
+			CreatedAt:    review.CreatedUnix.AsTime(),
+			State:        convertLocalReviewState(review.Type),
+		}
+
+		for _, lines := range review.CodeComments {
+			for _, comments := range lines {
+				for _, comment := range comments {
+					if err := comment.LoadReactions(g.repo); err != nil {
+						return nil, err
+					}
+					reactions, err := g.getReactions(comment.Reactions)
+					if err != nil {
+						return nil, err
+					}
+					posterID, _, _ := localPoster(comment.Poster, comment.OriginalAuthorID, comment.OriginalAuthor)
+
+					r.Comments = append(r.Comments, &base.ReviewComment{
+						ID:        comment.ID,
+						Content:   comment.Content,
+						TreePath:  comment.TreePath,
+						DiffHunk:  comment.Patch,
+						Line:      int(comment.Line),
+						CommitID:  comment.CommitSHA,
+						PosterID:  posterID,
+						Reactions: reactions,
+						CreatedAt: comment.CreatedUnix.AsTime(),
+						UpdatedAt: comment.UpdatedUnix.AsTime(),
+					})
+				}
+			}
+		}
+
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// FormatCloneURL returns the path of the repository, it is read from the disk
+func (g *GiteaLocalDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	return remoteAddr, nil
+}
@@ -340,6 +340,10 @@ func (g *GiteaLocalUploader) CreateIssues(issues ...*base.Issue) error {
 			CreatedUnix: timeutil.TimeStamp(issue.Created.Unix()),
 			UpdatedUnix: timeutil.TimeStamp(issue.Updated.Unix()),
 		}
+		is.ForeignIndex = issue.Number
+		if issue.OriginalNumber > 0 {
+			is.ForeignIndex = issue.OriginalNumber
+		}
 
 		userid, ok := g.userMap[issue.PosterID]
 		tp := g.gitServiceType.Name()
@@ -547,8 +551,11 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 		}
 	}
 
-	// download patch file
+	// download patch file, not every service provides one
 	err := func() error {
+		if pr.PatchURL == "" {
+			return nil
+		}
 		// pr.PatchURL maybe a local file
 		ret, err := uri.Open(pr.PatchURL)
 		if err != nil {
@@ -572,17 +579,7 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 	}
 
 	// set head information
-	pullHead := filepath.Join(g.repo.RepoPath(), "refs", "pull", fmt.Sprintf("%d", pr.Number))
-	if err := os.MkdirAll(pullHead, os.ModePerm); err != nil {
-		return nil, err
-	}
-	p, err := os.Create(filepath.Join(pullHead, "head"))
-	if err != nil {
-		return nil, err
-	}
-	_, err = p.WriteString(pr.Head.SHA)
-	p.Close()
-	if err != nil {
+	if err := g.writePullRequestHead(pr.Number, pr.Head.SHA); err != nil {
 		return nil, err
 	}
 
@@ -642,6 +639,10 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 		CreatedUnix: timeutil.TimeStamp(pr.Created.Unix()),
 		UpdatedUnix: timeutil.TimeStamp(pr.Updated.Unix()),
 	}
+	issue.ForeignIndex = pr.Number
+	if pr.OriginalNumber > 0 {
+		issue.ForeignIndex = pr.OriginalNumber
+	}
 
 	tp := g.gitServiceType.Name()
 
@@ -718,6 +719,21 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 	return &pullRequest, nil
 }
 
+// writePullRequestHead points the head reference of the pull request with the given index to sha
+func (g *GiteaLocalUploader) writePullRequestHead(index int64, sha string) error {
+	pullHead := filepath.Join(g.repo.RepoPath(), "refs", "pull", fmt.Sprintf("%d", index))
+	if err := os.MkdirAll(pullHead, os.ModePerm); err != nil {
+		return err
+	}
+	p, err := os.Create(filepath.Join(pullHead, "head"))
+	if err != nil {
+		return err
+	}
+	_, err = p.WriteString(sha)
+	p.Close()
+	return err
+}
+
 func convertReviewState(state string) models.ReviewType {
 	switch state {
 	case base.ReviewStatePending:
@@ -72,6 +72,11 @@ type GithubDownloaderV3 struct {
 	password   string
 	rate       *github.Rate
 	maxPerPage int
+
+	// SkipReactions and SkipReviews disable the migration of the entities
+	// GitHub API compatible services like GitBucket don't provide
+	SkipReactions bool
+	SkipReviews   bool
 }
 
 // NewGithubDownloaderV3 creates a github Downloader via github v3 API
@@ -128,6 +133,16 @@ func (g *GithubDownloaderV3) sleep() {
 	}
 }
 
+// setRate keeps the rate limit reported by a response, services which don't limit
+// the rate of their API don't report it and are never waited for
+func (g *GithubDownloaderV3) setRate(rate *github.Rate) {
+	if rate.Limit == 0 {
+		g.rate = nil
+		return
+	}
+	g.rate = rate
+}
+
 // RefreshRate update the current rate (doesn't count in rate limit)
 func (g *GithubDownloaderV3) RefreshRate() error {
 	rates, _, err := g.client.RateLimits(g.ctx)
@@ -146,7 +161,7 @@ func (g *GithubDownloaderV3) GetRepoInfo() (*base.Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	g.rate = &resp.Rate
+	g.setRate(&resp.Rate)
 
 	defaultBranch := ""
 	if gr.DefaultBranch != nil {
@@ -172,7 +187,7 @@ func (g *GithubDownloaderV3) GetTopics() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	g.rate = &resp.Rate
+	g.setRate(&resp.Rate)
 	return r.Topics, nil
 }
 
@@ -192,7 +207,7 @@ func (g *GithubDownloaderV3) GetMilestones() ([]*base.Milestone, error) {
 		if err != nil {
 			return nil, err
 		}
-		g.rate = &resp.Rate
+		g.setRate(&resp.Rate)
 
 		for _, m := range ms {
 			var desc string
@@ -208,7 +223,7 @@ func (g *GithubDownloaderV3) GetMilestones() ([]*base.Milestone, error) {
 				Description: desc,
 				Deadline:    m.DueOn,
 				State:       state,
-				Created:     *m.CreatedAt,
+				Created:     m.GetCreatedAt(),
 				Updated:     m.UpdatedAt,
 				Closed:      m.ClosedAt,
 			})
@@ -246,7 +261,7 @@ func (g *GithubDownloaderV3) GetLabels() ([]*base.Label, error) {
 		if err != nil {
 			return nil, err
 		}
-		g.rate = &resp.Rate
+		g.setRate(&resp.Rate)
 
 		for _, label := range ls {
 			labels = append(labels, convertGithubLabel(label))
@@ -280,13 +295,13 @@ func (g *GithubDownloaderV3) convertGithubRelease(rel *github.RepositoryRelease)
 		TargetCommitish: *rel.TargetCommitish,
 		Name:            name,
 		Body:            desc,
-		Draft:           *rel.Draft,
-		Prerelease:      *rel.Prerelease,
-		Created:         rel.CreatedAt.Time,
+		Draft:           rel.GetDraft(),
+		Prerelease:      rel.GetPrerelease(),
+		Created:         rel.GetCreatedAt().Time,
 		PublisherID:     *rel.Author.ID,
 		PublisherName:   *rel.Author.Login,
 		PublisherEmail:  email,
-		Published:       rel.PublishedAt.Time,
+		Published:       rel.GetPublishedAt().Time,
 	}
 
 	for _, asset := range rel.Assets {
@@ -349,7 +364,7 @@ func (g *GithubDownloaderV3) GetReleases() ([]*base.Release, error) {
 		if err != nil {
 			return nil, err
 		}
-		g.rate = &resp.Rate
+		g.setRate(&resp.Rate)
 
 		for _, release := range ls {
 			releases = append(releases, g.convertGithubRelease(release))
@@ -383,7 +398,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 		return nil, false, fmt.Errorf("error while listing repos: %v", err)
 	}
 	log.Trace("Request get issues %d/%d, but in fact get %d", perPage, page, len(issues))
-	g.rate = &resp.Rate
+	g.setRate(&resp.Rate)
 	for _, issue := range issues {
 		if issue.IsPullRequest() {
 			continue
@@ -408,7 +423,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.sleep()
 			res, resp, err := g.client.Reactions.ListIssueReactions(g.ctx, g.repoOwner, g.repoName, issue.GetNumber(), &github.ListOptions{
 				Page:    i,
@@ -417,7 +432,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 			if err != nil {
 				return nil, false, err
 			}
-			g.rate = &resp.Rate
+			g.setRate(&resp.Rate)
 			if len(res) == 0 {
 				break
 			}
@@ -444,7 +459,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 			Labels:      labels,
 			Reactions:   reactions,
 			Closed:      issue.ClosedAt,
-			IsLocked:    *issue.Locked,
+			IsLocked:    issue.GetLocked(),
 		})
 	}
 
@@ -471,7 +486,7 @@ func (g *GithubDownloaderV3) GetComments(issueNumber int64) ([]*base.Comment, er
 		if err != nil {
 			return nil, fmt.Errorf("error while listing repos: %v", err)
 		}
-		g.rate = &resp.Rate
+		g.setRate(&resp.Rate)
 		for _, comment := range comments {
 			var email string
 			if comment.User.Email != nil {
@@ -480,7 +495,7 @@ func (g *GithubDownloaderV3) GetComments(issueNumber int64) ([]*base.Comment, er
 
 			// get reactions
 			var reactions []*base.Reaction
-			for i := 1; ; i++ {
+			for i := 1; !g.SkipReactions; i++ {
 				g.sleep()
 				res, resp, err := g.client.Reactions.ListIssueCommentReactions(g.ctx, g.repoOwner, g.repoName, comment.GetID(), &github.ListOptions{
 					Page:    i,
@@ -489,7 +504,7 @@ func (g *GithubDownloaderV3) GetComments(issueNumber int64) ([]*base.Comment, er
 				if err != nil {
 					return nil, err
 				}
-				g.rate = &resp.Rate
+				g.setRate(&resp.Rate)
 				if len(res) == 0 {
 					break
 				}
@@ -540,7 +555,7 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 	if err != nil {
 		return nil, false, fmt.Errorf("error while listing repos: %v", err)
 	}
-	g.rate = &resp.Rate
+	g.setRate(&resp.Rate)
 	for _, pr := range prs {
 		var body string
 		if pr.Body != nil {
@@ -597,7 +612,7 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.sleep()
 			res, resp, err := g.client.Reactions.ListIssueReactions(g.ctx, g.repoOwner, g.repoName, pr.GetNumber(), &github.ListOptions{
 				Page:    i,
@@ -606,7 +621,7 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 			if err != nil {
 				return nil, false, err
 			}
-			g.rate = &resp.Rate
+			g.setRate(&resp.Rate)
 			if len(res) == 0 {
 				break
 			}
@@ -649,7 +664,7 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 				RepoName:  *pr.Base.Repo.Name,
 				OwnerName: *pr.Base.User.Login,
 			},
-			PatchURL:  *pr.PatchURL,
+			PatchURL:  pr.GetPatchURL(),
 			Reactions: reactions,
 		})
 	}
@@ -674,7 +689,7 @@ func (g *GithubDownloaderV3) convertGithubReviewComments(cs []*github.PullReques
 	for _, c := range cs {
 		// get reactions
 		var reactions []*base.Reaction
-		for i := 1; ; i++ {
+		for i := 1; !g.SkipReactions; i++ {
 			g.sleep()
 			res, resp, err := g.client.Reactions.ListPullRequestCommentReactions(g.ctx, g.repoOwner, g.repoName, c.GetID(), &github.ListOptions{
 				Page:    i,
@@ -683,7 +698,7 @@ func (g *GithubDownloaderV3) convertGithubReviewComments(cs []*github.PullReques
 			if err != nil {
 				return nil, err
 			}
-			g.rate = &resp.Rate
+			g.setRate(&resp.Rate)
 			if len(res) == 0 {
 				break
 			}
@@ -716,6 +731,9 @@ func (g *GithubDownloaderV3) convertGithubReviewComments(cs []*github.PullReques
 // GetReviews returns pull requests review
 func (g *GithubDownloaderV3) GetReviews(pullRequestNumber int64) ([]*base.Review, error) {
 	var allReviews = make([]*base.Review, 0, g.maxPerPage)
+	if g.SkipReviews {
+		return allReviews, nil
+	}
 	opt := &github.ListOptions{
 		PerPage: g.maxPerPage,
 	}
@@ -725,7 +743,7 @@ func (g *GithubDownloaderV3) GetReviews(pullRequestNumber int64) ([]*base.Review
 		if err != nil {
 			return nil, fmt.Errorf("error while listing repos: %v", err)
 		}
-		g.rate = &resp.Rate
+		g.setRate(&resp.Rate)
 		for _, review := range reviews {
 			r := convertGithubReview(review)
 			r.IssueIndex = pullRequestNumber
@@ -739,7 +757,7 @@ func (g *GithubDownloaderV3) GetReviews(pullRequestNumber int64) ([]*base.Review
 				if err != nil {
 					return nil, fmt.Errorf("error while listing repos: %v", err)
 				}
-				g.rate = &resp.Rate
+				g.setRate(&resp.Rate)
 
 				cs, err := g.convertGithubReviewComments(reviewComments)
 				if err != nil {
@@ -6,7 +6,11 @@
 package migrations
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -15,3 +19,17 @@ import (
 func TestMain(m *testing.M) {
 	models.MainTest(m, filepath.Join("..", ".."))
 }
+
+// newFixtureServer serves the API responses recorded in testdata/<service>, a request is answered
+// with the file at its path with a .json extension regardless of its query
+func newFixtureServer(service string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", service, filepath.FromSlash(strings.Trim(r.URL.Path, "/"))+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+}
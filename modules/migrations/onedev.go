@@ -0,0 +1,601 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &OneDevDownloader{}
+	_ base.DownloaderFactory = &OneDevDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&OneDevDownloaderFactory{})
+}
+
+// OneDevDownloaderFactory defines a OneDev downloader factory
+type OneDevDownloaderFactory struct {
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *OneDevDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// both the clone URL and the web URL of a project are accepted
+	fields := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if len(fields) > 1 && fields[0] == "projects" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || fields[0] == "" {
+		return nil, fmt.Errorf("invalid path: %s", u.Path)
+	}
+	repoName := fields[0]
+
+	baseURL := u.Scheme + "://" + u.Host
+
+	log.Trace("Create OneDev downloader. BaseURL: %s RepoName: %s", baseURL, repoName)
+	return NewOneDevDownloader(ctx, baseURL, opts.AuthUsername, opts.AuthPassword, repoName), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *OneDevDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.OneDevService
+}
+
+type onedevUser struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"fullName"`
+	Email    string `json:"email"`
+}
+
+// onedevIssueContext references the OneDev issue or pull request a migrated issue was created from
+type onedevIssueContext struct {
+	id            int64
+	isPullRequest bool
+}
+
+// OneDevDownloader implements a Downloader interface to get repository informations
+// from OneDev via its REST API
+// - OneDev numbers issues and pull requests separately, the pull request numbers are
+// shifted by maxIssueIndex so they don't overlap with the issue numbers
+// - issueContexts maps the migrated numbers to the OneDev issues and pull requests,
+// whose comments are fetched from different endpoints
+type OneDevDownloader struct {
+	base.NullDownloader
+	ctx            context.Context
+	client         *http.Client
+	baseURL        *url.URL
+	userName       string
+	password       string
+	repoName       string
+	repoID         int64
+	maxIssueIndex  int64
+	issueContexts  map[int64]onedevIssueContext
+	pullRequestIDs map[int64]int64
+	userMap        map[int64]*onedevUser
+}
+
+// NewOneDevDownloader creates a OneDev downloader
+func NewOneDevDownloader(ctx context.Context, baseURL, userName, password, repoName string) *OneDevDownloader {
+	u, _ := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	return &OneDevDownloader{
+		ctx:            ctx,
+		client:         &http.Client{},
+		baseURL:        u,
+		userName:       userName,
+		password:       password,
+		repoName:       repoName,
+		issueContexts:  make(map[int64]onedevIssueContext),
+		pullRequestIDs: make(map[int64]int64),
+		userMap:        make(map[int64]*onedevUser),
+	}
+}
+
+// SetContext set context
+func (d *OneDevDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+func (d *OneDevDownloader) callAPI(endpoint string, parameter map[string]string, result interface{}) error {
+	u, err := d.baseURL.Parse(strings.TrimPrefix(endpoint, "/"))
+	if err != nil {
+		return err
+	}
+
+	if parameter != nil {
+		query := u.Query()
+		for k, v := range parameter {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if d.userName != "" || d.password != "" {
+		req.SetBasicAuth(d.userName, d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d calling %s", resp.StatusCode, u.Path)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// GetRepoInfo returns a repository information
+func (d *OneDevDownloader) GetRepoInfo() (*base.Repository, error) {
+	info := make([]struct {
+		ID          int64  `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}, 0, 1)
+
+	err := d.callAPI(
+		"/api/projects",
+		map[string]string{
+			"query":  `"Name" is "` + d.repoName + `"`,
+			"offset": "0",
+			"count":  "1",
+		},
+		&info,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(info) != 1 {
+		return nil, fmt.Errorf("Project %s not found", d.repoName)
+	}
+
+	d.repoID = info[0].ID
+
+	cloneURL, err := d.baseURL.Parse(info[0].Name)
+	if err != nil {
+		return nil, err
+	}
+	originalURL, err := d.baseURL.Parse("projects/" + info[0].Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &base.Repository{
+		Name:        info[0].Name,
+		Description: info[0].Description,
+		CloneURL:    cloneURL.String(),
+		OriginalURL: originalURL.String(),
+	}, nil
+}
+
+// GetTopics return repository topics
+func (d *OneDevDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetMilestones returns milestones
+func (d *OneDevDownloader) GetMilestones() ([]*base.Milestone, error) {
+	rawMilestones := make([]struct {
+		ID          int64      `json:"id"`
+		Name        string     `json:"name"`
+		Description string     `json:"description"`
+		DueDate     *time.Time `json:"dueDate"`
+		Closed      bool       `json:"closed"`
+	}, 0, 100)
+
+	endpoint := fmt.Sprintf("/api/projects/%d/milestones", d.repoID)
+
+	var milestones = make([]*base.Milestone, 0, 100)
+	offset := 0
+	for {
+		err := d.callAPI(
+			endpoint,
+			map[string]string{
+				"offset": strconv.Itoa(offset),
+				"count":  "100",
+			},
+			&rawMilestones,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, milestone := range rawMilestones {
+			state := "open"
+			if milestone.Closed {
+				state = "closed"
+			}
+			milestones = append(milestones, &base.Milestone{
+				Title:       milestone.Name,
+				Description: milestone.Description,
+				Deadline:    milestone.DueDate,
+				State:       state,
+			})
+		}
+
+		if len(rawMilestones) < 100 {
+			break
+		}
+		offset += 100
+	}
+	return milestones, nil
+}
+
+// GetLabels returns labels, OneDev has no labels but classifies its issues by type,
+// the default types are migrated as labels
+func (d *OneDevDownloader) GetLabels() ([]*base.Label, error) {
+	return []*base.Label{
+		{
+			Name:  "Bug",
+			Color: "f64e60",
+		},
+		{
+			Name:  "Build Failure",
+			Color: "f64e60",
+		},
+		{
+			Name:  "Discussion",
+			Color: "8950fc",
+		},
+		{
+			Name:  "Improvement",
+			Color: "1bc5bd",
+		},
+		{
+			Name:  "New Feature",
+			Color: "1bc5bd",
+		},
+		{
+			Name:  "Support Request",
+			Color: "8950fc",
+		},
+	}, nil
+}
+
+// GetIssues returns issues according start and limit
+func (d *OneDevDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	rawIssues := make([]struct {
+		ID          int64     `json:"id"`
+		Number      int64     `json:"number"`
+		State       string    `json:"state"`
+		Title       string    `json:"title"`
+		Description string    `json:"description"`
+		SubmitterID int64     `json:"submitterId"`
+		SubmitDate  time.Time `json:"submitDate"`
+	}, 0, perPage)
+
+	err := d.callAPI(
+		"/api/issues",
+		map[string]string{
+			"query":  `"Project" is "` + d.repoName + `"`,
+			"offset": strconv.Itoa((page - 1) * perPage),
+			"count":  strconv.Itoa(perPage),
+		},
+		&rawIssues,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(rawIssues))
+	for _, issue := range rawIssues {
+		fields := make([]struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		}, 0, 10)
+		err := d.callAPI(
+			fmt.Sprintf("/api/issues/%d/fields", issue.ID),
+			nil,
+			&fields,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var labels []*base.Label
+		for _, field := range fields {
+			if field.Name == "Type" {
+				labels = append(labels, &base.Label{Name: field.Value})
+				break
+			}
+		}
+
+		milestones := make([]struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}, 0, 10)
+		err = d.callAPI(
+			fmt.Sprintf("/api/issues/%d/milestones", issue.ID),
+			nil,
+			&milestones,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+		var milestone string
+		if len(milestones) > 0 {
+			milestone = milestones[0].Name
+		}
+
+		state := strings.ToLower(issue.State)
+		if state == "released" {
+			state = "closed"
+		}
+		if state != "closed" {
+			state = "open"
+		}
+
+		poster := d.tryGetUser(issue.SubmitterID)
+		issues = append(issues, &base.Issue{
+			Title:       issue.Title,
+			Number:      issue.Number,
+			PosterID:    poster.ID,
+			PosterName:  poster.Name,
+			PosterEmail: poster.Email,
+			Content:     issue.Description,
+			Milestone:   milestone,
+			State:       state,
+			Created:     issue.SubmitDate,
+			Updated:     issue.SubmitDate,
+			Labels:      labels,
+		})
+
+		if d.maxIssueIndex < issue.Number {
+			d.maxIssueIndex = issue.Number
+		}
+		d.issueContexts[issue.Number] = onedevIssueContext{
+			id:            issue.ID,
+			isPullRequest: false,
+		}
+	}
+
+	return issues, len(issues) < perPage, nil
+}
+
+// GetComments returns comments according issueNumber
+func (d *OneDevDownloader) GetComments(issueNumber int64) ([]*base.Comment, error) {
+	issueContext, ok := d.issueContexts[issueNumber]
+	if !ok {
+		return nil, fmt.Errorf("unknown issue or pull request %d", issueNumber)
+	}
+
+	rawComments := make([]struct {
+		UserID  int64     `json:"userId"`
+		Content string    `json:"content"`
+		Date    time.Time `json:"date"`
+	}, 0, 100)
+
+	var endpoint string
+	if issueContext.isPullRequest {
+		endpoint = fmt.Sprintf("/api/pull-requests/%d/comments", issueContext.id)
+	} else {
+		endpoint = fmt.Sprintf("/api/issues/%d/comments", issueContext.id)
+	}
+
+	err := d.callAPI(
+		endpoint,
+		nil,
+		&rawComments,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]*base.Comment, 0, len(rawComments))
+	for _, comment := range rawComments {
+		if len(comment.Content) == 0 {
+			continue
+		}
+		poster := d.tryGetUser(comment.UserID)
+		comments = append(comments, &base.Comment{
+			IssueIndex:  issueNumber,
+			PosterID:    poster.ID,
+			PosterName:  poster.Name,
+			PosterEmail: poster.Email,
+			Content:     comment.Content,
+			Created:     comment.Date,
+			Updated:     comment.Date,
+		})
+	}
+
+	return comments, nil
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (d *OneDevDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	rawPullRequests := make([]struct {
+		ID             int64     `json:"id"`
+		Number         int64     `json:"number"`
+		Title          string    `json:"title"`
+		SubmitterID    int64     `json:"submitterId"`
+		SubmitDate     time.Time `json:"submitDate"`
+		Description    string    `json:"description"`
+		TargetBranch   string    `json:"targetBranch"`
+		SourceBranch   string    `json:"sourceBranch"`
+		BaseCommitHash string    `json:"baseCommitHash"`
+		CloseInfo      *struct {
+			Date   *time.Time `json:"date"`
+			Status string     `json:"status"`
+		} `json:"closeInfo"`
+	}, 0, perPage)
+
+	err := d.callAPI(
+		"/api/pull-requests",
+		map[string]string{
+			"query":  `"Target Project" is "` + d.repoName + `"`,
+			"offset": strconv.Itoa((page - 1) * perPage),
+			"count":  strconv.Itoa(perPage),
+		},
+		&rawPullRequests,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests))
+	for _, pr := range rawPullRequests {
+		var mergePreview struct {
+			TargetHeadCommitHash string `json:"targetHeadCommitHash"`
+			HeadCommitHash       string `json:"headCommitHash"`
+			MergeStrategy        string `json:"mergeStrategy"`
+			MergeCommitHash      string `json:"mergeCommitHash"`
+		}
+		err := d.callAPI(
+			fmt.Sprintf("/api/pull-requests/%d/merge-preview", pr.ID),
+			nil,
+			&mergePreview,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		state := "open"
+		merged := false
+		var closeTime *time.Time
+		var mergedTime *time.Time
+		var mergeCommitSHA string
+		if pr.CloseInfo != nil {
+			state = "closed"
+			closeTime = pr.CloseInfo.Date
+			if pr.CloseInfo.Status == "MERGED" {
+				merged = true
+				mergedTime = pr.CloseInfo.Date
+				mergeCommitSHA = mergePreview.MergeCommitHash
+			}
+		}
+
+		number := pr.Number + d.maxIssueIndex
+		poster := d.tryGetUser(pr.SubmitterID)
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Title:          pr.Title,
+			Number:         number,
+			OriginalNumber: pr.Number,
+			PosterName:     poster.Name,
+			PosterID:       poster.ID,
+			PosterEmail:    poster.Email,
+			Content:        pr.Description,
+			State:          state,
+			Created:        pr.SubmitDate,
+			Updated:        pr.SubmitDate,
+			Closed:         closeTime,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head: base.PullRequestBranch{
+				Ref:      pr.SourceBranch,
+				SHA:      mergePreview.HeadCommitHash,
+				RepoName: d.repoName,
+			},
+			Base: base.PullRequestBranch{
+				Ref:      pr.TargetBranch,
+				SHA:      pr.BaseCommitHash,
+				RepoName: d.repoName,
+			},
+		})
+
+		d.issueContexts[number] = onedevIssueContext{
+			id:            pr.ID,
+			isPullRequest: true,
+		}
+		d.pullRequestIDs[pr.Number] = pr.ID
+	}
+
+	return pullRequests, len(pullRequests) < perPage, nil
+}
+
+// GetReviews returns pull requests reviews
+func (d *OneDevDownloader) GetReviews(pullRequestNumber int64) ([]*base.Review, error) {
+	id, ok := d.pullRequestIDs[pullRequestNumber]
+	if !ok {
+		return nil, fmt.Errorf("unknown pull request %d", pullRequestNumber)
+	}
+
+	rawReviews := make([]struct {
+		ID     int64 `json:"id"`
+		UserID int64 `json:"userId"`
+		Result *struct {
+			Commit   string `json:"commit"`
+			Approved bool   `json:"approved"`
+			Comment  string `json:"comment"`
+		} `json:"result"`
+		UpdateDate time.Time `json:"updateDate"`
+	}, 0, 100)
+
+	err := d.callAPI(
+		fmt.Sprintf("/api/pull-requests/%d/reviews", id),
+		nil,
+		&rawReviews,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews = make([]*base.Review, 0, len(rawReviews))
+	for _, review := range rawReviews {
+		// reviews without a result were requested but not submitted yet
+		if review.Result == nil {
+			continue
+		}
+
+		state := base.ReviewStateChangesRequested
+		if review.Result.Approved {
+			state = base.ReviewStateApproved
+		}
+
+		poster := d.tryGetUser(review.UserID)
+		reviews = append(reviews, &base.Review{
+			ID:           review.ID,
+			IssueIndex:   pullRequestNumber,
+			ReviewerID:   poster.ID,
+			ReviewerName: poster.Name,
+			CommitID:     review.Result.Commit,
+			Content:      review.Result.Comment,
+			CreatedAt:    review.UpdateDate,
+			State:        state,
+		})
+	}
+
+	return reviews, nil
+}
+
+// tryGetUser returns the OneDev user of an id, a placeholder user if it can't be fetched
+func (d *OneDevDownloader) tryGetUser(userID int64) *onedevUser {
+	user, ok := d.userMap[userID]
+	if !ok {
+		user = new(onedevUser)
+		if err := d.callAPI(fmt.Sprintf("/api/users/%d", userID), nil, user); err != nil {
+			log.Warn("Unable to get OneDev user %d: %v", userID, err)
+			user = &onedevUser{
+				ID:   userID,
+				Name: fmt.Sprintf("User %d", userID),
+			}
+		}
+		d.userMap[userID] = user
+	}
+
+	return user
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneDevDownloadRepo(t *testing.T) {
+	server := newFixtureServer("onedev")
+	defer server.Close()
+
+	downloader := NewOneDevDownloader(context.Background(), server.URL, "", "", "go-gitea-test_repo")
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.EqualValues(t, &base.Repository{
+		Name:        "go-gitea-test_repo",
+		Description: "Test repository for testing migration from OneDev to gitea",
+		CloneURL:    server.URL + "/go-gitea-test_repo",
+		OriginalURL: server.URL + "/projects/go-gitea-test_repo",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	deadline := time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)
+	assert.EqualValues(t, []*base.Milestone{
+		{
+			Title:       "1.0.0",
+			Description: "the first release",
+			Deadline:    &deadline,
+			State:       "open",
+		},
+		{
+			Title: "0.9.0",
+			State: "closed",
+		},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assert.Len(t, labels, 6)
+
+	issues, isEnd, err := downloader.GetIssues(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.EqualValues(t, []*base.Issue{
+		{
+			Number:      2,
+			Title:       "Add an awesome feature",
+			Content:     "just another issue to test against",
+			PosterID:    1,
+			PosterName:  "admin",
+			PosterEmail: "admin@onedev.example.com",
+			Milestone:   "1.0.0",
+			State:       "open",
+			Created:     time.Date(2021, 6, 13, 10, 11, 12, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 13, 10, 11, 12, 0, time.UTC),
+			Labels: []*base.Label{
+				{
+					Name: "New Feature",
+				},
+			},
+		},
+		{
+			Number:      1,
+			Title:       "Fix the crash on startup",
+			Content:     "it crashes",
+			PosterID:    2,
+			PosterName:  "jdoe",
+			PosterEmail: "jdoe@onedev.example.com",
+			State:       "closed",
+			Created:     time.Date(2021, 6, 12, 8, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 12, 8, 0, 0, 0, time.UTC),
+			Labels: []*base.Label{
+				{
+					Name: "Bug",
+				},
+			},
+		},
+	}, issues)
+
+	comments, err := downloader.GetComments(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Comment{
+		{
+			IssueIndex:  2,
+			PosterID:    2,
+			PosterName:  "jdoe",
+			PosterEmail: "jdoe@onedev.example.com",
+			Content:     "would be great to have",
+			Created:     time.Date(2021, 6, 13, 11, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 13, 11, 0, 0, 0, time.UTC),
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 50)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	closed := time.Date(2021, 6, 15, 10, 0, 0, 0, time.UTC)
+	assert.EqualValues(t, []*base.PullRequest{
+		{
+			// the pull request numbers follow the issue numbers
+			Number:         3,
+			OriginalNumber: 1,
+			Title:          "Document the configuration",
+			Content:        "adds the missing docs",
+			PosterID:       2,
+			PosterName:     "jdoe",
+			PosterEmail:    "jdoe@onedev.example.com",
+			State:          "closed",
+			Created:        time.Date(2021, 6, 14, 9, 0, 0, 0, time.UTC),
+			Updated:        time.Date(2021, 6, 14, 9, 0, 0, 0, time.UTC),
+			Closed:         &closed,
+			Merged:         true,
+			MergedTime:     &closed,
+			MergeCommitSHA: "9e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d",
+			Head: base.PullRequestBranch{
+				Ref:      "docs",
+				SHA:      "4a7f3c9e2d1b0a8f7e6d5c4b3a29180f7e6d5c4b",
+				RepoName: "go-gitea-test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:      "master",
+				SHA:      "b8cb8a2e6b3c9a7f8d6b5e3f2a1c0d9e8f7a6b5c",
+				RepoName: "go-gitea-test_repo",
+			},
+		},
+		{
+			Number:         4,
+			OriginalNumber: 2,
+			Title:          "Speed up the startup",
+			PosterID:       1,
+			PosterName:     "admin",
+			PosterEmail:    "admin@onedev.example.com",
+			State:          "open",
+			Created:        time.Date(2021, 6, 16, 9, 30, 0, 0, time.UTC),
+			Updated:        time.Date(2021, 6, 16, 9, 30, 0, 0, time.UTC),
+			Head: base.PullRequestBranch{
+				Ref:      "faster",
+				SHA:      "c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f6",
+				RepoName: "go-gitea-test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:      "master",
+				SHA:      "0fd5e1c4a2b3c4d5e6f708192a3b4c5d6e7f8091",
+				RepoName: "go-gitea-test_repo",
+			},
+		},
+	}, prs)
+
+	comments, err = downloader.GetComments(3)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.EqualValues(t, "thanks for writing this", comments[0].Content)
+	assert.EqualValues(t, 3, comments[0].IssueIndex)
+
+	reviews, err := downloader.GetReviews(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*base.Review{
+		{
+			ID:           9,
+			IssueIndex:   1,
+			ReviewerID:   1,
+			ReviewerName: "admin",
+			CommitID:     "4a7f3c9e2d1b0a8f7e6d5c4b3a29180f7e6d5c4b",
+			Content:      "LGTM",
+			CreatedAt:    time.Date(2021, 6, 15, 9, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateApproved,
+		},
+	}, reviews)
+}
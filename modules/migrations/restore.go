@@ -11,8 +11,13 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 
 	"gopkg.in/yaml.v2"
 )
@@ -40,6 +45,14 @@ func NewRepositoryRestorer(ctx context.Context, baseDir string, owner, repoName
 	}, nil
 }
 
+func (r *RepositoryRestorer) gitPath() string {
+	return filepath.Join(r.baseDir, "git")
+}
+
+func (r *RepositoryRestorer) wikiPath() string {
+	return filepath.Join(r.baseDir, "wiki")
+}
+
 func (r *RepositoryRestorer) commentDir() string {
 	return filepath.Join(r.baseDir, "comments")
 }
@@ -48,6 +61,12 @@ func (r *RepositoryRestorer) reviewDir() string {
 	return filepath.Join(r.baseDir, "reviews")
 }
 
+// dumpFilePath returns the path of a file the dump refers to, it refuses paths outside of the dump
+// as they are read from the files of the dump
+func (r *RepositoryRestorer) dumpFilePath(name string) (string, error) {
+	return dumpEntryPath(r.baseDir, name)
+}
+
 // SetContext set context
 func (r *RepositoryRestorer) SetContext(ctx context.Context) {
 	r.ctx = ctx
@@ -77,17 +96,46 @@ func (r *RepositoryRestorer) GetRepoInfo() (*base.Repository, error) {
 
 	isPrivate, _ := strconv.ParseBool(opts["is_private"])
 
+	// the git data is always restored from the dump, never from the clone address it names, as
+	// that address hasn't been checked like the one of a migration. It is cloned through the file
+	// transport, which unlike a local clone doesn't copy the files of the repository as they are.
+	if isDir, err := util.IsDir(r.gitPath()); err != nil {
+		return nil, err
+	} else if !isDir {
+		return nil, fmt.Errorf("the dump contains no git repository at %s", r.gitPath())
+	}
+
 	return &base.Repository{
 		Owner:         r.repoOwner,
 		Name:          r.repoName,
 		IsPrivate:     isPrivate,
 		Description:   opts["description"],
 		OriginalURL:   opts["original_url"],
-		CloneURL:      opts["clone_addr"],
+		CloneURL:      "file://" + r.gitPath(),
 		DefaultBranch: opts["default_branch"],
 	}, nil
 }
 
+// restoreWiki restores the dumped wiki of repo, if there is one
+func (r *RepositoryRestorer) restoreWiki(repo *models.Repository) error {
+	if isDir, err := util.IsDir(r.wikiPath()); err != nil || !isDir {
+		return err
+	}
+
+	wikiPath := repo.WikiPath()
+	if err := util.RemoveAll(wikiPath); err != nil {
+		return fmt.Errorf("Failed to remove %s: %v", wikiPath, err)
+	}
+	if err := git.CloneWithContext(r.ctx, "file://"+r.wikiPath(), wikiPath, git.CloneRepoOptions{
+		Mirror:  true,
+		Quiet:   true,
+		Timeout: time.Duration(setting.Git.Timeout.Migrate) * time.Second,
+	}); err != nil {
+		return fmt.Errorf("Clone wiki: %v", err)
+	}
+	return nil
+}
+
 // GetTopics return github topics
 func (r *RepositoryRestorer) GetTopics() ([]string, error) {
 	p := filepath.Join(r.baseDir, "topic.yml")
@@ -98,6 +146,10 @@ func (r *RepositoryRestorer) GetTopics() ([]string, error) {
 
 	bs, err := ioutil.ReadFile(p)
 	if err != nil {
+		// the dump has no topics file if the repository had no topics
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -155,7 +207,14 @@ func (r *RepositoryRestorer) GetReleases() ([]*base.Release, error) {
 	}
 	for _, rel := range releases {
 		for _, asset := range rel.Assets {
-			*asset.DownloadURL = "file://" + filepath.Join(r.baseDir, *asset.DownloadURL)
+			if asset.DownloadURL == nil {
+				continue
+			}
+			p, err := r.dumpFilePath(*asset.DownloadURL)
+			if err != nil {
+				return nil, err
+			}
+			*asset.DownloadURL = "file://" + p
 		}
 	}
 	return releases, nil
@@ -255,7 +314,13 @@ func (r *RepositoryRestorer) GetPullRequests(page, perPage int) ([]*base.PullReq
 		return nil, false, err
 	}
 	for _, pr := range pulls {
-		pr.PatchURL = "file://" + filepath.Join(r.baseDir, pr.PatchURL)
+		if pr.PatchURL != "" {
+			p, err := r.dumpFilePath(pr.PatchURL)
+			if err != nil {
+				return nil, false, err
+			}
+			pr.PatchURL = "file://" + p
+		}
 	}
 	return pulls, true, nil
 }
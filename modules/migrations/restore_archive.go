@@ -0,0 +1,245 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	gouuid "github.com/google/uuid"
+)
+
+// ErrNoRepositoryDump is returned when an archive doesn't contain a repository dump
+var ErrNoRepositoryDump = errors.New("the archive does not contain a repository dump")
+
+// ErrRepositoryDumpTooLarge is returned when the content of an archive exceeds the configured limits of dumps
+var ErrRepositoryDumpTooLarge = errors.New("the content of the archive exceeds the size or entry limit of repository dumps")
+
+// SaveRepositoryDump stores an uploaded dump archive in setting.Migrations.DumpPath until it is restored,
+// it returns the path of the stored archive, which keeps the name it was uploaded with
+func SaveRepositoryDump(r io.Reader, name string) (string, error) {
+	name = filepath.Base(filepath.Clean("/" + strings.ReplaceAll(name, "\\", "/")))
+	if name == string(filepath.Separator) || name == "." {
+		name = "dump"
+	}
+
+	dir := filepath.Join(setting.Migrations.DumpPath, "uploads", gouuid.New().String())
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	p := filepath.Join(dir, name)
+	if _, err := writeDumpFile(p, r); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// ExtractRepositoryDump extracts a zip, tar or tar.gz archive of a directory written by the RepositoryDumper
+// into destDir, it returns the directory of the dump, the one containing its repo.yml.
+// Only directories and regular files are extracted and the files are never executable,
+// so the git hooks an archive might contain can't be run. The alternates of git repositories are
+// dropped, so that a restored repository can't borrow the objects of another repository on disk.
+// Archives with more entries or more content than setting.Migrations allows are refused.
+func ExtractRepositoryDump(archivePath, destDir string) (string, error) {
+	destDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	e := &dumpExtractor{
+		destDir:   destDir,
+		remaining: setting.Migrations.MaxDumpContentSize * 1024 * 1024,
+	}
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		err = e.extractZip(archivePath)
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		var gr *gzip.Reader
+		if gr, err = gzip.NewReader(br); err != nil {
+			return "", err
+		}
+		defer gr.Close()
+		err = e.extractTar(gr)
+	default:
+		err = e.extractTar(br)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return findRepositoryDump(destDir)
+}
+
+// dumpEntryPath returns the path an archive entry is extracted to, it refuses entries outside of destDir
+func dumpEntryPath(destDir, name string) (string, error) {
+	p := filepath.Join(destDir, filepath.FromSlash(name))
+	if p != destDir && !strings.HasPrefix(p, destDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+	return p, nil
+}
+
+// isGitAlternatesFile returns whether an archive entry lists alternate object stores of a git repository
+func isGitAlternatesFile(name string) bool {
+	name = "/" + strings.ReplaceAll(name, "\\", "/")
+	return strings.HasSuffix(name, "/objects/info/alternates") || strings.HasSuffix(name, "/objects/info/http-alternates")
+}
+
+func writeDumpFile(p string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// dumpExtractor extracts the entries of an archive, counting them and their content against the limits
+type dumpExtractor struct {
+	destDir   string
+	entries   int
+	remaining int64
+}
+
+// entryPath returns the path an archive entry is extracted to
+func (e *dumpExtractor) entryPath(name string) (string, error) {
+	e.entries++
+	if e.entries > setting.Migrations.MaxDumpEntries {
+		return "", ErrRepositoryDumpTooLarge
+	}
+	return dumpEntryPath(e.destDir, name)
+}
+
+// writeFile writes the content of an archive entry, which the size of an entry's header isn't trusted for
+func (e *dumpExtractor) writeFile(p string, r io.Reader) error {
+	n, err := writeDumpFile(p, io.LimitReader(r, e.remaining+1))
+	e.remaining -= n
+	if err != nil {
+		return err
+	}
+	if e.remaining < 0 {
+		return ErrRepositoryDumpTooLarge
+	}
+	return nil
+}
+
+func (e *dumpExtractor) extractZip(archivePath string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		p, err := e.entryPath(file.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(p, os.ModePerm); err != nil {
+				return err
+			}
+		case mode.IsRegular() && !isGitAlternatesFile(file.Name):
+			rc, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = e.writeFile(p, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *dumpExtractor) extractTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		p, err := e.entryPath(hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if isGitAlternatesFile(hdr.Name) {
+				continue
+			}
+			if err := e.writeFile(p, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// findRepositoryDump returns the least nested directory of dir containing a repo.yml
+func findRepositoryDump(dir string) (string, error) {
+	var found string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "repo.yml" {
+			return nil
+		}
+		if found == "" || strings.Count(p, string(filepath.Separator)) < strings.Count(found, string(filepath.Separator)) {
+			found = p
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", ErrNoRepositoryDump
+	}
+	return filepath.Dir(found), nil
+}
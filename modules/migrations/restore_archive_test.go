@@ -0,0 +1,223 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dumpEntry struct {
+	name    string
+	content string
+	mode    os.FileMode
+}
+
+var testDumpEntries = []dumpEntry{
+	{"dumps/", "", os.ModeDir | 0755},
+	{"dumps/user2/repo1/repo.yml", "name: repo1\n", 0644},
+	{"dumps/user2/repo1/git/HEAD", "ref: refs/heads/master\n", 0644},
+	{"dumps/user2/repo1/git/hooks/post-update", "#!/bin/sh\n", 0755},
+	{"dumps/user2/repo1/git/objects/info/alternates", "/data/gitea-repositories/user1/private.git/objects\n", 0644},
+	{"dumps/user2/repo1/wiki/objects/info/http-alternates", "http://localhost/private.git/objects\n", 0644},
+}
+
+func writeTestZip(t *testing.T, p string, entries []dumpEntry) {
+	f, err := os.Create(p)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, entry := range entries {
+		hdr := &zip.FileHeader{Name: entry.name}
+		hdr.SetMode(entry.mode)
+		w, err := zw.CreateHeader(hdr)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(entry.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+}
+
+func writeTestTarGz(t *testing.T, p string, entries []dumpEntry) {
+	f, err := os.Create(p)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Mode:     int64(entry.mode.Perm()),
+			Size:     int64(len(entry.content)),
+			Typeflag: tar.TypeReg,
+		}
+		if entry.mode.IsDir() {
+			hdr.Typeflag = tar.TypeDir
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write([]byte(entry.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+}
+
+func TestExtractRepositoryDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump-archive")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	for _, tc := range []struct {
+		name  string
+		write func(*testing.T, string, []dumpEntry)
+	}{
+		{"dump.zip", writeTestZip},
+		{"dump.tar.gz", writeTestTarGz},
+	} {
+		archivePath := filepath.Join(dir, tc.name)
+		tc.write(t, archivePath, testDumpEntries)
+
+		destDir := filepath.Join(dir, tc.name+".extracted")
+		baseDir, err := ExtractRepositoryDump(archivePath, destDir)
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, filepath.Join(destDir, "dumps", "user2", "repo1"), baseDir, tc.name)
+
+		bs, err := ioutil.ReadFile(filepath.Join(baseDir, "repo.yml"))
+		assert.NoError(t, err)
+		assert.Equal(t, "name: repo1\n", string(bs))
+
+		// hooks must never become executable
+		info, err := os.Stat(filepath.Join(baseDir, "git", "hooks", "post-update"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, info.Mode()&0111, tc.name)
+
+		// the objects of other repositories must not be borrowed
+		assert.NoFileExists(t, filepath.Join(baseDir, "git", "objects", "info", "alternates"), tc.name)
+		assert.NoFileExists(t, filepath.Join(baseDir, "wiki", "objects", "info", "http-alternates"), tc.name)
+	}
+
+	// entries outside of the destination are refused
+	archivePath := filepath.Join(dir, "evil.zip")
+	writeTestZip(t, archivePath, []dumpEntry{{"../outside/repo.yml", "name: evil\n", 0644}})
+	_, err = ExtractRepositoryDump(archivePath, filepath.Join(dir, "evil"))
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "outside", "repo.yml"))
+
+	// archives without repo.yml are no dumps
+	archivePath = filepath.Join(dir, "empty.tar.gz")
+	writeTestTarGz(t, archivePath, []dumpEntry{{"README.md", "readme", 0644}})
+	_, err = ExtractRepositoryDump(archivePath, filepath.Join(dir, "empty"))
+	assert.Equal(t, ErrNoRepositoryDump, err)
+}
+
+func TestExtractRepositoryDump_Limits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump-archive-limits")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	oldEntries, oldSize := setting.Migrations.MaxDumpEntries, setting.Migrations.MaxDumpContentSize
+	defer func() {
+		setting.Migrations.MaxDumpEntries, setting.Migrations.MaxDumpContentSize = oldEntries, oldSize
+	}()
+	setting.Migrations.MaxDumpEntries = len(testDumpEntries)
+	setting.Migrations.MaxDumpContentSize = 1
+
+	archivePath := filepath.Join(dir, "dump.zip")
+	writeTestZip(t, archivePath, testDumpEntries)
+	_, err = ExtractRepositoryDump(archivePath, filepath.Join(dir, "dump"))
+	assert.NoError(t, err)
+
+	// too many entries
+	archivePath = filepath.Join(dir, "entries.tar.gz")
+	writeTestTarGz(t, archivePath, append(testDumpEntries, dumpEntry{"dumps/user2/repo1/label.yml", "", 0644}))
+	_, err = ExtractRepositoryDump(archivePath, filepath.Join(dir, "entries"))
+	assert.Equal(t, ErrRepositoryDumpTooLarge, err)
+
+	// too much content, however small it claims to be
+	for _, tc := range []struct {
+		name  string
+		write func(*testing.T, string, []dumpEntry)
+	}{
+		{"size.zip", writeTestZip},
+		{"size.tar.gz", writeTestTarGz},
+	} {
+		archivePath = filepath.Join(dir, tc.name)
+		tc.write(t, archivePath, []dumpEntry{{"repo.yml", strings.Repeat("a", 1024*1024+1), 0644}})
+		_, err = ExtractRepositoryDump(archivePath, filepath.Join(dir, tc.name+".extracted"))
+		assert.Equal(t, ErrRepositoryDumpTooLarge, err, tc.name)
+	}
+}
+
+func TestSaveRepositoryDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump-uploads")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	oldDumpPath := setting.Migrations.DumpPath
+	setting.Migrations.DumpPath = dir
+	defer func() {
+		setting.Migrations.DumpPath = oldDumpPath
+	}()
+
+	for _, name := range []string{"dump.zip", "../../dump.zip", "..\\..\\dump.zip"} {
+		p, err := SaveRepositoryDump(strings.NewReader("content"), name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, "dump.zip", filepath.Base(p), name)
+		assert.True(t, strings.HasPrefix(p, filepath.Join(dir, "uploads")+string(filepath.Separator)), name)
+		bs, err := ioutil.ReadFile(p)
+		assert.NoError(t, err)
+		assert.Equal(t, "content", string(bs))
+	}
+}
+
+func TestRestoreDumpedRepository(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	dir, err := ioutil.TempDir("", "dump-restore")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	// a dump of repo1 with its wiki and labels
+	assert.NoError(t, util.CopyDir(filepath.Join(setting.RepoRootPath, "user2", "repo1.git"), filepath.Join(dir, "git")))
+	assert.NoError(t, util.CopyDir(filepath.Join(setting.RepoRootPath, "user2", "repo1.wiki.git"), filepath.Join(dir, "wiki")))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "repo.yml"), []byte("name: repo1\nowner: user2\ndescription: dumped\nclone_addr: https://example.com/user2/repo1.git\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "label.yml"), []byte("- name: bug\n  color: ee0701\n"), 0644))
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	var messages []string
+	repo, err := RestoreDumpedRepository(context.Background(), user, dir, user.Name, base.MigrateOptions{
+		RepoName: "restored",
+	}, func(key string, args ...interface{}) {
+		messages = append(messages, key)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "restored", repo.Name)
+	assert.False(t, repo.IsEmpty)
+	assert.True(t, repo.HasWiki())
+	assert.Contains(t, messages, "repo.migrate.migrating_git")
+	assert.Contains(t, messages, "repo.migrate.migrating_labels")
+
+	labels, err := models.GetLabelsByRepoID(repo.ID, "", models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, labels, 1)
+	assert.Equal(t, "bug", labels[0].Name)
+}
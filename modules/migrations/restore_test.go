@@ -0,0 +1,61 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryRestorer_FilePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump-restorer")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	r, err := NewRepositoryRestorer(context.Background(), dir, "user2", "repo1")
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "release.yml"), []byte("- tag_name: v1\n  assets:\n  - name: a.zip\n    download_url: release_assets/v1/a.zip\n"), 0644))
+	releases, err := r.GetReleases()
+	assert.NoError(t, err)
+	if assert.Len(t, releases, 1) && assert.Len(t, releases[0].Assets, 1) {
+		assert.Equal(t, "file://"+filepath.Join(dir, "release_assets", "v1", "a.zip"), *releases[0].Assets[0].DownloadURL)
+	}
+
+	// files outside of the dump must not be read
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "release.yml"), []byte("- tag_name: v1\n  assets:\n  - name: passwd\n    download_url: ../../../../etc/passwd\n"), 0644))
+	_, err = r.GetReleases()
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pull_request.yml"), []byte("- number: 1\n  patch_url: git/../../secret.patch\n"), 0644))
+	_, _, err = r.GetPullRequests(1, 10)
+	assert.Error(t, err)
+}
+
+func TestRepositoryRestorer_GetRepoInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump-restorer")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	r, err := NewRepositoryRestorer(context.Background(), dir, "user2", "restored")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "repo.yml"), []byte("name: repo1\nclone_addr: file:///etc\n"), 0644))
+
+	// the clone address of the dump is never cloned from
+	_, err = r.GetRepoInfo()
+	assert.Error(t, err)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "git"), os.ModePerm))
+	repo, err := r.GetRepoInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "file://"+filepath.Join(dir, "git"), repo.CloneURL)
+}
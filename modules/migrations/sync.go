@@ -0,0 +1,598 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/pull"
+)
+
+// SyncRepository brings the milestones, labels, releases, issues, pull requests, comments and reviews of a migrated
+// repository up to date with the service it has been migrated from. Issues and pull requests are matched by their
+// index on that service and comments and reviews by their poster and creation time, so nothing gets duplicated.
+// Issues and pull requests which haven't been updated on the source after since are left untouched.
+func SyncRepository(ctx context.Context, doer *models.User, repo *models.Repository, opts base.MigrateOptions, since time.Time) error {
+	downloader, err := newDownloader(ctx, repo.OwnerName, opts)
+	if err != nil {
+		return err
+	}
+
+	uploader := NewGiteaLocalUploader(ctx, doer, repo.OwnerName, repo.Name)
+	uploader.repo = repo
+	uploader.gitServiceType = opts.GitServiceType
+	uploader.gitRepo, err = git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer uploader.Close()
+
+	return syncRepository(downloader, uploader, opts, since)
+}
+
+// syncRepository syncs the repository of uploader from downloader, see SyncRepository
+func syncRepository(downloader base.Downloader, uploader *GiteaLocalUploader, opts base.MigrateOptions, since time.Time) error {
+	s := &repositorySyncer{
+		downloader: downloader,
+		uploader:   uploader,
+		repo:       uploader.repo,
+		opts:       opts,
+		since:      since,
+		assigned:   make(map[int64]bool),
+	}
+	var err error
+	if s.maxIndex, err = models.GetMaxIssueIndex(s.repo.ID); err != nil {
+		return err
+	}
+
+	if opts.Milestones {
+		log.Trace("syncing milestones of %-v", s.repo)
+		if err := s.syncMilestones(); err != nil {
+			return err
+		}
+	}
+	if opts.Labels {
+		log.Trace("syncing labels of %-v", s.repo)
+		if err := s.syncLabels(); err != nil {
+			return err
+		}
+	}
+	if opts.Releases {
+		log.Trace("syncing releases of %-v", s.repo)
+		if err := s.syncReleases(); err != nil {
+			return err
+		}
+	}
+	if opts.Issues {
+		log.Trace("syncing issues of %-v", s.repo)
+		if err := s.syncIssues(); err != nil {
+			return err
+		}
+	}
+	if opts.PullRequests {
+		log.Trace("syncing pull requests of %-v", s.repo)
+		if err := s.syncPullRequests(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type repositorySyncer struct {
+	downloader base.Downloader
+	uploader   *GiteaLocalUploader
+	repo       *models.Repository
+	opts       base.MigrateOptions
+	since      time.Time
+	maxIndex   int64
+	assigned   map[int64]bool // indexes given to new issues and pull requests
+}
+
+// syncedIssue is an issue or a pull request whose comments and reviews are synced
+type syncedIssue struct {
+	issueID      int64 // 0 if it has just been created
+	index        int64
+	number       int64 // its number for the downloader
+	foreignIndex int64
+}
+
+func (s *repositorySyncer) syncMilestones() error {
+	milestones, err := s.downloader.GetMilestones()
+	if err != nil {
+		if !base.IsErrNotSupported(err) {
+			return err
+		}
+		log.Warn("syncing milestones is not supported, ignored")
+		return nil
+	}
+
+	var newMilestones []*base.Milestone
+	for _, milestone := range milestones {
+		ms, err := models.GetMilestoneByRepoIDANDName(s.repo.ID, milestone.Title)
+		if models.IsErrMilestoneNotExist(err) {
+			newMilestones = append(newMilestones, milestone)
+			continue
+		} else if err != nil {
+			return err
+		}
+		s.uploader.milestones.Store(ms.Name, ms.ID)
+
+		isClosed := milestone.State == "closed"
+		if ms.Content == milestone.Description && ms.IsClosed == isClosed {
+			continue
+		}
+		oldIsClosed := ms.IsClosed
+		ms.Content = milestone.Description
+		ms.IsClosed = isClosed
+		if err := models.UpdateMilestone(ms, oldIsClosed); err != nil {
+			return err
+		}
+	}
+
+	if len(newMilestones) == 0 {
+		return nil
+	}
+	return s.uploader.CreateMilestones(newMilestones...)
+}
+
+func (s *repositorySyncer) syncLabels() error {
+	labels, err := s.downloader.GetLabels()
+	if err != nil {
+		if !base.IsErrNotSupported(err) {
+			return err
+		}
+		log.Warn("syncing labels is not supported, ignored")
+		return nil
+	}
+
+	var newLabels []*base.Label
+	for _, label := range labels {
+		lb, err := models.GetLabelInRepoByName(s.repo.ID, label.Name)
+		if models.IsErrRepoLabelNotExist(err) {
+			newLabels = append(newLabels, label)
+			continue
+		} else if err != nil {
+			return err
+		}
+		s.uploader.labels.Store(lb.Name, lb)
+
+		color := fmt.Sprintf("#%s", label.Color)
+		if lb.Description == label.Description && lb.Color == color {
+			continue
+		}
+		lb.Description = label.Description
+		lb.Color = color
+		if err := models.UpdateLabel(lb); err != nil {
+			return err
+		}
+	}
+
+	if len(newLabels) == 0 {
+		return nil
+	}
+	return s.uploader.CreateLabels(newLabels...)
+}
+
+func (s *repositorySyncer) syncReleases() error {
+	releases, err := s.downloader.GetReleases()
+	if err != nil {
+		if !base.IsErrNotSupported(err) {
+			return err
+		}
+		log.Warn("syncing releases is not supported, ignored")
+		return nil
+	}
+
+	var newReleases []*base.Release
+	for _, release := range releases {
+		rel, err := models.GetRelease(s.repo.ID, release.TagName)
+		if models.IsErrReleaseNotExist(err) {
+			newReleases = append(newReleases, release)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if rel.IsTag {
+			// the mirror update has created a bare release for the new tag, the migrated release replaces it
+			if err := models.DeleteReleaseByID(rel.ID); err != nil {
+				return err
+			}
+			newReleases = append(newReleases, release)
+			continue
+		}
+
+		if rel.Title == release.Name && rel.Note == release.Body && rel.IsDraft == release.Draft && rel.IsPrerelease == release.Prerelease {
+			continue
+		}
+		rel.Title = release.Name
+		rel.Note = release.Body
+		rel.IsDraft = release.Draft
+		rel.IsPrerelease = release.Prerelease
+		if err := models.UpdateRelease(models.DefaultDBContext(), rel); err != nil {
+			return err
+		}
+	}
+
+	if len(newReleases) == 0 {
+		return nil
+	}
+	return s.uploader.CreateReleases(newReleases...)
+}
+
+func (s *repositorySyncer) syncIssues() error {
+	var issueBatchSize = s.uploader.MaxBatchInsertSize("issue")
+	for i := 1; ; i++ {
+		issues, isEnd, err := s.downloader.GetIssues(i, issueBatchSize)
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing issues is not supported, ignored")
+			return nil
+		}
+
+		var (
+			newIssues = make([]*base.Issue, 0, len(issues))
+			synced    = make([]syncedIssue, 0, len(issues))
+		)
+		for _, issue := range issues {
+			number := issue.Number
+			local, err := models.GetIssueByForeignIndex(s.repo.ID, false, number)
+			if err == nil {
+				if !issue.Updated.After(s.since) {
+					continue
+				}
+				if err := s.updateIssue(local, issue); err != nil {
+					return err
+				}
+				synced = append(synced, syncedIssue{issueID: local.ID, index: local.Index, number: number, foreignIndex: number})
+				continue
+			} else if models.IsErrIssueNotExist(err) {
+				// it has been deleted locally
+				continue
+			} else if !models.IsErrForeignReferenceNotExist(err) {
+				return err
+			}
+
+			if issue.Number, err = s.newIndex(number); err != nil {
+				return err
+			}
+			if issue.Number != number {
+				issue.OriginalNumber = number
+			}
+			newIssues = append(newIssues, issue)
+			synced = append(synced, syncedIssue{index: issue.Number, number: number, foreignIndex: number})
+		}
+
+		if err := s.uploader.CreateIssues(newIssues...); err != nil {
+			return err
+		}
+		if err := s.syncComments(synced); err != nil {
+			return err
+		}
+
+		if isEnd {
+			return nil
+		}
+	}
+}
+
+func (s *repositorySyncer) syncPullRequests() error {
+	var prBatchSize = s.uploader.MaxBatchInsertSize("pullrequest")
+	for i := 1; ; i++ {
+		prs, isEnd, err := s.downloader.GetPullRequests(i, prBatchSize)
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing pull requests is not supported, ignored")
+			return nil
+		}
+
+		var (
+			newPRs = make([]*base.PullRequest, 0, len(prs))
+			synced = make([]syncedIssue, 0, len(prs))
+		)
+		for _, pr := range prs {
+			number := pr.Number
+			// on gitlab migrations pull number change
+			foreignIndex := pr.Number
+			if pr.OriginalNumber > 0 {
+				foreignIndex = pr.OriginalNumber
+			}
+
+			issue, err := models.GetIssueByForeignIndex(s.repo.ID, true, foreignIndex)
+			if err == nil {
+				// the mirror update prunes the head references the source doesn't have
+				if err := s.syncPullRequestHead(issue.Index, pr.Head.SHA); err != nil {
+					return err
+				}
+				if !pr.Updated.After(s.since) {
+					continue
+				}
+				if err := s.updatePullRequest(issue, pr); err != nil {
+					return err
+				}
+				synced = append(synced, syncedIssue{issueID: issue.ID, index: issue.Index, number: number, foreignIndex: foreignIndex})
+				continue
+			} else if models.IsErrIssueNotExist(err) {
+				// it has been deleted locally
+				continue
+			} else if !models.IsErrForeignReferenceNotExist(err) {
+				return err
+			}
+
+			if pr.Number, err = s.newIndex(number); err != nil {
+				return err
+			}
+			if pr.Number != number && pr.OriginalNumber == 0 {
+				pr.OriginalNumber = number
+			}
+			newPRs = append(newPRs, pr)
+			synced = append(synced, syncedIssue{index: pr.Number, number: number, foreignIndex: foreignIndex})
+		}
+
+		if len(newPRs) > 0 {
+			if err := s.uploader.CreatePullRequests(newPRs...); err != nil {
+				return err
+			}
+		}
+		if err := s.syncComments(synced); err != nil {
+			return err
+		}
+		if err := s.syncReviews(synced); err != nil {
+			return err
+		}
+
+		if isEnd {
+			return nil
+		}
+	}
+}
+
+// newIndex returns the local index of a new issue or pull request, it keeps its number on the source unless
+// that's already taken in the repository
+func (s *repositorySyncer) newIndex(number int64) (int64, error) {
+	if number > s.maxIndex {
+		s.maxIndex = number
+		s.assigned[number] = true
+		return number, nil
+	}
+	if !s.assigned[number] {
+		_, err := models.GetIssueByIndex(s.repo.ID, number)
+		if models.IsErrIssueNotExist(err) {
+			s.assigned[number] = true
+			return number, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	s.maxIndex++
+	s.assigned[s.maxIndex] = true
+	return s.maxIndex, nil
+}
+
+// updateIssue applies the changes of an issue or a pull request on the source to its local copy
+func (s *repositorySyncer) updateIssue(issue *models.Issue, remote *base.Issue) error {
+	issue.Title = remote.Title
+	issue.Content = remote.Content
+	issue.IsLocked = remote.IsLocked
+	issue.IsClosed = remote.State == "closed"
+	issue.ClosedUnix = 0
+	if issue.IsClosed && remote.Closed != nil {
+		issue.ClosedUnix = timeutil.TimeStamp(remote.Closed.Unix())
+	}
+	issue.UpdatedUnix = timeutil.TimeStamp(remote.Updated.Unix())
+
+	if s.opts.Milestones {
+		issue.MilestoneID = 0
+		if milestone, ok := s.uploader.milestones.Load(remote.Milestone); ok {
+			issue.MilestoneID = milestone.(int64)
+		}
+	}
+
+	if s.opts.Labels {
+		issue.Labels = make([]*models.Label, 0, len(remote.Labels))
+		for _, label := range remote.Labels {
+			if lb, ok := s.uploader.labels.Load(label.Name); ok {
+				issue.Labels = append(issue.Labels, lb.(*models.Label))
+			}
+		}
+	} else if err := issue.LoadLabels(); err != nil {
+		return err
+	}
+
+	return models.UpdateMigratedIssue(issue)
+}
+
+func (s *repositorySyncer) updatePullRequest(issue *models.Issue, pr *base.PullRequest) error {
+	var err error
+	issue.PullRequest, err = models.GetPullRequestByIssueIDWithNoAttributes(issue.ID)
+	if err != nil {
+		return err
+	}
+	issue.PullRequest.Issue = issue
+	if pr.Base.SHA != "" {
+		issue.PullRequest.MergeBase = pr.Base.SHA
+	}
+	if pr.Merged && !issue.PullRequest.HasMerged {
+		issue.PullRequest.HasMerged = true
+		issue.PullRequest.MergerID = s.uploader.doer.ID
+		issue.PullRequest.MergedCommitID = pr.MergeCommitSHA
+		if pr.MergedTime != nil {
+			issue.PullRequest.MergedUnix = timeutil.TimeStamp(pr.MergedTime.Unix())
+		}
+	}
+
+	if err := s.updateIssue(issue, &base.Issue{
+		Title:     pr.Title,
+		Content:   pr.Content,
+		Milestone: pr.Milestone,
+		State:     pr.State,
+		IsLocked:  pr.IsLocked,
+		Updated:   pr.Updated,
+		Closed:    pr.Closed,
+		Labels:    pr.Labels,
+	}); err != nil {
+		return err
+	}
+
+	if !issue.IsClosed {
+		pull.AddToTaskQueue(issue.PullRequest)
+	}
+	return nil
+}
+
+// syncPullRequestHead points the head reference of a pull request to sha unless it already does
+func (s *repositorySyncer) syncPullRequestHead(index int64, sha string) error {
+	if sha == "" {
+		return nil
+	}
+	if commitID, err := s.uploader.gitRepo.GetRefCommitID(fmt.Sprintf("refs/pull/%d/head", index)); err == nil && commitID == sha {
+		return nil
+	}
+	return s.uploader.writePullRequestHead(index, sha)
+}
+
+// posterKey identifies a migrated poster the same way for the items on the source and their local copies:
+// by the local user the poster has been mapped to or else by the poster's id on the source
+func posterKey(posterID, originalAuthorID int64) string {
+	if originalAuthorID != 0 {
+		return fmt.Sprintf("o%d", originalAuthorID)
+	}
+	return fmt.Sprintf("u%d", posterID)
+}
+
+// remotePosterKey returns the posterKey a user of the source gets when it's migrated
+func (s *repositorySyncer) remotePosterKey(externalID int64) string {
+	userID, ok := s.uploader.userMap[externalID]
+	tp := s.uploader.gitServiceType.Name()
+	if !ok && tp != "" {
+		var err error
+		userID, err = models.GetUserIDByExternalUserID(tp, fmt.Sprintf("%v", externalID))
+		if err != nil {
+			log.Error("GetUserIDByExternalUserID: %v", err)
+		}
+		if userID > 0 {
+			s.uploader.userMap[externalID] = userID
+		}
+	}
+	if userID > 0 {
+		return posterKey(userID, 0)
+	}
+	return posterKey(s.uploader.doer.ID, externalID)
+}
+
+func (s *repositorySyncer) syncComments(issues []syncedIssue) error {
+	if !s.opts.Comments {
+		return nil
+	}
+
+	for _, issue := range issues {
+		comments, err := s.downloader.GetComments(issue.number)
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing comments is not supported, ignored")
+			return nil
+		}
+
+		existing := make(map[string]*models.Comment)
+		if issue.issueID > 0 {
+			cms, err := models.FindComments(models.FindCommentsOptions{
+				IssueID: issue.issueID,
+				Type:    models.CommentTypeComment,
+			})
+			if err != nil {
+				return err
+			}
+			for _, cm := range cms {
+				existing[fmt.Sprintf("%s/%d", posterKey(cm.PosterID, cm.OriginalAuthorID), cm.CreatedUnix)] = cm
+			}
+		}
+
+		var newComments = make([]*base.Comment, 0, len(comments))
+		for _, comment := range comments {
+			comment.IssueIndex = issue.index
+
+			cm, ok := existing[fmt.Sprintf("%s/%d", s.remotePosterKey(comment.PosterID), comment.Created.Unix())]
+			if !ok {
+				newComments = append(newComments, comment)
+				continue
+			}
+			if cm.Content == comment.Content {
+				continue
+			}
+			cm.Content = comment.Content
+			cm.UpdatedUnix = timeutil.TimeStamp(comment.Updated.Unix())
+			if err := models.UpdateMigratedComment(cm); err != nil {
+				return err
+			}
+		}
+
+		if len(newComments) > 0 {
+			if err := s.uploader.CreateComments(newComments...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *repositorySyncer) syncReviews(issues []syncedIssue) error {
+	if !s.opts.Comments {
+		return nil
+	}
+
+	for _, issue := range issues {
+		reviews, err := s.downloader.GetReviews(issue.foreignIndex)
+		if err != nil {
+			if !base.IsErrNotSupported(err) {
+				return err
+			}
+			log.Warn("syncing reviews is not supported, ignored")
+			return nil
+		}
+
+		existing := make(map[string]bool)
+		if issue.issueID > 0 {
+			rvs, err := models.FindReviews(models.FindReviewOptions{
+				Type:    models.ReviewTypeUnknown,
+				IssueID: issue.issueID,
+			})
+			if err != nil {
+				return err
+			}
+			for _, rv := range rvs {
+				existing[fmt.Sprintf("%s/%d", posterKey(rv.ReviewerID, rv.OriginalAuthorID), rv.CreatedUnix)] = true
+			}
+		}
+
+		// reviews are only added, they can't be edited on most services
+		var newReviews = make([]*base.Review, 0, len(reviews))
+		for _, review := range reviews {
+			review.IssueIndex = issue.index
+			if !existing[fmt.Sprintf("%s/%d", s.remotePosterKey(review.ReviewerID), review.CreatedAt.Unix())] {
+				newReviews = append(newReviews, review)
+			}
+		}
+
+		if len(newReviews) > 0 {
+			if err := s.uploader.CreateReviews(newReviews...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncTestDownloader serves a fixed set of milestones, labels, issues and comments
+type syncTestDownloader struct {
+	base.NullDownloader
+	milestones []*base.Milestone
+	labels     []*base.Label
+	issues     []*base.Issue
+	comments   map[int64][]*base.Comment
+}
+
+func (d *syncTestDownloader) GetMilestones() ([]*base.Milestone, error) {
+	return d.milestones, nil
+}
+
+func (d *syncTestDownloader) GetLabels() ([]*base.Label, error) {
+	return d.labels, nil
+}
+
+func (d *syncTestDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	issues := make([]*base.Issue, 0, len(d.issues))
+	for _, issue := range d.issues {
+		copied := *issue
+		issues = append(issues, &copied)
+	}
+	return issues, true, nil
+}
+
+func (d *syncTestDownloader) GetComments(issueNumber int64) ([]*base.Comment, error) {
+	comments := make([]*base.Comment, 0, len(d.comments[issueNumber]))
+	for _, comment := range d.comments[issueNumber] {
+		copied := *comment
+		comments = append(comments, &copied)
+	}
+	return comments, nil
+}
+
+func TestSyncRepository(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	created := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	downloader := &syncTestDownloader{
+		milestones: []*base.Milestone{{Title: "milestone1", Description: "changed content", State: "open"}},
+		labels:     []*base.Label{{Name: "label1", Color: "abcdef"}, {Name: "label3", Color: "123456"}},
+		issues: []*base.Issue{{
+			Number:     1,
+			PosterID:   10,
+			PosterName: "remote-user",
+			Title:      "remote issue",
+			Content:    "remote content",
+			State:      "open",
+			Milestone:  "milestone1",
+			Created:    created,
+			Updated:    created,
+			Labels:     []*base.Label{{Name: "label1"}},
+		}},
+		comments: map[int64][]*base.Comment{1: {{
+			IssueIndex: 1,
+			PosterID:   10,
+			PosterName: "remote-user",
+			Content:    "first comment",
+			Created:    created,
+			Updated:    created,
+		}}},
+	}
+	opts := base.MigrateOptions{
+		GitServiceType: structs.GiteaService,
+		Milestones:     true,
+		Labels:         true,
+		Issues:         true,
+		Comments:       true,
+		Incremental:    true,
+	}
+
+	doSync := func(since time.Time) {
+		uploader := NewGiteaLocalUploader(context.Background(), doer, repo.OwnerName, repo.Name)
+		uploader.repo = repo
+		uploader.gitServiceType = opts.GitServiceType
+		var err error
+		uploader.gitRepo, err = git.OpenRepository(repo.RepoPath())
+		assert.NoError(t, err)
+		defer uploader.Close()
+		assert.NoError(t, syncRepository(downloader, uploader, opts, since))
+	}
+
+	// the index of the issue on the source is taken locally, the issue gets the next one
+	doSync(time.Time{})
+
+	issue, err := models.GetIssueByForeignIndex(repo.ID, false, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, issue.Index)
+	assert.Equal(t, "remote issue", issue.Title)
+	assert.Equal(t, "remote-user", issue.OriginalAuthor)
+	assert.EqualValues(t, 1, issue.NumComments)
+	assert.NoError(t, issue.LoadLabels())
+	assert.Len(t, issue.Labels, 1)
+
+	milestone := models.AssertExistsAndLoadBean(t, &models.Milestone{ID: 1}).(*models.Milestone)
+	assert.Equal(t, "changed content", milestone.Content)
+	assert.EqualValues(t, issue.MilestoneID, milestone.ID)
+	assert.EqualValues(t, 2, milestone.NumIssues)
+	models.AssertExistsAndLoadBean(t, &models.Label{RepoID: repo.ID, Name: "label3"})
+
+	before := models.AssertExistsAndLoadBean(t, &models.Repository{ID: repo.ID}).(*models.Repository)
+
+	// the changes on the source are applied to the same issue and comment
+	updated := created.Add(time.Hour)
+	downloader.issues[0].Title = "renamed remote issue"
+	downloader.issues[0].State = "closed"
+	downloader.issues[0].Closed = &updated
+	downloader.issues[0].Updated = updated
+	downloader.comments[1][0].Content = "edited comment"
+	downloader.comments[1] = append(downloader.comments[1], &base.Comment{
+		IssueIndex: 1,
+		PosterID:   11,
+		PosterName: "other-remote-user",
+		Content:    "second comment",
+		Created:    updated,
+		Updated:    updated,
+	})
+	doSync(created)
+
+	issue = models.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID}).(*models.Issue)
+	assert.Equal(t, "renamed remote issue", issue.Title)
+	assert.True(t, issue.IsClosed)
+	assert.EqualValues(t, 2, issue.NumComments)
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: issue.ID, Content: "edited comment"})
+	models.AssertNotExistsBean(t, &models.Issue{RepoID: repo.ID, Index: 7})
+
+	after := models.AssertExistsAndLoadBean(t, &models.Repository{ID: repo.ID}).(*models.Repository)
+	assert.Equal(t, before.NumIssues, after.NumIssues)
+	assert.Equal(t, before.NumClosedIssues+1, after.NumClosedIssues)
+	label := models.AssertExistsAndLoadBean(t, &models.Label{ID: 1}).(*models.Label)
+	assert.EqualValues(t, 1, label.NumClosedIssues)
+
+	// issues which haven't been updated on the source since the last sync are left as they are
+	downloader.issues[0].Title = "not synced"
+	doSync(updated)
+
+	issue = models.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID}).(*models.Issue)
+	assert.Equal(t, "renamed remote issue", issue.Title)
+}
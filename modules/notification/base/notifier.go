@@ -59,4 +59,18 @@ type Notifier interface {
 	NotifySyncDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
 
 	NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository)
+
+	NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string)
+	NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string)
+	NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string)
+
+	NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus)
+
+	NotifyMilestoneChangeStatus(doer *models.User, repo *models.Repository, milestone *models.Milestone)
+
+	NotifyMoveProjectIssue(doer *models.User, issue *models.Issue, project *models.Project, oldBoardID int64, board *models.ProjectBoard)
+
+	NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch)
+	NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch)
+	NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch)
 }
@@ -170,3 +170,39 @@ func (*NullNotifier) NotifySyncDeleteRef(doer *models.User, repo *models.Reposit
 // NotifyRepoPendingTransfer places a place holder function
 func (*NullNotifier) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
 }
+
+// NotifyNewWikiPage places a place holder function
+func (*NullNotifier) NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+}
+
+// NotifyEditWikiPage places a place holder function
+func (*NullNotifier) NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+}
+
+// NotifyDeleteWikiPage places a place holder function
+func (*NullNotifier) NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+}
+
+// NotifyCreateCommitStatus places a place holder function
+func (*NullNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+}
+
+// NotifyMilestoneChangeStatus places a place holder function
+func (*NullNotifier) NotifyMilestoneChangeStatus(doer *models.User, repo *models.Repository, milestone *models.Milestone) {
+}
+
+// NotifyMoveProjectIssue places a place holder function
+func (*NullNotifier) NotifyMoveProjectIssue(doer *models.User, issue *models.Issue, project *models.Project, oldBoardID int64, board *models.ProjectBoard) {
+}
+
+// NotifyCreateBranchProtection places a place holder function
+func (*NullNotifier) NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+}
+
+// NotifyUpdateBranchProtection places a place holder function
+func (*NullNotifier) NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+}
+
+// NotifyDeleteBranchProtection places a place holder function
+func (*NullNotifier) NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+}
@@ -5,6 +5,8 @@
 package indexer
 
 import (
+	"strings"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
@@ -29,19 +31,28 @@ func NewNotifier() base.Notifier {
 	return &indexerNotifier{}
 }
 
+// updateIssueIndexer indexes the issue with its searchable comments, which are loaded into a
+// copy of the issue as the notified issue may hold other comments
+func updateIssueIndexer(issue *models.Issue) {
+	searchable := *issue
+	searchable.Comments = nil
+	if err := searchable.LoadSearchableComments(); err != nil {
+		log.Error("LoadSearchableComments failed: %v", err)
+		return
+	}
+	issue_indexer.UpdateIssueIndexer(&searchable)
+}
+
+// isSearchableComment returns whether the comment is indexed with its issue
+func isSearchableComment(comment *models.Comment) bool {
+	return comment.Type == models.CommentTypeComment || comment.Type == models.CommentTypeCode ||
+		comment.Type == models.CommentTypeReview
+}
+
 func (r *indexerNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment, mentions []*models.User) {
-	if comment.Type == models.CommentTypeComment {
-		if issue.Comments == nil {
-			if err := issue.LoadDiscussComments(); err != nil {
-				log.Error("LoadComments failed: %v", err)
-				return
-			}
-		} else {
-			issue.Comments = append(issue.Comments, comment)
-		}
-
-		issue_indexer.UpdateIssueIndexer(issue)
+	if isSearchableComment(comment) {
+		updateIssueIndexer(issue)
 	}
 }
 
@@ -53,56 +64,33 @@ func (r *indexerNotifier) NotifyNewPullRequest(pr *models.PullRequest, mentions
 	issue_indexer.UpdateIssueIndexer(pr.Issue)
 }
 
-func (r *indexerNotifier) NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
-	if c.Type == models.CommentTypeComment {
-		var found bool
-		if c.Issue.Comments != nil {
-			for i := 0; i < len(c.Issue.Comments); i++ {
-				if c.Issue.Comments[i].ID == c.ID {
-					c.Issue.Comments[i] = c
-					found = true
-					break
-				}
-			}
-		}
+func (r *indexerNotifier) NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User) {
+	// submitting a review publishes its code comments
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	updateIssueIndexer(pr.Issue)
+}
 
-		if !found {
-			if err := c.Issue.LoadDiscussComments(); err != nil {
-				log.Error("LoadComments failed: %v", err)
-				return
-			}
+func (r *indexerNotifier) NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
+	if isSearchableComment(c) {
+		if err := c.LoadIssue(); err != nil {
+			log.Error("LoadIssue: %v", err)
+			return
 		}
-
-		issue_indexer.UpdateIssueIndexer(c.Issue)
+		updateIssueIndexer(c.Issue)
 	}
 }
 
 func (r *indexerNotifier) NotifyDeleteComment(doer *models.User, comment *models.Comment) {
-	if comment.Type == models.CommentTypeComment {
+	if isSearchableComment(comment) {
 		if err := comment.LoadIssue(); err != nil {
 			log.Error("LoadIssue: %v", err)
 			return
 		}
-
-		var found bool
-		if comment.Issue.Comments != nil {
-			for i := 0; i < len(comment.Issue.Comments); i++ {
-				if comment.Issue.Comments[i].ID == comment.ID {
-					comment.Issue.Comments = append(comment.Issue.Comments[:i], comment.Issue.Comments[i+1:]...)
-					found = true
-					break
-				}
-			}
-		}
-
-		if !found {
-			if err := comment.Issue.LoadDiscussComments(); err != nil {
-				log.Error("LoadComments failed: %v", err)
-				return
-			}
-		}
-		// reload comments to delete the old comment
-		issue_indexer.UpdateIssueIndexer(comment.Issue)
+		// the comment is already deleted, so reloading the comments drops it from the indexer
+		updateIssueIndexer(comment.Issue)
 	}
 }
 
@@ -123,8 +111,17 @@ func (r *indexerNotifier) NotifyMigrateRepository(doer *models.User, u *models.U
 	}
 }
 
+// isCodeIndexerRef returns whether the entries of a ref are kept in the code indexer
+func isCodeIndexerRef(repo *models.Repository, refFullName string) bool {
+	if !setting.Indexer.RepoIndexerEnabled || !strings.HasPrefix(refFullName, git.BranchPrefix) {
+		return false
+	}
+	branch := strings.TrimPrefix(refFullName, git.BranchPrefix)
+	return branch == repo.DefaultBranch || code_indexer.IsIndexedBranch(repo, branch)
+}
+
 func (r *indexerNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
-	if setting.Indexer.RepoIndexerEnabled && opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
+	if isCodeIndexerRef(repo, opts.RefFullName) {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
 	if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
@@ -133,7 +130,7 @@ func (r *indexerNotifier) NotifyPushCommits(pusher *models.User, repo *models.Re
 }
 
 func (r *indexerNotifier) NotifySyncPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
-	if setting.Indexer.RepoIndexerEnabled && opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
+	if isCodeIndexerRef(repo, opts.RefFullName) {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
 	if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
@@ -141,6 +138,18 @@ func (r *indexerNotifier) NotifySyncPushCommits(pusher *models.User, repo *model
 	}
 }
 
+func (r *indexerNotifier) NotifyDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string) {
+	if isCodeIndexerRef(repo, refFullName) {
+		code_indexer.UpdateRepoIndexer(repo)
+	}
+}
+
+func (r *indexerNotifier) NotifySyncDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string) {
+	if isCodeIndexerRef(repo, refFullName) {
+		code_indexer.UpdateRepoIndexer(repo)
+	}
+}
+
 func (r *indexerNotifier) NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
 	issue_indexer.UpdateIssueIndexer(issue)
 }
@@ -297,3 +297,66 @@ func NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Reposit
 		notifier.NotifyRepoPendingTransfer(doer, newOwner, repo)
 	}
 }
+
+// NotifyNewWikiPage notifies new wiki page to notifiers
+func NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyEditWikiPage notifies edit wiki page to notifiers
+func NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyEditWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyDeleteWikiPage notifies delete wiki page to notifiers
+func NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteWikiPage(doer, repo, page)
+	}
+}
+
+// NotifyCreateCommitStatus notifies new commit status to notifiers
+func NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(doer, repo, sha, status)
+	}
+}
+
+// NotifyMilestoneChangeStatus notifies milestone opened or closed to notifiers
+func NotifyMilestoneChangeStatus(doer *models.User, repo *models.Repository, milestone *models.Milestone) {
+	for _, notifier := range notifiers {
+		notifier.NotifyMilestoneChangeStatus(doer, repo, milestone)
+	}
+}
+
+// NotifyMoveProjectIssue notifies issue moved on a project board to notifiers
+func NotifyMoveProjectIssue(doer *models.User, issue *models.Issue, project *models.Project, oldBoardID int64, board *models.ProjectBoard) {
+	for _, notifier := range notifiers {
+		notifier.NotifyMoveProjectIssue(doer, issue, project, oldBoardID, board)
+	}
+}
+
+// NotifyCreateBranchProtection notifies new branch protection to notifiers
+func NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateBranchProtection(doer, repo, protectBranch)
+	}
+}
+
+// NotifyUpdateBranchProtection notifies update branch protection to notifiers
+func NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateBranchProtection(doer, repo, protectBranch)
+	}
+}
+
+// NotifyDeleteBranchProtection notifies delete branch protection to notifiers
+func NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteBranchProtection(doer, repo, protectBranch)
+	}
+}
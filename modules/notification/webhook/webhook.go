@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	webhook_services "code.gitea.io/gitea/services/webhook"
+	wiki_service "code.gitea.io/gitea/services/wiki"
 )
 
 type webhookNotifier struct {
@@ -817,3 +818,106 @@ func (m *webhookNotifier) NotifySyncCreateRef(pusher *models.User, repo *models.
 func (m *webhookNotifier) NotifySyncDeleteRef(pusher *models.User, repo *models.Repository, refType, refFullName string) {
 	m.NotifyDeleteRef(pusher, repo, refType, refFullName)
 }
+
+func sendWikiHook(doer *models.User, repo *models.Repository, action api.HookWikiAction, page, comment string) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventWiki, &api.WikiPayload{
+		Action:     action,
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+		Page:       page,
+		PageURL:    repo.HTMLURL() + "/wiki/" + wiki_service.NameToSubURL(page),
+		Comment:    comment,
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiCreated, page, comment)
+}
+
+func (m *webhookNotifier) NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiEdited, page, comment)
+}
+
+func (m *webhookNotifier) NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+	sendWikiHook(doer, repo, api.HookWikiDeleted, page, "")
+}
+
+func (m *webhookNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventStatus, &api.CommitStatusPayload{
+		SHA:        sha,
+		Status:     convert.ToCommitStatus(status),
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d, sha: %s]: %v", repo.ID, sha, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyMilestoneChangeStatus(doer *models.User, repo *models.Repository, milestone *models.Milestone) {
+	action := api.HookMilestoneOpened
+	if milestone.IsClosed {
+		action = api.HookMilestoneClosed
+	}
+
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventMilestone, &api.MilestonePayload{
+		Action:     action,
+		Milestone:  convert.ToAPIMilestone(milestone),
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [milestone_id: %d]: %v", milestone.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyMoveProjectIssue(doer *models.User, issue *models.Issue, project *models.Project, oldBoardID int64, board *models.ProjectBoard) {
+	if err := issue.LoadRepo(); err != nil {
+		log.Error("LoadRepo: %v", err)
+		return
+	}
+	if err := project.LoadCreator(); err != nil {
+		log.Error("LoadCreator: %v", err)
+		return
+	}
+
+	mode, _ := models.AccessLevel(doer, issue.Repo)
+	if err := webhook_services.PrepareWebhooks(issue.Repo, models.HookEventProjectCard, &api.ProjectCardPayload{
+		Action:      api.HookProjectCardMoved,
+		Project:     convert.ToAPIProject(project),
+		FromBoardID: oldBoardID,
+		Board:       convert.ToAPIProjectBoard(board),
+		Issue:       convert.ToAPIIssue(issue),
+		Repository:  convert.ToRepo(issue.Repo, mode),
+		Sender:      convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [issue_id: %d]: %v", issue.ID, err)
+	}
+}
+
+func sendBranchProtectionHook(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch, action api.HookBranchProtectionAction) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventBranchProtection, &api.BranchProtectionPayload{
+		Action:           action,
+		BranchProtection: convert.ToBranchProtection(protectBranch),
+		Repository:       convert.ToRepo(repo, mode),
+		Sender:           convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d, branch: %s]: %v", repo.ID, protectBranch.BranchName, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	sendBranchProtectionHook(doer, repo, protectBranch, api.HookBranchProtectionCreated)
+}
+
+func (m *webhookNotifier) NotifyUpdateBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	sendBranchProtectionHook(doer, repo, protectBranch, api.HookBranchProtectionEdited)
+}
+
+func (m *webhookNotifier) NotifyDeleteBranchProtection(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	sendBranchProtectionHook(doer, repo, protectBranch, api.HookBranchProtectionDeleted)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Media types of the manifests supported by the registry
+const (
+	ContentTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	ContentTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	ContentTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	ContentTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// DigestPrefixSHA256 is the prefix of sha256 digests
+const DigestPrefixSHA256 = "sha256:"
+
+const maxManifestSize = 10 * 1024 * 1024
+
+var (
+	// ErrInvalidManifest indicates an invalid manifest
+	ErrInvalidManifest = errors.New("The manifest is invalid")
+
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-manifests
+	imageNamePattern = regexp.MustCompile(`\A[a-z0-9]+(?:[._-][a-z0-9]+)*\z`)
+	tagPattern       = regexp.MustCompile(`\A[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}\z`)
+	digestPattern    = regexp.MustCompile(`\Asha256:[a-f0-9]{64}\z`)
+)
+
+// Metadata is stored for every manifest version
+type Metadata struct {
+	MediaType string `json:"media_type"`
+	Digest    string `json:"digest"`
+}
+
+// Descriptor references a blob or a manifest
+type Descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Manifest is the common part of image manifests and manifest lists (indexes)
+type Manifest struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	MediaType     string        `json:"mediaType"`
+	Config        *Descriptor   `json:"config"`
+	Layers        []*Descriptor `json:"layers"`
+	Manifests     []*Descriptor `json:"manifests"`
+}
+
+// IsIndex returns true if the manifest references other manifests
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == ContentTypeOCIIndex || m.MediaType == ContentTypeDockerManifestList
+}
+
+// BlobDigests returns the digests of all blobs referenced by an image manifest
+func (m *Manifest) BlobDigests() []string {
+	digests := make([]string, 0, len(m.Layers)+1)
+	if m.Config != nil {
+		digests = append(digests, m.Config.Digest)
+	}
+	for _, l := range m.Layers {
+		digests = append(digests, l.Digest)
+	}
+	return digests
+}
+
+// ParseManifest parses an image manifest or an image index.
+// The media type of the request is used if the document does not contain it.
+func ParseManifest(r io.Reader, contentType string) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(io.LimitReader(r, maxManifestSize)).Decode(&m); err != nil {
+		return nil, ErrInvalidManifest
+	}
+	if m.SchemaVersion != 2 {
+		return nil, ErrInvalidManifest
+	}
+	if m.MediaType == "" {
+		m.MediaType = contentType
+	}
+
+	switch m.MediaType {
+	case ContentTypeOCIManifest, ContentTypeDockerManifest:
+		if m.Config == nil || !IsValidDigest(m.Config.Digest) {
+			return nil, ErrInvalidManifest
+		}
+		for _, l := range m.Layers {
+			if !IsValidDigest(l.Digest) {
+				return nil, ErrInvalidManifest
+			}
+		}
+	case ContentTypeOCIIndex, ContentTypeDockerManifestList:
+		for _, d := range m.Manifests {
+			if !IsValidDigest(d.Digest) {
+				return nil, ErrInvalidManifest
+			}
+		}
+	default:
+		return nil, ErrInvalidManifest
+	}
+	return &m, nil
+}
+
+// IsValidImageName checks if the name is a valid image name
+func IsValidImageName(name string) bool {
+	return imageNamePattern.MatchString(name)
+}
+
+// IsValidTag checks if the reference is a valid tag
+func IsValidTag(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// IsValidDigest checks if the reference is a valid sha256 digest
+func IsValidDigest(digest string) bool {
+	return digestPattern.MatchString(digest)
+}
+
+// DigestToHash returns the hex encoded hash of a sha256 digest
+func DigestToHash(digest string) string {
+	return strings.TrimPrefix(digest, DigestPrefixSHA256)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	configDigest = "sha256:4607e093bec406eaadb6f3a340f63400c9d3a7038680744bd19f2f66f7fa3d8d"
+	layerDigest  = "sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest := `{"schemaVersion":2,"mediaType":"` + ContentTypeDockerManifest + `","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"` + configDigest + `","size":1},"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"` + layerDigest + `","size":2}]}`
+
+	m, err := ParseManifest(strings.NewReader(manifest), "")
+	assert.NoError(t, err)
+	assert.False(t, m.IsIndex())
+	assert.Equal(t, []string{configDigest, layerDigest}, m.BlobDigests())
+
+	// OCI manifests may omit the media type
+	m, err = ParseManifest(strings.NewReader(`{"schemaVersion":2,"config":{"digest":"`+configDigest+`"},"layers":[]}`), ContentTypeOCIManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentTypeOCIManifest, m.MediaType)
+
+	m, err = ParseManifest(strings.NewReader(`{"schemaVersion":2,"mediaType":"`+ContentTypeOCIIndex+`","manifests":[{"digest":"`+configDigest+`"}]}`), "")
+	assert.NoError(t, err)
+	assert.True(t, m.IsIndex())
+
+	_, err = ParseManifest(strings.NewReader(`{"schemaVersion":1}`), ContentTypeOCIManifest)
+	assert.Equal(t, ErrInvalidManifest, err)
+	_, err = ParseManifest(strings.NewReader(`{"schemaVersion":2,"config":{"digest":"invalid"}}`), ContentTypeOCIManifest)
+	assert.Equal(t, ErrInvalidManifest, err)
+}
+
+func TestValidation(t *testing.T) {
+	assert.True(t, IsValidImageName("test-image"))
+	assert.True(t, IsValidImageName("test.image_1"))
+	assert.False(t, IsValidImageName("Test"))
+	assert.False(t, IsValidImageName("-test"))
+
+	assert.True(t, IsValidTag("latest"))
+	assert.True(t, IsValidTag("v1.0.0-rc1"))
+	assert.False(t, IsValidTag(".hidden"))
+
+	assert.True(t, IsValidDigest(configDigest))
+	assert.False(t, IsValidDigest("sha256:1234"))
+	assert.Equal(t, "4607e093bec406eaadb6f3a340f63400c9d3a7038680744bd19f2f66f7fa3d8d", DigestToHash(configDigest))
+}
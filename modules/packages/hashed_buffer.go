@@ -0,0 +1,103 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// HashedBuffer is a temporary file which stores the uploaded content and
+// calculates the hashes needed to store it as a package blob while it is written.
+type HashedBuffer struct {
+	file *os.File
+	size int64
+
+	md5    hash.Hash
+	sha1   hash.Hash
+	sha256 hash.Hash
+	sha512 hash.Hash
+}
+
+// NewHashedBuffer creates a new, empty HashedBuffer
+func NewHashedBuffer() (*HashedBuffer, error) {
+	file, err := ioutil.TempFile("", "gitea-package-")
+	if err != nil {
+		return nil, err
+	}
+	return &HashedBuffer{
+		file:   file,
+		md5:    md5.New(),
+		sha1:   sha1.New(),
+		sha256: sha256.New(),
+		sha512: sha512.New(),
+	}, nil
+}
+
+// CreateHashedBufferFromReader creates a HashedBuffer with the content of the reader
+func CreateHashedBufferFromReader(r io.Reader) (*HashedBuffer, error) {
+	buf, err := NewHashedBuffer()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(buf, r); err != nil {
+		_ = buf.Close()
+		return nil, err
+	}
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		_ = buf.Close()
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Write implements io.Writer
+func (b *HashedBuffer) Write(p []byte) (int, error) {
+	n, err := b.file.Write(p)
+	b.size += int64(n)
+	for _, h := range []hash.Hash{b.md5, b.sha1, b.sha256, b.sha512} {
+		_, _ = h.Write(p[:n])
+	}
+	return n, err
+}
+
+// Read implements io.Reader
+func (b *HashedBuffer) Read(p []byte) (int, error) {
+	return b.file.Read(p)
+}
+
+// Seek implements io.Seeker
+func (b *HashedBuffer) Seek(offset int64, whence int) (int64, error) {
+	return b.file.Seek(offset, whence)
+}
+
+// Size returns the number of bytes written to the buffer
+func (b *HashedBuffer) Size() int64 {
+	return b.size
+}
+
+// Sums returns the hex encoded MD5, SHA1, SHA256 and SHA512 hashes of the content
+func (b *HashedBuffer) Sums() (hashMD5, hashSHA1, hashSHA256, hashSHA512 string) {
+	return hex.EncodeToString(b.md5.Sum(nil)),
+		hex.EncodeToString(b.sha1.Sum(nil)),
+		hex.EncodeToString(b.sha256.Sum(nil)),
+		hex.EncodeToString(b.sha512.Sum(nil))
+}
+
+// Close closes and removes the temporary file
+func (b *HashedBuffer) Close() error {
+	err := b.file.Close()
+	if rerr := os.Remove(b.file.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashedBuffer(t *testing.T) {
+	buf, err := CreateHashedBufferFromReader(strings.NewReader("test"))
+	assert.NoError(t, err)
+	defer buf.Close()
+
+	assert.EqualValues(t, 4, buf.Size())
+
+	hashMD5, hashSHA1, hashSHA256, hashSHA512 := buf.Sums()
+	assert.Equal(t, "098f6bcd4621d373cade4e832627b4f6", hashMD5)
+	assert.Equal(t, "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3", hashSHA1)
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", hashSHA256)
+	assert.Equal(t, "ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff", hashSHA512)
+
+	content, err := ioutil.ReadAll(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", string(content))
+}
+
+func TestKeyToRelativePath(t *testing.T) {
+	assert.Equal(t, "9f/86/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		KeyToRelativePath("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"))
+}
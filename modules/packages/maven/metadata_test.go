@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maven
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const pomContent = `<?xml version="1.0"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <groupId>org.gitea</groupId>
+  </parent>
+  <artifactId>gitea-test</artifactId>
+  <version>1.0.0</version>
+  <name>Gitea Test</name>
+  <description>Test package</description>
+  <url>https://gitea.io</url>
+  <licenses>
+    <license>
+      <name>MIT</name>
+    </license>
+  </licenses>
+  <dependencies>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>4.13</version>
+    </dependency>
+  </dependencies>
+</project>`
+
+func TestParsePackageMetaData(t *testing.T) {
+	m, err := ParsePackageMetaData(strings.NewReader(pomContent))
+	assert.NoError(t, err)
+	assert.Equal(t, "org.gitea", m.GroupID)
+	assert.Equal(t, "gitea-test", m.ArtifactID)
+	assert.Equal(t, "Gitea Test", m.Name)
+	assert.Equal(t, "Test package", m.Description)
+	assert.Equal(t, "https://gitea.io", m.ProjectURL)
+	assert.Equal(t, []string{"MIT"}, m.Licenses)
+	if assert.Len(t, m.Dependencies, 1) {
+		assert.Equal(t, "junit", m.Dependencies[0].ArtifactID)
+		assert.Equal(t, "4.13", m.Dependencies[0].Version)
+	}
+
+	_, err = ParsePackageMetaData(strings.NewReader("invalid"))
+	assert.Error(t, err)
+}
+
+func TestCreateMetadataResponse(t *testing.T) {
+	resp := CreateMetadataResponse("org.gitea", "gitea-test", []string{"1.0.0", "1.1.0", "1.2.0-SNAPSHOT"})
+	assert.Equal(t, "1.2.0-SNAPSHOT", resp.Latest)
+	assert.Equal(t, "1.1.0", resp.Release)
+
+	out, err := xml.Marshal(resp)
+	assert.NoError(t, err)
+	assert.Equal(t, `<metadata><groupId>org.gitea</groupId><artifactId>gitea-test</artifactId><versioning><release>1.1.0</release><latest>1.2.0-SNAPSHOT</latest><versions><version>1.0.0</version><version>1.1.0</version><version>1.2.0-SNAPSHOT</version></versions></versioning></metadata>`, string(out))
+}
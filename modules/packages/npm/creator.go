@@ -0,0 +1,224 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package npm
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+var (
+	// ErrInvalidPackage indicates an invalid package
+	ErrInvalidPackage = errors.New("The package is invalid")
+	// ErrInvalidPackageName indicates an invalid name
+	ErrInvalidPackageName = errors.New("The package name is invalid")
+	// ErrInvalidPackageVersion indicates an invalid version
+	ErrInvalidPackageVersion = errors.New("The package version is invalid")
+	// ErrInvalidAttachment indicates a invalid attachment
+	ErrInvalidAttachment = errors.New("The package attachment is invalid")
+	// ErrInvalidIntegrity indicates an integrity validation error
+	ErrInvalidIntegrity = errors.New("Failed to validate integrity")
+)
+
+var nameMatch = regexp.MustCompile(`\A((@[^\s\/~'!\(\)\*]+?)[\/])?([^_.][^\s\/~'!\(\)\*]+)\z`)
+
+// Package represents a npm package
+type Package struct {
+	Name     string
+	Version  string
+	Metadata Metadata
+	Filename string
+	Data     []byte
+}
+
+// Metadata represents the metadata of a npm package version
+type Metadata struct {
+	Scope                string            `json:"scope,omitempty"`
+	Name                 string            `json:"name"`
+	Description          string            `json:"description,omitempty"`
+	Author               string            `json:"author,omitempty"`
+	License              string            `json:"license,omitempty"`
+	ProjectURL           string            `json:"project_url,omitempty"`
+	Keywords             []string          `json:"keywords,omitempty"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string `json:"development_dependencies,omitempty"`
+	PeerDependencies     map[string]string `json:"peer_dependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optional_dependencies,omitempty"`
+	Readme               string            `json:"readme,omitempty"`
+	Integrity            string            `json:"integrity"`
+	Shasum               string            `json:"shasum"`
+}
+
+// packageUpload is the document sent by "npm publish"
+type packageUpload struct {
+	ID          string                              `json:"_id"`
+	Name        string                              `json:"name"`
+	Readme      string                              `json:"readme"`
+	Versions    map[string]*packageUploadVersion    `json:"versions"`
+	Attachments map[string]*packageUploadAttachment `json:"_attachments"`
+}
+
+type packageUploadVersion struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Description          string            `json:"description"`
+	Author               json.RawMessage   `json:"author"`
+	License              string            `json:"license"`
+	Homepage             string            `json:"homepage"`
+	Keywords             []string          `json:"keywords"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Readme               string            `json:"readme"`
+	Dist                 struct {
+		Integrity string `json:"integrity"`
+		Shasum    string `json:"shasum"`
+	} `json:"dist"`
+}
+
+type packageUploadAttachment struct {
+	ContentType string `json:"content_type"`
+	Data        string `json:"data"`
+}
+
+// ParsePackage parses the document sent by "npm publish".
+// It must contain exactly one version and the matching tarball attachment.
+func ParsePackage(r io.Reader) (*Package, error) {
+	var upload packageUpload
+	if err := json.NewDecoder(r).Decode(&upload); err != nil {
+		return nil, err
+	}
+
+	if !nameMatch.MatchString(upload.Name) {
+		return nil, ErrInvalidPackageName
+	}
+	if len(upload.Versions) != 1 || len(upload.Attachments) != 1 {
+		return nil, ErrInvalidPackage
+	}
+
+	var meta *packageUploadVersion
+	for _, v := range upload.Versions {
+		meta = v
+	}
+	if meta == nil || meta.Name != upload.Name {
+		return nil, ErrInvalidPackage
+	}
+	if _, err := version.NewSemver(meta.Version); err != nil {
+		return nil, ErrInvalidPackageVersion
+	}
+
+	var filename string
+	var attachment *packageUploadAttachment
+	for name, a := range upload.Attachments {
+		filename, attachment = name, a
+	}
+	if attachment == nil || len(attachment.Data) == 0 {
+		return nil, ErrInvalidAttachment
+	}
+
+	data, err := base64.StdEncoding.DecodeString(attachment.Data)
+	if err != nil {
+		return nil, ErrInvalidAttachment
+	}
+
+	if err := validateIntegrity(data, meta.Dist.Integrity, meta.Dist.Shasum); err != nil {
+		return nil, err
+	}
+
+	scope := ""
+	if idx := strings.Index(upload.Name, "/"); idx > 0 {
+		scope = upload.Name[1:idx]
+	}
+
+	readme := meta.Readme
+	if readme == "" {
+		readme = upload.Readme
+	}
+
+	return &Package{
+		Name:     upload.Name,
+		Version:  meta.Version,
+		Filename: filename,
+		Data:     data,
+		Metadata: Metadata{
+			Scope:                scope,
+			Name:                 upload.Name,
+			Description:          meta.Description,
+			Author:               parseAuthor(meta.Author),
+			License:              meta.License,
+			ProjectURL:           meta.Homepage,
+			Keywords:             meta.Keywords,
+			Dependencies:         meta.Dependencies,
+			DevDependencies:      meta.DevDependencies,
+			PeerDependencies:     meta.PeerDependencies,
+			OptionalDependencies: meta.OptionalDependencies,
+			Readme:               readme,
+			Integrity:            meta.Dist.Integrity,
+			Shasum:               meta.Dist.Shasum,
+		},
+	}, nil
+}
+
+// parseAuthor accepts the author as plain string or as {"name": ...} object
+func parseAuthor(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.Name
+	}
+	return ""
+}
+
+// validateIntegrity checks the tarball against the subresource integrity string and the sha1 shasum
+func validateIntegrity(data []byte, integrity, shasum string) error {
+	if integrity != "" {
+		parts := strings.SplitN(integrity, "-", 2)
+		if len(parts) != 2 {
+			return ErrInvalidIntegrity
+		}
+		expected, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return ErrInvalidIntegrity
+		}
+		var sum []byte
+		switch parts[0] {
+		case "sha512":
+			s := sha512.Sum512(data)
+			sum = s[:]
+		case "sha1":
+			s := sha1.Sum(data)
+			sum = s[:]
+		default:
+			// unknown algorithms are not validated
+			return nil
+		}
+		if !bytes.Equal(expected, sum) {
+			return ErrInvalidIntegrity
+		}
+	}
+	if shasum != "" && shasum != fmt.Sprintf("%x", sha1.Sum(data)) {
+		return ErrInvalidIntegrity
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package npm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePackage(t *testing.T) {
+	data := []byte("test")
+	// sha512 and sha1 of "test"
+	const integrity = "sha512-7iaw3Ur350mqGo7jwQrpkj9hiYB3Lkc/iBml1JQODbJ6wYX4oOHV+E+IvIh/1nsUNzLDBMxfqa2Ob1f1ACio/w=="
+	const shasum = "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3"
+
+	build := func(name, version, integrity string) *bytes.Buffer {
+		return bytes.NewBufferString(fmt.Sprintf(`{
+			"_id": %[1]q,
+			"name": %[1]q,
+			"versions": {
+				%[2]q: {
+					"name": %[1]q,
+					"version": %[2]q,
+					"description": "Test package",
+					"author": {"name": "Gitea Authors"},
+					"license": "MIT",
+					"dependencies": {"dep": "^1.0.0"},
+					"dist": {"integrity": %[3]q, "shasum": %[4]q}
+				}
+			},
+			"_attachments": {
+				"package-1.0.0.tgz": {"content_type": "application/octet-stream", "data": %[5]q}
+			}
+		}`, name, version, integrity, shasum, base64.StdEncoding.EncodeToString(data)))
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParsePackage(build("@scope/package", "1.0.0", integrity))
+		assert.NoError(t, err)
+		assert.Equal(t, "@scope/package", p.Name)
+		assert.Equal(t, "1.0.0", p.Version)
+		assert.Equal(t, "package-1.0.0.tgz", p.Filename)
+		assert.Equal(t, data, p.Data)
+		assert.Equal(t, "scope", p.Metadata.Scope)
+		assert.Equal(t, "Gitea Authors", p.Metadata.Author)
+		assert.Equal(t, "MIT", p.Metadata.License)
+		assert.Equal(t, "^1.0.0", p.Metadata.Dependencies["dep"])
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		_, err := ParsePackage(build("_package", "1.0.0", integrity))
+		assert.Equal(t, ErrInvalidPackageName, err)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		_, err := ParsePackage(build("package", "1.x", integrity))
+		assert.Equal(t, ErrInvalidPackageVersion, err)
+	})
+
+	t.Run("InvalidIntegrity", func(t *testing.T) {
+		_, err := ParsePackage(build("package", "1.0.0", "sha512-AAAA"))
+		assert.Equal(t, ErrInvalidIntegrity, err)
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package npm
+
+// PackageMetadata is the package document returned to the npm client
+// https://github.com/npm/registry/blob/master/docs/REGISTRY-API.md#package
+type PackageMetadata struct {
+	ID          string                             `json:"_id"`
+	Name        string                             `json:"name"`
+	Description string                             `json:"description,omitempty"`
+	DistTags    map[string]string                  `json:"dist-tags,omitempty"`
+	Versions    map[string]*PackageMetadataVersion `json:"versions"`
+	Readme      string                             `json:"readme,omitempty"`
+	Time        map[string]string                  `json:"time,omitempty"`
+	Homepage    string                             `json:"homepage,omitempty"`
+	Keywords    []string                           `json:"keywords,omitempty"`
+	License     string                             `json:"license,omitempty"`
+}
+
+// PackageMetadataVersion is the metadata of a single version
+// https://github.com/npm/registry/blob/master/docs/REGISTRY-API.md#version
+type PackageMetadataVersion struct {
+	ID                   string              `json:"_id"`
+	Name                 string              `json:"name"`
+	Version              string              `json:"version"`
+	Description          string              `json:"description,omitempty"`
+	Author               string              `json:"author,omitempty"`
+	Homepage             string              `json:"homepage,omitempty"`
+	License              string              `json:"license,omitempty"`
+	Keywords             []string            `json:"keywords,omitempty"`
+	Dependencies         map[string]string   `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string   `json:"devDependencies,omitempty"`
+	PeerDependencies     map[string]string   `json:"peerDependencies,omitempty"`
+	OptionalDependencies map[string]string   `json:"optionalDependencies,omitempty"`
+	Readme               string              `json:"readme,omitempty"`
+	Dist                 PackageDistribution `json:"dist"`
+}
+
+// PackageDistribution describes the tarball of a version
+// https://github.com/npm/registry/blob/master/docs/REGISTRY-API.md#version
+type PackageDistribution struct {
+	Integrity string `json:"integrity"`
+	Shasum    string `json:"shasum"`
+	Tarball   string `json:"tarball"`
+}
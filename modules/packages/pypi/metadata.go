@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pypi
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	normalizer = strings.NewReplacer("_", "-", ".", "-")
+	// https://www.python.org/dev/peps/pep-0508/#names
+	nameMatcher = regexp.MustCompile(`\A(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9._\-]*[a-zA-Z0-9])\z`)
+	// https://www.python.org/dev/peps/pep-0440/#appendix-b-parsing-version-strings-with-regular-expressions
+	versionMatcher = regexp.MustCompile(`\Av?` +
+		`(?:[0-9]+!)?` + // epoch
+		`[0-9]+(?:\.[0-9]+)*` + // release segment
+		`(?:[-_\.]?(?:a|b|c|rc|alpha|beta|pre|preview)[-_\.]?[0-9]*)?` + // pre-release
+		`(?:-[0-9]+|[-_\.]?(?:post|rev|r)[-_\.]?[0-9]*)?` + // post release
+		`(?:[-_\.]?dev[-_\.]?[0-9]*)?` + // dev release
+		`(?:\+[a-z0-9]+(?:[-_\.][a-z0-9]+)*)?` + // local version
+		`\z`)
+	multiDash = regexp.MustCompile(`-+`)
+)
+
+// Metadata represents the metadata of a PyPI package
+type Metadata struct {
+	Author          string   `json:"author,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	LongDescription string   `json:"long_description,omitempty"`
+	Summary         string   `json:"summary,omitempty"`
+	ProjectURL      string   `json:"project_url,omitempty"`
+	License         string   `json:"license,omitempty"`
+	RequiresPython  string   `json:"requires_python,omitempty"`
+	Keywords        []string `json:"keywords,omitempty"`
+}
+
+// NormalizeName normalizes a package name as described in PEP 503
+func NormalizeName(name string) string {
+	return multiDash.ReplaceAllString(normalizer.Replace(strings.ToLower(name)), "-")
+}
+
+// IsValidName checks if the package name is valid
+func IsValidName(name string) bool {
+	return nameMatcher.MatchString(name)
+}
+
+// IsValidVersion checks if the version is a valid PEP 440 version
+func IsValidVersion(version string) bool {
+	return versionMatcher.MatchString(strings.ToLower(version))
+}
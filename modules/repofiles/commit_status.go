@@ -9,6 +9,9 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 // CreateCommitStatus creates a new CommitStatus given a bunch of parameters
@@ -37,5 +40,14 @@ func CreateCommitStatus(repo *models.Repository, creator *models.User, sha strin
 		return fmt.Errorf("NewCommitStatus[repo_id: %d, user_id: %d, sha: %s]: %v", repo.ID, creator.ID, sha, err)
 	}
 
+	notification.NotifyCreateCommitStatus(creator, repo, sha, status)
+
+	if err := pull_service.AddToAutoMergeQueueByCommitStatus(repo, sha); err != nil {
+		log.Error("AddToAutoMergeQueueByCommitStatus[repo_id: %d, sha: %s]: %v", repo.ID, sha, err)
+	}
+	if err := pull_service.AddToMergeQueueByCommitStatus(repo, sha); err != nil {
+		log.Error("AddToMergeQueueByCommitStatus[repo_id: %d, sha: %s]: %v", repo.ID, sha, err)
+	}
+
 	return nil
 }
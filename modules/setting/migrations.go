@@ -5,6 +5,7 @@
 package setting
 
 import (
+	"path/filepath"
 	"strings"
 )
 
@@ -16,9 +17,16 @@ var (
 		AllowedDomains     []string
 		BlockedDomains     []string
 		AllowLocalNetworks bool
+		DumpPath           string
+		MaxDumpSize        int64
+		MaxDumpContentSize int64
+		MaxDumpEntries     int
 	}{
-		MaxAttempts:  3,
-		RetryBackoff: 3,
+		MaxAttempts:        3,
+		RetryBackoff:       3,
+		MaxDumpSize:        1024,
+		MaxDumpContentSize: 4096,
+		MaxDumpEntries:     100000,
 	}
 )
 
@@ -37,4 +45,12 @@ func newMigrationsService() {
 	}
 
 	Migrations.AllowLocalNetworks = sec.Key("ALLOW_LOCALNETWORKS").MustBool(false)
+
+	Migrations.DumpPath = sec.Key("DUMP_PATH").MustString(filepath.Join(AppDataPath, "repo-dumps"))
+	if !filepath.IsAbs(Migrations.DumpPath) {
+		Migrations.DumpPath = filepath.Join(AppWorkPath, Migrations.DumpPath)
+	}
+	Migrations.MaxDumpSize = sec.Key("MAX_DUMP_SIZE").MustInt64(Migrations.MaxDumpSize)
+	Migrations.MaxDumpContentSize = sec.Key("MAX_DUMP_CONTENT_SIZE").MustInt64(Migrations.MaxDumpContentSize)
+	Migrations.MaxDumpEntries = sec.Key("MAX_DUMP_ENTRIES").MustInt(Migrations.MaxDumpEntries)
 }
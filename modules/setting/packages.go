@@ -0,0 +1,23 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+var (
+	// Packages settings
+	Packages = struct {
+		Storage
+		Enabled bool
+	}{
+		Enabled: true,
+	}
+)
+
+func newPackagesService() {
+	sec := Cfg.Section("packages")
+	Packages.Enabled = sec.Key("ENABLED").MustBool(true)
+
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+	Packages.Storage = getStorage("packages", storageType, sec)
+}
@@ -26,6 +26,14 @@ type Hook struct {
 	Config map[string]string `json:"config"`
 	Events []string          `json:"events"`
 	Active bool              `json:"active"`
+	// number of times a failed delivery is retried
+	MaxRetries int `json:"max_retries"`
+	// deactivate the hook once a delivery has failed after all retries
+	DisableAfterRetries bool `json:"disable_after_retries"`
+	// PEM encoded client certificate presented to receivers which require mutual TLS
+	ClientCertificate string `json:"client_certificate"`
+	// PEM encoded certificates of the CAs the receiver's certificate is verified against instead of the system's
+	CACertificates string `json:"ca_certificates"`
 	// swagger:strfmt date-time
 	Updated time.Time `json:"updated_at"`
 	// swagger:strfmt date-time
@@ -35,14 +43,37 @@ type Hook struct {
 // HookList represents a list of API hook.
 type HookList []*Hook
 
+// HookDelivery represents a delivery of a hook
+type HookDelivery struct {
+	ID    int64  `json:"id"`
+	UUID  string `json:"uuid"`
+	Event string `json:"event"`
+	URL   string `json:"url"`
+	// number of times delivery has been attempted
+	Attempts  int  `json:"attempts"`
+	IsSucceed bool `json:"is_succeed"`
+	// HTTP status of the last response, 0 if the receiver could not be reached
+	ResponseStatus int `json:"response_status"`
+	// swagger:strfmt date-time
+	Delivered time.Time `json:"delivered_at"`
+}
+
+// RedeliverHookOption options to redeliver failed deliveries of a hook
+type RedeliverHookOption struct {
+	// ids of the deliveries to redeliver, all failed deliveries are redelivered if empty
+	IDs []int64 `json:"ids"`
+}
+
 // CreateHookOptionConfig has all config options in it
 // required are "content_type" and "url" Required
+// "secret" is optional, and "signature_algorithm" is the HMAC algorithm of its signature, one of sha1, sha256 or sha512
+// custom hooks also take "template", "headers" and "http_method", their "content_type" is the media type of the body
 type CreateHookOptionConfig map[string]string
 
 // CreateHookOption options when create a hook
 type CreateHookOption struct {
 	// required: true
-	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu
+	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,custom
 	Type string `json:"type" binding:"Required"`
 	// required: true
 	Config       CreateHookOptionConfig `json:"config" binding:"Required"`
@@ -50,14 +81,33 @@ type CreateHookOption struct {
 	BranchFilter string                 `json:"branch_filter" binding:"GlobPattern"`
 	// default: false
 	Active bool `json:"active"`
+	// number of times a failed delivery is retried, at most 10
+	MaxRetries int `json:"max_retries" binding:"Range(0,10)"`
+	// deactivate the hook once a delivery has failed after all retries
+	DisableAfterRetries bool `json:"disable_after_retries"`
+	// value of the Authorization header sent with deliveries, it is stored encrypted and not returned
+	AuthorizationHeader string `json:"authorization_header"`
+	// PEM encoded client certificate presented to receivers which require mutual TLS
+	ClientCertificate string `json:"client_certificate"`
+	// PEM encoded private key of the client certificate, it is stored encrypted and not returned
+	ClientKey string `json:"client_key"`
+	// PEM encoded certificates of the CAs the receiver's certificate is verified against instead of the system's
+	CACertificates string `json:"ca_certificates"`
 }
 
 // EditHookOption options when modify one hook
 type EditHookOption struct {
-	Config       map[string]string `json:"config"`
-	Events       []string          `json:"events"`
-	BranchFilter string            `json:"branch_filter" binding:"GlobPattern"`
-	Active       *bool             `json:"active"`
+	Config              map[string]string `json:"config"`
+	Events              []string          `json:"events"`
+	BranchFilter        string            `json:"branch_filter" binding:"GlobPattern"`
+	Active              *bool             `json:"active"`
+	MaxRetries          *int              `json:"max_retries"`
+	DisableAfterRetries *bool             `json:"disable_after_retries"`
+	AuthorizationHeader *string           `json:"authorization_header"`
+	ClientCertificate   *string           `json:"client_certificate"`
+	// the current key is kept if it is empty and the client certificate is set
+	ClientKey      *string `json:"client_key"`
+	CACertificates *string `json:"ca_certificates"`
 }
 
 // Payloader payload is some part of one hook
@@ -493,3 +543,151 @@ func (p *RepositoryPayload) JSONPayload() ([]byte, error) {
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	return json.MarshalIndent(p, "", " ")
 }
+
+// HookWikiAction an action that happens to a wiki page
+type HookWikiAction string
+
+const (
+	// HookWikiCreated created
+	HookWikiCreated HookWikiAction = "created"
+	// HookWikiEdited edited
+	HookWikiEdited HookWikiAction = "edited"
+	// HookWikiDeleted deleted
+	HookWikiDeleted HookWikiAction = "deleted"
+)
+
+// WikiPayload payload for wiki webhooks
+type WikiPayload struct {
+	Secret     string         `json:"secret"`
+	Action     HookWikiAction `json:"action"`
+	Repository *Repository    `json:"repository"`
+	Sender     *User          `json:"sender"`
+	Page       string         `json:"page"`
+	PageURL    string         `json:"page_url"`
+	Comment    string         `json:"comment"`
+}
+
+// SetSecret modifies the secret of the WikiPayload
+func (p *WikiPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *WikiPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", " ")
+}
+
+// CommitStatusPayload payload for commit status webhooks
+type CommitStatusPayload struct {
+	Secret     string        `json:"secret"`
+	SHA        string        `json:"sha"`
+	Status     *CommitStatus `json:"status"`
+	Repository *Repository   `json:"repository"`
+	Sender     *User         `json:"sender"`
+}
+
+// SetSecret modifies the secret of the CommitStatusPayload
+func (p *CommitStatusPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *CommitStatusPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", " ")
+}
+
+// HookMilestoneAction an action that happens to a milestone
+type HookMilestoneAction string
+
+const (
+	// HookMilestoneOpened opened or reopened
+	HookMilestoneOpened HookMilestoneAction = "opened"
+	// HookMilestoneClosed closed
+	HookMilestoneClosed HookMilestoneAction = "closed"
+)
+
+// MilestonePayload payload for milestone webhooks
+type MilestonePayload struct {
+	Secret     string              `json:"secret"`
+	Action     HookMilestoneAction `json:"action"`
+	Milestone  *Milestone          `json:"milestone"`
+	Repository *Repository         `json:"repository"`
+	Sender     *User               `json:"sender"`
+}
+
+// SetSecret modifies the secret of the MilestonePayload
+func (p *MilestonePayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *MilestonePayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", " ")
+}
+
+// HookProjectCardAction an action that happens to an issue on a project board
+type HookProjectCardAction string
+
+const (
+	// HookProjectCardMoved moved to another board
+	HookProjectCardMoved HookProjectCardAction = "moved"
+)
+
+// ProjectCardPayload payload for project card webhooks
+type ProjectCardPayload struct {
+	Secret  string                `json:"secret"`
+	Action  HookProjectCardAction `json:"action"`
+	Project *Project              `json:"project"`
+	// the board the issue was on before, 0 for the default board
+	FromBoardID int64         `json:"from_board_id"`
+	Board       *ProjectBoard `json:"board"`
+	Issue       *Issue        `json:"issue"`
+	Repository  *Repository   `json:"repository"`
+	Sender      *User         `json:"sender"`
+}
+
+// SetSecret modifies the secret of the ProjectCardPayload
+func (p *ProjectCardPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *ProjectCardPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", " ")
+}
+
+// HookBranchProtectionAction an action that happens to a branch protection
+type HookBranchProtectionAction string
+
+const (
+	// HookBranchProtectionCreated created
+	HookBranchProtectionCreated HookBranchProtectionAction = "created"
+	// HookBranchProtectionEdited edited
+	HookBranchProtectionEdited HookBranchProtectionAction = "edited"
+	// HookBranchProtectionDeleted deleted
+	HookBranchProtectionDeleted HookBranchProtectionAction = "deleted"
+)
+
+// BranchProtectionPayload payload for branch protection webhooks
+type BranchProtectionPayload struct {
+	Secret           string                     `json:"secret"`
+	Action           HookBranchProtectionAction `json:"action"`
+	BranchProtection *BranchProtection          `json:"branch_protection"`
+	Repository       *Repository                `json:"repository"`
+	Sender           *User                      `json:"sender"`
+}
+
+// SetSecret modifies the secret of the BranchProtectionPayload
+func (p *BranchProtectionPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *BranchProtectionPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", " ")
+}
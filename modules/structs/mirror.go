@@ -0,0 +1,39 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// PushMirror represents information of a push mirror
+type PushMirror struct {
+	ID            int64  `json:"id"`
+	RepoName      string `json:"repo_name"`
+	RemoteName    string `json:"remote_name"`
+	RemoteAddress string `json:"remote_address"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created"`
+	// swagger:strfmt date-time
+	LastUpdate   *time.Time `json:"last_update"`
+	LastError    string     `json:"last_error"`
+	Interval     string     `json:"interval"`
+	SyncOnCommit bool       `json:"sync_on_commit"`
+}
+
+// CreatePushMirrorOption represents need information to create a push mirror of a repository.
+type CreatePushMirrorOption struct {
+	// required: true
+	RemoteAddress  string `json:"remote_address" binding:"Required"`
+	RemoteUsername string `json:"remote_username"`
+	RemotePassword string `json:"remote_password"`
+	// interval between two automatic syncs, e.g. "8h0m0s"; "0" disables them
+	Interval     string `json:"interval"`
+	SyncOnCommit bool   `json:"sync_on_commit"`
+}
+
+// EditPushMirrorOption represents need information to edit a push mirror of a repository.
+type EditPushMirrorOption struct {
+	Interval     *string `json:"interval"`
+	SyncOnCommit *bool   `json:"sync_on_commit"`
+}
@@ -4,12 +4,52 @@
 
 package structs
 
+import "time"
+
 // SearchResults results of a successful search
 type SearchResults struct {
 	OK   bool          `json:"ok"`
 	Data []*Repository `json:"data"`
 }
 
+// CodeSearchResults results of a successful code search
+type CodeSearchResults struct {
+	OK        bool                  `json:"ok"`
+	Data      []*CodeSearchResult   `json:"data"`
+	Languages []*CodeSearchLanguage `json:"languages"`
+}
+
+// CodeSearchResult a file matching a code search
+type CodeSearchResult struct {
+	RepoID       int64  `json:"repo_id"`
+	RepoFullName string `json:"repo_full_name"`
+	Branch       string `json:"branch"`
+	Path         string `json:"path"`
+	Language     string `json:"language"`
+	CommitID     string `json:"commit_id"`
+	HTMLURL      string `json:"html_url"`
+	// Lines the snippet around the match
+	Lines []*CodeSearchLine `json:"lines"`
+	// swagger:strfmt date-time
+	Indexed time.Time `json:"indexed_at"`
+}
+
+// CodeSearchLine a line of the snippet of a code search result
+type CodeSearchLine struct {
+	Number  int    `json:"number"`
+	Content string `json:"content"`
+	// HighlightStart and HighlightEnd are the offsets of the match within the line, both are zero if it isn't on the line
+	HighlightStart int `json:"highlight_start"`
+	HighlightEnd   int `json:"highlight_end"`
+}
+
+// CodeSearchLanguage number of code search results of a language
+type CodeSearchLanguage struct {
+	Language string `json:"language"`
+	Color    string `json:"color"`
+	Count    int    `json:"count"`
+}
+
 // SearchError error of a failed search
 type SearchError struct {
 	OK    bool   `json:"ok"`
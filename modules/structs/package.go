@@ -0,0 +1,42 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Package represents a package
+type Package struct {
+	ID         int64       `json:"id"`
+	Owner      *User       `json:"owner"`
+	Repository *Repository `json:"repository"`
+	Type       string      `json:"type"`
+	Name       string      `json:"name"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PackageVersion represents a version of a package
+type PackageVersion struct {
+	ID            int64  `json:"id"`
+	Creator       *User  `json:"creator"`
+	Version       string `json:"version"`
+	DownloadCount int64  `json:"download_count"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []*PackageFile `json:"files"`
+}
+
+// PackageFile represents a file of a package version
+type PackageFile struct {
+	ID         int64  `json:"id"`
+	Size       int64  `json:"size"`
+	Name       string `json:"name"`
+	HashMD5    string `json:"md5"`
+	HashSHA1   string `json:"sha1"`
+	HashSHA256 string `json:"sha256"`
+	HashSHA512 string `json:"sha512"`
+}
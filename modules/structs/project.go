@@ -0,0 +1,87 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Project represents a project with boards to organize issues and pull requests
+type Project struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// enum: none,basic_kanban,bug_triage
+	BoardType    string    `json:"board_type"`
+	State        StateType `json:"state"`
+	OpenIssues   int       `json:"open_issues"`
+	ClosedIssues int       `json:"closed_issues"`
+	Creator      *User     `json:"creator"`
+	HTMLURL      string    `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated *time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// CreateProjectOption options for creating a project
+type CreateProjectOption struct {
+	// required:true
+	Title       string `json:"title" binding:"Required;MaxSize(255)"`
+	Description string `json:"description"`
+	// the predefined boards the project is created with
+	// enum: none,basic_kanban,bug_triage
+	BoardType string `json:"board_type"`
+}
+
+// EditProjectOption options for editing a project
+type EditProjectOption struct {
+	Title       *string `json:"title" binding:"MaxSize(255)"`
+	Description *string `json:"description"`
+	// enum: open,closed
+	State *string `json:"state"`
+}
+
+// ProjectBoard represents a board (column) of a project
+type ProjectBoard struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	// issues which are not assigned to a board are shown on the default board
+	Default bool `json:"default"`
+	Sorting int8 `json:"sorting"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateProjectBoardOption options for creating a project board
+type CreateProjectBoardOption struct {
+	// required:true
+	Title string `json:"title" binding:"Required;MaxSize(255)"`
+}
+
+// EditProjectBoardOption options for editing a project board
+type EditProjectBoardOption struct {
+	Title *string `json:"title" binding:"MaxSize(255)"`
+	// make this board the default board of the project
+	Default *bool `json:"default"`
+}
+
+// SortProjectBoardsOption options for reordering the boards of a project
+type SortProjectBoardsOption struct {
+	// the ids of the boards in their new order
+	// required:true
+	BoardIDs []int64 `json:"board_ids" binding:"Required"`
+}
+
+// MoveProjectIssueOption options for moving an issue to a project board
+type MoveProjectIssueOption struct {
+	// index of the issue or pull request, it is added to the project if it isn't already
+	// required:true
+	Issue int64 `json:"issue" binding:"Required"`
+}
@@ -34,6 +34,7 @@ type BranchProtection struct {
 	MergeWhitelistTeams           []string `json:"merge_whitelist_teams"`
 	EnableStatusCheck             bool     `json:"enable_status_check"`
 	StatusCheckContexts           []string `json:"status_check_contexts"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
 	RequiredApprovals             int64    `json:"required_approvals"`
 	EnableApprovalsWhitelist      bool     `json:"enable_approvals_whitelist"`
 	ApprovalsWhitelistUsernames   []string `json:"approvals_whitelist_username"`
@@ -63,6 +64,7 @@ type CreateBranchProtectionOption struct {
 	MergeWhitelistTeams           []string `json:"merge_whitelist_teams"`
 	EnableStatusCheck             bool     `json:"enable_status_check"`
 	StatusCheckContexts           []string `json:"status_check_contexts"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
 	RequiredApprovals             int64    `json:"required_approvals"`
 	EnableApprovalsWhitelist      bool     `json:"enable_approvals_whitelist"`
 	ApprovalsWhitelistUsernames   []string `json:"approvals_whitelist_username"`
@@ -87,6 +89,7 @@ type EditBranchProtectionOption struct {
 	MergeWhitelistTeams           []string `json:"merge_whitelist_teams"`
 	EnableStatusCheck             *bool    `json:"enable_status_check"`
 	StatusCheckContexts           []string `json:"status_check_contexts"`
+	EnableMergeQueue              *bool    `json:"enable_merge_queue"`
 	RequiredApprovals             *int64   `json:"required_approvals"`
 	EnableApprovalsWhitelist      *bool    `json:"enable_approvals_whitelist"`
 	ApprovalsWhitelistUsernames   []string `json:"approvals_whitelist_username"`
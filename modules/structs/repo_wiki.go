@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// WikiCommit represents a revision of a wiki page
+type WikiCommit struct {
+	ID        string      `json:"sha"`
+	Author    *CommitUser `json:"author"`
+	Committer *CommitUser `json:"committer"`
+	Message   string      `json:"message"`
+}
+
+// WikiPageMetaData represents a wiki page without its content
+type WikiPageMetaData struct {
+	Title      string      `json:"title"`
+	HTMLURL    string      `json:"html_url"`
+	SubURL     string      `json:"sub_url"`
+	LastCommit *WikiCommit `json:"last_commit"`
+}
+
+// WikiPage represents a wiki page
+type WikiPage struct {
+	Title      string      `json:"title"`
+	HTMLURL    string      `json:"html_url"`
+	SubURL     string      `json:"sub_url"`
+	LastCommit *WikiCommit `json:"last_commit"`
+	// raw content of the page, base64 encoded
+	ContentBase64 string `json:"content_base64"`
+	// content of the page rendered to HTML
+	ContentHTML string `json:"content_html"`
+	// number of revisions of the page
+	CommitCount int64 `json:"commit_count"`
+}
+
+// CreateWikiPageOptions options for creating or editing a wiki page
+type CreateWikiPageOptions struct {
+	// title of the page, leave it empty to keep the title when editing a page
+	Title string `json:"title" binding:"MaxSize(255)"`
+	// content of the page, base64 encoded. Leave it empty to keep the content when editing a page
+	ContentBase64 string `json:"content_base64"`
+	// optional commit message summarizing the change
+	Message string `json:"message"`
+}
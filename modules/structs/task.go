@@ -10,6 +10,8 @@ type TaskType int
 // all kinds of task types
 const (
 	TaskTypeMigrateRepo TaskType = iota // migrate repository from external or local disk
+	TaskTypeRestoreRepo                 // restore repository from an uploaded dump archive
+	TaskTypeExportRepo                  // export repository to a dump archive
 )
 
 // Name returns the task type name
@@ -17,6 +19,10 @@ func (taskType TaskType) Name() string {
 	switch taskType {
 	case TaskTypeMigrateRepo:
 		return "Migrate Repository"
+	case TaskTypeRestoreRepo:
+		return "Restore Repository"
+	case TaskTypeExportRepo:
+		return "Export Repository"
 	}
 	return ""
 }
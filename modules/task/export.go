@@ -0,0 +1,102 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrExportInProgress is returned when an export of a repository is requested while another one is pending
+var ErrExportInProgress = errors.New("an export of the repository is already in progress")
+
+// exportWorkingPool makes sure that only one export of a repository is added at a time
+var exportWorkingPool = sync.NewExclusivePool()
+
+// ExportRepository adds the export of a repository to task
+func ExportRepository(doer *models.User, repo *models.Repository) error {
+	exportWorkingPool.CheckIn(fmt.Sprint(repo.ID))
+	defer exportWorkingPool.CheckOut(fmt.Sprint(repo.ID))
+
+	latest, err := models.GetLatestExportTask(repo.ID)
+	if err != nil && !models.IsErrTaskDoesNotExist(err) {
+		return err
+	} else if err == nil && latest.IsRunning() {
+		return ErrExportInProgress
+	}
+
+	var task = models.Task{
+		DoerID:  doer.ID,
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+		Type:    structs.TaskTypeExportRepo,
+		Status:  structs.TaskStatusQueue,
+	}
+	if err := models.CreateTask(&task); err != nil {
+		return err
+	}
+
+	return taskQueue.Push(&task)
+}
+
+func runExportTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do export task: %v", e)
+			log.Critical("PANIC during runExportTask[%d] by DoerID[%d] of RepoID[%d]: %v\nStacktrace: %v", t.ID, t.DoerID, t.RepoID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		if err == nil {
+			t.Status = structs.TaskStatusFinished
+			if err := t.UpdateCols("status", "end_time"); err != nil {
+				log.Error("Task UpdateCols failed: %v", err)
+			}
+			return
+		}
+
+		t.Status = structs.TaskStatusFailed
+		t.Errors = err.Error()
+		if err := t.UpdateCols("status", "errors", "end_time"); err != nil {
+			log.Error("Task UpdateCols failed: %v", err)
+		}
+	}()
+
+	if err = t.LoadRepo(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	pm := process.GetManager()
+	pid := pm.Add(fmt.Sprintf("ExportTask: %s/%s", t.Repo.OwnerName, t.Repo.Name), cancel)
+	defer pm.Remove(pid)
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	err = migrations.ExportRepository(ctx, t.Repo, func(key string, args ...interface{}) {
+		if err := t.SetMessage(key, args...); err != nil {
+			log.Error("Task[%d] SetMessage failed: %v", t.ID, err)
+		}
+	})
+	if err != nil {
+		err = fmt.Errorf("Export failed: %v", err)
+	}
+	return
+}
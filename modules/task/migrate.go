@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -106,7 +107,17 @@ func runMigrateTask(t *models.Task) (err error) {
 		return
 	}
 
-	repo, err = migrations.MigrateRepository(ctx, t.Doer, t.Owner.Name, *opts)
+	messenger := migration.Messenger(func(key string, args ...interface{}) {
+		if err := t.SetMessage(key, args...); err != nil {
+			log.Error("Task[%d] SetMessage failed: %v", t.ID, err)
+		}
+	})
+
+	if t.Type == structs.TaskTypeRestoreRepo {
+		repo, err = restoreRepository(ctx, t, opts, messenger)
+	} else {
+		repo, err = migrations.MigrateRepository(ctx, t.Doer, t.Owner.Name, *opts, messenger)
+	}
 	if err == nil {
 		log.Trace("Repository migrated [%d]: %s/%s", repo.ID, t.Owner.Name, repo.Name)
 		return
@@ -130,3 +141,31 @@ func runMigrateTask(t *models.Task) (err error) {
 	err = handleCreateError(t.Owner, err)
 	return
 }
+
+// restoreRepository restores the repository of a restore task from the dump archive at opts.CloneAddr,
+// the archive has been stored by migrations.SaveRepositoryDump and is removed once it has been restored
+func restoreRepository(ctx context.Context, t *models.Task, opts *migration.MigrateOptions, messenger migration.Messenger) (*models.Repository, error) {
+	defer func() {
+		if err := util.RemoveAll(filepath.Dir(opts.CloneAddr)); err != nil {
+			log.Error("Unable to remove the repository dump %s: %v", opts.CloneAddr, err)
+		}
+	}()
+
+	tmpDir, err := models.CreateTemporaryPath("restore")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpDir); err != nil {
+			log.Error("Unable to remove temporary directory: %s: Error: %v", tmpDir, err)
+		}
+	}()
+
+	messenger("repo.migrate.extracting_dump")
+	baseDir, err := migrations.ExtractRepositoryDump(opts.CloneAddr, tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("Restore failed: %v", err)
+	}
+
+	return migrations.RestoreDumpedRepository(ctx, t.Doer, baseDir, t.Owner.Name, *opts, messenger)
+}
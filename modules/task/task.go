@@ -24,8 +24,10 @@ var taskQueue queue.Queue
 // Run a task
 func Run(t *models.Task) error {
 	switch t.Type {
-	case structs.TaskTypeMigrateRepo:
+	case structs.TaskTypeMigrateRepo, structs.TaskTypeRestoreRepo:
 		return runMigrateTask(t)
+	case structs.TaskTypeExportRepo:
+		return runExportTask(t)
 	default:
 		return fmt.Errorf("Unknown task type: %d", t.Type)
 	}
@@ -63,8 +65,44 @@ func MigrateRepository(doer, u *models.User, opts base.MigrateOptions) error {
 	return taskQueue.Push(task)
 }
 
+// RestoreRepository adds the restoration of a repository from the dump archive at opts.CloneAddr to task
+func RestoreRepository(doer, u *models.User, opts base.MigrateOptions) error {
+	task, err := createMigrateTask(doer, u, structs.TaskTypeRestoreRepo, opts)
+	if err != nil {
+		return err
+	}
+
+	return taskQueue.Push(task)
+}
+
+// RecordMigration records a migration which has been run directly rather than from the task queue as a finished
+// migrate task, so its options are available afterwards like the ones of queued migrations
+func RecordMigration(doer *models.User, repo *models.Repository, opts base.MigrateOptions) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	bs, err := json.Marshal(&opts)
+	if err != nil {
+		return err
+	}
+
+	now := timeutil.TimeStampNow()
+	return models.CreateTask(&models.Task{
+		DoerID:         doer.ID,
+		OwnerID:        repo.OwnerID,
+		RepoID:         repo.ID,
+		Type:           structs.TaskTypeMigrateRepo,
+		Status:         structs.TaskStatusFinished,
+		StartTime:      now,
+		EndTime:        now,
+		PayloadContent: string(bs),
+	})
+}
+
 // CreateMigrateTask creates a migrate task
 func CreateMigrateTask(doer, u *models.User, opts base.MigrateOptions) (*models.Task, error) {
+	return createMigrateTask(doer, u, structs.TaskTypeMigrateRepo, opts)
+}
+
+func createMigrateTask(doer, u *models.User, tp structs.TaskType, opts base.MigrateOptions) (*models.Task, error) {
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	bs, err := json.Marshal(&opts)
 	if err != nil {
@@ -74,7 +112,7 @@ func CreateMigrateTask(doer, u *models.User, opts base.MigrateOptions) (*models.
 	var task = models.Task{
 		DoerID:         doer.ID,
 		OwnerID:        u.ID,
-		Type:           structs.TaskTypeMigrateRepo,
+		Type:           tp,
 		Status:         structs.TaskStatusQueue,
 		PayloadContent: string(bs),
 	}
@@ -197,6 +197,12 @@ func prepareUserInfo(ctx *context.Context) *models.User {
 		ctx.Data["TwoFactorEnabled"] = false
 	}
 
+	ctx.Data["WebAuthnCredentials"], err = models.GetWebAuthnCredentialsByUID(u.ID)
+	if err != nil {
+		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+		return nil
+	}
+
 	return u
 }
 
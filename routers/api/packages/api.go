@@ -0,0 +1,126 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/packages/container"
+	"code.gitea.io/gitea/routers/api/packages/generic"
+	"code.gitea.io/gitea/routers/api/packages/maven"
+	"code.gitea.io/gitea/routers/api/packages/npm"
+	"code.gitea.io/gitea/routers/api/packages/pypi"
+
+	"gitea.com/go-chi/session"
+)
+
+func reqPackageAccess(accessMode models.AccessMode) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if token := ctx.AccessToken(); token != nil && !token.HasScope(models.AccessTokenScopePackage) {
+			ctx.Error(http.StatusForbidden, "reqPackageAccess", "token requires the package scope")
+			return
+		}
+		if ctx.Package.AccessMode < accessMode {
+			ctx.Resp.Header().Set("WWW-Authenticate", `Basic realm="Gitea Package API"`)
+			ctx.Error(http.StatusUnauthorized, "reqPackageAccess", "user should have specific permission or be a site admin")
+			return
+		}
+	}
+}
+
+func newRoute() *web.Route {
+	r := web.NewRoute()
+
+	r.Use(session.Sessioner(session.Options{
+		Provider:       setting.SessionConfig.Provider,
+		ProviderConfig: setting.SessionConfig.ProviderConfig,
+		CookieName:     setting.SessionConfig.CookieName,
+		CookiePath:     setting.SessionConfig.CookiePath,
+		Gclifetime:     setting.SessionConfig.Gclifetime,
+		Maxlifetime:    setting.SessionConfig.Maxlifetime,
+		Secure:         setting.SessionConfig.Secure,
+		Domain:         setting.SessionConfig.Domain,
+	}))
+	r.Use(context.APIContexter())
+
+	if setting.EnableAccessLog {
+		r.Use(context.AccessLogger())
+	}
+
+	return r
+}
+
+// Routes registers the routes of the package registries served below /api/packages
+func Routes() *web.Route {
+	r := newRoute()
+
+	r.Group("/{username}", func() {
+		r.Group("/generic", func() {
+			r.Group("/{packagename}/{packageversion}", func() {
+				r.Delete("", reqPackageAccess(models.AccessModeWrite), generic.DeletePackage)
+				r.Group("/{filename}", func() {
+					r.Get("", generic.DownloadPackageFile)
+					r.Put("", reqPackageAccess(models.AccessModeWrite), generic.UploadPackage)
+				})
+			})
+		})
+		r.Group("/maven", func() {
+			r.Put("/*", reqPackageAccess(models.AccessModeWrite), maven.UploadPackageFile)
+			r.Get("/*", maven.DownloadPackageFile)
+			r.Head("/*", maven.DownloadPackageFile)
+		})
+		r.Group("/npm", func() {
+			r.Group("/{id}", func() {
+				r.Get("", npm.PackageMetadata)
+				r.Put("", reqPackageAccess(models.AccessModeWrite), npm.UploadPackage)
+				r.Get("/-/{version}/{filename}", npm.DownloadPackageFile)
+				r.Delete("/-/{version}/{filename}/-rev/{revision}", reqPackageAccess(models.AccessModeWrite), npm.DeletePackageVersion)
+				r.Delete("/-rev/{revision}", reqPackageAccess(models.AccessModeWrite), npm.DeletePackage)
+			})
+		})
+		r.Group("/pypi", func() {
+			r.Post("", reqPackageAccess(models.AccessModeWrite), pypi.UploadPackageFile)
+			r.Get("/files/{id}/{version}/{filename}", pypi.DownloadPackageFile)
+			r.Get("/simple/{id}", pypi.PackageMetadata)
+		})
+	}, context.PackageAssignmentAPI(), reqPackageAccess(models.AccessModeRead))
+
+	return r
+}
+
+// ContainerRoutes registers the routes of the container registry which must be served below /v2
+func ContainerRoutes() *web.Route {
+	r := newRoute()
+
+	r.Get("", container.DetermineSupport)
+	r.Group("/{username}/{image}", func() {
+		r.Group("/blobs/uploads", func() {
+			r.Post("", container.InitiateUploadBlob)
+			r.Group("/{uuid}", func() {
+				r.Get("", container.GetUploadBlob)
+				r.Patch("", container.UploadBlob)
+				r.Put("", container.EndUploadBlob)
+				r.Delete("", container.CancelUploadBlob)
+			})
+		}, container.ReqContainerAccess(models.AccessModeWrite))
+		r.Group("/blobs/{digest}", func() {
+			r.Head("", container.HeadBlob)
+			r.Get("", container.GetBlob)
+		})
+		r.Group("/manifests/{reference}", func() {
+			r.Put("", container.ReqContainerAccess(models.AccessModeWrite), container.UploadManifest)
+			r.Head("", container.HeadManifest)
+			r.Get("", container.GetManifest)
+			r.Delete("", container.ReqContainerAccess(models.AccessModeWrite), container.DeleteManifest)
+		})
+		r.Get("/tags/list", container.GetTagList)
+	}, context.PackageAssignmentAPI(), container.VerifyImageName, container.ReqContainerAccess(models.AccessModeRead))
+
+	return r
+}
@@ -0,0 +1,777 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	gouuid "github.com/google/uuid"
+)
+
+const (
+	authenticateRealm = `Basic realm="Gitea Container Registry"`
+	maxManifestSize   = 10 * 1024 * 1024
+)
+
+type containerHeaders struct {
+	Status        int
+	ContentDigest string
+	UploadUUID    string
+	Range         string
+	Location      string
+	ContentType   string
+	ContentLength int64
+}
+
+func setResponseHeaders(resp http.ResponseWriter, h *containerHeaders) {
+	if h.Location != "" {
+		resp.Header().Set("Location", h.Location)
+	}
+	if h.Range != "" {
+		resp.Header().Set("Range", h.Range)
+	}
+	if h.ContentType != "" {
+		resp.Header().Set("Content-Type", h.ContentType)
+	}
+	if h.ContentLength != 0 {
+		resp.Header().Set("Content-Length", strconv.FormatInt(h.ContentLength, 10))
+	}
+	if h.UploadUUID != "" {
+		resp.Header().Set("Docker-Upload-Uuid", h.UploadUUID)
+	}
+	if h.ContentDigest != "" {
+		resp.Header().Set("Docker-Content-Digest", h.ContentDigest)
+	}
+	resp.Header().Set("Docker-Distribution-Api-Version", "registry/2.0")
+	resp.WriteHeader(h.Status)
+}
+
+func jsonResponse(ctx *context.APIContext, status int, obj interface{}) {
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Status:      status,
+		ContentType: "application/json",
+	})
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("JSON encode: %v", err)
+	}
+}
+
+func apiError(ctx *context.APIContext, status int, err error) {
+	helper.LogAndProcessError(ctx, status, err, func(message string) {
+		setResponseHeaders(ctx.Resp, &containerHeaders{
+			Status: status,
+		})
+	})
+}
+
+// apiErrorDefined writes an error in the format defined by the distribution spec
+func apiErrorDefined(ctx *context.APIContext, err *namedError) {
+	type ContainerError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	type ContainerErrors struct {
+		Errors []ContainerError `json:"errors"`
+	}
+
+	jsonResponse(ctx, err.StatusCode, ContainerErrors{
+		Errors: []ContainerError{
+			{
+				Code:    err.Code,
+				Message: err.Message,
+			},
+		},
+	})
+}
+
+// ReqContainerAccess is a middleware which checks the current user has the necessary access mode on the packages of the owner
+func ReqContainerAccess(mode models.AccessMode) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if token := ctx.AccessToken(); token != nil && !token.HasScope(models.AccessTokenScopePackage) {
+			apiErrorDefined(ctx, errDenied.WithMessage("token requires the package scope"))
+			return
+		}
+		if ctx.Package.AccessMode < mode {
+			ctx.Resp.Header().Add("WWW-Authenticate", authenticateRealm)
+			apiErrorDefined(ctx, errUnauthorized)
+		}
+	}
+}
+
+// VerifyImageName is a middleware which checks if the image name is allowed
+func VerifyImageName(ctx *context.APIContext) {
+	if !container_module.IsValidImageName(ctx.Params("image")) {
+		apiErrorDefined(ctx, errNameInvalid)
+	}
+}
+
+// DetermineSupport is used to test if the registry supports OCI
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#determining-support
+func DetermineSupport(ctx *context.APIContext) {
+	if !setting.Packages.Enabled {
+		apiErrorDefined(ctx, errNameUnknown.WithMessage("the package registry is disabled"))
+		return
+	}
+	if ctx.User == nil {
+		ctx.Resp.Header().Add("WWW-Authenticate", authenticateRealm)
+		apiErrorDefined(ctx, errUnauthorized)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Status: http.StatusOK,
+	})
+}
+
+func imageURL(ctx *context.APIContext) string {
+	return fmt.Sprintf("/v2/%s/%s", url.PathEscape(ctx.Package.Owner.LowerName), url.PathEscape(ctx.Params("image")))
+}
+
+func uploadPath(uuid string) (string, error) {
+	if _, err := gouuid.Parse(uuid); err != nil {
+		return "", err
+	}
+	return filepath.Join(packages_service.UploadDir(), uuid), nil
+}
+
+// InitiateUploadBlob starts a blob upload.
+// Monolithic uploads (with digest) and cross repository mounts are finished immediately.
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-blobs
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#mounting-a-blob-from-another-repository
+func InitiateUploadBlob(ctx *context.APIContext) {
+	mount := ctx.Query("mount")
+	if mount != "" && container_module.IsValidDigest(mount) {
+		if _, err := models.GetPackageBlobOfOwner(ctx.Package.Owner.ID, container_module.DigestToHash(mount)); err == nil {
+			setResponseHeaders(ctx.Resp, &containerHeaders{
+				Location:      imageURL(ctx) + "/blobs/" + mount,
+				ContentDigest: mount,
+				Status:        http.StatusCreated,
+			})
+			return
+		}
+	}
+
+	digest := ctx.Query("digest")
+	if digest != "" {
+		if !container_module.IsValidDigest(digest) {
+			apiErrorDefined(ctx, errDigestInvalid)
+			return
+		}
+
+		buf, err := packages_module.CreateHashedBufferFromReader(ctx.Req.Body)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		defer buf.Close()
+
+		if err := storeBlob(buf, digest); err != nil {
+			if namedErr, ok := err.(*namedError); ok {
+				apiErrorDefined(ctx, namedErr)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		setResponseHeaders(ctx.Resp, &containerHeaders{
+			Location:      imageURL(ctx) + "/blobs/" + digest,
+			ContentDigest: digest,
+			Status:        http.StatusCreated,
+		})
+		return
+	}
+
+	uuid := gouuid.New().String()
+	p, _ := uploadPath(uuid)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	f.Close()
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Location:   imageURL(ctx) + "/blobs/uploads/" + uuid,
+		Range:      "0-0",
+		UploadUUID: uuid,
+		Status:     http.StatusAccepted,
+	})
+}
+
+// appendToUpload writes the request body to the upload and returns the new size of the upload
+func appendToUpload(ctx *context.APIContext, uuid string) (int64, error) {
+	p, err := uploadPath(uuid)
+	if err != nil {
+		return 0, errBlobUploadUnknown
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, errBlobUploadUnknown
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, ctx.Req.Body); err != nil {
+		return 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func uploadRange(size int64) string {
+	if size == 0 {
+		return "0-0"
+	}
+	return fmt.Sprintf("0-%d", size-1)
+}
+
+// UploadBlob appends a chunk to the blob upload
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-a-blob-in-chunks
+func UploadBlob(ctx *context.APIContext) {
+	uuid := ctx.Params("uuid")
+	size, err := appendToUpload(ctx, uuid)
+	if err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Location:   imageURL(ctx) + "/blobs/uploads/" + uuid,
+		Range:      uploadRange(size),
+		UploadUUID: uuid,
+		Status:     http.StatusAccepted,
+	})
+}
+
+// GetUploadBlob returns the status of a blob upload
+func GetUploadBlob(ctx *context.APIContext) {
+	uuid := ctx.Params("uuid")
+	p, err := uploadPath(uuid)
+	if err != nil {
+		apiErrorDefined(ctx, errBlobUploadUnknown)
+		return
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			apiErrorDefined(ctx, errBlobUploadUnknown)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Range:      uploadRange(fi.Size()),
+		UploadUUID: uuid,
+		Status:     http.StatusNoContent,
+	})
+}
+
+// EndUploadBlob finishes a blob upload
+func EndUploadBlob(ctx *context.APIContext) {
+	uuid := ctx.Params("uuid")
+	digest := ctx.Query("digest")
+	if !container_module.IsValidDigest(digest) {
+		apiErrorDefined(ctx, errDigestInvalid)
+		return
+	}
+
+	if _, err := appendToUpload(ctx, uuid); err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	p, _ := uploadPath(uuid)
+	defer func() {
+		if err := os.Remove(p); err != nil {
+			log.Error("Error removing blob upload %s: %v", uuid, err)
+		}
+	}()
+
+	f, err := os.Open(p)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer f.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(f)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	if err := storeBlob(buf, digest); err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Location:      imageURL(ctx) + "/blobs/" + digest,
+		ContentDigest: digest,
+		Status:        http.StatusCreated,
+	})
+}
+
+// CancelUploadBlob cancels a blob upload
+func CancelUploadBlob(ctx *context.APIContext) {
+	p, err := uploadPath(ctx.Params("uuid"))
+	if err != nil {
+		apiErrorDefined(ctx, errBlobUploadUnknown)
+		return
+	}
+	if err := os.Remove(p); err != nil {
+		if os.IsNotExist(err) {
+			apiErrorDefined(ctx, errBlobUploadUnknown)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Status: http.StatusNoContent,
+	})
+}
+
+// storeBlob verifies the digest of the content and stores it as unreferenced blob
+func storeBlob(buf *packages_module.HashedBuffer, digest string) error {
+	_, _, hashSHA256, _ := buf.Sums()
+	if container_module.DigestToHash(digest) != hashSHA256 {
+		return errDigestInvalid.WithMessage("digest does not match the content")
+	}
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := packages_service.CreateBlob(buf)
+	return err
+}
+
+func getBlobFromParams(ctx *context.APIContext) (*models.PackageBlob, error) {
+	digest := ctx.Params("digest")
+	if !container_module.IsValidDigest(digest) {
+		return nil, errBlobUnknown
+	}
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageContainer, ctx.Params("image"))
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			return nil, errBlobUnknown
+		}
+		return nil, err
+	}
+
+	pb, err := models.GetPackageBlobOfPackage(p.ID, container_module.DigestToHash(digest))
+	if err != nil {
+		if err == models.ErrPackageBlobNotExist {
+			return nil, errBlobUnknown
+		}
+		return nil, err
+	}
+	return pb, nil
+}
+
+// HeadBlob checks if a blob exists
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#checking-if-content-exists-in-the-registry
+func HeadBlob(ctx *context.APIContext) {
+	pb, err := getBlobFromParams(ctx)
+	if err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		ContentDigest: container_module.DigestPrefixSHA256 + pb.HashSHA256,
+		ContentLength: pb.Size,
+		Status:        http.StatusOK,
+	})
+}
+
+// GetBlob serves the content of a blob
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-blobs
+func GetBlob(ctx *context.APIContext) {
+	pb, err := getBlobFromParams(ctx)
+	if err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	s, err := packages_service.GetPackageBlobStream(pb)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		ContentDigest: container_module.DigestPrefixSHA256 + pb.HashSHA256,
+		ContentType:   "application/octet-stream",
+		ContentLength: pb.Size,
+		Status:        http.StatusOK,
+	})
+	if _, err := io.Copy(ctx.Resp, s); err != nil {
+		log.Error("Error whilst copying blob content to response: %v", err)
+	}
+}
+
+// UploadManifest stores a manifest (or an index) and tags it with the reference
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-manifests
+func UploadManifest(ctx *context.APIContext) {
+	reference := ctx.Params("reference")
+	if !container_module.IsValidTag(reference) && !container_module.IsValidDigest(reference) {
+		apiErrorDefined(ctx, errManifestInvalid.WithMessage("manifest reference is invalid"))
+		return
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(io.LimitReader(ctx.Req.Body, maxManifestSize))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	manifest, err := container_module.ParseManifest(buf, ctx.Req.Header.Get("Content-Type"))
+	if err != nil {
+		apiErrorDefined(ctx, errManifestInvalid.WithMessage(err.Error()))
+		return
+	}
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, hashSHA256, _ := buf.Sums()
+	digest := container_module.DigestPrefixSHA256 + hashSHA256
+	if container_module.IsValidDigest(reference) && reference != digest {
+		apiErrorDefined(ctx, errDigestInvalid)
+		return
+	}
+
+	references, err := resolveManifestReferences(ctx, manifest)
+	if err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, err = packages_service.ReplacePackageVersion(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: models.PackageContainer,
+				Name:        ctx.Params("image"),
+				Version:     reference,
+			},
+			Creator: ctx.User,
+			Metadata: &container_module.Metadata{
+				MediaType: manifest.MediaType,
+				Digest:    digest,
+			},
+		},
+		&packages_service.PackageFileInfo{
+			Filename: digest,
+			Data:     buf,
+		},
+		references,
+	)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Location:      imageURL(ctx) + "/manifests/" + reference,
+		ContentDigest: digest,
+		Status:        http.StatusCreated,
+	})
+}
+
+// resolveManifestReferences finds the blobs referenced by the manifest.
+// Blobs must either belong to a package of the same owner or be freshly uploaded (unreferenced).
+// Manifests referenced by an index must have been pushed to the same image before.
+func resolveManifestReferences(ctx *context.APIContext, manifest *container_module.Manifest) (map[string]*models.PackageBlob, error) {
+	references := make(map[string]*models.PackageBlob)
+
+	if manifest.IsIndex() {
+		p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageContainer, ctx.Params("image"))
+		if err != nil {
+			if err == models.ErrPackageNotExist {
+				return nil, errManifestBlobUnknown
+			}
+			return nil, err
+		}
+		for _, d := range manifest.Manifests {
+			pb, err := models.GetPackageBlobOfPackage(p.ID, container_module.DigestToHash(d.Digest))
+			if err != nil {
+				if err == models.ErrPackageBlobNotExist {
+					return nil, errManifestBlobUnknown.WithMessage(d.Digest)
+				}
+				return nil, err
+			}
+			references[d.Digest] = pb
+		}
+		return references, nil
+	}
+
+	for _, digest := range manifest.BlobDigests() {
+		if _, ok := references[digest]; ok {
+			continue
+		}
+		hash := container_module.DigestToHash(digest)
+		pb, err := models.GetPackageBlobOfOwner(ctx.Package.Owner.ID, hash)
+		if err == models.ErrPackageBlobNotExist {
+			pb, err = models.GetPackageBlobByHash(hash)
+			if err == nil {
+				var referenced bool
+				if referenced, err = models.IsPackageBlobReferenced(pb.ID); err == nil && referenced {
+					err = models.ErrPackageBlobNotExist
+				}
+			}
+		}
+		if err != nil {
+			if err == models.ErrPackageBlobNotExist {
+				return nil, errManifestBlobUnknown.WithMessage(digest)
+			}
+			return nil, err
+		}
+		references[digest] = pb
+	}
+	return references, nil
+}
+
+// getManifestFromParams resolves the reference (tag or digest) to the manifest blob and its media type
+func getManifestFromParams(ctx *context.APIContext) (*models.PackageBlob, string, error) {
+	reference := ctx.Params("reference")
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageContainer, ctx.Params("image"))
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			return nil, "", errNameUnknown
+		}
+		return nil, "", err
+	}
+
+	pvs, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	isDigest := container_module.IsValidDigest(reference)
+	for _, pv := range pvs {
+		var metadata container_module.Metadata
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &metadata); err != nil {
+			return nil, "", err
+		}
+		if isDigest && metadata.Digest != reference || !isDigest && pv.LowerVersion != strings.ToLower(reference) {
+			continue
+		}
+
+		pb, err := models.GetPackageBlobOfPackage(p.ID, container_module.DigestToHash(metadata.Digest))
+		if err != nil {
+			if err == models.ErrPackageBlobNotExist {
+				return nil, "", errManifestUnknown
+			}
+			return nil, "", err
+		}
+		return pb, metadata.MediaType, nil
+	}
+	return nil, "", errManifestUnknown
+}
+
+// HeadManifest checks if a manifest exists
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#checking-if-content-exists-in-the-registry
+func HeadManifest(ctx *context.APIContext) {
+	pb, mediaType, err := getManifestFromParams(ctx)
+	if err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		ContentDigest: container_module.DigestPrefixSHA256 + pb.HashSHA256,
+		ContentType:   mediaType,
+		ContentLength: pb.Size,
+		Status:        http.StatusOK,
+	})
+}
+
+// GetManifest serves a manifest
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-manifests
+func GetManifest(ctx *context.APIContext) {
+	pb, mediaType, err := getManifestFromParams(ctx)
+	if err != nil {
+		if namedErr, ok := err.(*namedError); ok {
+			apiErrorDefined(ctx, namedErr)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	s, err := packages_service.GetPackageBlobStream(pb)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		ContentDigest: container_module.DigestPrefixSHA256 + pb.HashSHA256,
+		ContentType:   mediaType,
+		ContentLength: pb.Size,
+		Status:        http.StatusOK,
+	})
+	if _, err := io.Copy(ctx.Resp, s); err != nil {
+		log.Error("Error whilst copying manifest to response: %v", err)
+	}
+}
+
+// DeleteManifest deletes a tag, or all tags pointing to the manifest if the reference is a digest
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#deleting-tags
+func DeleteManifest(ctx *context.APIContext) {
+	reference := ctx.Params("reference")
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageContainer, ctx.Params("image"))
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiErrorDefined(ctx, errNameUnknown)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pvs, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	deleted := 0
+	for _, pv := range pvs {
+		match := pv.LowerVersion == strings.ToLower(reference)
+		if !match && container_module.IsValidDigest(reference) {
+			var metadata container_module.Metadata
+			if err := json.Unmarshal([]byte(pv.MetadataJSON), &metadata); err == nil {
+				match = metadata.Digest == reference
+			}
+		}
+		if !match {
+			continue
+		}
+		if err := models.DeletePackageVersion(pv); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		deleted++
+	}
+
+	if deleted == 0 {
+		apiErrorDefined(ctx, errManifestUnknown)
+		return
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Status: http.StatusAccepted,
+	})
+}
+
+// GetTagList lists the tags of an image, manifests pushed by digest only are not listed
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-tags
+func GetTagList(ctx *context.APIContext) {
+	image := ctx.Params("image")
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageContainer, image)
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiErrorDefined(ctx, errNameUnknown)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pvs, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	tags := make([]string, 0, len(pvs))
+	for _, pv := range pvs {
+		if !container_module.IsValidDigest(pv.Version) {
+			tags = append(tags, pv.Version)
+		}
+	}
+	sort.Strings(tags)
+
+	type TagList struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	jsonResponse(ctx, http.StatusOK, TagList{
+		Name: strings.ToLower(ctx.Package.Owner.LowerName + "/" + image),
+		Tags: tags,
+	})
+}
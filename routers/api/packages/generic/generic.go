@@ -0,0 +1,127 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package generic
+
+import (
+	"net/http"
+	"regexp"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+var (
+	packageNameRegex = regexp.MustCompile(`\A[A-Za-z0-9\.\_\-\+]+\z`)
+	filenameRegex    = packageNameRegex
+)
+
+func apiError(ctx *context.APIContext, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, []byte(message))
+	})
+}
+
+// DownloadPackageFile serves the specific generic package.
+func DownloadPackageFile(ctx *context.APIContext) {
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: models.PackageGeneric,
+			Name:        ctx.Params("packagename"),
+			Version:     ctx.Params("packageversion"),
+		},
+		ctx.Params("filename"),
+	)
+	if err != nil {
+		if err == models.ErrPackageVersionNotExist || err == models.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(pf.Name, s, pf.CreatedUnix.AsTime())
+}
+
+// UploadPackage uploads the specific generic package.
+// Duplicated packages get rejected.
+func UploadPackage(ctx *context.APIContext) {
+	packageName, filename := ctx.Params("packagename"), ctx.Params("filename")
+	if !packageNameRegex.MatchString(packageName) || !filenameRegex.MatchString(filename) {
+		apiError(ctx, http.StatusBadRequest, "Invalid package name or filename")
+		return
+	}
+
+	packageVersion := ctx.Params("packageversion")
+	if packageVersion == "" || !packageNameRegex.MatchString(packageVersion) {
+		apiError(ctx, http.StatusBadRequest, "Invalid package version")
+		return
+	}
+
+	upload := ctx.Req.Body
+	defer upload.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload)
+	if err != nil {
+		log.Error("Error creating hashed buffer: %v", err)
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: models.PackageGeneric,
+				Name:        packageName,
+				Version:     packageVersion,
+			},
+			Creator: ctx.User,
+		},
+		&packages_service.PackageFileInfo{
+			Filename: filename,
+			Data:     buf,
+		},
+	)
+	if err != nil {
+		if err == models.ErrPackageFileAlreadyExist {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DeletePackage deletes the specific generic package.
+func DeletePackage(ctx *context.APIContext) {
+	err := packages_service.DeletePackageVersionByNameAndVersion(
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: models.PackageGeneric,
+			Name:        ctx.Params("packagename"),
+			Version:     ctx.Params("packageversion"),
+		},
+	)
+	if err != nil {
+		if err == models.ErrPackageNotExist || err == models.ErrPackageVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,381 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maven
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	maven_module "code.gitea.io/gitea/modules/packages/maven"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+const (
+	mavenMetadataFile = "maven-metadata.xml"
+	extensionMD5      = ".md5"
+	extensionSHA1     = ".sha1"
+	extensionSHA256   = ".sha256"
+	extensionSHA512   = ".sha512"
+)
+
+var (
+	errInvalidParameters = errors.New("request parameters are invalid")
+	illegalCharacters    = regexp.MustCompile(`[\\/:"<>|?\*]`)
+)
+
+func apiError(ctx *context.APIContext, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, []byte(message))
+	})
+}
+
+// DownloadPackageFile serves the content of a package
+func DownloadPackageFile(ctx *context.APIContext) {
+	params, err := extractPathParameters(ctx.Params("*"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if params.IsMeta && params.Version == "" {
+		serveMavenMetadata(ctx, params)
+	} else {
+		servePackageFile(ctx, params)
+	}
+}
+
+func serveMavenMetadata(ctx *context.APIContext, params parameters) {
+	// /com/foo/project/maven-metadata.xml[.md5/.sha1/.sha256/.sha512]
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageMaven, params.PackageName())
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pvs, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the versions are returned newest first, the metadata lists them oldest first
+	versions := make([]string, len(pvs))
+	for i, pv := range pvs {
+		versions[len(pvs)-1-i] = pv.Version
+	}
+
+	xmlMetadata, err := xml.Marshal(maven_module.CreateMetadataResponse(params.GroupID, params.ArtifactID, versions))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	xmlMetadataWithHeader := append([]byte(xml.Header), xmlMetadata...)
+
+	ext := strings.ToLower(filepath.Ext(params.Filename))
+	if isChecksumExtension(ext) {
+		var h hash.Hash
+		switch ext {
+		case extensionMD5:
+			h = md5.New()
+		case extensionSHA1:
+			h = sha1.New()
+		case extensionSHA256:
+			h = sha256.New()
+		case extensionSHA512:
+			h = sha512.New()
+		}
+		_, _ = h.Write(xmlMetadataWithHeader)
+		ctx.PlainText(http.StatusOK, []byte(hex.EncodeToString(h.Sum(nil))))
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/xml")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write(xmlMetadataWithHeader); err != nil {
+		log.Error("write bytes failed: %v", err)
+	}
+}
+
+func servePackageFile(ctx *context.APIContext, params parameters) {
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageMaven, params.PackageName())
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pv, err := models.GetPackageVersionByName(p.ID, params.Version)
+	if err != nil {
+		if err == models.ErrPackageVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := params.Filename
+	ext := strings.ToLower(filepath.Ext(filename))
+	if isChecksumExtension(ext) {
+		filename = filename[:len(filename)-len(ext)]
+	}
+
+	pf, err := models.GetPackageFileByName(pv.ID, filename)
+	if err != nil {
+		if err == models.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if isChecksumExtension(ext) {
+		if err := pf.LoadBlob(); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		var checksum string
+		switch ext {
+		case extensionMD5:
+			checksum = pf.Blob.HashMD5
+		case extensionSHA1:
+			checksum = pf.Blob.HashSHA1
+		case extensionSHA256:
+			checksum = pf.Blob.HashSHA256
+		case extensionSHA512:
+			checksum = pf.Blob.HashSHA512
+		}
+		ctx.PlainText(http.StatusOK, []byte(checksum))
+		return
+	}
+
+	s, err := packages_service.GetPackageFileStream(pf)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	if err := models.IncrementPackageVersionDownloadCount(pv.ID); err != nil {
+		log.Error("Error incrementing download counter of package version %d: %v", pv.ID, err)
+	}
+
+	ctx.ServeContent(pf.Name, s, pf.CreatedUnix.AsTime())
+}
+
+// UploadPackageFile adds a file to the package. If the package does not exist, it gets created.
+func UploadPackageFile(ctx *context.APIContext) {
+	params, err := extractPathParameters(ctx.Params("*"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	log.Trace("Parameters: %#v", params)
+
+	// Ignore the package index /<name>/maven-metadata.xml, it is generated on the fly
+	if params.IsMeta && params.Version == "" {
+		ctx.Status(http.StatusOK)
+		return
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(ctx.Req.Body)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pvci := &packages_service.PackageCreationInfo{
+		PackageInfo: packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: models.PackageMaven,
+			Name:        params.PackageName(),
+			Version:     params.Version,
+		},
+		Creator:  ctx.User,
+		Metadata: &maven_module.Metadata{GroupID: params.GroupID, ArtifactID: params.ArtifactID},
+	}
+
+	ext := filepath.Ext(params.Filename)
+
+	// Do not upload checksum files but compare the hashes
+	if isChecksumExtension(ext) {
+		pv, err := packages_service.GetPackageVersion(&pvci.PackageInfo)
+		if err != nil {
+			if err == models.ErrPackageNotExist || err == models.ErrPackageVersionNotExist {
+				apiError(ctx, http.StatusNotFound, err)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		pf, err := models.GetPackageFileByName(pv.ID, params.Filename[:len(params.Filename)-len(ext)])
+		if err != nil {
+			if err == models.ErrPackageFileNotExist {
+				apiError(ctx, http.StatusNotFound, err)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if err := pf.LoadBlob(); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		checksum, err := ioutil.ReadAll(buf)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		expected := strings.TrimSpace(string(checksum))
+		if (ext == extensionMD5 && expected != pf.Blob.HashMD5) ||
+			(ext == extensionSHA1 && expected != pf.Blob.HashSHA1) ||
+			(ext == extensionSHA256 && expected != pf.Blob.HashSHA256) ||
+			(ext == extensionSHA512 && expected != pf.Blob.HashSHA512) {
+			apiError(ctx, http.StatusBadRequest, "hash mismatch")
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+		return
+	}
+
+	var pomMetadata *maven_module.Metadata
+	if ext == ".pom" {
+		pomMetadata, err = maven_module.ParsePackageMetaData(buf)
+		if err != nil {
+			log.Error("Error parsing package metadata: %v", err)
+		} else if pomMetadata != nil {
+			pvci.Metadata = pomMetadata
+		}
+
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	pv, _, err := packages_service.CreatePackageOrAddFileToExisting(
+		pvci,
+		&packages_service.PackageFileInfo{
+			Filename: params.Filename,
+			Data:     buf,
+		},
+	)
+	if err != nil {
+		if err == models.ErrPackageFileAlreadyExist {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// The pom may be uploaded after the first file created the version
+	if pomMetadata != nil {
+		metadataJSON, err := json.Marshal(pomMetadata)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		pv.MetadataJSON = string(metadataJSON)
+		if err := models.UpdatePackageVersion(pv); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+func isChecksumExtension(ext string) bool {
+	return ext == extensionMD5 || ext == extensionSHA1 || ext == extensionSHA256 || ext == extensionSHA512
+}
+
+type parameters struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Filename   string
+	IsMeta     bool
+}
+
+// PackageName returns the name of the package which combines group and artifact id
+func (p parameters) PackageName() string {
+	return p.GroupID + ":" + p.ArtifactID
+}
+
+func extractPathParameters(path string) (parameters, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	p := parameters{
+		Filename: parts[len(parts)-1],
+	}
+
+	p.IsMeta = p.Filename == mavenMetadataFile ||
+		p.Filename == mavenMetadataFile+extensionMD5 ||
+		p.Filename == mavenMetadataFile+extensionSHA1 ||
+		p.Filename == mavenMetadataFile+extensionSHA256 ||
+		p.Filename == mavenMetadataFile+extensionSHA512
+
+	parts = parts[:len(parts)-1]
+	if len(parts) == 0 {
+		return p, errInvalidParameters
+	}
+
+	p.Version = parts[len(parts)-1]
+	if p.IsMeta && !strings.HasSuffix(p.Version, "-SNAPSHOT") {
+		p.Version = ""
+	} else {
+		parts = parts[:len(parts)-1]
+	}
+
+	if illegalCharacters.MatchString(p.Version) {
+		return p, errInvalidParameters
+	}
+
+	if len(parts) < 2 {
+		return p, errInvalidParameters
+	}
+
+	p.ArtifactID = parts[len(parts)-1]
+	p.GroupID = strings.Join(parts[:len(parts)-1], ".")
+
+	if illegalCharacters.MatchString(p.GroupID) || illegalCharacters.MatchString(p.ArtifactID) {
+		return p, errInvalidParameters
+	}
+
+	return p, nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maven
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPathParameters(t *testing.T) {
+	p, err := extractPathParameters("/org/gitea/gitea-test/1.0.0/gitea-test-1.0.0.jar")
+	assert.NoError(t, err)
+	assert.Equal(t, parameters{GroupID: "org.gitea", ArtifactID: "gitea-test", Version: "1.0.0", Filename: "gitea-test-1.0.0.jar"}, p)
+	assert.Equal(t, "org.gitea:gitea-test", p.PackageName())
+
+	p, err = extractPathParameters("org/gitea/gitea-test/maven-metadata.xml.sha1")
+	assert.NoError(t, err)
+	assert.Equal(t, parameters{GroupID: "org.gitea", ArtifactID: "gitea-test", Filename: "maven-metadata.xml.sha1", IsMeta: true}, p)
+
+	p, err = extractPathParameters("org/gitea/gitea-test/1.0.0-SNAPSHOT/maven-metadata.xml")
+	assert.NoError(t, err)
+	assert.Equal(t, parameters{GroupID: "org.gitea", ArtifactID: "gitea-test", Version: "1.0.0-SNAPSHOT", Filename: "maven-metadata.xml", IsMeta: true}, p)
+
+	_, err = extractPathParameters("gitea-test/1.0.0/gitea-test-1.0.0.jar")
+	assert.Equal(t, errInvalidParameters, err)
+	_, err = extractPathParameters("file.jar")
+	assert.Equal(t, errInvalidParameters, err)
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	npm_module "code.gitea.io/gitea/modules/packages/npm"
+)
+
+// createPackageMetadataResponse builds the package document from all versions.
+// The versions must be ordered from newest to oldest, the newest one is tagged as "latest".
+func createPackageMetadataResponse(registryURL string, p *models.Package, versions []*models.PackageVersion) (*npm_module.PackageMetadata, error) {
+	resp := &npm_module.PackageMetadata{
+		ID:       p.Name,
+		Name:     p.Name,
+		DistTags: map[string]string{"latest": versions[0].Version},
+		Versions: make(map[string]*npm_module.PackageMetadataVersion, len(versions)),
+		Time:     make(map[string]string, len(versions)),
+	}
+
+	for i, pv := range versions {
+		var metadata npm_module.Metadata
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &metadata); err != nil {
+			return nil, fmt.Errorf("unable to parse metadata of %s@%s: %v", p.Name, pv.Version, err)
+		}
+
+		files, err := models.GetPackageFilesByVersionID(pv.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		if i == 0 {
+			resp.Description = metadata.Description
+			resp.Readme = metadata.Readme
+			resp.Homepage = metadata.ProjectURL
+			resp.Keywords = metadata.Keywords
+			resp.License = metadata.License
+		}
+
+		resp.Time[pv.Version] = pv.CreatedUnix.AsTime().UTC().Format(time.RFC3339)
+		resp.Versions[pv.Version] = &npm_module.PackageMetadataVersion{
+			ID:                   fmt.Sprintf("%s@%s", p.Name, pv.Version),
+			Name:                 p.Name,
+			Version:              pv.Version,
+			Description:          metadata.Description,
+			Author:               metadata.Author,
+			Homepage:             metadata.ProjectURL,
+			License:              metadata.License,
+			Keywords:             metadata.Keywords,
+			Dependencies:         metadata.Dependencies,
+			DevDependencies:      metadata.DevDependencies,
+			PeerDependencies:     metadata.PeerDependencies,
+			OptionalDependencies: metadata.OptionalDependencies,
+			Readme:               metadata.Readme,
+			Dist: npm_module.PackageDistribution{
+				Integrity: metadata.Integrity,
+				Shasum:    metadata.Shasum,
+				Tarball:   fmt.Sprintf("%s/%s/-/%s/%s", registryURL, url.QueryEscape(p.Name), url.PathEscape(pv.Version), url.PathEscape(files[0].Name)),
+			},
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package npm
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	npm_module "code.gitea.io/gitea/modules/packages/npm"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+func apiError(ctx *context.APIContext, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.JSON(status, map[string]string{
+			"error": message,
+		})
+	})
+}
+
+func packageNameFromParams(ctx *context.APIContext) (string, error) {
+	packageName, err := url.QueryUnescape(ctx.Params("id"))
+	if err != nil {
+		return "", err
+	}
+	return packageName, nil
+}
+
+// PackageMetadata returns the metadata for a single package
+func PackageMetadata(ctx *context.APIContext) {
+	packageName, err := packageNameFromParams(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageNpm, packageName)
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	versions, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(versions) == 0 {
+		apiError(ctx, http.StatusNotFound, models.ErrPackageVersionNotExist)
+		return
+	}
+
+	resp, err := createPackageMetadataResponse(
+		setting.AppURL+"api/packages/"+url.PathEscape(ctx.Package.Owner.Name)+"/npm",
+		p,
+		versions,
+	)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// DownloadPackageFile serves the content of a package
+func DownloadPackageFile(ctx *context.APIContext) {
+	packageName, err := packageNameFromParams(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: models.PackageNpm,
+			Name:        packageName,
+			Version:     ctx.Params("version"),
+		},
+		ctx.Params("filename"),
+	)
+	if err != nil {
+		if err == models.ErrPackageVersionNotExist || err == models.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(pf.Name, s, pf.CreatedUnix.AsTime())
+}
+
+// UploadPackage creates a new package
+func UploadPackage(ctx *context.APIContext) {
+	npmPackage, err := npm_module.ParsePackage(ctx.Req.Body)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(bytes.NewReader(npmPackage.Data))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: models.PackageNpm,
+				Name:        npmPackage.Name,
+				Version:     npmPackage.Version,
+			},
+			Creator:  ctx.User,
+			Metadata: npmPackage.Metadata,
+		},
+		&packages_service.PackageFileInfo{
+			Filename: npmPackage.Filename,
+			Data:     buf,
+		},
+	)
+	if err != nil {
+		if err == models.ErrPackageVersionAlreadyExist {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DeletePackageVersion deletes the package version
+func DeletePackageVersion(ctx *context.APIContext) {
+	packageName, err := packageNameFromParams(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	err = packages_service.DeletePackageVersionByNameAndVersion(
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: models.PackageNpm,
+			Name:        packageName,
+			Version:     ctx.Params("version"),
+		},
+	)
+	if err != nil {
+		if err == models.ErrPackageNotExist || err == models.ErrPackageVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// DeletePackage deletes the package with all its versions
+func DeletePackage(ctx *context.APIContext) {
+	packageName, err := packageNameFromParams(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackageNpm, packageName)
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := models.DeletePackage(p); err != nil {
+		apiError(ctx, http.StatusInternalServerError, fmt.Errorf("DeletePackage: %v", err))
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
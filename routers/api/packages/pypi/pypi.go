@@ -0,0 +1,213 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pypi
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+var filenameRegex = regexp.MustCompile(`\A[A-Za-z0-9\.\_\-\+]+\z`)
+
+// https://www.python.org/dev/peps/pep-0503/
+var simpleIndexTemplate = template.Must(template.New("simple").Parse(`<!DOCTYPE html>
+<html>
+	<head>
+		<title>Links for {{.Name}}</title>
+	</head>
+	<body>
+		<h1>Links for {{.Name}}</h1>
+		{{range .Files}}<a href="{{.URL}}#sha256={{.HashSHA256}}"{{if .RequiresPython}} data-requires-python="{{.RequiresPython}}"{{end}}>{{.Name}}</a><br/>
+		{{end}}
+	</body>
+</html>
+`))
+
+type simpleIndexFile struct {
+	Name           string
+	URL            string
+	HashSHA256     string
+	RequiresPython string
+}
+
+func apiError(ctx *context.APIContext, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, []byte(message))
+	})
+}
+
+// PackageMetadata returns the simple index of the package
+func PackageMetadata(ctx *context.APIContext) {
+	packageName := pypi_module.NormalizeName(ctx.Params("id"))
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, models.PackagePyPI, packageName)
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	versions, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	registryURL := setting.AppURL + "api/packages/" + url.PathEscape(ctx.Package.Owner.Name) + "/pypi"
+
+	files := make([]*simpleIndexFile, 0, len(versions))
+	for _, pv := range versions {
+		var metadata pypi_module.Metadata
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &metadata); err != nil {
+			log.Error("Unable to parse metadata of %s %s: %v", p.Name, pv.Version, err)
+		}
+
+		pfs, err := models.GetPackageFilesByVersionID(pv.ID)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		for _, pf := range pfs {
+			if err := pf.LoadBlob(); err != nil {
+				apiError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+			files = append(files, &simpleIndexFile{
+				Name:           pf.Name,
+				URL:            registryURL + "/files/" + url.PathEscape(p.Name) + "/" + url.PathEscape(pv.Version) + "/" + url.PathEscape(pf.Name),
+				HashSHA256:     pf.Blob.HashSHA256,
+				RequiresPython: metadata.RequiresPython,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := simpleIndexTemplate.Execute(&buf, map[string]interface{}{
+		"Name":  p.Name,
+		"Files": files,
+	}); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write(buf.Bytes()); err != nil {
+		log.Error("write bytes failed: %v", err)
+	}
+}
+
+// DownloadPackageFile serves the content of a package
+func DownloadPackageFile(ctx *context.APIContext) {
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: models.PackagePyPI,
+			Name:        pypi_module.NormalizeName(ctx.Params("id")),
+			Version:     ctx.Params("version"),
+		},
+		ctx.Params("filename"),
+	)
+	if err != nil {
+		if err == models.ErrPackageVersionNotExist || err == models.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(pf.Name, s, pf.CreatedUnix.AsTime())
+}
+
+// UploadPackageFile adds a file to the package. If the package does not exist, it gets created.
+// The request is sent by twine as multipart form.
+func UploadPackageFile(ctx *context.APIContext) {
+	file, fileHeader, err := ctx.Req.FormFile("content")
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(file)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	_, _, hashSHA256, _ := buf.Sums()
+	if expected := ctx.Req.FormValue("sha256_digest"); expected != "" && !strings.EqualFold(expected, hashSHA256) {
+		apiError(ctx, http.StatusBadRequest, "hash mismatch")
+		return
+	}
+
+	packageName := strings.TrimSpace(ctx.Req.FormValue("name"))
+	packageVersion := strings.TrimSpace(ctx.Req.FormValue("version"))
+	if !pypi_module.IsValidName(packageName) || !pypi_module.IsValidVersion(packageVersion) || !filenameRegex.MatchString(fileHeader.Filename) {
+		apiError(ctx, http.StatusBadRequest, "invalid name, version or filename")
+		return
+	}
+
+	projectURL := ctx.Req.FormValue("home_page")
+	if !strings.HasPrefix(projectURL, "http://") && !strings.HasPrefix(projectURL, "https://") {
+		projectURL = ""
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: models.PackagePyPI,
+				Name:        pypi_module.NormalizeName(packageName),
+				Version:     packageVersion,
+			},
+			Creator: ctx.User,
+			Metadata: &pypi_module.Metadata{
+				Author:          ctx.Req.FormValue("author"),
+				Description:     ctx.Req.FormValue("description"),
+				LongDescription: ctx.Req.FormValue("long_description"),
+				Summary:         ctx.Req.FormValue("summary"),
+				ProjectURL:      projectURL,
+				License:         ctx.Req.FormValue("license"),
+				RequiresPython:  ctx.Req.FormValue("requires_python"),
+				Keywords:        strings.FieldsFunc(ctx.Req.FormValue("keywords"), func(r rune) bool { return r == ',' || r == ' ' }),
+			},
+		},
+		&packages_service.PackageFileInfo{
+			Filename: fileHeader.Filename,
+			Data:     buf,
+		},
+	)
+	if err != nil {
+		if err == models.ErrPackageFileAlreadyExist {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
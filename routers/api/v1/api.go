@@ -65,6 +65,7 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
@@ -79,6 +80,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/api/v1/notify"
 	"code.gitea.io/gitea/routers/api/v1/org"
+	"code.gitea.io/gitea/routers/api/v1/packages"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/settings"
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
@@ -98,6 +100,12 @@ func sudo() func(ctx *context.APIContext) {
 		}
 
 		if len(sudo) > 0 {
+			if token := ctx.AccessToken(); token != nil && !token.HasScope(models.AccessTokenScopeAll) {
+				ctx.JSON(http.StatusForbidden, map[string]string{
+					"message": "Only tokens with all scopes are allowed to sudo.",
+				})
+				return
+			}
 			if ctx.IsSigned && ctx.User.IsAdmin {
 				user, err := models.GetUserByName(sudo)
 				if err != nil {
@@ -183,6 +191,58 @@ func repoAssignment() func(ctx *context.APIContext) {
 			ctx.NotFound()
 			return
 		}
+
+		if token := ctx.AccessToken(); token != nil && !token.CanAccessRepo(repo.ID) {
+			ctx.NotFound()
+			return
+		}
+	}
+}
+
+// tokenRequiresScopes requires a request authenticated with an access token to be granted at least one of the scopes.
+// Requests authenticated by other means are not limited.
+func tokenRequiresScopes(scopes ...models.AccessTokenScope) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		token := ctx.AccessToken()
+		if token == nil || token.Scope.HasAnyScope(scopes...) {
+			return
+		}
+		ctx.Error(http.StatusForbidden, "tokenRequiresScopes", fmt.Sprintf("token requires one of the scopes %v", scopes))
+	}
+}
+
+// tokenRequiresScopesToWrite is like tokenRequiresScopes but only limits requests which may modify data
+func tokenRequiresScopesToWrite(scopes ...models.AccessTokenScope) func(ctx *context.APIContext) {
+	check := tokenRequiresScopes(scopes...)
+	return func(ctx *context.APIContext) {
+		if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+			return
+		}
+		check(ctx)
+	}
+}
+
+// tokenRequiresRepoScopes requires the repo:read scope to read and the repo:write scope to modify data.
+// The additional scopes grant both.
+func tokenRequiresRepoScopes(additional ...models.AccessTokenScope) func(ctx *context.APIContext) {
+	readCheck := tokenRequiresScopes(append([]models.AccessTokenScope{models.AccessTokenScopeRepoRead}, additional...)...)
+	writeCheck := tokenRequiresScopes(append([]models.AccessTokenScope{models.AccessTokenScopeRepoWrite}, additional...)...)
+	return func(ctx *context.APIContext) {
+		if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+			readCheck(ctx)
+		} else {
+			writeCheck(ctx)
+		}
+	}
+}
+
+// tokenRequiresAllRepos refuses access tokens restricted to some repositories, e.g. to create a repository
+// which the token couldn't access afterwards.
+func tokenRequiresAllRepos() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if len(ctx.AccessTokenRepoIDs()) > 0 {
+			ctx.Error(http.StatusForbidden, "tokenRequiresAllRepos", "token is restricted to some repositories")
+		}
 	}
 }
 
@@ -449,6 +509,20 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 	}
 }
 
+// reqPackageAccess user should have the specific access mode on the packages of the owner
+func reqPackageAccess(accessMode models.AccessMode) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if ctx.Package.AccessMode == models.AccessModeNone {
+			ctx.NotFound()
+			return
+		}
+		if ctx.Package.AccessMode < accessMode {
+			ctx.Error(http.StatusForbidden, "reqPackageAccess", "user should have specific permission or be a site admin")
+			return
+		}
+	}
+}
+
 func mustEnableIssues(ctx *context.APIContext) {
 	if !ctx.Repo.CanRead(models.UnitTypeIssues) {
 		if log.IsTrace() {
@@ -607,7 +681,7 @@ func Routes() *web.Route {
 			m.Combo("/threads/{id}").
 				Get(notify.GetThread).
 				Patch(notify.ReadThread)
-		}, reqToken())
+		}, reqToken(), tokenRequiresScopes(models.AccessTokenScopeNotification))
 
 		// Users
 		m.Group("/users", func() {
@@ -625,7 +699,7 @@ func Routes() *web.Route {
 					m.Combo("").Get(user.ListAccessTokens).
 						Post(bind(api.CreateAccessTokenOption{}), user.CreateAccessToken)
 					m.Combo("/{id}").Delete(user.DeleteAccessToken)
-				}, reqBasicAuth())
+				}, reqBasicAuth(), tokenRequiresScopes(models.AccessTokenScopeAll))
 			})
 		})
 
@@ -646,8 +720,8 @@ func Routes() *web.Route {
 			})
 		}, reqToken())
 
+		m.Get("/user", reqToken(), user.GetAuthenticatedUser)
 		m.Group("/user", func() {
-			m.Get("", user.GetAuthenticatedUser)
 			m.Combo("/emails").Get(user.ListEmails).
 				Post(bind(api.CreateEmailOption{}), user.AddEmail).
 				Delete(bind(api.DeleteEmailOption{}), user.DeleteEmail)
@@ -682,7 +756,7 @@ func Routes() *web.Route {
 			})
 
 			m.Combo("/repos").Get(user.ListMyRepos).
-				Post(bind(api.CreateRepoOption{}), repo.Create)
+				Post(tokenRequiresRepoScopes(), tokenRequiresAllRepos(), bind(api.CreateRepoOption{}), repo.Create)
 
 			m.Group("/starred", func() {
 				m.Get("", user.GetMyStarredRepos)
@@ -699,26 +773,31 @@ func Routes() *web.Route {
 			m.Get("/subscriptions", user.GetMyWatchedRepos)
 
 			m.Get("/teams", org.ListUserTeams)
-		}, reqToken())
+		}, reqToken(), tokenRequiresScopes(models.AccessTokenScopeUser))
 
 		// Repositories
-		m.Post("/org/{org}/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepoDeprecated)
+		m.Post("/org/{org}/repos", reqToken(), tokenRequiresScopes(models.AccessTokenScopeAdminOrg), tokenRequiresAllRepos(), bind(api.CreateRepoOption{}), repo.CreateOrgRepoDeprecated)
 
-		m.Combo("/repositories/{id}", reqToken()).Get(repo.GetByID)
+		m.Combo("/repositories/{id}", reqToken(), tokenRequiresRepoScopes()).Get(repo.GetByID)
 
 		m.Group("/repos", func() {
-			m.Get("/search", repo.Search)
+			m.Get("/search", tokenRequiresRepoScopes(), repo.Search)
+			m.Get("/search/code", tokenRequiresRepoScopes(), repo.SearchCode)
 
-			m.Get("/issues/search", repo.SearchIssues)
+			m.Get("/issues/search", tokenRequiresRepoScopes(models.AccessTokenScopeIssue), repo.SearchIssues)
 
-			m.Post("/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
+			m.Post("/migrate", reqToken(), tokenRequiresRepoScopes(), tokenRequiresAllRepos(), bind(api.MigrateRepoOptions{}), repo.Migrate)
+			m.Post("/migrate/dump", reqToken(), tokenRequiresRepoScopes(), tokenRequiresAllRepos(), repo.MigrateDump)
 
 			m.Group("/{username}/{reponame}", func() {
-				m.Combo("").Get(reqAnyRepoReader(), repo.Get).
-					Delete(reqToken(), reqOwner(), repo.Delete).
+				m.Combo("", tokenRequiresRepoScopes()).Get(reqAnyRepoReader(), repo.Get).
+					Delete(reqToken(), tokenRequiresScopes(models.AccessTokenScopeAll), reqOwner(), repo.Delete).
 					Patch(reqToken(), reqAdmin(), context.RepoRefForAPI, bind(api.EditRepoOption{}), repo.Edit)
-				m.Post("/transfer", reqOwner(), bind(api.TransferRepoOption{}), repo.Transfer)
-				m.Combo("/notifications").
+				m.Post("/transfer", tokenRequiresScopes(models.AccessTokenScopeAll), reqOwner(), bind(api.TransferRepoOption{}), repo.Transfer)
+				m.Combo("/export", tokenRequiresRepoScopes(), reqToken(), reqAdmin()).
+					Get(repo.GetExport).
+					Post(repo.CreateExport)
+				m.Combo("/notifications", tokenRequiresScopes(models.AccessTokenScopeNotification)).
 					Get(reqToken(), notify.ListRepoNotifications).
 					Put(reqToken(), notify.ReadRepoNotifications)
 				m.Group("/hooks/git", func() {
@@ -728,7 +807,7 @@ func Routes() *web.Route {
 							Patch(bind(api.EditGitHookOption{}), repo.EditGitHook).
 							Delete(repo.DeleteGitHook)
 					})
-				}, reqToken(), reqAdmin(), reqGitHook(), context.ReferencesGitRepo(true))
+				}, tokenRequiresRepoScopes(), reqToken(), reqAdmin(), reqGitHook(), context.ReferencesGitRepo(true))
 				m.Group("/hooks", func() {
 					m.Combo("").Get(repo.ListHooks).
 						Post(bind(api.CreateHookOption{}), repo.CreateHook)
@@ -737,30 +816,32 @@ func Routes() *web.Route {
 							Patch(bind(api.EditHookOption{}), repo.EditHook).
 							Delete(repo.DeleteHook)
 						m.Post("/tests", context.RepoRefForAPI, repo.TestHook)
+						m.Get("/deliveries/failed", repo.ListFailedHookDeliveries)
+						m.Post("/deliveries/failed/redeliver", bind(api.RedeliverHookOption{}), repo.RedeliverFailedHookDeliveries)
 					})
-				}, reqToken(), reqAdmin(), reqWebhooksEnabled())
+				}, tokenRequiresRepoScopes(), reqToken(), reqAdmin(), reqWebhooksEnabled())
 				m.Group("/collaborators", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListCollaborators)
 					m.Combo("/{collaborator}").Get(reqAnyRepoReader(), repo.IsCollaborator).
 						Put(reqAdmin(), bind(api.AddCollaboratorOption{}), repo.AddCollaborator).
 						Delete(reqAdmin(), repo.DeleteCollaborator)
-				}, reqToken())
+				}, tokenRequiresRepoScopes(), reqToken())
 				m.Group("/teams", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListTeams)
 					m.Combo("/{team}").Get(reqAnyRepoReader(), repo.IsTeam).
 						Put(reqAdmin(), repo.AddTeam).
 						Delete(reqAdmin(), repo.DeleteTeam)
-				}, reqToken())
-				m.Get("/raw/*", context.RepoRefForAPI, reqRepoReader(models.UnitTypeCode), repo.GetRawFile)
-				m.Get("/archive/*", reqRepoReader(models.UnitTypeCode), repo.GetArchive)
-				m.Combo("/forks").Get(repo.ListForks).
-					Post(reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
+				}, tokenRequiresRepoScopes(), reqToken())
+				m.Get("/raw/*", tokenRequiresRepoScopes(), context.RepoRefForAPI, reqRepoReader(models.UnitTypeCode), repo.GetRawFile)
+				m.Get("/archive/*", tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode), repo.GetArchive)
+				m.Combo("/forks", tokenRequiresRepoScopes()).Get(repo.ListForks).
+					Post(reqToken(), tokenRequiresAllRepos(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
 				m.Group("/branches", func() {
 					m.Get("", repo.ListBranches)
 					m.Get("/*", repo.GetBranch)
 					m.Delete("/*", context.ReferencesGitRepo(false), reqRepoWriter(models.UnitTypeCode), repo.DeleteBranch)
 					m.Post("", reqRepoWriter(models.UnitTypeCode), bind(api.CreateBranchRepoOption{}), repo.CreateBranch)
-				}, reqRepoReader(models.UnitTypeCode))
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode))
 				m.Group("/branch_protections", func() {
 					m.Get("", repo.ListBranchProtections)
 					m.Post("", bind(api.CreateBranchProtectionOption{}), repo.CreateBranchProtection)
@@ -769,21 +850,21 @@ func Routes() *web.Route {
 						m.Patch("", bind(api.EditBranchProtectionOption{}), repo.EditBranchProtection)
 						m.Delete("", repo.DeleteBranchProtection)
 					})
-				}, reqToken(), reqAdmin())
+				}, tokenRequiresRepoScopes(), reqToken(), reqAdmin())
 				m.Group("/tags", func() {
 					m.Get("", repo.ListTags)
 					m.Delete("/{tag}", repo.DeleteTag)
-				}, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(true))
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(true))
 				m.Group("/keys", func() {
 					m.Combo("").Get(repo.ListDeployKeys).
 						Post(bind(api.CreateKeyOption{}), repo.CreateDeployKey)
 					m.Combo("/{id}").Get(repo.GetDeployKey).
 						Delete(repo.DeleteDeploykey)
-				}, reqToken(), reqAdmin())
+				}, tokenRequiresRepoScopes(), reqToken(), reqAdmin())
 				m.Group("/times", func() {
 					m.Combo("").Get(repo.ListTrackedTimesByRepository)
 					m.Combo("/{timetrackingusername}").Get(repo.ListTrackedTimesByUser)
-				}, mustEnableIssues, reqToken())
+				}, tokenRequiresRepoScopes(models.AccessTokenScopeIssue), mustEnableIssues, reqToken())
 				m.Group("/issues", func() {
 					m.Combo("").Get(repo.ListIssues).
 						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
@@ -840,14 +921,14 @@ func Routes() *web.Route {
 							Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueReaction).
 							Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueReaction)
 					})
-				}, mustEnableIssuesOrPulls)
+				}, tokenRequiresRepoScopes(models.AccessTokenScopeIssue), mustEnableIssuesOrPulls)
 				m.Group("/labels", func() {
 					m.Combo("").Get(repo.ListLabels).
 						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateLabelOption{}), repo.CreateLabel)
 					m.Combo("/{id}").Get(repo.GetLabel).
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
-				})
+				}, tokenRequiresRepoScopes(models.AccessTokenScopeIssue))
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
 				m.Group("/milestones", func() {
@@ -856,14 +937,45 @@ func Routes() *web.Route {
 					m.Combo("/{id}").Get(repo.GetMilestone).
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteMilestone)
-				})
-				m.Get("/stargazers", repo.ListStargazers)
-				m.Get("/subscribers", repo.ListSubscribers)
+				}, tokenRequiresRepoScopes(models.AccessTokenScopeIssue))
+				m.Group("/projects", func() {
+					m.Combo("").Get(repo.ListProjects).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, bind(api.CreateProjectOption{}), repo.CreateProject)
+					m.Group("/{id}", func() {
+						m.Combo("").Get(repo.GetProject).
+							Patch(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, bind(api.EditProjectOption{}), repo.EditProject).
+							Delete(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, repo.DeleteProject)
+						m.Group("/boards", func() {
+							m.Combo("").Get(repo.ListProjectBoards).
+								Post(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, bind(api.CreateProjectBoardOption{}), repo.CreateProjectBoard)
+							m.Post("/sort", reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, bind(api.SortProjectBoardsOption{}), repo.SortProjectBoards)
+							m.Group("/{board}", func() {
+								m.Combo("").
+									Patch(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, bind(api.EditProjectBoardOption{}), repo.EditProjectBoard).
+									Delete(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, repo.DeleteProjectBoard)
+								m.Combo("/issues").Get(repo.ListProjectBoardIssues).
+									Post(reqToken(), reqRepoWriter(models.UnitTypeProjects), mustNotBeArchived, bind(api.MoveProjectIssueOption{}), repo.MoveProjectIssue)
+							})
+						})
+					})
+				}, tokenRequiresRepoScopes(models.AccessTokenScopeIssue), reqRepoReader(models.UnitTypeProjects))
+				m.Group("/wiki/pages", func() {
+					m.Combo("").Get(repo.ListWikiPages).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeWiki), mustNotBeArchived, bind(api.CreateWikiPageOptions{}), repo.CreateWikiPage)
+					m.Group("/{pageName}", func() {
+						m.Combo("").Get(repo.GetWikiPage).
+							Patch(reqToken(), reqRepoWriter(models.UnitTypeWiki), mustNotBeArchived, bind(api.CreateWikiPageOptions{}), repo.EditWikiPage).
+							Delete(reqToken(), reqRepoWriter(models.UnitTypeWiki), mustNotBeArchived, repo.DeleteWikiPage)
+						m.Get("/revisions", repo.ListWikiPageRevisions)
+					})
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeWiki))
+				m.Get("/stargazers", tokenRequiresRepoScopes(), repo.ListStargazers)
+				m.Get("/subscribers", tokenRequiresRepoScopes(), repo.ListSubscribers)
 				m.Group("/subscription", func() {
 					m.Get("", user.IsWatching)
 					m.Put("", reqToken(), user.Watch)
 					m.Delete("", reqToken(), user.Unwatch)
-				})
+				}, tokenRequiresRepoScopes())
 				m.Group("/releases", func() {
 					m.Combo("").Get(repo.ListReleases).
 						Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.CreateReleaseOption{}), repo.CreateRelease)
@@ -884,9 +996,18 @@ func Routes() *web.Route {
 							Get(repo.GetReleaseByTag).
 							Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseByTag)
 					})
-				}, reqRepoReader(models.UnitTypeReleases))
-				m.Post("/mirror-sync", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
-				m.Get("/editorconfig/{filename}", context.RepoRefForAPI, reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeReleases))
+				m.Post("/mirror-sync", tokenRequiresRepoScopes(), reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
+				m.Post("/push_mirrors-sync", tokenRequiresRepoScopes(), reqToken(), reqAdmin(), repo.PushMirrorSync)
+				m.Group("/push_mirrors", func() {
+					m.Combo("").Get(repo.ListPushMirrors).
+						Post(bind(api.CreatePushMirrorOption{}), repo.AddPushMirror)
+					m.Combo("/{id}").
+						Get(repo.GetPushMirror).
+						Patch(bind(api.EditPushMirrorOption{}), repo.EditPushMirror).
+						Delete(repo.DeletePushMirror)
+				}, tokenRequiresRepoScopes(), reqToken(), reqAdmin())
+				m.Get("/editorconfig/{filename}", tokenRequiresRepoScopes(), context.RepoRefForAPI, reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
 					m.Combo("").Get(repo.ListPullRequests).
 						Post(reqToken(), mustNotBeArchived, bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
@@ -897,7 +1018,8 @@ func Routes() *web.Route {
 						m.Get(".patch", repo.DownloadPullPatch)
 						m.Post("/update", reqToken(), repo.UpdatePullRequest)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
-							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest)
+							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest).
+							Delete(reqToken(), mustNotBeArchived, repo.CancelScheduledAutoMerge)
 						m.Group("/reviews", func() {
 							m.Combo("").
 								Get(repo.ListPullReviews).
@@ -917,18 +1039,18 @@ func Routes() *web.Route {
 							Delete(reqToken(), bind(api.PullReviewRequestOptions{}), repo.DeleteReviewRequests).
 							Post(reqToken(), bind(api.PullReviewRequestOptions{}), repo.CreateReviewRequests)
 					})
-				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
+				}, tokenRequiresRepoScopes(), mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
 				m.Group("/statuses", func() {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
 						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
-				}, reqRepoReader(models.UnitTypeCode))
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode))
 				m.Group("/commits", func() {
 					m.Get("", repo.GetAllCommits)
 					m.Group("/{ref}", func() {
 						m.Get("/status", repo.GetCombinedCommitStatusByRef)
 						m.Get("/statuses", repo.GetCommitStatusesByRef)
 					})
-				}, reqRepoReader(models.UnitTypeCode))
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode))
 				m.Group("/git", func() {
 					m.Group("/commits", func() {
 						m.Get("/{sha}", repo.GetSingleCommit)
@@ -938,7 +1060,7 @@ func Routes() *web.Route {
 					m.Get("/trees/{sha}", context.RepoRefForAPI, repo.GetTree)
 					m.Get("/blobs/{sha}", context.RepoRefForAPI, repo.GetBlob)
 					m.Get("/tags/{sha}", context.RepoRefForAPI, repo.GetTag)
-				}, reqRepoReader(models.UnitTypeCode))
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode))
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContentsList)
 					m.Get("/*", repo.GetContents)
@@ -947,8 +1069,8 @@ func Routes() *web.Route {
 						m.Put("", bind(api.UpdateFileOptions{}), repo.UpdateFile)
 						m.Delete("", bind(api.DeleteFileOptions{}), repo.DeleteFile)
 					}, reqRepoWriter(models.UnitTypeCode), reqToken())
-				}, reqRepoReader(models.UnitTypeCode))
-				m.Get("/signing-key.gpg", misc.SigningKey)
+				}, tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode))
+				m.Get("/signing-key.gpg", tokenRequiresRepoScopes(), misc.SigningKey)
 				m.Group("/topics", func() {
 					m.Combo("").Get(repo.ListTopics).
 						Put(reqToken(), reqAdmin(), bind(api.RepoTopicOptions{}), repo.UpdateTopics)
@@ -956,23 +1078,23 @@ func Routes() *web.Route {
 						m.Combo("").Put(reqToken(), repo.AddTopic).
 							Delete(reqToken(), repo.DeleteTopic)
 					}, reqAdmin())
-				}, reqAnyRepoReader())
-				m.Get("/issue_templates", context.ReferencesGitRepo(false), repo.GetIssueTemplates)
-				m.Get("/languages", reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
+				}, tokenRequiresRepoScopes(), reqAnyRepoReader())
+				m.Get("/issue_templates", tokenRequiresRepoScopes(), context.ReferencesGitRepo(false), repo.GetIssueTemplates)
+				m.Get("/languages", tokenRequiresRepoScopes(), reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
 			}, repoAssignment())
 		})
 
 		// Organizations
 		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
 		m.Get("/users/{username}/orgs", org.ListUserOrgs)
-		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
+		m.Post("/orgs", reqToken(), tokenRequiresScopes(models.AccessTokenScopeAdminOrg), bind(api.CreateOrgOption{}), org.Create)
 		m.Get("/orgs", org.GetAll)
 		m.Group("/orgs/{org}", func() {
 			m.Combo("").Get(org.Get).
 				Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit).
 				Delete(reqToken(), reqOrgOwnership(), org.Delete)
 			m.Combo("/repos").Get(user.ListOrgRepos).
-				Post(reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+				Post(reqToken(), tokenRequiresAllRepos(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
 			m.Group("/members", func() {
 				m.Get("", org.ListMembers)
 				m.Combo("/{username}").Get(org.IsMember).
@@ -999,11 +1121,15 @@ func Routes() *web.Route {
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
-				m.Combo("/{id}").Get(org.GetHook).
-					Patch(bind(api.EditHookOption{}), org.EditHook).
-					Delete(org.DeleteHook)
-			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
-		}, orgAssignment(true))
+				m.Group("/{id}", func() {
+					m.Combo("").Get(org.GetHook).
+						Patch(bind(api.EditHookOption{}), org.EditHook).
+						Delete(org.DeleteHook)
+					m.Get("/deliveries/failed", org.ListFailedHookDeliveries)
+					m.Post("/deliveries/failed/redeliver", bind(api.RedeliverHookOption{}), org.RedeliverFailedHookDeliveries)
+				})
+			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled(), tokenRequiresScopes(models.AccessTokenScopeAdminOrg))
+		}, orgAssignment(true), tokenRequiresScopesToWrite(models.AccessTokenScopeAdminOrg))
 		m.Group("/teams/{teamid}", func() {
 			m.Combo("").Get(org.GetTeam).
 				Patch(reqOrgOwnership(), bind(api.EditTeamOption{}), org.EditTeam).
@@ -1021,7 +1147,7 @@ func Routes() *web.Route {
 					Put(org.AddTeamRepository).
 					Delete(org.RemoveTeamRepository)
 			})
-		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
+		}, orgAssignment(false, true), reqToken(), reqTeamMembership(), tokenRequiresScopesToWrite(models.AccessTokenScopeAdminOrg))
 
 		m.Group("/admin", func() {
 			m.Group("/cron", func() {
@@ -1049,11 +1175,26 @@ func Routes() *web.Route {
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
 				m.Delete("/{username}/{reponame}", admin.DeleteUnadoptedRepository)
 			})
-		}, reqToken(), reqSiteAdmin())
+		}, reqToken(), reqSiteAdmin(), tokenRequiresScopes(models.AccessTokenScopeAll))
 
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
 		})
+
+		m.Group("/packages/{username}", func() {
+			m.Get("", reqPackageAccess(models.AccessModeRead), packages.ListPackages)
+			m.Group("/{type}/{name}", func() {
+				m.Combo("").
+					Get(reqPackageAccess(models.AccessModeRead), packages.GetPackage).
+					Delete(reqToken(), reqPackageAccess(models.AccessModeWrite), packages.DeletePackage)
+				m.Get("/versions", reqPackageAccess(models.AccessModeRead), packages.ListPackageVersions)
+				m.Delete("/versions/{version}", reqToken(), reqPackageAccess(models.AccessModeWrite), packages.DeletePackageVersion)
+				m.Group("/link", func() {
+					m.Put("/{reponame}", packages.LinkPackage)
+					m.Delete("", packages.UnlinkPackage)
+				}, reqToken(), reqPackageAccess(models.AccessModeWrite))
+			})
+		}, context.PackageAssignmentAPI(), tokenRequiresScopes(models.AccessTokenScopePackage))
 	}, sudo())
 
 	return m
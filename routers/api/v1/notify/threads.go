@@ -109,5 +109,9 @@ func getThread(ctx *context.APIContext) *models.Notification {
 		ctx.Error(http.StatusForbidden, "GetNotificationByID", fmt.Errorf("only user itself and admin are allowed to read/change this thread %d", n.ID))
 		return nil
 	}
+	if token := ctx.AccessToken(); token != nil && !token.CanAccessRepo(n.RepoID) {
+		ctx.NotFound()
+		return nil
+	}
 	return n
 }
@@ -70,6 +70,7 @@ func ListNotifications(ctx *context.APIContext) {
 	opts := models.FindNotificationOptions{
 		ListOptions:       utils.GetListOptions(ctx),
 		UserID:            ctx.User.ID,
+		RepoIDs:           ctx.AccessTokenRepoIDs(),
 		UpdatedBeforeUnix: before,
 		UpdatedAfterUnix:  since,
 	}
@@ -143,6 +144,7 @@ func ReadNotifications(ctx *context.APIContext) {
 	}
 	opts := models.FindNotificationOptions{
 		UserID:            ctx.User.ID,
+		RepoIDs:           ctx.AccessTokenRepoIDs(),
 		UpdatedBeforeUnix: lastRead,
 	}
 	if !ctx.QueryBool("all") {
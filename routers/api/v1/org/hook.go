@@ -13,6 +13,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/webhook"
 )
 
 // ListHooks list an organziation's webhooks
@@ -85,6 +86,84 @@ func GetHook(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToHook(org.HomeLink(), hook))
 }
 
+// ListFailedHookDeliveries list the failed deliveries of an organization's hook
+func ListFailedHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries/failed organization orgListFailedHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook which failed after all retries
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListFailedHookDeliveries(ctx, hook)
+}
+
+// RedeliverFailedHookDeliveries redeliver the failed deliveries of an organization's hook
+func RedeliverFailedHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/hooks/{id}/deliveries/failed/redeliver organization orgRedeliverFailedHookDeliveries
+	// ---
+	// summary: Redeliver deliveries of a hook which failed after all retries
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/RedeliverHookOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverFailedHookDeliveries(ctx, hook)
+}
+
 // CreateHook create a hook for an organization
 func CreateHook(ctx *context.APIContext) {
 	// swagger:operation POST /orgs/{org}/hooks/ organization orgCreateHook
@@ -186,5 +265,6 @@ func DeleteHook(ctx *context.APIContext) {
 		}
 		return
 	}
+	webhook.RemoveWebhookClient(hookID)
 	ctx.Status(http.StatusNoContent)
 }
@@ -0,0 +1,439 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListPackages gets all packages of an owner
+func ListPackages(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner} package listPackages
+	// ---
+	// summary: Gets all packages of an owner
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: query
+	//   description: package type filter
+	//   type: string
+	//   enum: [generic, npm, maven, pypi, container]
+	// - name: q
+	//   in: query
+	//   description: name filter
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listOptions := utils.GetListOptions(ctx)
+
+	opts := &models.PackageSearchOptions{
+		ListOptions: listOptions,
+		OwnerID:     ctx.Package.Owner.ID,
+		Keyword:     ctx.Query("q"),
+	}
+	if typ := ctx.Query("type"); typ != "" {
+		packageType, ok := models.PackageTypeFromName(typ)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "PackageTypeFromName", "unknown package type")
+			return
+		}
+		opts.Type = &packageType
+	}
+
+	packages, count, err := models.SearchPackages(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchPackages", err)
+		return
+	}
+
+	apiPackages := make([]*api.Package, 0, len(packages))
+	for _, p := range packages {
+		p.Owner = ctx.Package.Owner
+		if err := p.LoadAttributes(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiPackage, err := convert.ToPackage(p, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToPackage", err)
+			return
+		}
+		apiPackages = append(apiPackages, apiPackage)
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, apiPackages)
+}
+
+// getPackageByParams returns the package specified by the type and name parameters
+func getPackageByParams(ctx *context.APIContext) *models.Package {
+	packageType, ok := models.PackageTypeFromName(ctx.Params("type"))
+	if !ok {
+		ctx.NotFound()
+		return nil
+	}
+
+	p, err := models.GetPackageByName(ctx.Package.Owner.ID, packageType, ctx.Params("name"))
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPackageByName", err)
+		}
+		return nil
+	}
+
+	p.Owner = ctx.Package.Owner
+	if err := p.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return p
+}
+
+// GetPackage gets a package
+func GetPackage(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name} package getPackage
+	// ---
+	// summary: Gets a package
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Package"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiPackage, err := convert.ToPackage(p, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToPackage", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiPackage)
+}
+
+// DeletePackage deletes a package with all its versions
+func DeletePackage(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/{type}/{name} package deletePackage
+	// ---
+	// summary: Deletes a package with all its versions
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeletePackage(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeletePackage", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListPackageVersions gets all versions of a package
+func ListPackageVersions(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/{type}/{name}/versions package listPackageVersions
+	// ---
+	// summary: Gets all versions of a package
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageVersionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pvs, err := models.GetPackageVersionsByPackageID(p.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPackageVersionsByPackageID", err)
+		return
+	}
+
+	apiVersions := make([]*api.PackageVersion, 0, len(pvs))
+	for _, pv := range pvs {
+		if err := pv.LoadCreator(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadCreator", err)
+			return
+		}
+		pfs, err := models.GetPackageFilesByVersionID(pv.ID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetPackageFilesByVersionID", err)
+			return
+		}
+		for _, pf := range pfs {
+			if err := pf.LoadBlob(); err != nil {
+				ctx.Error(http.StatusInternalServerError, "LoadBlob", err)
+				return
+			}
+		}
+		apiVersions = append(apiVersions, convert.ToPackageVersion(pv, pfs, ctx.User))
+	}
+
+	ctx.JSON(http.StatusOK, apiVersions)
+}
+
+// DeletePackageVersion deletes a version of a package
+func DeletePackageVersion(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/{type}/{name}/versions/{version} package deletePackageVersion
+	// ---
+	// summary: Deletes a version of a package
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: path
+	//   description: version of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pv, err := models.GetPackageVersionByName(p.ID, ctx.Params("version"))
+	if err != nil {
+		if err == models.ErrPackageVersionNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPackageVersionByName", err)
+		}
+		return
+	}
+
+	if err := models.DeletePackageVersion(pv); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeletePackageVersion", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// LinkPackage links a package to a repository of the same owner
+func LinkPackage(ctx *context.APIContext) {
+	// swagger:operation PUT /packages/{owner}/{type}/{name}/link/{repo} package linkPackage
+	// ---
+	// summary: Links a package to a repository of the same owner
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package and the repository
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	repo, err := models.GetRepositoryByName(ctx.Package.Owner.ID, ctx.Params("reponame"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
+		}
+		return
+	}
+
+	mode, err := models.AccessLevel(ctx.User, repo)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+		return
+	}
+	if mode < models.AccessModeRead {
+		ctx.NotFound()
+		return
+	}
+	if mode < models.AccessModeWrite {
+		ctx.Error(http.StatusForbidden, "", "user should have write access to the repository")
+		return
+	}
+
+	if err := models.SetPackageRepositoryLink(p.ID, repo.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetPackageRepositoryLink", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// UnlinkPackage removes the link between a package and its repository
+func UnlinkPackage(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/{type}/{name}/link package unlinkPackage
+	// ---
+	// summary: Removes the link between a package and its repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getPackageByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.SetPackageRepositoryLink(p.ID, 0); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetPackageRepositoryLink", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
@@ -534,6 +535,7 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		WhitelistDeployKeys:           form.EnablePush && form.EnablePushWhitelist && form.PushWhitelistDeployKeys,
 		EnableStatusCheck:             form.EnableStatusCheck,
 		StatusCheckContexts:           form.StatusCheckContexts,
+		EnableMergeQueue:              form.EnableMergeQueue,
 		EnableApprovalsWhitelist:      form.EnableApprovalsWhitelist,
 		RequiredApprovals:             requiredApprovals,
 		BlockOnRejectedReviews:        form.BlockOnRejectedReviews,
@@ -572,6 +574,7 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "New branch protection not found", err)
 		return
 	}
+	notification.NotifyCreateBranchProtection(ctx.User, repo, bp)
 
 	ctx.JSON(http.StatusCreated, convert.ToBranchProtection(bp))
 
@@ -658,6 +661,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.StatusCheckContexts = form.StatusCheckContexts
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
 	if form.RequiredApprovals != nil && *form.RequiredApprovals >= 0 {
 		protectBranch.RequiredApprovals = *form.RequiredApprovals
 	}
@@ -804,6 +811,7 @@ func EditBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "New branch protection not found", err)
 		return
 	}
+	notification.NotifyUpdateBranchProtection(ctx.User, repo, bp)
 
 	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
 }
@@ -853,6 +861,7 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
+	notification.NotifyDeleteBranchProtection(ctx.User, repo, bp)
 
 	ctx.Status(http.StatusNoContent)
 }
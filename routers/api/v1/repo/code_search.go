@@ -0,0 +1,145 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/context"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/search"
+)
+
+// SearchCode searches the code of the repositories the user can read
+func SearchCode(ctx *context.APIContext) {
+	// swagger:operation GET /repos/search/code repository repoSearchCode
+	// ---
+	// summary: Search for code in repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: q
+	//   in: query
+	//   description: keyword, which can be narrowed down with the qualifiers
+	//                "repo:", "lang:", "path:", "ext:" and "branch:"
+	//   type: string
+	//   required: true
+	// - name: language
+	//   in: query
+	//   description: search only for files of this language
+	//   type: string
+	// - name: uid
+	//   in: query
+	//   description: search only the repositories of the user or organization with the given id
+	//   type: integer
+	//   format: int64
+	// - name: exact
+	//   in: query
+	//   description: match the keyword exactly instead of fuzzily
+	//   type: boolean
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeSearchResults"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !setting.Indexer.RepoIndexerEnabled {
+		ctx.NotFound()
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+
+	result, err := search.Code(&search.CodeOptions{
+		Doer:     ctx.User,
+		OwnerID:  ctx.QueryInt64("uid"),
+		RepoIDs:  ctx.AccessTokenRepoIDs(),
+		Query:    strings.TrimSpace(ctx.Query("q")),
+		Language: strings.TrimSpace(ctx.Query("language")),
+		IsMatch:  ctx.QueryBool("exact"),
+		Page:     listOptions.Page,
+		PageSize: listOptions.PageSize,
+	})
+	if code_indexer.IsErrQueryTooBroad(err) {
+		ctx.JSON(http.StatusUnprocessableEntity, api.SearchError{
+			OK:    false,
+			Error: err.Error(),
+		})
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, api.SearchError{
+			OK:    false,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	results := make([]*api.CodeSearchResult, 0, len(result.Results))
+	for _, res := range result.Results {
+		repo, ok := result.Repos[res.RepoID]
+		if !ok {
+			continue
+		}
+		lines := make([]*api.CodeSearchLine, 0, len(res.Lines))
+		for _, line := range res.Lines {
+			lines = append(lines, &api.CodeSearchLine{
+				Number:         line.Num,
+				Content:        line.Content,
+				HighlightStart: line.HighlightStart,
+				HighlightEnd:   line.HighlightEnd,
+			})
+		}
+		branch := res.Branch
+		if len(branch) == 0 {
+			branch = repo.DefaultBranch
+		}
+		results = append(results, &api.CodeSearchResult{
+			RepoID:       repo.ID,
+			RepoFullName: repo.FullName(),
+			Branch:       branch,
+			Path:         res.Filename,
+			Language:     res.Language,
+			CommitID:     res.CommitID,
+			HTMLURL:      repo.HTMLURL() + "/src/commit/" + res.CommitID + "/" + util.PathEscapeSegments(res.Filename),
+			Lines:        lines,
+			Indexed:      res.UpdatedUnix.AsTime(),
+		})
+	}
+
+	languages := make([]*api.CodeSearchLanguage, 0, len(result.Languages))
+	for _, language := range result.Languages {
+		languages = append(languages, &api.CodeSearchLanguage{
+			Language: language.Language,
+			Color:    language.Color,
+			Count:    language.Count,
+		})
+	}
+
+	ctx.SetLinkHeader(result.Total, listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", result.Total))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, api.CodeSearchResults{
+		OK:        true,
+		Data:      results,
+		Languages: languages,
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/task"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// CreateExport requests an export of a repository
+func CreateExport(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/export repository repoCreateExport
+	// ---
+	// summary: Request an export of a repository, it is generated in the background
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	if err := task.ExportRepository(ctx.User, ctx.Repo.Repository); err != nil {
+		if err == task.ErrExportInProgress {
+			ctx.Error(http.StatusConflict, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "ExportRepository", err)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// GetExport downloads the latest export of a repository
+func GetExport(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/export repository repoGetExport
+	// ---
+	// summary: Download the latest export archive of a repository
+	// produces:
+	// - application/zip
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   200:
+	//     description: success
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	exportPath := migrations.RepositoryExportPath(ctx.Repo.Repository)
+	if isExist, err := util.IsFile(exportPath); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsFile", err)
+		return
+	} else if !isExist {
+		ctx.NotFound()
+		return
+	}
+	ctx.ServeFile(exportPath, ctx.Repo.Repository.Name+"-export.zip")
+}
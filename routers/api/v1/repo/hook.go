@@ -100,6 +100,94 @@ func GetHook(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToHook(repo.RepoLink, hook))
 }
 
+// ListFailedHookDeliveries list the failed deliveries of a repo's hook
+func ListFailedHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/hooks/{id}/deliveries/failed repository repoListFailedHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook which failed after all retries
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListFailedHookDeliveries(ctx, hook)
+}
+
+// RedeliverFailedHookDeliveries redeliver the failed deliveries of a repo's hook
+func RedeliverFailedHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/hooks/{id}/deliveries/failed/redeliver repository repoRedeliverFailedHookDeliveries
+	// ---
+	// summary: Redeliver deliveries of a hook which failed after all retries
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/RedeliverHookOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverFailedHookDeliveries(ctx, hook)
+}
+
 // TestHook tests a hook
 func TestHook(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/hooks/{id}/tests repository repoTestHook
@@ -266,5 +354,6 @@ func DeleteHook(ctx *context.APIContext) {
 		}
 		return
 	}
+	webhook.RemoveWebhookClient(ctx.ParamsInt64(":id"))
 	ctx.Status(http.StatusNoContent)
 }
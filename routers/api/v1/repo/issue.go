@@ -24,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	issue_service "code.gitea.io/gitea/services/issue"
+	"code.gitea.io/gitea/services/search"
 )
 
 // SearchIssues searches for issues across the repositories that the user has access to
@@ -44,7 +45,8 @@ func SearchIssues(ctx *context.APIContext) {
 	//   type: string
 	// - name: q
 	//   in: query
-	//   description: search string
+	//   description: search string, which can be narrowed down with the qualifiers "is:", "label:",
+	//                "author:", "assignee:", "mentions:", "milestone:" and "sort:"
 	//   type: string
 	// - name: priority_repo_id
 	//   in: query
@@ -121,6 +123,7 @@ func SearchIssues(ctx *context.APIContext) {
 		// MySQL will return different results when sorting by null in some cases
 		OrderBy: models.SearchOrderByAlphabetically,
 		Actor:   ctx.User,
+		RepoIDs: ctx.AccessTokenRepoIDs(),
 	}
 	if ctx.IsSigned {
 		opts.Private = true
@@ -140,6 +143,13 @@ func SearchIssues(ctx *context.APIContext) {
 	if strings.IndexByte(keyword, 0) >= 0 {
 		keyword = ""
 	}
+	// the issues are restricted to the accessible repositories, so milestones can be looked up in all
+	filter, err := search.ParseIssueQuery(ctx.User, nil, keyword)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ParseIssueQuery", err)
+		return
+	}
+	keyword = filter.Keyword
 	var issueIDs []int64
 	if len(keyword) > 0 && len(repoIDs) > 0 {
 		if issueIDs, err = issue_indexer.SearchIssuesByKeyword(repoIDs, keyword); err != nil {
@@ -175,7 +185,8 @@ func SearchIssues(ctx *context.APIContext) {
 
 	// Only fetch the issues if we either don't have a keyword or the search returned issues
 	// This would otherwise return all issues if no issues were found by the search.
-	if len(keyword) == 0 || len(issueIDs) > 0 || len(includedLabelNames) > 0 {
+	// The same goes for an empty list of repositories.
+	if len(repoIDs) > 0 && (len(keyword) == 0 || len(issueIDs) > 0 || len(includedLabelNames) > 0) {
 		issuesOpt := &models.IssuesOptions{
 			ListOptions: models.ListOptions{
 				Page:     ctx.QueryInt("page"),
@@ -206,17 +217,19 @@ func SearchIssues(ctx *context.APIContext) {
 			issuesOpt.ReviewRequestedID = ctx.User.ID
 		}
 
-		if issues, err = models.Issues(issuesOpt); err != nil {
-			ctx.Error(http.StatusInternalServerError, "Issues", err)
-			return
-		}
+		if filter.Apply(issuesOpt) {
+			if issues, err = models.Issues(issuesOpt); err != nil {
+				ctx.Error(http.StatusInternalServerError, "Issues", err)
+				return
+			}
 
-		issuesOpt.ListOptions = models.ListOptions{
-			Page: -1,
-		}
-		if filteredCount, err = models.CountIssues(issuesOpt); err != nil {
-			ctx.Error(http.StatusInternalServerError, "CountIssues", err)
-			return
+			issuesOpt.ListOptions = models.ListOptions{
+				Page: -1,
+			}
+			if filteredCount, err = models.CountIssues(issuesOpt); err != nil {
+				ctx.Error(http.StatusInternalServerError, "CountIssues", err)
+				return
+			}
 		}
 	}
 
@@ -255,7 +268,8 @@ func ListIssues(ctx *context.APIContext) {
 	//   type: string
 	// - name: q
 	//   in: query
-	//   description: search string
+	//   description: search string, which can be narrowed down with the qualifiers "is:", "label:",
+	//                "author:", "assignee:", "mentions:", "milestone:" and "sort:"
 	//   type: string
 	// - name: type
 	//   in: query
@@ -295,9 +309,14 @@ func ListIssues(ctx *context.APIContext) {
 	if strings.IndexByte(keyword, 0) >= 0 {
 		keyword = ""
 	}
+	filter, err := search.ParseIssueQuery(ctx.User, []int64{ctx.Repo.Repository.ID}, keyword)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ParseIssueQuery", err)
+		return
+	}
+	keyword = filter.Keyword
 	var issueIDs []int64
 	var labelIDs []int64
-	var err error
 	if len(keyword) > 0 {
 		issueIDs, err = issue_indexer.SearchIssuesByKeyword([]int64{ctx.Repo.Repository.ID}, keyword)
 		if err != nil {
@@ -369,17 +388,19 @@ func ListIssues(ctx *context.APIContext) {
 			IsPull:       isPull,
 		}
 
-		if issues, err = models.Issues(issuesOpt); err != nil {
-			ctx.Error(http.StatusInternalServerError, "Issues", err)
-			return
-		}
+		if filter.Apply(issuesOpt) {
+			if issues, err = models.Issues(issuesOpt); err != nil {
+				ctx.Error(http.StatusInternalServerError, "Issues", err)
+				return
+			}
 
-		issuesOpt.ListOptions = models.ListOptions{
-			Page: -1,
-		}
-		if filteredCount, err = models.CountIssues(issuesOpt); err != nil {
-			ctx.Error(http.StatusInternalServerError, "CountIssues", err)
-			return
+			issuesOpt.ListOptions = models.ListOptions{
+				Page: -1,
+			}
+			if filteredCount, err = models.CountIssues(issuesOpt); err != nil {
+				ctx.Error(http.StatusInternalServerError, "CountIssues", err)
+				return
+			}
 		}
 	}
 
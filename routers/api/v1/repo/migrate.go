@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -23,6 +25,7 @@ import (
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
@@ -52,24 +55,8 @@ func Migrate(ctx *context.APIContext) {
 
 	form := web.GetForm(ctx).(*api.MigrateRepoOptions)
 
-	//get repoOwner
-	var (
-		repoOwner *models.User
-		err       error
-	)
-	if len(form.RepoOwner) != 0 {
-		repoOwner, err = models.GetUserByName(form.RepoOwner)
-	} else if form.RepoOwnerID != 0 {
-		repoOwner, err = models.GetUserByID(form.RepoOwnerID)
-	} else {
-		repoOwner = ctx.User
-	}
-	if err != nil {
-		if models.IsErrUserNotExist(err) {
-			ctx.Error(http.StatusUnprocessableEntity, "", err)
-		} else {
-			ctx.Error(http.StatusInternalServerError, "GetUser", err)
-		}
+	repoOwner := getMigrateRepoOwner(ctx, form.RepoOwner, form.RepoOwnerID)
+	if ctx.Written() {
 		return
 	}
 
@@ -78,25 +65,6 @@ func Migrate(ctx *context.APIContext) {
 		return
 	}
 
-	if !ctx.User.IsAdmin {
-		if !repoOwner.IsOrganization() && ctx.User.ID != repoOwner.ID {
-			ctx.Error(http.StatusForbidden, "", "Given user is not an organization.")
-			return
-		}
-
-		if repoOwner.IsOrganization() {
-			// Check ownership of organization.
-			isOwner, err := repoOwner.IsOwnedBy(ctx.User.ID)
-			if err != nil {
-				ctx.Error(http.StatusInternalServerError, "IsOwnedBy", err)
-				return
-			} else if !isOwner {
-				ctx.Error(http.StatusForbidden, "", "Given user is not owner of organization.")
-				return
-			}
-		}
-	}
-
 	remoteAddr, err := forms.ParseRemoteAddr(form.CloneAddr, form.AuthUsername, form.AuthPassword)
 	if err == nil {
 		err = migrations.IsMigrateURLAllowed(remoteAddr, ctx.User)
@@ -153,8 +121,9 @@ func Migrate(ctx *context.APIContext) {
 		Releases:       form.Releases,
 		GitServiceType: gitServiceType,
 		MirrorInterval: form.MirrorInterval,
+		Incremental:    form.Incremental && form.Mirror,
 	}
-	if opts.Mirror {
+	if opts.Mirror && !opts.Incremental {
 		opts.Issues = false
 		opts.Milestones = false
 		opts.Labels = false
@@ -199,15 +168,177 @@ func Migrate(ctx *context.APIContext) {
 		}
 	}()
 
-	if _, err = migrations.MigrateRepository(graceful.GetManager().HammerContext(), ctx.User, repoOwner.Name, opts); err != nil {
+	if _, err = migrations.MigrateRepository(graceful.GetManager().HammerContext(), ctx.User, repoOwner.Name, opts, nil); err != nil {
 		handleMigrateError(ctx, repoOwner, remoteAddr, err)
 		return
 	}
 
+	if opts.Incremental {
+		// mirror updates sync the issues and pull requests with the options of the migration
+		if err = task.RecordMigration(ctx.User, repo, opts); err != nil {
+			ctx.Error(http.StatusInternalServerError, "RecordMigration", err)
+			return
+		}
+	}
+
 	log.Trace("Repository migrated: %s/%s", repoOwner.Name, form.RepoName)
 	ctx.JSON(http.StatusCreated, convert.ToRepo(repo, models.AccessModeAdmin))
 }
 
+// MigrateDump restores a repository from an uploaded dump archive
+func MigrateDump(ctx *context.APIContext) {
+	// swagger:operation POST /repos/migrate/dump repository repoMigrateDump
+	// ---
+	// summary: Restore a repository from a dump archive
+	// description: The archive is a zip, tar or tar.gz file of a directory written by `gitea dump-repo`,
+	//   it is restored in the background while the returned repository is being migrated.
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: dump
+	//   in: formData
+	//   description: dump archive to restore
+	//   type: file
+	//   required: true
+	// - name: repo_name
+	//   in: formData
+	//   description: name of the restored repository
+	//   type: string
+	//   required: true
+	// - name: repo_owner
+	//   in: formData
+	//   description: name of the user or organization owning the restored repository, default is the authenticated user
+	//   type: string
+	// - name: description
+	//   in: formData
+	//   description: description of the restored repository, default is the one of the dump
+	//   type: string
+	// - name: private
+	//   in: formData
+	//   description: whether the restored repository is private
+	//   type: boolean
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     description: The repository with the same name already exists.
+	//   "413":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if setting.Repository.DisableMigrations {
+		ctx.Error(http.StatusForbidden, "MigrationsGlobalDisabled", fmt.Errorf("the site administrator has disabled migrations"))
+		return
+	}
+
+	repoOwner := getMigrateRepoOwner(ctx, ctx.Req.FormValue("repo_owner"), 0)
+	if ctx.Written() {
+		return
+	}
+
+	repoName := ctx.Req.FormValue("repo_name")
+	description := ctx.Req.FormValue("description")
+	if len(repoName) == 0 || len(repoName) > 100 || len(description) > 255 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repo_name is required and must not be longer than 100 characters, description must not be longer than 255 characters.")
+		return
+	}
+
+	file, header, err := ctx.Req.FormFile("dump")
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetFile", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > setting.Migrations.MaxDumpSize*1024*1024 {
+		ctx.Error(http.StatusRequestEntityTooLarge, "", fmt.Sprintf("The dump archive is larger than %d MB.", setting.Migrations.MaxDumpSize))
+		return
+	}
+
+	if err = models.CheckCreateRepository(ctx.User, repoOwner, repoName, false); err != nil {
+		handleMigrateError(ctx, repoOwner, "", err)
+		return
+	}
+
+	archivePath, err := migrations.SaveRepositoryDump(file, header.Filename)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SaveRepositoryDump", err)
+		return
+	}
+
+	private, _ := strconv.ParseBool(ctx.Req.FormValue("private"))
+	if err = task.RestoreRepository(ctx.User, repoOwner, migrations.MigrateOptions{
+		CloneAddr:   archivePath,
+		RepoName:    repoName,
+		Description: description,
+		Private:     private || setting.Repository.ForcePrivate,
+	}); err != nil {
+		if errRemove := util.RemoveAll(filepath.Dir(archivePath)); errRemove != nil {
+			log.Error("Unable to remove the repository dump %s: %v", archivePath, errRemove)
+		}
+		handleMigrateError(ctx, repoOwner, "", err)
+		return
+	}
+
+	repo, err := models.GetRepositoryByName(repoOwner.ID, repoName)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
+		return
+	}
+
+	log.Trace("Repository restore queued: %s/%s", repoOwner.Name, repoName)
+	ctx.JSON(http.StatusAccepted, convert.ToRepo(repo, models.AccessModeAdmin))
+}
+
+// getMigrateRepoOwner returns the owner of a migrated repository, the authenticated user by default,
+// it has to be the authenticated user or one of the organizations they own unless they are an admin
+func getMigrateRepoOwner(ctx *context.APIContext, ownerName string, ownerID int64) *models.User {
+	var (
+		repoOwner *models.User
+		err       error
+	)
+	if len(ownerName) != 0 {
+		repoOwner, err = models.GetUserByName(ownerName)
+	} else if ownerID != 0 {
+		repoOwner, err = models.GetUserByID(ownerID)
+	} else {
+		repoOwner = ctx.User
+	}
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetUser", err)
+		}
+		return nil
+	}
+
+	if !ctx.User.IsAdmin {
+		if !repoOwner.IsOrganization() && ctx.User.ID != repoOwner.ID {
+			ctx.Error(http.StatusForbidden, "", "Given user is not an organization.")
+			return nil
+		}
+
+		if repoOwner.IsOrganization() {
+			// Check ownership of organization.
+			isOwner, err := repoOwner.IsOwnedBy(ctx.User.ID)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "IsOwnedBy", err)
+				return nil
+			} else if !isOwner {
+				ctx.Error(http.StatusForbidden, "", "Given user is not owner of organization.")
+				return nil
+			}
+		}
+	}
+	return repoOwner
+}
+
 func handleMigrateError(ctx *context.APIContext, repoOwner *models.User, remoteAddr string, err error) {
 	switch {
 	case models.IsErrRepoAlreadyExist(err):
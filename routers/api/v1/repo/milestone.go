@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
@@ -220,6 +221,9 @@ func EditMilestone(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "UpdateMilestone", err)
 		return
 	}
+	if milestone.IsClosed != oldIsClosed {
+		notification.NotifyMilestoneChangeStatus(ctx.User, ctx.Repo.Repository, milestone)
+	}
 	ctx.JSON(http.StatusOK, convert.ToAPIMilestone(milestone))
 }
 
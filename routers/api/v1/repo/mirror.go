@@ -5,10 +5,20 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 )
 
@@ -46,3 +56,344 @@ func MirrorSync(ctx *context.APIContext) {
 
 	ctx.Status(http.StatusOK)
 }
+
+// ListPushMirrors get list of push mirrors of a repository
+func ListPushMirrors(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors repository repoListPushMirrors
+	// ---
+	// summary: Get all push mirrors of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirrorList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	pushMirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushMirrorsByRepoID", err)
+		return
+	}
+
+	apiPushMirrors := make([]*api.PushMirror, 0, len(pushMirrors))
+	for _, pm := range pushMirrors {
+		apiPushMirrors = append(apiPushMirrors, convert.ToPushMirror(pm, mirror_service.PushMirrorAddress(pm)))
+	}
+
+	ctx.JSON(http.StatusOK, apiPushMirrors)
+}
+
+// GetPushMirror get a push mirror of a repository by its id
+func GetPushMirror(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors/{id} repository repoGetPushMirror
+	// ---
+	// summary: Get a push mirror of the repository by its id
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirror"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pm := getPushMirrorByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPushMirror(pm, mirror_service.PushMirrorAddress(pm)))
+}
+
+// AddPushMirror adds a push mirror to a repository
+func AddPushMirror(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors repository repoAddPushMirror
+	// ---
+	// summary: Add a push mirror to the repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePushMirrorOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PushMirror"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreatePushMirrorOption)
+
+	interval, err := parsePushMirrorInterval(form.Interval)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "Interval", err)
+		return
+	}
+
+	address, err := forms.ParseRemoteAddr(form.RemoteAddress, form.RemoteUsername, form.RemotePassword)
+	if err == nil {
+		err = migrations.IsMigrateURLAllowed(address, ctx.User)
+	}
+	if err != nil {
+		handleRemoteAddrError(ctx, err)
+		return
+	}
+
+	remoteSuffix, err := generate.GetRandomString(10)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRandomString", err)
+		return
+	}
+
+	pm := &models.PushMirror{
+		RepoID:       ctx.Repo.Repository.ID,
+		Repo:         ctx.Repo.Repository,
+		RemoteName:   fmt.Sprintf("remote_mirror_%s", remoteSuffix),
+		Interval:     interval,
+		SyncOnCommit: form.SyncOnCommit,
+	}
+	if err := models.InsertPushMirror(pm); err != nil {
+		ctx.Error(http.StatusInternalServerError, "InsertPushMirror", err)
+		return
+	}
+
+	if err := mirror_service.AddPushMirrorRemote(pm, address); err != nil {
+		if err := models.DeletePushMirrorByID(pm.ID); err != nil {
+			log.Error("DeletePushMirrorByID %v", err)
+		}
+		ctx.Error(http.StatusInternalServerError, "AddPushMirrorRemote", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToPushMirror(pm, mirror_service.PushMirrorAddress(pm)))
+}
+
+// EditPushMirror edits a push mirror of a repository
+func EditPushMirror(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/push_mirrors/{id} repository repoEditPushMirror
+	// ---
+	// summary: Edit a push mirror of the repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPushMirrorOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirror"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPushMirrorOption)
+
+	pm := getPushMirrorByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Interval != nil {
+		interval, err := parsePushMirrorInterval(*form.Interval)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "Interval", err)
+			return
+		}
+		pm.Interval = interval
+	}
+	if form.SyncOnCommit != nil {
+		pm.SyncOnCommit = *form.SyncOnCommit
+	}
+
+	if err := models.UpdatePushMirror(pm); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdatePushMirror", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPushMirror(pm, mirror_service.PushMirrorAddress(pm)))
+}
+
+// DeletePushMirror removes a push mirror from a repository
+func DeletePushMirror(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/push_mirrors/{id} repository repoDeletePushMirror
+	// ---
+	// summary: Delete a push mirror of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pm := getPushMirrorByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := mirror_service.RemovePushMirrorRemote(pm); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemovePushMirrorRemote", err)
+		return
+	}
+
+	if err := models.DeletePushMirrorByID(pm.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeletePushMirrorByID", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// PushMirrorSync adds all push mirrored repositories to the sync queue
+func PushMirrorSync(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors-sync repository repoPushMirrorSync
+	// ---
+	// summary: Sync all push mirrors of the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to sync
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to sync
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	pushMirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushMirrorsByRepoID", err)
+		return
+	}
+
+	for _, pm := range pushMirrors {
+		mirror_service.AddPushMirrorToQueue(pm.ID)
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func getPushMirrorByParams(ctx *context.APIContext) *models.PushMirror {
+	pm, err := models.GetPushMirrorByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if err == models.ErrPushMirrorNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPushMirrorByID", err)
+		}
+		return nil
+	}
+	if pm.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return pm
+}
+
+func parsePushMirrorInterval(s string) (time.Duration, error) {
+	if len(s) == 0 || s == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if interval != 0 && interval < setting.Mirror.MinInterval {
+		return 0, fmt.Errorf("interval must be at least %s", setting.Mirror.MinInterval)
+	}
+	return interval, nil
+}
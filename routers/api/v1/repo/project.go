@@ -0,0 +1,786 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/notification"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListProjects list the projects of a repository
+func ListProjects(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects issue issueListProjects
+	// ---
+	// summary: List a repository's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+
+	var isClosed util.OptionalBool = util.OptionalBoolFalse
+	switch ctx.Query("state") {
+	case string(api.StateClosed):
+		isClosed = util.OptionalBoolTrue
+	case string(api.StateAll):
+		isClosed = util.OptionalBoolNone
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	projects, count, err := models.GetProjects(models.ProjectSearchOptions{
+		RepoID:   ctx.Repo.Repository.ID,
+		Page:     listOptions.Page,
+		PageSize: listOptions.PageSize,
+		IsClosed: isClosed,
+		Type:     models.ProjectTypeRepository,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjects", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, len(projects))
+	for i := range projects {
+		if err := projects[i].LoadCreator(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadCreator", err)
+			return
+		}
+		apiProjects[i] = convert.ToAPIProject(projects[i])
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, &apiProjects)
+}
+
+// GetProject get a project of a repository
+func GetProject(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects/{id} issue issueGetProject
+	// ---
+	// summary: Get a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIProject(project))
+}
+
+// CreateProject create a project for a repository
+func CreateProject(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects issue issueCreateProject
+	// ---
+	// summary: Create a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.CreateProjectOption)
+
+	boardType, ok := convert.ToProjectBoardType(form.BoardType)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown board type %q", form.BoardType))
+		return
+	}
+
+	project := &models.Project{
+		RepoID:      ctx.Repo.Repository.ID,
+		Title:       form.Title,
+		Description: form.Description,
+		CreatorID:   ctx.User.ID,
+		BoardType:   boardType,
+		Type:        models.ProjectTypeRepository,
+		Creator:     ctx.User,
+	}
+	if err := models.NewProject(project); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProject", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIProject(project))
+}
+
+// EditProject modify a project of a repository
+func EditProject(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/projects/{id} issue issueEditProject
+	// ---
+	// summary: Update a project, it can be closed or reopened by changing the state
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.EditProjectOption)
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != nil {
+		if *form.Title == "" {
+			ctx.Error(http.StatusUnprocessableEntity, "", "title must not be empty")
+			return
+		}
+		project.Title = *form.Title
+	}
+	if form.Description != nil {
+		project.Description = *form.Description
+	}
+	if err := models.UpdateProject(project); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProject", err)
+		return
+	}
+
+	if form.State != nil {
+		isClosed := *form.State == string(api.StateClosed)
+		if isClosed != project.IsClosed {
+			if err := models.ChangeProjectStatus(project, isClosed); err != nil {
+				ctx.Error(http.StatusInternalServerError, "ChangeProjectStatus", err)
+				return
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIProject(project))
+}
+
+// DeleteProject delete a project of a repository
+func DeleteProject(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/projects/{id} issue issueDeleteProject
+	// ---
+	// summary: Delete a project
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectByID(project.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListProjectBoards list the boards of a project
+func ListProjectBoards(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects/{id}/boards issue issueListProjectBoards
+	// ---
+	// summary: List the boards of a project
+	// description: The first board is the default board. If no board is marked as default, a board with id 0 is returned for the issues which are not assigned to a board.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(project.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectBoards", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPIProjectBoards(boards))
+}
+
+// CreateProjectBoard add a board to a project
+func CreateProjectBoard(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects/{id}/boards issue issueCreateProjectBoard
+	// ---
+	// summary: Add a board to a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	form := web.GetForm(ctx).(*api.CreateProjectBoardOption)
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	board := &models.ProjectBoard{
+		ProjectID: project.ID,
+		Title:     form.Title,
+		CreatorID: ctx.User.ID,
+	}
+	if err := models.NewProjectBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProjectBoard", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIProjectBoard(board))
+}
+
+// EditProjectBoard modify a board of a project
+func EditProjectBoard(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/projects/{id}/boards/{board} issue issueEditProjectBoard
+	// ---
+	// summary: Update a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectBoardOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.EditProjectBoardOption)
+	project, board := getRepoProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+	if board.ID == 0 {
+		ctx.NotFound()
+		return
+	}
+
+	if form.Title != nil {
+		if *form.Title == "" {
+			ctx.Error(http.StatusUnprocessableEntity, "", "title must not be empty")
+			return
+		}
+		board.Title = *form.Title
+		if err := models.UpdateProjectBoard(board); err != nil {
+			ctx.Error(http.StatusInternalServerError, "UpdateProjectBoard", err)
+			return
+		}
+	}
+
+	if form.Default != nil && *form.Default != board.Default {
+		defaultID := board.ID
+		if !*form.Default {
+			defaultID = 0
+		}
+		if err := models.SetDefaultBoard(project.ID, defaultID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "SetDefaultBoard", err)
+			return
+		}
+		board.Default = *form.Default
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIProjectBoard(board))
+}
+
+// DeleteProjectBoard delete a board of a project
+func DeleteProjectBoard(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/projects/{id}/boards/{board} issue issueDeleteProjectBoard
+	// ---
+	// summary: Delete a board of a project, its issues are moved to the default board
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, board := getRepoProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+	if board.ID == 0 {
+		ctx.NotFound()
+		return
+	}
+
+	if err := models.DeleteProjectBoardByID(board.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectBoardByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// SortProjectBoards change the order of the boards of a project
+func SortProjectBoards(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects/{id}/boards/sort issue issueSortProjectBoards
+	// ---
+	// summary: Change the order of the boards of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SortProjectBoardsOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.SortProjectBoardsOption)
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(project.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectBoards", err)
+		return
+	}
+
+	boardsByID := make(map[int64]*models.ProjectBoard, len(boards))
+	for _, board := range boards {
+		if board.ID != 0 {
+			boardsByID[board.ID] = board
+		}
+	}
+	if len(form.BoardIDs) != len(boardsByID) || len(form.BoardIDs) > math.MaxInt8 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "board_ids must contain every board of the project exactly once")
+		return
+	}
+
+	sorted := make(models.ProjectBoardList, 0, len(form.BoardIDs))
+	for i, id := range form.BoardIDs {
+		board, ok := boardsByID[id]
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", "board_ids must contain every board of the project exactly once")
+			return
+		}
+		delete(boardsByID, id)
+		board.Sorting = int8(i)
+		sorted = append(sorted, board)
+	}
+
+	if err := models.UpdateProjectBoardSorting(sorted); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProjectBoardSorting", err)
+		return
+	}
+
+	boards, err = models.GetProjectBoards(project.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjectBoards", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPIProjectBoards(boards))
+}
+
+// ListProjectBoardIssues list the issues on a board of a project
+func ListProjectBoardIssues(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects/{id}/boards/{board}/issues issue issueListProjectBoardIssues
+	// ---
+	// summary: List the issues and pull requests on a board of a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board, 0 for the issues which are not assigned to a board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, board := getRepoProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	issues, err := board.LoadIssues()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssues", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+}
+
+// MoveProjectIssue move an issue to a board of a project
+func MoveProjectIssue(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects/{id}/boards/{board}/issues issue issueMoveProjectIssue
+	// ---
+	// summary: Move an issue or pull request to a board of a project
+	// description: The issue is added to the project if it belongs to another or no project.
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board
+	//   in: path
+	//   description: id of the board, 0 to remove the issue from its board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveProjectIssueOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.MoveProjectIssueOption)
+	project, board := getRepoProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, form.Issue)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	if issue.ProjectID() != project.ID {
+		if err := models.ChangeProjectAssign(issue, ctx.User, project.ID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeProjectAssign", err)
+			return
+		}
+	}
+
+	oldBoardID := issue.ProjectBoardID()
+	if err := models.MoveIssueAcrossProjectBoards(issue, board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "MoveIssueAcrossProjectBoards", err)
+		return
+	}
+	if oldBoardID != board.ID {
+		notification.NotifyMoveProjectIssue(ctx.User, issue, project, oldBoardID, board)
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getRepoProject returns the project given by the id in the path if it belongs to the repository
+func getRepoProject(ctx *context.APIContext) *models.Project {
+	project, err := models.GetProjectByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectByRepoID", err)
+		}
+		return nil
+	}
+	if err := project.LoadCreator(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadCreator", err)
+		return nil
+	}
+	return project
+}
+
+// getRepoProjectBoard returns the project and the board given in the path.
+// Board 0 is the placeholder board for the issues which are not assigned to a board.
+func getRepoProjectBoard(ctx *context.APIContext) (*models.Project, *models.ProjectBoard) {
+	project := getRepoProject(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+
+	boardID := ctx.ParamsInt64(":board")
+	if boardID == 0 {
+		return project, &models.ProjectBoard{ProjectID: project.ID, Default: true}
+	}
+
+	board, err := models.GetProjectBoard(boardID)
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectBoard", err)
+		}
+		return nil, nil
+	}
+	if board.ProjectID != project.ID {
+		ctx.NotFound()
+		return nil, nil
+	}
+	return project, board
+}
+
+func toAPIProjectBoards(boards models.ProjectBoardList) []*api.ProjectBoard {
+	apiBoards := make([]*api.ProjectBoard, len(boards))
+	for i := range boards {
+		apiBoards[i] = convert.ToAPIProjectBoard(boards[i])
+	}
+	return apiBoards
+}
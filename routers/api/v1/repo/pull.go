@@ -639,6 +639,113 @@ func EditPullRequest(ctx *context.APIContext) {
 	ctx.JSON(http.StatusCreated, convert.ToAPIPullRequest(pr))
 }
 
+// CancelScheduledAutoMerge cancels the scheduled auto merge of a pull request
+func CancelScheduledAutoMerge(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge repository repoCancelScheduledAutoMerge
+	// ---
+	// summary: Cancel the scheduled auto merge for the given pull request or remove it from the merge queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request to merge
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil && !models.IsErrMergeQueueEntryNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "GetMergeQueueEntryByPullID", err)
+		return
+	}
+	if entry != nil {
+		if entry.DoerID != ctx.User.ID {
+			allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
+				return
+			}
+			if !allowedMerge {
+				ctx.Error(http.StatusForbidden, "CancelScheduledAutoMerge", "user is not allowed to remove the pull request from the merge queue")
+				return
+			}
+		}
+
+		if err := pull_service.RemoveFromMergeQueue(ctx.User, pr); err != nil {
+			if models.IsErrMergeQueueEntryNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "RemoveFromMergeQueue", err)
+			}
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	autoMerge, err := models.GetPullAutoMergeByPullID(pr.ID)
+	if err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullAutoMergeByPullID", err)
+		}
+		return
+	}
+
+	if autoMerge.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden, "CancelScheduledAutoMerge", "user is not allowed to cancel the scheduled auto merge")
+			return
+		}
+	}
+
+	if err := pull_service.CancelAutoMerge(ctx.User, pr); err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CancelAutoMerge", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 // IsPullRequestMerged checks if a PR exists given an index
 func IsPullRequestMerged(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/merge repository repoPullRequestIsMerged
@@ -790,6 +897,40 @@ func MergePullRequest(ctx *context.APIContext) {
 		return
 	}
 
+	if len(form.Do) == 0 {
+		form.Do = string(models.MergeStyleMerge)
+	}
+
+	message := strings.TrimSpace(form.MergeTitleField)
+	if len(message) == 0 {
+		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
+			message = pr.GetDefaultMergeMessage()
+		}
+		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		}
+	}
+
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	if form.MergeWhenChecksSucceed {
+		if err := pull_service.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", models.MergeStyle(form.Do)))
+			} else if models.IsErrPullAutoMergeAlreadyScheduled(err) {
+				ctx.Error(http.StatusConflict, "ScheduleAutoMerge", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ScheduleAutoMerge", err)
+			}
+			return
+		}
+		ctx.Status(http.StatusOK)
+		return
+	}
+
 	if !pr.CanAutoMerge() {
 		ctx.Error(http.StatusMethodNotAllowed, "PR not in mergeable state", "Please try again later")
 		return
@@ -827,23 +968,23 @@ func MergePullRequest(ctx *context.APIContext) {
 		return
 	}
 
-	if len(form.Do) == 0 {
-		form.Do = string(models.MergeStyleMerge)
-	}
-
-	message := strings.TrimSpace(form.MergeTitleField)
-	if len(message) == 0 {
-		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
-			message = pr.GetDefaultMergeMessage()
-		}
-		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
-			message = pr.GetDefaultSquashMessage()
+	if useMergeQueue, err := pull_service.IsMergeQueueEnabled(pr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsMergeQueueEnabled", err)
+		return
+	} else if useMergeQueue {
+		if err := pull_service.AddToMergeQueue(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", models.MergeStyle(form.Do)))
+			} else if models.IsErrPullAlreadyInMergeQueue(err) {
+				ctx.Error(http.StatusConflict, "AddToMergeQueue", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+			}
+			return
 		}
-	}
-
-	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
-	if len(form.MergeMessageField) > 0 {
-		message += "\n\n" + form.MergeMessageField
+		log.Trace("Pull request added to the merge queue: %d", pr.ID)
+		ctx.Status(http.StatusOK)
+		return
 	}
 
 	if err := pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
@@ -859,6 +1000,9 @@ func MergePullRequest(ctx *context.APIContext) {
 		} else if models.IsErrMergeUnrelatedHistories(err) {
 			conflictError := err.(models.ErrMergeUnrelatedHistories)
 			ctx.JSON(http.StatusConflict, conflictError)
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			ctx.Error(http.StatusConflict, "Merge", "the base branch has diverged from the head branch, fast-forward is not possible")
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			ctx.Error(http.StatusConflict, "Merge", "merge push out of date")
 			return
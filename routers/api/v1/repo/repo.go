@@ -145,6 +145,7 @@ func Search(ctx *context.APIContext) {
 		Template:           util.OptionalBoolNone,
 		StarredByID:        ctx.QueryInt64("starredBy"),
 		IncludeDescription: ctx.QueryBool("includeDesc"),
+		RepoIDs:            ctx.AccessTokenRepoIDs(),
 	}
 
 	if ctx.Query("template") != "" {
@@ -454,6 +455,10 @@ func GetByID(ctx *context.APIContext) {
 		ctx.NotFound()
 		return
 	}
+	if token := ctx.AccessToken(); token != nil && !token.CanAccessRepo(repo.ID) {
+		ctx.NotFound()
+		return
+	}
 	ctx.JSON(http.StatusOK, convert.ToRepo(repo, perm.AccessMode))
 }
 
@@ -752,6 +757,9 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			if opts.AllowSquash != nil {
 				config.AllowSquash = *opts.AllowSquash
 			}
+			if opts.AllowFastForwardOnly != nil {
+				config.AllowFastForwardOnly = *opts.AllowFastForwardOnly
+			}
 			if opts.AllowManualMerge != nil {
 				config.AllowManualMerge = *opts.AllowManualMerge
 			}
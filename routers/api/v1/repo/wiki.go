@@ -0,0 +1,587 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/notification"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	wiki_service "code.gitea.io/gitea/services/wiki"
+)
+
+// ListWikiPages lists the pages of a repository's wiki
+func ListWikiPages(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/pages repository repoListWikiPages
+	// ---
+	// summary: List the pages of a repository's wiki
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiPageList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	wikiRepo, commit := findWikiRepoCommit(ctx)
+	if wikiRepo != nil {
+		defer wikiRepo.Close()
+	}
+	if ctx.Written() {
+		return
+	}
+	if commit == nil {
+		ctx.JSON(http.StatusOK, []*api.WikiPageMetaData{})
+		return
+	}
+
+	entries, err := commit.ListEntries()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListEntries", err)
+		return
+	}
+
+	type wikiEntry struct {
+		name     string
+		filename string
+	}
+	pageEntries := make([]wikiEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsRegular() {
+			continue
+		}
+		wikiName, err := wiki_service.FilenameToName(entry.Name())
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			ctx.Error(http.StatusInternalServerError, "WikiFilenameToName", err)
+			return
+		}
+		pageEntries = append(pageEntries, wikiEntry{name: wikiName, filename: entry.Name()})
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	skip, end := (listOptions.Page-1)*listOptions.PageSize, listOptions.Page*listOptions.PageSize
+	if skip > len(pageEntries) {
+		skip = len(pageEntries)
+	}
+	if end > len(pageEntries) {
+		end = len(pageEntries)
+	}
+
+	pages := make([]*api.WikiPageMetaData, 0, end-skip)
+	for _, entry := range pageEntries[skip:end] {
+		meta, err := toWikiPageMetaData(ctx, wikiRepo, entry.name, entry.filename)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetCommitByPath", err)
+			return
+		}
+		pages = append(pages, meta)
+	}
+
+	ctx.SetLinkHeader(len(pageEntries), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", len(pageEntries)))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, pages)
+}
+
+// GetWikiPage gets a page of a repository's wiki
+func GetWikiPage(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/pages/{pageName} repository repoGetWikiPage
+	// ---
+	// summary: Get a wiki page
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiPage"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	wikiRepo, commit := findWikiRepoCommit(ctx)
+	if wikiRepo != nil {
+		defer wikiRepo.Close()
+	}
+	if ctx.Written() {
+		return
+	}
+
+	page := getWikiPage(ctx, wikiRepo, commit, wiki_service.NormalizeWikiName(ctx.Params(":pageName")))
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// CreateWikiPage creates a page in a repository's wiki
+func CreateWikiPage(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/wiki/pages repository repoCreateWikiPage
+	// ---
+	// summary: Create a wiki page
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateWikiPageOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/WikiPage"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateWikiPageOptions)
+	if util.IsEmptyString(form.Title) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "title is empty")
+		return
+	}
+	content, err := base64.StdEncoding.DecodeString(form.ContentBase64)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "DecodeContent", err)
+		return
+	}
+
+	wikiName := wiki_service.NormalizeWikiName(form.Title)
+	if len(form.Message) == 0 {
+		form.Message = fmt.Sprintf("Add '%s'", form.Title)
+	}
+
+	if err := wiki_service.AddWikiPage(ctx.User, ctx.Repo.Repository, wikiName, string(content), form.Message); err != nil {
+		if models.IsErrWikiReservedName(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else if models.IsErrWikiAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AddWikiPage", err)
+		}
+		return
+	}
+	notification.NotifyNewWikiPage(ctx.User, ctx.Repo.Repository, wikiName, form.Message)
+
+	wikiRepo, commit := findWikiRepoCommit(ctx)
+	if wikiRepo != nil {
+		defer wikiRepo.Close()
+	}
+	if ctx.Written() {
+		return
+	}
+
+	page := getWikiPage(ctx, wikiRepo, commit, wikiName)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusCreated, page)
+}
+
+// EditWikiPage edits a page of a repository's wiki
+func EditWikiPage(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/wiki/pages/{pageName} repository repoEditWikiPage
+	// ---
+	// summary: Edit a wiki page
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateWikiPageOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiPage"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateWikiPageOptions)
+
+	wikiRepo, commit := findWikiRepoCommit(ctx)
+	if wikiRepo != nil {
+		defer wikiRepo.Close()
+	}
+	if ctx.Written() {
+		return
+	}
+
+	oldWikiName := wiki_service.NormalizeWikiName(ctx.Params(":pageName"))
+	content, entry, _ := wikiContentsByName(ctx, commit, oldWikiName)
+	if ctx.Written() {
+		return
+	}
+	if entry == nil {
+		ctx.NotFound()
+		return
+	}
+
+	newWikiName := oldWikiName
+	if !util.IsEmptyString(form.Title) {
+		newWikiName = wiki_service.NormalizeWikiName(form.Title)
+	}
+	if newWikiName != oldWikiName {
+		_, existing, _ := wikiContentsByName(ctx, commit, newWikiName)
+		if ctx.Written() {
+			return
+		}
+		if existing != nil {
+			ctx.Error(http.StatusConflict, "", models.ErrWikiAlreadyExist{Title: newWikiName})
+			return
+		}
+	}
+
+	if len(form.ContentBase64) > 0 {
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(form.ContentBase64); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "DecodeContent", err)
+			return
+		}
+	}
+	if len(form.Message) == 0 {
+		form.Message = fmt.Sprintf("Update '%s'", newWikiName)
+	}
+
+	if err := wiki_service.EditWikiPage(ctx.User, ctx.Repo.Repository, oldWikiName, newWikiName, string(content), form.Message); err != nil {
+		if models.IsErrWikiReservedName(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "EditWikiPage", err)
+		}
+		return
+	}
+	notification.NotifyEditWikiPage(ctx.User, ctx.Repo.Repository, newWikiName, form.Message)
+
+	// the wiki has a new commit now
+	wikiRepo.Close()
+	wikiRepo, commit = findWikiRepoCommit(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	page := getWikiPage(ctx, wikiRepo, commit, newWikiName)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// DeleteWikiPage deletes a page of a repository's wiki
+func DeleteWikiPage(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/wiki/pages/{pageName} repository repoDeleteWikiPage
+	// ---
+	// summary: Delete a wiki page
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	wikiRepo, commit := findWikiRepoCommit(ctx)
+	if wikiRepo != nil {
+		defer wikiRepo.Close()
+	}
+	if ctx.Written() {
+		return
+	}
+
+	wikiName := wiki_service.NormalizeWikiName(ctx.Params(":pageName"))
+	_, entry, _ := wikiContentsByName(ctx, commit, wikiName)
+	if ctx.Written() {
+		return
+	}
+	if entry == nil {
+		ctx.NotFound()
+		return
+	}
+
+	if err := wiki_service.DeleteWikiPage(ctx.User, ctx.Repo.Repository, wikiName); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteWikiPage", err)
+		return
+	}
+	notification.NotifyDeleteWikiPage(ctx.User, ctx.Repo.Repository, wikiName)
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListWikiPageRevisions lists the revisions of a wiki page
+func ListWikiPageRevisions(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/pages/{pageName}/revisions repository repoListWikiPageRevisions
+	// ---
+	// summary: List the revisions of a wiki page
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based), a page has 50 revisions
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiCommitList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	wikiRepo, commit := findWikiRepoCommit(ctx)
+	if wikiRepo != nil {
+		defer wikiRepo.Close()
+	}
+	if ctx.Written() {
+		return
+	}
+
+	wikiName := wiki_service.NormalizeWikiName(ctx.Params(":pageName"))
+	_, entry, filename := wikiContentsByName(ctx, commit, wikiName)
+	if ctx.Written() {
+		return
+	}
+	if entry == nil {
+		ctx.NotFound()
+		return
+	}
+
+	count, err := wikiRepo.FileCommitsCount("master", filename)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FileCommitsCount", err)
+		return
+	}
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	commits, err := wikiRepo.CommitsByFileAndRangeNoFollow("master", filename, page)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CommitsByFileAndRangeNoFollow", err)
+		return
+	}
+
+	ctx.SetLinkHeader(int(count), git.CommitsRangeSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, convert.ToWikiCommitList(commits))
+}
+
+// findWikiRepoCommit opens the wiki repository and returns its latest commit,
+// the commit is nil if the wiki has no pages yet. Writes to ctx if an error occurs.
+func findWikiRepoCommit(ctx *context.APIContext) (*git.Repository, *git.Commit) {
+	if !ctx.Repo.Repository.HasWiki() {
+		return nil, nil
+	}
+
+	wikiRepo, err := git.OpenRepository(ctx.Repo.Repository.WikiPath())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
+		return nil, nil
+	}
+
+	commit, err := wikiRepo.GetBranchCommit("master")
+	if err != nil {
+		if !git.IsErrNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "GetBranchCommit", err)
+		}
+		return wikiRepo, nil
+	}
+	return wikiRepo, commit
+}
+
+// wikiContentsByName returns the contents and the tree entry of a wiki page, the entry is nil
+// if the page doesn't exist. Writes to ctx if an error occurs.
+func wikiContentsByName(ctx *context.APIContext, commit *git.Commit, wikiName string) ([]byte, *git.TreeEntry, string) {
+	if commit == nil {
+		return nil, nil, ""
+	}
+
+	filename := wiki_service.NameToFilename(wikiName)
+	entry, err := commit.GetTreeEntryByPath(filename)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil, ""
+		}
+		ctx.Error(http.StatusInternalServerError, "GetTreeEntryByPath", err)
+		return nil, nil, ""
+	}
+
+	reader, err := entry.Blob().DataAsync()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Blob.Data", err)
+		return nil, nil, ""
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReadAll", err)
+		return nil, nil, ""
+	}
+	return content, entry, filename
+}
+
+func toWikiPageMetaData(ctx *context.APIContext, wikiRepo *git.Repository, wikiName, filename string) (*api.WikiPageMetaData, error) {
+	lastCommit, err := wikiRepo.GetCommitByPath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	subURL := wiki_service.NameToSubURL(wikiName)
+	return &api.WikiPageMetaData{
+		Title:      wikiName,
+		HTMLURL:    util.URLJoin(ctx.Repo.Repository.HTMLURL(), "wiki", url.PathEscape(subURL)),
+		SubURL:     subURL,
+		LastCommit: convert.ToWikiCommit(lastCommit),
+	}, nil
+}
+
+// getWikiPage returns a wiki page with its raw and rendered content. Writes to ctx if an error occurs.
+func getWikiPage(ctx *context.APIContext, wikiRepo *git.Repository, commit *git.Commit, wikiName string) *api.WikiPage {
+	content, entry, filename := wikiContentsByName(ctx, commit, wikiName)
+	if ctx.Written() {
+		return nil
+	}
+	if entry == nil {
+		ctx.NotFound()
+		return nil
+	}
+
+	meta, err := toWikiPageMetaData(ctx, wikiRepo, wikiName, filename)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommitByPath", err)
+		return nil
+	}
+
+	commitsCount, err := wikiRepo.FileCommitsCount("master", filename)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FileCommitsCount", err)
+		return nil
+	}
+
+	return &api.WikiPage{
+		Title:         meta.Title,
+		HTMLURL:       meta.HTMLURL,
+		SubURL:        meta.SubURL,
+		LastCommit:    meta.LastCommit,
+		ContentBase64: base64.StdEncoding.EncodeToString(content),
+		ContentHTML:   markdown.RenderWiki(content, ctx.Repo.Repository.Link(), ctx.Repo.Repository.ComposeDocumentMetas()),
+		CommitCount:   commitsCount,
+	}
+}
@@ -28,6 +28,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditHookOption api.EditHookOption
 
+	// in:body
+	RedeliverHookOption api.RedeliverHookOption
+
 	// in:body
 	EditGitHookOption api.EditGitHookOption
 
@@ -62,6 +65,19 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditMilestoneOption api.EditMilestoneOption
 
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+	// in:body
+	EditProjectOption api.EditProjectOption
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+	// in:body
+	EditProjectBoardOption api.EditProjectBoardOption
+	// in:body
+	SortProjectBoardsOption api.SortProjectBoardsOption
+	// in:body
+	MoveProjectIssueOption api.MoveProjectIssueOption
+
 	// in:body
 	CreateOrgOption api.CreateOrgOption
 	// in:body
@@ -158,4 +174,12 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	PullReviewRequestOptions api.PullReviewRequestOptions
+
+	// in:body
+	CreatePushMirrorOption api.CreatePushMirrorOption
+	// in:body
+	EditPushMirrorOption api.EditPushMirrorOption
+
+	// in:body
+	CreateWikiPageOptions api.CreateWikiPageOptions
 }
@@ -10,11 +10,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
@@ -52,11 +54,12 @@ func ListAccessTokens(ctx *context.APIContext) {
 
 	apiTokens := make([]*api.AccessToken, len(tokens))
 	for i := range tokens {
-		apiTokens[i] = &api.AccessToken{
-			ID:             tokens[i].ID,
-			Name:           tokens[i].Name,
-			TokenLastEight: tokens[i].TokenLastEight,
+		repos, err := tokens[i].GetRepositories()
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetRepositories", err)
+			return
 		}
+		apiTokens[i] = convert.ToAccessToken(tokens[i], repos)
 	}
 	ctx.JSON(http.StatusOK, &apiTokens)
 }
@@ -85,17 +88,56 @@ func CreateAccessToken(ctx *context.APIContext) {
 	//     properties:
 	//       name:
 	//         type: string
+	//       scopes:
+	//         type: array
+	//         items:
+	//           type: string
+	//           enum: [all, repo:read, repo:write, issue, admin:org, user, package, notification]
+	//       repositories:
+	//         type: array
+	//         items:
+	//           type: string
+	//       expires_at:
+	//         type: string
+	//         format: date-time
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/AccessToken"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.CreateAccessTokenOption)
 
+	scope, err := models.ParseAccessTokenScope(form.Scopes)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseAccessTokenScope", err)
+		return
+	}
+
+	repoIDs, err := models.GetAccessTokenRepoIDsByNames(ctx.User, form.Repositories)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetAccessTokenRepoIDsByNames", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAccessTokenRepoIDsByNames", err)
+		}
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:     ctx.User.ID,
+		Name:    form.Name,
+		Scope:   scope,
+		RepoIDs: repoIDs,
+	}
+	if form.ExpiresAt != nil {
+		if !form.ExpiresAt.After(time.Now()) {
+			ctx.Error(http.StatusUnprocessableEntity, "ExpiresAt", errors.New("expiry date must be in the future"))
+			return
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(form.ExpiresAt.Unix())
 	}
 
 	exist, err := models.AccessTokenByNameExists(t)
@@ -112,12 +154,13 @@ func CreateAccessToken(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
 		return
 	}
-	ctx.JSON(http.StatusCreated, &api.AccessToken{
-		Name:           t.Name,
-		Token:          t.Token,
-		ID:             t.ID,
-		TokenLastEight: t.TokenLastEight,
-	})
+
+	repos, err := t.GetRepositories()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositories", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAccessToken(t, repos))
 }
 
 // DeleteAccessToken delete access tokens
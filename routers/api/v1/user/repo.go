@@ -24,6 +24,7 @@ func listUserRepos(ctx *context.APIContext, u *models.User, private bool) {
 		Private:     private,
 		ListOptions: opts,
 		OrderBy:     "id ASC",
+		RepoIDs:     ctx.AccessTokenRepoIDs(),
 	})
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetUserRepositories", err)
@@ -107,6 +108,7 @@ func ListMyRepos(ctx *context.APIContext) {
 		OwnerID:            ctx.User.ID,
 		Private:            ctx.IsSigned,
 		IncludeDescription: true,
+		RepoIDs:            ctx.AccessTokenRepoIDs(),
 	}
 
 	var err error
@@ -17,8 +17,8 @@ import (
 
 // getStarredRepos returns the repos that the user with the specified userID has
 // starred
-func getStarredRepos(user *models.User, private bool, listOptions models.ListOptions) ([]*api.Repository, error) {
-	starredRepos, err := models.GetStarredRepos(user.ID, private, listOptions)
+func getStarredRepos(user *models.User, private bool, repoIDs []int64, listOptions models.ListOptions) ([]*api.Repository, error) {
+	starredRepos, err := models.GetStarredRepos(user.ID, private, repoIDs, listOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +61,7 @@ func GetStarredRepos(ctx *context.APIContext) {
 
 	user := GetUserByParams(ctx)
 	private := user.ID == ctx.User.ID
-	repos, err := getStarredRepos(user, private, utils.GetListOptions(ctx))
+	repos, err := getStarredRepos(user, private, ctx.AccessTokenRepoIDs(), utils.GetListOptions(ctx))
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "getStarredRepos", err)
 	}
@@ -88,7 +88,7 @@ func GetMyStarredRepos(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/RepositoryList"
 
-	repos, err := getStarredRepos(ctx.User, true, utils.GetListOptions(ctx))
+	repos, err := getStarredRepos(ctx.User, true, ctx.AccessTokenRepoIDs(), utils.GetListOptions(ctx))
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "getStarredRepos", err)
 	}
@@ -16,8 +16,8 @@ import (
 
 // getWatchedRepos returns the repos that the user with the specified userID is
 // watching
-func getWatchedRepos(user *models.User, private bool, listOptions models.ListOptions) ([]*api.Repository, error) {
-	watchedRepos, err := models.GetWatchedRepos(user.ID, private, listOptions)
+func getWatchedRepos(user *models.User, private bool, repoIDs []int64, listOptions models.ListOptions) ([]*api.Repository, error) {
+	watchedRepos, err := models.GetWatchedRepos(user.ID, private, repoIDs, listOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +60,7 @@ func GetWatchedRepos(ctx *context.APIContext) {
 
 	user := GetUserByParams(ctx)
 	private := user.ID == ctx.User.ID
-	repos, err := getWatchedRepos(user, private, utils.GetListOptions(ctx))
+	repos, err := getWatchedRepos(user, private, ctx.AccessTokenRepoIDs(), utils.GetListOptions(ctx))
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "getWatchedRepos", err)
 	}
@@ -87,7 +87,7 @@ func GetMyWatchedRepos(ctx *context.APIContext) {
 	//   "200":
 	//     "$ref": "#/responses/RepositoryList"
 
-	repos, err := getWatchedRepos(ctx.User, true, utils.GetListOptions(ctx))
+	repos, err := getWatchedRepos(ctx.User, true, ctx.AccessTokenRepoIDs(), utils.GetListOptions(ctx))
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "getWatchedRepos", err)
 	}
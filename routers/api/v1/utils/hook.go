@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
 	"code.gitea.io/gitea/services/webhook"
 	jsoniter "github.com/json-iterator/go"
@@ -62,7 +63,12 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 			return false
 		}
 	}
-	if !models.IsValidHookContentType(form.Config["content_type"]) {
+	if algorithm, ok := form.Config["signature_algorithm"]; ok && !models.IsValidHookSignatureAlgorithm(algorithm) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid signature algorithm")
+		return false
+	}
+	// the content type of a custom hook is the media type of its body, which is checked with its template
+	if form.Type != models.CUSTOM && !models.IsValidHookContentType(form.Config["content_type"]) {
 		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid content type")
 		return false
 	}
@@ -87,6 +93,59 @@ func AddRepoHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	}
 }
 
+// customHookMeta applies the custom hook options of `config` to `custom` and returns it as webhook
+// metadata. If they are invalid, write to `ctx` accordingly. Return (meta, ok)
+func customHookMeta(ctx *context.APIContext, config map[string]string, custom *webhook.CustomMeta) (string, bool) {
+	if template, ok := config["template"]; ok {
+		custom.Template = template
+	}
+	if contentType, ok := config["content_type"]; ok {
+		custom.ContentType = strings.TrimSpace(contentType)
+	}
+	if headers, ok := config["headers"]; ok {
+		custom.Headers = headers
+	}
+	if err := custom.Validate(); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return "", false
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	meta, err := json.Marshal(custom)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "custom: JSON marshal failed", err)
+		return "", false
+	}
+	return string(meta), true
+}
+
+// customHookMethod returns the http method of a custom hook given in `config`, POST by default. If it
+// is invalid, write to `ctx` accordingly. Return (method, ok)
+func customHookMethod(ctx *context.APIContext, config map[string]string, method string) (string, bool) {
+	if m, ok := config["http_method"]; ok {
+		method = strings.ToUpper(m)
+	}
+	if method != http.MethodPost && method != http.MethodPut {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid http method")
+		return "", false
+	}
+	return method, true
+}
+
+// setHookClientTLS sets the client certificate, key and CA certificates of a hook, keeping the
+// current key if none is given. If they are invalid, write to `ctx` accordingly. Return whether successful
+func setHookClientTLS(ctx *context.APIContext, w *models.Webhook, cert, key, ca string) bool {
+	if err := webhook.SetClientTLS(w, cert, key, ca); err != nil {
+		if webhook.IsErrInvalidClientCertificate(err) || webhook.IsErrInvalidCACertificates(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetClientTLS", err)
+		}
+		return false
+	}
+	return true
+}
+
 func issuesHook(events []string, event string) bool {
 	return util.IsStringInSlice(event, events, true) || util.IsStringInSlice(string(models.HookEventIssues), events, true)
 }
@@ -102,12 +161,13 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		form.Events = []string{"push"}
 	}
 	w := &models.Webhook{
-		OrgID:       orgID,
-		RepoID:      repoID,
-		URL:         form.Config["url"],
-		ContentType: models.ToHookContentType(form.Config["content_type"]),
-		Secret:      form.Config["secret"],
-		HTTPMethod:  "POST",
+		OrgID:              orgID,
+		RepoID:             repoID,
+		URL:                form.Config["url"],
+		ContentType:        models.ToHookContentType(form.Config["content_type"]),
+		Secret:             form.Config["secret"],
+		SignatureAlgorithm: form.Config["signature_algorithm"],
+		HTTPMethod:         "POST",
 		HookEvent: &models.HookEvent{
 			ChooseEvents: true,
 			HookEvents: models.HookEvents{
@@ -129,11 +189,18 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequestSync:      pullHook(form.Events, string(models.HookEventPullRequestSync)),
 				Repository:           util.IsStringInSlice(string(models.HookEventRepository), form.Events, true),
 				Release:              util.IsStringInSlice(string(models.HookEventRelease), form.Events, true),
+				Wiki:                 util.IsStringInSlice(string(models.HookEventWiki), form.Events, true),
+				Status:               util.IsStringInSlice(string(models.HookEventStatus), form.Events, true),
+				Milestone:            util.IsStringInSlice(string(models.HookEventMilestone), form.Events, true),
+				ProjectCard:          util.IsStringInSlice(string(models.HookEventProjectCard), form.Events, true),
+				BranchProtection:     util.IsStringInSlice(string(models.HookEventBranchProtection), form.Events, true),
 			},
 			BranchFilter: form.BranchFilter,
 		},
-		IsActive: form.Active,
-		Type:     models.HookTaskType(form.Type),
+		IsActive:            form.Active,
+		Type:                models.HookTaskType(form.Type),
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
 	}
 	if w.Type == models.SLACK {
 		channel, ok := form.Config["channel"]
@@ -159,8 +226,24 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 			return nil, false
 		}
 		w.Meta = string(meta)
+	} else if w.Type == models.CUSTOM {
+		var ok bool
+		if w.HTTPMethod, ok = customHookMethod(ctx, form.Config, http.MethodPost); !ok {
+			return nil, false
+		}
+		if w.Meta, ok = customHookMeta(ctx, form.Config, &webhook.CustomMeta{}); !ok {
+			return nil, false
+		}
+		w.ContentType = models.ContentTypeJSON
 	}
 
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetHeaderAuthorization", err)
+		return nil, false
+	}
+	if !setHookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates) {
+		return nil, false
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateEvent", err)
 		return nil, false
@@ -212,13 +295,20 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 		if url, ok := form.Config["url"]; ok {
 			w.URL = url
 		}
-		if ct, ok := form.Config["content_type"]; ok {
+		if ct, ok := form.Config["content_type"]; ok && w.Type != models.CUSTOM {
 			if !models.IsValidHookContentType(ct) {
 				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid content type")
 				return false
 			}
 			w.ContentType = models.ToHookContentType(ct)
 		}
+		if algorithm, ok := form.Config["signature_algorithm"]; ok {
+			if !models.IsValidHookSignatureAlgorithm(algorithm) {
+				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid signature algorithm")
+				return false
+			}
+			w.SignatureAlgorithm = algorithm
+		}
 
 		if w.Type == models.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -235,6 +325,14 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 				}
 				w.Meta = string(meta)
 			}
+		} else if w.Type == models.CUSTOM {
+			var ok bool
+			if w.HTTPMethod, ok = customHookMethod(ctx, form.Config, w.HTTPMethod); !ok {
+				return false
+			}
+			if w.Meta, ok = customHookMeta(ctx, form.Config, webhook.GetCustomHook(w)); !ok {
+				return false
+			}
 		}
 	}
 
@@ -257,6 +355,11 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.PullRequest = util.IsStringInSlice(string(models.HookEventPullRequest), form.Events, true)
 	w.Repository = util.IsStringInSlice(string(models.HookEventRepository), form.Events, true)
 	w.Release = util.IsStringInSlice(string(models.HookEventRelease), form.Events, true)
+	w.Wiki = util.IsStringInSlice(string(models.HookEventWiki), form.Events, true)
+	w.Status = util.IsStringInSlice(string(models.HookEventStatus), form.Events, true)
+	w.Milestone = util.IsStringInSlice(string(models.HookEventMilestone), form.Events, true)
+	w.ProjectCard = util.IsStringInSlice(string(models.HookEventProjectCard), form.Events, true)
+	w.BranchProtection = util.IsStringInSlice(string(models.HookEventBranchProtection), form.Events, true)
 	w.BranchFilter = form.BranchFilter
 
 	if err := w.UpdateEvent(); err != nil {
@@ -267,6 +370,37 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	if form.Active != nil {
 		w.IsActive = *form.Active
 	}
+	if form.MaxRetries != nil {
+		if *form.MaxRetries < 0 || *form.MaxRetries > models.MaxHookRetries {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("max_retries must be between 0 and %d", models.MaxHookRetries))
+			return false
+		}
+		w.MaxRetries = *form.MaxRetries
+	}
+	if form.DisableAfterRetries != nil {
+		w.DisableAfterRetries = *form.DisableAfterRetries
+	}
+	if form.AuthorizationHeader != nil {
+		if err := w.SetHeaderAuthorization(*form.AuthorizationHeader); err != nil {
+			ctx.Error(http.StatusInternalServerError, "SetHeaderAuthorization", err)
+			return false
+		}
+	}
+	if form.ClientCertificate != nil || form.ClientKey != nil || form.CACertificates != nil {
+		cert, key, ca := w.ClientCertificate, "", w.CACertificates
+		if form.ClientCertificate != nil {
+			cert = *form.ClientCertificate
+		}
+		if form.ClientKey != nil {
+			key = *form.ClientKey
+		}
+		if form.CACertificates != nil {
+			ca = *form.CACertificates
+		}
+		if !setHookClientTLS(ctx, w, cert, key, ca) {
+			return false
+		}
+	}
 
 	if err := models.UpdateWebhook(w); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateWebhook", err)
@@ -274,3 +408,35 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	}
 	return true
 }
+
+// ListFailedHookDeliveries lists the deliveries of the webhook `w` which failed after all retries.
+// Writes to `ctx` accordingly
+func ListFailedHookDeliveries(ctx *context.APIContext, w *models.Webhook) {
+	listOptions := GetListOptions(ctx)
+	tasks, count, err := models.FindDeadLetterHookTasks(w.ID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDeadLetterHookTasks", err)
+		return
+	}
+
+	deliveries := make([]*api.HookDelivery, len(tasks))
+	for i := range tasks {
+		deliveries[i] = convert.ToHookDelivery(tasks[i])
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
+	ctx.JSON(http.StatusOK, &deliveries)
+}
+
+// RedeliverFailedHookDeliveries queues the deliveries of the webhook `w` which failed after all
+// retries for delivery again. Writes to `ctx` accordingly
+func RedeliverFailedHookDeliveries(ctx *context.APIContext, w *models.Webhook) {
+	form := web.GetForm(ctx).(*api.RedeliverHookOption)
+	if _, err := models.RedeliverHookTasks(w.ID, form.IDs); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RedeliverHookTasks", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/user"
+	"code.gitea.io/gitea/services/search"
 )
 
 const (
@@ -311,94 +312,33 @@ func ExploreCode(ctx *context.Context) {
 	queryType := strings.TrimSpace(ctx.Query("t"))
 	isMatch := queryType == "match"
 
-	var (
-		repoIDs []int64
-		err     error
-		isAdmin bool
-	)
-	if ctx.User != nil {
-		isAdmin = ctx.User.IsAdmin
-	}
-
-	// guest user or non-admin user
-	if ctx.User == nil || !isAdmin {
-		repoIDs, err = models.FindUserAccessibleRepoIDs(ctx.User)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
-	}
-
-	var (
-		total                 int
-		searchResults         []*code_indexer.Result
-		searchResultLanguages []*code_indexer.SearchResultLanguages
-	)
-
-	// if non-admin login user, we need check UnitTypeCode at first
-	if ctx.User != nil && len(repoIDs) > 0 {
-		repoMaps, err := models.GetRepositoriesMapByIDs(repoIDs)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
-
-		var rightRepoMap = make(map[int64]*models.Repository, len(repoMaps))
-		repoIDs = make([]int64, 0, len(repoMaps))
-		for id, repo := range repoMaps {
-			if repo.CheckUnitUser(ctx.User, models.UnitTypeCode) {
-				rightRepoMap[id] = repo
-				repoIDs = append(repoIDs, id)
-			}
-		}
-
-		ctx.Data["RepoMaps"] = rightRepoMap
-
-		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(repoIDs, language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
-		// if non-login user or isAdmin, no need to check UnitTypeCode
-	} else if (ctx.User == nil && len(repoIDs) > 0) || isAdmin {
-		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(repoIDs, language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
-
-		var loadRepoIDs = make([]int64, 0, len(searchResults))
-		for _, result := range searchResults {
-			var find bool
-			for _, id := range loadRepoIDs {
-				if id == result.RepoID {
-					find = true
-					break
-				}
-			}
-			if !find {
-				loadRepoIDs = append(loadRepoIDs, result.RepoID)
-			}
-		}
-
-		repoMaps, err := models.GetRepositoriesMapByIDs(loadRepoIDs)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
-
-		ctx.Data["RepoMaps"] = repoMaps
+	result, err := search.Code(&search.CodeOptions{
+		Doer:     ctx.User,
+		Query:    keyword,
+		Language: language,
+		IsMatch:  isMatch,
+		Page:     page,
+		PageSize: setting.UI.RepoSearchPagingNum,
+	})
+	if code_indexer.IsErrQueryTooBroad(err) {
+		ctx.Flash.Error(ctx.Tr("explore.code_search_too_broad", err.(code_indexer.ErrQueryTooBroad).Limit), true)
+		result = &search.CodeResult{Language: language}
+	} else if err != nil {
+		ctx.ServerError("SearchResults", err)
+		return
 	}
 
+	ctx.Data["CodeSearchLink"] = setting.AppSubURL + "/explore/code"
+	ctx.Data["RepoMaps"] = result.Repos
 	ctx.Data["Keyword"] = keyword
-	ctx.Data["Language"] = language
+	ctx.Data["Language"] = result.Language
 	ctx.Data["queryType"] = queryType
-	ctx.Data["SearchResults"] = searchResults
-	ctx.Data["SearchResultLanguages"] = searchResultLanguages
+	ctx.Data["SearchResults"] = result.Results
+	ctx.Data["SearchResultLanguages"] = result.Languages
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PageIsViewCode"] = true
 
-	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
+	pager := context.NewPagination(result.Total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
 	ctx.Data["Page"] = pager
@@ -35,6 +35,7 @@ func Home(ctx *context.Context) {
 	}
 
 	ctx.Data["PageIsUserProfile"] = true
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
 	ctx.Data["Title"] = org.DisplayName()
 	if len(org.Description) != 0 {
 		ctx.Data["RenderedDescription"] = string(markdown.Render([]byte(org.Description), ctx.Repo.RepoLink, map[string]string{"mode": "document"}))
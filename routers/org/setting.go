@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	userSetting "code.gitea.io/gitea/routers/user/setting"
 	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/webhook"
 )
 
 const (
@@ -192,6 +193,7 @@ func DeleteWebhook(ctx *context.Context) {
 	if err := models.DeleteWebhookByOrgID(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteWebhookByOrgID: " + err.Error())
 	} else {
+		webhook.RemoveWebhookClient(ctx.QueryInt64("id"))
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
@@ -23,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -437,6 +438,16 @@ func HookPostReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	if repo != nil && len(updates) > 0 {
+		pushMirrors, err := models.GetPushMirrorsSyncedOnCommit(repo.ID)
+		if err != nil {
+			log.Error("GetPushMirrorsSyncedOnCommit: %s/%s Error: %v", ownerName, repoName, err)
+		}
+		for _, mirror := range pushMirrors {
+			mirror_service.AddPushMirrorToQueue(mirror.ID)
+		}
+	}
+
 	// Push Options
 	if repo != nil && len(opts.GitPushOptions) > 0 {
 		repo.IsPrivate = opts.GitPushOptions.Bool(private.GitPushOptionRepoPrivate, repo.IsPrivate)
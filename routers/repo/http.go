@@ -221,6 +221,19 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			// Assume password is a token.
 			token, err := models.GetAccessTokenBySHA(authToken)
 			if err == nil {
+				requiredScope := models.AccessTokenScopeRepoRead
+				if !isPull {
+					requiredScope = models.AccessTokenScopeRepoWrite
+				}
+				if !token.HasScope(requiredScope) {
+					ctx.HandleText(http.StatusForbidden, fmt.Sprintf("Token requires the %s scope", requiredScope))
+					return
+				}
+				if !repoExist && len(token.RepoIDs) > 0 || repoExist && !token.CanAccessRepo(repo.ID) {
+					ctx.HandleText(http.StatusForbidden, "Token is not allowed to access this repository")
+					return
+				}
+
 				authUser, err = models.GetUserByID(token.UID)
 				if err != nil {
 					ctx.ServerError("GetUserByID", err)
@@ -33,6 +33,7 @@ import (
 	"code.gitea.io/gitea/services/forms"
 	issue_service "code.gitea.io/gitea/services/issue"
 	pull_service "code.gitea.io/gitea/services/pull"
+	"code.gitea.io/gitea/services/search"
 
 	"github.com/unknwon/com"
 )
@@ -154,14 +155,42 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 		keyword = ""
 	}
 
-	var issueIDs []int64
-	if len(keyword) > 0 {
-		issueIDs, err = issue_indexer.SearchIssuesByKeyword([]int64{repo.ID}, keyword)
+	var mileIDs []int64
+	if milestoneID > 0 {
+		mileIDs = []int64{milestoneID}
+	}
+
+	opts := &models.IssuesOptions{
+		RepoIDs:           []int64{repo.ID},
+		AssigneeID:        assigneeID,
+		PosterID:          posterID,
+		MentionedID:       mentionedID,
+		ReviewRequestedID: reviewRequestedID,
+		MilestoneIDs:      mileIDs,
+		ProjectID:         projectID,
+		IsPull:            isPullOption,
+		LabelIDs:          labelIDs,
+		SortType:          sortType,
+	}
+
+	// the qualifiers of the query narrow down the filters above
+	filter, err := search.ParseIssueQuery(ctx.User, []int64{repo.ID}, keyword)
+	if err != nil {
+		ctx.ServerError("ParseIssueQuery", err)
+		return
+	}
+	if !filter.Apply(opts) {
+		forceEmpty = true
+	}
+
+	if len(filter.Keyword) > 0 && !forceEmpty {
+		matches, err := issue_indexer.SearchIssueMatchesByKeyword([]int64{repo.ID}, filter.Keyword)
 		if err != nil {
 			ctx.ServerError("issueIndexer.Search", err)
 			return
 		}
-		if len(issueIDs) == 0 {
+		opts.IssueIDs, ctx.Data["MatchedComments"] = search.SplitIssueMatches(matches)
+		if len(opts.IssueIDs) == 0 {
 			forceEmpty = true
 		}
 	}
@@ -171,15 +200,16 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 		issueStats = &models.IssueStats{}
 	} else {
 		issueStats, err = models.GetIssueStats(&models.IssueStatsOptions{
-			RepoID:            repo.ID,
-			Labels:            selectLabels,
-			MilestoneID:       milestoneID,
-			AssigneeID:        assigneeID,
-			MentionedID:       mentionedID,
-			PosterID:          posterID,
-			ReviewRequestedID: reviewRequestedID,
-			IsPull:            isPullOption,
-			IssueIDs:          issueIDs,
+			RepoID:             repo.ID,
+			Labels:             selectLabels,
+			MilestoneIDs:       opts.MilestoneIDs,
+			AssigneeID:         opts.AssigneeID,
+			MentionedID:        opts.MentionedID,
+			PosterID:           opts.PosterID,
+			ReviewRequestedID:  reviewRequestedID,
+			IsPull:             opts.IsPull,
+			IssueIDs:           opts.IssueIDs,
+			RequiredLabelNames: opts.RequiredLabelNames,
 		})
 		if err != nil {
 			ctx.ServerError("GetIssueStats", err)
@@ -188,8 +218,10 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	}
 
 	isShowClosed := ctx.Query("state") == "closed"
-	// if open issues are zero and close don't, use closed as default
-	if len(ctx.Query("state")) == 0 && issueStats.OpenCount == 0 && issueStats.ClosedCount != 0 {
+	if filter.IsClosed != util.OptionalBoolNone {
+		isShowClosed = filter.IsClosed.IsTrue()
+	} else if len(ctx.Query("state")) == 0 && issueStats.OpenCount == 0 && issueStats.ClosedCount != 0 {
+		// if open issues are zero and close don't, use closed as default
 		isShowClosed = true
 	}
 
@@ -206,33 +238,16 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	}
 	pager := context.NewPagination(total, setting.UI.IssuePagingNum, page, 5)
 
-	var mileIDs []int64
-	if milestoneID > 0 {
-		mileIDs = []int64{milestoneID}
-	}
-
 	var issues []*models.Issue
 	if forceEmpty {
 		issues = []*models.Issue{}
 	} else {
-		issues, err = models.Issues(&models.IssuesOptions{
-			ListOptions: models.ListOptions{
-				Page:     pager.Paginater.Current(),
-				PageSize: setting.UI.IssuePagingNum,
-			},
-			RepoIDs:           []int64{repo.ID},
-			AssigneeID:        assigneeID,
-			PosterID:          posterID,
-			MentionedID:       mentionedID,
-			ReviewRequestedID: reviewRequestedID,
-			MilestoneIDs:      mileIDs,
-			ProjectID:         projectID,
-			IsClosed:          util.OptionalBoolOf(isShowClosed),
-			IsPull:            isPullOption,
-			LabelIDs:          labelIDs,
-			SortType:          sortType,
-			IssueIDs:          issueIDs,
-		})
+		opts.ListOptions = models.ListOptions{
+			Page:     pager.Paginater.Current(),
+			PageSize: setting.UI.IssuePagingNum,
+		}
+		opts.IsClosed = util.OptionalBoolOf(isShowClosed)
+		issues, err = models.Issues(opts)
 		if err != nil {
 			ctx.ServerError("Issues", err)
 			return
@@ -427,10 +442,7 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 }
 
 func retrieveProjects(ctx *context.Context, repo *models.Repository) {
-
-	var err error
-
-	ctx.Data["OpenProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
+	openProjects, _, err := models.GetProjects(models.ProjectSearchOptions{
 		RepoID:   repo.ID,
 		Page:     -1,
 		IsClosed: util.OptionalBoolFalse,
@@ -441,7 +453,7 @@ func retrieveProjects(ctx *context.Context, repo *models.Repository) {
 		return
 	}
 
-	ctx.Data["ClosedProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
+	closedProjects, _, err := models.GetProjects(models.ProjectSearchOptions{
 		RepoID:   repo.ID,
 		Page:     -1,
 		IsClosed: util.OptionalBoolTrue,
@@ -451,6 +463,37 @@ func retrieveProjects(ctx *context.Context, repo *models.Repository) {
 		ctx.ServerError("GetProjects", err)
 		return
 	}
+
+	for _, p := range append(openProjects, closedProjects...) {
+		p.Repo = repo
+	}
+
+	owners, err := projectOwnersOfDoer(ctx, repo)
+	if err != nil {
+		ctx.ServerError("projectOwnersOfDoer", err)
+		return
+	}
+	for _, owner := range owners {
+		projects, _, err := models.GetProjects(models.ProjectSearchOptions{
+			OwnerID: owner.ID,
+			Page:    -1,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
+		for _, p := range projects {
+			p.Owner = owner
+			if p.IsClosed {
+				closedProjects = append(closedProjects, p)
+			} else {
+				openProjects = append(openProjects, p)
+			}
+		}
+	}
+
+	ctx.Data["OpenProjects"] = openProjects
+	ctx.Data["ClosedProjects"] = closedProjects
 }
 
 // repoReviewerSelection items to bee shown
@@ -800,8 +843,8 @@ func NewIssue(ctx *context.Context) {
 		project, err := models.GetProjectByID(projectID)
 		if err != nil {
 			log.Error("GetProjectByID: %d: %v", projectID, err)
-		} else if project.RepoID != ctx.Repo.Repository.ID {
-			log.Error("GetProjectByID: %d: %v", projectID, fmt.Errorf("project[%d] not in repo [%d]", project.ID, ctx.Repo.Repository.ID))
+		} else if ok, err := canAssignProject(ctx, project); err != nil || !ok {
+			log.Error("GetProjectByID: %d: %v", projectID, fmt.Errorf("project[%d] can't be assigned in repo [%d]: %v", project.ID, ctx.Repo.Repository.ID, err))
 		} else {
 			ctx.Data["project_id"] = projectID
 			ctx.Data["Project"] = project
@@ -884,7 +927,10 @@ func ValidateRepoMetas(ctx *context.Context, form forms.CreateIssueForm, isPull
 			ctx.ServerError("GetProjectByID", err)
 			return nil, nil, 0, 0
 		}
-		if p.RepoID != ctx.Repo.Repository.ID {
+		if ok, err := canAssignProject(ctx, p); err != nil {
+			ctx.ServerError("canAssignProject", err)
+			return nil, nil, 0, 0
+		} else if !ok {
 			ctx.NotFound("", nil)
 			return nil, nil, 0, 0
 		}
@@ -1493,12 +1539,49 @@ func ViewIssue(ctx *context.Context) {
 				ctx.Data["MergeStyle"] = models.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				ctx.Data["MergeStyle"] = models.MergeStyleSquash
+			} else if prConfig.AllowFastForwardOnly {
+				ctx.Data["MergeStyle"] = models.MergeStyleFastForwardOnly
 			} else if prConfig.AllowManualMerge {
 				ctx.Data["MergeStyle"] = models.MergeStyleManuallyMerged
 			} else {
 				ctx.Data["MergeStyle"] = ""
 			}
 		}
+		if !pull.HasMerged && !issue.IsClosed {
+			autoMerge, err := models.GetPullAutoMergeByPullID(pull.ID)
+			if err != nil && !models.IsErrPullAutoMergeNotExist(err) {
+				ctx.ServerError("GetPullAutoMergeByPullID", err)
+				return
+			}
+			if autoMerge != nil {
+				if err = autoMerge.LoadDoer(); err != nil {
+					ctx.ServerError("LoadDoer", err)
+					return
+				}
+				ctx.Data["PullAutoMerge"] = autoMerge
+				ctx.Data["CanCancelAutoMerge"] = ctx.IsSigned && (ctx.Data["AllowMerge"].(bool) || autoMerge.DoerID == ctx.User.ID)
+			}
+
+			queue, err := models.GetMergeQueue(pull.BaseRepoID, pull.BaseBranch)
+			if err != nil {
+				ctx.ServerError("GetMergeQueue", err)
+				return
+			}
+			for i, entry := range queue {
+				if entry.PullID != pull.ID {
+					continue
+				}
+				if err = entry.LoadDoer(); err != nil {
+					ctx.ServerError("LoadDoer", err)
+					return
+				}
+				ctx.Data["MergeQueueEntry"] = entry
+				ctx.Data["MergeQueuePosition"] = i + 1
+				ctx.Data["MergeQueueLength"] = len(queue)
+				ctx.Data["CanRemoveFromMergeQueue"] = ctx.IsSigned && (ctx.Data["AllowMerge"].(bool) || entry.DoerID == ctx.User.ID)
+				break
+			}
+		}
 		if err = pull.LoadProtectedBranch(); err != nil {
 			ctx.ServerError("LoadProtectedBranch", err)
 			return
@@ -7,6 +7,7 @@ package repo
 
 import (
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -24,7 +25,8 @@ import (
 )
 
 const (
-	tplMigrate base.TplName = "repo/migrate/migrate"
+	tplMigrate     base.TplName = "repo/migrate/migrate"
+	tplMigrateDump base.TplName = "repo/migrate/dump"
 )
 
 // Migrate render migration of repository page
@@ -55,6 +57,7 @@ func Migrate(ctx *context.Context) {
 	ctx.Data["issues"] = ctx.Query("issues") == "1"
 	ctx.Data["pull_requests"] = ctx.Query("pull_requests") == "1"
 	ctx.Data["releases"] = ctx.Query("releases") == "1"
+	ctx.Data["incremental"] = ctx.Query("incremental") == "1"
 
 	ctxUser := checkContextUser(ctx, ctx.QueryInt64("org"))
 	if ctx.Written() {
@@ -65,7 +68,7 @@ func Migrate(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, base.TplName("repo/migrate/"+serviceType.Name()))
 }
 
-func handleMigrateError(ctx *context.Context, owner *models.User, err error, name string, tpl base.TplName, form *forms.MigrateRepoForm) {
+func handleMigrateError(ctx *context.Context, owner *models.User, err error, name string, tpl base.TplName, form interface{}) {
 	if setting.Repository.DisableMigrations {
 		ctx.Error(http.StatusForbidden, "MigrateError: the site administrator has disabled migrations")
 		return
@@ -100,8 +103,10 @@ func handleMigrateError(ctx *context.Context, owner *models.User, err error, nam
 		ctx.Data["Err_RepoName"] = true
 		ctx.RenderWithErr(ctx.Tr("repo.form.name_pattern_not_allowed", err.(models.ErrNamePatternNotAllowed).Pattern), tpl, form)
 	default:
-		remoteAddr, _ := forms.ParseRemoteAddr(form.CloneAddr, form.AuthUsername, form.AuthPassword)
-		err = util.URLSanitizedError(err, remoteAddr)
+		if form, ok := form.(*forms.MigrateRepoForm); ok {
+			remoteAddr, _ := forms.ParseRemoteAddr(form.CloneAddr, form.AuthUsername, form.AuthPassword)
+			err = util.URLSanitizedError(err, remoteAddr)
+		}
 		if strings.Contains(err.Error(), "Authentication failed") ||
 			strings.Contains(err.Error(), "Bad credentials") ||
 			strings.Contains(err.Error(), "could not read Username") {
@@ -216,8 +221,9 @@ func MigratePost(ctx *context.Context) {
 		Comments:       form.Issues || form.PullRequests,
 		PullRequests:   form.PullRequests,
 		Releases:       form.Releases,
+		Incremental:    form.Incremental && form.Mirror,
 	}
-	if opts.Mirror {
+	if opts.Mirror && !opts.Incremental {
 		opts.Issues = false
 		opts.Milestones = false
 		opts.Labels = false
@@ -241,12 +247,100 @@ func MigratePost(ctx *context.Context) {
 	handleMigrateError(ctx, ctxUser, err, "MigratePost", tpl, form)
 }
 
+// MigrateDump render the page to restore a repository from a dump archive
+func MigrateDump(ctx *context.Context) {
+	if setting.Repository.DisableMigrations {
+		ctx.Error(http.StatusForbidden, "MigrateDump: the site administrator has disabled migrations")
+		return
+	}
+
+	setMigrationContextData(ctx, 0)
+	ctx.Data["private"] = getRepoPrivate(ctx)
+
+	ctxUser := checkContextUser(ctx, ctx.QueryInt64("org"))
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ContextUser"] = ctxUser
+
+	ctx.HTML(http.StatusOK, tplMigrateDump)
+}
+
+// MigrateDumpPost response for restoring a repository from an uploaded dump archive
+func MigrateDumpPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.MigrateDumpForm)
+	if setting.Repository.DisableMigrations {
+		ctx.Error(http.StatusForbidden, "MigrateDumpPost: the site administrator has disabled migrations")
+		return
+	}
+
+	setMigrationContextData(ctx, 0)
+
+	ctxUser := checkContextUser(ctx, form.UID)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ContextUser"] = ctxUser
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplMigrateDump)
+		return
+	}
+
+	if form.Dump == nil {
+		ctx.Data["Err_Dump"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.migrate.dump_required"), tplMigrateDump, form)
+		return
+	}
+	if form.Dump.Size > setting.Migrations.MaxDumpSize*1024*1024 {
+		ctx.Data["Err_Dump"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.migrate.dump_too_big", setting.Migrations.MaxDumpSize), tplMigrateDump, form)
+		return
+	}
+
+	err := models.CheckCreateRepository(ctx.User, ctxUser, form.RepoName, false)
+	if err != nil {
+		handleMigrateError(ctx, ctxUser, err, "MigrateDumpPost", tplMigrateDump, form)
+		return
+	}
+
+	fr, err := form.Dump.Open()
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	archivePath, err := migrations.SaveRepositoryDump(fr, form.Dump.Filename)
+	if err != nil {
+		ctx.ServerError("SaveRepositoryDump", err)
+		return
+	}
+
+	err = task.RestoreRepository(ctx.User, ctxUser, migrations.MigrateOptions{
+		CloneAddr:   archivePath,
+		RepoName:    form.RepoName,
+		Description: form.Description,
+		Private:     form.Private || setting.Repository.ForcePrivate,
+	})
+	if err == nil {
+		ctx.Redirect(setting.AppSubURL + "/" + ctxUser.Name + "/" + form.RepoName)
+		return
+	}
+
+	if errRemove := util.RemoveAll(filepath.Dir(archivePath)); errRemove != nil {
+		log.Error("Unable to remove the repository dump %s: %v", archivePath, errRemove)
+	}
+	handleMigrateError(ctx, ctxUser, err, "MigrateDumpPost", tplMigrateDump, form)
+}
+
 func setMigrationContextData(ctx *context.Context, serviceType structs.GitServiceType) {
 	ctx.Data["Title"] = ctx.Tr("new_migrate")
 
 	ctx.Data["LFSActive"] = setting.LFS.StartServer
 	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
 	ctx.Data["DisableMirrors"] = setting.Repository.DisableMirrors
+	ctx.Data["MaxDumpSize"] = setting.Migrations.MaxDumpSize
 
 	// Plain git should be first
 	ctx.Data["Services"] = append([]structs.GitServiceType{structs.PlainGitService}, structs.SupportedFullGitService...)
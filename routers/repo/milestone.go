@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -231,14 +232,22 @@ func ChangeMilestoneStatus(ctx *context.Context) {
 	}
 	id := ctx.ParamsInt64(":id")
 
-	if err := models.ChangeMilestoneStatusByRepoIDAndID(ctx.Repo.Repository.ID, id, toClose); err != nil {
+	m, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, id)
+	if err != nil {
 		if models.IsErrMilestoneNotExist(err) {
 			ctx.NotFound("", err)
 		} else {
-			ctx.ServerError("ChangeMilestoneStatusByIDAndRepoID", err)
+			ctx.ServerError("GetMilestoneByRepoID", err)
 		}
 		return
 	}
+	if m.IsClosed != toClose {
+		if err := models.ChangeMilestoneStatus(m, toClose); err != nil {
+			ctx.ServerError("ChangeMilestoneStatus", err)
+			return
+		}
+		notification.NotifyMilestoneChangeStatus(ctx.User, ctx.Repo.Repository, m)
+	}
 	ctx.Redirect(ctx.Repo.RepoLink + "/milestones?state=" + ctx.Params(":action"))
 }
 
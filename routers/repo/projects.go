@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
@@ -20,10 +21,9 @@ import (
 )
 
 const (
-	tplProjects           base.TplName = "repo/projects/list"
-	tplProjectsNew        base.TplName = "repo/projects/new"
-	tplProjectsView       base.TplName = "repo/projects/view"
-	tplGenericProjectsNew base.TplName = "user/project"
+	tplProjects     base.TplName = "repo/projects/list"
+	tplProjectsNew  base.TplName = "repo/projects/new"
+	tplProjectsView base.TplName = "repo/projects/view"
 )
 
 // MustEnableProjects check if projects are enabled in settings
@@ -313,8 +313,9 @@ func ViewProject(ctx *context.Context) {
 
 	project.RenderedContent = string(markdown.Render([]byte(project.Description), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
 
-	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
+	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects) && !ctx.Repo.Repository.IsArchived
 	ctx.Data["Project"] = project
+	ctx.Data["ProjectLink"] = fmt.Sprintf("%s/projects/%d", ctx.Repo.RepoLink, project.ID)
 	ctx.Data["Boards"] = boards
 	ctx.Data["PageIsProjects"] = true
 	ctx.Data["RequiresDraggable"] = true
@@ -322,6 +323,60 @@ func ViewProject(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplProjectsView)
 }
 
+// projectOwnersOfDoer returns the owner of the repository, the signed in user and their organizations
+// as far as the signed in user can add issues to their projects
+func projectOwnersOfDoer(ctx *context.Context, repo *models.Repository) ([]*models.User, error) {
+	if ctx.User == nil {
+		return nil, nil
+	}
+
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	orgs, err := models.GetOrgsByUserID(ctx.User.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := append([]*models.User{repo.Owner, ctx.User}, orgs...)
+	owners := make([]*models.User, 0, len(candidates))
+	seen := make(map[int64]bool, len(candidates))
+	for _, owner := range candidates {
+		if seen[owner.ID] {
+			continue
+		}
+		seen[owner.ID] = true
+
+		mode, err := models.AccessModeOfOwnerProjects(owner, ctx.User)
+		if err != nil {
+			return nil, err
+		}
+		if mode >= models.AccessModeWrite {
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
+
+// canAssignProject returns whether the signed in user can add issues of the repository to the project
+func canAssignProject(ctx *context.Context, p *models.Project) (bool, error) {
+	if !p.IsOwnerProject() {
+		return p.RepoID == ctx.Repo.Repository.ID, nil
+	}
+	if ctx.User == nil {
+		return false, nil
+	}
+
+	if err := p.LoadOwner(); err != nil {
+		return false, err
+	}
+	mode, err := models.AccessModeOfOwnerProjects(p.Owner, ctx.User)
+	if err != nil {
+		return false, err
+	}
+	return mode >= models.AccessModeWrite, nil
+}
+
 // UpdateIssueProject change an issue's project
 func UpdateIssueProject(ctx *context.Context) {
 	issues := getActionIssues(ctx)
@@ -330,6 +385,25 @@ func UpdateIssueProject(ctx *context.Context) {
 	}
 
 	projectID := ctx.QueryInt64("id")
+	if projectID > 0 {
+		p, err := models.GetProjectByID(projectID)
+		if err != nil {
+			if models.IsErrProjectNotExist(err) {
+				ctx.NotFound("", nil)
+			} else {
+				ctx.ServerError("GetProjectByID", err)
+			}
+			return
+		}
+		if ok, err := canAssignProject(ctx, p); err != nil {
+			ctx.ServerError("canAssignProject", err)
+			return
+		} else if !ok {
+			ctx.NotFound("", nil)
+			return
+		}
+	}
+
 	for _, issue := range issues {
 		oldProjectID := issue.ProjectID()
 		if oldProjectID == projectID {
@@ -594,57 +668,16 @@ func MoveIssueAcrossBoards(ctx *context.Context) {
 		return
 	}
 
+	oldBoardID := issue.ProjectBoardID()
 	if err := models.MoveIssueAcrossProjectBoards(issue, board); err != nil {
 		ctx.ServerError("MoveIssueAcrossProjectBoards", err)
 		return
 	}
+	if oldBoardID != board.ID {
+		notification.NotifyMoveProjectIssue(ctx.User, issue, p, oldBoardID, board)
+	}
 
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"ok": true,
 	})
 }
-
-// CreateProject renders the generic project creation page
-func CreateProject(ctx *context.Context) {
-	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
-	ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
-	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
-
-	ctx.HTML(http.StatusOK, tplGenericProjectsNew)
-}
-
-// CreateProjectPost creates an individual and/or organization project
-func CreateProjectPost(ctx *context.Context, form forms.UserCreateProjectForm) {
-
-	user := checkContextUser(ctx, form.UID)
-	if ctx.Written() {
-		return
-	}
-
-	ctx.Data["ContextUser"] = user
-
-	if ctx.HasError() {
-		ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
-		ctx.HTML(http.StatusOK, tplGenericProjectsNew)
-		return
-	}
-
-	var projectType = models.ProjectTypeIndividual
-	if user.IsOrganization() {
-		projectType = models.ProjectTypeOrganization
-	}
-
-	if err := models.NewProject(&models.Project{
-		Title:       form.Title,
-		Description: form.Content,
-		CreatorID:   user.ID,
-		BoardType:   form.BoardType,
-		Type:        projectType,
-	}); err != nil {
-		ctx.ServerError("NewProject", err)
-		return
-	}
-
-	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
-	ctx.Redirect(setting.AppSubURL + "/")
-}
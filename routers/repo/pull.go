@@ -820,6 +820,43 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
+	message := strings.TrimSpace(form.MergeTitleField)
+	if len(message) == 0 {
+		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
+			message = pr.GetDefaultMergeMessage()
+		}
+		if models.MergeStyle(form.Do) == models.MergeStyleRebaseMerge {
+			message = pr.GetDefaultMergeMessage()
+		}
+		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		}
+	}
+
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	if form.MergeWhenChecksSucceed {
+		if err := pull_service.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrPullAutoMergeAlreadyScheduled(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_already_scheduled"))
+			} else {
+				ctx.ServerError("ScheduleAutoMerge", err)
+				return
+			}
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+			return
+		}
+
+		ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_newly_scheduled"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+		return
+	}
+
 	if !pr.CanAutoMerge() {
 		ctx.Flash.Error(ctx.Tr("repo.pulls.no_merge_not_ready"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
@@ -853,24 +890,6 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
-	message := strings.TrimSpace(form.MergeTitleField)
-	if len(message) == 0 {
-		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
-			message = pr.GetDefaultMergeMessage()
-		}
-		if models.MergeStyle(form.Do) == models.MergeStyleRebaseMerge {
-			message = pr.GetDefaultMergeMessage()
-		}
-		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
-			message = pr.GetDefaultSquashMessage()
-		}
-	}
-
-	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
-	if len(form.MergeMessageField) > 0 {
-		message += "\n\n" + form.MergeMessageField
-	}
-
 	pr.Issue = issue
 	pr.Issue.Repo = ctx.Repo.Repository
 
@@ -885,6 +904,26 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
+	if useMergeQueue, err := pull_service.IsMergeQueueEnabled(pr); err != nil {
+		ctx.ServerError("IsMergeQueueEnabled", err)
+		return
+	} else if useMergeQueue {
+		if err := pull_service.AddToMergeQueue(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrPullAlreadyInMergeQueue(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_already_queued"))
+			} else {
+				ctx.ServerError("AddToMergeQueue", err)
+				return
+			}
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_newly_queued"))
+		}
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+		return
+	}
+
 	if err = pull_service.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
@@ -923,6 +962,11 @@ func MergePullRequest(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.unrelated_histories"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
 			return
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			log.Debug("MergeDivergingFastForwardOnly error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.fast_forward_only_merge_pull_request_diverging"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			log.Debug("MergePushOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_out_of_date"))
@@ -962,6 +1006,88 @@ func MergePullRequest(ctx *context.Context) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
 }
 
+// CancelAutoMergePullRequest cancels the scheduled auto merge of a pull request
+func CancelAutoMergePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	autoMerge, err := models.GetPullAutoMergeByPullID(pr.ID)
+	if err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound("GetPullAutoMergeByPullID", err)
+		} else {
+			ctx.ServerError("GetPullAutoMergeByPullID", err)
+		}
+		return
+	}
+
+	if autoMerge.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := pull_service.CancelAutoMerge(ctx.User, pr); err != nil {
+		if !models.IsErrPullAutoMergeNotExist(err) {
+			ctx.ServerError("CancelAutoMerge", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_canceled_schedule"))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		if models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.NotFound("GetMergeQueueEntryByPullID", err)
+		} else {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		}
+		return
+	}
+
+	if entry.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(ctx.User, pr); err != nil {
+		if !models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.ServerError("RemoveFromMergeQueue", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_removed"))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
+}
+
 func stopTimerIfAvailable(user *models.User, issue *models.Issue) error {
 
 	if models.StopwatchExists(user.ID, issue.ID) {
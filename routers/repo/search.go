@@ -32,11 +32,42 @@ func Search(ctx *context.Context) {
 	queryType := strings.TrimSpace(ctx.Query("t"))
 	isMatch := queryType == "match"
 
-	total, searchResults, searchResultLanguages, err := code_indexer.PerformSearch([]int64{ctx.Repo.Repository.ID},
-		language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-	if err != nil {
-		ctx.ServerError("SearchResults", err)
-		return
+	// the repository is given, so `repo:` qualifiers are ignored
+	query := code_indexer.ParseSearchQuery(keyword)
+	if len(query.Language) > 0 {
+		language = query.Language
+	}
+
+	var (
+		total                 int
+		searchResults         []*code_indexer.Result
+		searchResultLanguages []*code_indexer.SearchResultLanguages
+		branchRepoIDs         []int64
+	)
+	isBranch := len(query.Branch) > 0 && query.Branch != ctx.Repo.Repository.DefaultBranch
+	if isBranch {
+		branchRepoIDs = []int64{ctx.Repo.Repository.ID}
+	}
+	if !isBranch || code_indexer.IsIndexedBranch(ctx.Repo.Repository, query.Branch) {
+		var err error
+		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(&code_indexer.SearchOptions{
+			RepoIDs:       []int64{ctx.Repo.Repository.ID},
+			Keyword:       query.Keyword,
+			Language:      language,
+			IsMatch:       isMatch,
+			Paths:         query.Paths,
+			Extensions:    query.Extensions,
+			Branch:        query.Branch,
+			BranchRepoIDs: branchRepoIDs,
+			Page:          page,
+			PageSize:      setting.UI.RepoSearchPagingNum,
+		})
+		if code_indexer.IsErrQueryTooBroad(err) {
+			ctx.Flash.Error(ctx.Tr("explore.code_search_too_broad", err.(code_indexer.ErrQueryTooBroad).Limit), true)
+		} else if err != nil {
+			ctx.ServerError("SearchResults", err)
+			return
+		}
 	}
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Language"] = language
@@ -17,13 +17,16 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/migrations"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/task"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
@@ -31,6 +34,8 @@ import (
 	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/gobwas/glob"
 )
 
 const (
@@ -52,15 +57,64 @@ func Settings(ctx *context.Context) {
 	signing, _ := models.SigningKey(ctx.Repo.Repository.RepoPath())
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
+	ctx.Data["CodeIndexerBranches"] = strings.Join(ctx.Repo.Repository.CodeIndexerBranches, ", ")
+
+	if !setRepositoryExportData(ctx) {
+		return
+	}
 
 	ctx.HTML(http.StatusOK, tplSettingsOptions)
 }
 
+// setRepositoryExportData sets the status of the latest export of the repository, it returns false on errors
+func setRepositoryExportData(ctx *context.Context) bool {
+	exportTask, err := models.GetLatestExportTask(ctx.Repo.Repository.ID)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return true
+		}
+		ctx.ServerError("GetLatestExportTask", err)
+		return false
+	}
+	ctx.Data["ExportTask"] = exportTask
+
+	if exportTask.IsRunning() {
+		translatableMessage, err := exportTask.TranslatableMessage()
+		if err != nil {
+			log.Error("Task[%d] has an invalid message: %v", exportTask.ID, err)
+		} else if translatableMessage != nil {
+			ctx.Data["ExportMessage"] = ctx.Tr(translatableMessage.Format, translatableMessage.Args...)
+		}
+	}
+
+	if isExist, err := util.IsFile(migrations.RepositoryExportPath(ctx.Repo.Repository)); err != nil {
+		ctx.ServerError("IsFile", err)
+		return false
+	} else if isExist {
+		ctx.Data["ExportLink"] = ctx.Repo.RepoLink + "/settings/export"
+	}
+	return true
+}
+
+// SettingsExportDownload serves the latest export archive of a repository
+func SettingsExportDownload(ctx *context.Context) {
+	exportPath := migrations.RepositoryExportPath(ctx.Repo.Repository)
+	if isExist, err := util.IsFile(exportPath); err != nil {
+		ctx.ServerError("IsFile", err)
+		return
+	} else if !isExist {
+		ctx.NotFound("SettingsExportDownload", nil)
+		return
+	}
+	ctx.ServeFile(exportPath, ctx.Repo.Repository.Name+"-export.zip")
+}
+
 // SettingsPost response for changes of a repository
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.RepoSettingForm)
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
+	ctx.Data["CodeIndexerBranches"] = strings.Join(ctx.Repo.Repository.CodeIndexerBranches, ", ")
 
 	repo := ctx.Repo.Repository
 
@@ -324,6 +378,7 @@ func SettingsPost(ctx *context.Context) {
 					AllowRebase:               form.PullsAllowRebase,
 					AllowRebaseMerge:          form.PullsAllowRebaseMerge,
 					AllowSquash:               form.PullsAllowSquash,
+					AllowFastForwardOnly:      form.PullsAllowFastForwardOnly,
 					AllowManualMerge:          form.PullsAllowManualMerge,
 					AutodetectManualMerge:     form.EnableAutodetectManualMerge,
 					DefaultMergeStyle:         models.MergeStyle(form.PullsDefaultMergeStyle),
@@ -368,6 +423,51 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "code_indexer":
+		branches := make([]string, 0, 5)
+		for _, pattern := range strings.FieldsFunc(form.CodeIndexerBranches, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r'
+		}) {
+			pattern = strings.TrimSpace(pattern)
+			if len(pattern) == 0 || util.IsStringInSlice(pattern, branches) {
+				continue
+			}
+			if _, err := glob.Compile(pattern); err != nil {
+				ctx.Flash.Error(ctx.Tr("repo.settings.code_indexer_branches_invalid", pattern))
+				ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+				return
+			}
+			branches = append(branches, pattern)
+		}
+
+		repo.CodeIndexerBranches = branches
+		if err := models.UpdateRepositoryCols(repo, "code_indexer_branches"); err != nil {
+			ctx.ServerError("UpdateRepositoryCols", err)
+			return
+		}
+		if setting.Indexer.RepoIndexerEnabled {
+			code_indexer.UpdateRepoIndexer(repo)
+		}
+		log.Trace("Repository code indexer settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "export":
+		if err := task.ExportRepository(ctx.User, repo); err != nil {
+			if err == task.ErrExportInProgress {
+				ctx.Flash.Error(ctx.Tr("repo.settings.export.in_progress"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+				return
+			}
+			ctx.ServerError("ExportRepository", err)
+			return
+		}
+		log.Trace("Repository export requested: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.export.requested"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
 	case "admin":
 		if !ctx.User.IsAdmin {
 			ctx.Error(http.StatusForbidden)
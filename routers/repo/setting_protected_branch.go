@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
@@ -237,6 +238,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		} else {
 			protectBranch.StatusCheckContexts = nil
 		}
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
 
 		protectBranch.RequiredApprovals = f.RequiredApprovals
 		protectBranch.EnableApprovalsWhitelist = f.EnableApprovalsWhitelist
@@ -255,6 +257,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
 
+		isNew := protectBranch.ID == 0
 		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 			UserIDs:          whitelistUsers,
 			TeamIDs:          whitelistTeams,
@@ -267,6 +270,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
+		if isNew {
+			notification.NotifyCreateBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch)
+		} else {
+			notification.NotifyUpdateBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch)
+		}
 		if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 			ctx.ServerError("CheckPrsForBaseBranch", err)
 			return
@@ -279,6 +287,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			notification.NotifyDeleteBranchProtection(ctx.User, ctx.Repo.Repository, protectBranch)
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
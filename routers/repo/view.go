@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -29,6 +30,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
 )
 
 const (
@@ -581,7 +583,12 @@ func Home(ctx *context.Context) {
 
 			ctx.Data["Repo"] = ctx.Repo
 			ctx.Data["MigrateTask"] = task
-			ctx.Data["CloneAddr"] = safeURL(cfg.CloneAddr)
+			if task.Type == structs.TaskTypeRestoreRepo {
+				// the archive keeps the name it was uploaded with
+				ctx.Data["RestoreArchive"] = filepath.Base(cfg.CloneAddr)
+			} else {
+				ctx.Data["CloneAddr"] = safeURL(cfg.CloneAddr)
+			}
 			ctx.HTML(http.StatusOK, tplMigrating)
 			return
 		}
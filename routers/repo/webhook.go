@@ -8,6 +8,7 @@ package repo
 import (
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
 	"path"
 	"strings"
@@ -177,11 +178,34 @@ func ParseHookEvent(form forms.WebhookForm) *models.HookEvent {
 			PullRequestReview:    form.PullRequestReview,
 			PullRequestSync:      form.PullRequestSync,
 			Repository:           form.Repository,
+			Wiki:                 form.Wiki,
+			Status:               form.Status,
+			Milestone:            form.Milestone,
+			ProjectCard:          form.ProjectCard,
+			BranchProtection:     form.BranchProtection,
 		},
 		BranchFilter: form.BranchFilter,
 	}
 }
 
+// setWebhookClientTLS validates and sets the client certificate, key and CA certificates of a webhook
+func setWebhookClientTLS(ctx *context.Context, w *models.Webhook, cert, key, ca string, tpl base.TplName, form interface{}) bool {
+	if err := webhook.SetClientTLS(w, cert, key, ca); err != nil {
+		switch {
+		case webhook.IsErrInvalidClientCertificate(err):
+			ctx.Data["Err_ClientCertificate"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.invalid_client_certificate", html.EscapeString(err.(webhook.ErrInvalidClientCertificate).Err.Error())), tpl, form)
+		case webhook.IsErrInvalidCACertificates(err):
+			ctx.Data["Err_CACertificates"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.invalid_ca_certificates"), tpl, form)
+		default:
+			ctx.ServerError("SetClientTLS", err)
+		}
+		return false
+	}
+	return true
+}
+
 // GiteaHooksNewPost response for creating Gitea webhook
 func GiteaHooksNewPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.NewWebhookForm)
@@ -209,16 +233,26 @@ func GiteaHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		HTTPMethod:      form.HTTPMethod,
-		ContentType:     contentType,
-		Secret:          form.Secret,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.GITEA,
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		HTTPMethod:          form.HTTPMethod,
+		ContentType:         contentType,
+		Secret:              form.Secret,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		SignatureAlgorithm:  form.SignatureAlgorithm,
+		Type:                models.GITEA,
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
+	}
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.ServerError("SetHeaderAuthorization", err)
+		return
+	}
+	if !setWebhookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates, orCtx.NewTemplate, form) {
+		return
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -264,15 +298,25 @@ func newGogsWebhookPost(ctx *context.Context, form forms.NewGogshookForm, kind m
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		ContentType:     contentType,
-		Secret:          form.Secret,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            kind,
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		ContentType:         contentType,
+		Secret:              form.Secret,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		SignatureAlgorithm:  form.SignatureAlgorithm,
+		Type:                kind,
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
+	}
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.ServerError("SetHeaderAuthorization", err)
+		return
+	}
+	if !setWebhookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates, orCtx.NewTemplate, form) {
+		return
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -317,15 +361,17 @@ func DiscordHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		ContentType:     models.ContentTypeJSON,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.DISCORD,
-		Meta:            string(meta),
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		ContentType:         models.ContentTypeJSON,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.DISCORD,
+		Meta:                string(meta),
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -360,15 +406,17 @@ func DingtalkHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		ContentType:     models.ContentTypeJSON,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.DINGTALK,
-		Meta:            "",
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		ContentType:         models.ContentTypeJSON,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.DINGTALK,
+		Meta:                "",
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -413,15 +461,17 @@ func TelegramHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID),
-		ContentType:     models.ContentTypeJSON,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.TELEGRAM,
-		Meta:            string(meta),
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID),
+		ContentType:         models.ContentTypeJSON,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.TELEGRAM,
+		Meta:                string(meta),
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -468,16 +518,18 @@ func MatrixHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message", form.HomeserverURL, form.RoomID),
-		ContentType:     models.ContentTypeJSON,
-		HTTPMethod:      "PUT",
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.MATRIX,
-		Meta:            string(meta),
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message", form.HomeserverURL, form.RoomID),
+		ContentType:         models.ContentTypeJSON,
+		HTTPMethod:          "PUT",
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.MATRIX,
+		Meta:                string(meta),
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -512,15 +564,17 @@ func MSTeamsHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		ContentType:     models.ContentTypeJSON,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.MSTEAMS,
-		Meta:            "",
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		ContentType:         models.ContentTypeJSON,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.MSTEAMS,
+		Meta:                "",
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -573,15 +627,17 @@ func SlackHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		ContentType:     models.ContentTypeJSON,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.SLACK,
-		Meta:            string(meta),
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		ContentType:         models.ContentTypeJSON,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.SLACK,
+		Meta:                string(meta),
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -616,15 +672,113 @@ func FeishuHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &models.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		ContentType:     models.ContentTypeJSON,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            models.FEISHU,
-		Meta:            "",
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		ContentType:         models.ContentTypeJSON,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		Type:                models.FEISHU,
+		Meta:                "",
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// customHookMeta validates the template, content type and headers of a custom hook form and returns
+// them as webhook metadata. If they are invalid, the form is rendered again with an error.
+func customHookMeta(ctx *context.Context, form *forms.NewCustomHookForm, tpl base.TplName) (string, bool) {
+	custom := &webhook.CustomMeta{
+		Template:    form.Template,
+		ContentType: strings.TrimSpace(form.ContentType),
+		Headers:     form.Headers,
+	}
+	ctx.Data["CustomHook"] = custom
+
+	if err := custom.Validate(); err != nil {
+		switch {
+		case webhook.IsErrCustomTemplate(err):
+			ctx.Data["Err_Template"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.custom.invalid_template", html.EscapeString(err.(webhook.ErrCustomTemplate).Err.Error())), tpl, form)
+		case webhook.IsErrInvalidCustomContentType(err):
+			ctx.Data["Err_ContentType"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.custom.invalid_content_type"), tpl, form)
+		case webhook.IsErrInvalidCustomHeader(err):
+			ctx.Data["Err_Headers"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.custom.invalid_header", html.EscapeString(err.(webhook.ErrInvalidCustomHeader).Header)), tpl, form)
+		default:
+			ctx.ServerError("Validate", err)
+		}
+		return "", false
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	meta, err := json.Marshal(custom)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return "", false
+	}
+	return string(meta), true
+}
+
+// CustomHooksNewPost response for creating custom hook
+func CustomHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewCustomHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.CUSTOM
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, ok := customHookMeta(ctx, form, orCtx.NewTemplate)
+	if !ok {
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:              orCtx.RepoID,
+		URL:                 form.PayloadURL,
+		HTTPMethod:          form.HTTPMethod,
+		ContentType:         models.ContentTypeJSON,
+		Secret:              form.Secret,
+		HookEvent:           ParseHookEvent(form.WebhookForm),
+		IsActive:            form.Active,
+		MaxRetries:          form.MaxRetries,
+		DisableAfterRetries: form.DisableAfterRetries,
+		SignatureAlgorithm:  form.SignatureAlgorithm,
+		Type:                models.CUSTOM,
+		Meta:                meta,
+		OrgID:               orCtx.OrgID,
+		IsSystemWebhook:     orCtx.IsSystemWebhook,
+	}
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.ServerError("SetHeaderAuthorization", err)
+		return
+	}
+	if !setWebhookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates, orCtx.NewTemplate, form) {
+		return
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -666,6 +820,11 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 	}
 
 	ctx.Data["HookType"] = w.Type
+	ctx.Data["AuthorizationHeader"], err = w.HeaderAuthorization()
+	if err != nil {
+		ctx.ServerError("HeaderAuthorization", err)
+		return nil, nil
+	}
 	switch w.Type {
 	case models.SLACK:
 		ctx.Data["SlackHook"] = webhook.GetSlackHook(w)
@@ -675,6 +834,8 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 		ctx.Data["TelegramHook"] = webhook.GetTelegramHook(w)
 	case models.MATRIX:
 		ctx.Data["MatrixHook"] = webhook.GetMatrixHook(w)
+	case models.CUSTOM:
+		ctx.Data["CustomHook"] = webhook.GetCustomHook(w)
 	}
 
 	ctx.Data["History"], err = w.History(1)
@@ -727,6 +888,16 @@ func WebHooksEditPost(ctx *context.Context) {
 	w.Secret = form.Secret
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
+	w.SignatureAlgorithm = form.SignatureAlgorithm
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.ServerError("SetHeaderAuthorization", err)
+		return
+	}
+	if !setWebhookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates, orCtx.NewTemplate, form) {
+		return
+	}
 	w.HTTPMethod = form.HTTPMethod
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -768,6 +939,16 @@ func GogsHooksEditPost(ctx *context.Context) {
 	w.Secret = form.Secret
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
+	w.SignatureAlgorithm = form.SignatureAlgorithm
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.ServerError("SetHeaderAuthorization", err)
+		return
+	}
+	if !setWebhookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates, orCtx.NewTemplate, form) {
+		return
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -820,6 +1001,8 @@ func SlackHooksEditPost(ctx *context.Context) {
 	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -864,6 +1047,8 @@ func DiscordHooksEditPost(ctx *context.Context) {
 	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -897,6 +1082,8 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -939,6 +1126,8 @@ func TelegramHooksEditPost(ctx *context.Context) {
 	w.URL = fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -984,6 +1173,8 @@ func MatrixHooksEditPost(ctx *context.Context) {
 
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1017,6 +1208,8 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1050,6 +1243,59 @@ func FeishuHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// CustomHooksEditPost response for editing custom hook
+func CustomHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewCustomHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
+		return
+	}
+
+	meta, ok := customHookMeta(ctx, form, orCtx.NewTemplate)
+	if !ok {
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.HTTPMethod = form.HTTPMethod
+	w.Secret = form.Secret
+	w.Meta = meta
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	w.MaxRetries = form.MaxRetries
+	w.DisableAfterRetries = form.DisableAfterRetries
+	w.SignatureAlgorithm = form.SignatureAlgorithm
+	if err := w.SetHeaderAuthorization(form.AuthorizationHeader); err != nil {
+		ctx.ServerError("SetHeaderAuthorization", err)
+		return
+	}
+	if !setWebhookClientTLS(ctx, w, form.ClientCertificate, form.ClientKey, form.CACertificates, orCtx.NewTemplate, form) {
+		return
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1122,6 +1368,7 @@ func DeleteWebhook(ctx *context.Context) {
 	if err := models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteWebhookByRepoID: " + err.Error())
 	} else {
+		webhook.RemoveWebhookClient(ctx.QueryInt64("id"))
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
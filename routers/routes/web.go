@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/log"
@@ -27,6 +28,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers"
 	"code.gitea.io/gitea/routers/admin"
+	packages_router "code.gitea.io/gitea/routers/api/packages"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/dev"
@@ -50,7 +52,6 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/cors"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/tstranex/u2f"
 	"github.com/unknwon/com"
 )
 
@@ -122,6 +123,8 @@ func NormalRoutes() *web.Route {
 
 	r.Mount("/", WebRoutes())
 	r.Mount("/api/v1", apiv1.Routes())
+	r.Mount("/api/packages", packages_router.Routes())
+	r.Mount("/v2", packages_router.ContainerRoutes())
 	r.Mount("/api/internal", private.Routes())
 	return r
 }
@@ -158,7 +161,7 @@ func WebRoutes() *web.Route {
 	r.Use(storageHandler(setting.Avatar.Storage, "avatars", storage.Avatars))
 	r.Use(storageHandler(setting.RepoAvatar.Storage, "repo-avatars", storage.RepoAvatars))
 
-	gob.Register(&u2f.Challenge{})
+	gob.Register(&webauthn.SessionData{})
 
 	if setting.EnableGzip {
 		h, err := gziphandler.GzipHandlerWithOpts(gziphandler.MinSize(GzipMinSize))
@@ -346,6 +349,8 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/code", routers.ExploreCode)
 	}, ignExploreSignIn)
 	m.Get("/issues", reqSignIn, user.Issues)
+	m.Post("/issues/searches", reqSignIn, bindIgnErr(forms.SaveIssueSearchForm{}), user.SaveIssueSearch)
+	m.Post("/issues/searches/{id}/delete", reqSignIn, user.DeleteIssueSearch)
 	m.Get("/pulls", reqSignIn, user.Pulls)
 	m.Get("/milestones", reqSignIn, reqMilestonesDashboardPageEnabled, user.Milestones)
 
@@ -383,11 +388,12 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/scratch", user.TwoFactorScratch)
 			m.Post("/scratch", bindIgnErr(forms.TwoFactorScratchAuthForm{}), user.TwoFactorScratchPost)
 		})
-		m.Group("/u2f", func() {
-			m.Get("", user.U2F)
-			m.Get("/challenge", user.U2FChallenge)
-			m.Post("/sign", bindIgnErr(u2f.SignResponse{}), user.U2FSign)
-
+		m.Group("/webauthn", func() {
+			m.Get("", user.WebAuthn)
+			m.Get("/assertion", user.WebAuthnLoginAssertion)
+			m.Post("/assertion", bindIgnErr(webauthn.CredentialAssertionResponse{}), user.WebAuthnLoginAssertionPost)
+			m.Get("/passwordless/assertion", user.WebAuthnPasswordlessAssertion)
+			m.Post("/passwordless/assertion", bindIgnErr(webauthn.CredentialAssertionResponse{}), user.WebAuthnPasswordlessAssertionPost)
 		})
 	}, reqSignOut)
 
@@ -434,10 +440,10 @@ func RegisterRoutes(m *web.Route) {
 				m.Get("/enroll", userSetting.EnrollTwoFactor)
 				m.Post("/enroll", bindIgnErr(forms.TwoFactorAuthForm{}), userSetting.EnrollTwoFactorPost)
 			})
-			m.Group("/u2f", func() {
-				m.Post("/request_register", bindIgnErr(forms.U2FRegistrationForm{}), userSetting.U2FRegister)
-				m.Post("/register", bindIgnErr(u2f.RegisterResponse{}), userSetting.U2FRegisterPost)
-				m.Post("/delete", bindIgnErr(forms.U2FDeleteForm{}), userSetting.U2FDelete)
+			m.Group("/webauthn", func() {
+				m.Post("/request_register", bindIgnErr(forms.WebAuthnRegistrationForm{}), userSetting.WebAuthnRegister)
+				m.Post("/register", bindIgnErr(webauthn.CredentialCreationResponse{}), userSetting.WebAuthnRegisterPost)
+				m.Post("/delete", bindIgnErr(forms.WebAuthnDeleteForm{}), userSetting.WebAuthnDelete)
 			})
 			m.Group("/openid", func() {
 				m.Post("", bindIgnErr(forms.AddOpenIDForm{}), userSetting.OpenIDPost)
@@ -540,6 +546,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/matrix/{id}", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 			m.Post("/msteams/{id}", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 			m.Post("/feishu/{id}", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+			m.Post("/custom/{id}", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 		}, webhooksEnabled)
 
 		m.Group("/{configType:default-hooks|system-hooks}", func() {
@@ -553,6 +560,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/matrix/new", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 			m.Post("/msteams/new", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 			m.Post("/feishu/new", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+			m.Post("/custom/new", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 		})
 
 		m.Group("/auths", func() {
@@ -580,6 +588,33 @@ func RegisterRoutes(m *web.Route) {
 		m.Post("/action/{action}", user.Action)
 	}, reqSignIn)
 
+	m.Get("/{username}/-/code", ignSignIn, user.CodeSearch)
+
+	m.Group("/{username}/-/projects", func() {
+		m.Get("", user.Projects)
+		m.Get("/{id}", user.ViewProject)
+		m.Group("", func() {
+			m.Get("/new", user.NewProject)
+			m.Post("/new", bindIgnErr(forms.CreateProjectForm{}), user.NewProjectPost)
+			m.Group("/{id}", func() {
+				m.Post("", bindIgnErr(forms.EditProjectBoardForm{}), user.AddBoardToProjectPost)
+				m.Post("/delete", user.DeleteProject)
+
+				m.Get("/edit", user.EditProject)
+				m.Post("/edit", bindIgnErr(forms.CreateProjectForm{}), user.EditProjectPost)
+				m.Post("/{action:open|close}", user.ChangeProjectStatus)
+
+				m.Group("/{boardID}", func() {
+					m.Put("", bindIgnErr(forms.EditProjectBoardForm{}), user.EditProjectBoard)
+					m.Delete("", user.DeleteProjectBoard)
+					m.Post("/default", user.SetDefaultProjectBoard)
+
+					m.Post("/{index}", user.MoveIssueAcrossBoards)
+				})
+			})
+		}, reqSignIn, user.MustWriteProjects)
+	}, ignSignIn, user.ProjectsAssignment)
+
 	if !setting.IsProd() {
 		m.Get("/template/*", dev.TemplatePreview)
 	}
@@ -652,6 +687,7 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/matrix/new", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 					m.Post("/msteams/new", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 					m.Post("/feishu/new", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+					m.Post("/custom/new", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 					m.Get("/{id}", repo.WebHooksEdit)
 					m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
 					m.Post("/gogs/{id}", bindIgnErr(forms.NewGogshookForm{}), repo.GogsHooksEditPost)
@@ -662,6 +698,7 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/matrix/{id}", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 					m.Post("/msteams/{id}", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 					m.Post("/feishu/{id}", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+					m.Post("/custom/{id}", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 				}, webhooksEnabled)
 
 				m.Group("/labels", func() {
@@ -684,6 +721,8 @@ func RegisterRoutes(m *web.Route) {
 		m.Post("/create", bindIgnErr(forms.CreateRepoForm{}), repo.CreatePost)
 		m.Get("/migrate", repo.Migrate)
 		m.Post("/migrate", bindIgnErr(forms.MigrateRepoForm{}), repo.MigratePost)
+		m.Get("/migrate/dump", repo.MigrateDump)
+		m.Post("/migrate/dump", bindIgnErr(forms.MigrateDumpForm{}), repo.MigrateDumpPost)
 		m.Group("/fork", func() {
 			m.Combo("/{repoid}").Get(repo.Fork).
 				Post(bindIgnErr(forms.CreateRepoForm{}), repo.ForkPost)
@@ -699,6 +738,7 @@ func RegisterRoutes(m *web.Route) {
 				Post(bindIgnErr(forms.RepoSettingForm{}), repo.SettingsPost)
 			m.Post("/avatar", bindIgnErr(forms.AvatarForm{}), repo.SettingsAvatar)
 			m.Post("/avatar/delete", repo.SettingsDeleteAvatar)
+			m.Get("/export", repo.SettingsExportDownload)
 
 			m.Group("/collaboration", func() {
 				m.Combo("").Get(repo.Collaboration).Post(repo.CollaborationPost)
@@ -734,6 +774,7 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/matrix/new", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 				m.Post("/msteams/new", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 				m.Post("/feishu/new", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+				m.Post("/custom/new", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 				m.Get("/{id}", repo.WebHooksEdit)
 				m.Post("/{id}/test", repo.TestWebhook)
 				m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
@@ -745,6 +786,7 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/matrix/{id}", bindIgnErr(forms.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 				m.Post("/msteams/{id}", bindIgnErr(forms.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 				m.Post("/feishu/{id}", bindIgnErr(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+				m.Post("/custom/{id}", bindIgnErr(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 			}, webhooksEnabled)
 
 			m.Group("/keys", func() {
@@ -1018,6 +1060,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(forms.MergePullRequestForm{}), repo.MergePullRequest)
+			m.Post("/cancel_auto_merge", reqSignIn, context.RepoMustNotBeArchived(), repo.CancelAutoMergePullRequest)
+			m.Post("/remove_from_merge_queue", reqSignIn, context.RepoMustNotBeArchived(), repo.RemoveFromMergeQueue)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
 			m.Group("/files", func() {
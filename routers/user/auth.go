@@ -9,10 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/oauth2"
+	"code.gitea.io/gitea/modules/auth/webauthn"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/eventsource"
@@ -30,7 +32,6 @@ import (
 	"code.gitea.io/gitea/services/mailer"
 
 	"github.com/markbates/goth"
-	"github.com/tstranex/u2f"
 )
 
 const (
@@ -47,7 +48,7 @@ const (
 	tplTwofa          base.TplName = "user/auth/twofa"
 	tplTwofaScratch   base.TplName = "user/auth/twofa_scratch"
 	tplLinkAccount    base.TplName = "user/auth/link_account"
-	tplU2F            base.TplName = "user/auth/u2f"
+	tplWebAuthn       base.TplName = "user/auth/webauthn"
 )
 
 // AutoSignIn reads cookie and try to auto-login.
@@ -200,13 +201,13 @@ func SignInPost(ctx *context.Context) {
 	}
 	// If this user is enrolled in 2FA, we can't sign the user in just yet.
 	// Instead, redirect them to the 2FA authentication page.
-	_, err = models.GetTwoFactorByUID(u.ID)
+	hasTOTP, hasWebAuthn, err := hasSecondFactor(u.ID)
 	if err != nil {
-		if models.IsErrTwoFactorNotEnrolled(err) {
-			handleSignIn(ctx, u, form.Remember)
-		} else {
-			ctx.ServerError("UserSignIn", err)
-		}
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if !hasTOTP && !hasWebAuthn {
+		handleSignIn(ctx, u, form.Remember)
 		return
 	}
 
@@ -224,15 +225,25 @@ func SignInPost(ctx *context.Context) {
 		return
 	}
 
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+	if hasWebAuthn {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
 		return
 	}
 
 	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
 }
 
+// hasSecondFactor returns whether the user is enrolled in TOTP and whether the user has registered security keys
+func hasSecondFactor(uid int64) (hasTOTP, hasWebAuthn bool, err error) {
+	if _, err = models.GetTwoFactorByUID(uid); err == nil {
+		hasTOTP = true
+	} else if !models.IsErrTwoFactorNotEnrolled(err) {
+		return false, false, err
+	}
+	hasWebAuthn, err = models.HasWebAuthnRegistrationsByUID(uid)
+	return hasTOTP, hasWebAuthn, err
+}
+
 // TwoFactor shows the user a two-factor authentication page.
 func TwoFactor(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("twofa")
@@ -378,116 +389,210 @@ func TwoFactorScratchPost(ctx *context.Context) {
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, forms.TwoFactorScratchAuthForm{})
 }
 
-// U2F shows the U2F login page
-func U2F(ctx *context.Context) {
+// WebAuthn shows the WebAuthn login page
+func WebAuthn(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("twofa")
-	ctx.Data["RequireU2F"] = true
+
 	// Check auto-login.
 	if checkAutoLogin(ctx) {
 		return
 	}
 
 	// Ensure user is in a 2FA session.
-	if ctx.Session.Get("twofaUid") == nil {
-		ctx.ServerError("UserSignIn", errors.New("not in U2F session"))
+	idSess := ctx.Session.Get("twofaUid")
+	if idSess == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
 		return
 	}
 
-	ctx.HTML(http.StatusOK, tplU2F)
+	hasTOTP, _, err := hasSecondFactor(idSess.(int64))
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	ctx.Data["HasTwoFactor"] = hasTOTP
+
+	ctx.HTML(http.StatusOK, tplWebAuthn)
 }
 
-// U2FChallenge submits a sign challenge to the browser
-func U2FChallenge(ctx *context.Context) {
-	// Ensure user is in a U2F session.
+// WebAuthnLoginAssertion submits a WebAuthn challenge to the browser
+func WebAuthnLoginAssertion(ctx *context.Context) {
+	// Ensure user is in a WebAuthn session.
 	idSess := ctx.Session.Get("twofaUid")
 	if idSess == nil {
-		ctx.ServerError("UserSignIn", errors.New("not in U2F session"))
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
 		return
 	}
 	id := idSess.(int64)
-	regs, err := models.GetU2FRegistrationsByUID(id)
+	creds, err := models.GetWebAuthnCredentialsByUID(id)
 	if err != nil {
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
-	if len(regs) == 0 {
+	if len(creds) == 0 {
 		ctx.ServerError("UserSignIn", errors.New("no device registered"))
 		return
 	}
-	challenge, err := u2f.NewChallenge(setting.U2F.AppID, setting.U2F.TrustedFacets)
+
+	appID := ""
+	if creds.HasLegacyU2F() {
+		appID = setting.U2F.AppID
+	}
+	assertion, sessionData, err := webauthn.BeginLogin(id, creds.ToCredentialDescriptors(), appID)
 	if err != nil {
-		ctx.ServerError("u2f.NewChallenge", err)
+		ctx.ServerError("webauthn.BeginLogin", err)
 		return
 	}
-	if err := ctx.Session.Set("u2fChallenge", challenge); err != nil {
-		ctx.ServerError("UserSignIn: unable to set u2fChallenge in session", err)
+	if err := ctx.Session.Set("webauthnAssertion", sessionData); err != nil {
+		ctx.ServerError("UserSignIn: unable to set webauthnAssertion in session", err)
 		return
 	}
 	if err := ctx.Session.Release(); err != nil {
 		ctx.ServerError("UserSignIn: unable to store session", err)
+		return
 	}
 
-	ctx.JSON(http.StatusOK, challenge.SignRequest(regs.ToRegistrations()))
+	ctx.JSON(http.StatusOK, assertion)
 }
 
-// U2FSign authenticates the user by signResp
-func U2FSign(ctx *context.Context) {
-	signResp := web.GetForm(ctx).(*u2f.SignResponse)
-	challSess := ctx.Session.Get("u2fChallenge")
+// WebAuthnLoginAssertionPost validates the signature and logs the user in
+func WebAuthnLoginAssertionPost(ctx *context.Context) {
+	resp := web.GetForm(ctx).(*webauthn.CredentialAssertionResponse)
+	sessData := ctx.Session.Get("webauthnAssertion")
 	idSess := ctx.Session.Get("twofaUid")
-	if challSess == nil || idSess == nil {
-		ctx.ServerError("UserSignIn", errors.New("not in U2F session"))
+	if sessData == nil || idSess == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
 		return
 	}
-	challenge := challSess.(*u2f.Challenge)
+	// A challenge may only be used once
+	_ = ctx.Session.Delete("webauthnAssertion")
 	id := idSess.(int64)
-	regs, err := models.GetU2FRegistrationsByUID(id)
+
+	dbCred, err := models.GetWebAuthnCredentialByCredID(resp.RawID)
+	if err != nil || dbCred.UserID != id {
+		if err != nil && !models.IsErrWebAuthnCredentialNotExist(err) {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+		ctx.Error(http.StatusUnauthorized)
+		return
+	}
+	if !verifyWebAuthnAssertion(ctx, sessData.(*webauthn.SessionData), resp, dbCred) {
+		return
+	}
+
+	user, err := models.GetUserByID(id)
 	if err != nil {
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
-	for _, reg := range regs {
-		r, err := reg.Parse()
+	remember := ctx.Session.Get("twofaRemember").(bool)
+
+	if ctx.Session.Get("linkAccount") != nil {
+		gothUser := ctx.Session.Get("linkAccountGothUser")
+		if gothUser == nil {
+			ctx.ServerError("UserSignIn", errors.New("not in LinkAccount session"))
+			return
+		}
+
+		err = externalaccount.LinkAccountToUser(user, gothUser.(goth.User))
 		if err != nil {
-			log.Fatal("parsing u2f registration: %v", err)
-			continue
+			ctx.ServerError("UserSignIn", err)
+			return
 		}
-		newCounter, authErr := r.Authenticate(*signResp, *challenge, reg.Counter)
-		if authErr == nil {
-			reg.Counter = newCounter
-			user, err := models.GetUserByID(id)
-			if err != nil {
-				ctx.ServerError("UserSignIn", err)
-				return
-			}
-			remember := ctx.Session.Get("twofaRemember").(bool)
-			if err := reg.UpdateCounter(); err != nil {
-				ctx.ServerError("UserSignIn", err)
-				return
-			}
+	}
+	redirect := handleSignInFull(ctx, user, remember, false)
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+	ctx.PlainText(http.StatusOK, []byte(redirect))
+}
 
-			if ctx.Session.Get("linkAccount") != nil {
-				gothUser := ctx.Session.Get("linkAccountGothUser")
-				if gothUser == nil {
-					ctx.ServerError("UserSignIn", errors.New("not in LinkAccount session"))
-					return
-				}
+// WebAuthnPasswordlessAssertion submits a WebAuthn challenge for a discoverable credential to the browser
+func WebAuthnPasswordlessAssertion(ctx *context.Context) {
+	assertion, sessionData, err := webauthn.BeginLogin(0, nil, "")
+	if err != nil {
+		ctx.ServerError("webauthn.BeginLogin", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnPasswordlessAssertion", sessionData); err != nil {
+		ctx.ServerError("UserSignIn: unable to set webauthnPasswordlessAssertion in session", err)
+		return
+	}
+	if err := ctx.Session.Release(); err != nil {
+		ctx.ServerError("UserSignIn: unable to store session", err)
+		return
+	}
 
-				err = externalaccount.LinkAccountToUser(user, gothUser.(goth.User))
-				if err != nil {
-					ctx.ServerError("UserSignIn", err)
-					return
-				}
-			}
-			redirect := handleSignInFull(ctx, user, remember, false)
-			if redirect == "" {
-				redirect = setting.AppSubURL + "/"
-			}
-			ctx.PlainText(200, []byte(redirect))
+	ctx.JSON(http.StatusOK, assertion)
+}
+
+// WebAuthnPasswordlessAssertionPost signs the user in with a discoverable credential.
+// The authenticator verified the user, so no further factor is required.
+func WebAuthnPasswordlessAssertionPost(ctx *context.Context) {
+	resp := web.GetForm(ctx).(*webauthn.CredentialAssertionResponse)
+	sessData := ctx.Session.Get("webauthnPasswordlessAssertion")
+	if sessData == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
+		return
+	}
+	// A challenge may only be used once
+	_ = ctx.Session.Delete("webauthnPasswordlessAssertion")
+
+	dbCred, err := models.GetWebAuthnCredentialByCredID(resp.RawID)
+	if err != nil {
+		if models.IsErrWebAuthnCredentialNotExist(err) {
+			ctx.Error(http.StatusUnauthorized)
 			return
 		}
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if len(resp.Response.UserHandle) > 0 && string(resp.Response.UserHandle) != strconv.FormatInt(dbCred.UserID, 10) {
+		ctx.Error(http.StatusUnauthorized)
+		return
+	}
+	if !verifyWebAuthnAssertion(ctx, sessData.(*webauthn.SessionData), resp, dbCred) {
+		return
+	}
+
+	user, err := models.GetUserByID(dbCred.UserID)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if !user.IsActive || user.ProhibitLogin {
+		log.Info("Failed passwordless authentication attempt for %s from %s: account is inactive or prohibited", user.Name, ctx.RemoteAddr())
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	redirect := handleSignInFull(ctx, user, false, false)
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+	ctx.PlainText(http.StatusOK, []byte(redirect))
+}
+
+// verifyWebAuthnAssertion verifies the assertion and stores the new sign count of the credential
+func verifyWebAuthnAssertion(ctx *context.Context, sessData *webauthn.SessionData, resp *webauthn.CredentialAssertionResponse, dbCred *models.WebAuthnCredential) bool {
+	cred := dbCred.ToCredential()
+	if err := webauthn.FinishLogin(sessData, resp, cred); err != nil {
+		if webauthn.IsErrInvalidResponse(err) {
+			log.Info("Failed WebAuthn authentication attempt for user %d from %s: %v", dbCred.UserID, ctx.RemoteAddr(), err)
+			ctx.Error(http.StatusUnauthorized)
+			return false
+		}
+		ctx.ServerError("UserSignIn", err)
+		return false
+	}
+
+	dbCred.SignCount = cred.SignCount
+	if err := dbCred.UpdateSignCount(); err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return false
 	}
-	ctx.Error(http.StatusUnauthorized)
+	return true
 }
 
 // This handles the final part of the sign-in process of the user.
@@ -509,7 +614,7 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 	_ = ctx.Session.Delete("openid_determined_username")
 	_ = ctx.Session.Delete("twofaUid")
 	_ = ctx.Session.Delete("twofaRemember")
-	_ = ctx.Session.Delete("u2fChallenge")
+	_ = ctx.Session.Delete("webauthnAssertion")
 	_ = ctx.Session.Delete("linkAccount")
 	if err := ctx.Session.Set("uid", u.ID); err != nil {
 		log.Error("Error setting uid %d in session: %v", u.ID, err)
@@ -632,13 +737,12 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 
 	// If this user is enrolled in 2FA, we can't sign the user in just yet.
 	// Instead, redirect them to the 2FA authentication page.
-	_, err = models.GetTwoFactorByUID(u.ID)
+	hasTOTP, hasWebAuthn, err := hasSecondFactor(u.ID)
 	if err != nil {
-		if !models.IsErrTwoFactorNotEnrolled(err) {
-			ctx.ServerError("UserSignIn", err)
-			return
-		}
-
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if !hasTOTP && !hasWebAuthn {
 		if err := ctx.Session.Set("uid", u.ID); err != nil {
 			log.Error("Error setting uid in session: %v", err)
 		}
@@ -685,10 +789,9 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 		log.Error("Error storing session: %v", err)
 	}
 
-	// If U2F is enrolled -> Redirect to U2F instead
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+	// If WebAuthn is enrolled -> Redirect to WebAuthn instead
+	if hasWebAuthn {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
 		return
 	}
 
@@ -838,13 +941,12 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 
 	// If this user is enrolled in 2FA, we can't sign the user in just yet.
 	// Instead, redirect them to the 2FA authentication page.
-	_, err = models.GetTwoFactorByUID(u.ID)
+	hasTOTP, hasWebAuthn, err := hasSecondFactor(u.ID)
 	if err != nil {
-		if !models.IsErrTwoFactorNotEnrolled(err) {
-			ctx.ServerError("UserLinkAccount", err)
-			return
-		}
-
+		ctx.ServerError("UserLinkAccount", err)
+		return
+	}
+	if !hasTOTP && !hasWebAuthn {
 		err = externalaccount.LinkAccountToUser(u, gothUser.(goth.User))
 		if err != nil {
 			ctx.ServerError("UserLinkAccount", err)
@@ -869,10 +971,9 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 		log.Error("Error storing session: %v", err)
 	}
 
-	// If U2F is enrolled -> Redirect to U2F instead
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+	// If WebAuthn is enrolled -> Redirect to WebAuthn instead
+	if hasWebAuthn {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
 		return
 	}
 
@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/search"
+)
+
+const tplCodeSearch base.TplName = "user/code"
+
+// CodeSearch renders the code search across the repositories of a user or an organization
+func CodeSearch(ctx *context.Context) {
+	if !setting.Indexer.RepoIndexerEnabled {
+		ctx.NotFound("RepoIndexerEnabled", nil)
+		return
+	}
+
+	owner := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if owner.IsOrganization() && !models.HasOrgVisible(owner, ctx.User) {
+		ctx.NotFound("HasOrgVisible", nil)
+		return
+	}
+
+	language := strings.TrimSpace(ctx.Query("l"))
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	queryType := strings.TrimSpace(ctx.Query("t"))
+
+	result, err := search.Code(&search.CodeOptions{
+		Doer:     ctx.User,
+		OwnerID:  owner.ID,
+		Query:    keyword,
+		Language: language,
+		IsMatch:  queryType == "match",
+		Page:     page,
+		PageSize: setting.UI.RepoSearchPagingNum,
+	})
+	if code_indexer.IsErrQueryTooBroad(err) {
+		ctx.Flash.Error(ctx.Tr("explore.code_search_too_broad", err.(code_indexer.ErrQueryTooBroad).Limit), true)
+		result = &search.CodeResult{Language: language}
+	} else if err != nil {
+		ctx.ServerError("SearchResults", err)
+		return
+	}
+
+	ctx.Data["Title"] = owner.DisplayName()
+	ctx.Data["ContextUser"] = owner
+	ctx.Data["CodeSearchLink"] = owner.HomeLink() + "/-/code"
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["Language"] = result.Language
+	ctx.Data["queryType"] = queryType
+	ctx.Data["RepoMaps"] = result.Repos
+	ctx.Data["SearchResults"] = result.Results
+	ctx.Data["SearchResultLanguages"] = result.Languages
+	ctx.Data["RequireHighlightJS"] = true
+	ctx.Data["PageIsViewCode"] = true
+
+	pager := context.NewPagination(result.Total, setting.UI.RepoSearchPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "l", "Language")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplCodeSearch)
+}
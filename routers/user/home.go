@@ -24,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	issue_service "code.gitea.io/gitea/services/issue"
 	pull_service "code.gitea.io/gitea/services/pull"
+	"code.gitea.io/gitea/services/search"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/keybase/go-crypto/openpgp"
@@ -449,27 +450,35 @@ func buildIssueOverview(ctx *context.Context, unitType models.UnitType) {
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	ctx.Data["Keyword"] = keyword
 
+	// Educated guess: Do or don't show closed issues.
+	isShowClosed := ctx.Query("state") == "closed"
+	opts.IsClosed = util.OptionalBoolOf(isShowClosed)
+
+	// Split the qualifiers off the keyword and narrow down opts by them.
+	filter, err := search.ParseIssueQuery(ctx.User, userRepoIDs, keyword)
+	if err != nil {
+		ctx.ServerError("ParseIssueQuery", err)
+		return
+	}
+	forceEmpty := !filter.Apply(opts)
+	isShowClosed = opts.IsClosed.IsTrue()
+
 	// Execute keyword search for issues.
 	// USING NON-FINAL STATE OF opts FOR A QUERY.
-	issueIDsFromSearch, err := issueIDsFromSearch(ctxUser, keyword, opts)
+	issueIDsFromSearch, matchedComments, err := issueIDsFromSearch(ctxUser, filter.Keyword, opts)
 	if err != nil {
 		ctx.ServerError("issueIDsFromSearch", err)
 		return
 	}
+	ctx.Data["MatchedComments"] = matchedComments
 
 	// Ensure no issues are returned if a keyword was provided that didn't match any issues.
-	var forceEmpty bool
-
 	if len(issueIDsFromSearch) > 0 {
 		opts.IssueIDs = issueIDsFromSearch
-	} else if len(keyword) > 0 {
+	} else if len(filter.Keyword) > 0 {
 		forceEmpty = true
 	}
 
-	// Educated guess: Do or don't show closed issues.
-	isShowClosed := ctx.Query("state") == "closed"
-	opts.IsClosed = util.OptionalBoolOf(isShowClosed)
-
 	// Filter repos and count issues in them. Count will be used later.
 	// USING NON-FINAL STATE OF opts FOR A QUERY.
 	var issueCountByRepo map[int64]int64
@@ -596,6 +605,12 @@ func buildIssueOverview(ctx *context.Context, unitType models.UnitType) {
 			IssueIDs:    issueIDsFromSearch,
 			IsArchived:  util.OptionalBoolFalse,
 			LabelIDs:    opts.LabelIDs,
+
+			PosterID:           filter.PosterID,
+			AssigneeID:         filter.AssigneeID,
+			MentionedID:        filter.MentionedID,
+			MilestoneIDs:       opts.MilestoneIDs,
+			RequiredLabelNames: opts.RequiredLabelNames,
 		}
 		if len(repoIDs) > 0 {
 			statsOpts.RepoIDs = repoIDs
@@ -622,6 +637,12 @@ func buildIssueOverview(ctx *context.Context, unitType models.UnitType) {
 			IssueIDs:    issueIDsFromSearch,
 			IsArchived:  util.OptionalBoolFalse,
 			LabelIDs:    opts.LabelIDs,
+
+			PosterID:           filter.PosterID,
+			AssigneeID:         filter.AssigneeID,
+			MentionedID:        filter.MentionedID,
+			MilestoneIDs:       opts.MilestoneIDs,
+			RequiredLabelNames: opts.RequiredLabelNames,
 		}
 		if ctxUser.IsOrganization() {
 			allIssueStatsOpts.RepoIDs = userRepoIDs
@@ -686,6 +707,12 @@ func buildIssueOverview(ctx *context.Context, unitType models.UnitType) {
 	ctx.Data["IsShowClosed"] = isShowClosed
 	ctx.Data["SelectLabels"] = selectedLabels
 
+	ctx.Data["SavedSearches"], err = models.GetSavedSearchesByUserID(ctx.User.ID, isPullList)
+	if err != nil {
+		ctx.ServerError("GetSavedSearchesByUserID", err)
+		return
+	}
+
 	if isShowClosed {
 		ctx.Data["State"] = "closed"
 	} else {
@@ -788,21 +815,24 @@ func getActiveTeamOrOrgRepoIds(ctxUser *models.User, team *models.Team, unitType
 	return orgRepoIDs, nil
 }
 
-func issueIDsFromSearch(ctxUser *models.User, keyword string, opts *models.IssuesOptions) ([]int64, error) {
+// issueIDsFromSearch returns the ids of the issues matching the keyword, and the anchors of the
+// comments which matched by issue id
+func issueIDsFromSearch(ctxUser *models.User, keyword string, opts *models.IssuesOptions) ([]int64, map[int64]string, error) {
 	if len(keyword) == 0 {
-		return []int64{}, nil
+		return []int64{}, nil, nil
 	}
 
 	searchRepoIDs, err := models.GetRepoIDsForIssuesOptions(opts, ctxUser)
 	if err != nil {
-		return nil, fmt.Errorf("GetRepoIDsForIssuesOptions: %v", err)
+		return nil, nil, fmt.Errorf("GetRepoIDsForIssuesOptions: %v", err)
 	}
-	issueIDsFromSearch, err := issue_indexer.SearchIssuesByKeyword(searchRepoIDs, keyword)
+	matches, err := issue_indexer.SearchIssueMatchesByKeyword(searchRepoIDs, keyword)
 	if err != nil {
-		return nil, fmt.Errorf("SearchIssuesByKeyword: %v", err)
+		return nil, nil, fmt.Errorf("SearchIssueMatchesByKeyword: %v", err)
 	}
 
-	return issueIDsFromSearch, nil
+	issueIDsFromSearch, matchedComments := search.SplitIssueMatches(matches)
+	return issueIDsFromSearch, matchedComments, nil
 }
 
 func repoIDMap(ctxUser *models.User, issueCountByRepo map[int64]int64, unitType models.UnitType) (map[int64]*models.Repository, error) {
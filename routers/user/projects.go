@@ -0,0 +1,512 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplProjects     base.TplName = "user/projects/list"
+	tplProjectsNew  base.TplName = "user/projects/new"
+	tplProjectsView base.TplName = "user/projects/view"
+)
+
+// ProjectsAssignment loads the user or organization owning the projects of the request
+// and checks whether the signed in user can access them
+func ProjectsAssignment(ctx *context.Context) {
+	if models.UnitTypeProjects.UnitGlobalDisabled() {
+		ctx.NotFound("EnableKanbanBoard", nil)
+		return
+	}
+
+	owner := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	mode, err := models.AccessModeOfOwnerProjects(owner, ctx.User)
+	if err != nil {
+		ctx.ServerError("AccessModeOfOwnerProjects", err)
+		return
+	}
+	if mode < models.AccessModeRead {
+		ctx.NotFound("AccessModeOfOwnerProjects", nil)
+		return
+	}
+
+	ctx.Data["ContextUser"] = owner
+	ctx.Data["ProjectsLink"] = owner.HomeLink() + "/-/projects"
+	ctx.Data["CanWriteProjects"] = mode >= models.AccessModeWrite
+	ctx.Data["IsOwnerProject"] = true
+	ctx.Data["PageIsProjects"] = true
+}
+
+// MustWriteProjects checks if the signed in user can change the projects of the owner
+func MustWriteProjects(ctx *context.Context) {
+	if canWrite, _ := ctx.Data["CanWriteProjects"].(bool); !canWrite {
+		ctx.NotFound("MustWriteProjects", nil)
+	}
+}
+
+func projectsOwner(ctx *context.Context) *models.User {
+	return ctx.Data["ContextUser"].(*models.User)
+}
+
+func projectsLink(ctx *context.Context) string {
+	return ctx.Data["ProjectsLink"].(string)
+}
+
+// getProject returns the project of the owner given by the request, it renders a 404 if there is no such project
+func getProject(ctx *context.Context) *models.Project {
+	p, err := models.GetProjectByOwnerID(projectsOwner(ctx).ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectByOwnerID", err)
+		}
+		return nil
+	}
+	return p
+}
+
+// getProjectBoard returns the board of the project given by the request, it renders a 404 if there is no such board
+func getProjectBoard(ctx *context.Context, p *models.Project) *models.ProjectBoard {
+	board, err := models.GetProjectBoard(ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectBoard", err)
+		}
+		return nil
+	}
+	if board.ProjectID != p.ID {
+		ctx.NotFound("", nil)
+		return nil
+	}
+	return board
+}
+
+// Projects renders the projects of a user or an organization
+func Projects(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.project_board")
+
+	owner := projectsOwner(ctx)
+	sortType := ctx.QueryTrim("sort")
+	isShowClosed := strings.ToLower(ctx.QueryTrim("state")) == "closed"
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	projects, count, err := models.GetProjects(models.ProjectSearchOptions{
+		OwnerID:  owner.ID,
+		Page:     page,
+		IsClosed: util.OptionalBoolOf(isShowClosed),
+		SortType: sortType,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+
+	openCount, err := models.CountProjects(models.ProjectSearchOptions{OwnerID: owner.ID, IsClosed: util.OptionalBoolFalse})
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	closedCount, err := models.CountProjects(models.ProjectSearchOptions{OwnerID: owner.ID, IsClosed: util.OptionalBoolTrue})
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	ctx.Data["OpenCount"] = openCount
+	ctx.Data["ClosedCount"] = closedCount
+
+	for i := range projects {
+		projects[i].RenderedContent = string(markdown.Render([]byte(projects[i].Description), owner.HomeLink(), map[string]string{"mode": "document"}))
+	}
+	ctx.Data["Projects"] = projects
+
+	if isShowClosed {
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["State"] = "open"
+	}
+
+	pager := context.NewPagination(int(count), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	pager.AddParam(ctx, "sort", "SortType")
+	ctx.Data["Page"] = pager
+
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["SortType"] = sortType
+
+	ctx.HTML(http.StatusOK, tplProjects)
+}
+
+// NewProject renders the page to create a project of a user or an organization
+func NewProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// NewProjectPost creates a project of a user or an organization
+func NewProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+
+	if ctx.HasError() {
+		ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	owner := projectsOwner(ctx)
+	projectType := models.ProjectTypeIndividual
+	if owner.IsOrganization() {
+		projectType = models.ProjectTypeOrganization
+	}
+
+	if err := models.NewProject(&models.Project{
+		OwnerID:     owner.ID,
+		Title:       form.Title,
+		Description: form.Content,
+		CreatorID:   ctx.User.ID,
+		BoardType:   form.BoardType,
+		Type:        projectType,
+	}); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(projectsLink(ctx))
+}
+
+// ChangeProjectStatus updates the status of a project between "open" and "close"
+func ChangeProjectStatus(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.ChangeProjectStatus(p, ctx.Params(":action") == "close"); err != nil {
+		ctx.ServerError("ChangeProjectStatus", err)
+		return
+	}
+	ctx.Redirect(projectsLink(ctx) + "?state=" + ctx.Params(":action"))
+}
+
+// DeleteProject deletes a project of a user or an organization
+func DeleteProject(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": projectsLink(ctx),
+	})
+}
+
+// EditProject renders the page to edit a project
+func EditProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProjects"] = true
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["title"] = p.Title
+	ctx.Data["content"] = p.Description
+
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// EditProjectPost response for editing a project
+func EditProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProjects"] = true
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Content
+	if err := models.UpdateProject(p); err != nil {
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(projectsLink(ctx))
+}
+
+// ViewProject renders the boards of a project, issues and pull requests
+// of repositories the signed in user can't read are left out
+func ViewProject(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+
+	if boards[0].ID == 0 {
+		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
+	}
+
+	if _, err = boards.LoadIssues(); err != nil {
+		ctx.ServerError("LoadIssuesOfBoards", err)
+		return
+	}
+
+	perms := make(map[int64]models.Permission)
+	canRead := func(issue *models.Issue) (bool, error) {
+		perm, ok := perms[issue.RepoID]
+		if !ok {
+			if err := issue.LoadRepo(); err != nil {
+				return false, err
+			}
+			if perm, err = models.GetUserRepoPermission(issue.Repo, ctx.User); err != nil {
+				return false, err
+			}
+			perms[issue.RepoID] = perm
+		}
+		return perm.CanReadIssuesOrPulls(issue.IsPull), nil
+	}
+
+	var issueList models.IssueList
+	for _, board := range boards {
+		issues := make([]*models.Issue, 0, len(board.Issues))
+		for _, issue := range board.Issues {
+			visible, err := canRead(issue)
+			if err != nil {
+				ctx.ServerError("GetUserRepoPermission", err)
+				return
+			}
+			if visible {
+				issues = append(issues, issue)
+			}
+		}
+		board.Issues = issues
+		issueList = append(issueList, issues...)
+	}
+	ctx.Data["Issues"] = issueList
+
+	linkedPrsMap := make(map[int64][]*models.Issue)
+	for _, issue := range issueList {
+		var referencedIds []int64
+		for _, comment := range issue.Comments {
+			if comment.RefIssueID != 0 && comment.RefIsPull {
+				referencedIds = append(referencedIds, comment.RefIssueID)
+			}
+		}
+
+		if len(referencedIds) > 0 {
+			linkedPrs, err := models.Issues(&models.IssuesOptions{
+				IssueIDs: referencedIds,
+				IsPull:   util.OptionalBoolTrue,
+			})
+			if err != nil {
+				continue
+			}
+			for _, pr := range linkedPrs {
+				visible, err := canRead(pr)
+				if err != nil {
+					ctx.ServerError("GetUserRepoPermission", err)
+					return
+				}
+				if visible {
+					linkedPrsMap[issue.ID] = append(linkedPrsMap[issue.ID], pr)
+				}
+			}
+		}
+	}
+	ctx.Data["LinkedPRs"] = linkedPrsMap
+
+	p.RenderedContent = string(markdown.Render([]byte(p.Description), projectsOwner(ctx).HomeLink(), map[string]string{"mode": "document"}))
+
+	ctx.Data["Title"] = p.Title
+	ctx.Data["Project"] = p
+	ctx.Data["ProjectLink"] = fmt.Sprintf("%s/%d", projectsLink(ctx), p.ID)
+	ctx.Data["Boards"] = boards
+	ctx.Data["RequiresDraggable"] = true
+
+	ctx.HTML(http.StatusOK, tplProjectsView)
+}
+
+// AddBoardToProjectPost allows a new board to be added to a project
+func AddBoardToProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditProjectBoardForm)
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.NewProjectBoard(&models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     form.Title,
+		CreatorID: ctx.User.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// EditProjectBoard allows a project board's to be updated
+func EditProjectBoard(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditProjectBoardForm)
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != "" {
+		board.Title = form.Title
+	}
+
+	if form.Sorting != 0 {
+		board.Sorting = form.Sorting
+	}
+
+	if err := models.UpdateProjectBoard(board); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// DeleteProjectBoard allows for the deletion of a project board
+func DeleteProjectBoard(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectBoardByID(board.ID); err != nil {
+		ctx.ServerError("DeleteProjectBoardByID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// SetDefaultProjectBoard set default board for uncategorized issues/pulls
+func SetDefaultProjectBoard(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.SetDefaultBoard(p.ID, board.ID); err != nil {
+		ctx.ServerError("SetDefaultBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// MoveIssueAcrossBoards move a card from one board to another in a project
+func MoveIssueAcrossBoards(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	board := &models.ProjectBoard{ProjectID: p.ID}
+	if ctx.ParamsInt64(":boardID") != 0 {
+		board = getProjectBoard(ctx, p)
+		if ctx.Written() {
+			return
+		}
+	}
+
+	issue, err := models.GetIssueByID(ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetIssueByID", err)
+		}
+		return
+	}
+	if issue.ProjectID() != p.ID {
+		ctx.NotFound("", nil)
+		return
+	}
+
+	oldBoardID := issue.ProjectBoardID()
+	if err := models.MoveIssueAcrossProjectBoards(issue, board); err != nil {
+		ctx.ServerError("MoveIssueAcrossProjectBoards", err)
+		return
+	}
+	if oldBoardID != board.ID {
+		notification.NotifyMoveProjectIssue(ctx.User, issue, p, oldBoardID, board)
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
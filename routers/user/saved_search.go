@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// SaveIssueSearch saves a search of the issues or pull requests overview page
+func SaveIssueSearch(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.SaveIssueSearchForm)
+
+	redirectTo := setting.AppSubURL + "/issues"
+	if form.IsPull {
+		redirectTo = setting.AppSubURL + "/pulls"
+	}
+	if len(form.RedirectTo) > 0 {
+		redirectTo = form.RedirectTo
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.RedirectToFirst(redirectTo)
+		return
+	}
+
+	if err := models.CreateSavedSearch(&models.SavedSearch{
+		UserID: ctx.User.ID,
+		Name:   form.Name,
+		IsPull: form.IsPull,
+		Query:  form.Query,
+	}); err != nil {
+		ctx.ServerError("CreateSavedSearch", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("home.issues.saved_search_success", form.Name))
+	ctx.RedirectToFirst(redirectTo + "?q=" + url.QueryEscape(form.Query))
+}
+
+// DeleteIssueSearch deletes a saved search of the issues or pull requests overview page
+func DeleteIssueSearch(ctx *context.Context) {
+	if err := models.DeleteSavedSearch(ctx.User.ID, ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrSavedSearchNotExist(err) {
+			ctx.NotFound("DeleteSavedSearch", err)
+		} else {
+			ctx.ServerError("DeleteSavedSearch", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("home.issues.saved_search_deletion_success"))
+	ctx.RedirectToFirst(ctx.Query("redirect_to"), setting.AppSubURL+"/issues")
+}
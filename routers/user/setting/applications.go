@@ -7,11 +7,14 @@ package setting
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
 )
@@ -43,9 +46,45 @@ func ApplicationsPost(ctx *context.Context) {
 		return
 	}
 
+	scope, err := models.ParseAccessTokenScope(form.Scopes)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("settings.token_scope_invalid", err.(models.ErrAccessTokenScopeInvalid).Scope))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		return
+	}
+
+	var repoNames []string
+	for _, name := range strings.Split(form.Repositories, ",") {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			repoNames = append(repoNames, name)
+		}
+	}
+	repoIDs, err := models.GetAccessTokenRepoIDsByNames(ctx.User, repoNames)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("settings.token_repositories_invalid"))
+			ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+			return
+		}
+		ctx.ServerError("GetAccessTokenRepoIDsByNames", err)
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:     ctx.User.ID,
+		Name:    form.Name,
+		Scope:   scope,
+		RepoIDs: repoIDs,
+	}
+
+	if len(form.ExpiresAt) > 0 {
+		expires, err := time.ParseInLocation("2006-01-02", form.ExpiresAt, setting.DefaultUILocation)
+		if err != nil || !expires.After(time.Now()) {
+			ctx.Flash.Error(ctx.Tr("settings.token_expires_invalid"))
+			ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+			return
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(expires.Unix())
 	}
 
 	exist, err := models.AccessTokenByNameExists(t)
@@ -90,6 +129,7 @@ func loadApplicationsData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["AccessTokenScopes"] = models.AccessTokenScopes
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = models.GetOAuth2ApplicationsByUserID(ctx.User.ID)
@@ -0,0 +1,126 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// WebAuthnRegister initializes the webauthn registration procedure
+func WebAuthnRegister(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.WebAuthnRegistrationForm)
+	if form.Name == "" {
+		ctx.Error(http.StatusConflict)
+		return
+	}
+
+	exists, err := models.ExistsWebAuthnCredentialsForUIDAndName(ctx.User.ID, form.Name)
+	if err != nil {
+		ctx.ServerError("ExistsWebAuthnCredentialsForUIDAndName", err)
+		return
+	}
+	if exists {
+		ctx.Error(http.StatusConflict, "Name already taken")
+		return
+	}
+
+	creds, err := models.GetWebAuthnCredentialsByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+		return
+	}
+
+	creation, sessionData, err := webauthn.BeginRegistration(webauthn.UserEntity{
+		ID:          []byte(strconv.FormatInt(ctx.User.ID, 10)),
+		Name:        ctx.User.Name,
+		DisplayName: ctx.User.DisplayName(),
+	}, creds.ToCredentialDescriptors(), form.Passwordless)
+	if err != nil {
+		ctx.ServerError("BeginRegistration", err)
+		return
+	}
+
+	if err := ctx.Session.Set("webauthnRegistration", sessionData); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnRegistration", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnName", form.Name); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnName", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnPasswordless", form.Passwordless); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnPasswordless", err)
+		return
+	}
+	// Here we're just going to try to release the session early
+	if err := ctx.Session.Release(); err != nil {
+		// we'll tolerate errors here as they *should* get saved elsewhere
+		log.Error("Unable to save changes to the session: %v", err)
+	}
+
+	ctx.JSON(http.StatusOK, creation)
+}
+
+// WebAuthnRegisterPost receives the response of the security key
+func WebAuthnRegisterPost(ctx *context.Context) {
+	response := web.GetForm(ctx).(*webauthn.CredentialCreationResponse)
+	sessSession := ctx.Session.Get("webauthnRegistration")
+	sessName := ctx.Session.Get("webauthnName")
+	sessPasswordless := ctx.Session.Get("webauthnPasswordless")
+	if sessSession == nil || sessName == nil || sessPasswordless == nil {
+		ctx.ServerError("WebAuthnRegisterPost", errors.New("not in WebAuthn session"))
+		return
+	}
+	_ = ctx.Session.Delete("webauthnRegistration")
+	_ = ctx.Session.Delete("webauthnName")
+	_ = ctx.Session.Delete("webauthnPasswordless")
+
+	cred, err := webauthn.FinishRegistration(sessSession.(*webauthn.SessionData), response)
+	if err != nil {
+		if webauthn.IsErrInvalidResponse(err) {
+			log.Info("Invalid WebAuthn registration of %s from %s: %v", ctx.User.Name, ctx.RemoteAddr(), err)
+			ctx.Error(http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.ServerError("FinishRegistration", err)
+		return
+	}
+
+	if _, err := models.GetWebAuthnCredentialByCredID(cred.ID); err == nil {
+		ctx.Error(http.StatusConflict, "Credential already registered")
+		return
+	} else if !models.IsErrWebAuthnCredentialNotExist(err) {
+		ctx.ServerError("GetWebAuthnCredentialByCredID", err)
+		return
+	}
+
+	if _, err := models.CreateCredential(ctx.User.ID, sessName.(string), cred, sessPasswordless.(bool)); err != nil {
+		ctx.ServerError("CreateCredential", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, cred.Descriptor())
+}
+
+// WebAuthnDelete deletes an security key by id
+func WebAuthnDelete(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.WebAuthnDeleteForm)
+	if _, err := models.DeleteCredential(form.ID, ctx.User.ID); err != nil {
+		ctx.ServerError("DeleteCredential", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 )
 
 // TaskStatus returns task's status
@@ -21,8 +22,17 @@ func TaskStatus(ctx *context.Context) {
 		return
 	}
 
+	var message string
+	translatableMessage, err := task.TranslatableMessage()
+	if err != nil {
+		log.Error("Task[%d] has an invalid message: %v", task.ID, err)
+	} else if translatableMessage != nil {
+		message = ctx.Tr(translatableMessage.Format, translatableMessage.Args...)
+	}
+
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"status":    task.Status,
+		"message":   message,
 		"err":       task.Errors,
 		"repo-id":   task.RepoID,
 		"repo-name": opts.RepoName,
@@ -6,6 +6,7 @@
 package forms
 
 import (
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
@@ -82,6 +83,7 @@ type MigrateRepoForm struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	Incremental    bool   `json:"incremental"`
 }
 
 // Validate validates the fields
@@ -90,6 +92,21 @@ func (f *MigrateRepoForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// MigrateDumpForm form for restoring a repository from an uploaded dump archive
+type MigrateDumpForm struct {
+	Dump        *multipart.FileHeader
+	UID         int64  `binding:"Required"`
+	RepoName    string `binding:"Required;AlphaDashDot;MaxSize(100)"`
+	Private     bool
+	Description string `binding:"MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *MigrateDumpForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ParseRemoteAddr checks if given remote address is valid,
 // and returns composed URL with needed username and password.
 func ParseRemoteAddr(remoteAddr, authUsername, authPassword string) (string, error) {
@@ -143,6 +160,7 @@ type RepoSettingForm struct {
 	PullsAllowRebase                      bool
 	PullsAllowRebaseMerge                 bool
 	PullsAllowSquash                      bool
+	PullsAllowFastForwardOnly             bool
 	PullsAllowManualMerge                 bool
 	PullsDefaultMergeStyle                string
 	EnableAutodetectManualMerge           bool
@@ -154,6 +172,9 @@ type RepoSettingForm struct {
 	// Signing Settings
 	TrustModel string
 
+	// Code indexer settings
+	CodeIndexerBranches string
+
 	// Admin settings
 	EnableHealthCheck bool
 }
@@ -183,6 +204,7 @@ type ProtectBranchForm struct {
 	MergeWhitelistTeams           string
 	EnableStatusCheck             bool
 	StatusCheckContexts           []string
+	EnableMergeQueue              bool
 	RequiredApprovals             int64
 	EnableApprovalsWhitelist      bool
 	ApprovalsWhitelistUsers       string
@@ -229,8 +251,15 @@ type WebhookForm struct {
 	PullRequestReview    bool
 	PullRequestSync      bool
 	Repository           bool
+	Wiki                 bool
+	Status               bool
+	Milestone            bool
+	ProjectCard          bool
+	BranchProtection     bool
 	Active               bool
 	BranchFilter         string `binding:"GlobPattern"`
+	MaxRetries           int    `binding:"Range(0,10)"`
+	DisableAfterRetries  bool
 }
 
 // PushOnly if the hook will be triggered when push
@@ -250,10 +279,15 @@ func (f WebhookForm) ChooseEvents() bool {
 
 // NewWebhookForm form for creating web hook
 type NewWebhookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	HTTPMethod  string `binding:"Required;In(POST,GET)"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL          string `binding:"Required;ValidUrl"`
+	HTTPMethod          string `binding:"Required;In(POST,GET)"`
+	ContentType         int    `binding:"Required"`
+	Secret              string
+	SignatureAlgorithm  string `binding:"In(,sha1,sha256,sha512)"`
+	AuthorizationHeader string
+	ClientCertificate   string
+	ClientKey           string
+	CACertificates      string
 	WebhookForm
 }
 
@@ -265,9 +299,14 @@ func (f *NewWebhookForm) Validate(req *http.Request, errs binding.Errors) bindin
 
 // NewGogshookForm form for creating gogs hook
 type NewGogshookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL          string `binding:"Required;ValidUrl"`
+	ContentType         int    `binding:"Required"`
+	Secret              string
+	SignatureAlgorithm  string `binding:"In(,sha1,sha256,sha512)"`
+	AuthorizationHeader string
+	ClientCertificate   string
+	ClientKey           string
+	CACertificates      string
 	WebhookForm
 }
 
@@ -376,6 +415,28 @@ func (f *NewFeishuHookForm) Validate(req *http.Request, errs binding.Errors) bin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewCustomHookForm form for creating custom hook
+type NewCustomHookForm struct {
+	PayloadURL          string `binding:"Required;ValidUrl"`
+	HTTPMethod          string `binding:"Required;In(POST,PUT)"`
+	ContentType         string `binding:"Required"`
+	Headers             string
+	Template            string `binding:"Required"`
+	Secret              string
+	SignatureAlgorithm  string `binding:"In(,sha1,sha256,sha512)"`
+	AuthorizationHeader string
+	ClientCertificate   string
+	ClientKey           string
+	CACertificates      string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewCustomHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -467,15 +528,6 @@ type CreateProjectForm struct {
 	BoardType models.ProjectBoardType
 }
 
-// UserCreateProjectForm is a from for creating an individual or organization
-// form.
-type UserCreateProjectForm struct {
-	Title     string `binding:"Required;MaxSize(100)"`
-	Content   string
-	BoardType models.ProjectBoardType
-	UID       int64 `binding:"Required"`
-}
-
 // EditProjectBoardForm is a form for editing a project board
 type EditProjectBoardForm struct {
 	Title   string `binding:"Required;MaxSize(100)"`
@@ -545,12 +597,14 @@ func (f *InitializeLabelsForm) Validate(req *http.Request, errs binding.Errors)
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash,manually-merged
-	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,manually-merged)"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged
+	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged)"`
 	MergeTitleField   string
 	MergeMessageField string
 	MergeCommitID     string // only used for manually-merged
 	ForceMerge        *bool  `json:"force_merge,omitempty"`
+	// schedule the merge to happen once all required checks succeed instead of merging right away
+	MergeWhenChecksSucceed bool `json:"merge_when_checks_succeed,omitempty"`
 }
 
 // Validate validates the fields
@@ -270,6 +270,20 @@ func (f *AddEmailForm) Validate(req *http.Request, errs binding.Errors) binding.
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// SaveIssueSearchForm form for saving a search of the issues or pull requests overview page
+type SaveIssueSearchForm struct {
+	Name       string `binding:"Required;MaxSize(50)"`
+	Query      string `binding:"Required;MaxSize(1000)"`
+	IsPull     bool
+	RedirectTo string
+}
+
+// Validate validates the fields
+func (f *SaveIssueSearchForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // UpdateThemeForm form for updating a users' theme
 type UpdateThemeForm struct {
 	Theme string `binding:"Required;MaxSize(30)"`
@@ -335,7 +349,10 @@ func (f *AddKeyForm) Validate(req *http.Request, errs binding.Errors) binding.Er
 
 // NewAccessTokenForm form for creating access token
 type NewAccessTokenForm struct {
-	Name string `binding:"Required;MaxSize(255)"`
+	Name         string `binding:"Required;MaxSize(255)"`
+	Scopes       []string
+	Repositories string
+	ExpiresAt    string
 }
 
 // Validate validates the fields
@@ -378,24 +395,25 @@ func (f *TwoFactorScratchAuthForm) Validate(req *http.Request, errs binding.Erro
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
-// U2FRegistrationForm for reserving an U2F name
-type U2FRegistrationForm struct {
-	Name string `binding:"Required"`
+// WebAuthnRegistrationForm for reserving a WebAuthn name
+type WebAuthnRegistrationForm struct {
+	Name         string `binding:"Required"`
+	Passwordless bool
 }
 
 // Validate validates the fields
-func (f *U2FRegistrationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+func (f *WebAuthnRegistrationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
-// U2FDeleteForm for deleting U2F keys
-type U2FDeleteForm struct {
+// WebAuthnDeleteForm for deleting WebAuthn keys
+type WebAuthnDeleteForm struct {
 	ID int64 `binding:"Required"`
 }
 
 // Validate validates the fields
-func (f *U2FDeleteForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+func (f *WebAuthnDeleteForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
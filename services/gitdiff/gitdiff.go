@@ -17,9 +17,9 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/charset"
@@ -78,6 +78,8 @@ type DiffLine struct {
 	Content     string
 	Comments    []*models.Comment
 	SectionInfo *DiffLineSectionInfo
+	// IsMoved is set if the added or deleted line is part of a block moved within the file
+	IsMoved bool
 }
 
 // DiffLineSectionInfo represents diff line section meta data
@@ -176,6 +178,8 @@ type DiffSection struct {
 	FileName string
 	Name     string
 	Lines    []*DiffLine
+	// IgnoreWhitespace is set if whitespace changes are not highlighted within lines
+	IgnoreWhitespace bool
 }
 
 var (
@@ -184,302 +188,6 @@ var (
 	codeTagSuffix     = []byte(`</span>`)
 )
 
-var unfinishedtagRegex = regexp.MustCompile(`<[^>]*$`)
-var trailingSpanRegex = regexp.MustCompile(`<span\s*[[:alpha:]="]*?[>]?$`)
-var entityRegex = regexp.MustCompile(`&[#]*?[0-9[:alpha:]]*$`)
-
-// shouldWriteInline represents combinations where we manually write inline changes
-func shouldWriteInline(diff diffmatchpatch.Diff, lineType DiffLineType) bool {
-	if true &&
-		diff.Type == diffmatchpatch.DiffEqual ||
-		diff.Type == diffmatchpatch.DiffInsert && lineType == DiffLineAdd ||
-		diff.Type == diffmatchpatch.DiffDelete && lineType == DiffLineDel {
-		return true
-	}
-	return false
-}
-
-func fixupBrokenSpans(diffs []diffmatchpatch.Diff) []diffmatchpatch.Diff {
-
-	// Create a new array to store our fixed up blocks
-	fixedup := make([]diffmatchpatch.Diff, 0, len(diffs))
-
-	// semantically label some numbers
-	const insert, delete, equal = 0, 1, 2
-
-	// record the positions of the last type of each block in the fixedup blocks
-	last := []int{-1, -1, -1}
-	operation := []diffmatchpatch.Operation{diffmatchpatch.DiffInsert, diffmatchpatch.DiffDelete, diffmatchpatch.DiffEqual}
-
-	// create a writer for insert and deletes
-	toWrite := []strings.Builder{
-		{},
-		{},
-	}
-
-	// make some flags for insert and delete
-	unfinishedTag := []bool{false, false}
-	unfinishedEnt := []bool{false, false}
-
-	// store stores the provided text in the writer for the typ
-	store := func(text string, typ int) {
-		(&(toWrite[typ])).WriteString(text)
-	}
-
-	// hasStored returns true if there is stored content
-	hasStored := func(typ int) bool {
-		return (&toWrite[typ]).Len() > 0
-	}
-
-	// stored will return that content
-	stored := func(typ int) string {
-		return (&toWrite[typ]).String()
-	}
-
-	// empty will empty the stored content
-	empty := func(typ int) {
-		(&toWrite[typ]).Reset()
-	}
-
-	// pop will remove the stored content appending to a diff block for that typ
-	pop := func(typ int, fixedup []diffmatchpatch.Diff) []diffmatchpatch.Diff {
-		if hasStored(typ) {
-			if last[typ] > last[equal] {
-				fixedup[last[typ]].Text += stored(typ)
-			} else {
-				fixedup = append(fixedup, diffmatchpatch.Diff{
-					Type: operation[typ],
-					Text: stored(typ),
-				})
-			}
-			empty(typ)
-		}
-		return fixedup
-	}
-
-	// Now we walk the provided diffs and check the type of each block in turn
-	for _, diff := range diffs {
-
-		typ := delete // flag for handling insert or delete typs
-		switch diff.Type {
-		case diffmatchpatch.DiffEqual:
-			// First check if there is anything stored
-			if hasStored(insert) || hasStored(delete) {
-				// There are two reasons for storing content:
-				// 1. Unfinished Entity <- Could be more efficient here by not doing this if we're looking for a tag
-				if unfinishedEnt[insert] || unfinishedEnt[delete] {
-					// we look for a ';' to finish an entity
-					idx := strings.IndexRune(diff.Text, ';')
-					if idx >= 0 {
-						// if we find a ';' store the preceding content to both insert and delete
-						store(diff.Text[:idx+1], insert)
-						store(diff.Text[:idx+1], delete)
-
-						// and remove it from this block
-						diff.Text = diff.Text[idx+1:]
-
-						// reset the ent flags
-						unfinishedEnt[insert] = false
-						unfinishedEnt[delete] = false
-					} else {
-						// otherwise store it all on insert and delete
-						store(diff.Text, insert)
-						store(diff.Text, delete)
-						// and empty this block
-						diff.Text = ""
-					}
-				}
-				// 2. Unfinished Tag
-				if unfinishedTag[insert] || unfinishedTag[delete] {
-					// we look for a '>' to finish a tag
-					idx := strings.IndexRune(diff.Text, '>')
-					if idx >= 0 {
-						store(diff.Text[:idx+1], insert)
-						store(diff.Text[:idx+1], delete)
-						diff.Text = diff.Text[idx+1:]
-						unfinishedTag[insert] = false
-						unfinishedTag[delete] = false
-					} else {
-						store(diff.Text, insert)
-						store(diff.Text, delete)
-						diff.Text = ""
-					}
-				}
-
-				// If we've completed the required tag/entities
-				if !(unfinishedTag[insert] || unfinishedTag[delete] || unfinishedEnt[insert] || unfinishedEnt[delete]) {
-					// pop off the stack
-					fixedup = pop(insert, fixedup)
-					fixedup = pop(delete, fixedup)
-				}
-
-				// If that has left this diff block empty then shortcut
-				if len(diff.Text) == 0 {
-					continue
-				}
-			}
-
-			// check if this block ends in an unfinished tag?
-			idx := unfinishedtagRegex.FindStringIndex(diff.Text)
-			if idx != nil {
-				unfinishedTag[insert] = true
-				unfinishedTag[delete] = true
-			} else {
-				// otherwise does it end in an unfinished entity?
-				idx = entityRegex.FindStringIndex(diff.Text)
-				if idx != nil {
-					unfinishedEnt[insert] = true
-					unfinishedEnt[delete] = true
-				}
-			}
-
-			// If there is an unfinished component
-			if idx != nil {
-				// Store the fragment
-				store(diff.Text[idx[0]:], insert)
-				store(diff.Text[idx[0]:], delete)
-				// and remove it from this block
-				diff.Text = diff.Text[:idx[0]]
-			}
-
-			// If that hasn't left the block empty
-			if len(diff.Text) > 0 {
-				// store the position of the last equal block and store it in our diffs
-				last[equal] = len(fixedup)
-				fixedup = append(fixedup, diff)
-			}
-			continue
-		case diffmatchpatch.DiffInsert:
-			typ = insert
-			fallthrough
-		case diffmatchpatch.DiffDelete:
-			// First check if there is anything stored for this type
-			if hasStored(typ) {
-				// if there is prepend it to this block, empty the storage and reset our flags
-				diff.Text = stored(typ) + diff.Text
-				empty(typ)
-				unfinishedEnt[typ] = false
-				unfinishedTag[typ] = false
-			}
-
-			// check if this block ends in an unfinished tag
-			idx := unfinishedtagRegex.FindStringIndex(diff.Text)
-			if idx != nil {
-				unfinishedTag[typ] = true
-			} else {
-				// otherwise does it end in an unfinished entity
-				idx = entityRegex.FindStringIndex(diff.Text)
-				if idx != nil {
-					unfinishedEnt[typ] = true
-				}
-			}
-
-			// If there is an unfinished component
-			if idx != nil {
-				// Store the fragment
-				store(diff.Text[idx[0]:], typ)
-				// and remove it from this block
-				diff.Text = diff.Text[:idx[0]]
-			}
-
-			// If that hasn't left the block empty
-			if len(diff.Text) > 0 {
-				// if the last block of this type was after the last equal block
-				if last[typ] > last[equal] {
-					// store this blocks content on that block
-					fixedup[last[typ]].Text += diff.Text
-				} else {
-					// otherwise store the position of the last block of this type and store the block
-					last[typ] = len(fixedup)
-					fixedup = append(fixedup, diff)
-				}
-			}
-			continue
-		}
-	}
-
-	// pop off any remaining stored content
-	fixedup = pop(insert, fixedup)
-	fixedup = pop(delete, fixedup)
-
-	return fixedup
-}
-
-func diffToHTML(fileName string, diffs []diffmatchpatch.Diff, lineType DiffLineType) template.HTML {
-	buf := bytes.NewBuffer(nil)
-	match := ""
-
-	diffs = fixupBrokenSpans(diffs)
-
-	for _, diff := range diffs {
-		if shouldWriteInline(diff, lineType) {
-			if len(match) > 0 {
-				diff.Text = match + diff.Text
-				match = ""
-			}
-			// Chroma HTML syntax highlighting is done before diffing individual lines in order to maintain consistency.
-			// Since inline changes might split in the middle of a chroma span tag or HTML entity, make we manually put it back together
-			// before writing so we don't try insert added/removed code spans in the middle of one of those
-			// and create broken HTML. This is done by moving incomplete HTML forward until it no longer matches our pattern of
-			// a line ending with an incomplete HTML entity or partial/opening <span>.
-
-			// EX:
-			// diffs[{Type: dmp.DiffDelete, Text: "language</span><span "},
-			// {Type: dmp.DiffEqual, Text: "c"},
-			// {Type: dmp.DiffDelete, Text: "lass="p">}]
-
-			// After first iteration
-			// diffs[{Type: dmp.DiffDelete, Text: "language</span>"}, //write out
-			// {Type: dmp.DiffEqual, Text: "<span c"},
-			// {Type: dmp.DiffDelete, Text: "lass="p">,</span>}]
-
-			// After second iteration
-			// {Type: dmp.DiffEqual, Text: ""}, // write out
-			// {Type: dmp.DiffDelete, Text: "<span class="p">,</span>}]
-
-			// Final
-			// {Type: dmp.DiffDelete, Text: "<span class="p">,</span>}]
-			// end up writing <span class="removed-code"><span class="p">,</span></span>
-			// Instead of <span class="removed-code">lass="p",</span></span>
-
-			m := trailingSpanRegex.FindStringSubmatchIndex(diff.Text)
-			if m != nil {
-				match = diff.Text[m[0]:m[1]]
-				diff.Text = strings.TrimSuffix(diff.Text, match)
-			}
-			m = entityRegex.FindStringSubmatchIndex(diff.Text)
-			if m != nil {
-				match = diff.Text[m[0]:m[1]]
-				diff.Text = strings.TrimSuffix(diff.Text, match)
-			}
-			// Print an existing closing span first before opening added/remove-code span so it doesn't unintentionally close it
-			if strings.HasPrefix(diff.Text, "</span>") {
-				buf.WriteString("</span>")
-				diff.Text = strings.TrimPrefix(diff.Text, "</span>")
-			}
-			// If we weren't able to fix it then this should avoid broken HTML by not inserting more spans below
-			// The previous/next diff section will contain the rest of the tag that is missing here
-			if strings.Count(diff.Text, "<") != strings.Count(diff.Text, ">") {
-				buf.WriteString(diff.Text)
-				continue
-			}
-		}
-		switch {
-		case diff.Type == diffmatchpatch.DiffEqual:
-			buf.WriteString(diff.Text)
-		case diff.Type == diffmatchpatch.DiffInsert && lineType == DiffLineAdd:
-			buf.Write(addedCodePrefix)
-			buf.WriteString(diff.Text)
-			buf.Write(codeTagSuffix)
-		case diff.Type == diffmatchpatch.DiffDelete && lineType == DiffLineDel:
-			buf.Write(removedCodePrefix)
-			buf.WriteString(diff.Text)
-			buf.Write(codeTagSuffix)
-		}
-	}
-	return template.HTML(buf.Bytes())
-}
-
 // GetLine gets a specific line by type (add or del) and file line number
 func (diffSection *DiffSection) GetLine(lineType DiffLineType, idx int) *DiffLine {
 	var (
@@ -525,21 +233,13 @@ LOOP:
 
 var diffMatchPatch = diffmatchpatch.New()
 
-func init() {
-	diffMatchPatch.DiffEditCost = 100
-}
-
 // GetComputedInlineDiffFor computes inline diff for the given line.
 func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) template.HTML {
 	if setting.Git.DisableDiffHighlight {
 		return template.HTML(getLineContent(diffLine.Content[1:]))
 	}
 
-	var (
-		compareDiffLine *DiffLine
-		diff1           string
-		diff2           string
-	)
+	var compareDiffLine *DiffLine
 
 	// try to find equivalent diff line. ignore, otherwise
 	switch diffLine.Type {
@@ -547,18 +247,8 @@ func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) tem
 		return template.HTML(getLineContent(diffLine.Content[1:]))
 	case DiffLineAdd:
 		compareDiffLine = diffSection.GetLine(DiffLineDel, diffLine.RightIdx)
-		if compareDiffLine == nil {
-			return template.HTML(highlight.Code(diffSection.FileName, diffLine.Content[1:]))
-		}
-		diff1 = compareDiffLine.Content
-		diff2 = diffLine.Content
 	case DiffLineDel:
 		compareDiffLine = diffSection.GetLine(DiffLineAdd, diffLine.LeftIdx)
-		if compareDiffLine == nil {
-			return template.HTML(highlight.Code(diffSection.FileName, diffLine.Content[1:]))
-		}
-		diff1 = diffLine.Content
-		diff2 = compareDiffLine.Content
 	default:
 		if strings.IndexByte(" +-", diffLine.Content[0]) > -1 {
 			return template.HTML(highlight.Code(diffSection.FileName, diffLine.Content[1:]))
@@ -566,10 +256,17 @@ func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) tem
 		return template.HTML(highlight.Code(diffSection.FileName, diffLine.Content))
 	}
 
-	diffRecord := diffMatchPatch.DiffMain(highlight.Code(diffSection.FileName, diff1[1:]), highlight.Code(diffSection.FileName, diff2[1:]), true)
-	diffRecord = diffMatchPatch.DiffCleanupEfficiency(diffRecord)
+	// moved lines are shown as a whole, comparing them to the line they replaced makes no sense
+	if compareDiffLine == nil || diffLine.IsMoved || compareDiffLine.IsMoved {
+		return template.HTML(highlight.Code(diffSection.FileName, diffLine.Content[1:]))
+	}
 
-	return diffToHTML(diffSection.FileName, diffRecord, diffLine.Type)
+	if diffLine.Type == DiffLineAdd {
+		_, added := highlightDiffLines(diffSection.FileName, compareDiffLine.Content[1:], diffLine.Content[1:], diffSection.IgnoreWhitespace)
+		return added
+	}
+	deleted, _ := highlightDiffLines(diffSection.FileName, diffLine.Content[1:], compareDiffLine.Content[1:], diffSection.IgnoreWhitespace)
+	return deleted
 }
 
 // DiffFile represents a file diff.
@@ -643,6 +340,114 @@ func getCommitFileLineCount(commit *git.Commit, filePath string) int {
 	return lineCount
 }
 
+const (
+	// movedBlockMinAlnums is the number of alphanumeric characters a block of lines needs to be
+	// detected as moved, like for git's --color-moved, so that e.g. lone braces are not
+	movedBlockMinAlnums = 20
+	// movedBlockStartMinAlnums is the number of alphanumeric characters the first line of a moved block
+	// needs, so that frequent lines like braces or blank lines don't start blocks
+	movedBlockStartMinAlnums = 4
+	// movedBlockMaxCandidates is the number of added lines a block of deleted lines is compared to at most
+	movedBlockMaxCandidates = 16
+)
+
+// countAlnums returns the number of letters and digits of a string
+func countAlnums(s string) int {
+	alnums := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			alnums++
+		}
+	}
+	return alnums
+}
+
+// diffLinePosition is the position of a line within the sections of a DiffFile
+type diffLinePosition struct {
+	section, line int
+}
+
+// DetectMovedBlocks marks blocks of deleted lines which are added again elsewhere in the file as moved.
+// If whitespace is ignored, lines are compared with their whitespace collapsed.
+func (diffFile *DiffFile) DetectMovedBlocks(ignoreWhitespace bool) {
+	lineKey := func(diffLine *DiffLine) string {
+		if ignoreWhitespace {
+			return strings.Join(strings.Fields(diffLine.Content[1:]), " ")
+		}
+		return diffLine.Content[1:]
+	}
+
+	// the lines which may start a moved block, each with a limited number of candidates
+	// so that the detection doesn't take quadratic time on frequent lines
+	added := make(map[string][]diffLinePosition)
+	for i, section := range diffFile.Sections {
+		for j, diffLine := range section.Lines {
+			if diffLine.Type != DiffLineAdd || countAlnums(diffLine.Content[1:]) < movedBlockStartMinAlnums {
+				continue
+			}
+			key := lineKey(diffLine)
+			if len(added[key]) < movedBlockMaxCandidates {
+				added[key] = append(added[key], diffLinePosition{i, j})
+			}
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	// blockLength returns the number of deleted lines from the start which are added from the target on
+	blockLength := func(start, target diffLinePosition) int {
+		deleted := diffFile.Sections[start.section].Lines
+		additions := diffFile.Sections[target.section].Lines
+		length := 0
+		for start.line+length < len(deleted) && target.line+length < len(additions) {
+			deletedLine := deleted[start.line+length]
+			addedLine := additions[target.line+length]
+			if deletedLine.Type != DiffLineDel || deletedLine.IsMoved ||
+				addedLine.Type != DiffLineAdd || addedLine.IsMoved ||
+				lineKey(deletedLine) != lineKey(addedLine) {
+				break
+			}
+			length++
+		}
+		return length
+	}
+
+	for i, section := range diffFile.Sections {
+		for j := 0; j < len(section.Lines); j++ {
+			if section.Lines[j].Type != DiffLineDel || section.Lines[j].IsMoved {
+				continue
+			}
+
+			start := diffLinePosition{i, j}
+			var target diffLinePosition
+			length := 0
+			for _, position := range added[lineKey(section.Lines[j])] {
+				if l := blockLength(start, position); l > length {
+					target, length = position, l
+				}
+			}
+			if length == 0 {
+				continue
+			}
+
+			alnums := 0
+			for _, diffLine := range section.Lines[j : j+length] {
+				alnums += countAlnums(diffLine.Content[1:])
+			}
+			if alnums < movedBlockMinAlnums {
+				continue
+			}
+
+			for k := 0; k < length; k++ {
+				section.Lines[j+k].IsMoved = true
+				diffFile.Sections[target.section].Lines[target.line+k].IsMoved = true
+			}
+			j += length - 1
+		}
+	}
+}
+
 // Diff represents a difference between two git trees.
 type Diff struct {
 	NumFiles, TotalAddition, TotalDeletion int
@@ -1244,7 +1049,13 @@ func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID
 	if err != nil {
 		return nil, fmt.Errorf("ParsePatch: %v", err)
 	}
+	// with git's whitespace flags, also ignore whitespace changes within lines and moved blocks
+	ignoreWhitespace := whitespaceBehavior == "-w" || whitespaceBehavior == "-b"
 	for _, diffFile := range diff.Files {
+		diffFile.DetectMovedBlocks(ignoreWhitespace)
+		for _, section := range diffFile.Sections {
+			section.IgnoreWhitespace = ignoreWhitespace
+		}
 		tailSection := diffFile.GetTailSection(gitRepo, beforeCommitID, afterCommitID)
 		if tailSection != nil {
 			diffFile.Sections = append(diffFile.Sections, tailSection)
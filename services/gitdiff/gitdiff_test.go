@@ -14,10 +14,8 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
-	"code.gitea.io/gitea/modules/highlight"
 	"code.gitea.io/gitea/modules/setting"
 	jsoniter "github.com/json-iterator/go"
-	dmp "github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/ini.v1"
 )
@@ -28,78 +26,148 @@ func assertEqual(t *testing.T, s1 string, s2 template.HTML) {
 	}
 }
 
-func TestDiffToHTML(t *testing.T) {
+func TestHighlightDiffLines(t *testing.T) {
 	setting.Cfg = ini.Empty()
-	assertEqual(t, "foo <span class=\"added-code\">bar</span> biz", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "foo "},
-		{Type: dmp.DiffInsert, Text: "bar"},
-		{Type: dmp.DiffDelete, Text: " baz"},
-		{Type: dmp.DiffEqual, Text: " biz"},
-	}, DiffLineAdd))
-
-	assertEqual(t, "foo <span class=\"removed-code\">bar</span> biz", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "foo "},
-		{Type: dmp.DiffDelete, Text: "bar"},
-		{Type: dmp.DiffInsert, Text: " baz"},
-		{Type: dmp.DiffEqual, Text: " biz"},
-	}, DiffLineDel))
-
-	assertEqual(t, "<span class=\"k\">if</span> <span class=\"p\">!</span><span class=\"nx\">nohl</span> <span class=\"o\">&amp;&amp;</span> <span class=\"added-code\"><span class=\"p\">(</span></span><span class=\"nx\">lexer</span> <span class=\"o\">!=</span> <span class=\"kc\">nil</span><span class=\"added-code\"> <span class=\"o\">||</span> <span class=\"nx\">r</span><span class=\"p\">.</span><span class=\"nx\">GuessLanguage</span><span class=\"p\">)</span></span> <span class=\"p\">{</span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "<span class=\"k\">if</span> <span class=\"p\">!</span><span class=\"nx\">nohl</span> <span class=\"o\">&amp;&amp;</span> <span class=\""},
-		{Type: dmp.DiffInsert, Text: "p\">(</span><span class=\""},
-		{Type: dmp.DiffEqual, Text: "nx\">lexer</span> <span class=\"o\">!=</span> <span class=\"kc\">nil"},
-		{Type: dmp.DiffInsert, Text: "</span> <span class=\"o\">||</span> <span class=\"nx\">r</span><span class=\"p\">.</span><span class=\"nx\">GuessLanguage</span><span class=\"p\">)"},
-		{Type: dmp.DiffEqual, Text: "</span> <span class=\"p\">{</span>"},
-	}, DiffLineAdd))
-
-	assertEqual(t, "<span class=\"nx\">tagURL</span> <span class=\"o\">:=</span> <span class=\"removed-code\"><span class=\"nx\">fmt</span><span class=\"p\">.</span><span class=\"nf\">Sprintf</span><span class=\"p\">(</span><span class=\"s\">&#34;## [%s](%s/%s/%s/%s?q=&amp;type=all&amp;state=closed&amp;milestone=%d) - %s&#34;</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">Milestone\"</span></span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">BaseURL</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">Owner</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">Repo</span><span class=\"p\">,</span> <span class=\"removed-code\"><span class=\"nx\">from</span><span class=\"p\">,</span> <span class=\"nx\">milestoneID</span><span class=\"p\">,</span> <span class=\"nx\">time</span><span class=\"p\">.</span><span class=\"nf\">Now</span><span class=\"p\">(</span><span class=\"p\">)</span><span class=\"p\">.</span><span class=\"nf\">Format</span><span class=\"p\">(</span><span class=\"s\">&#34;2006-01-02&#34;</span><span class=\"p\">)</span></span><span class=\"p\">)</span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "<span class=\"nx\">tagURL</span> <span class=\"o\">:=</span> <span class=\"n"},
-		{Type: dmp.DiffDelete, Text: "x\">fmt</span><span class=\"p\">.</span><span class=\"nf\">Sprintf</span><span class=\"p\">(</span><span class=\"s\">&#34;## [%s](%s/%s/%s/%s?q=&amp;type=all&amp;state=closed&amp;milestone=%d) - %s&#34;</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">Milestone\""},
-		{Type: dmp.DiffInsert, Text: "f\">getGiteaTagURL</span><span class=\"p\">(</span><span class=\"nx\">client"},
-		{Type: dmp.DiffEqual, Text: "</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">BaseURL</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">Owner</span><span class=\"p\">,</span> <span class=\"nx\">ge</span><span class=\"p\">.</span><span class=\"nx\">Repo</span><span class=\"p\">,</span> <span class=\"nx\">"},
-		{Type: dmp.DiffDelete, Text: "from</span><span class=\"p\">,</span> <span class=\"nx\">milestoneID</span><span class=\"p\">,</span> <span class=\"nx\">time</span><span class=\"p\">.</span><span class=\"nf\">Now</span><span class=\"p\">(</span><span class=\"p\">)</span><span class=\"p\">.</span><span class=\"nf\">Format</span><span class=\"p\">(</span><span class=\"s\">&#34;2006-01-02&#34;</span><span class=\"p\">)"},
-		{Type: dmp.DiffInsert, Text: "ge</span><span class=\"p\">.</span><span class=\"nx\">Milestone</span><span class=\"p\">,</span> <span class=\"nx\">from</span><span class=\"p\">,</span> <span class=\"nx\">milestoneID"},
-		{Type: dmp.DiffEqual, Text: "</span><span class=\"p\">)</span>"},
-	}, DiffLineDel))
+	for _, c := range []struct {
+		fileName, deleted, added string
+		ignoreWhitespace         bool
+		expectedDeleted          string
+		expectedAdded            string
+	}{
+		{
+			deleted:         "foo baz biz",
+			added:           "foo bar biz",
+			expectedDeleted: `foo <span class="removed-code">baz</span> biz`,
+			expectedAdded:   `foo <span class="added-code">bar</span> biz`,
+		},
+		{
+			fileName:        "main.go",
+			deleted:         "if !nohl && lexer != nil {",
+			added:           "if !nohl && (lexer != nil || r.GuessLanguage) {",
+			expectedDeleted: `<span class="k">if</span> <span class="p">!</span><span class="nx">nohl</span> <span class="o">&amp;&amp;</span> <span class="nx">lexer</span> <span class="o">!=</span> <span class="kc">nil</span> <span class="p">{</span>`,
+			expectedAdded:   `<span class="k">if</span> <span class="p">!</span><span class="nx">nohl</span> <span class="o">&amp;&amp;</span> <span class="added-code"><span class="p">(</span></span><span class="nx">lexer</span> <span class="o">!=</span> <span class="kc">nil</span><span class="added-code"> <span class="o">||</span> <span class="nx">r</span><span class="p">.</span><span class="nx">GuessLanguage</span><span class="p">)</span></span> <span class="p">{</span>`,
+		},
+		{
+			fileName:        "main.go",
+			deleted:         "r.WrapperRenderer(w, language, true, attrs, false)",
+			added:           "r.WrapperRenderer(w, false)",
+			expectedDeleted: `<span class="nx">r</span><span class="p">.</span><span class="nf">WrapperRenderer</span><span class="p">(</span><span class="nx">w</span><span class="p">,</span> <span class="removed-code"><span class="nx">language</span><span class="p">,</span> <span class="kc">true</span><span class="p">,</span> <span class="nx">attrs</span><span class="p">,</span> </span><span class="kc">false</span><span class="p">)</span>`,
+			expectedAdded:   `<span class="nx">r</span><span class="p">.</span><span class="nf">WrapperRenderer</span><span class="p">(</span><span class="nx">w</span><span class="p">,</span> <span class="kc">false</span><span class="p">)</span>`,
+		},
+		{
+			// comments are diffed word by word
+			fileName:        "main.go",
+			deleted:         "// compute the inline diff",
+			added:           "// compute the word diff",
+			expectedDeleted: `<span class="c1">// compute the </span><span class="removed-code"><span class="c1">inline</span></span><span class="c1"> diff</span>`,
+			expectedAdded:   `<span class="c1">// compute the </span><span class="added-code"><span class="c1">word</span></span><span class="c1"> diff</span>`,
+		},
+		{
+			fileName:        "main.v",
+			deleted:         "		run()",
+			added:           "		run(db)",
+			expectedDeleted: `		<span class="n">run</span><span class="bp">()</span>`,
+			expectedAdded:   `		<span class="n">run</span><span class="o">(</span><span class="added-code"><span class="n">db</span></span><span class="o">)</span>`,
+		},
+		{
+			fileName:        "main.go",
+			deleted:         "	x := a+b",
+			added:           "	x := a + b",
+			expectedDeleted: `	<span class="nx">x</span> <span class="o">:=</span> <span class="nx">a</span><span class="o">+</span><span class="nx">b</span>`,
+			expectedAdded:   `	<span class="nx">x</span> <span class="o">:=</span> <span class="nx">a</span><span class="added-code"> </span><span class="o">+</span><span class="added-code"> </span><span class="nx">b</span>`,
+		},
+		{
+			fileName:         "main.go",
+			deleted:          "	x := a+b",
+			added:            "    x := a + c",
+			ignoreWhitespace: true,
+			expectedDeleted:  `	<span class="nx">x</span> <span class="o">:=</span> <span class="nx">a</span><span class="o">+</span><span class="removed-code"><span class="nx">b</span></span>`,
+			expectedAdded:    `    <span class="nx">x</span> <span class="o">:=</span> <span class="nx">a</span> <span class="o">+</span> <span class="added-code"><span class="nx">c</span></span>`,
+		},
+		{
+			deleted:         `sh "useradd -u 111 jenkins"`,
+			added:           `sh 'useradd -u $(stat -c "%u" .gitignore) jenkins'`,
+			expectedDeleted: `sh <span class="removed-code">&#34;</span>useradd -u <span class="removed-code">111 jenkins</span>&#34;`,
+			expectedAdded:   `sh <span class="added-code">&#39;</span>useradd -u <span class="added-code">$(stat -c </span>&#34;<span class="added-code">%u&#34; .gitignore) jenkins&#39;</span>`,
+		},
+		{
+			fileName:        "list.tmpl",
+			deleted:         "							<h3>",
+			added:           `							<h4 class="release-list-title df ac">`,
+			expectedDeleted: `<span class="x">							&lt;</span><span class="removed-code"><span class="x">h3</span></span><span class="x">&gt;</span>`,
+			expectedAdded:   `<span class="x">							&lt;</span><span class="added-code"><span class="x">h4 class=&#34;release-list-title df ac&#34;</span></span><span class="x">&gt;</span>`,
+		},
+		{
+			fileName:        "main.py",
+			deleted:         `print "// ", sys.argv`,
+			added:           `print("// ", sys.argv)`,
+			expectedDeleted: `<span class="k">print</span><span class="removed-code"> </span><span class="s2">&#34;// &#34;</span><span class="p">,</span> <span class="n">sys</span><span class="o">.</span><span class="n">argv</span>`,
+			expectedAdded:   `<span class="k">print</span><span class="added-code"><span class="p">(</span></span><span class="s2">&#34;// &#34;</span><span class="p">,</span> <span class="n">sys</span><span class="o">.</span><span class="n">argv</span><span class="added-code"><span class="p">)</span></span>`,
+		},
+		{
+			deleted:         "",
+			added:           "x",
+			expectedDeleted: "",
+			expectedAdded:   `<span class="added-code">x</span>`,
+		},
+	} {
+		deleted, added := highlightDiffLines(c.fileName, c.deleted, c.added, c.ignoreWhitespace)
+		assertEqual(t, c.expectedDeleted, deleted)
+		assertEqual(t, c.expectedAdded, added)
+	}
+}
 
-	assertEqual(t, "<span class=\"nx\">r</span><span class=\"p\">.</span><span class=\"nf\">WrapperRenderer</span><span class=\"p\">(</span><span class=\"nx\">w</span><span class=\"p\">,</span> <span class=\"removed-code\"><span class=\"nx\">language</span><span class=\"p\">,</span> <span class=\"kc\">true</span><span class=\"p\">,</span> <span class=\"nx\">attrs</span></span><span class=\"p\">,</span> <span class=\"kc\">false</span><span class=\"p\">)</span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "<span class=\"nx\">r</span><span class=\"p\">.</span><span class=\"nf\">WrapperRenderer</span><span class=\"p\">(</span><span class=\"nx\">w</span><span class=\"p\">,</span> <span class=\"nx\">"},
-		{Type: dmp.DiffDelete, Text: "language</span><span "},
-		{Type: dmp.DiffEqual, Text: "c"},
-		{Type: dmp.DiffDelete, Text: "lass=\"p\">,</span> <span class=\"kc\">true</span><span class=\"p\">,</span> <span class=\"nx\">attrs"},
-		{Type: dmp.DiffEqual, Text: "</span><span class=\"p\">,</span> <span class=\"kc\">false</span><span class=\"p\">)</span>"},
-	}, DiffLineDel))
+func TestDiffFile_DetectMovedBlocks(t *testing.T) {
+	setting.Cfg = ini.Empty()
+	diffFile := &DiffFile{
+		Name: "main.go",
+		Sections: []*DiffSection{
+			{
+				FileName: "main.go",
+				Lines: []*DiffLine{
+					{LeftIdx: 1, RightIdx: 1, Type: DiffLinePlain, Content: " package main"},
+					{LeftIdx: 2, Type: DiffLineDel, Content: "-func moved() {"},
+					{LeftIdx: 3, Type: DiffLineDel, Content: "-	return doSomething(argument)"},
+					{LeftIdx: 4, Type: DiffLineDel, Content: "-}"},
+					{RightIdx: 2, Type: DiffLineAdd, Content: "+func changed() {"},
+					{RightIdx: 3, Type: DiffLineAdd, Content: "+}"},
+				},
+			},
+			{
+				FileName: "main.go",
+				Lines: []*DiffLine{
+					{LeftIdx: 20, RightIdx: 19, Type: DiffLinePlain, Content: " "},
+					{RightIdx: 20, Type: DiffLineAdd, Content: "+func moved() {"},
+					{RightIdx: 21, Type: DiffLineAdd, Content: "+    return doSomething(argument)"},
+					{RightIdx: 22, Type: DiffLineAdd, Content: "+}"},
+				},
+			},
+		},
+	}
 
-	assertEqual(t, "<span class=\"added-code\">language</span><span class=\"p\">,</span> <span class=\"kc\">true</span><span class=\"p\">,</span> <span class=\"nx\">attrs</span></span><span class=\"p\">,</span> <span class=\"kc\">false</span><span class=\"p\">)</span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffInsert, Text: "language</span><span "},
-		{Type: dmp.DiffEqual, Text: "c"},
-		{Type: dmp.DiffInsert, Text: "lass=\"p\">,</span> <span class=\"kc\">true</span><span class=\"p\">,</span> <span class=\"nx\">attrs"},
-		{Type: dmp.DiffEqual, Text: "</span><span class=\"p\">,</span> <span class=\"kc\">false</span><span class=\"p\">)</span>"},
-	}, DiffLineAdd))
+	isMoved := func() []bool {
+		var moved []bool
+		for _, section := range diffFile.Sections {
+			for _, line := range section.Lines {
+				moved = append(moved, line.IsMoved)
+			}
+		}
+		return moved
+	}
 
-	assertEqual(t, "<span class=\"k\">print</span><span class=\"added-code\"><span class=\"p\">(</span></span><span class=\"sa\"></span><span class=\"s2\">&#34;</span><span class=\"s2\">// </span><span class=\"s2\">&#34;</span><span class=\"p\">,</span> <span class=\"n\">sys</span><span class=\"o\">.</span><span class=\"n\">argv</span><span class=\"added-code\"><span class=\"p\">)</span></span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "<span class=\"k\">print</span>"},
-		{Type: dmp.DiffInsert, Text: "<span"},
-		{Type: dmp.DiffEqual, Text: " "},
-		{Type: dmp.DiffInsert, Text: "class=\"p\">(</span>"},
-		{Type: dmp.DiffEqual, Text: "<span class=\"sa\"></span><span class=\"s2\">&#34;</span><span class=\"s2\">// </span><span class=\"s2\">&#34;</span><span class=\"p\">,</span> <span class=\"n\">sys</span><span class=\"o\">.</span><span class=\"n\">argv</span>"},
-		{Type: dmp.DiffInsert, Text: "<span class=\"p\">)</span>"},
-	}, DiffLineAdd))
+	// the indentation differs, and the function header alone is too short to be a moved block
+	diffFile.DetectMovedBlocks(false)
+	assert.Equal(t, []bool{false, false, false, false, false, false, false, false, false, false}, isMoved())
 
-	assertEqual(t, "sh <span class=\"added-code\">&#39;useradd -u $(stat -c &#34;%u&#34; .gitignore) jenkins&#39;</span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "sh &#3"},
-		{Type: dmp.DiffDelete, Text: "4;useradd -u 111 jenkins&#34"},
-		{Type: dmp.DiffInsert, Text: "9;useradd -u $(stat -c &#34;%u&#34; .gitignore) jenkins&#39"},
-		{Type: dmp.DiffEqual, Text: ";"},
-	}, DiffLineAdd))
+	diffFile.DetectMovedBlocks(true)
+	assert.Equal(t, []bool{false, true, true, true, false, false, false, true, true, true}, isMoved())
 
-	assertEqual(t, "<span class=\"x\">							&lt;h<span class=\"added-code\">4 class=&#34;release-list-title df ac&#34;</span>&gt;</span>", diffToHTML("", []dmp.Diff{
-		{Type: dmp.DiffEqual, Text: "<span class=\"x\">							&lt;h"},
-		{Type: dmp.DiffInsert, Text: "4 class=&#"},
-		{Type: dmp.DiffEqual, Text: "3"},
-		{Type: dmp.DiffInsert, Text: "4;release-list-title df ac&#34;"},
-		{Type: dmp.DiffEqual, Text: "&gt;</span>"},
-	}, DiffLineAdd))
+	// moved lines are not compared to the lines they replaced
+	section := diffFile.Sections[0]
+	section.Lines = append(section.Lines, &DiffLine{RightIdx: 4, Type: DiffLineAdd, Content: "+"})
+	assert.NotNil(t, section.GetLine(DiffLineAdd, 2))
+	assert.NotContains(t, section.GetComputedInlineDiffFor(section.Lines[1]), "removed-code")
+	assert.NotContains(t, section.GetComputedInlineDiffFor(section.Lines[4]), "added-code")
 }
 
 func TestParsePatch_singlefile(t *testing.T) {
@@ -524,14 +592,3 @@ func TestGetDiffRangeWithWhitespaceBehavior(t *testing.T) {
 		}
 	}
 }
-
-func TestDiffToHTML_14231(t *testing.T) {
-	setting.Cfg = ini.Empty()
-	diffRecord := diffMatchPatch.DiffMain(highlight.Code("main.v", "		run()\n"), highlight.Code("main.v", "		run(db)\n"), true)
-	diffRecord = diffMatchPatch.DiffCleanupEfficiency(diffRecord)
-
-	expected := `		<span class="n">run</span><span class="added-code"><span class="o">(</span><span class="n">db</span></span><span class="o">)</span>`
-	output := diffToHTML("main.v", diffRecord, DiffLineAdd)
-
-	assertEqual(t, expected, output)
-}
@@ -0,0 +1,208 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitdiff
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/modules/highlight"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffTokenRuneOffset is the first rune tokens are mapped to for diffing,
+// the start of the private use area so no surrogates are used
+const diffTokenRuneOffset = 0xE000
+
+// diffToken is a piece of a highlighted line which is diffed as a whole
+type diffToken struct {
+	highlight.Token
+	// key is what the token is compared by
+	key string
+	// isSpace is set if the token is a run of whitespace
+	isSpace bool
+}
+
+const (
+	runeKindOther = iota
+	runeKindWord
+	runeKindSpace
+)
+
+func runeKind(r rune) int {
+	switch {
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return runeKindWord
+	case unicode.IsSpace(r):
+		return runeKindSpace
+	}
+	return runeKindOther
+}
+
+// splitDiffTokens splits the syntax highlighting tokens of a line further into words, runs of
+// whitespace and single other characters, so that e.g. comments and strings are diffed word by word.
+// If whitespace is ignored, all runs of whitespace compare equal.
+func splitDiffTokens(fileName, code string, ignoreWhitespace bool) []diffToken {
+	tokens := make([]diffToken, 0, 16)
+	for _, token := range highlight.Tokens(fileName, code) {
+		value := token.Value
+		for len(value) > 0 {
+			r, size := utf8.DecodeRuneInString(value)
+			kind := runeKind(r)
+			if kind != runeKindOther {
+				for size < len(value) {
+					next, nextSize := utf8.DecodeRuneInString(value[size:])
+					if runeKind(next) != kind {
+						break
+					}
+					size += nextSize
+				}
+			}
+
+			diffToken := diffToken{
+				Token:   highlight.Token{Class: token.Class, Value: value[:size]},
+				key:     value[:size],
+				isSpace: kind == runeKindSpace,
+			}
+			if ignoreWhitespace && diffToken.isSpace {
+				diffToken.key = " "
+			}
+			tokens = append(tokens, diffToken)
+			value = value[size:]
+		}
+	}
+	return tokens
+}
+
+// diffTokens diffs two lists of tokens and returns which tokens of each list were changed
+func diffTokens(tokens1, tokens2 []diffToken) (changed1, changed2 []bool) {
+	runes := make(map[string]rune)
+	isSpace := make(map[rune]bool)
+	toRunes := func(tokens []diffToken) []rune {
+		result := make([]rune, len(tokens))
+		for i, token := range tokens {
+			r, ok := runes[token.key]
+			if !ok {
+				r = diffTokenRuneOffset + rune(len(runes))
+				runes[token.key] = r
+				isSpace[r] = token.isSpace
+			}
+			result[i] = r
+		}
+		return result
+	}
+
+	diffs := diffMatchPatch.DiffMainRunes(toRunes(tokens1), toRunes(tokens2), false)
+
+	changed1 = make([]bool, len(tokens1))
+	changed2 = make([]bool, len(tokens2))
+	idx1, idx2 := 0, 0
+	for i, diff := range diffs {
+		count := utf8.RuneCountInString(diff.Text)
+		switch diff.Type {
+		case diffmatchpatch.DiffEqual:
+			// whitespace between two changes is part of the change, so "a b" to "c d" is a single change
+			if i > 0 && i < len(diffs)-1 && isSpaceRunes(diff.Text, isSpace) {
+				markChanged(changed1[idx1:idx1+count], changed2[idx2:idx2+count])
+			}
+			idx1 += count
+			idx2 += count
+		case diffmatchpatch.DiffDelete:
+			markChanged(changed1[idx1 : idx1+count])
+			idx1 += count
+		case diffmatchpatch.DiffInsert:
+			markChanged(changed2[idx2 : idx2+count])
+			idx2 += count
+		}
+	}
+	return changed1, changed2
+}
+
+func isSpaceRunes(text string, isSpace map[rune]bool) bool {
+	for _, r := range text {
+		if !isSpace[r] {
+			return false
+		}
+	}
+	return true
+}
+
+func markChanged(changed ...[]bool) {
+	for _, c := range changed {
+		for i := range c {
+			c[i] = true
+		}
+	}
+}
+
+// tokensToHTML renders highlighted tokens and wraps the changed ones into the code prefix.
+// If whitespace is ignored, only whitespace within a change is rendered as changed.
+func tokensToHTML(tokens []diffToken, changed []bool, codePrefix []byte, ignoreWhitespace bool) template.HTML {
+	if ignoreWhitespace {
+		for i, token := range tokens {
+			if token.isSpace && changed[i] {
+				changed[i] = i > 0 && i < len(tokens)-1 && changed[i-1] && changed[i+1]
+			}
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for start := 0; start < len(tokens); {
+		end := start + 1
+		for end < len(tokens) && changed[end] == changed[start] {
+			end++
+		}
+
+		if changed[start] {
+			buf.Write(codePrefix)
+		}
+		writeTokens(buf, tokens[start:end])
+		if changed[start] {
+			buf.Write(codeTagSuffix)
+		}
+		start = end
+	}
+	return template.HTML(buf.Bytes())
+}
+
+// writeTokens writes tokens like chroma's HTML formatter, joining neighbours of the same class
+func writeTokens(buf *bytes.Buffer, tokens []diffToken) {
+	for start := 0; start < len(tokens); {
+		end := start + 1
+		for end < len(tokens) && tokens[end].Class == tokens[start].Class {
+			end++
+		}
+
+		var value strings.Builder
+		for _, token := range tokens[start:end] {
+			value.WriteString(token.Value)
+		}
+		if len(tokens[start].Class) > 0 {
+			buf.WriteString(`<span class="`)
+			buf.WriteString(tokens[start].Class)
+			buf.WriteString(`">`)
+			buf.WriteString(html.EscapeString(value.String()))
+			buf.Write(codeTagSuffix)
+		} else {
+			buf.WriteString(html.EscapeString(value.String()))
+		}
+		start = end
+	}
+}
+
+// highlightDiffLines highlights a deleted and an added line and marks the tokens which were
+// changed between them
+func highlightDiffLines(fileName, deleted, added string, ignoreWhitespace bool) (deletedHTML, addedHTML template.HTML) {
+	deletedTokens := splitDiffTokens(fileName, deleted, ignoreWhitespace)
+	addedTokens := splitDiffTokens(fileName, added, ignoreWhitespace)
+	deletedChanged, addedChanged := diffTokens(deletedTokens, addedTokens)
+	return tokensToHTML(deletedTokens, deletedChanged, removedCodePrefix, ignoreWhitespace),
+		tokensToHTML(addedTokens, addedChanged, addedCodePrefix, ignoreWhitespace)
+}
@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
 	"code.gitea.io/gitea/modules/notification"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
@@ -29,6 +30,13 @@ import (
 // mirrorQueue holds an UniqueQueue object of the mirror
 var mirrorQueue = sync.NewUniqueQueue(setting.Repository.MirrorQueueLength)
 
+// Items in the mirror queue are prefixed by the kind of mirror they refer to:
+// pull mirrors are keyed by repository ID and push mirrors by push mirror ID.
+const (
+	pullMirrorQueuePrefix = "pull:"
+	pushMirrorQueuePrefix = "push:"
+)
+
 func readAddress(m *models.Mirror) {
 	if len(m.Address) > 0 {
 		return
@@ -41,15 +49,19 @@ func readAddress(m *models.Mirror) {
 }
 
 func remoteAddress(repoPath string) (string, error) {
+	return remoteAddressByName(repoPath, "origin")
+}
+
+func remoteAddressByName(repoPath, remoteName string) (string, error) {
 	var cmd *git.Command
 	err := git.LoadGitVersion()
 	if err != nil {
 		return "", err
 	}
 	if git.CheckGitVersionAtLeast("2.7") == nil {
-		cmd = git.NewCommand("remote", "get-url", "origin")
+		cmd = git.NewCommand("remote", "get-url", remoteName)
 	} else {
-		cmd = git.NewCommand("config", "--get", "remote.origin.url")
+		cmd = git.NewCommand("config", "--get", "remote."+remoteName+".url")
 	}
 
 	result, err := cmd.RunInDir(repoPath)
@@ -366,7 +378,24 @@ func Update(ctx context.Context) error {
 		case <-ctx.Done():
 			return fmt.Errorf("Aborted")
 		default:
-			mirrorQueue.Add(m.RepoID)
+			mirrorQueue.Add(pullMirrorQueuePrefix + strconv.FormatInt(m.RepoID, 10))
+			return nil
+		}
+	}); err != nil {
+		log.Trace("Update: %v", err)
+		return err
+	}
+	if err := models.PushMirrorsIterate(func(idx int, bean interface{}) error {
+		m := bean.(*models.PushMirror)
+		if m.Repo == nil {
+			log.Error("Disconnected push-mirror found: %d", m.ID)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Aborted")
+		default:
+			mirrorQueue.Add(pushMirrorQueuePrefix + strconv.FormatInt(m.ID, 10))
 			return nil
 		}
 	}); err != nil {
@@ -386,8 +415,17 @@ func SyncMirrors(ctx context.Context) {
 		case <-ctx.Done():
 			mirrorQueue.Close()
 			return
-		case repoID := <-mirrorQueue.Queue():
-			syncMirror(ctx, repoID)
+		case item := <-mirrorQueue.Queue():
+			mirrorQueue.Remove(item)
+			switch {
+			case strings.HasPrefix(item, pushMirrorQueuePrefix):
+				id, _ := strconv.ParseInt(item[len(pushMirrorQueuePrefix):], 10, 64)
+				_ = SyncPushMirror(ctx, id)
+			case strings.HasPrefix(item, pullMirrorQueuePrefix):
+				syncMirror(ctx, item[len(pullMirrorQueuePrefix):])
+			default:
+				log.Error("Unknown item in mirror queue: %q", item)
+			}
 		}
 	}
 }
@@ -402,7 +440,6 @@ func syncMirror(ctx context.Context, repoID string) {
 		// There was a panic whilst syncMirrors...
 		log.Error("PANIC whilst syncMirrors[%s] Panic: %v\nStacktrace: %s", repoID, err, log.Stack(2))
 	}()
-	mirrorQueue.Remove(repoID)
 
 	id, _ := strconv.ParseInt(repoID, 10, 64)
 	m, err := models.GetMirrorByRepoID(id)
@@ -411,6 +448,9 @@ func syncMirror(ctx context.Context, repoID string) {
 		return
 	}
 
+	// the issues and pull requests of an incremental migration are synced from the last successful sync on
+	since := m.LastSyncedUnix
+
 	log.Trace("SyncMirrors [repo: %-v]: Running Sync", m.Repo)
 	results, ok := runSync(ctx, m)
 	if !ok {
@@ -506,7 +546,18 @@ func syncMirror(ctx context.Context, repoID string) {
 			NewCommitID: newCommitID,
 		}, theCommits)
 	}
-	log.Trace("SyncMirrors [repo: %-v]: done notifying updated branches/tags - now updating last commit time", m.Repo)
+	log.Trace("SyncMirrors [repo: %-v]: done notifying updated branches/tags - now syncing migrated issues and pull requests", m.Repo)
+
+	syncStart := timeutil.TimeStampNow()
+	if err = syncMigratedRepository(ctx, m.Repo, since); err != nil {
+		log.Error("syncMigratedRepository [%d]: %v", m.RepoID, err)
+	} else {
+		m.LastSyncedUnix = syncStart
+		if err = models.UpdateMirror(m); err != nil {
+			log.Error("UpdateMirror [%d]: %v", m.RepoID, err)
+		}
+	}
+	log.Trace("SyncMirrors [repo: %-v]: now updating last commit time", m.Repo)
 
 	// Get latest commit date and update to current repository updated time
 	commitDate, err := git.GetLatestCommitTime(m.Repo.RepoPath())
@@ -523,6 +574,33 @@ func syncMirror(ctx context.Context, repoID string) {
 	log.Trace("SyncMirrors [repo: %-v]: Successfully updated", m.Repo)
 }
 
+// syncMigratedRepository syncs the issues, pull requests, comments and releases of a mirror which has been migrated
+// incrementally from the service it has been migrated from
+func syncMigratedRepository(ctx context.Context, repo *models.Repository, since timeutil.TimeStamp) error {
+	task, err := models.GetMigratingTask(repo.ID)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	opts, err := task.MigrateConfig()
+	if err != nil {
+		return err
+	}
+	if !opts.Incremental {
+		return nil
+	}
+
+	if err := task.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			return err
+		}
+		task.Doer = models.NewGhostUser()
+	}
+	return migrations.SyncRepository(ctx, task.Doer, repo, *opts, since.AsTime())
+}
+
 func checkAndUpdateEmptyRepository(m *models.Mirror, gitRepo *git.Repository, results []*mirrorSyncResult) bool {
 	if !m.Repo.IsEmpty {
 		return true
@@ -595,5 +673,10 @@ func InitSyncMirrors() {
 
 // StartToMirror adds repoID to mirror queue
 func StartToMirror(repoID int64) {
-	go mirrorQueue.Add(repoID)
+	go mirrorQueue.Add(pullMirrorQueuePrefix + strconv.FormatInt(repoID, 10))
+}
+
+// AddPushMirrorToQueue adds the push mirror to the mirror queue
+func AddPushMirrorToQueue(mirrorID int64) {
+	go mirrorQueue.Add(pushMirrorQueuePrefix + strconv.FormatInt(mirrorID, 10))
 }
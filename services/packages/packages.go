@@ -0,0 +1,394 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PackageInfo describes a package
+type PackageInfo struct {
+	Owner       *models.User
+	PackageType models.PackageType
+	Name        string
+	Version     string
+}
+
+// PackageCreationInfo describes a package to create
+type PackageCreationInfo struct {
+	PackageInfo
+	Creator  *models.User
+	Metadata interface{}
+}
+
+// PackageFileInfo describes a package file to add
+type PackageFileInfo struct {
+	Filename string
+	Data     *packages_module.HashedBuffer
+}
+
+// CreatePackageAndAddFile creates a package with a file. If the same package exists already, ErrPackageVersionAlreadyExist is returned
+func CreatePackageAndAddFile(pvci *PackageCreationInfo, pfi *PackageFileInfo) (*models.PackageVersion, *models.PackageFile, error) {
+	return createPackageAndAddFile(pvci, pfi, false)
+}
+
+// CreatePackageOrAddFileToExisting creates a package with a file or adds the file if the package exists already
+func CreatePackageOrAddFileToExisting(pvci *PackageCreationInfo, pfi *PackageFileInfo) (*models.PackageVersion, *models.PackageFile, error) {
+	return createPackageAndAddFile(pvci, pfi, true)
+}
+
+func createPackageAndAddFile(pvci *PackageCreationInfo, pfi *PackageFileInfo, allowExistingVersion bool) (*models.PackageVersion, *models.PackageFile, error) {
+	var (
+		pv          *models.PackageVersion
+		pf          *models.PackageFile
+		blobCreated bool
+	)
+
+	err := models.WithTx(func(ctx models.DBContext) error {
+		var err error
+		pv, err = getOrCreatePackageVersion(ctx, pvci, allowExistingVersion)
+		if err != nil {
+			return err
+		}
+
+		pf, blobCreated, err = addFileToPackageVersion(ctx, pv, pfi)
+		return err
+	})
+	if err != nil {
+		if blobCreated {
+			removeBlobContent(pfi.Data)
+		}
+		return nil, nil, err
+	}
+
+	return pv, pf, nil
+}
+
+func getOrCreatePackageVersion(ctx models.DBContext, pvci *PackageCreationInfo, allowExisting bool) (*models.PackageVersion, error) {
+	p, err := models.TryInsertPackage(ctx, &models.Package{
+		OwnerID: pvci.Owner.ID,
+		Type:    pvci.PackageType,
+		Name:    pvci.Name,
+	})
+	if err != nil && err != models.ErrPackageAlreadyExist {
+		log.Error("Error inserting package: %v", err)
+		return nil, err
+	}
+
+	metadataJSON, err := json.Marshal(pvci.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	pv, err := models.TryInsertPackageVersion(ctx, &models.PackageVersion{
+		PackageID:    p.ID,
+		CreatorID:    pvci.Creator.ID,
+		Version:      pvci.Version,
+		MetadataJSON: string(metadataJSON),
+	})
+	if err != nil {
+		if err == models.ErrPackageVersionAlreadyExist && allowExisting {
+			return pv, nil
+		}
+		if err != models.ErrPackageVersionAlreadyExist {
+			log.Error("Error inserting package version: %v", err)
+		}
+		return nil, err
+	}
+
+	return pv, nil
+}
+
+// AddFileToExistingPackage adds a file to an existing package. If the package does not exist, ErrPackageNotExist is returned
+func AddFileToExistingPackage(pvi *PackageInfo, pfi *PackageFileInfo) (*models.PackageVersion, *models.PackageFile, error) {
+	pv, err := GetPackageVersion(pvi)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		pf          *models.PackageFile
+		blobCreated bool
+	)
+	err = models.WithTx(func(ctx models.DBContext) error {
+		var err error
+		pf, blobCreated, err = addFileToPackageVersion(ctx, pv, pfi)
+		return err
+	})
+	if err != nil {
+		if blobCreated {
+			removeBlobContent(pfi.Data)
+		}
+		return nil, nil, err
+	}
+
+	return pv, pf, nil
+}
+
+// addFileToPackageVersion stores the blob (if it does not exist yet) and adds the file to the version.
+// The returned bool reports if the blob content was written to the content store.
+func addFileToPackageVersion(ctx models.DBContext, pv *models.PackageVersion, pfi *PackageFileInfo) (*models.PackageFile, bool, error) {
+	pb, blobCreated, err := createOrGetBlob(ctx, pfi.Data)
+	if err != nil {
+		return nil, blobCreated, err
+	}
+
+	pf := &models.PackageFile{
+		VersionID: pv.ID,
+		BlobID:    pb.ID,
+		Blob:      pb,
+		Name:      pfi.Filename,
+	}
+	if err := models.InsertPackageFile(ctx, pf); err != nil {
+		if err != models.ErrPackageFileAlreadyExist {
+			log.Error("Error inserting package file: %v", err)
+		}
+		return nil, blobCreated, err
+	}
+
+	return pf, blobCreated, nil
+}
+
+// createOrGetBlob gets the blob with the content of the buffer or creates it and writes the content to the content store
+func createOrGetBlob(ctx models.DBContext, data *packages_module.HashedBuffer) (*models.PackageBlob, bool, error) {
+	hashMD5, hashSHA1, hashSHA256, hashSHA512 := data.Sums()
+	pb, exists, err := models.GetOrInsertPackageBlob(ctx, &models.PackageBlob{
+		Size:       data.Size(),
+		HashMD5:    hashMD5,
+		HashSHA1:   hashSHA1,
+		HashSHA256: hashSHA256,
+		HashSHA512: hashSHA512,
+	})
+	if err != nil {
+		log.Error("Error inserting package blob: %v", err)
+		return nil, false, err
+	}
+	if exists {
+		return pb, false, nil
+	}
+
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return nil, false, err
+	}
+	if err := packages_module.NewContentStore().Save(packages_module.BlobHash256Key(pb.HashSHA256), data, data.Size()); err != nil {
+		log.Error("Error saving package blob in content store: %v", err)
+		return nil, false, err
+	}
+	return pb, true, nil
+}
+
+// removeBlobContent removes the content written by createOrGetBlob if the transaction failed afterwards
+func removeBlobContent(data *packages_module.HashedBuffer) {
+	_, _, hashSHA256, _ := data.Sums()
+	if err := packages_module.NewContentStore().Delete(packages_module.BlobHash256Key(hashSHA256)); err != nil {
+		log.Error("Error deleting package blob %s from content store: %v", hashSHA256, err)
+	}
+}
+
+// CreateBlob stores the content of the buffer as a blob without referencing it by a package file.
+// Unreferenced blobs are removed by the cleanup task if no file references them in time.
+func CreateBlob(data *packages_module.HashedBuffer) (*models.PackageBlob, error) {
+	var pb *models.PackageBlob
+	err := models.WithTx(func(ctx models.DBContext) error {
+		var err error
+		pb, _, err = createOrGetBlob(ctx, data)
+		return err
+	})
+	return pb, err
+}
+
+// AddBlobToPackageVersion adds an existing blob as file to a package version
+func AddBlobToPackageVersion(ctx models.DBContext, pv *models.PackageVersion, pb *models.PackageBlob, filename string) (*models.PackageFile, error) {
+	pf := &models.PackageFile{
+		VersionID: pv.ID,
+		BlobID:    pb.ID,
+		Blob:      pb,
+		Name:      filename,
+	}
+	if err := models.InsertPackageFile(ctx, pf); err != nil {
+		return nil, err
+	}
+	return pf, nil
+}
+
+// GetPackageVersion gets the version described by the package info
+func GetPackageVersion(pvi *PackageInfo) (*models.PackageVersion, error) {
+	p, err := models.GetPackageByName(pvi.Owner.ID, pvi.PackageType, pvi.Name)
+	if err != nil {
+		return nil, err
+	}
+	return models.GetPackageVersionByName(p.ID, pvi.Version)
+}
+
+// DeletePackageVersionByNameAndVersion deletes a package version and all associated files
+func DeletePackageVersionByNameAndVersion(pvi *PackageInfo) error {
+	pv, err := GetPackageVersion(pvi)
+	if err != nil {
+		return err
+	}
+	return models.DeletePackageVersion(pv)
+}
+
+// GetFileStreamByPackageNameAndVersion returns the content of the specific package file
+func GetFileStreamByPackageNameAndVersion(pvi *PackageInfo, filename string) (storage.Object, *models.PackageFile, error) {
+	pv, err := GetPackageVersion(pvi)
+	if err != nil {
+		if err == models.ErrPackageNotExist {
+			return nil, nil, models.ErrPackageVersionNotExist
+		}
+		return nil, nil, err
+	}
+
+	pf, err := models.GetPackageFileByName(pv.ID, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := GetPackageFileStream(pf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := models.IncrementPackageVersionDownloadCount(pv.ID); err != nil {
+		log.Error("Error incrementing download counter of package version %d: %v", pv.ID, err)
+	}
+
+	return s, pf, nil
+}
+
+// GetPackageFileStream returns the content of the package file
+func GetPackageFileStream(pf *models.PackageFile) (storage.Object, error) {
+	if err := pf.LoadBlob(); err != nil {
+		return nil, err
+	}
+	return GetPackageBlobStream(pf.Blob)
+}
+
+// GetPackageBlobStream returns the content of the package blob
+func GetPackageBlobStream(pb *models.PackageBlob) (storage.Object, error) {
+	return packages_module.NewContentStore().Get(packages_module.BlobHash256Key(pb.HashSHA256))
+}
+
+// Cleanup removes blobs which are not referenced by any package file and are older than olderThan
+func Cleanup(ctx context.Context, olderThan time.Duration) error {
+	blobs, err := models.FindUnreferencedPackageBlobs(timeutil.TimeStampNow().AddDuration(-olderThan))
+	if err != nil {
+		return fmt.Errorf("FindUnreferencedPackageBlobs: %v", err)
+	}
+
+	contentStore := packages_module.NewContentStore()
+	for _, pb := range blobs {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("Before deleting package blob %d", pb.ID)
+		default:
+		}
+
+		if err := contentStore.Delete(packages_module.BlobHash256Key(pb.HashSHA256)); err != nil {
+			return fmt.Errorf("Delete package blob %s: %v", pb.HashSHA256, err)
+		}
+		if err := models.DeletePackageBlobByID(pb.ID); err != nil {
+			return fmt.Errorf("DeletePackageBlobByID: %v", err)
+		}
+	}
+
+	return cleanupUploads(ctx, olderThan)
+}
+
+// UploadDir returns the directory which holds unfinished chunked uploads
+func UploadDir() string {
+	return filepath.Join(setting.AppDataPath, "tmp", "package-upload")
+}
+
+// cleanupUploads removes abandoned chunked uploads
+func cleanupUploads(ctx context.Context, olderThan time.Duration) error {
+	entries, err := ioutil.ReadDir(UploadDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	threshold := time.Now().Add(-olderThan)
+	for _, fi := range entries {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("Before deleting package upload %s", fi.Name())
+		default:
+		}
+
+		if fi.IsDir() || fi.ModTime().After(threshold) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(UploadDir(), fi.Name())); err != nil {
+			log.Error("Unable to remove package upload %s: %v", fi.Name(), err)
+		}
+	}
+	return nil
+}
+
+// ReplacePackageVersion creates a package version with a new file and additional files referencing existing blobs.
+// An existing version with the same name is replaced.
+func ReplacePackageVersion(pvci *PackageCreationInfo, pfi *PackageFileInfo, references map[string]*models.PackageBlob) (*models.PackageVersion, error) {
+	var (
+		pv          *models.PackageVersion
+		blobCreated bool
+	)
+
+	err := models.WithTx(func(ctx models.DBContext) error {
+		p, err := models.GetPackageByNameContext(ctx, pvci.Owner.ID, pvci.PackageType, pvci.Name)
+		if err == nil {
+			existing, err := models.GetPackageVersionByNameContext(ctx, p.ID, pvci.Version)
+			if err == nil {
+				if err := models.DeletePackageVersionContext(ctx, existing); err != nil {
+					return err
+				}
+			} else if err != models.ErrPackageVersionNotExist {
+				return err
+			}
+		} else if err != models.ErrPackageNotExist {
+			return err
+		}
+
+		pv, err = getOrCreatePackageVersion(ctx, pvci, false)
+		if err != nil {
+			return err
+		}
+
+		if _, blobCreated, err = addFileToPackageVersion(ctx, pv, pfi); err != nil {
+			return err
+		}
+
+		for name, pb := range references {
+			if _, err := AddBlobToPackageVersion(ctx, pv, pb, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if blobCreated {
+			removeBlobContent(pfi.Data)
+		}
+		return nil, err
+	}
+
+	return pv, nil
+}
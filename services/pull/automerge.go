@@ -0,0 +1,177 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// autoMergeQueue represents a queue to merge pull requests scheduled to auto merge once their checks succeed
+var autoMergeQueue queue.UniqueQueue
+
+// ScheduleAutoMerge schedules a pull request to be merged by doer once all its checks succeed
+func ScheduleAutoMerge(doer *models.User, pr *models.PullRequest, mergeStyle models.MergeStyle, message string) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	if mergeStyle == models.MergeStyleManuallyMerged || !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	if err := models.ScheduleAutoMerge(doer, pr, mergeStyle, message); err != nil {
+		return err
+	}
+
+	// the checks might already have succeeded in the meantime
+	AddToAutoMergeQueue(pr)
+	return nil
+}
+
+// CancelAutoMerge cancels the scheduled auto merge of a pull request
+func CancelAutoMerge(doer *models.User, pr *models.PullRequest) error {
+	return models.UnscheduleAutoMerge(doer, pr, "")
+}
+
+// AddToAutoMergeQueue adds a pull request to the queue checking whether it can be auto merged
+func AddToAutoMergeQueue(pr *models.PullRequest) {
+	go func() {
+		err := autoMergeQueue.PushFunc(strconv.FormatInt(pr.ID, 10), func() error {
+			log.Trace("Adding PR ID: %d to the pull requests auto merge queue", pr.ID)
+			return nil
+		})
+		if err != nil && err != queue.ErrAlreadyInQueue {
+			log.Error("Error adding prID %d to the pull requests auto merge queue: %v", pr.ID, err)
+		}
+	}()
+}
+
+// handleAutoMerge merges the passed pull requests if they are scheduled to auto merge and all their checks succeed
+func handleAutoMerge(data ...queue.Data) {
+	for _, datum := range data {
+		id, _ := strconv.ParseInt(datum.(string), 10, 64)
+
+		log.Trace("Checking PR ID %d from the pull requests auto merge queue", id)
+
+		if err := autoMerge(id); err != nil {
+			log.Error("autoMerge[%d]: %v", id, err)
+		}
+	}
+}
+
+func autoMerge(pullID int64) error {
+	scheduled, err := models.GetPullAutoMergeByPullID(pullID)
+	if err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	pr, err := models.GetPullRequestByID(pullID)
+	if err != nil {
+		return err
+	}
+	if err = pr.LoadIssue(); err != nil {
+		return err
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return nil
+	}
+	if err = pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if err = scheduled.LoadDoer(); err != nil {
+		return err
+	}
+	doer := scheduled.Doer
+
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, doer)
+	if err != nil {
+		return err
+	}
+	if allowed, err := IsUserAllowedToMerge(pr, perm, doer); err != nil {
+		return err
+	} else if !allowed {
+		return models.UnscheduleAutoMerge(doer, pr, fmt.Sprintf("%s is not allowed to merge this pull request anymore", doer.Name))
+	}
+
+	// wait until the pull request is mergeable and all required checks succeed
+	if !pr.CanAutoMerge() || pr.IsWorkInProgress() {
+		return nil
+	}
+	if err = CheckPRReadyToMerge(pr, false); err != nil {
+		if models.IsErrNotAllowedToMerge(err) {
+			log.Trace("PR ID %d is not ready to be auto merged: %v", pr.ID, err)
+			return nil
+		}
+		return err
+	}
+	if noDeps, err := models.IssueNoDependenciesLeft(pr.Issue); err != nil {
+		return err
+	} else if !noDeps {
+		return nil
+	}
+
+	if _, err = IsSignedIfRequired(pr, doer); err != nil {
+		if models.IsErrWontSign(err) {
+			return models.UnscheduleAutoMerge(doer, pr, fmt.Sprintf("Protected branch %s requires signed commits but this merge would not be signed", pr.BaseBranch))
+		}
+		return err
+	}
+
+	if useMergeQueue, err := IsMergeQueueEnabled(pr); err != nil {
+		return err
+	} else if useMergeQueue {
+		if err = AddToMergeQueue(doer, pr, scheduled.MergeStyle, scheduled.Message); err != nil {
+			log.Error("AddToMergeQueue[%d]: %v", pr.ID, err)
+			return models.UnscheduleAutoMerge(doer, pr, autoMergeFailureReason(err))
+		}
+		return nil
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer baseGitRepo.Close()
+
+	if err = Merge(pr, doer, baseGitRepo, scheduled.MergeStyle, scheduled.Message); err != nil {
+		log.Error("Merge[%d]: %v", pr.ID, err)
+		return models.UnscheduleAutoMerge(doer, pr, autoMergeFailureReason(err))
+	}
+
+	log.Trace("Pull request auto merged: %d", pr.ID)
+	return nil
+}
+
+// autoMergeFailureReason returns a short description of why a scheduled merge failed
+func autoMergeFailureReason(err error) string {
+	switch {
+	case models.IsErrInvalidMergeStyle(err):
+		return "The chosen merge style is not allowed anymore"
+	case models.IsErrMergeConflicts(err), models.IsErrRebaseConflicts(err):
+		return "The pull request has merge conflicts"
+	case models.IsErrMergeUnrelatedHistories(err):
+		return "The head and base branches have unrelated histories"
+	case models.IsErrMergeDivergingFastForwardOnly(err):
+		return "The base branch has diverged from the head branch and can't be fast-forwarded"
+	case git.IsErrPushOutOfDate(err):
+		return "The base branch changed while merging"
+	case git.IsErrPushRejected(err):
+		return "The push of the merge was rejected"
+	}
+	return "The merge failed"
+}
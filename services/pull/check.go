@@ -62,6 +62,8 @@ func checkAndUpdateStatus(pr *models.PullRequest) {
 	if !has {
 		if err := pr.UpdateColsIfNotMerged("merge_base", "status", "conflicted_files", "changed_protected_files"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+		} else if pr.Status == models.PullRequestStatusMergeable {
+			AddToAutoMergeQueue(pr)
 		}
 	}
 }
@@ -262,7 +264,22 @@ func Init() error {
 		return fmt.Errorf("Unable to create pr_patch_checker Queue")
 	}
 
+	autoMergeQueue = queue.CreateUniqueQueue("pr_auto_merge", handleAutoMerge, "").(queue.UniqueQueue)
+
+	if autoMergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_auto_merge Queue")
+	}
+
+	mergeQueue = queue.CreateUniqueQueue("pr_merge_queue", handleMergeQueue, "").(queue.UniqueQueue)
+
+	if mergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+
 	go graceful.GetManager().RunWithShutdownFns(prQueue.Run)
+	go graceful.GetManager().RunWithShutdownFns(autoMergeQueue.Run)
+	go graceful.GetManager().RunWithShutdownFns(mergeQueue.Run)
 	go graceful.GetManager().RunWithShutdownContext(InitializePullRequests)
+	go graceful.GetManager().RunWithShutdownContext(InitializeMergeQueues)
 	return nil
 }
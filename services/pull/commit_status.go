@@ -8,6 +8,7 @@ package pull
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/structs"
 
 	"github.com/pkg/errors"
@@ -128,3 +129,37 @@ func GetPullRequestCommitStatusState(pr *models.PullRequest) (structs.CommitStat
 
 	return MergeRequiredContextsCommitStatus(commitStatuses, pr.ProtectedBranch.StatusCheckContexts), nil
 }
+
+// AddToAutoMergeQueueByCommitStatus adds the pull requests targeting repo which are scheduled to auto merge
+// and whose head is sha to the auto merge queue, it's called once a commit status has been created for sha
+func AddToAutoMergeQueueByCommitStatus(repo *models.Repository, sha string) error {
+	scheduled, err := models.GetPullAutoMergesByBaseRepoID(repo.ID)
+	if err != nil {
+		return errors.Wrap(err, "GetPullAutoMergesByBaseRepoID")
+	}
+	if len(scheduled) == 0 {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return errors.Wrap(err, "OpenRepository")
+	}
+	defer gitRepo.Close()
+
+	for _, m := range scheduled {
+		pr, err := models.GetPullRequestByID(m.PullID)
+		if err != nil {
+			return errors.Wrap(err, "GetPullRequestByID")
+		}
+		headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			log.Error("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+			continue
+		}
+		if headCommitID == sha {
+			AddToAutoMergeQueue(pr)
+		}
+	}
+	return nil
+}
@@ -55,11 +55,18 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
 	}()
 
-	pr.MergedCommitID, err = rawMerge(pr, doer, mergeStyle, message)
+	mergeCommitID, err := rawMerge(pr, doer, mergeStyle, message, pr.BaseBranch)
 	if err != nil {
 		return err
 	}
 
+	return markMerged(pr, doer, mergeCommitID)
+}
+
+// markMerged records that pr was merged by doer as mergeCommitID once the base branch has been updated,
+// notifies about it and resolves the cross references of the pull request
+func markMerged(pr *models.PullRequest, doer *models.User, mergeCommitID string) error {
+	pr.MergedCommitID = mergeCommitID
 	pr.MergedUnix = timeutil.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
@@ -110,7 +117,8 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 }
 
 // rawMerge perform the merge operation without changing any pull information in database
-func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string) (string, error) {
+// and pushes the result to targetBranch of the base repository
+func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message, targetBranch string) (string, error) {
 	err := git.LoadGitVersion()
 	if err != nil {
 		log.Error("git.LoadGitVersion: %v", err)
@@ -251,6 +259,13 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 			log.Error("Unable to make final commit: %v", err)
 			return "", err
 		}
+	case models.MergeStyleFastForwardOnly:
+		// No commit is created so the commits of the head branch are kept exactly as they were authored and signed
+		cmd := git.NewCommand("merge", "--ff-only", trackingBranch)
+		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
+			log.Error("Unable to fast-forward base to tracking: %v", err)
+			return "", err
+		}
 	case models.MergeStyleRebase:
 		fallthrough
 	case models.MergeStyleRebaseMerge:
@@ -411,7 +426,12 @@ func rawMerge(pr *models.PullRequest, doer *models.User, mergeStyle models.Merge
 	)
 
 	// Push back to upstream.
-	if err := git.NewCommand("push", "origin", baseBranch+":refs/heads/"+pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
+	refspec := baseBranch + ":" + git.BranchPrefix + targetBranch
+	if targetBranch != pr.BaseBranch {
+		// The target is a scratch branch (e.g. of the merge queue) which is rebuilt from scratch every time
+		refspec = "+" + refspec
+	}
+	if err := git.NewCommand("push", "origin", refspec).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, &outbuf, &errbuf); err != nil {
 		if strings.Contains(errbuf.String(), "non-fast-forward") {
 			return "", &git.ErrPushOutOfDate{
 				StdOut: outbuf.String(),
@@ -464,6 +484,13 @@ func runMergeCommand(pr *models.PullRequest, mergeStyle models.MergeStyle, cmd *
 				StdErr: errbuf.String(),
 				Err:    err,
 			}
+		} else if mergeStyle == models.MergeStyleFastForwardOnly && strings.Contains(errbuf.String(), "Not possible to fast-forward") {
+			log.Debug("MergeDivergingFastForwardOnly [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			return models.ErrMergeDivergingFastForwardOnly{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
 		} else if strings.Contains(errbuf.String(), "refusing to merge unrelated histories") {
 			log.Debug("MergeUnrelatedHistories [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return models.ErrMergeUnrelatedHistories{
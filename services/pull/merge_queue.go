@@ -0,0 +1,326 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// mergeQueue represents a queue to process the merge queues of protected branches,
+// its items are "<repoID>:<branch>" keys
+var mergeQueue queue.UniqueQueue
+
+// MergeQueueBranchPrefix is the prefix of the branches the merge commits of the merge queue are pushed to
+const MergeQueueBranchPrefix = "gitea-merge-queue/"
+
+// mergeQueueBranchName returns the name of the branch the merge commit of pr is pushed to while it is tested
+func mergeQueueBranchName(pr *models.PullRequest) string {
+	return fmt.Sprintf("%s%s/pr-%d", MergeQueueBranchPrefix, pr.BaseBranch, pr.Index)
+}
+
+// IsMergeQueueEnabled returns whether merges of pr have to go through the merge queue of its base branch
+func IsMergeQueueEnabled(pr *models.PullRequest) (bool, error) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return false, err
+	}
+	return pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue, nil
+}
+
+// AddToMergeQueue adds a pull request to the merge queue of its base branch, it will be merged by doer
+// once the pull requests ahead of it are merged and the required checks succeed on its merge commit
+func AddToMergeQueue(doer *models.User, pr *models.PullRequest, mergeStyle models.MergeStyle, message string) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	if mergeStyle == models.MergeStyleManuallyMerged || !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	if err := models.AddToMergeQueue(doer, pr, mergeStyle, message); err != nil {
+		return err
+	}
+
+	addToMergeQueueQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue on request of doer
+func RemoveFromMergeQueue(doer *models.User, pr *models.PullRequest) error {
+	return ejectFromMergeQueue(doer, pr, "")
+}
+
+// AddToMergeQueueByCommitStatus checks the merge queue testing sha again,
+// it's called once a commit status has been created for sha
+func AddToMergeQueueByCommitStatus(repo *models.Repository, sha string) error {
+	entry, err := models.GetMergeQueueEntryByMergeCommit(repo.ID, sha)
+	if err != nil {
+		return err
+	} else if entry != nil {
+		addToMergeQueueQueue(entry.RepoID, entry.BaseBranch)
+	}
+	return nil
+}
+
+// removeFromMergeQueueOnPush ejects pr from the merge queue once new commits are pushed to its head branch,
+// they have neither been reviewed nor are they part of the merge commit being tested
+func removeFromMergeQueueOnPush(doer *models.User, pr *models.PullRequest) {
+	if _, err := models.GetMergeQueueEntryByPullID(pr.ID); err != nil {
+		if !models.IsErrMergeQueueEntryNotExist(err) {
+			log.Error("GetMergeQueueEntryByPullID[%d]: %v", pr.ID, err)
+		}
+		return
+	}
+	if err := ejectFromMergeQueue(doer, pr, "New commits were pushed to the pull request"); err != nil {
+		log.Error("ejectFromMergeQueue[%d]: %v", pr.ID, err)
+	}
+}
+
+func addToMergeQueueQueue(repoID int64, branch string) {
+	key := strconv.FormatInt(repoID, 10) + ":" + branch
+	go func() {
+		err := mergeQueue.PushFunc(key, func() error {
+			log.Trace("Adding %s to the merge queue queue", key)
+			return nil
+		})
+		if err != nil && err != queue.ErrAlreadyInQueue {
+			log.Error("Error adding %s to the merge queue queue: %v", key, err)
+		}
+	}()
+}
+
+// InitializeMergeQueues resumes processing the merge queues of all the branches with queued pull requests
+func InitializeMergeQueues(ctx context.Context) {
+	entries, err := models.GetMergeQueueEntries()
+	if err != nil {
+		log.Error("GetMergeQueueEntries: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			addToMergeQueueQueue(entry.RepoID, entry.BaseBranch)
+		}
+	}
+}
+
+// handleMergeQueue advances the merge queues of the passed branches
+func handleMergeQueue(data ...queue.Data) {
+	for _, datum := range data {
+		key := datum.(string)
+		idx := strings.IndexByte(key, ':')
+		if idx < 0 {
+			log.Error("Invalid merge queue key: %s", key)
+			continue
+		}
+		repoID, _ := strconv.ParseInt(key[:idx], 10, 64)
+		branch := key[idx+1:]
+
+		log.Trace("Processing merge queue of %s", key)
+
+		if err := processMergeQueue(repoID, branch); err != nil {
+			log.Error("processMergeQueue[%s]: %v", key, err)
+		}
+	}
+}
+
+// processMergeQueue moves the head of the merge queue of a branch one step forward: its merge commit is built
+// and pushed for testing, and once the required checks succeed on it the branch is fast-forwarded to it
+func processMergeQueue(repoID int64, branch string) error {
+	entry, err := models.GetMergeQueueHead(repoID, branch)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	pr, err := models.GetPullRequestByID(entry.PullID)
+	if err != nil {
+		return err
+	}
+	if err = pr.LoadBaseRepo(); err != nil {
+		return err
+	}
+	if err = entry.LoadDoer(); err != nil {
+		return err
+	}
+	doer := entry.Doer
+
+	if enabled, err := IsMergeQueueEnabled(pr); err != nil {
+		return err
+	} else if !enabled {
+		return ejectFromMergeQueue(doer, pr, fmt.Sprintf("The merge queue of branch %s was disabled", pr.BaseBranch))
+	}
+
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, doer)
+	if err != nil {
+		return err
+	}
+	if allowed, err := IsUserAllowedToMerge(pr, perm, doer); err != nil {
+		return err
+	} else if !allowed {
+		return ejectFromMergeQueue(doer, pr, fmt.Sprintf("%s is not allowed to merge this pull request anymore", doer.Name))
+	}
+
+	switch entry.Status {
+	case models.MergeQueueEntryStatusWaiting:
+		sha, err := rawMerge(pr, doer, entry.MergeStyle, entry.Message, mergeQueueBranchName(pr))
+		if err != nil {
+			log.Error("rawMerge[%d]: %v", pr.ID, err)
+			return ejectFromMergeQueue(doer, pr, autoMergeFailureReason(err))
+		}
+
+		entry.Status = models.MergeQueueEntryStatusTesting
+		entry.MergeCommitSHA = sha
+		if err = models.UpdateMergeQueueEntryCols(entry, "status", "merge_commit_sha"); err != nil {
+			return err
+		}
+
+		// the merge commit might not need any checks to succeed
+		addToMergeQueueQueue(repoID, branch)
+		return nil
+
+	case models.MergeQueueEntryStatusTesting:
+		var requiredContexts []string
+		if pr.ProtectedBranch.EnableStatusCheck {
+			requiredContexts = pr.ProtectedBranch.StatusCheckContexts
+		}
+		commitStatuses, err := models.GetLatestCommitStatus(repoID, entry.MergeCommitSHA, models.ListOptions{})
+		if err != nil {
+			return err
+		}
+
+		switch state := MergeRequiredContextsCommitStatus(commitStatuses, requiredContexts); {
+		case state.IsPending():
+			return nil
+		case state.IsError(), state.IsFailure():
+			return ejectFromMergeQueue(doer, pr, "The required checks did not succeed on the merge commit")
+		}
+
+		if err = fastForwardToMergeQueueCommit(pr, doer, entry.MergeCommitSHA); err != nil {
+			if git.IsErrPushOutOfDate(err) {
+				// the branch was updated outside of the queue, the merge commit has to be built again
+				deleteMergeQueueBranch(pr)
+				entry.Status = models.MergeQueueEntryStatusWaiting
+				entry.MergeCommitSHA = ""
+				if err = models.UpdateMergeQueueEntryCols(entry, "status", "merge_commit_sha"); err != nil {
+					return err
+				}
+				addToMergeQueueQueue(repoID, branch)
+				return nil
+			}
+			log.Error("fastForwardToMergeQueueCommit[%d]: %v", pr.ID, err)
+			return ejectFromMergeQueue(doer, pr, autoMergeFailureReason(err))
+		}
+		deleteMergeQueueBranch(pr)
+
+		// marking the pull request as merged closes it, which removes it from the queue
+		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
+		if err = markMerged(pr, doer, entry.MergeCommitSHA); err != nil {
+			log.Error("markMerged[%d]: %v", pr.ID, err)
+		}
+		log.Trace("Pull request merged by the merge queue: %d", pr.ID)
+
+		addToMergeQueueQueue(repoID, branch)
+		return nil
+	}
+
+	return fmt.Errorf("unknown merge queue entry status %d", entry.Status)
+}
+
+// ejectFromMergeQueue removes pr from the merge queue, cleans up its merge commit and
+// lets the next pull request of the queue move forward
+func ejectFromMergeQueue(doer *models.User, pr *models.PullRequest, reason string) error {
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		return err
+	}
+	if entry.Status == models.MergeQueueEntryStatusTesting {
+		deleteMergeQueueBranch(pr)
+	}
+
+	if err = models.RemoveFromMergeQueue(doer, pr, reason); err != nil {
+		return err
+	}
+
+	addToMergeQueueQueue(entry.RepoID, entry.BaseBranch)
+	return nil
+}
+
+// fastForwardToMergeQueueCommit updates the base branch of pr to the tested merge commit, the push is refused
+// if the branch has moved in the meantime
+func fastForwardToMergeQueueCommit(pr *models.PullRequest, doer *models.User, sha string) error {
+	if err := pr.LoadHeadRepo(); err != nil {
+		return err
+	}
+
+	headUser := doer
+	if pr.HeadRepo != nil {
+		if err := pr.HeadRepo.GetOwner(); err == nil {
+			headUser = pr.HeadRepo.Owner
+		} else if !models.IsErrUserNotExist(err) {
+			return err
+		}
+	}
+
+	env := models.FullPushingEnvironment(
+		headUser,
+		doer,
+		pr.BaseRepo,
+		pr.BaseRepo.Name,
+		pr.ID,
+	)
+
+	var outbuf, errbuf strings.Builder
+	if err := git.NewCommand("push", ".", sha+":"+git.BranchPrefix+pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, pr.BaseRepo.RepoPath(), &outbuf, &errbuf); err != nil {
+		if strings.Contains(errbuf.String(), "non-fast-forward") || strings.Contains(errbuf.String(), "[rejected]") {
+			return &git.ErrPushOutOfDate{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
+		} else if strings.Contains(errbuf.String(), "! [remote rejected]") {
+			err := &git.ErrPushRejected{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
+			err.GenerateMessage()
+			return err
+		}
+		return fmt.Errorf("git push: %s", errbuf.String())
+	}
+	return nil
+}
+
+// deleteMergeQueueBranch removes the branch the merge commit of pr was pushed to for testing
+func deleteMergeQueueBranch(pr *models.PullRequest) {
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", pr.BaseRepo.RepoPath(), err)
+		return
+	}
+	defer gitRepo.Close()
+
+	name := mergeQueueBranchName(pr)
+	if !gitRepo.IsBranchExist(name) {
+		return
+	}
+	if err := gitRepo.DeleteBranch(name, git.DeleteBranchOptions{Force: true}); err != nil {
+		log.Error("DeleteBranch[%s]: %v", name, err)
+	}
+}
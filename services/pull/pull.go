@@ -263,6 +263,7 @@ func AddTestPullRequestTask(doer *models.User, repoID int64, branch string, isSy
 						if err := models.MarkReviewsAsNotStale(pr.IssueID, newCommitID); err != nil {
 							log.Error("MarkReviewsAsNotStale: %v", err)
 						}
+						removeFromMergeQueueOnPush(doer, pr)
 						divergence, err := GetDiverging(pr)
 						if err != nil {
 							log.Error("GetDiverging: %v", err)
@@ -253,6 +253,10 @@ func SubmitReview(doer *models.User, gitRepo *git.Repository, issue *models.Issu
 
 	notification.NotifyPullRequestReview(pr, review, comm, mentions)
 
+	if reviewType == models.ReviewTypeApprove {
+		AddToAutoMergeQueue(pr)
+	}
+
 	for _, lines := range review.CodeComments {
 		for _, comments := range lines {
 			for _, codeComment := range comments {
@@ -0,0 +1,127 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"code.gitea.io/gitea/models"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// CodeOptions represents the options of a code search across repositories
+type CodeOptions struct {
+	Doer *models.User
+	// OwnerID restricts the search to the repositories of a user or an organization
+	OwnerID int64
+	// RepoIDs restricts the search to these repositories if not empty, e.g. to those of an access token
+	RepoIDs []int64
+	// Query is the keyword, possibly with qualifiers
+	Query string
+	// Language is used unless the query has a `lang:` qualifier
+	Language string
+	IsMatch  bool
+	Page     int
+	PageSize int
+}
+
+// CodeResult represents the results of a code search across repositories
+type CodeResult struct {
+	Query     *code_indexer.SearchQuery
+	Language  string
+	Total     int
+	Results   []*code_indexer.Result
+	Languages []*code_indexer.SearchResultLanguages
+	// Repos holds the repositories of the results
+	Repos map[int64]*models.Repository
+}
+
+// Code searches the code of all repositories whose code the doer can read
+func Code(opts *CodeOptions) (*CodeResult, error) {
+	query := code_indexer.ParseSearchQuery(opts.Query)
+	result := &CodeResult{
+		Query:    query,
+		Language: query.Language,
+		Repos:    make(map[int64]*models.Repository),
+	}
+	if len(result.Language) == 0 {
+		result.Language = opts.Language
+	}
+	if len(query.Keyword) == 0 {
+		return result, nil
+	}
+
+	// site admins can read all repositories, so they only need to be looked up if the search is restricted
+	isAdmin := opts.Doer != nil && opts.Doer.IsAdmin
+	var repoIDs, branchRepoIDs []int64
+	if !isAdmin || opts.OwnerID > 0 || len(opts.RepoIDs) > 0 || len(query.Repos) > 0 || len(query.Branch) > 0 {
+		ids, err := models.FindUserAccessibleRepoIDsByOwnerAndNames(opts.Doer, models.UnitTypeCode, opts.OwnerID, query.Repos)
+		if err != nil {
+			return nil, err
+		}
+		if len(opts.RepoIDs) > 0 {
+			allowed := ids[:0]
+			for _, id := range ids {
+				if util.IsInt64InSlice(id, opts.RepoIDs) {
+					allowed = append(allowed, id)
+				}
+			}
+			ids = allowed
+		}
+		repos, err := models.GetRepositoriesMapByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		repoIDs = make([]int64, 0, len(repos))
+		for id, repo := range repos {
+			isBranchRepo := len(query.Branch) > 0 && repo.DefaultBranch != query.Branch
+			if isBranchRepo && !code_indexer.IsIndexedBranch(repo, query.Branch) {
+				continue
+			}
+			repoIDs = append(repoIDs, id)
+			if isBranchRepo {
+				branchRepoIDs = append(branchRepoIDs, id)
+			}
+			result.Repos[id] = repo
+		}
+		if len(repoIDs) == 0 {
+			return result, nil
+		}
+	}
+
+	var err error
+	result.Total, result.Results, result.Languages, err = code_indexer.PerformSearch(&code_indexer.SearchOptions{
+		RepoIDs:       repoIDs,
+		Keyword:       query.Keyword,
+		Language:      result.Language,
+		IsMatch:       opts.IsMatch,
+		Paths:         query.Paths,
+		Extensions:    query.Extensions,
+		Branch:        query.Branch,
+		BranchRepoIDs: branchRepoIDs,
+		Page:          opts.Page,
+		PageSize:      opts.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var loadRepoIDs []int64
+	for _, res := range result.Results {
+		if _, ok := result.Repos[res.RepoID]; !ok && !util.IsInt64InSlice(res.RepoID, loadRepoIDs) {
+			loadRepoIDs = append(loadRepoIDs, res.RepoID)
+		}
+	}
+	if len(loadRepoIDs) > 0 {
+		repos, err := models.GetRepositoriesMapByIDs(loadRepoIDs)
+		if err != nil {
+			return nil, err
+		}
+		for id, repo := range repos {
+			result.Repos[id] = repo
+		}
+	}
+	return result, nil
+}
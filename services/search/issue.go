@@ -0,0 +1,174 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"code.gitea.io/gitea/models"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// IssueFilter represents an issue search query with its users and milestones resolved
+type IssueFilter struct {
+	// Keyword is the query without its qualifiers, to be searched for in the issue indexer
+	Keyword      string
+	IsClosed     util.OptionalBool
+	IsPull       util.OptionalBool
+	PosterID     int64
+	AssigneeID   int64
+	MentionedID  int64
+	MilestoneIDs []int64
+	LabelNames   []string
+	SortType     string
+	// IsEmpty is set if no issue can match the query, e.g. because of an unknown user
+	IsEmpty bool
+}
+
+// ParseIssueQuery parses an issue search query. `@me` refers to the doer, and milestones are
+// looked up by name in the given repositories, or in all repositories if none is given.
+func ParseIssueQuery(doer *models.User, repoIDs []int64, query string) (*IssueFilter, error) {
+	q := issue_indexer.ParseSearchQuery(query)
+	filter := &IssueFilter{
+		Keyword:    q.Keyword,
+		LabelNames: q.Labels,
+		SortType:   q.SortType,
+	}
+
+	switch q.State {
+	case "open":
+		filter.IsClosed = util.OptionalBoolFalse
+	case "closed":
+		filter.IsClosed = util.OptionalBoolTrue
+	}
+	switch q.Type {
+	case "issue":
+		filter.IsPull = util.OptionalBoolFalse
+	case "pr":
+		filter.IsPull = util.OptionalBoolTrue
+	}
+
+	for _, user := range []struct {
+		name string
+		id   *int64
+	}{
+		{q.Author, &filter.PosterID},
+		{q.Assignee, &filter.AssigneeID},
+		{q.Mentions, &filter.MentionedID},
+	} {
+		if len(user.name) == 0 {
+			continue
+		}
+		id, err := issueQueryUserID(doer, user.name)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			filter.IsEmpty = true
+		}
+		*user.id = id
+	}
+
+	if len(q.Milestone) > 0 {
+		ids, err := models.GetMilestoneIDsByRepoIDsAndName(repoIDs, q.Milestone)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			filter.IsEmpty = true
+		}
+		filter.MilestoneIDs = ids
+	}
+	return filter, nil
+}
+
+// issueQueryUserID returns the id of the named user, or 0 if there is no such user
+func issueQueryUserID(doer *models.User, name string) (int64, error) {
+	if name == "@me" {
+		if doer == nil {
+			return 0, nil
+		}
+		return doer.ID, nil
+	}
+	user, err := models.GetUserByName(name)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// Apply narrows down the options by the filter, its state and sort type taking precedence.
+// It returns false if no issue can match both, e.g. because they ask for different authors.
+func (f *IssueFilter) Apply(opts *models.IssuesOptions) bool {
+	if f.IsEmpty {
+		return false
+	}
+
+	if f.IsClosed != util.OptionalBoolNone {
+		opts.IsClosed = f.IsClosed
+	}
+	if f.IsPull != util.OptionalBoolNone {
+		if opts.IsPull != util.OptionalBoolNone && opts.IsPull != f.IsPull {
+			return false
+		}
+		opts.IsPull = f.IsPull
+	}
+
+	for _, id := range []struct {
+		filter int64
+		opt    *int64
+	}{
+		{f.PosterID, &opts.PosterID},
+		{f.AssigneeID, &opts.AssigneeID},
+		{f.MentionedID, &opts.MentionedID},
+	} {
+		if id.filter == 0 {
+			continue
+		}
+		if *id.opt != 0 && *id.opt != id.filter {
+			return false
+		}
+		*id.opt = id.filter
+	}
+
+	if len(f.MilestoneIDs) > 0 {
+		if len(opts.MilestoneIDs) > 0 {
+			milestoneIDs := make([]int64, 0, len(opts.MilestoneIDs))
+			for _, id := range opts.MilestoneIDs {
+				if util.IsInt64InSlice(id, f.MilestoneIDs) {
+					milestoneIDs = append(milestoneIDs, id)
+				}
+			}
+			if len(milestoneIDs) == 0 {
+				return false
+			}
+			opts.MilestoneIDs = milestoneIDs
+		} else {
+			opts.MilestoneIDs = f.MilestoneIDs
+		}
+	}
+
+	opts.RequiredLabelNames = append(opts.RequiredLabelNames, f.LabelNames...)
+	if len(f.SortType) > 0 {
+		opts.SortType = f.SortType
+	}
+	return true
+}
+
+// SplitIssueMatches splits the matches of the issue indexer into the issue ids and the anchors
+// of the comments which matched, by issue id
+func SplitIssueMatches(matches []issue_indexer.Match) ([]int64, map[int64]string) {
+	issueIDs := make([]int64, 0, len(matches))
+	commentAnchors := make(map[int64]string)
+	for _, match := range matches {
+		issueIDs = append(issueIDs, match.ID)
+		if match.CommentID > 0 {
+			commentAnchors[match.ID] = models.CommentHashTag(match.CommentID)
+		}
+	}
+	return issueIDs, commentAnchors
+}
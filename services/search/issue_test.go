@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIssueQuery(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	filter, err := ParseIssueQuery(doer, []int64{1}, `is:closed is:issue author:user1 assignee:@me milestone:milestone1 label:label1 sort:updated bug`)
+	assert.NoError(t, err)
+	assert.Equal(t, &IssueFilter{
+		Keyword:      "bug",
+		IsClosed:     util.OptionalBoolTrue,
+		IsPull:       util.OptionalBoolFalse,
+		PosterID:     1,
+		AssigneeID:   2,
+		MilestoneIDs: []int64{1},
+		LabelNames:   []string{"label1"},
+		SortType:     "recentupdate",
+	}, filter)
+
+	filter, err = ParseIssueQuery(nil, nil, "assignee:@me")
+	assert.NoError(t, err)
+	assert.True(t, filter.IsEmpty)
+
+	filter, err = ParseIssueQuery(doer, nil, "mentions:user_does_not_exist")
+	assert.NoError(t, err)
+	assert.True(t, filter.IsEmpty)
+
+	filter, err = ParseIssueQuery(doer, []int64{2}, "milestone:milestone1")
+	assert.NoError(t, err)
+	assert.True(t, filter.IsEmpty)
+}
+
+func TestIssueFilterApply(t *testing.T) {
+	filter := &IssueFilter{
+		IsClosed:     util.OptionalBoolTrue,
+		PosterID:     1,
+		MilestoneIDs: []int64{1, 2},
+		LabelNames:   []string{"bug"},
+		SortType:     "oldest",
+	}
+
+	opts := &models.IssuesOptions{
+		IsClosed:           util.OptionalBoolFalse,
+		IsPull:             util.OptionalBoolTrue,
+		PosterID:           1,
+		MilestoneIDs:       []int64{2, 3},
+		RequiredLabelNames: []string{"feature"},
+		SortType:           "newest",
+	}
+	assert.True(t, filter.Apply(opts))
+	assert.True(t, opts.IsClosed.IsTrue())
+	assert.True(t, opts.IsPull.IsTrue())
+	assert.EqualValues(t, 1, opts.PosterID)
+	assert.Equal(t, []int64{2}, opts.MilestoneIDs)
+	assert.Equal(t, []string{"feature", "bug"}, opts.RequiredLabelNames)
+	assert.Equal(t, "oldest", opts.SortType)
+
+	assert.False(t, filter.Apply(&models.IssuesOptions{PosterID: 2}))
+	assert.False(t, filter.Apply(&models.IssuesOptions{MilestoneIDs: []int64{3}}))
+	assert.False(t, (&IssueFilter{IsPull: util.OptionalBoolFalse}).Apply(&models.IssuesOptions{IsPull: util.OptionalBoolTrue}))
+	assert.False(t, (&IssueFilter{IsEmpty: true}).Apply(&models.IssuesOptions{}))
+}
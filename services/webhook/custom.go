@@ -0,0 +1,543 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	jsoniter "github.com/json-iterator/go"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	customPayloadSizeLimit = 1024 * 1024
+	// customTemplateMaxRangeDepth is how deep ranges may be nested in a custom template
+	customTemplateMaxRangeDepth = 3
+	// customTemplateMaxLength is how long the text of a custom template may be
+	customTemplateMaxLength = 64 * 1024
+	// customTemplateMaxSteps is how many range iterations, writes and function calls executing a custom
+	// template may take
+	customTemplateMaxSteps = 100000
+	// customTemplateMaxFuncBytes is how many bytes the functions of a custom template may produce in one
+	// execution, each of their results is limited to customPayloadSizeLimit
+	customTemplateMaxFuncBytes = 16 * customPayloadSizeLimit
+	// customTemplateTimeout is how long executing a custom template may take
+	customTemplateTimeout = 5 * time.Second
+	// customTemplateStepFunc is the function counting the steps of a template, it is only called by
+	// the actions inserted into ranges, custom templates themselves can't call it
+	customTemplateStepFunc = "customTemplateStep"
+)
+
+// CustomMeta contains the custom webhook metadata
+type CustomMeta struct {
+	// Template is a text/template rendering the request body
+	Template    string `json:"template"`
+	ContentType string `json:"content_type"`
+	// Headers are additional request headers, one `Name: Value` per line
+	Headers string `json:"headers"`
+}
+
+// GetCustomHook returns custom metadata
+func GetCustomHook(w *models.Webhook) *CustomMeta {
+	s := &CustomMeta{}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetCustomHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// Validate checks the template, content type and headers of a custom webhook
+func (m *CustomMeta) Validate() error {
+	if _, err := parseCustomTemplate(m.Template); err != nil {
+		return err
+	}
+	if _, _, err := mime.ParseMediaType(m.ContentType); err != nil {
+		return ErrInvalidCustomContentType{ContentType: m.ContentType}
+	}
+	_, err := parseCustomHeaders(m.Headers)
+	return err
+}
+
+// ErrCustomTemplate represents an error parsing or executing the template of a custom webhook
+type ErrCustomTemplate struct {
+	Err error
+}
+
+// IsErrCustomTemplate checks if an error is a ErrCustomTemplate.
+func IsErrCustomTemplate(err error) bool {
+	_, ok := err.(ErrCustomTemplate)
+	return ok
+}
+
+func (err ErrCustomTemplate) Error() string {
+	return fmt.Sprintf("custom webhook template: %v", err.Err)
+}
+
+// ErrInvalidCustomContentType represents an invalid content type of a custom webhook
+type ErrInvalidCustomContentType struct {
+	ContentType string
+}
+
+// IsErrInvalidCustomContentType checks if an error is a ErrInvalidCustomContentType.
+func IsErrInvalidCustomContentType(err error) bool {
+	_, ok := err.(ErrInvalidCustomContentType)
+	return ok
+}
+
+func (err ErrInvalidCustomContentType) Error() string {
+	return fmt.Sprintf("invalid custom webhook content type [content_type: %s]", err.ContentType)
+}
+
+// ErrInvalidCustomHeader represents a malformed or reserved header of a custom webhook
+type ErrInvalidCustomHeader struct {
+	Header string
+}
+
+// IsErrInvalidCustomHeader checks if an error is a ErrInvalidCustomHeader.
+func IsErrInvalidCustomHeader(err error) bool {
+	_, ok := err.(ErrInvalidCustomHeader)
+	return ok
+}
+
+func (err ErrInvalidCustomHeader) Error() string {
+	return fmt.Sprintf("invalid custom webhook header [header: %s]", err.Header)
+}
+
+// newCustomTemplateFuncs returns the functions available to custom templates besides the builtins of
+// text/template. Like the builtins, they only operate on their arguments. The builtins building strings
+// are replaced, so that the strings all functions produce are counted by the limiter.
+func newCustomTemplateFuncs(l *customTemplateLimiter) template.FuncMap {
+	return template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			json := jsoniter.ConfigCompatibleWithStandardLibrary
+			data, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			return l.result(string(data))
+		},
+		"upper": func(v interface{}) (string, error) {
+			return l.result(strings.ToUpper(customTemplateString(v)))
+		},
+		"lower": func(v interface{}) (string, error) {
+			return l.result(strings.ToLower(customTemplateString(v)))
+		},
+		"trim": func(v interface{}) (string, error) {
+			return l.result(strings.TrimSpace(customTemplateString(v)))
+		},
+		"replace": func(old, new string, v interface{}) (string, error) {
+			s := customTemplateString(v)
+			// the result is counted before it is built, as it can be much longer than its arguments
+			if err := l.produce(len(s) + strings.Count(s, old)*(len(new)-len(old))); err != nil {
+				return "", err
+			}
+			return strings.ReplaceAll(s, old, new), nil
+		},
+		"truncate": func(length int, v interface{}) (string, error) {
+			s := customTemplateString(v)
+			if utf8.RuneCountInString(s) > length {
+				s = string([]rune(s)[:length])
+			}
+			return l.result(s)
+		},
+		"default": func(def, v interface{}) interface{} {
+			if v == nil || v == "" {
+				return def
+			}
+			return v
+		},
+		// the arguments of the builtins joining them are counted before they are joined, as there
+		// can be many of them
+		"print": func(args ...interface{}) (string, error) {
+			if err := l.produce(customTemplateArgsLen(args)); err != nil {
+				return "", err
+			}
+			return fmt.Sprint(args...), nil
+		},
+		"println": func(args ...interface{}) (string, error) {
+			if err := l.produce(customTemplateArgsLen(args)); err != nil {
+				return "", err
+			}
+			return fmt.Sprintln(args...), nil
+		},
+		"printf": func(format string, args ...interface{}) (string, error) {
+			s, err := customTemplatePrintf(format, args...)
+			if err != nil {
+				return "", err
+			}
+			return l.result(s)
+		},
+		"html": func(args ...interface{}) (string, error) {
+			if err := l.produce(customTemplateArgsLen(args)); err != nil {
+				return "", err
+			}
+			return l.result(template.HTMLEscaper(args...))
+		},
+		"js": func(args ...interface{}) (string, error) {
+			if err := l.produce(customTemplateArgsLen(args)); err != nil {
+				return "", err
+			}
+			return l.result(template.JSEscaper(args...))
+		},
+		"urlquery": func(args ...interface{}) (string, error) {
+			if err := l.produce(customTemplateArgsLen(args)); err != nil {
+				return "", err
+			}
+			return l.result(template.URLQueryEscaper(args...))
+		},
+	}
+}
+
+func customTemplateString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// customTemplateArgsLen returns how long the arguments of a function are as strings, including a
+// separator after each of them
+func customTemplateArgsLen(args []interface{}) int {
+	n := 0
+	for _, arg := range args {
+		n += len(customTemplateString(arg)) + 1
+	}
+	return n
+}
+
+// customTemplatePrintf formats like fmt.Sprintf, but only supports verbs without flags, width, precision
+// or argument indexes, like %s or %d, so that the result can't get much longer than the format and its
+// arguments and is stopped once it exceeds customPayloadSizeLimit
+func customTemplatePrintf(format string, args ...interface{}) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return "", errors.New("printf format ends with %")
+		}
+		verb := format[i]
+		switch {
+		case verb == '%':
+			b.WriteByte('%')
+		case 'a' <= verb && verb <= 'z' || 'A' <= verb && verb <= 'Z':
+			if len(args) == 0 {
+				return "", fmt.Errorf("printf is missing the argument of %%%c", verb)
+			}
+			b.WriteString(fmt.Sprintf("%"+string(verb), args[0]))
+			args = args[1:]
+		default:
+			return "", fmt.Errorf("printf only supports verbs without flags, width or precision, not %%%c", verb)
+		}
+		if b.Len() > customPayloadSizeLimit {
+			return "", fmt.Errorf("printf result exceeds %d bytes", customPayloadSizeLimit)
+		}
+	}
+	return b.String(), nil
+}
+
+// customTemplateStepNode is the action inserted at the start of each range, counting its iterations
+var customTemplateStepNode = template.Must(template.New("step").
+	Funcs(template.FuncMap{customTemplateStepFunc: func() string { return "" }}).
+	Parse("{{" + customTemplateStepFunc + "}}")).Tree.Root.Nodes[0]
+
+// parseCustomTemplate parses the template of a custom webhook. Defining or calling templates is not
+// allowed, as they could call each other recursively, and only fields may be ranged over, nested up to
+// customTemplateMaxRangeDepth. The ranges count their iterations, see renderCustomTemplate.
+func parseCustomTemplate(text string) (*template.Template, error) {
+	if len(strings.TrimSpace(text)) == 0 {
+		return nil, ErrCustomTemplate{Err: errors.New("template is empty")}
+	}
+	if len(text) > customTemplateMaxLength {
+		return nil, ErrCustomTemplate{Err: fmt.Errorf("template exceeds %d bytes", customTemplateMaxLength)}
+	}
+	// the functions are bound to the limiter of an execution when the template is rendered
+	funcs := newCustomTemplateFuncs(&customTemplateLimiter{ctx: context.Background()})
+	tpl, err := template.New("custom").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, ErrCustomTemplate{Err: err}
+	}
+	if len(tpl.Templates()) > 1 {
+		return nil, ErrCustomTemplate{Err: errors.New("template must not define other templates")}
+	}
+	if err := checkCustomTemplateNode(tpl.Tree.Root, 0); err != nil {
+		return nil, ErrCustomTemplate{Err: err}
+	}
+	tpl.Funcs(template.FuncMap{customTemplateStepFunc: func() string { return "" }})
+	return tpl, nil
+}
+
+// checkCustomTemplateNode checks the actions of a custom template and inserts the counting of steps
+// into its ranges
+func checkCustomTemplateNode(node parse.Node, rangeDepth int) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkCustomTemplateNode(child, rangeDepth); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return errors.New("template must not call templates")
+	case *parse.IfNode:
+		return checkCustomTemplateBranch(&n.BranchNode, rangeDepth)
+	case *parse.WithNode:
+		return checkCustomTemplateBranch(&n.BranchNode, rangeDepth)
+	case *parse.RangeNode:
+		if rangeDepth >= customTemplateMaxRangeDepth {
+			return fmt.Errorf("ranges must not be nested more than %d deep", customTemplateMaxRangeDepth)
+		}
+		if !isCustomTemplateField(n.Pipe) {
+			return fmt.Errorf("only fields of the payload can be ranged over, not %s", n.Pipe)
+		}
+		if err := checkCustomTemplateNode(n.List, rangeDepth+1); err != nil {
+			return err
+		}
+		if err := checkCustomTemplateNode(n.ElseList, rangeDepth); err != nil {
+			return err
+		}
+		if n.List != nil {
+			n.List.Nodes = append([]parse.Node{customTemplateStepNode}, n.List.Nodes...)
+		}
+	}
+	return nil
+}
+
+func checkCustomTemplateBranch(n *parse.BranchNode, rangeDepth int) error {
+	if err := checkCustomTemplateNode(n.List, rangeDepth); err != nil {
+		return err
+	}
+	return checkCustomTemplateNode(n.ElseList, rangeDepth)
+}
+
+// isCustomTemplateField returns whether a pipeline is only a field, like `.Payload.commits`,
+// `.added` or `$.Payload.commits`, so neither a number, a variable itself nor the result of a function
+func isCustomTemplateField(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1
+	}
+	return false
+}
+
+// customTemplateLimiter stops the execution of a custom template once it took too many steps, its
+// functions produced too many bytes or its context is done
+type customTemplateLimiter struct {
+	ctx   context.Context
+	steps int
+	// size is how many bytes the functions of the template produced so far
+	size int
+}
+
+func (l *customTemplateLimiter) step() error {
+	l.steps++
+	if l.steps > customTemplateMaxSteps {
+		return fmt.Errorf("execution exceeds %d steps", customTemplateMaxSteps)
+	}
+	if err := l.ctx.Err(); err != nil {
+		return fmt.Errorf("execution stopped: %v", err)
+	}
+	return nil
+}
+
+// produce counts a function call producing a string of n bytes, which can't be longer than a body
+func (l *customTemplateLimiter) produce(n int) error {
+	if n > customPayloadSizeLimit {
+		return fmt.Errorf("function result exceeds %d bytes", customPayloadSizeLimit)
+	}
+	l.size += n
+	if l.size > customTemplateMaxFuncBytes {
+		return fmt.Errorf("functions produce more than %d bytes", customTemplateMaxFuncBytes)
+	}
+	return l.step()
+}
+
+// result counts a string produced by a function and returns it
+func (l *customTemplateLimiter) result(s string) (string, error) {
+	if err := l.produce(len(s)); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// customTemplateData is what the template of a custom webhook is executed on
+type customTemplateData struct {
+	// Event is the event as sent in the X-Gitea-Event header, e.g. "issues"
+	Event string
+	// EventType is the more specific type of the event, e.g. "issue_assign"
+	EventType string
+	// Payload is the payload as sent by Gitea webhooks, decoded from JSON so its fields are
+	// accessed by their JSON names and no methods can be called
+	Payload interface{}
+}
+
+// limitedBuffer is a buffer which fails writes beyond its limit, each write is a step of the limiter
+type limitedBuffer struct {
+	buf     bytes.Buffer
+	limit   int
+	limiter *customTemplateLimiter
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if err := b.limiter.step(); err != nil {
+		return 0, err
+	}
+	if b.buf.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("body exceeds %d bytes", b.limit)
+	}
+	return b.buf.Write(p)
+}
+
+// renderCustomTemplate executes the template of a custom webhook on a payload
+func renderCustomTemplate(tpl *template.Template, p api.Payloader, event models.HookEventType) ([]byte, error) {
+	data, err := p.JSONPayload()
+	if err != nil {
+		return nil, err
+	}
+	var payload interface{}
+	decoder := jsoniter.ConfigCompatibleWithStandardLibrary.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	// the payload is shared with the other webhooks, which might have set their secret on it
+	if fields, ok := payload.(map[string]interface{}); ok {
+		delete(fields, "secret")
+	}
+
+	ctx, cancel := context.WithTimeout(graceful.GetManager().ShutdownContext(), customTemplateTimeout)
+	defer cancel()
+	limiter := &customTemplateLimiter{ctx: ctx}
+	funcs := newCustomTemplateFuncs(limiter)
+	funcs[customTemplateStepFunc] = func() (string, error) {
+		return "", limiter.step()
+	}
+	tpl.Funcs(funcs)
+
+	body := &limitedBuffer{limit: customPayloadSizeLimit, limiter: limiter}
+	if err := tpl.Execute(body, &customTemplateData{
+		Event:     event.Event(),
+		EventType: string(event),
+		Payload:   payload,
+	}); err != nil {
+		return nil, ErrCustomTemplate{Err: err}
+	}
+	return body.buf.Bytes(), nil
+}
+
+// CustomPayload is the rendered body of a custom webhook
+type CustomPayload []byte
+
+// SetSecret does nothing, the secret of a custom webhook is only used to sign the body
+func (c CustomPayload) SetSecret(_ string) {}
+
+// JSONPayload returns the rendered body, which is JSON only if the template renders JSON
+func (c CustomPayload) JSONPayload() ([]byte, error) {
+	return c, nil
+}
+
+// GetCustomPayload renders the template of a custom webhook on a payload
+func GetCustomPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	custom := &CustomMeta{}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(meta), custom); err != nil {
+		return nil, errors.New("GetCustomPayload meta json:" + err.Error())
+	}
+
+	tpl, err := parseCustomTemplate(custom.Template)
+	if err != nil {
+		return nil, err
+	}
+	body, err := renderCustomTemplate(tpl, p, event)
+	if err != nil {
+		return nil, err
+	}
+	return CustomPayload(body), nil
+}
+
+// isReservedCustomHeader returns whether a header is set by the delivery itself
+func isReservedCustomHeader(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "content-type", "content-length", "host", "connection", "transfer-encoding":
+		return true
+	}
+	for _, prefix := range []string{"x-gitea-", "x-gogs-", "x-github-", "x-hub-"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCustomHeaders parses the `Name: Value` lines of the headers of a custom webhook
+func parseCustomHeaders(text string) (http.Header, error) {
+	header := http.Header{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		idx := strings.IndexByte(line, ':')
+		if idx <= 0 {
+			return nil, ErrInvalidCustomHeader{Header: line}
+		}
+		name := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(line[idx+1:])
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) || isReservedCustomHeader(name) {
+			return nil, ErrInvalidCustomHeader{Header: name}
+		}
+		header.Add(name, value)
+	}
+	return header, nil
+}
+
+// getCustomHookRequest creates the request of a custom webhook with its content type and headers
+func getCustomHookRequest(t *models.HookTask, w *models.Webhook, method string) (*http.Request, error) {
+	custom := GetCustomHook(w)
+	header, err := parseCustomHeaders(custom.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, t.URL, strings.NewReader(t.PayloadContent))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", custom.ContentType)
+	return req, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func customTestMeta(t *testing.T, template string) string {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	meta, err := json.Marshal(&CustomMeta{Template: template, ContentType: "application/json"})
+	require.NoError(t, err)
+	return string(meta)
+}
+
+func TestGetCustomPayload(t *testing.T) {
+	p := issueTestPayload()
+	p.Secret = "secret of another webhook"
+
+	template := `{"event":"{{.Event}}/{{.EventType}}","text":{{json .Payload.issue.title | upper}},"number":{{.Payload.issue.number}},"secret":"{{.Payload.secret}}"}`
+	pl, err := GetCustomPayload(p, models.HookEventIssueAssign, customTestMeta(t, template))
+	require.NoError(t, err)
+	data, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.Equal(t, `{"event":"issues/issue_assign","text":"CRASH","number":2,"secret":"<no value>"}`, string(data))
+}
+
+func TestGetCustomPayload_Functions(t *testing.T) {
+	p := issueTestPayload()
+	for template, expected := range map[string]string{
+		`{{.Payload.issue.title | truncate 3}}`:                    "cra",
+		`{{.Payload.issue.title | replace "a" "o" | trim}}`:        "crosh",
+		`{{.Payload.issue.missing | default "none"}}`:              "none",
+		`{{.Payload.issue.title | default "none" | lower}}`:        "crash",
+		`{{range .Payload.issue.labels}}{{.name}}{{else}}-{{end}}`: "-",
+		`{{printf "%s: %d%%" .Payload.issue.title 5}}`:             "crash: 5%",
+		`{{print .Payload.issue.title "!" | html}}`:                "crash!",
+		`{{urlquery "a b"}}`:                                       "a+b",
+	} {
+		pl, err := GetCustomPayload(p, models.HookEventIssues, customTestMeta(t, template))
+		require.NoError(t, err, template)
+		assert.EqualValues(t, expected, pl, template)
+	}
+}
+
+func TestGetCustomPayload_Errors(t *testing.T) {
+	p := issueTestPayload()
+
+	_, err := GetCustomPayload(p, models.HookEventIssues, customTestMeta(t, "{{index .Payload.issue.labels 0}}"))
+	assert.True(t, IsErrCustomTemplate(err))
+
+	_, err = GetCustomPayload(p, models.HookEventIssues, customTestMeta(t, "{{range .Payload.issue}}{{.}}{{end}}"))
+	assert.NoError(t, err)
+
+	big := "{{range .Payload.issue}}{{range $.Payload.issue}}" + strings.Repeat("x", customTemplateMaxLength/2) + "{{end}}{{end}}"
+	_, err = GetCustomPayload(p, models.HookEventIssues, customTestMeta(t, big))
+	assert.True(t, IsErrCustomTemplate(err))
+
+	// strings built by functions are limited, whether they are written or only assigned
+	for _, template := range []string{
+		`{{$x := printf "%0999999d" 1}}`,
+		`{{$x := printf "%s%s" "x" .Payload.issue.title}}{{$x = printf "%s%s%s%s" $x $x $x $x}}` + strings.Repeat(`{{$x = printf "%s%s%s%s" $x $x $x $x}}`, 12),
+		`{{$x := print "xxxxxxxxxxxxxxxx"}}` + strings.Repeat(`{{$x = print $x $x}}`, 24),
+		`{{$x := replace "" "xxxxxxxxxxxxxxxx" "xxxxxxxxxxxxxxxx"}}` + strings.Repeat(`{{$x = replace "" $x $x}}`, 4),
+		`{{$x := print "xxxxxxxxxxxxxxxx"}}` + strings.Repeat(`{{$x = html $x $x}}`, 24),
+		`{{$x := print "xxxxxxxxxxxxxxxx"}}` + strings.Repeat(`{{$x = print $x $x}}`, 15) + `{{range .Payload.issue}}{{range $.Payload.issue}}{{$y := print $x}}{{end}}{{end}}`,
+	} {
+		_, err = GetCustomPayload(p, models.HookEventIssues, customTestMeta(t, template))
+		assert.True(t, IsErrCustomTemplate(err), "%s: %v", template[:20], err)
+	}
+}
+
+func TestGetCustomPayload_Loops(t *testing.T) {
+	p := &api.PushPayload{Commits: make([]*api.PayloadCommit, 100)}
+	for i := range p.Commits {
+		p.Commits[i] = &api.PayloadCommit{ID: strconv.Itoa(i)}
+	}
+
+	// a million iterations writing nothing are stopped after the allowed steps
+	start := time.Now()
+	template := "{{range .Payload.commits}}{{range $.Payload.commits}}{{range $.Payload.commits}}{{end}}{{end}}{{end}}"
+	_, err := GetCustomPayload(p, models.HookEventPush, customTestMeta(t, template))
+	assert.True(t, IsErrCustomTemplate(err), "%v", err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+
+	pl, err := GetCustomPayload(p, models.HookEventPush, customTestMeta(t, "{{range .Payload.commits}}{{.id}}{{end}}"))
+	require.NoError(t, err)
+	assert.Len(t, pl, 190)
+}
+
+func TestCustomTemplateLimiter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	limiter := &customTemplateLimiter{ctx: ctx}
+	assert.NoError(t, limiter.step())
+	cancel()
+	assert.Error(t, limiter.step())
+}
+
+func TestCustomMeta_Validate(t *testing.T) {
+	for _, c := range []struct {
+		meta  CustomMeta
+		check func(error) bool
+	}{
+		{CustomMeta{Template: "{{.Event}}", ContentType: "text/plain; charset=utf-8", Headers: "Authorization: Bearer token\n\nX-Custom:value"}, nil},
+		{CustomMeta{Template: " ", ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: "{{.Event", ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: "{{.Event | exec}}", ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: `{{define "a"}}{{template "a"}}{{end}}`, ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: `{{template "custom" .}}`, ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: "{{range 100000000000}}{{end}}", ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: "{{range $}}{{end}}", ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: strings.Repeat("x", customTemplateMaxLength+1), ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: `{{range (slice .Payload.commits 0)}}{{end}}`, ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: "{{range .Payload.commits}}{{range .added}}{{range $.Payload.commits}}{{end}}{{end}}{{end}}", ContentType: "text/plain"}, nil},
+		{CustomMeta{Template: "{{range .Payload.commits}}{{range .added}}{{range $.Payload.commits}}{{range .added}}{{end}}{{end}}{{end}}{{end}}", ContentType: "text/plain"}, IsErrCustomTemplate},
+		{CustomMeta{Template: "{{.Event}}", ContentType: "text plain"}, IsErrInvalidCustomContentType},
+		{CustomMeta{Template: "{{.Event}}", ContentType: "text/plain", Headers: "Authorization"}, IsErrInvalidCustomHeader},
+		{CustomMeta{Template: "{{.Event}}", ContentType: "text/plain", Headers: "X-Gitea-Signature: forged"}, IsErrInvalidCustomHeader},
+		{CustomMeta{Template: "{{.Event}}", ContentType: "text/plain", Headers: "Content-Type: text/html"}, IsErrInvalidCustomHeader},
+		{CustomMeta{Template: "{{.Event}}", ContentType: "text/plain", Headers: "Bad Name: value"}, IsErrInvalidCustomHeader},
+	} {
+		err := c.meta.Validate()
+		if c.check == nil {
+			assert.NoError(t, err, c.meta)
+		} else {
+			assert.True(t, c.check(err), "%v: %v", c.meta, err)
+		}
+	}
+}
+
+func TestParseCustomHeaders(t *testing.T) {
+	header, err := parseCustomHeaders("authorization: Bearer token\r\n X-Custom : a \nx-custom: b\n")
+	require.NoError(t, err)
+	assert.Equal(t, http.Header{
+		"Authorization": {"Bearer token"},
+		"X-Custom":      {"a", "b"},
+	}, header)
+}
@@ -6,10 +6,16 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io/ioutil"
-	"net"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -21,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 	"github.com/gobwas/glob"
 )
 
@@ -35,24 +42,138 @@ func Deliver(t *models.HookTask) error {
 		log.Error("PANIC whilst trying to deliver webhook[%d] for repo[%d] to %s Panic: %v\nStacktrace: %s", t.ID, t.RepoID, t.URL, err, log.Stack(2))
 	}()
 	t.IsDelivered = true
+	t.Attempts++
 
-	var req *http.Request
-	var err error
+	w, err := models.GetWebhookByID(t.HookID)
+	if err != nil {
+		// the task can't be delivered without its webhook, so don't try again
+		if err := models.UpdateHookTask(t); err != nil {
+			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
+		}
+		return fmt.Errorf("GetWebhookByID: %v", err)
+	}
+
+	t.RequestInfo = &models.HookRequest{
+		Headers: map[string]string{},
+	}
+	t.ResponseInfo = &models.HookResponse{
+		Headers: map[string]string{},
+	}
+
+	// the outcome is recorded in any case, so that a failed delivery is retried or dead-lettered
+	// instead of being picked up again and again
+	defer func() {
+		t.Delivered = time.Now().UnixNano()
+		if t.IsSucceed {
+			log.Trace("Hook delivered: %s", t.UUID)
+		} else {
+			log.Trace("Hook delivery failed: %s", t.UUID)
+		}
+
+		disable := false
+		if !t.IsSucceed {
+			disable = handleFailedDelivery(t, w)
+		}
+
+		if err := models.UpdateHookTask(t); err != nil {
+			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
+		}
+
+		// Update webhook last delivery status.
+		if t.IsSucceed {
+			w.LastStatus = models.HookStatusSucceed
+		} else {
+			w.LastStatus = models.HookStatusFail
+		}
+		if err := models.UpdateWebhookLastStatus(w); err != nil {
+			log.Error("UpdateWebhookLastStatus: %v", err)
+			return
+		}
+
+		if disable {
+			log.Warn("Deactivating webhook[%d] as delivery %s failed after %d attempts", w.ID, t.UUID, t.Attempts)
+			w.IsActive = false
+			if err := models.UpdateWebhookActive(w); err != nil {
+				log.Error("UpdateWebhookActive: %v", err)
+			}
+		}
+	}()
+
+	req, err := newHookRequest(t, w)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("Request: %v", err)
+		return err
+	}
+
+	// Record delivery information.
+	for k, vals := range req.Header {
+		// the authorization is a credential, don't show it in the delivery history
+		if k == "Authorization" {
+			t.RequestInfo.Headers[k] = "******"
+			continue
+		}
+		t.RequestInfo.Headers[k] = strings.Join(vals, ",")
+	}
+
+	if setting.DisableWebhooks {
+		return fmt.Errorf("Webhook task skipped (webhooks disabled): [%d]", t.ID)
+	}
+
+	client, err := webhookClient(w)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("TLS configuration: %v", err)
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Status code is 20x can be seen as succeed.
+	t.IsSucceed = resp.StatusCode/100 == 2
+	t.ResponseInfo.Status = resp.StatusCode
+	for k, vals := range resp.Header {
+		t.ResponseInfo.Headers[k] = strings.Join(vals, ",")
+	}
+
+	p, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("read body: %s", err)
+		return err
+	}
+	t.ResponseInfo.Body = string(p)
+	return nil
+}
+
+// newHookRequest builds the request delivering a hook task to its webhook
+func newHookRequest(t *models.HookTask, w *models.Webhook) (*http.Request, error) {
+	var (
+		req *http.Request
+		err error
+	)
 
 	switch t.HTTPMethod {
 	case "":
 		log.Info("HTTP Method for webhook %d empty, setting to POST as default", t.ID)
 		fallthrough
 	case http.MethodPost:
-		switch t.ContentType {
-		case models.ContentTypeJSON:
+		switch {
+		case t.Typ == models.CUSTOM:
+			req, err = getCustomHookRequest(t, w, http.MethodPost)
+			if err != nil {
+				return nil, err
+			}
+		case t.ContentType == models.ContentTypeJSON:
 			req, err = http.NewRequest("POST", t.URL, strings.NewReader(t.PayloadContent))
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			req.Header.Set("Content-Type", "application/json")
-		case models.ContentTypeForm:
+		case t.ContentType == models.ContentTypeForm:
 			var forms = url.Values{
 				"payload": []string{t.PayloadContent},
 			}
@@ -60,35 +181,46 @@ func Deliver(t *models.HookTask) error {
 			req, err = http.NewRequest("POST", t.URL, strings.NewReader(forms.Encode()))
 			if err != nil {
 
-				return err
+				return nil, err
 			}
 
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		default:
+			return nil, fmt.Errorf("Invalid content type for webhook: [%d] %v", t.ID, t.ContentType)
 		}
 	case http.MethodGet:
 		u, err := url.Parse(t.URL)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		vals := u.Query()
 		vals["payload"] = []string{t.PayloadContent}
 		u.RawQuery = vals.Encode()
 		req, err = http.NewRequest("GET", u.String(), nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	case http.MethodPut:
 		switch t.Typ {
 		case models.MATRIX:
 			req, err = getMatrixHookRequest(t)
 			if err != nil {
-				return err
+				return nil, err
+			}
+		case models.CUSTOM:
+			req, err = getCustomHookRequest(t, w, http.MethodPut)
+			if err != nil {
+				return nil, err
 			}
 		default:
-			return fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, t.HTTPMethod)
+			return nil, fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, t.HTTPMethod)
 		}
 	default:
-		return fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, t.HTTPMethod)
+		return nil, fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, t.HTTPMethod)
+	}
+
+	if len(w.Secret) > 0 {
+		t.Signature = signPayload(w.GetSignatureAlgorithm(), w.Secret, []byte(t.PayloadContent))
 	}
 
 	req.Header.Add("X-Gitea-Delivery", t.UUID)
@@ -100,100 +232,131 @@ func Deliver(t *models.HookTask) error {
 	req.Header["X-GitHub-Delivery"] = []string{t.UUID}
 	req.Header["X-GitHub-Event"] = []string{t.EventType.Event()}
 
-	// Record delivery information.
-	t.RequestInfo = &models.HookRequest{
-		Headers: map[string]string{},
-	}
-	for k, vals := range req.Header {
-		t.RequestInfo.Headers[k] = strings.Join(vals, ",")
+	if len(w.Secret) > 0 {
+		// unlike X-Gitea-Signature, GitHub signs the request body, which differs for forms
+		body, err := requestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		if body == nil {
+			body = []byte(t.PayloadContent)
+		}
+		if w.GetSignatureAlgorithm() == models.HookSignatureSHA1 {
+			req.Header["X-Hub-Signature"] = []string{"sha1=" + signPayload(models.HookSignatureSHA1, w.Secret, body)}
+		}
+		req.Header["X-Hub-Signature-256"] = []string{"sha256=" + signPayload(models.HookSignatureSHA256, w.Secret, body)}
 	}
 
-	t.ResponseInfo = &models.HookResponse{
-		Headers: map[string]string{},
+	authorization, err := w.HeaderAuthorization()
+	if err != nil {
+		return nil, fmt.Errorf("HeaderAuthorization: %v", err)
+	}
+	if len(authorization) > 0 {
+		req.Header.Set("Authorization", authorization)
 	}
 
-	defer func() {
-		t.Delivered = time.Now().UnixNano()
-		if t.IsSucceed {
-			log.Trace("Hook delivered: %s", t.UUID)
-		} else {
-			log.Trace("Hook delivery failed: %s", t.UUID)
-		}
+	return req, nil
+}
 
-		if err := models.UpdateHookTask(t); err != nil {
-			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
-		}
+// hookSignatureHashes maps the HMAC algorithms of webhooks to their hash functions
+var hookSignatureHashes = map[string]func() hash.Hash{
+	models.HookSignatureSHA1:   sha1.New,
+	models.HookSignatureSHA256: sha256.New,
+	models.HookSignatureSHA512: sha512.New,
+}
 
-		// Update webhook last delivery status.
-		w, err := models.GetWebhookByID(t.HookID)
-		if err != nil {
-			log.Error("GetWebhookByID: %v", err)
-			return
-		}
-		if t.IsSucceed {
-			w.LastStatus = models.HookStatusSucceed
-		} else {
-			w.LastStatus = models.HookStatusFail
-		}
-		if err = models.UpdateWebhookLastStatus(w); err != nil {
-			log.Error("UpdateWebhookLastStatus: %v", err)
-			return
-		}
-	}()
+// signPayload returns the hex encoded HMAC of a payload with the given algorithm
+func signPayload(algorithm, secret string, payload []byte) string {
+	sig := hmac.New(hookSignatureHashes[algorithm], []byte(secret))
+	// writing to a hash never fails
+	_, _ = sig.Write(payload)
+	return hex.EncodeToString(sig.Sum(nil))
+}
 
-	if setting.DisableWebhooks {
-		return fmt.Errorf("Webhook task skipped (webhooks disabled): [%d]", t.ID)
+// requestBody returns a copy of the body of a request, or nil if it has none
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		return nil, nil
 	}
-
-	resp, err := webhookHTTPClient.Do(req)
+	body, err := req.GetBody()
 	if err != nil {
-		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
 
-	// Status code is 20x can be seen as succeed.
-	t.IsSucceed = resp.StatusCode/100 == 2
-	t.ResponseInfo.Status = resp.StatusCode
-	for k, vals := range resp.Header {
-		t.ResponseInfo.Headers[k] = strings.Join(vals, ",")
+// handleFailedDelivery schedules a retry of a failed delivery, or dead-letters it once all retries
+// of the webhook are used up. It returns whether the webhook is to be deactivated.
+func handleFailedDelivery(t *models.HookTask, w *models.Webhook) bool {
+	if t.Attempts <= w.MaxRetries {
+		t.IsDelivered = false
+		t.NextAttemptUnix = timeutil.TimeStampNow().AddDuration(retryDelay(t.Attempts))
+		log.Trace("Hook delivery of %s will be retried at %v", t.UUID, t.NextAttemptUnix.AsTime())
+		return false
 	}
+	t.IsDeadLetter = true
+	return w.DisableAfterRetries && w.IsActive
+}
 
-	p, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		t.ResponseInfo.Body = fmt.Sprintf("read body: %s", err)
-		return err
+// retryDelay returns the delay before retrying a delivery after the given number of failed attempts.
+// It doubles with each attempt up to the maximum delay, and is randomized by up to half so that
+// the retries of a receiver which was down are spread out.
+func retryDelay(attempts int) time.Duration {
+	delay := setting.Webhook.RetryBaseDelay
+	for i := 1; i < attempts && delay < setting.Webhook.RetryMaxDelay; i++ {
+		delay *= 2
 	}
-	t.ResponseInfo.Body = string(p)
-	return nil
+	if delay > setting.Webhook.RetryMaxDelay {
+		delay = setting.Webhook.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay/2)+1))
 }
 
-// DeliverHooks checks and delivers undelivered hooks.
-// FIXME: graceful: This would likely benefit from either a worker pool with dummy queue
-// or a full queue. Then more hooks could be sent at same time.
-func DeliverHooks(ctx context.Context) {
-	select {
-	case <-ctx.Done():
-		return
-	default:
-	}
+// retryCheckInterval is how often failed deliveries are checked for being due to be retried
+const retryCheckInterval = 10 * time.Second
+
+// deliverDueHookTasks delivers all undelivered hook tasks which are due. It returns false if
+// the context is done.
+func deliverDueHookTasks(ctx context.Context) bool {
 	tasks, err := models.FindUndeliveredHookTasks()
 	if err != nil {
 		log.Error("DeliverHooks: %v", err)
-		return
+		return true
 	}
 
 	// Update hook task status.
 	for _, t := range tasks {
 		select {
 		case <-ctx.Done():
-			return
+			return false
 		default:
 		}
 		if err = Deliver(t); err != nil {
 			log.Error("deliver: %v", err)
 		}
 	}
+	return true
+}
+
+// DeliverHooks checks and delivers undelivered hooks.
+// FIXME: graceful: This would likely benefit from either a worker pool with dummy queue
+// or a full queue. Then more hooks could be sent at same time.
+func DeliverHooks(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+	if !deliverDueHookTasks(ctx) {
+		return
+	}
+
+	retryTicker := time.NewTicker(retryCheckInterval)
+	defer retryTicker.Stop()
 
 	// Start listening on new hook requests.
 	for {
@@ -201,6 +364,11 @@ func DeliverHooks(ctx context.Context) {
 		case <-ctx.Done():
 			hookQueue.Close()
 			return
+		case <-retryTicker.C:
+			// deliver failed tasks to be retried and redelivered tasks
+			if !deliverDueHookTasks(ctx) {
+				return
+			}
 		case repoIDStr := <-hookQueue.Queue():
 			log.Trace("DeliverHooks [repo_id: %v]", repoIDStr)
 			hookQueue.Remove(repoIDStr)
@@ -264,21 +432,8 @@ func webhookProxy() func(req *http.Request) (*url.URL, error) {
 
 // InitDeliverHooks starts the hooks delivery thread
 func InitDeliverHooks() {
-	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
-
 	webhookHTTPClient = &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify},
-			Proxy:           webhookProxy(),
-			Dial: func(netw, addr string) (net.Conn, error) {
-				conn, err := net.DialTimeout(netw, addr, timeout)
-				if err != nil {
-					return nil, err
-				}
-
-				return conn, conn.SetDeadline(time.Now().Add(timeout))
-			},
-		},
+		Transport: newWebhookTransport(&tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify}),
 	}
 
 	go graceful.GetManager().RunWithShutdownContext(DeliverHooks)
@@ -7,9 +7,13 @@ package webhook
 import (
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,3 +41,96 @@ func TestWebhookProxy(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryDelay(t *testing.T) {
+	setting.Webhook.RetryBaseDelay = 10 * time.Second
+	setting.Webhook.RetryMaxDelay = time.Minute
+
+	for attempts, maxDelay := range []time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 10: time.Minute} {
+		if maxDelay == 0 {
+			continue
+		}
+		delay := retryDelay(attempts)
+		assert.True(t, delay >= maxDelay/2 && delay <= maxDelay, "attempt %d: %v", attempts, delay)
+	}
+}
+
+func TestHandleFailedDelivery(t *testing.T) {
+	setting.Webhook.RetryBaseDelay = 10 * time.Second
+	setting.Webhook.RetryMaxDelay = time.Minute
+
+	w := &models.Webhook{IsActive: true, MaxRetries: 1, DisableAfterRetries: true}
+	task := &models.HookTask{IsDelivered: true, Attempts: 1}
+
+	// the first failure is retried later
+	assert.False(t, handleFailedDelivery(task, w))
+	assert.False(t, task.IsDelivered)
+	assert.False(t, task.IsDeadLetter)
+	assert.True(t, task.NextAttemptUnix > timeutil.TimeStampNow())
+
+	// once the retry failed too, the task is dead-lettered and the hook to be deactivated
+	task.IsDelivered = true
+	task.Attempts++
+	assert.True(t, handleFailedDelivery(task, w))
+	assert.True(t, task.IsDelivered)
+	assert.True(t, task.IsDeadLetter)
+
+	w.DisableAfterRetries = false
+	assert.False(t, handleFailedDelivery(task, w))
+}
+
+func TestSignPayload(t *testing.T) {
+	payload := []byte("Hello, World!")
+	for algorithm, expected := range map[string]string{
+		models.HookSignatureSHA1:   "01dc10d0c83e72ed246219cdd91669667fe2ca59",
+		models.HookSignatureSHA256: "757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		models.HookSignatureSHA512: "11ed355a617e98134e842012a7944ccf59c10256cb182357bd7e3a42013ff07c376f8c14cf5cc1923da20b51d64256b2fb8ebbf100aa67a61326f61fea8111bc",
+	} {
+		assert.Equal(t, expected, signPayload(algorithm, "It's a Secret to Everybody", payload), algorithm)
+	}
+}
+
+func TestRequestBody(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://localhost", strings.NewReader("payload=%7B%7D"))
+	assert.NoError(t, err)
+	body, err := requestBody(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "payload=%7B%7D", string(body))
+
+	req, err = http.NewRequest(http.MethodGet, "http://localhost", nil)
+	assert.NoError(t, err)
+	body, err = requestBody(req)
+	assert.NoError(t, err)
+	assert.Nil(t, body)
+}
+
+func TestNewHookRequest(t *testing.T) {
+	w := &models.Webhook{Secret: "secret"}
+	assert.NoError(t, w.SetHeaderAuthorization("Bearer token"))
+	task := &models.HookTask{
+		URL:            "http://localhost/hook",
+		HTTPMethod:     http.MethodPost,
+		ContentType:    models.ContentTypeJSON,
+		PayloadContent: `{"ref":"refs/heads/master"}`,
+		EventType:      models.HookEventPush,
+	}
+
+	req, err := newHookRequest(task, w)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, task.Signature, req.Header.Get("X-Gitea-Signature"))
+
+	// errors are returned, so that the delivery is recorded as failed
+	w.HeaderAuthorizationEncrypted = "invalid"
+	_, err = newHookRequest(task, w)
+	assert.Error(t, err)
+
+	task.ContentType = 0
+	_, err = newHookRequest(task, &models.Webhook{})
+	assert.Error(t, err)
+
+	task.HTTPMethod = http.MethodDelete
+	_, err = newHookRequest(task, &models.Webhook{})
+	assert.Error(t, err)
+}
@@ -265,6 +265,55 @@ func (d *DingtalkPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	}, nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (d *DingtalkPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, "view wiki page", p.PageURL), nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (d *DingtalkPayload) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, "view status", commitStatusURL(p)), nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (d *DingtalkPayload) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, _ := getMilestonePayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, "view milestone", fmt.Sprintf("%s/milestone/%d", p.Repository.HTMLURL, p.Milestone.ID)), nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (d *DingtalkPayload) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, _ := getProjectCardPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, "view issue", p.Issue.HTMLURL), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (d *DingtalkPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _ := getBranchProtectionPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, "view branch protection", p.Repository.HTMLURL+"/settings/branches"), nil
+}
+
+// createDingtalkPayload creates an action card linking to the given URL
+func createDingtalkPayload(text, singleTitle, singleURL string) *DingtalkPayload {
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        text,
+			Title:       text,
+			HideAvatar:  "0",
+			SingleTitle: singleTitle,
+			SingleURL:   singleURL,
+		},
+	}
+}
+
 // GetDingtalkPayload converts a ding talk webhook into a DingtalkPayload
 func GetDingtalkPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(DingtalkPayload), p, event)
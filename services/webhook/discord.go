@@ -404,6 +404,62 @@ func (d *DiscordPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	}, nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (d *DiscordPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Comment, p.PageURL, color), nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (d *DiscordPayload) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getCommitStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Status.Description, commitStatusURL(p), color), nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (d *DiscordPayload) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, color := getMilestonePayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Milestone.Description, fmt.Sprintf("%s/milestone/%d", p.Repository.HTMLURL, p.Milestone.ID), color), nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (d *DiscordPayload) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, color := getProjectCardPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", p.Issue.HTMLURL, color), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (d *DiscordPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, color := getBranchProtectionPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", p.Repository.HTMLURL+"/settings/branches", color), nil
+}
+
+// createPayload creates an embed with the settings of the webhook
+func (d *DiscordPayload) createPayload(s *api.User, title, text, url string, color int) *DiscordPayload {
+	return &DiscordPayload{
+		Username:  d.Username,
+		AvatarURL: d.AvatarURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       title,
+				Description: text,
+				URL:         url,
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    s.UserName,
+					URL:     setting.AppURL + s.UserName,
+					IconURL: s.AvatarURL,
+				},
+			},
+		},
+	}
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	s := new(DiscordPayload)
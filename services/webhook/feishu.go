@@ -163,6 +163,41 @@ func (f *FeishuPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return newFeishuTextPayload(text), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (f *FeishuPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (f *FeishuPayload) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getCommitStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (f *FeishuPayload) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, _ := getMilestonePayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (f *FeishuPayload) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, _ := getProjectCardPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (f *FeishuPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _ := getBranchProtectionPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
 // GetFeishuPayload converts a ding talk webhook into a FeishuPayload
 func GetFeishuPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(FeishuPayload), p, event)
@@ -9,6 +9,7 @@ import (
 	"html"
 	"strings"
 
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 )
@@ -191,3 +192,114 @@ func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFo
 
 	return text, issueTitle, color
 }
+
+func getWikiPayloadInfo(p *api.WikiPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	pageLink := linkFormatter(p.PageURL, p.Page)
+
+	switch p.Action {
+	case api.HookWikiCreated:
+		text = fmt.Sprintf("[%s] Wiki page created: %s", repoLink, pageLink)
+		color = greenColor
+	case api.HookWikiEdited:
+		text = fmt.Sprintf("[%s] Wiki page edited: %s", repoLink, pageLink)
+		color = yellowColor
+	case api.HookWikiDeleted:
+		text = fmt.Sprintf("[%s] Wiki page deleted: %s", repoLink, pageLink)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+// commitStatusURL returns the target URL of a commit status, or the URL of its commit if it has none
+func commitStatusURL(p *api.CommitStatusPayload) string {
+	if len(p.Status.TargetURL) > 0 {
+		return p.Status.TargetURL
+	}
+	return p.Repository.HTMLURL + "/commit/" + p.SHA
+}
+
+func getCommitStatusPayloadInfo(p *api.CommitStatusPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	commitLink := linkFormatter(p.Repository.HTMLURL+"/commit/"+p.SHA, base.ShortSha(p.SHA))
+	contextLink := linkFormatter(commitStatusURL(p), p.Status.Context)
+
+	text = fmt.Sprintf("[%s] Commit status %s: %s on %s", repoLink, p.Status.State, contextLink, commitLink)
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	switch p.Status.State {
+	case api.CommitStatusSuccess:
+		color = greenColor
+	case api.CommitStatusError, api.CommitStatusFailure:
+		color = redColor
+	case api.CommitStatusWarning:
+		color = orangeColor
+	default:
+		color = yellowColor
+	}
+	return text, color
+}
+
+func getMilestonePayloadInfo(p *api.MilestonePayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	milestoneLink := linkFormatter(fmt.Sprintf("%s/milestone/%d", p.Repository.HTMLURL, p.Milestone.ID), p.Milestone.Title)
+
+	switch p.Action {
+	case api.HookMilestoneOpened:
+		text = fmt.Sprintf("[%s] Milestone opened: %s", repoLink, milestoneLink)
+		color = greenColor
+	case api.HookMilestoneClosed:
+		text = fmt.Sprintf("[%s] Milestone closed: %s", repoLink, milestoneLink)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+func getProjectCardPayloadInfo(p *api.ProjectCardPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	titleLink := linkFormatter(p.Issue.HTMLURL, fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title))
+	board := p.Board.Title
+	if len(board) == 0 {
+		board = "Uncategorized"
+	}
+	boardLink := linkFormatter(p.Project.HTMLURL, fmt.Sprintf("%s / %s", p.Project.Title, board))
+
+	text = fmt.Sprintf("[%s] %s moved to %s", repoLink, titleLink, boardLink)
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	return text, yellowColor
+}
+
+func getBranchProtectionPayloadInfo(p *api.BranchProtectionPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	branchLink := linkFormatter(p.Repository.HTMLURL+"/src/branch/"+p.BranchProtection.BranchName, p.BranchProtection.BranchName)
+
+	switch p.Action {
+	case api.HookBranchProtectionCreated:
+		text = fmt.Sprintf("[%s] Branch protection created: %s", repoLink, branchLink)
+		color = greenColor
+	case api.HookBranchProtectionEdited:
+		text = fmt.Sprintf("[%s] Branch protection edited: %s", repoLink, branchLink)
+		color = yellowColor
+	case api.HookBranchProtectionDeleted:
+		text = fmt.Sprintf("[%s] Branch protection deleted: %s", repoLink, branchLink)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	return text, color
+}
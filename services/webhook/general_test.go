@@ -123,3 +123,105 @@ func pullRequestTestPayload() *api.PullRequestPayload {
 		},
 	}
 }
+
+func wikiTestPayload() *api.WikiPayload {
+	return &api.WikiPayload{
+		Action: api.HookWikiEdited,
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Page:    "Getting started",
+		PageURL: "http://localhost:3000/test/repo/wiki/Getting-started",
+		Comment: "fix typo",
+	}
+}
+
+func commitStatusTestPayload() *api.CommitStatusPayload {
+	return &api.CommitStatusPayload{
+		SHA: "2020fe1e81a5dd0f50d3bb8b2be9c1b2a1b3e4d5",
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Status: &api.CommitStatus{
+			State:       api.CommitStatusFailure,
+			TargetURL:   "http://ci.example.com/builds/12",
+			Description: "2 tests failed",
+			Context:     "ci/build",
+		},
+	}
+}
+
+func milestoneTestPayload() *api.MilestonePayload {
+	return &api.MilestonePayload{
+		Action: api.HookMilestoneClosed,
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Milestone: &api.Milestone{
+			ID:    3,
+			Title: "v1.0",
+		},
+	}
+}
+
+func projectCardTestPayload() *api.ProjectCardPayload {
+	return &api.ProjectCardPayload{
+		Action: api.HookProjectCardMoved,
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Project: &api.Project{
+			ID:      1,
+			Title:   "Roadmap <2021>",
+			HTMLURL: "http://localhost:3000/test/repo/projects/1",
+		},
+		FromBoardID: 1,
+		Board: &api.ProjectBoard{
+			ID:    2,
+			Title: "Done",
+		},
+		Issue: &api.Issue{
+			ID:      2,
+			Index:   2,
+			HTMLURL: "http://localhost:3000/test/repo/issues/2",
+			Title:   "crash",
+		},
+	}
+}
+
+func branchProtectionTestPayload() *api.BranchProtectionPayload {
+	return &api.BranchProtectionPayload{
+		Action: api.HookBranchProtectionCreated,
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		BranchProtection: &api.BranchProtection{
+			BranchName: "master",
+		},
+	}
+}
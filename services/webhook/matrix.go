@@ -226,6 +226,41 @@ func (m *MatrixPayloadUnsafe) Repository(p *api.RepositoryPayload) (api.Payloade
 	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (m *MatrixPayloadUnsafe) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (m *MatrixPayloadUnsafe) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getCommitStatusPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (m *MatrixPayloadUnsafe) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, _ := getMilestonePayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (m *MatrixPayloadUnsafe) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, _ := getProjectCardPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (m *MatrixPayloadUnsafe) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _ := getBranchProtectionPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
 // GetMatrixPayload converts a Matrix webhook into a MatrixPayloadUnsafe
 func GetMatrixPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	s := new(MatrixPayloadUnsafe)
@@ -558,6 +558,83 @@ func (m *MSTeamsPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	}, nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (m *MSTeamsPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(p.Repository, p.Sender, text, p.Comment, p.PageURL, color, &MSTeamsFact{Name: "Page:", Value: p.Page}), nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (m *MSTeamsPayload) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getCommitStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(p.Repository, p.Sender, text, p.Status.Description, commitStatusURL(p), color, &MSTeamsFact{Name: "Commit:", Value: p.SHA}), nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (m *MSTeamsPayload) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, color := getMilestonePayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(p.Repository, p.Sender, text, p.Milestone.Description, fmt.Sprintf("%s/milestone/%d", p.Repository.HTMLURL, p.Milestone.ID), color, nil), nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (m *MSTeamsPayload) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, color := getProjectCardPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(p.Repository, p.Sender, text, "", p.Issue.HTMLURL, color, &MSTeamsFact{Name: "Project:", Value: p.Project.Title}), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (m *MSTeamsPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, color := getBranchProtectionPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(p.Repository, p.Sender, text, "", p.Repository.HTMLURL+"/settings/branches", color, &MSTeamsFact{Name: "Branch:", Value: p.BranchProtection.BranchName}), nil
+}
+
+// createMSTeamsPayload creates a message card with the repository and an optional further fact
+func createMSTeamsPayload(r *api.Repository, s *api.User, title, text, actionTarget string, color int, fact *MSTeamsFact) *MSTeamsPayload {
+	facts := []MSTeamsFact{
+		{
+			Name:  "Repository:",
+			Value: r.FullName,
+		},
+	}
+	if fact != nil {
+		facts = append(facts, *fact)
+	}
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      title,
+		Summary:    title,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    s.FullName,
+				ActivitySubtitle: s.UserName,
+				ActivityImage:    s.AvatarURL,
+				Text:             text,
+				Facts:            facts,
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: actionTarget,
+					},
+				},
+			},
+		},
+	}
+}
+
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
 func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(MSTeamsPayload), p, event)
@@ -22,6 +22,11 @@ type PayloadConvertor interface {
 	Review(*api.PullRequestPayload, models.HookEventType) (api.Payloader, error)
 	Repository(*api.RepositoryPayload) (api.Payloader, error)
 	Release(*api.ReleasePayload) (api.Payloader, error)
+	Wiki(*api.WikiPayload) (api.Payloader, error)
+	CommitStatus(*api.CommitStatusPayload) (api.Payloader, error)
+	Milestone(*api.MilestonePayload) (api.Payloader, error)
+	ProjectCard(*api.ProjectCardPayload) (api.Payloader, error)
+	BranchProtection(*api.BranchProtectionPayload) (api.Payloader, error)
 }
 
 func convertPayloader(s PayloadConvertor, p api.Payloader, event models.HookEventType) (api.Payloader, error) {
@@ -51,6 +56,16 @@ func convertPayloader(s PayloadConvertor, p api.Payloader, event models.HookEven
 		return s.Repository(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
 		return s.Release(p.(*api.ReleasePayload))
+	case models.HookEventWiki:
+		return s.Wiki(p.(*api.WikiPayload))
+	case models.HookEventStatus:
+		return s.CommitStatus(p.(*api.CommitStatusPayload))
+	case models.HookEventMilestone:
+		return s.Milestone(p.(*api.MilestonePayload))
+	case models.HookEventProjectCard:
+		return s.ProjectCard(p.(*api.ProjectCardPayload))
+	case models.HookEventBranchProtection:
+		return s.BranchProtection(p.(*api.BranchProtectionPayload))
 	}
 	return s, nil
 }
@@ -317,6 +317,51 @@ func (s *SlackPayload) Repository(p *api.RepositoryPayload) (api.Payloader, erro
 	}, nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (s *SlackPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text), nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (s *SlackPayload) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getCommitStatusPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text), nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (s *SlackPayload) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, _ := getMilestonePayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text), nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (s *SlackPayload) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, _ := getProjectCardPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text), nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (s *SlackPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _ := getBranchProtectionPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text), nil
+}
+
+// createPayload creates a message with the text and the settings of the webhook
+func (s *SlackPayload) createPayload(text string) *SlackPayload {
+	return &SlackPayload{
+		Channel:  s.Channel,
+		Text:     text,
+		Username: s.Username,
+		IconURL:  s.IconURL,
+	}
+}
+
 // GetSlackPayload converts a slack webhook into a SlackPayload
 func GetSlackPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	s := new(SlackPayload)
@@ -78,3 +78,58 @@ func TestSlackPullRequestPayload(t *testing.T) {
 
 	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Pull request opened: <http://localhost:3000/test/repo/pulls/12|#2 Fix bug> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
 }
+
+func TestSlackWikiPayload(t *testing.T) {
+	p := wikiTestPayload()
+	s := new(SlackPayload)
+
+	pl, err := s.Wiki(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Wiki page edited: <http://localhost:3000/test/repo/wiki/Getting-started|Getting started> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackCommitStatusPayload(t *testing.T) {
+	p := commitStatusTestPayload()
+	s := new(SlackPayload)
+
+	pl, err := s.CommitStatus(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Commit status failure: <http://ci.example.com/builds/12|ci/build> on <http://localhost:3000/test/repo/commit/2020fe1e81a5dd0f50d3bb8b2be9c1b2a1b3e4d5|2020fe1e81> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackMilestonePayload(t *testing.T) {
+	p := milestoneTestPayload()
+	s := new(SlackPayload)
+
+	pl, err := s.Milestone(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Milestone closed: <http://localhost:3000/test/repo/milestone/3|v1.0> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackProjectCardPayload(t *testing.T) {
+	p := projectCardTestPayload()
+	s := new(SlackPayload)
+
+	pl, err := s.ProjectCard(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] <http://localhost:3000/test/repo/issues/2|#2 crash> moved to <http://localhost:3000/test/repo/projects/1|Roadmap &lt;2021&gt; / Done> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackBranchProtectionPayload(t *testing.T) {
+	p := branchProtectionTestPayload()
+	s := new(SlackPayload)
+
+	pl, err := s.BranchProtection(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Branch protection created: <http://localhost:3000/test/repo/src/branch/master|master> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
@@ -208,6 +208,51 @@ func (t *TelegramPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	}, nil
 }
 
+// Wiki implements PayloadConvertor Wiki method
+func (t *TelegramPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
+// CommitStatus implements PayloadConvertor CommitStatus method
+func (t *TelegramPayload) CommitStatus(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getCommitStatusPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
+// Milestone implements PayloadConvertor Milestone method
+func (t *TelegramPayload) Milestone(p *api.MilestonePayload) (api.Payloader, error) {
+	text, _ := getMilestonePayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
+// ProjectCard implements PayloadConvertor ProjectCard method
+func (t *TelegramPayload) ProjectCard(p *api.ProjectCardPayload) (api.Payloader, error) {
+	text, _ := getProjectCardPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
+// BranchProtection implements PayloadConvertor BranchProtection method
+func (t *TelegramPayload) BranchProtection(p *api.BranchProtectionPayload) (api.Payloader, error) {
+	text, _ := getBranchProtectionPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
 // GetTelegramPayload converts a telegram webhook into a TelegramPayload
 func GetTelegramPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(TelegramPayload), p, event)
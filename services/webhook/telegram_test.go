@@ -22,3 +22,13 @@ func TestGetTelegramIssuesPayload(t *testing.T) {
 
 	assert.Equal(t, "[<a href=\"http://localhost:3000/test/repo\">test/repo</a>] Issue closed: <a href=\"http://localhost:3000/test/repo/issues/2\">#2 crash</a> by <a href=\"https://try.gitea.io/user1\">user1</a>\n\n", pl.(*TelegramPayload).Message)
 }
+
+func TestGetTelegramProjectCardPayload(t *testing.T) {
+	p := projectCardTestPayload()
+
+	pl, err := new(TelegramPayload).ProjectCard(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<a href=\"http://localhost:3000/test/repo\">test/repo</a>] <a href=\"http://localhost:3000/test/repo/issues/2\">#2 crash</a> moved to <a href=\"http://localhost:3000/test/repo/projects/1\">Roadmap &lt;2021&gt; / Done</a> by <a href=\"https://try.gitea.io/user1\">user1</a>\n", pl.(*TelegramPayload).Message)
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrInvalidClientCertificate represents an invalid client certificate or key of a webhook
+type ErrInvalidClientCertificate struct {
+	Err error
+}
+
+// IsErrInvalidClientCertificate checks if an error is a ErrInvalidClientCertificate.
+func IsErrInvalidClientCertificate(err error) bool {
+	_, ok := err.(ErrInvalidClientCertificate)
+	return ok
+}
+
+func (err ErrInvalidClientCertificate) Error() string {
+	return fmt.Sprintf("invalid webhook client certificate: %v", err.Err)
+}
+
+// ErrInvalidCACertificates represents CA certificates of a webhook which can't be parsed
+type ErrInvalidCACertificates struct{}
+
+// IsErrInvalidCACertificates checks if an error is a ErrInvalidCACertificates.
+func IsErrInvalidCACertificates(err error) bool {
+	_, ok := err.(ErrInvalidCACertificates)
+	return ok
+}
+
+func (err ErrInvalidCACertificates) Error() string {
+	return "invalid webhook CA certificates: no PEM encoded certificate found"
+}
+
+// ValidateClientTLS checks the PEM encoded client certificate, key and CA certificates of a webhook.
+// Each of them may be empty, but the certificate and key must be given together.
+func ValidateClientTLS(certPEM, keyPEM, caPEM string) error {
+	_, err := newClientTLSConfig(certPEM, keyPEM, caPEM)
+	return err
+}
+
+// SetClientTLS validates and sets the PEM encoded client certificate, key and CA certificates of a
+// webhook. An empty key keeps the current one, so that it doesn't have to be given on every edit.
+func SetClientTLS(w *models.Webhook, certPEM, keyPEM, caPEM string) error {
+	certPEM, caPEM = strings.TrimSpace(certPEM), strings.TrimSpace(caPEM)
+	keepKey := len(keyPEM) == 0 && len(certPEM) > 0
+	if keepKey {
+		var err error
+		if keyPEM, err = w.ClientKey(); err != nil {
+			return fmt.Errorf("ClientKey: %v", err)
+		}
+	}
+	if err := ValidateClientTLS(certPEM, keyPEM, caPEM); err != nil {
+		return err
+	}
+
+	w.ClientCertificate = certPEM
+	w.CACertificates = caPEM
+	if keepKey {
+		return nil
+	}
+	return w.SetClientKey(keyPEM)
+}
+
+func newClientTLSConfig(certPEM, keyPEM, caPEM string) (*tls.Config, error) {
+	// webhooks with their own CA certificates are always verified against them
+	config := &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify && len(caPEM) == 0}
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		if len(certPEM) == 0 || len(keyPEM) == 0 {
+			return nil, ErrInvalidClientCertificate{Err: errors.New("certificate and key must be given together")}
+		}
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, ErrInvalidClientCertificate{Err: err}
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if len(caPEM) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM([]byte(caPEM)) {
+			return nil, ErrInvalidCACertificates{}
+		}
+	}
+	return config, nil
+}
+
+// newWebhookTransport creates a transport for deliveries using the configured proxy and timeout
+func newWebhookTransport(tlsConfig *tls.Config) *http.Transport {
+	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
+	return &http.Transport{
+		TLSClientConfig: tlsConfig,
+		Proxy:           webhookProxy(),
+		Dial: func(netw, addr string) (net.Conn, error) {
+			conn, err := net.DialTimeout(netw, addr, timeout)
+			if err != nil {
+				return nil, err
+			}
+
+			return conn, conn.SetDeadline(time.Now().Add(timeout))
+		},
+	}
+}
+
+// hookClientIdleTimeout is how long the cached client of a webhook is kept without deliveries, so that
+// the clients of webhooks deleted along with their repository or owner don't keep their keys
+const hookClientIdleTimeout = 10 * time.Minute
+
+// hookClient is the cached client of a webhook with its own TLS settings
+type hookClient struct {
+	// fingerprint identifies the TLS settings the client was created with
+	fingerprint string
+	client      *http.Client
+	lastUsed    time.Time
+}
+
+var (
+	hookClientsLock sync.Mutex
+	hookClients     = make(map[int64]*hookClient)
+	// hookClientsTimer removes the idle clients while there are cached clients
+	hookClientsTimer *time.Timer
+)
+
+// clientTLSFingerprint identifies the TLS settings of a webhook, changing whenever they are edited
+func clientTLSFingerprint(w *models.Webhook) string {
+	h := sha256.New()
+	for _, s := range []string{w.ClientCertificate, w.ClientKeyEncrypted, w.CACertificates} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// webhookClient returns the client to deliver to a webhook with. Webhooks with a client
+// certificate or CA certificates get their own client, which is cached until their TLS settings
+// change, all others share the default one.
+func webhookClient(w *models.Webhook) (*http.Client, error) {
+	if !w.HasClientTLS() {
+		RemoveWebhookClient(w.ID)
+		return webhookHTTPClient, nil
+	}
+
+	fingerprint := clientTLSFingerprint(w)
+	hookClientsLock.Lock()
+	defer hookClientsLock.Unlock()
+
+	cached, ok := hookClients[w.ID]
+	if ok && cached.fingerprint == fingerprint {
+		cached.lastUsed = time.Now()
+		return cached.client, nil
+	}
+
+	key, err := w.ClientKey()
+	if err != nil {
+		return nil, fmt.Errorf("ClientKey: %v", err)
+	}
+	tlsConfig, err := newClientTLSConfig(w.ClientCertificate, key, w.CACertificates)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cached.client.CloseIdleConnections()
+	}
+	client := &http.Client{Transport: newWebhookTransport(tlsConfig)}
+	hookClients[w.ID] = &hookClient{fingerprint: fingerprint, client: client, lastUsed: time.Now()}
+	if hookClientsTimer == nil {
+		hookClientsTimer = time.AfterFunc(hookClientIdleTimeout, removeIdleWebhookClients)
+	}
+	return client, nil
+}
+
+// removeIdleWebhookClients drops the cached clients which weren't used within hookClientIdleTimeout,
+// it runs again as long as clients are left
+func removeIdleWebhookClients() {
+	hookClientsLock.Lock()
+	defer hookClientsLock.Unlock()
+
+	hookClientsTimer = nil
+	for hookID, cached := range hookClients {
+		if time.Since(cached.lastUsed) >= hookClientIdleTimeout {
+			cached.client.CloseIdleConnections()
+			delete(hookClients, hookID)
+		}
+	}
+	if len(hookClients) > 0 {
+		hookClientsTimer = time.AfterFunc(hookClientIdleTimeout, removeIdleWebhookClients)
+	}
+}
+
+// RemoveWebhookClient drops the cached client of a webhook, it has to be called when the webhook is deleted
+func RemoveWebhookClient(hookID int64) {
+	hookClientsLock.Lock()
+	defer hookClientsLock.Unlock()
+
+	if cached, ok := hookClients[hookID]; ok {
+		cached.client.CloseIdleConnections()
+		delete(hookClients, hookID)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// generateClientCertificate returns a PEM encoded self-signed client certificate and its key
+func generateClientCertificate(t *testing.T) (certPEM, keyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gitea"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestValidateClientTLS(t *testing.T) {
+	certPEM, keyPEM := generateClientCertificate(t)
+	otherCertPEM, _ := generateClientCertificate(t)
+
+	assert.NoError(t, ValidateClientTLS("", "", ""))
+	assert.NoError(t, ValidateClientTLS(certPEM, keyPEM, ""))
+	assert.NoError(t, ValidateClientTLS("", "", certPEM+otherCertPEM))
+
+	assert.True(t, IsErrInvalidClientCertificate(ValidateClientTLS(certPEM, "", "")))
+	assert.True(t, IsErrInvalidClientCertificate(ValidateClientTLS("", keyPEM, "")))
+	assert.True(t, IsErrInvalidClientCertificate(ValidateClientTLS(otherCertPEM, keyPEM, "")))
+	assert.True(t, IsErrInvalidCACertificates(ValidateClientTLS("", "", "not a certificate")))
+}
+
+func TestSetClientTLS(t *testing.T) {
+	certPEM, keyPEM := generateClientCertificate(t)
+	w := &models.Webhook{}
+	assert.True(t, IsErrInvalidClientCertificate(SetClientTLS(w, certPEM, "", "")))
+
+	assert.NoError(t, SetClientTLS(w, certPEM, keyPEM, ""))
+	encrypted := w.ClientKeyEncrypted
+	assert.NotEmpty(t, encrypted)
+
+	// an empty key keeps the current one
+	assert.NoError(t, SetClientTLS(w, certPEM, "", certPEM))
+	assert.Equal(t, encrypted, w.ClientKeyEncrypted)
+	assert.Equal(t, strings.TrimSpace(certPEM), w.CACertificates)
+
+	// removing the certificate removes the key
+	assert.NoError(t, SetClientTLS(w, "", "", ""))
+	assert.Empty(t, w.ClientKeyEncrypted)
+	assert.False(t, w.HasClientTLS())
+}
+
+func TestWebhookClient(t *testing.T) {
+	certPEM, keyPEM := generateClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	assert.True(t, clientCAs.AppendCertsFromPEM([]byte(certPEM)))
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	s.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	s.StartTLS()
+	defer s.Close()
+	serverCAPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}))
+
+	w := &models.Webhook{ID: 1000}
+	client, err := webhookClient(w)
+	assert.NoError(t, err)
+	assert.Equal(t, webhookHTTPClient, client)
+
+	// the server's certificate is trusted, but no client certificate is presented
+	w.CACertificates = serverCAPEM
+	client, err = webhookClient(w)
+	assert.NoError(t, err)
+	_, err = client.Get(s.URL)
+	assert.Error(t, err)
+
+	w.ClientCertificate = certPEM
+	assert.NoError(t, w.SetClientKey(keyPEM))
+	client, err = webhookClient(w)
+	assert.NoError(t, err)
+	resp, err := client.Get(s.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	// the client is cached until the TLS settings change
+	cached, err := webhookClient(w)
+	assert.NoError(t, err)
+	assert.True(t, client == cached)
+	w.CACertificates = ""
+	changed, err := webhookClient(w)
+	assert.NoError(t, err)
+	assert.False(t, client == changed)
+
+	// the cached client is dropped when the webhook has no TLS settings anymore or is deleted
+	w.ClientCertificate, w.ClientKeyEncrypted = "", ""
+	client, err = webhookClient(w)
+	assert.NoError(t, err)
+	assert.Equal(t, webhookHTTPClient, client)
+	assert.NotContains(t, hookClients, w.ID)
+
+	w.CACertificates = serverCAPEM
+	_, err = webhookClient(w)
+	assert.NoError(t, err)
+	assert.Contains(t, hookClients, w.ID)
+	RemoveWebhookClient(w.ID)
+	assert.NotContains(t, hookClients, w.ID)
+
+	// clients are dropped once they are idle, e.g. as their webhook was deleted with its repository
+	_, err = webhookClient(w)
+	assert.NoError(t, err)
+	other := &models.Webhook{ID: 1001, CACertificates: serverCAPEM}
+	_, err = webhookClient(other)
+	assert.NoError(t, err)
+	hookClients[w.ID].lastUsed = time.Now().Add(-hookClientIdleTimeout)
+	removeIdleWebhookClients()
+	assert.NotContains(t, hookClients, w.ID)
+	assert.Contains(t, hookClients, other.ID)
+	RemoveWebhookClient(other.ID)
+}
+
+func TestNewClientTLSConfig_SkipTLSVerify(t *testing.T) {
+	certPEM, _ := generateClientCertificate(t)
+
+	oldSkipTLSVerify := setting.Webhook.SkipTLSVerify
+	setting.Webhook.SkipTLSVerify = true
+	defer func() {
+		setting.Webhook.SkipTLSVerify = oldSkipTLSVerify
+	}()
+
+	config, err := newClientTLSConfig("", "", "")
+	assert.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+
+	// the CA certificates of a webhook are always verified against
+	config, err = newClientTLSConfig("", "", certPEM)
+	assert.NoError(t, err)
+	assert.False(t, config.InsecureSkipVerify)
+}
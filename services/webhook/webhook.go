@@ -5,9 +5,6 @@
 package webhook
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -55,6 +52,10 @@ var (
 			name:           models.MATRIX,
 			payloadCreator: GetMatrixPayload,
 		},
+		models.CUSTOM: {
+			name:           models.CUSTOM,
+			payloadCreator: GetCustomPayload,
+		},
 	}
 )
 
@@ -138,7 +139,7 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	// Avoid sending "0 new commits" to non-integration relevant webhooks (e.g. slack, discord, etc.).
 	// Integration webhooks (e.g. drone) still receive the required data.
 	if pushEvent, ok := p.(*api.PushPayload); ok &&
-		w.Type != models.GITEA && w.Type != models.GOGS &&
+		w.Type != models.GITEA && w.Type != models.GOGS && w.Type != models.CUSTOM &&
 		len(pushEvent.Commits) == 0 {
 		return nil
 	}
@@ -157,7 +158,11 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	webhook, ok := webhooks[w.Type]
 	if ok {
 		payloader, err = webhook.payloadCreator(p, event, w.Meta)
-		if err != nil {
+		if IsErrCustomTemplate(err) {
+			// the template is up to the user, so don't fail the other webhooks of the event
+			log.Warn("Skipping webhook[%d] for %s: %v", w.ID, event, err)
+			return nil
+		} else if err != nil {
 			return fmt.Errorf("create payload for %s[%s]: %v", w.Type, event, err)
 		}
 	} else {
@@ -165,26 +170,11 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		payloader = p
 	}
 
-	var signature string
-	if len(w.Secret) > 0 {
-		data, err := payloader.JSONPayload()
-		if err != nil {
-			log.Error("prepareWebhooks.JSONPayload: %v", err)
-		}
-		sig := hmac.New(sha256.New, []byte(w.Secret))
-		_, err = sig.Write(data)
-		if err != nil {
-			log.Error("prepareWebhooks.sigWrite: %v", err)
-		}
-		signature = hex.EncodeToString(sig.Sum(nil))
-	}
-
 	if err = models.CreateHookTask(&models.HookTask{
 		RepoID:      repo.ID,
 		HookID:      w.ID,
 		Typ:         w.Type,
 		URL:         w.URL,
-		Signature:   signature,
 		Payloader:   payloader,
 		HTTPMethod:  w.HTTPMethod,
 		ContentType: w.ContentType,
@@ -3,6 +3,7 @@ const $user = $('#auth_username');
 const $pass = $('#auth_password');
 const $token = $('#auth_token');
 const $mirror = $('#mirror');
+const $incremental = $('#incremental');
 const $lfs = $('#lfs');
 const $lfsSettings = $('#lfs_settings');
 const $lfsEndpoint = $('#lfs_endpoint');
@@ -16,6 +17,7 @@ export default function initMigration() {
   $pass.on('keyup', () => {checkItems(false)});
   $token.on('keyup', () => {checkItems(true)});
   $mirror.on('change', () => {checkItems(true)});
+  $incremental.on('change', () => {checkItems(true)});
   $('#lfs_settings_show').on('click', () => { $lfsEndpoint.show(); return false });
   $lfs.on('change', setLFSSettingsVisibility);
 
@@ -41,8 +43,9 @@ function checkItems(tokenAuth) {
   } else {
     enableItems = $user.val() !== '' || $pass.val() !== '';
   }
+  $incremental.attr('disabled', !$mirror.is(':checked'));
   if (enableItems && $service.val() > 1) {
-    if ($mirror.is(':checked')) {
+    if ($mirror.is(':checked') && !$incremental.is(':checked')) {
       $items.not('[name="wiki"]').attr('disabled', true);
       $items.filter('[name="wiki"]').attr('disabled', false);
       return;
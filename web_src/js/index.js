@@ -225,6 +225,9 @@ function initRepoStatusChecker() {
             $('#repo_migrating_failed_error').text(xhr.responseJSON.err);
             return;
           }
+          if (xhr.responseJSON.message) {
+            $('#repo_migrating_progress_message').text(xhr.responseJSON.message);
+          }
           setTimeout(() => {
             initRepoStatusChecker();
           }, 2000);
@@ -2307,127 +2310,177 @@ function initCodeView() {
   });
 }
 
-function initU2FAuth() {
-  if ($('#wait-for-key').length === 0) {
-    return;
+function encodeURLEncodedBase64(arrayBuffer) {
+  return btoa(String.fromCharCode(...new Uint8Array(arrayBuffer)))
+    .replace(/\+/g, '-')
+    .replace(/\//g, '_')
+    .replace(/=/g, '');
+}
+
+function decodeURLEncodedBase64(base64url) {
+  const base64 = base64url.replace(/-/g, '+').replace(/_/g, '/');
+  return Uint8Array.from(atob(base64), (c) => c.charCodeAt(0));
+}
+
+function webAuthnSupported() {
+  return window.PublicKeyCredential !== undefined && navigator.credentials !== undefined;
+}
+
+function decodeAssertionOptions(options) {
+  options.publicKey.challenge = decodeURLEncodedBase64(options.publicKey.challenge);
+  for (const cred of options.publicKey.allowCredentials) {
+    cred.id = decodeURLEncodedBase64(cred.id);
   }
-  u2fApi.ensureSupport()
-    .then(() => {
-      $.getJSON(`${AppSubUrl}/user/u2f/challenge`).done((req) => {
-        u2fApi.sign(req.appId, req.challenge, req.registeredKeys, 30)
-          .then(u2fSigned)
-          .catch((err) => {
-            if (err === undefined) {
-              u2fError(1);
-              return;
-            }
-            u2fError(err.metaData.code);
-          });
-      });
-    }).catch(() => {
-      // Fallback in case browser do not support U2F
-      window.location.href = `${AppSubUrl}/user/two_factor`;
-    });
+  return options;
 }
-function u2fSigned(resp) {
-  $.ajax({
-    url: `${AppSubUrl}/user/u2f/sign`,
-    type: 'POST',
-    headers: {'X-Csrf-Token': csrf},
-    data: JSON.stringify(resp),
-    contentType: 'application/json; charset=utf-8',
-  }).done((res) => {
-    window.location.replace(res);
-  }).fail(() => {
-    u2fError(1);
+
+function encodeAssertion(assertion) {
+  const {response} = assertion;
+  return JSON.stringify({
+    id: assertion.id,
+    rawId: encodeURLEncodedBase64(assertion.rawId),
+    type: assertion.type,
+    clientExtensionResults: assertion.getClientExtensionResults(),
+    response: {
+      authenticatorData: encodeURLEncodedBase64(response.authenticatorData),
+      clientDataJSON: encodeURLEncodedBase64(response.clientDataJSON),
+      signature: encodeURLEncodedBase64(response.signature),
+      userHandle: response.userHandle ? encodeURLEncodedBase64(response.userHandle) : '',
+    },
   });
 }
 
-function u2fRegistered(resp) {
-  if (checkError(resp)) {
-    return;
-  }
-  $.ajax({
-    url: `${AppSubUrl}/user/settings/security/u2f/register`,
+async function webAuthnAssert(url) {
+  const options = await $.getJSON(url);
+  const assertion = await navigator.credentials.get(decodeAssertionOptions(options));
+  return $.ajax({
+    url,
     type: 'POST',
     headers: {'X-Csrf-Token': csrf},
-    data: JSON.stringify(resp),
+    data: encodeAssertion(assertion),
     contentType: 'application/json; charset=utf-8',
-    success() {
-      reload();
-    },
-    fail() {
-      u2fError(1);
-    }
   });
 }
 
-function checkError(resp) {
-  if (!('errorCode' in resp)) {
-    return false;
-  }
-  if (resp.errorCode === 0) {
-    return false;
-  }
-  u2fError(resp.errorCode);
-  return true;
+function webAuthnErrorType(err) {
+  if (err && err.status === 400) return 'unable-to-process';
+  if (err && err.status === 409) return 'duplicated';
+  if (err && err.name === 'InvalidStateError') return 'duplicated';
+  if (err && err.name === 'SecurityError') return 'insecure';
+  if (err && err.name === 'NotAllowedError') return 'timeout';
+  return 'unknown';
 }
 
-function u2fError(errorType) {
-  const u2fErrors = {
-    browser: $('#unsupported-browser'),
-    1: $('#u2f-error-1'),
-    2: $('#u2f-error-2'),
-    3: $('#u2f-error-3'),
-    4: $('#u2f-error-4'),
-    5: $('.u2f_error_5')
+function webAuthnError(errorType) {
+  const webAuthnErrors = {
+    browser: $('#webauthn-error-browser'),
+    unknown: $('#webauthn-error-unknown'),
+    insecure: $('#webauthn-error-insecure'),
+    'unable-to-process': $('#webauthn-error-unable-to-process'),
+    duplicated: $('#webauthn-error-duplicated'),
+    empty: $('#webauthn-error-empty'),
+    timeout: $('#webauthn-error-timeout'),
   };
-  u2fErrors[errorType].removeClass('hide');
+  Object.keys(webAuthnErrors).forEach((type) => {
+    webAuthnErrors[type].toggleClass('hide', type !== errorType);
+  });
+  $('.webauthn_error_timeout').toggleClass('hide', errorType !== 'timeout');
+  $('#webauthn-error').modal('show');
+}
 
-  Object.keys(u2fErrors).forEach((type) => {
-    if (type !== errorType) {
-      u2fErrors[type].addClass('hide');
-    }
+function initWebAuthnAuth() {
+  if ($('#wait-for-key').length === 0) {
+    return;
+  }
+  if (!webAuthnSupported()) {
+    // Fallback in case browser do not support WebAuthn
+    window.location.href = `${AppSubUrl}/user/two_factor`;
+    return;
+  }
+  webAuthnAssert(`${AppSubUrl}/user/webauthn/assertion`).then((redirect) => {
+    window.location.replace(redirect);
+  }).catch((err) => {
+    webAuthnError(webAuthnErrorType(err));
   });
-  $('#u2f-error').modal('show');
 }
 
-function initU2FRegister() {
-  $('#register-device').modal({allowMultiple: false});
-  $('#u2f-error').modal({allowMultiple: false});
-  $('#register-security-key').on('click', (e) => {
+function initWebAuthnPasswordless() {
+  $('#webauthn-passwordless').on('click', (e) => {
     e.preventDefault();
-    u2fApi.ensureSupport()
-      .then(u2fRegisterRequest)
-      .catch(() => {
-        u2fError('browser');
-      });
+    if (!webAuthnSupported()) {
+      webAuthnError('browser');
+      return;
+    }
+    webAuthnAssert(`${AppSubUrl}/user/webauthn/passwordless/assertion`).then((redirect) => {
+      window.location.replace(redirect);
+    }).catch((err) => {
+      webAuthnError(webAuthnErrorType(err));
+    });
   });
 }
 
-function u2fRegisterRequest() {
-  $.post(`${AppSubUrl}/user/settings/security/u2f/request_register`, {
-    _csrf: csrf,
-    name: $('#nickname').val()
-  }).done((req) => {
-    $('#nickname').closest('div.field').removeClass('error');
-    $('#register-device').modal('show');
-    if (req.registeredKeys === null) {
-      req.registeredKeys = [];
-    }
-    u2fApi.register(req.appId, req.registerRequests, req.registeredKeys, 30)
-      .then(u2fRegistered)
-      .catch((reason) => {
-        if (reason === undefined) {
-          u2fError(1);
-          return;
-        }
-        u2fError(reason.metaData.code);
-      });
-  }).fail((xhr) => {
+async function webAuthnRegisterRequest() {
+  const $nickname = $('#nickname');
+  if ($nickname.val() === '') {
+    webAuthnError('empty');
+    return;
+  }
+
+  let options;
+  try {
+    options = await $.post(`${AppSubUrl}/user/settings/security/webauthn/request_register`, {
+      _csrf: csrf,
+      name: $nickname.val(),
+      passwordless: $('#passwordless').is(':checked'),
+    });
+  } catch (xhr) {
     if (xhr.status === 409) {
-      $('#nickname').closest('div.field').addClass('error');
+      $nickname.closest('div.field').addClass('error');
+    }
+    return;
+  }
+  $nickname.closest('div.field').removeClass('error');
+  $('#register-device').modal('show');
+
+  options.publicKey.challenge = decodeURLEncodedBase64(options.publicKey.challenge);
+  options.publicKey.user.id = decodeURLEncodedBase64(options.publicKey.user.id);
+  for (const cred of options.publicKey.excludeCredentials) {
+    cred.id = decodeURLEncodedBase64(cred.id);
+  }
+
+  try {
+    const credential = await navigator.credentials.create(options);
+    await $.ajax({
+      url: `${AppSubUrl}/user/settings/security/webauthn/register`,
+      type: 'POST',
+      headers: {'X-Csrf-Token': csrf},
+      data: JSON.stringify({
+        id: credential.id,
+        rawId: encodeURLEncodedBase64(credential.rawId),
+        type: credential.type,
+        response: {
+          attestationObject: encodeURLEncodedBase64(credential.response.attestationObject),
+          clientDataJSON: encodeURLEncodedBase64(credential.response.clientDataJSON),
+        },
+      }),
+      contentType: 'application/json; charset=utf-8',
+    });
+    reload();
+  } catch (err) {
+    webAuthnError(webAuthnErrorType(err));
+  }
+}
+
+function initWebAuthnRegister() {
+  $('#register-device').modal({allowMultiple: false});
+  $('#webauthn-error').modal({allowMultiple: false});
+  $('#register-webauthn').on('click', (e) => {
+    e.preventDefault();
+    if (!webAuthnSupported()) {
+      webAuthnError('browser');
+      return;
     }
+    webAuthnRegisterRequest();
   });
 }
 
@@ -2789,8 +2842,9 @@ $(document).ready(async () => {
   initCtrlEnterSubmit();
   initNavbarContentToggle();
   initTopicbar();
-  initU2FAuth();
-  initU2FRegister();
+  initWebAuthnAuth();
+  initWebAuthnPasswordless();
+  initWebAuthnRegister();
   initIssueList();
   initIssueTimetracking();
   initIssueDue();